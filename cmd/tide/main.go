@@ -7,14 +7,10 @@ import (
 
 	"github.com/bethropolis/tide/internal/app"
 	"github.com/bethropolis/tide/internal/config"
+	"github.com/bethropolis/tide/internal/i18n"
 	"github.com/bethropolis/tide/internal/logger"
-)
-
-
-var (
-	Version   = "v0.1.1" 
-	Commit    = "n/a"
-	BuildDate = "n/a"
+	"github.com/bethropolis/tide/internal/startuptime"
+	"github.com/bethropolis/tide/internal/version"
 )
 
 func main() {
@@ -27,6 +23,11 @@ func main() {
 		os.Exit(0)
 	}
 
+	if *flags.StartupTime != "" {
+		startuptime.Start(*flags.StartupTime)
+	}
+	startuptime.Mark("parse flags")
+
 	filePathArg := ""
 	if len(args) > 0 {
 		filePathArg = args[0] // File to open is the first non-flag arg
@@ -41,8 +42,11 @@ func main() {
 		fmt.Fprintf(os.Stderr, "WARN: Error loading config file: %v\n", loadErr)
 	}
 
+	startuptime.Mark("load config")
+
 	// 3. Initialize Logger (using the final loaded config)
 	logger.Init(cfg.Logger)
+	startuptime.Mark("init logger")
 
 	// Enable filter debugging if requested
 	if flags.DebugLog != nil && *flags.DebugLog {
@@ -51,6 +55,23 @@ func main() {
 
 	// --- Now use the logger ---
 	logger.Infof("Starting Tide editor...")
+
+	// 4. Offer a first-run setup when there is no config file yet
+	if *flags.ConfigFilePath == "" && *flags.Script == "" {
+		firstRunSetup(cfg)
+	}
+	startuptime.Mark("first-run check")
+
+	// 5. Layer project settings (.tide.toml) over the user config
+	applyLocalConfig()
+	startuptime.Mark("load project settings")
+
+	// 6. Pick the message catalog
+	if err := i18n.Load(config.Get().Editor.Language); err != nil {
+		logger.Warnf("Using English messages: %v", err)
+	}
+	startuptime.Mark("load messages")
+	logger.DebugTagf("config", "Message locale: %s", i18n.Locale())
 	logger.DebugTagf("config", "Effective Log level set to: %s", cfg.Logger.LogLevel)
 	logger.DebugTagf("config", "Effective Log file: %s", cfg.Logger.LogFilePath)
 	logger.DebugTagf("config", "Tab Width: %d", cfg.Editor.TabWidth)
@@ -74,6 +95,14 @@ func main() {
 	// Define an app.Config struct if needed
 	appConfig := filePathArg // Currently just passing filepath, but could expand to a struct
 
+	if *flags.Script != "" {
+		if err := app.RunScript(appConfig, *flags.Script); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
 	tideApp, err := app.NewApp(appConfig)
 	if err != nil {
 		logger.Fatalf("Error initializing application: %v", err) // Use Fatalf
@@ -87,10 +116,57 @@ func main() {
 	os.Exit(0) // Explicit exit
 }
 
+// applyLocalConfig finds the project settings file for the working
+// directory and loads it, asking the user first unless they already
+// trusted this exact content.
+func applyLocalConfig() {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return
+	}
+	path := config.FindLocalConfig(cwd)
+	if path == "" {
+		return
+	}
+	local, err := config.ReadLocalConfig(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "WARN: Error reading project settings: %v\n", err)
+		return
+	}
+
+	if !local.Trusted() {
+		if !stdinIsTerminal() {
+			fmt.Fprintf(os.Stderr, "Skipping untrusted project settings %s\n", local.Path)
+			return
+		}
+		fmt.Printf("Load project settings from %s?\n", local.Path)
+		if !confirm("They can change keybindings, plugin settings and the commands run as language servers.", false) {
+			logger.Infof("Project settings %s not trusted, skipping", local.Path)
+			return
+		}
+		if err := local.Trust(); err != nil {
+			fmt.Fprintf(os.Stderr, "WARN: Could not remember trust for %s: %v\n", local.Path, err)
+		}
+	}
+
+	if err := local.Apply(); err != nil {
+		fmt.Fprintf(os.Stderr, "WARN: Error loading project settings: %v\n", err)
+		return
+	}
+	logger.Infof("Loaded project settings from %s", local.Path)
+}
+
+// stdinIsTerminal reports whether questions can be asked on stdin.
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
 
 func printVersion() {
+	info := version.Get()
 	fmt.Printf("Tide Editor\n")
-	fmt.Printf(" Version:   %s\n", Version)
-	fmt.Printf(" Commit:    %s\n", Commit)
-	fmt.Printf(" Built:     %s\n", BuildDate)
+	fmt.Printf(" Version:   %s\n", info.Version)
+	fmt.Printf(" Commit:    %s\n", info.Commit)
+	fmt.Printf(" Built:     %s\n", info.BuildDate)
+	fmt.Printf(" Go:        %s %s\n", info.GoVersion, info.Platform)
 }
\ No newline at end of file
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bethropolis/tide/internal/config"
+	"github.com/bethropolis/tide/internal/logger"
+	"github.com/bethropolis/tide/internal/paths"
+	"github.com/bethropolis/tide/internal/theme"
+)
+
+// Clipboard choices offered by the first-run setup.
+const (
+	clipboardInternal = "internal"
+	clipboardSystem   = "system"
+	clipboardPrimary  = "system+primary"
+)
+
+// firstRunSetup asks a few questions when tide starts without a config
+// file, applies the answers to cfg and writes them out as a commented
+// config.toml. Declining still writes the defaults, so the question is
+// only asked once.
+func firstRunSetup(cfg *config.Config) {
+	path, err := paths.Config(config.DefaultConfigFileName)
+	if err != nil {
+		return
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) || !stdinIsTerminal() {
+		return
+	}
+
+	fmt.Printf("Welcome to Tide! There is no config file at %s yet.\n", path)
+	file := config.NewDefaultConfig()
+	if confirm("Set it up now? Otherwise the defaults are written there.", true) {
+		fmt.Println("Press Enter to keep the value in brackets.")
+		setupAnswers(file)
+	}
+
+	// Only the asked-about settings change; flags given for this run still win
+	cfg.Editor.TabWidth = file.Editor.TabWidth
+	cfg.Editor.SystemClipboard = file.Editor.SystemClipboard
+	cfg.Editor.PrimarySelection = file.Editor.PrimarySelection
+	cfg.Editor.LeaderKey = file.Editor.LeaderKey
+
+	if err := file.WriteCommented(path); err != nil {
+		fmt.Fprintf(os.Stderr, "WARN: Could not write %s: %v\n", path, err)
+		return
+	}
+	fmt.Printf("Wrote %s. Edit it any time; every option is explained there.\n", path)
+	logger.Infof("First-run setup wrote %s", path)
+}
+
+// setupAnswers asks for the theme, tab width, clipboard and leader key.
+func setupAnswers(file *config.Config) {
+	themes := theme.NewManager()
+	if names := themes.ListThemes(); len(names) > 1 {
+		sort.Strings(names)
+		current := themes.Current().Name
+		name := ask("Theme ("+strings.Join(names, ", ")+")", current, func(s string) error {
+			if _, ok := themes.GetTheme(s); !ok {
+				return fmt.Errorf("no theme named %q", s)
+			}
+			return nil
+		})
+		if !strings.EqualFold(name, current) {
+			if err := themes.SetTheme(name); err != nil {
+				fmt.Fprintf(os.Stderr, "WARN: %v\n", err)
+			}
+		}
+	}
+
+	width := ask("Tab width", strconv.Itoa(file.Editor.TabWidth), func(s string) error {
+		if n, err := strconv.Atoi(s); err != nil || n < 1 || n > 16 {
+			return errors.New("enter a number from 1 to 16")
+		}
+		return nil
+	})
+	file.Editor.TabWidth, _ = strconv.Atoi(width)
+
+	choices := []string{clipboardInternal, clipboardSystem}
+	if runtime.GOOS == "linux" {
+		choices = append(choices, clipboardPrimary)
+	}
+	def := clipboardInternal
+	if file.Editor.SystemClipboard {
+		def = clipboardSystem
+	}
+	clipboard := ask("Clipboard: "+strings.Join(choices, ", "), def, func(s string) error {
+		for _, c := range choices {
+			if s == c {
+				return nil
+			}
+		}
+		return fmt.Errorf("choose one of %s", strings.Join(choices, ", "))
+	})
+	file.Editor.SystemClipboard = clipboard != clipboardInternal
+	file.Editor.PrimarySelection = clipboard == clipboardPrimary
+
+	file.Editor.LeaderKey = ask("Leader key", file.Editor.LeaderKey, func(s string) error {
+		if utf8.RuneCountInString(s) != 1 {
+			return errors.New("enter a single key")
+		}
+		return nil
+	})
+}
+
+// stdin is shared by all questions so no typed-ahead answer is lost.
+var stdin = bufio.NewReader(os.Stdin)
+
+// confirm asks a yes/no question; an empty answer picks def.
+func confirm(question string, def bool) bool {
+	choices := "y/N"
+	if def {
+		choices = "Y/n"
+	}
+	fmt.Printf("%s [%s] ", question, choices)
+	line, _ := stdin.ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return true
+	case "n", "no":
+		return false
+	}
+	return def
+}
+
+// ask prints a question and reads the answer, repeating the question until
+// valid accepts it. An empty answer, or the end of input, picks def.
+func ask(question, def string, valid func(string) error) string {
+	for {
+		fmt.Printf("%s [%s]: ", question, def)
+		line, err := stdin.ReadString('\n')
+		answer := strings.TrimSpace(line)
+		if answer == "" {
+			if err != nil {
+				fmt.Println()
+			}
+			return def
+		}
+		if verr := valid(answer); verr != nil {
+			fmt.Println(verr)
+			if err != nil {
+				return def
+			}
+			continue
+		}
+		return answer
+	}
+}
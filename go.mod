@@ -23,5 +23,5 @@ require (
 	github.com/mattn/go-runewidth v0.0.20 // indirect
 	golang.org/x/sys v0.29.0 // indirect
 	golang.org/x/term v0.28.0 // indirect
-	golang.org/x/text v0.21.0 // indirect
+	golang.org/x/text v0.21.0
 )
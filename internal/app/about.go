@@ -0,0 +1,115 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bethropolis/tide/internal/config"
+	"github.com/bethropolis/tide/internal/highlighter/lang"
+	"github.com/bethropolis/tide/internal/version"
+)
+
+// ShowVersion shows the build information, the optional features that are
+// on, and the loaded plugins and grammars in a read-only buffer (:version).
+func (a *App) ShowVersion() {
+	info := version.Get()
+	var b strings.Builder
+	line := func(format string, args ...interface{}) {
+		fmt.Fprintf(&b, format+"\n", args...)
+	}
+	section := func(title string) {
+		line("\n%s\n%s", title, strings.Repeat("-", len(title)))
+	}
+
+	line("Tide %s", info)
+	line("  Version    %s", info.Version)
+	line("  Commit     %s", info.Commit)
+	line("  Built      %s", info.BuildDate)
+	line("  Go         %s %s", info.GoVersion, info.Platform)
+	if path := config.FilePath(); path != "" {
+		line("  Config     %s", path)
+	}
+
+	section("Features")
+	for _, f := range enabledFeatures(config.Get()) {
+		line("  %s", f)
+	}
+
+	section("Plugins")
+	if a.pluginManager != nil {
+		names := a.pluginManager.Names()
+		sort.Strings(names)
+		for _, name := range names {
+			line("  %s", name)
+		}
+	}
+
+	section("Grammars")
+	for _, l := range lang.GetAll() {
+		line("  %-12s %s", l.Name, strings.Join(l.Extensions, " "))
+	}
+
+	a.showReport(&a.about, b.String())
+	a.statusBar.SetTemporaryMessage("Tide %s", info)
+	a.requestRedraw()
+}
+
+// enabledFeatures lists the optional behaviors cfg turns on, by their
+// config.toml names, and the configured language servers.
+func enabledFeatures(cfg *config.Config) []string {
+	e := cfg.Editor
+	toggles := []struct {
+		name string
+		on   bool
+	}{
+		{"system_clipboard", e.SystemClipboard},
+		{"primary_selection", e.PrimarySelection},
+		{"osc52_clipboard", e.OSC52Clipboard},
+		{"paste_reindent", e.PasteReindent},
+		{"open_dropped_files", e.OpenDroppedFiles},
+		{"auto_view", e.AutoView},
+		{"templates", e.Templates},
+		{"table_view", e.TableView},
+		{"virtual_edit", e.VirtualEdit},
+		{"sticky_context", e.StickyContext},
+		{"inline_blame", e.InlineBlame},
+		{"wrap_scan", e.WrapScan},
+		{"normalize_search", e.NormalizeSearch},
+		{"project_index", e.ProjectIndex},
+		{"expand_tab", e.ExpandTab},
+		{"line_numbers", e.LineNumbers},
+		{"show_gutter", e.ShowGutter},
+		{"status_line", e.StatusLine},
+		{"title", e.Title},
+		{"ui.screen_reader", cfg.UI.ScreenReader},
+		{"ui.reduce_motion", cfg.UI.ReduceMotion},
+	}
+	var features []string
+	for _, t := range toggles {
+		if t.on {
+			features = append(features, t.name)
+		}
+	}
+
+	servers := make([]string, 0, len(cfg.LSP))
+	for id, srv := range cfg.LSP {
+		if len(srv.Command) > 0 {
+			servers = append(servers, id)
+		}
+	}
+	sort.Strings(servers)
+	if len(servers) > 0 {
+		features = append(features, "lsp: "+strings.Join(servers, ", "))
+	}
+	if e.BufferBackend != "" && e.BufferBackend != config.DefaultBufferBackend {
+		features = append(features, "buffer_backend: "+e.BufferBackend)
+	}
+	if e.MmapThreshold > 0 {
+		features = append(features, fmt.Sprintf("mmap_threshold: %d MB", e.MmapThreshold))
+	}
+	if local := config.AppliedLocalConfig(); local != "" {
+		features = append(features, "project settings: "+local)
+	}
+	return features
+}
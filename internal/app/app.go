@@ -2,26 +2,33 @@
 package app
 
 import (
-	"context"
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
-	"github.com/bethropolis/tide/internal/buffer"
+	"github.com/bethropolis/tide/internal/archive"
 	"github.com/bethropolis/tide/internal/commands"
 	"github.com/bethropolis/tide/internal/config"
 	"github.com/bethropolis/tide/internal/core"
+	"github.com/bethropolis/tide/internal/core/clipboard"
+	"github.com/bethropolis/tide/internal/core/find"
+	"github.com/bethropolis/tide/internal/core/index"
+	"github.com/bethropolis/tide/internal/core/jumplist"
 	"github.com/bethropolis/tide/internal/event"
 	"github.com/bethropolis/tide/internal/highlighter"
+	"github.com/bethropolis/tide/internal/i18n"
 	"github.com/bethropolis/tide/internal/input"
 	"github.com/bethropolis/tide/internal/logger"
 	"github.com/bethropolis/tide/internal/modehandler"
 	"github.com/bethropolis/tide/internal/plugin"
+	"github.com/bethropolis/tide/internal/startuptime"
 	"github.com/bethropolis/tide/internal/statusbar"
 	"github.com/bethropolis/tide/internal/theme"
 	"github.com/bethropolis/tide/internal/tui"
 	"github.com/bethropolis/tide/internal/types"
+	"github.com/bethropolis/tide/internal/utils"
 	"github.com/gdamore/tcell/v2"
 )
 
@@ -30,6 +37,7 @@ type App struct {
 	tuiManager         *tui.TUI
 	editors            []*core.Editor
 	activeEditorIndex  int
+	layout             *tui.Layout // Split windows; the focused one shows the active editor
 	statusBar          *statusbar.StatusBar
 	eventManager       *event.Manager
 	pluginManager      *plugin.Manager
@@ -38,9 +46,53 @@ type App struct {
 	highlighterService *highlighter.Highlighter
 	activeTheme        *theme.Theme
 	themeManager       *theme.Manager
-	fuzzyFinder        *tui.FuzzyFinder // Overlay
-	picker             *tui.Picker     // Generic plugin-reusable overlay
-	completion         *tui.CompletionOverlay // Identifier completion suggestion popup
+	fuzzyFinder        *tui.FuzzyFinder          // Overlay
+	picker             *tui.Picker               // Generic plugin-reusable overlay
+	completion         *tui.CompletionOverlay    // Identifier completion suggestion popup
+	windows            *tui.WindowStack          // Free-standing floating windows (plugin UIs, popups)
+	confirm            *tui.ConfirmDialog        // Modal yes/no style prompts
+	dirViews           map[*core.Editor]*dirView // Editors showing a directory listing
+	drawHooks          drawHooks                 // Plugin painters run after each frame
+	scheduler          scheduler                 // Plugin functions waiting to run on the event loop
+	lsp                lspClients                // Language servers, started on first use
+	hover              *docPopup                 // LSP hover documentation (K), closed by the next key
+	signature          *docPopup                 // LSP signature help while typing a call
+	signatureSeq       int                       // Identifies the latest signature help request
+
+	// Bracketed paste state: keys between paste start and end are collected
+	pasting  bool
+	pasteBuf strings.Builder
+
+	cursorHold utils.Debouncer // Turns CursorMoved into CursorHold once the cursor rests
+
+	pathCompleter utils.PathCompleter // Directory listings for the path being typed in a string
+
+	// Screen reader mode: what the announcement line last said changed
+	announcedMode   string
+	announcedEditor *core.Editor
+
+	blame          map[*core.Editor]*blameCache // git blame per buffer, for inline blame
+	contentChanged map[*core.Editor]bool        // Buffers edited since the last TypeContentChanged
+	savedDiffs     map[*core.Editor]*savedDiff  // Unsaved changes per buffer shown with :diffsaved
+	collab         *collabSession               // Buffer shared with another tide (:collab)
+	tails          map[*core.Editor]*following  // Buffers whose files are followed as they grow (:tail)
+	tailStop       chan struct{}                // Stops checking the followed files; nil when none are
+	loading        map[*core.Editor]func()      // Cancels reading the text of each buffer being loaded (loadAsync)
+	health         *core.Editor                 // Read-only :checkhealth report, reused by the next run
+	about          *core.Editor                 // Read-only :version report
+	keymaps        *core.Editor                 // Read-only :map listing
+	docs           *core.Editor                 // Read-only :man / :godoc page
+	docSections    []int                        // Lines of the section headings in docs
+	index          *index.Index                 // Words and symbols of the project; nil when editor.project_index is off
+
+	lastSession []byte // Last session snapshot written, without its time
+	sourcing    int    // Scripts being replayed by :source, nested ones included
+
+	// Quickfix list (validation errors and other locations to step through)
+	quickfix    []types.QuickfixItem
+	quickfixIdx int // Entry last jumped to, -1 before the first jump
+
+	jumps *jumplist.List // Places jumped from, in any buffer (Ctrl+O, Ctrl+I)
 
 	// Channels managed by the App
 	quit          chan struct{}
@@ -49,31 +101,58 @@ type App struct {
 
 // NewApp creates and initializes a new application instance.
 func NewApp(filePath string) (*App, error) {
+	return newApp(filePath, tui.New)
+}
+
+// newApp creates the app on the screen newTUI sets up.
+func newApp(filePath string, newTUI func() (*tui.TUI, error)) (*App, error) {
+	// Grammars register while the terminal and the file are set up
+	highlighterReady := make(chan *highlighter.Highlighter, 1)
+	go func() {
+		began := time.Now()
+		highlighterReady <- highlighter.NewHighlighter()
+		startuptime.Since(began, "register grammars (background)")
+	}()
+
 	// --- Create Core Components ---
-	tuiManager, err := tui.New()
+	tuiManager, err := newTUI()
 	if err != nil {
 		return nil, fmt.Errorf("TUI initialization failed: %w", err)
 	}
+	startuptime.Mark("init terminal")
+	clipboard.SetTerminalWriter(tuiManager.GetScreen().SetClipboard)
 
-	buf := buffer.NewPieceTable()
-	var _ buffer.Buffer = buf
+	buf := newBuffer(filePath)
 
-	loadErr := buf.Load(filePath)
+	var loadErr error
+	if info, statErr := os.Stat(filePath); statErr == nil && info.IsDir() {
+		logger.Infof("'%s' is a directory, opening in browse mode", filePath)
+	} else if _, _, inArchive := archive.Split(filePath); !isURL(filePath) && !inArchive {
+		// URLs and archive members are read by createEditor
+		loadErr = buf.Load(filePath)
+	}
 	if loadErr != nil && !errors.Is(loadErr, os.ErrNotExist) {
 		logger.Errorf("Critical error: failed to load file '%s': %v", filePath, loadErr)
 		// Close TUI before returning error since we initialized it
 		tuiManager.Close()
 		return nil, fmt.Errorf("failed to open file '%s': %w", filePath, loadErr)
 	}
+	startuptime.Mark("read file")
+
+	statusConfig := statusbar.DefaultConfig()
+	statusConfig.ScreenReader = config.Get().UI.ScreenReader
 
 	appInstance := &App{
 		tuiManager:    tuiManager,
-		statusBar:     statusbar.New(statusbar.DefaultConfig()),
+		statusBar:     statusbar.New(statusConfig),
 		eventManager:  event.NewManager(),
 		pluginManager: plugin.NewManager(),
 		themeManager:  theme.NewManager(),
+		windows:       tui.NewWindowStack(),
+		confirm:       tui.NewConfirmDialog(),
 		quit:          make(chan struct{}),
 		redrawRequest: make(chan struct{}, 1),
+		jumps:         jumplist.New(jumplist.DefaultMaxJumps),
 	}
 
 	appInstance.fuzzyFinder = tui.NewFuzzyFinder(func(selectedPath string) {
@@ -82,6 +161,7 @@ func NewApp(filePath string) (*App, error) {
 	})
 
 	appInstance.picker = tui.NewPicker("", nil, nil)
+	appInstance.quickfixIdx = -1
 
 	appInstance.completion = &tui.CompletionOverlay{
 		OnAccept: func(item tui.CompletionItem) {
@@ -110,22 +190,29 @@ func NewApp(filePath string) (*App, error) {
 
 			cursor.Col = prefixStart + len([]rune(item.InsertText))
 			ed.SetCursor(cursor)
+			appInstance.modeHandler.RecordCompletion(end.Col-start.Col, item.InsertText)
 
-			appInstance.eventManager.Dispatch(event.TypeBufferModified, event.BufferModifiedData{Edit: editInfo})
+			appInstance.eventManager.Dispatch(event.TypeBufferModified, event.BufferModifiedData{Edit: editInfo, FilePath: ed.GetBuffer().FilePath()})
 			appInstance.requestRedraw()
 		},
 	}
 
 	appInstance.activeTheme = appInstance.themeManager.Current()
+	startuptime.Mark("load theme")
 
-	highlighterSvc := highlighter.NewHighlighter()
-	appInstance.highlighterService = highlighterSvc
+	appInstance.highlighterService = <-highlighterReady
+	startuptime.Mark("wait for grammars")
+	appInstance.startIndex()
 
 	editor := appInstance.createEditor(filePath)
+	appInstance.autoLoadView(editor)
 	appInstance.editors = append(appInstance.editors, editor)
 	appInstance.activeEditorIndex = 0
+	appInstance.layout = tui.NewLayout(editor)
+	startuptime.Mark("create editor")
 
 	inputProcessor := input.NewInputProcessor()
+	inputProcessor.SetLeaderKey(config.Get().Editor.Leader())
 	if err := inputProcessor.LoadUserBindings(&config.Get().Keybinds); err != nil {
 		logger.Warnf("Failed to load user keybindings from config: %v", err)
 	}
@@ -137,7 +224,9 @@ func NewApp(filePath string) (*App, error) {
 		QuitSignal:     appInstance.quit,
 		OnInsertEdit: func() {
 			appInstance.rebuildCompletions()
+			appInstance.updateSignatureHelp()
 		},
+		CompletionWords: appInstance.completionWords,
 	}
 	modeHandler := modehandler.New(modeHandlerCfg)
 	appInstance.modeHandler = modeHandler
@@ -146,17 +235,45 @@ func NewApp(filePath string) (*App, error) {
 	modeHandler.SetAPI(appInstance.editorAPI)
 
 	commands.RegisterAppCommands(appInstance.editorAPI, appInstance)
+	startuptime.Mark("register commands")
 
 	// --- Register Plugins (Call centralized function) ---
 	err = registerPlugins(appInstance.pluginManager) // <<< CALL NEW FUNCTION
 	if err != nil {
 		logger.Errorf("Errors occurred during plugin registration: %v", err)
 	}
+	startuptime.Mark("load plugins")
 
 	appInstance.eventManager.Subscribe(event.TypeCursorMoved, appInstance.handleCursorMovedForStatus)
 	appInstance.eventManager.Subscribe(event.TypeBufferModified, appInstance.handleBufferModifiedForStatus)
 	appInstance.eventManager.Subscribe(event.TypeBufferSaved, appInstance.handleBufferSavedForStatus)
 	appInstance.eventManager.Subscribe(event.TypeBufferLoaded, appInstance.handleBufferLoadedForStatus)
+	appInstance.eventManager.Subscribe(event.TypeBufferRenamed, appInstance.handleBufferRenamedForStatus)
+
+	appInstance.eventManager.Subscribe(event.TypeCursorMoved, appInstance.handleCursorMovedForWordHighlight)
+	appInstance.eventManager.Subscribe(event.TypeCursorHold, appInstance.handleCursorHoldForWordHighlight)
+	appInstance.eventManager.Subscribe(event.TypeBufferModified, appInstance.handleBufferModifiedForWordHighlight)
+	appInstance.eventManager.Subscribe(event.TypeBufferModified, appInstance.handleBufferModifiedForTable)
+	appInstance.eventManager.Subscribe(event.TypeBufferModified, appInstance.handleBufferModifiedForConflicts)
+	appInstance.eventManager.Subscribe(event.TypeBufferModified, appInstance.handleBufferModifiedForWindows)
+	appInstance.eventManager.Subscribe(event.TypeBufferModified, appInstance.handleBufferModifiedForContentChange)
+	appInstance.eventManager.Subscribe(event.TypeBufferModified, appInstance.handleBufferModifiedForCollab)
+	appInstance.eventManager.Subscribe(event.TypeCursorMoved, appInstance.handleCursorMovedForCollab)
+
+	appInstance.eventManager.Subscribe(event.TypeCursorMoved, appInstance.handleCursorMovedForBlame)
+	appInstance.eventManager.Subscribe(event.TypeCursorHold, appInstance.handleCursorHoldForBlame)
+	appInstance.eventManager.Subscribe(event.TypeBufferModified, appInstance.handleBufferChangedForBlame)
+	appInstance.eventManager.Subscribe(event.TypeBufferLoaded, appInstance.handleBufferChangedForBlame)
+	appInstance.eventManager.Subscribe(event.TypeBufferRenamed, appInstance.handleBufferChangedForBlame)
+	appInstance.eventManager.Subscribe(event.TypeBufferModified, appInstance.handleBufferChangedForSavedDiff)
+	appInstance.eventManager.Subscribe(event.TypeBufferSaved, appInstance.handleBufferChangedForSavedDiff)
+	appInstance.eventManager.Subscribe(event.TypeBufferLoaded, appInstance.handleBufferChangedForSavedDiff)
+	appInstance.eventManager.Subscribe(event.TypeBufferRenamed, appInstance.handleBufferChangedForSavedDiff)
+	appInstance.eventManager.Subscribe(event.TypeBufferSaved, appInstance.handleBufferSavedForIndex)
+	appInstance.eventManager.Subscribe(event.TypeBufferRenamed, appInstance.handleBufferRenamedForIndex)
+	appInstance.eventManager.Subscribe(event.TypeFileDeleted, appInstance.handleFileDeletedForIndex)
+	appInstance.eventManager.Subscribe(event.TypeBufferModified, appInstance.handleBufferModifiedForMarks)
+	appInstance.eventManager.Subscribe(event.TypeBufferSaved, appInstance.handleBufferSavedForMarks)
 
 	appInstance.eventManager.Subscribe(event.TypeTriggerFuzzyFind, func(e event.Event) bool {
 		cwd, err := os.Getwd()
@@ -167,13 +284,24 @@ func NewApp(filePath string) (*App, error) {
 		return false
 	})
 
+	appInstance.eventManager.Subscribe(event.TypeTriggerCommandPalette, func(e event.Event) bool {
+		appInstance.showCommandPalette()
+		return false
+	})
+
+	appInstance.eventManager.Subscribe(event.TypeTriggerHover, appInstance.handleTriggerHover)
+	appInstance.eventManager.Subscribe(event.TypeTriggerCodeActions, appInstance.handleTriggerCodeActions)
+
+	appInstance.eventManager.Subscribe(event.TypeTriggerClipboardHistory, func(e event.Event) bool {
+		appInstance.showClipboardHistory()
+		return false
+	})
+
 	appInstance.eventManager.Subscribe(event.TypeBufferModified, func(e event.Event) bool {
 		if data, ok := e.Data.(event.BufferModifiedData); ok {
 			if hm := appInstance.getActiveEditor().GetHighlightManager(); hm != nil {
 				logger.DebugTagf("highlight", "App: Forwarding BufferModified event to core highlight manager")
 				hm.AccumulateEdit(data.Edit)
-			} else {
-				logger.Warnf("App: Highlight manager is nil, cannot process buffer modification.")
 			}
 			// Mark affected lines dirty for delta rendering.
 			ed := appInstance.getActiveEditor()
@@ -204,92 +332,87 @@ func NewApp(filePath string) (*App, error) {
 	})
 
 	appInstance.pluginManager.InitializePlugins(appInstance.editorAPI)
+	startuptime.Mark("init plugins")
 
-	width, height := tuiManager.Size()
-	editor.SetViewSize(width, height-config.StatusBarHeight)
-
-	logger.DebugTagf("highlight", "App: Beginning initial asynchronous syntax highlight process...")
-	lang, queryBytes := appInstance.highlighterService.GetLanguage(filePath)
-	if lang != nil {
-		logger.DebugTagf("highlight", "App: Language detected for '%s', proceeding with highlighting", filePath)
-
-		bufContent := buf.Bytes() // capture bytes to avoid race and block
-		go func() {
-			logger.DebugTagf("highlight", "App: Calling highlighter.HighlightBuffer asynchronously...")
-			startTime := time.Now()
-			// Background context
-			initialCtx := context.Background()
-			initialHighlights, initialTree, err := appInstance.highlighterService.HighlightBuffer(initialCtx, bufContent, lang, queryBytes, nil)
-			duration := time.Since(startTime)
-
-			if err != nil {
-				logger.Warnf("App: Initial async highlighting failed: %v", err)
-			} else {
-				highlightCount := 0
-				for _, ranges := range initialHighlights {
-					highlightCount += len(ranges)
-				}
-				logger.DebugTagf("highlight", "App: Initial async highlighting complete in %v. Found %d highlight ranges across %d lines.",
-					duration, highlightCount, len(initialHighlights))
-
-				// Update core highlight manager and trigger a redraw
-				if hm := editor.GetHighlightManager(); hm != nil {
-					hm.UpdateHighlights(initialHighlights, initialTree)
-					logger.DebugTagf("highlight", "App: Core highlight manager state updated successfully. Requesting redraw.")
-					editor.MarkAllDirty()
-					appInstance.requestRedraw()
-				} else {
-					logger.Warnf("App: Highlight manager is nil after async highlight.")
-				}
-			}
-		}()
-	} else {
-		logger.DebugTagf("highlight", "App: No language detected for initial highlight of '%s'", filePath)
-		if hm := editor.GetHighlightManager(); hm != nil {
-			hm.ClearHighlights()
-		}
-	}
+	appInstance.sizeView(editor)
 
 	return appInstance, nil
 }
 
 // Run starts the application's main event and drawing loops.
 func (a *App) Run() error {
+	// Messages for the user are printed after the TUI is closed so they are
+	// not wiped when the terminal leaves the alternate screen.
+	var exitMessages []string
 	defer func() {
-		if ed := a.getActiveEditor(); ed != nil {
-			if hm := ed.GetHighlightManager(); hm != nil {
-				hm.Shutdown()
-			}
+		a.shutdown()
+		for _, msg := range exitMessages {
+			fmt.Fprintln(os.Stderr, msg)
 		}
-		a.pluginManager.ShutdownPlugins()
-		a.tuiManager.Close()
 		logger.Infof("Tide editor shut down.")
 	}()
 
 	go a.eventLoop()
 
 	a.eventManager.Dispatch(event.TypeAppReady, event.AppReadyData{})
-	a.statusBar.SetTemporaryMessage("Tide Editor - Ctrl+S Save | :q Quit | ,: Command | ,/ Find")
+	startuptime.Mark("app_ready handlers")
+	a.startSessionSnapshots()
+	a.offerSessionRestore()
+	if notice, ok := a.startupNotice(); ok {
+		a.statusBar.SetTemporaryMessage("%s", notice)
+	} else {
+		a.statusBar.SetTemporaryMessage(i18n.T("status.welcome"))
+	}
 	a.requestRedraw()
 
+	drawn := false
 	for {
 		select {
 		case <-a.quit:
 			logger.Infof("Quit signal received.")
 			a.eventManager.Dispatch(event.TypeAppQuit, event.AppQuitData{})
-			if ed := a.getActiveEditor(); ed != nil && ed.GetBuffer().IsModified() {
+			for _, ed := range a.editors {
+				a.autoSaveView(ed)
+			}
+			a.removeSessionSnapshot()
+			a.stopRecording()
+			backups, err := a.backupUnsavedBuffers()
+			if len(backups) > 0 || err != nil {
 				logger.Warnf("Exited with unsaved changes.")
-				fmt.Fprintln(os.Stderr, "Warning: Exited with unsaved changes.")
+				exitMessages = append(exitMessages, "Warning: Exited with unsaved changes.")
+			}
+			for name, path := range backups {
+				logger.Infof("Backed up unsaved buffer '%s' to %s", name, path)
+				exitMessages = append(exitMessages, fmt.Sprintf("  %s backed up to %s", name, path))
+			}
+			if err != nil {
+				logger.Errorf("Backup of unsaved buffers failed: %v", err)
+				exitMessages = append(exitMessages, fmt.Sprintf("  Backup failed: %v", err))
 			}
 			return nil
 		case <-a.redrawRequest:
-			w, h := a.tuiManager.Size()
-			if ed := a.getActiveEditor(); ed != nil {
-				ed.SetViewSize(w, h-config.StatusBarHeight)
-			}
 			a.drawEditor()
+			if !drawn {
+				drawn = true
+				startuptime.Mark("first screen")
+				if err := startuptime.Finish(); err != nil {
+					logger.Warnf("Could not write startup times: %v", err)
+				}
+			}
+		}
+	}
+}
+
+// shutdown stops the background work of the app and closes the screen.
+func (a *App) shutdown() {
+	if ed := a.getActiveEditor(); ed != nil {
+		if hm := ed.GetHighlightManager(); hm != nil {
+			hm.Shutdown()
 		}
 	}
+	a.pluginManager.ShutdownPlugins()
+	a.lsp.shutdown()
+	a.tuiManager.Close()
 }
 
 // eventLoop handles TUI events, delegating key events to ModeHandler.
@@ -309,31 +432,30 @@ func (a *App) eventLoop() {
 			needsRedraw = true
 
 		case *tcell.EventKey:
-			if a.fuzzyFinder != nil && a.fuzzyFinder.IsActive() {
-				needsRedraw = a.fuzzyFinder.HandleKeyEvent(eventData)
-			} else if a.picker != nil && a.picker.IsActive() {
-				needsRedraw = a.picker.HandleKeyEvent(eventData)
-			} else if a.completion != nil && a.completion.IsActive() {
-				// Completion overlay: Tab/Enter accept, Up/Down
-				// navigate, Esc cancels; everything else passes through
-				// to the editor so the user can keep typing.
-				switch eventData.Key() {
-				case tcell.KeyEscape, tcell.KeyCtrlC:
-					a.completion.Cancel()
-					needsRedraw = true
-				case tcell.KeyEnter, tcell.KeyTab:
-					needsRedraw = a.completion.HandleKeyEvent(eventData)
-				case tcell.KeyUp, tcell.KeyDown, tcell.KeyCtrlK, tcell.KeyCtrlJ:
-					needsRedraw = a.completion.HandleKeyEvent(eventData)
-				default:
-					needsRedraw = a.modeHandler.HandleKeyEvent(eventData)
-				}
+			if a.pasting {
+				a.pasteBuf.WriteString(pastedKeyText(eventData))
+			} else {
+				needsRedraw = a.handleKeyEvent(eventData)
+			}
+
+		case *tcell.EventPaste:
+			if eventData.Start() {
+				a.pasting = true
+				a.pasteBuf.Reset()
 			} else {
-				needsRedraw = a.modeHandler.HandleKeyEvent(eventData)
+				a.pasting = false
+				a.handlePaste(a.pasteBuf.String())
+				needsRedraw = true
 			}
 
 		case *tcell.EventMouse:
-			needsRedraw = a.modeHandler.HandleMouseEvent(eventData)
+			needsRedraw = a.handlePaneMouse(eventData)
+		}
+
+		// Interrupts only wake the loop; scheduled functions also run after
+		// any other event in case the wake-up was dropped.
+		if a.runScheduled() {
+			needsRedraw = true
 		}
 
 		if needsRedraw {
@@ -342,6 +464,47 @@ func (a *App) eventLoop() {
 	}
 }
 
+// handleKeyEvent routes a key to the topmost active overlay, the directory
+// browser or the mode handler. Returns true if a redraw is needed.
+func (a *App) handleKeyEvent(eventData *tcell.EventKey) bool {
+	// Hover documentation closes on the next key; Esc does nothing else
+	if a.closeDocPopup(&a.hover) && eventData.Key() == tcell.KeyEscape {
+		return true
+	}
+
+	needsRedraw := false
+	if a.confirm != nil && a.confirm.IsActive() {
+		needsRedraw = a.confirm.HandleKeyEvent(eventData)
+	} else if a.fuzzyFinder != nil && a.fuzzyFinder.IsActive() {
+		needsRedraw = a.fuzzyFinder.HandleKeyEvent(eventData)
+	} else if a.picker != nil && a.picker.IsActive() {
+		needsRedraw = a.picker.HandleKeyEvent(eventData)
+	} else if a.completion != nil && a.completion.IsActive() {
+		// Completion overlay: Tab/Enter accept, Up/Down
+		// navigate, Esc cancels; everything else passes through
+		// to the editor so the user can keep typing.
+		switch eventData.Key() {
+		case tcell.KeyEscape, tcell.KeyCtrlC:
+			a.completion.Cancel()
+			needsRedraw = true
+		case tcell.KeyEnter, tcell.KeyTab:
+			needsRedraw = a.completion.HandleKeyEvent(eventData)
+		case tcell.KeyUp, tcell.KeyDown, tcell.KeyCtrlK, tcell.KeyCtrlJ:
+			needsRedraw = a.completion.HandleKeyEvent(eventData)
+		default:
+			needsRedraw = a.modeHandler.HandleKeyEvent(eventData)
+		}
+	} else if a.handleDirViewKey(eventData) || a.handleDocViewKey(eventData) {
+		needsRedraw = true
+	} else {
+		needsRedraw = a.modeHandler.HandleKeyEvent(eventData)
+	}
+	if a.signature != nil && a.modeHandler.GetCurrentMode() != modehandler.ModeInsert {
+		needsRedraw = a.closeDocPopup(&a.signature) || needsRedraw
+	}
+	return needsRedraw
+}
+
 // GetModeHandler allows the API adapter to access the mode handler.
 func (a *App) GetModeHandler() *modehandler.ModeHandler {
 	return a.modeHandler
@@ -430,7 +593,8 @@ func (a *App) SetStatusMessage(format string, args ...interface{}) {
 // rebuildCompletions is called after each insert-mode edit.  It queries the
 // current buffer's Tree-sitter tree for local identifier symbols and
 // updates the floating completion overlay if the cursor sits at the end of
-// a word prefix.
+// a word prefix. Inside a string that looks like a path it offers
+// filesystem entries instead.
 func (a *App) rebuildCompletions() {
 	if a.completion == nil {
 		return
@@ -445,6 +609,11 @@ func (a *App) rebuildCompletions() {
 	}
 
 	cursor := ed.GetCursor()
+	if pathPrefix, items, ok := a.pathCompletions(ed, cursor); ok {
+		a.showCompletions(pathPrefix, cursor, items)
+		return
+	}
+
 	prefix, err := a.wordPrefix(ed, cursor)
 	if err != nil || len(prefix) < 2 {
 		a.completion.Cancel()
@@ -461,7 +630,12 @@ func (a *App) rebuildCompletions() {
 		return
 	}
 
-	items := tui.FilterSymbols(prefix, symbols, len(prefix))
+	a.showCompletions(prefix, cursor, tui.FilterSymbols(prefix, symbols, len(prefix)))
+}
+
+// showCompletions opens or updates the completion popup, or hides it when
+// there are no items.
+func (a *App) showCompletions(prefix string, cursor types.Position, items []tui.CompletionItem) {
 	if len(items) == 0 {
 		a.completion.Cancel()
 		return
@@ -475,6 +649,41 @@ func (a *App) rebuildCompletions() {
 	a.requestRedraw()
 }
 
+// completionWords returns the words for Ctrl+N/Ctrl+P completion of prefix:
+// the active buffer is scanned from the cursor line down, wrapping around,
+// then the other open buffers. It closes the completion popup, since inline
+// completion takes over from it.
+func (a *App) completionWords(prefix string) []string {
+	if a.completion != nil {
+		a.completion.Cancel()
+	}
+	active := a.getActiveEditor()
+	if active == nil {
+		return nil
+	}
+	lines := active.GetBuffer().Lines()
+	cursorLine := active.GetCursor().Line
+	if cursorLine > len(lines) {
+		cursorLine = len(lines)
+	}
+	ordered := make([][]byte, 0, len(lines))
+	ordered = append(ordered, lines[cursorLine:]...)
+	ordered = append(ordered, lines[:cursorLine]...)
+
+	seen := map[string]bool{prefix: true}
+	words := find.CollectWords(ordered, prefix, seen)
+	for _, ed := range a.editors {
+		if _, isDir := a.dirViews[ed]; ed == active || isDir {
+			continue
+		}
+		words = append(words, find.CollectWords(ed.GetBuffer().Lines(), prefix, seen)...)
+	}
+	if a.index != nil {
+		words = append(words, a.index.Words(prefix, seen)...)
+	}
+	return words
+}
+
 // wordPrefix returns the identifier-like text immediately before the cursor
 // on the same line.  Returns an empty string if the cursor is at the start
 // of a line or preceded by non-identifier characters.
@@ -527,12 +736,14 @@ func (a *App) updateStatusBarContent() {
 		return
 	}
 	buffer := ed.GetBuffer()
-	a.statusBar.SetFileInfo(buffer.FilePath(), buffer.IsModified())
+	a.statusBar.SetFileInfo(utils.DisplayPath(buffer.FilePath(), config.Get().Editor.PathStyle), buffer.IsModified())
 	a.statusBar.SetCursorInfo(ed.GetCursor())
 
 	// Get mode string and potentially command/find buffer from ModeHandler
 	modeStr := a.modeHandler.GetCurrentModeString()
 	a.statusBar.SetEditorMode(modeStr) // Update the mode display
+	a.statusBar.SetInputState(a.modeHandler.PendingKeys(), a.modeHandler.Recording())
+	a.announceChanges(ed, modeStr)
 
 	// Check if in Command or Find mode to display the buffer in the status bar
 	// Use SetTemporaryMessage to override the default status line
@@ -541,6 +752,8 @@ func (a *App) updateStatusBarContent() {
 		a.statusBar.SetTemporaryMessage(":%s", a.modeHandler.GetCommandBuffer())
 	} else if currentMode == modehandler.ModeFind {
 		a.statusBar.SetTemporaryMessage("/%s", a.modeHandler.GetFindBuffer())
+	} else if currentMode == modehandler.ModeSubstitute {
+		a.statusBar.SetTemporaryMessage("%s", a.modeHandler.GetSubstitutePrompt())
 	}
 	// Note: If not in Command/Find mode, SetTemporaryMessage called elsewhere (e.g., by commands)
 	// will still take effect, or the status bar will show its default content if no temp message is active.
@@ -562,17 +775,21 @@ func (a *App) drawEditor() {
 
 	// Determine if we should draw a tab bar (only with 2+ buffers)
 	multiBuffer := len(a.editors) > 1
-	totalBarHeight := config.StatusBarHeight
-	if multiBuffer {
-		totalBarHeight++ // Extra row for tab bar
-	}
+	totalBarHeight := a.barHeight()
 
-	// Ensure the editor view accounts for all UI rows
-	ed.SetViewSize(w, h-totalBarHeight)
+	// Fit the windows between the top of the screen and the bars
+	a.arrangePanes()
 
 	// Update status bar content *before* drawing anything
 	a.updateStatusBarContent() // Update the status bar with latest info
 
+	// Plugin decorations may move between frames, so repaint every row
+	// underneath them rather than leaving stale cells behind.
+	hooks := a.drawHooks.snapshot()
+	if len(hooks) > 0 {
+		ed.MarkAllDirty()
+	}
+
 	// --- Drawing ---
 	// For a full redraw (e.g. first frame, resize, theme change) clear everything.
 	// For incremental redraws, DrawBuffer handles clearing only dirty rows.
@@ -580,20 +797,33 @@ func (a *App) drawEditor() {
 		a.tuiManager.Clear()
 	}
 
-	// Draw the buffer content (uses dirty-line tracking internally)
-	tui.DrawBuffer(a.tuiManager, ed, a.activeTheme)
+	// Draw the buffer content of each window (uses dirty-line tracking internally)
+	a.drawPanes(screen)
 
 	// Draw tab bar if multiple buffers are open
 	if multiBuffer {
 		a.drawTabBar(screen, w, h-totalBarHeight)
 	}
 
-	// Draw the status bar
-	a.statusBar.Draw(screen, w, h, a.activeTheme)
+	// Draw the command line, above the announcement line if there is one
+	if a.screenReader() {
+		a.statusBar.DrawMessage(screen, h-2, w, a.activeTheme)
+		a.statusBar.DrawAnnouncement(screen, h-1, w, a.activeTheme)
+	} else {
+		a.statusBar.DrawMessage(screen, h-1, w, a.activeTheme)
+	}
+
+	// Plugin draw hooks paint over the text area, below floating windows
+	if len(hooks) > 0 {
+		a.runDrawHooks(screen, hooks)
+	}
 
 	// Draw the cursor (position is calculated relative to buffer draw)
 	// Only draw cursor if not in an overlay that hides it
 	showCursor := true
+	if a.windows != nil {
+		a.windows.Draw(screen, a.activeTheme)
+	}
 	if a.fuzzyFinder != nil && a.fuzzyFinder.IsActive() {
 		showCursor = false
 		a.fuzzyFinder.Draw(screen, a.activeTheme, w, h)
@@ -605,9 +835,15 @@ func (a *App) drawEditor() {
 	if a.completion != nil && a.completion.IsActive() {
 		a.completion.Draw(screen, a.activeTheme, w, h)
 	}
+	if a.confirm != nil && a.confirm.IsActive() {
+		showCursor = false
+		a.confirm.Draw(screen, a.activeTheme, w, h)
+	}
 
+	a.tuiManager.SetTitle(a.windowTitle())
 	if showCursor {
-		tui.DrawCursor(a.tuiManager, ed)
+		a.tuiManager.SetCursorShape(a.cursorShape())
+		tui.DrawCursor(a.tuiManager, ed, a.layout.Focused().Rect)
 	}
 
 	// Refresh the screen to display changes
@@ -629,27 +865,22 @@ func (a *App) drawTabBar(screen tcell.Screen, w, tabY int) {
 
 	x := 0
 	for i, ed := range a.editors {
-		name := ed.GetBuffer().FilePath()
+		name := utils.DisplayPath(ed.GetBuffer().FilePath(), config.Get().Editor.PathStyle)
 		if name == "" {
 			name = "[No Name]"
-		} else {
-			// Shorten to basename
-			for j := len(name) - 1; j >= 0; j-- {
-				if name[j] == '/' || name[j] == '\\' {
-					name = name[j+1:]
-					break
-				}
-			}
 		}
 
-		label := " " + name + " "
 		if ed.GetBuffer().IsModified() {
-			label = " " + name + "* "
+			name += "*"
 		}
+		label := " " + name + " "
 
 		style := inactiveStyle
 		if i == a.activeEditorIndex {
 			style = activeStyle
+			if a.screenReader() {
+				label = "[" + name + "]" // Not by color alone
+			}
 		}
 
 		_ = modifiedStyle // used inline via label suffix above
@@ -665,7 +896,11 @@ func (a *App) drawTabBar(screen tcell.Screen, w, tabY int) {
 
 		// Separator between tabs
 		if i < len(a.editors)-1 && x < w {
-			screen.SetContent(x, tabY, '│', nil, inactiveStyle)
+			sep := '│'
+			if a.screenReader() {
+				sep = '|'
+			}
+			screen.SetContent(x, tabY, sep, nil, inactiveStyle)
 			x++
 		}
 	}
@@ -0,0 +1,27 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/bethropolis/tide/internal/archive"
+	"github.com/bethropolis/tide/internal/buffer"
+	"github.com/bethropolis/tide/internal/types"
+)
+
+// loadArchiveMember fills buf with member of the archive at archivePath
+// and makes it read-only. The buffer keeps filePath, the path through the
+// archive, so its language is detected from the member's name.
+func loadArchiveMember(buf buffer.Buffer, filePath, archivePath, member string) error {
+	buf.SetFilePath(filePath)
+	defer buf.SetReadOnly(true)
+
+	data, err := archive.ReadFile(archivePath, member)
+	if err != nil {
+		return fmt.Errorf("failed to read '%s' from '%s': %w", member, archivePath, err)
+	}
+	if _, err := buf.Insert(types.Position{}, data); err != nil {
+		return err
+	}
+	buf.SetModified(false)
+	return nil
+}
@@ -0,0 +1,80 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bethropolis/tide/internal/core"
+	"github.com/bethropolis/tide/internal/event"
+	"github.com/bethropolis/tide/internal/logger"
+	"github.com/bethropolis/tide/internal/types"
+)
+
+// loadAsync puts the text read returns in ed's empty buffer, running read
+// off the event loop so a slow source (a download) leaves the editor
+// usable, and shows what is being loaded meanwhile. Closing the buffer
+// first cancels the context read gets and drops the text.
+func (a *App) loadAsync(ed *core.Editor, what string, read func(ctx context.Context) ([]byte, error)) {
+	ctx, cancel := context.WithCancel(context.Background())
+	if a.loading == nil {
+		a.loading = make(map[*core.Editor]func())
+	}
+	a.loading[ed] = cancel
+	notice := loadingNotice(what)
+	a.statusBar.SetTemporaryMessage("%s", notice)
+
+	a.goAsync(func() {
+		data, err := read(ctx)
+		a.schedule(func() {
+			if _, open := a.loading[ed]; !open {
+				return // Closed meanwhile
+			}
+			a.stopLoading(ed)
+			if err == nil {
+				err = a.fillLoaded(ed, data)
+			}
+			if err != nil {
+				logger.Warnf("Warning: %v", err)
+				a.statusBar.SetErrorMessage("%v", err)
+			} else if msg, _ := a.statusBar.Message(); msg == notice {
+				a.statusBar.ResetTemporaryMessage()
+			}
+			a.updateStatusBarContent()
+			a.requestRedraw()
+		})
+	})
+}
+
+// loadingNotice is shown while what is being loaded.
+func loadingNotice(what string) string {
+	return fmt.Sprintf("Loading %s…", what)
+}
+
+// stopLoading cancels loading the text of ed, if it is being loaded.
+func (a *App) stopLoading(ed *core.Editor) {
+	if cancel, ok := a.loading[ed]; ok {
+		cancel()
+		delete(a.loading, ed)
+	}
+}
+
+// fillLoaded puts data in ed's buffer as the text it was opened with: the
+// buffer stays as read-only and unmodified as it was, and nothing can be
+// undone.
+func (a *App) fillLoaded(ed *core.Editor, data []byte) error {
+	var edit types.EditInfo
+	var err error
+	a.asActive(ed, func() {
+		if edit, err = appendText(ed.GetBuffer(), data); err == nil {
+			a.eventManager.Dispatch(event.TypeBufferModified, event.BufferModifiedData{Edit: edit, FilePath: ed.GetBuffer().FilePath()})
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if hm := ed.GetHighlightManager(); hm != nil {
+		hm.Rehighlight() // The language may only be known now (loadURL)
+	}
+	ed.MarkAllDirty()
+	return nil
+}
@@ -0,0 +1,60 @@
+// internal/app/backup.go
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/bethropolis/tide/internal/config"
+	"github.com/bethropolis/tide/internal/paths"
+)
+
+// backupDir returns the directory used for unsaved-buffer backups,
+// ~/.local/state/tide/backups on Linux.
+func backupDir() (string, error) {
+	return paths.State(config.BackupsDirName)
+}
+
+// backupUnsavedBuffers writes the content of every modified buffer to a
+// timestamped file in the backup directory. It returns a map of buffer name
+// to backup path for the buffers that were written; failures are collected
+// in the returned error.
+func (a *App) backupUnsavedBuffers() (map[string]string, error) {
+	dir, err := backupDir()
+	if err != nil {
+		return nil, fmt.Errorf("cannot locate backup directory: %w", err)
+	}
+
+	written := make(map[string]string)
+	stamp := time.Now().Format("20060102-150405")
+	var failed []error
+	for i, ed := range a.editors {
+		buf := ed.GetBuffer()
+		if !buf.IsModified() {
+			continue
+		}
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return written, fmt.Errorf("cannot create backup directory: %w", err)
+		}
+
+		name := buf.FilePath()
+		base := filepath.Base(name)
+		if name == "" {
+			name = "[No Name]"
+			base = fmt.Sprintf("unnamed-%d", i+1)
+		}
+		backupPath := filepath.Join(dir, fmt.Sprintf("%s.%s.bak", base, stamp))
+		if err := os.WriteFile(backupPath, buf.Bytes(), 0600); err != nil {
+			failed = append(failed, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		written[name] = backupPath
+	}
+
+	if len(failed) > 0 {
+		return written, fmt.Errorf("failed to back up %d buffer(s): %v", len(failed), failed)
+	}
+	return written, nil
+}
@@ -0,0 +1,127 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/bethropolis/tide/internal/config"
+	"github.com/bethropolis/tide/internal/core"
+	"github.com/bethropolis/tide/internal/event"
+	"github.com/bethropolis/tide/internal/git"
+	"github.com/bethropolis/tide/internal/logger"
+	"github.com/bethropolis/tide/internal/modehandler"
+)
+
+// blameTimeout bounds a single git blame run.
+const blameTimeout = 10 * time.Second
+
+// blameCache holds the git blame of one buffer. revision counts the edits
+// to the buffer, so the lines can be matched to the text they describe.
+type blameCache struct {
+	revision int
+	fetched  int  // Revision lines belong to, -1 before the first run
+	running  bool // A git blame is in flight
+	lines    []git.BlameLine
+}
+
+// blameFor returns the blame cache of ed, creating it on first use.
+func (a *App) blameFor(ed *core.Editor) *blameCache {
+	if a.blame == nil {
+		a.blame = make(map[*core.Editor]*blameCache)
+	}
+	c, ok := a.blame[ed]
+	if !ok {
+		c = &blameCache{fetched: -1}
+		a.blame[ed] = c
+	}
+	return c
+}
+
+// ToggleInlineBlame turns inline blame for the cursor line on or off.
+func (a *App) ToggleInlineBlame() bool {
+	editorCfg := &config.Get().Editor
+	editorCfg.InlineBlame = !editorCfg.InlineBlame
+	if ed := a.getActiveEditor(); ed != nil {
+		if editorCfg.InlineBlame {
+			a.showBlame(ed)
+		} else {
+			ed.ClearLineHint()
+		}
+	}
+	a.requestRedraw()
+	return editorCfg.InlineBlame
+}
+
+// handleCursorMovedForBlame hides the blame of the line the cursor left.
+func (a *App) handleCursorMovedForBlame(e event.Event) bool {
+	if ed := a.getActiveEditor(); ed != nil {
+		ed.ClearLineHint()
+	}
+	return false
+}
+
+// handleCursorHoldForBlame shows the blame of the line the cursor rests on.
+func (a *App) handleCursorHoldForBlame(e event.Event) bool {
+	ed := a.getActiveEditor()
+	if ed == nil || !config.Get().Editor.InlineBlame || a.modeHandler.GetCurrentMode() != modehandler.ModeNormal {
+		return false
+	}
+	if data, ok := e.Data.(event.CursorHoldData); !ok || data.Position != ed.GetCursor() {
+		return false // Cursor moved again before the hold fired
+	}
+	a.showBlame(ed)
+	return false
+}
+
+// handleBufferChangedForBlame drops the blame of the active buffer after it
+// was edited, reloaded or renamed.
+func (a *App) handleBufferChangedForBlame(e event.Event) bool {
+	if ed := a.getActiveEditor(); ed != nil {
+		a.blameFor(ed).revision++
+		ed.ClearLineHint()
+	}
+	return false
+}
+
+// showBlame puts the blame of the cursor line after its end, running git
+// first when the buffer changed since the last run. Files git cannot blame,
+// such as untracked ones, show nothing.
+func (a *App) showBlame(ed *core.Editor) {
+	if _, isDir := a.dirViews[ed]; isDir || ed.GetBuffer().FilePath() == "" {
+		return
+	}
+	c := a.blameFor(ed)
+	if c.fetched == c.revision {
+		if line := ed.GetCursor().Line; line < len(c.lines) {
+			ed.SetLineHint(line, c.lines[line].Describe(time.Now()))
+			a.requestRedraw()
+		}
+		return
+	}
+	if c.running {
+		return // Shown when the run in flight finishes
+	}
+
+	c.running = true
+	revision := c.revision
+	path := ed.GetBuffer().FilePath()
+	text := ed.GetBuffer().Bytes()
+	a.goAsync(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), blameTimeout)
+		defer cancel()
+		lines, err := git.Blame(ctx, path, text)
+		if err != nil {
+			logger.Debugf("Blame: %s: %v", path, err)
+		}
+		a.schedule(func() {
+			c.running = false
+			if c.revision != revision {
+				return // Edited meanwhile; the next hold runs git again
+			}
+			c.lines, c.fetched = lines, revision
+			if a.getActiveEditor() == ed && config.Get().Editor.InlineBlame && a.modeHandler.GetCurrentMode() == modehandler.ModeNormal {
+				a.showBlame(ed)
+			}
+		})
+	})
+}
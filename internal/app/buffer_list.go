@@ -0,0 +1,73 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bethropolis/tide/internal/config"
+	"github.com/bethropolis/tide/internal/tui"
+	"github.com/bethropolis/tide/internal/utils"
+)
+
+// ListBuffers opens the shared picker listing the open buffers by number
+// (:ls). Choosing one switches to it.
+func (a *App) ListBuffers() {
+	if a.picker == nil {
+		return
+	}
+	items := make([]tui.PickerItem, len(a.editors))
+	for i, ed := range a.editors {
+		buf := ed.GetBuffer()
+		name := utils.DisplayPath(buf.FilePath(), config.Get().Editor.PathStyle)
+		if name == "" {
+			name = "[No Name]"
+		}
+		// Flags as in Vim's :ls: % current, + modified, - read-only
+		var flags []string
+		if i == a.activeEditorIndex {
+			flags = append(flags, "%")
+		}
+		if buf.IsModified() {
+			flags = append(flags, "+")
+		}
+		if buf.ReadOnly() {
+			flags = append(flags, "-")
+		}
+		items[i] = tui.PickerItem{
+			Label:       fmt.Sprintf("%d %s", i+1, name),
+			Description: fmt.Sprintf("%s %d lines", strings.Join(flags, ""), buf.LineCount()),
+			Value:       strconv.Itoa(i + 1),
+		}
+	}
+
+	a.picker.Title = "Buffers"
+	a.picker.Items = items
+	a.picker.OnSelect = func(val string) {
+		n, _ := strconv.Atoi(val)
+		if err := a.SwitchBuffer(n); err != nil {
+			a.statusBar.SetTemporaryMessage("%v", err)
+			a.requestRedraw()
+		}
+	}
+	a.picker.OnCancel = nil
+	a.picker.Activate()
+	a.requestRedraw()
+}
+
+// SwitchBuffer makes buffer n (counting from 1, as :ls numbers them) the
+// active one (:b N).
+func (a *App) SwitchBuffer(n int) error {
+	if n < 1 || n > len(a.editors) {
+		return fmt.Errorf("no buffer %d (there are %d)", n, len(a.editors))
+	}
+	a.getActiveEditor().PushJump()
+	a.activeEditorIndex = n - 1
+	if a.modeHandler != nil {
+		a.modeHandler.SetEditor(a.getActiveEditor())
+	}
+	a.getActiveEditor().MarkAllDirty()
+	a.statusBar.SetTemporaryMessage("Buffer %d/%d", n, len(a.editors))
+	a.requestRedraw()
+	return nil
+}
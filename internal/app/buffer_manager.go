@@ -4,11 +4,16 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 
+	"github.com/bethropolis/tide/internal/archive"
 	"github.com/bethropolis/tide/internal/buffer"
 	"github.com/bethropolis/tide/internal/config"
 	"github.com/bethropolis/tide/internal/core"
+	"github.com/bethropolis/tide/internal/event"
 	"github.com/bethropolis/tide/internal/logger"
+	"github.com/bethropolis/tide/internal/preview"
+	"github.com/bethropolis/tide/internal/utils"
 )
 
 func (a *App) getActiveEditor() *core.Editor {
@@ -18,37 +23,224 @@ func (a *App) getActiveEditor() *core.Editor {
 	return a.editors[a.activeEditorIndex]
 }
 
+// newBuffer returns an empty buffer to load filePath into, of the
+// configured backend or of the one its scheme or size calls for.
+func newBuffer(filePath string) buffer.Buffer {
+	e := config.Get().Editor
+	return buffer.ForFile(filePath, e.BufferBackend, int64(e.MmapThreshold)<<20)
+}
+
+// mappedNotice returns the message telling that buf was opened read-only
+// because of its size, if it was.
+func mappedNotice(buf buffer.Buffer) (string, bool) {
+	if _, ok := buf.(*buffer.Mapped); !ok {
+		return "", false
+	}
+	return fmt.Sprintf("%s is memory-mapped and read-only (mmap_threshold); :w other-file saves a copy", filepath.Base(buf.FilePath())), true
+}
+
+// startupNotice returns what to show in place of the welcome message: the
+// mapped notice of the file opened at startup.
+func (a *App) startupNotice() (string, bool) {
+	if ed := a.getActiveEditor(); ed != nil {
+		if _, loading := a.loading[ed]; loading {
+			return loadingNotice(ed.GetBuffer().FilePath()), true
+		}
+		return mappedNotice(ed.GetBuffer())
+	}
+	return "", false
+}
+
 func (a *App) createEditor(filePath string) *core.Editor {
-	buf := buffer.NewPieceTable()
+	if info, err := os.Stat(filePath); err == nil && info.IsDir() {
+		return a.createDirEditor(filePath)
+	}
+	archivePath, member, inArchive := archive.Split(filePath)
+	if inArchive && archive.IsDir(archivePath, member) {
+		return a.createDirEditor(filePath)
+	}
+
+	buf := newBuffer(filePath)
+	_, remote := buffer.Scheme(filePath)
 
-	if filePath != "" {
+	newFile := false
+	var loadLater func(ed *core.Editor) // Reads the text off the event loop once the editor exists
+	if isURL(filePath) && !remote {
+		buf.SetFilePath(filePath)
+		buf.SetReadOnly(true)
+		loadLater = func(ed *core.Editor) { a.loadURL(ed, filePath) }
+	} else if inArchive {
+		if err := loadArchiveMember(buf, filePath, archivePath, member); err != nil {
+			logger.Warnf("Warning: %v", err)
+			a.statusBar.SetErrorMessage("%v", err)
+		}
+	} else if filePath != "" && preview.Detect(filePath) != preview.None {
+		buf.SetFilePath(filePath)
+		buf.SetReadOnly(true)
+		width := max(a.editorArea().Width-8, 16) // Leave room for the gutter
+		loadLater = func(ed *core.Editor) { a.loadPreview(ed, filePath, width) }
+	} else if filePath != "" {
 		err := buf.Load(filePath)
 		if err != nil && !os.IsNotExist(err) {
 			logger.Warnf("Warning: error loading file '%s': %v", filePath, err)
 		}
+		newFile = os.IsNotExist(err)
+	}
+	caps := buffer.CapabilitiesOf(buf)
+	if !caps.Writable {
+		buf.SetReadOnly(true)
+	}
+	if notice, ok := mappedNotice(buf); ok {
+		a.statusBar.SetTemporaryMessage("%s", notice)
 	}
 
 	// Use the event manager so the highlight manager can dispatch TypeHighlightComplete
 	// when a background highlighting pass finishes; the app subscribes to that event
 	// to call MarkAllDirty() + requestRedraw().
 	editor := core.NewEditor(buf, a.highlighterService, a.eventManager)
+	editor.SetJumpList(a.jumps)
+	a.loadMarks(editor)
+	if newFile && config.Get().Editor.Templates {
+		applySkeleton(editor)
+	}
+	// Highlighting a large file takes a while; draw it plain until then.
+	// Text that is not to be parsed has no highlight manager at all.
+	if hm := editor.GetHighlightManager(); hm != nil {
+		hm.Rehighlight()
+	}
+
+	a.sizeView(editor)
+	applyTableView(editor)
+	if loadLater != nil {
+		loadLater(editor)
+	}
+	return editor
+}
+
+// highlightEditor runs a full synchronous highlight pass for the editor,
+// detecting the language from its current file path. Highlights are cleared
+// when no language matches or the buffer's text is not parsed.
+func (a *App) highlightEditor(editor *core.Editor) {
+	hm := editor.GetHighlightManager()
+	if hm == nil {
+		return
+	}
+	buf := editor.GetBuffer()
+	lang, queryBytes := a.highlighterService.GetLanguage(buf.FilePath())
+	if lang == nil || !buffer.CapabilitiesOf(buf).Parsed {
+		hm.ClearHighlights()
+		return
+	}
+	highlights, tree, err := a.highlighterService.HighlightBuffer(context.Background(), buf.Bytes(), lang, queryBytes, nil)
+	if err != nil {
+		logger.Warnf("App: Highlighting '%s' failed: %v", buf.FilePath(), err)
+		hm.ClearHighlights()
+		return
+	}
+	hm.UpdateHighlights(highlights, tree)
+}
+
+// RenameFile moves the active buffer's file to newPath on disk, points the
+// buffer at the new path and re-detects its filetype. Unsaved buffers whose
+// file does not exist yet are simply renamed in memory.
+func (a *App) RenameFile(newPath string) error {
+	ed := a.getActiveEditor()
+	if ed == nil {
+		return fmt.Errorf("no active buffer")
+	}
+	buf := ed.GetBuffer()
+	oldPath := buf.FilePath()
+	if oldPath == "" {
+		return fmt.Errorf("buffer has no file name (use :w <filename>)")
+	}
+	if newPath == "" {
+		return fmt.Errorf("new file name cannot be empty")
+	}
+	if info, err := os.Stat(newPath); err == nil && info.IsDir() {
+		newPath = filepath.Join(newPath, filepath.Base(oldPath))
+	}
+	if newPath == oldPath {
+		return nil
+	}
+	if _, err := os.Stat(newPath); err == nil {
+		return fmt.Errorf("'%s' already exists", newPath)
+	}
+	for _, other := range a.editors {
+		if other != ed && other.GetBuffer().FilePath() == newPath {
+			return fmt.Errorf("'%s' is open in another buffer", newPath)
+		}
+	}
 
-	lang, queryBytes := a.highlighterService.GetLanguage(filePath)
-	if lang != nil {
-		initialCtx := context.Background()
-		initialHighlights, initialTree, _ := a.highlighterService.HighlightBuffer(initialCtx, buf.Bytes(), lang, queryBytes, nil)
-		if hm := editor.GetHighlightManager(); hm != nil {
-			hm.UpdateHighlights(initialHighlights, initialTree)
+	if _, err := os.Stat(oldPath); err == nil {
+		if err := utils.MoveFile(oldPath, newPath); err != nil {
+			return fmt.Errorf("failed to rename '%s': %w", oldPath, err)
 		}
+	} else if !os.IsNotExist(err) {
+		return err
 	}
 
-	w, h := a.tuiManager.Size()
-	editor.SetViewSize(w, h-config.StatusBarHeight)
-	return editor
+	buf.SetFilePath(newPath)
+	a.highlightEditor(ed)
+	ed.MarkAllDirty()
+
+	logger.Infof("Renamed '%s' to '%s'", oldPath, newPath)
+	a.eventManager.Dispatch(event.TypeBufferRenamed, event.BufferRenamedData{OldPath: oldPath, NewPath: newPath})
+	a.requestRedraw()
+	return nil
+}
+
+// DeleteFile removes the active buffer's file from disk, moving it to the
+// trash when toTrash is set. The buffer is closed when other buffers are open
+// and it has no unsaved changes; otherwise it is kept as an unnamed scratch
+// buffer so its content is not lost.
+func (a *App) DeleteFile(toTrash bool) error {
+	ed := a.getActiveEditor()
+	if ed == nil {
+		return fmt.Errorf("no active buffer")
+	}
+	buf := ed.GetBuffer()
+	filePath := buf.FilePath()
+	if filePath == "" {
+		return fmt.Errorf("buffer has no file name")
+	}
+
+	trashPath := ""
+	if toTrash {
+		dst, err := utils.MoveToTrash(filePath)
+		if err != nil {
+			return fmt.Errorf("failed to trash '%s': %w", filePath, err)
+		}
+		trashPath = dst
+	} else if err := os.Remove(filePath); err != nil {
+		return fmt.Errorf("failed to delete '%s': %w", filePath, err)
+	}
+
+	logger.Infof("Deleted '%s' (trash: %q)", filePath, trashPath)
+	a.eventManager.Dispatch(event.TypeFileDeleted, event.FileDeletedData{FilePath: filePath, TrashPath: trashPath})
+
+	if len(a.editors) > 1 && !buf.IsModified() {
+		a.ForceCloseBuffer()
+	} else {
+		buf.SetFilePath("")
+		a.highlightEditor(ed)
+		ed.MarkAllDirty()
+		a.updateStatusBarContent()
+		a.requestRedraw()
+	}
+	return nil
 }
 
-// OpenFile opens a file in a new buffer or switches to it if already open
+// OpenFile opens a file in a new buffer or switches to it if already open.
+// Where the cursor was goes on the jump list.
 func (a *App) OpenFile(filePath string) {
+	if ed := a.getActiveEditor(); ed != nil && ed.GetBuffer().FilePath() != filePath {
+		ed.PushJump()
+	}
+	a.openFile(filePath)
+}
+
+// openFile opens a file in a new buffer or switches to it if already open
+func (a *App) openFile(filePath string) {
 	// Check if already open
 	for i, ed := range a.editors {
 		if ed.GetBuffer().FilePath() == filePath {
@@ -64,6 +256,7 @@ func (a *App) OpenFile(filePath string) {
 
 	// Create new editor
 	newEd := a.createEditor(filePath)
+	a.autoLoadView(newEd)
 	a.editors = append(a.editors, newEd)
 	a.activeEditorIndex = len(a.editors) - 1
 	if a.modeHandler != nil {
@@ -118,11 +311,24 @@ func (a *App) CloseBuffer() error {
 // ForceCloseBuffer closes the active buffer without checking for modifications
 func (a *App) ForceCloseBuffer() {
 	if len(a.editors) <= 1 {
-		a.quit <- struct{}{}
+		a.signalQuit()
 		return
 	}
 
+	a.autoSaveView(a.editors[a.activeEditorIndex])
+
 	// Remove from slice
+	delete(a.dirViews, a.editors[a.activeEditorIndex])
+	delete(a.blame, a.editors[a.activeEditorIndex])
+	delete(a.savedDiffs, a.editors[a.activeEditorIndex])
+	a.stopTail(a.editors[a.activeEditorIndex])
+	a.stopLoading(a.editors[a.activeEditorIndex])
+	if a.collab != nil && a.collab.ed == a.editors[a.activeEditorIndex] {
+		a.stopCollab("Collab: shared buffer closed; session ended")
+	}
+	if err := buffer.Close(a.editors[a.activeEditorIndex].GetBuffer()); err != nil {
+		logger.Warnf("Closing buffer: %v", err)
+	}
 	a.editors = append(a.editors[:a.activeEditorIndex], a.editors[a.activeEditorIndex+1:]...)
 	if a.activeEditorIndex >= len(a.editors) {
 		a.activeEditorIndex = len(a.editors) - 1
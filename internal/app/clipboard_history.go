@@ -0,0 +1,80 @@
+// internal/app/clipboard_history.go
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bethropolis/tide/internal/core/clipboard"
+	"github.com/bethropolis/tide/internal/tui"
+)
+
+// clipboardPreviewWidth caps the preview text shown for each entry.
+const clipboardPreviewWidth = 60
+
+// showClipboardHistory opens the shared picker listing the yank ring, newest
+// first. Choosing an entry pastes it after the cursor.
+func (a *App) showClipboardHistory() {
+	if a.picker == nil {
+		return
+	}
+	entries := clipboard.History().Entries()
+	if len(entries) == 0 {
+		a.statusBar.SetTemporaryMessage("Clipboard history is empty")
+		a.requestRedraw()
+		return
+	}
+
+	items := make([]tui.PickerItem, len(entries))
+	for i, entry := range entries {
+		text := entry.Text
+		lines := bytes.Count(text, []byte("\n"))
+		if len(text) > 0 && text[len(text)-1] != '\n' {
+			lines++
+		}
+		kind := "chars"
+		if entry.Linewise {
+			kind = "lines"
+		} else if entry.Blockwise {
+			kind = "block"
+		}
+		items[i] = tui.PickerItem{
+			Label:       clipboardPreview(text),
+			Description: fmt.Sprintf("%s, %d line(s), %d bytes", kind, lines, len(text)),
+			Value:       strconv.Itoa(i),
+		}
+	}
+
+	a.picker.Title = "Clipboard History"
+	a.picker.Items = items
+	a.picker.OnSelect = func(val string) {
+		i, err := strconv.Atoi(val)
+		if err != nil || i < 0 || i >= len(entries) {
+			return
+		}
+		if _, err := a.getActiveEditor().PasteFromHistory(entries[i]); err != nil {
+			a.statusBar.SetTemporaryMessage("Paste failed: %v", err)
+		} else {
+			a.statusBar.SetTemporaryMessage("Pasted clipboard entry %d", i+1)
+		}
+		a.requestRedraw()
+	}
+	a.picker.OnCancel = nil
+	a.picker.Activate()
+	a.requestRedraw()
+}
+
+// clipboardPreview flattens text to a single line: newlines are shown as
+// "⏎", tabs as spaces, and the result is cut to clipboardPreviewWidth runes.
+func clipboardPreview(text []byte) string {
+	s := strings.TrimRight(string(text), "\n")
+	s = strings.ReplaceAll(s, "\t", " ")
+	s = strings.ReplaceAll(s, "\n", " ⏎ ")
+	if utf8.RuneCountInString(s) > clipboardPreviewWidth {
+		s = string([]rune(s)[:clipboardPreviewWidth-1]) + "…"
+	}
+	return s
+}
@@ -0,0 +1,145 @@
+// internal/app/code_actions.go
+package app
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/bethropolis/tide/internal/event"
+	"github.com/bethropolis/tide/internal/lsp"
+	"github.com/bethropolis/tide/internal/tui"
+)
+
+// codeActionResult is what the server offered for the cursor position.
+type codeActionResult struct {
+	client      *lsp.Client
+	actions     []lsp.CodeAction
+	diagnostics []lsp.Diagnostic // Diagnostics on the cursor line
+}
+
+// handleTriggerCodeActions asks the language server for the code actions at
+// the cursor (<leader>a), adds the editor's built-in fixes for diagnostics
+// on the cursor line, and offers them in a menu at the cursor.
+func (a *App) handleTriggerCodeActions(e event.Event) bool {
+	ed := a.getActiveEditor()
+	if ed == nil {
+		return false
+	}
+	pos, err := cursorLSPPosition(ed)
+	if err != nil {
+		return false
+	}
+	anchor := ed.GetCursor()
+
+	err = a.lspRequest(func(ctx context.Context, c *lsp.Client, path string) (interface{}, error) {
+		p := pos(c.Encoding())
+		var near []lsp.Diagnostic
+		for _, d := range c.Diagnostics(path) {
+			if d.Range.Start.Line <= p.Line && p.Line <= d.Range.End.Line {
+				near = append(near, d)
+			}
+		}
+		actions, err := c.CodeActions(ctx, path, lsp.Range{Start: p, End: p}, near)
+		return &codeActionResult{client: c, actions: actions, diagnostics: near}, err
+	}, func(result interface{}, docs map[string]*lspDocument, err error) {
+		if err != nil {
+			a.statusBar.SetTemporaryMessage("Code actions failed: %v", err)
+			return
+		}
+		if a.getActiveEditor() != ed || ed.GetCursor() != anchor {
+			return // The cursor has moved on meanwhile
+		}
+		res := result.(*codeActionResult)
+		actions := res.actions
+		builtin := len(actions)
+		for path, doc := range docs {
+			if doc.editor == ed {
+				actions = append(actions, lsp.BuiltinFixes(path, doc.text, res.diagnostics, res.client.Encoding())...)
+			}
+		}
+		if len(actions) == 0 {
+			a.statusBar.SetTemporaryMessage("No code actions at the cursor")
+			return
+		}
+		a.showCodeActions(actions, builtin, res.client, docs)
+	})
+	if err != nil {
+		a.statusBar.SetTemporaryMessage("%v", err)
+	}
+	a.requestRedraw()
+	return false
+}
+
+// showCodeActions lists actions in the shared picker next to the cursor.
+// Actions from index builtin on are the editor's own fixes.
+func (a *App) showCodeActions(actions []lsp.CodeAction, builtin int, c *lsp.Client, docs map[string]*lspDocument) {
+	items := make([]tui.PickerItem, len(actions))
+	for i, action := range actions {
+		desc := action.Kind
+		if i >= builtin {
+			desc = "built-in"
+		} else if action.IsPreferred {
+			desc += " (preferred)"
+		}
+		items[i] = tui.PickerItem{Label: action.Title, Description: desc, Value: strconv.Itoa(i)}
+	}
+
+	a.picker.Title = "Code Actions"
+	a.picker.Items = items
+	a.picker.OnSelect = func(val string) {
+		i, err := strconv.Atoi(val)
+		if err != nil || i < 0 || i >= len(actions) {
+			return
+		}
+		a.runCodeAction(actions[i], c, docs)
+	}
+	a.picker.OnCancel = nil
+	a.picker.ActivateAt(a.cursorCell())
+	a.requestRedraw()
+}
+
+// runCodeAction applies the edit of the chosen action, first asking the
+// server for it if the action came without one.
+func (a *App) runCodeAction(action lsp.CodeAction, c *lsp.Client, docs map[string]*lspDocument) {
+	if action.Edit != nil {
+		a.applyCodeAction(action, c.Encoding(), docs)
+		return
+	}
+	a.goAsync(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), lspRequestTimeout)
+		defer cancel()
+		resolved, err := c.ResolveCodeAction(ctx, action)
+		a.schedule(func() {
+			if err == nil && resolved.Edit == nil {
+				err = fmt.Errorf("the server sent no edit")
+			}
+			if err != nil {
+				a.statusBar.SetTemporaryMessage("%s: %v", action.Title, err)
+				return
+			}
+			a.applyCodeAction(resolved, c.Encoding(), docs)
+		})
+	})
+}
+
+// applyCodeAction applies a resolved action's edit, one undo step per buffer.
+func (a *App) applyCodeAction(action lsp.CodeAction, enc lsp.Encoding, docs map[string]*lspDocument) {
+	targets, err := editTargets(action.Edit, docs)
+	if err != nil {
+		a.statusBar.SetTemporaryMessage("%s: %v", action.Title, err)
+		return
+	}
+	edited, skipped, failed := a.applyEditTargets(targets, enc)
+	switch {
+	case failed > 0:
+		a.statusBar.SetTemporaryMessage("%s: failed in %d file(s) (see log)", action.Title, failed)
+	case skipped > 0:
+		a.statusBar.SetTemporaryMessage("%s: buffer changed since the request, not applied", action.Title)
+	case edited == 0:
+		a.statusBar.SetTemporaryMessage("%s: nothing to change", action.Title)
+	default:
+		a.statusBar.SetTemporaryMessage("Applied: %s", action.Title)
+	}
+	a.requestRedraw()
+}
@@ -0,0 +1,375 @@
+package app
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/bethropolis/tide/internal/collab"
+	"github.com/bethropolis/tide/internal/config"
+	"github.com/bethropolis/tide/internal/core"
+	"github.com/bethropolis/tide/internal/core/cursor"
+	"github.com/bethropolis/tide/internal/core/history"
+	"github.com/bethropolis/tide/internal/event"
+	"github.com/bethropolis/tide/internal/logger"
+	"github.com/bethropolis/tide/internal/plugin"
+	"github.com/bethropolis/tide/internal/types"
+	"github.com/gdamore/tcell/v2"
+)
+
+// DefaultCollabAddr is where :collab host listens without an address. It
+// only takes connections from this machine; give an address such as
+// ":7878" to let others join.
+const DefaultCollabAddr = "127.0.0.1:7878"
+
+// collabSession is the buffer shared with :collab host or :collab join.
+type collabSession struct {
+	ed       *core.Editor
+	addr     string
+	listener net.Listener    // Set while hosting
+	session  *collab.Session // Set once the other side is connected
+	peer     *collab.Peer    // Set once the texts match on both sides
+	applying bool            // Applying a remote edit, which must not be sent back
+	remote   int             // Byte offset of the other side's cursor, -1 before it is known
+	hook     plugin.DrawHookID
+}
+
+// CollabHost shares the active buffer, waiting on addr for one other tide
+// to join with CollabJoin (:collab host).
+func (a *App) CollabHost(addr string) error {
+	ed := a.getActiveEditor()
+	if ed == nil {
+		return fmt.Errorf("no buffer to share")
+	}
+	if a.collab != nil {
+		return fmt.Errorf("already in a session (:collab stop ends it)")
+	}
+	if addr == "" {
+		addr = DefaultCollabAddr
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	c := &collabSession{ed: ed, addr: ln.Addr().String(), listener: ln, remote: -1}
+	a.collab = c
+
+	a.goAsync(func() {
+		conn, err := ln.Accept()
+		a.schedule(func() {
+			if a.collab != c {
+				if err == nil {
+					conn.Close()
+				}
+				return
+			}
+			if err != nil {
+				a.stopCollab(fmt.Sprintf("Collab: %v", err))
+				return
+			}
+			ln.Close() // One peer per session
+			c.session = a.startCollabSession(c, conn)
+			c.peer = collab.NewPeer(true)
+			c.session.Send(collab.Message{Type: collab.MsgSnapshot, Text: string(ed.GetBuffer().Bytes())})
+			a.sendCollabCursor(c)
+			a.statusBar.SetTemporaryMessage("Collab: %s joined", c.session.RemoteAddr())
+			a.requestRedraw()
+		})
+	})
+	a.statusBar.SetTemporaryMessage("Collab: sharing on %s, waiting for a peer", c.addr)
+	return nil
+}
+
+// CollabJoin connects to a tide sharing a buffer with CollabHost and puts
+// its text into the active buffer (:collab join).
+func (a *App) CollabJoin(addr string) error {
+	ed := a.getActiveEditor()
+	if ed == nil {
+		return fmt.Errorf("no buffer to edit in")
+	}
+	if a.collab != nil {
+		return fmt.Errorf("already in a session (:collab stop ends it)")
+	}
+	if ed.GetBuffer().ReadOnly() {
+		return fmt.Errorf("buffer is read-only")
+	}
+	if addr == "" {
+		return fmt.Errorf("usage: :collab join host:port")
+	}
+	c := &collabSession{ed: ed, addr: addr, remote: -1}
+	a.collab = c
+
+	a.goAsync(func() {
+		conn, err := net.DialTimeout("tcp", addr, 10*time.Second)
+		a.schedule(func() {
+			if a.collab != c {
+				if err == nil {
+					conn.Close()
+				}
+				return
+			}
+			if err != nil {
+				a.stopCollab(fmt.Sprintf("Collab: %v", err))
+				return
+			}
+			c.session = a.startCollabSession(c, conn)
+		})
+	})
+	a.statusBar.SetTemporaryMessage("Collab: connecting to %s...", addr)
+	return nil
+}
+
+// StopCollab ends the session, keeping the text as it is (:collab stop).
+func (a *App) StopCollab() {
+	if a.collab == nil {
+		a.statusBar.SetTemporaryMessage("Collab: not in a session")
+		return
+	}
+	a.stopCollab("Collab: session ended")
+}
+
+// CollabStatus describes the session, or returns "" outside of one.
+func (a *App) CollabStatus() string {
+	c := a.collab
+	switch {
+	case c == nil:
+		return ""
+	case c.session == nil && c.listener != nil:
+		return fmt.Sprintf("sharing on %s, waiting for a peer", c.addr)
+	case c.session == nil || c.peer == nil:
+		return fmt.Sprintf("connecting to %s", c.addr)
+	default:
+		return fmt.Sprintf("editing with %s", c.session.RemoteAddr())
+	}
+}
+
+// startCollabSession runs the session over conn, handling its messages on
+// the event loop.
+func (a *App) startCollabSession(c *collabSession, conn net.Conn) *collab.Session {
+	c.hook = a.drawHooks.add(func(screen tcell.Screen, geo plugin.Geometry) {
+		a.drawRemoteCursor(screen, geo, c)
+	})
+	return collab.NewSession(conn,
+		func(m collab.Message) {
+			a.schedule(func() {
+				if a.collab == c {
+					a.handleCollabMessage(c, m)
+				}
+			})
+		},
+		func(err error) {
+			a.schedule(func() {
+				if a.collab != c {
+					return
+				}
+				if err != nil {
+					a.stopCollab(fmt.Sprintf("Collab: %v", err))
+				} else {
+					a.stopCollab("Collab: the other side left")
+				}
+			})
+		})
+}
+
+// stopCollab closes the session and shows msg.
+func (a *App) stopCollab(msg string) {
+	c := a.collab
+	if c == nil {
+		return
+	}
+	a.collab = nil
+	if c.listener != nil {
+		c.listener.Close()
+	}
+	if c.session != nil {
+		c.session.Close()
+		a.drawHooks.remove(c.hook)
+	}
+	a.statusBar.SetTemporaryMessage("%s", msg)
+	a.requestRedraw()
+}
+
+// handleCollabMessage applies a message from the other side.
+func (a *App) handleCollabMessage(c *collabSession, m collab.Message) {
+	switch m.Type {
+	case collab.MsgSnapshot:
+		if c.peer != nil {
+			return
+		}
+		if err := a.applyCollabSnapshot(c, []byte(m.Text)); err != nil {
+			a.stopCollab(fmt.Sprintf("Collab: %v", err))
+			return
+		}
+		c.peer = collab.NewPeer(false)
+		a.sendCollabCursor(c)
+		a.statusBar.SetTemporaryMessage("Collab: editing with %s", c.session.RemoteAddr())
+	case collab.MsgOp:
+		if c.peer == nil {
+			return
+		}
+		op := c.peer.Remote(m)
+		if err := a.applyCollabOp(c, op); err != nil {
+			a.stopCollab(fmt.Sprintf("Collab: the texts no longer match (%v); session ended", err))
+			return
+		}
+		if c.remote >= 0 {
+			c.remote = collab.TransformCursor(c.remote, op)
+		}
+	case collab.MsgCursor:
+		if c.peer == nil {
+			return
+		}
+		c.remote = c.peer.RemoteCursor(m)
+	default:
+		logger.Debugf("Collab: ignoring message of type %q", m.Type)
+		return
+	}
+	a.requestRedraw()
+}
+
+// applyCollabSnapshot replaces the text of the shared buffer with the
+// host's, as one undoable change.
+func (a *App) applyCollabSnapshot(c *collabSession, text []byte) error {
+	current := c.ed.GetBuffer().Bytes()
+	end := collab.PositionAt(current, len(current))
+	c.applying = true
+	defer func() { c.applying = false }()
+	var err error
+	a.asActive(c.ed, func() { _, err = c.ed.ReplaceRange(types.Position{}, end, text) })
+	if err != nil {
+		return err
+	}
+	c.ed.SetCursor(types.Position{})
+	c.ed.ScrollToCursor()
+	c.ed.MarkAllDirty()
+	return nil
+}
+
+// applyCollabOp applies an edit from the other side to the shared buffer,
+// recording it for undo and keeping the local cursor on the same text.
+func (a *App) applyCollabOp(c *collabSession, op collab.Op) error {
+	if op.Noop() {
+		return nil
+	}
+	buf := c.ed.GetBuffer()
+	text := buf.Bytes()
+	if op.Pos+op.Del > len(text) {
+		return fmt.Errorf("edit at byte %d is past the end", op.Pos)
+	}
+	cursorBefore := c.ed.GetCursor()
+	cursorOffset := collab.OffsetOf(text, cursorBefore)
+
+	start := collab.PositionAt(text, op.Pos)
+	change := history.Change{Type: history.InsertAction, Text: []byte(op.Ins), StartPosition: start, CursorBefore: cursorBefore}
+	var edit types.EditInfo
+	var err error
+	if op.Ins != "" {
+		edit, err = buf.Insert(start, change.Text)
+		change.EndPosition = collab.PositionAt(op.Apply(text), op.Pos+len(op.Ins))
+	} else {
+		change.Type = history.DeleteAction
+		change.Text = text[op.Pos : op.Pos+op.Del]
+		change.EndPosition = collab.PositionAt(text, op.Pos+op.Del)
+		edit, err = buf.Delete(start, change.EndPosition)
+	}
+	if err != nil {
+		return err
+	}
+
+	c.applying = true
+	defer func() { c.applying = false }()
+	if hist := c.ed.GetHistoryManager(); hist != nil {
+		if !hist.InTransaction() {
+			hist.BeginTransaction()
+			defer hist.EndTransaction(cursorBefore)
+		}
+		hist.RecordChange(change)
+	}
+	a.asActive(c.ed, func() {
+		a.eventManager.Dispatch(event.TypeBufferModified, event.BufferModifiedData{Edit: edit, FilePath: c.ed.GetBuffer().FilePath()})
+	})
+
+	c.ed.SetCursor(collab.PositionAt(buf.Bytes(), collab.TransformCursor(cursorOffset, op)))
+	c.ed.MarkAllDirty()
+	return nil
+}
+
+// handleBufferModifiedForCollab sends edits of the shared buffer to the
+// other side, a replacement as a deletion followed by an insertion. The
+// buffer is told by its file, so edits made while another one is active
+// (plugins, language servers) are sent too; an unnamed buffer can only be
+// told by being the active one.
+func (a *App) handleBufferModifiedForCollab(e event.Event) bool {
+	c := a.collab
+	if c == nil || c.peer == nil || c.applying {
+		return false
+	}
+	data, ok := e.Data.(event.BufferModifiedData)
+	if !ok {
+		return false
+	}
+	if path := c.ed.GetBuffer().FilePath(); path != "" && data.FilePath != path {
+		return false
+	} else if path == "" && a.getActiveEditor() != c.ed {
+		return false
+	}
+	edit := data.Edit
+	start := int(edit.StartIndex)
+	var ops []collab.Op
+	if edit.OldEndIndex > edit.StartIndex {
+		ops = append(ops, collab.Op{Pos: start, Del: int(edit.OldEndIndex - edit.StartIndex)})
+	}
+	if edit.NewEndIndex > edit.StartIndex {
+		text := c.ed.GetBuffer().Bytes()
+		if int(edit.NewEndIndex) > len(text) {
+			a.stopCollab("Collab: lost track of an edit; session ended")
+			return false
+		}
+		ops = append(ops, collab.Op{Pos: start, Ins: string(text[start:edit.NewEndIndex])})
+	}
+	for _, op := range ops {
+		c.session.Send(c.peer.Local(op))
+		if c.remote >= 0 {
+			c.remote = collab.TransformCursor(c.remote, op)
+		}
+	}
+	return false
+}
+
+// handleCursorMovedForCollab tells the other side where the cursor is.
+func (a *App) handleCursorMovedForCollab(e event.Event) bool {
+	if c := a.collab; c != nil && c.peer != nil && a.getActiveEditor() == c.ed {
+		a.sendCollabCursor(c)
+	}
+	return false
+}
+
+func (a *App) sendCollabCursor(c *collabSession) {
+	offset := collab.LineOffset(c.ed.GetBuffer().Line, c.ed.GetCursor())
+	c.session.Send(c.peer.Cursor(offset))
+}
+
+// drawRemoteCursor shows the other side's cursor as a reversed cell when
+// the shared buffer is in the active window.
+func (a *App) drawRemoteCursor(screen tcell.Screen, geo plugin.Geometry, c *collabSession) {
+	if a.collab != c || c.remote < 0 || a.getActiveEditor() != c.ed {
+		return
+	}
+	buf := c.ed.GetBuffer()
+	pos := collab.LinePosition(buf.Line, c.remote)
+	row, visible := geo.ScreenRow(pos.Line)
+	if !visible {
+		return
+	}
+	line, err := buf.Line(pos.Line)
+	if err != nil {
+		return
+	}
+	col := cursor.GetVisualCol(string(line), pos.Col, config.Get().Editor.TabWidth) - geo.ViewportX
+	if col < 0 || geo.GutterWidth+col >= geo.Editor.Width {
+		return
+	}
+	x := geo.Editor.X + geo.GutterWidth + col
+	r, combining, style, _ := screen.GetContent(x, row)
+	screen.SetContent(x, row, r, combining, style.Reverse(true))
+}
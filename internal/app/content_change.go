@@ -0,0 +1,47 @@
+package app
+
+import (
+	"time"
+
+	"github.com/bethropolis/tide/internal/config"
+	"github.com/bethropolis/tide/internal/core"
+	"github.com/bethropolis/tide/internal/event"
+)
+
+// handleBufferModifiedForContentChange sends TypeContentChanged for the
+// edited buffer once content_change_interval passes. Edits made meanwhile
+// ride along with it, so plugins mirroring the text get it at most once
+// per interval however fast the typing, and always get the last edit.
+func (a *App) handleBufferModifiedForContentChange(e event.Event) bool {
+	interval := config.Get().Editor.ContentChangeInterval
+	ed := a.getActiveEditor()
+	if interval <= 0 || ed == nil || !a.eventManager.HasSubscribers(event.TypeContentChanged) {
+		return false
+	}
+	if a.contentChanged == nil {
+		a.contentChanged = make(map[*core.Editor]bool)
+	}
+	if len(a.contentChanged) == 0 {
+		time.AfterFunc(time.Duration(interval)*time.Millisecond, func() {
+			a.schedule(a.dispatchContentChanged)
+		})
+	}
+	a.contentChanged[ed] = true
+	return false
+}
+
+// dispatchContentChanged sends TypeContentChanged for each buffer edited
+// since the last one that is still open.
+func (a *App) dispatchContentChanged() {
+	edited := a.contentChanged
+	a.contentChanged = nil
+	for _, ed := range a.editors {
+		if edited[ed] {
+			buf := ed.GetBuffer()
+			a.eventManager.Dispatch(event.TypeContentChanged, event.ContentChangedData{
+				FilePath: buf.FilePath(),
+				Text:     buf.Bytes(),
+			})
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package app
+
+import (
+	"github.com/bethropolis/tide/internal/config"
+	"github.com/bethropolis/tide/internal/logger"
+	"github.com/bethropolis/tide/internal/modehandler"
+	"github.com/bethropolis/tide/internal/tui"
+	"github.com/gdamore/tcell/v2"
+)
+
+// cursorShape returns the configured cursor shape for the current mode.
+func (a *App) cursorShape() tcell.CursorStyle {
+	key := "normal"
+	switch a.modeHandler.GetCurrentMode() {
+	case modehandler.ModeInsert:
+		key = "insert"
+	case modehandler.ModeVisual, modehandler.ModeVisualLine, modehandler.ModeVisualBlock:
+		key = "visual"
+	case modehandler.ModeCommand, modehandler.ModeFind:
+		key = "command"
+	}
+
+	shapes := config.Get().Editor.CursorShape
+	name, ok := shapes[key]
+	if !ok {
+		name = shapes["normal"]
+	}
+	shape, ok := tui.ParseCursorShape(name)
+	if !ok {
+		logger.DebugTagf("tui", "Unknown cursor_shape %q for %s mode", name, key)
+	}
+	if config.Get().UI.ReduceMotion {
+		shape = tui.SteadyCursorShape(shape)
+	}
+	return shape
+}
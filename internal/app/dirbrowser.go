@@ -0,0 +1,259 @@
+// internal/app/dirbrowser.go
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bethropolis/tide/internal/archive"
+	"github.com/bethropolis/tide/internal/buffer"
+	"github.com/bethropolis/tide/internal/core"
+	"github.com/bethropolis/tide/internal/logger"
+	"github.com/bethropolis/tide/internal/modehandler"
+	"github.com/bethropolis/tide/internal/types"
+	"github.com/gdamore/tcell/v2"
+)
+
+// dirSortMode selects how a directory listing is ordered.
+type dirSortMode int
+
+const (
+	dirSortName dirSortMode = iota
+	dirSortSize
+	dirSortTime
+)
+
+func (m dirSortMode) String() string {
+	switch m {
+	case dirSortSize:
+		return "size"
+	case dirSortTime:
+		return "time"
+	default:
+		return "name"
+	}
+}
+
+// dirHeaderLines is the number of comment lines above the first entry.
+const dirHeaderLines = 2
+
+// dirView holds the state of a directory-listing buffer (netrw-style). The
+// directory may be one inside an archive, whose path runs through the
+// archive file (project.zip/cmd).
+// The buffer text is generated from entries; line dirHeaderLines+i shows
+// entries[i].
+type dirView struct {
+	path       string
+	showHidden bool
+	sortMode   dirSortMode
+	entries    []dirEntry
+	buf        *buffer.PieceTable
+}
+
+type dirEntry struct {
+	name  string
+	isDir bool
+	info  os.FileInfo
+}
+
+// createDirEditor builds an editor whose buffer lists the directory at path.
+func (a *App) createDirEditor(path string) *core.Editor {
+	buf := buffer.NewPieceTable()
+	editor := core.NewEditor(buf, a.highlighterService, a.eventManager)
+	editor.SetJumpList(a.jumps)
+
+	view := &dirView{buf: buf}
+	if a.dirViews == nil {
+		a.dirViews = make(map[*core.Editor]*dirView)
+	}
+	a.dirViews[editor] = view
+	if err := a.browseDirectory(editor, view, path); err != nil {
+		logger.Warnf("Warning: error listing directory '%s': %v", path, err)
+	}
+
+	a.sizeView(editor)
+	return editor
+}
+
+// browseDirectory (re)reads path into view and regenerates the buffer text.
+func (a *App) browseDirectory(ed *core.Editor, view *dirView, path string) error {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	listed, err := readDirEntries(absPath, view.showHidden)
+	if err != nil {
+		return err
+	}
+
+	view.path = absPath
+	view.entries = view.entries[:0]
+	if parent := filepath.Dir(absPath); parent != absPath {
+		view.entries = append(view.entries, dirEntry{name: "..", isDir: true})
+	}
+
+	sortDirEntries(listed, view.sortMode)
+	view.entries = append(view.entries, listed...)
+
+	var sb strings.Builder
+	hidden := "off"
+	if view.showHidden {
+		hidden = "on"
+	}
+	fmt.Fprintf(&sb, "\" %s%c  (sort: %s, hidden: %s)\n", absPath, filepath.Separator, view.sortMode, hidden)
+	sb.WriteString("\" Enter: open  -: parent  s: sort  .: hidden  R: refresh\n")
+	for i, e := range view.entries {
+		sb.WriteString(e.name)
+		if e.isDir {
+			sb.WriteByte('/')
+		}
+		if i < len(view.entries)-1 {
+			sb.WriteByte('\n')
+		}
+	}
+
+	view.buf.SetContent([]byte(sb.String())) // Unmodified, so nothing offers to save or back it up
+	view.buf.SetFilePath(absPath)
+	view.buf.SetReadOnly(true) // A stray key must not edit the listing
+	ed.ClearHistory()
+	ed.SetCursor(types.Position{Line: dirHeaderLines, Col: 0})
+	ed.MarkAllDirty()
+	return nil
+}
+
+// readDirEntries lists the directory at absPath, which may also be a
+// directory inside an archive (or the archive itself).
+func readDirEntries(absPath string, showHidden bool) ([]dirEntry, error) {
+	if archivePath, member, ok := archive.Split(absPath); ok {
+		infos, err := archive.List(archivePath, member)
+		if err != nil {
+			return nil, err
+		}
+		var listed []dirEntry
+		for _, info := range infos {
+			if !showHidden && strings.HasPrefix(info.Name(), ".") {
+				continue
+			}
+			listed = append(listed, dirEntry{name: info.Name(), isDir: info.IsDir(), info: info})
+		}
+		return listed, nil
+	}
+
+	dirEntries, err := os.ReadDir(absPath)
+	if err != nil {
+		return nil, err
+	}
+	var listed []dirEntry
+	for _, de := range dirEntries {
+		if !showHidden && strings.HasPrefix(de.Name(), ".") {
+			continue
+		}
+		info, err := de.Info()
+		if err != nil {
+			continue // Entry vanished between ReadDir and Info
+		}
+		isDir := de.IsDir()
+		if info.Mode()&os.ModeSymlink != 0 {
+			if target, err := os.Stat(filepath.Join(absPath, de.Name())); err == nil {
+				isDir = target.IsDir()
+			}
+		}
+		listed = append(listed, dirEntry{name: de.Name(), isDir: isDir, info: info})
+	}
+	return listed, nil
+}
+
+// sortDirEntries orders directories before files, then by the chosen key.
+// Size and time sort largest/newest first.
+func sortDirEntries(entries []dirEntry, mode dirSortMode) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.isDir != b.isDir {
+			return a.isDir
+		}
+		switch mode {
+		case dirSortSize:
+			if a.info.Size() != b.info.Size() {
+				return a.info.Size() > b.info.Size()
+			}
+		case dirSortTime:
+			if !a.info.ModTime().Equal(b.info.ModTime()) {
+				return a.info.ModTime().After(b.info.ModTime())
+			}
+		}
+		return strings.ToLower(a.name) < strings.ToLower(b.name)
+	})
+}
+
+// handleDirViewKey handles browse-mode keys when the active buffer is a
+// directory listing in normal mode. Returns true if the key was consumed.
+func (a *App) handleDirViewKey(ev *tcell.EventKey) bool {
+	ed := a.getActiveEditor()
+	view, ok := a.dirViews[ed]
+	if !ok || a.modeHandler.GetCurrentMode() != modehandler.ModeNormal {
+		return false
+	}
+
+	var err error
+	switch {
+	case ev.Key() == tcell.KeyEnter:
+		line := ed.GetCursor().Line - dirHeaderLines
+		if line < 0 || line >= len(view.entries) {
+			return true
+		}
+		entry := view.entries[line]
+		target := filepath.Join(view.path, entry.name)
+		if entry.isDir {
+			err = a.browseDirectory(ed, view, target)
+		} else {
+			a.openFromDirView(ed, target)
+		}
+	case ev.Key() == tcell.KeyRune && ev.Rune() == '-':
+		err = a.browseDirectory(ed, view, filepath.Dir(view.path))
+	case ev.Key() == tcell.KeyRune && ev.Rune() == 's':
+		view.sortMode = (view.sortMode + 1) % 3
+		err = a.browseDirectory(ed, view, view.path)
+	case ev.Key() == tcell.KeyRune && ev.Rune() == '.':
+		view.showHidden = !view.showHidden
+		err = a.browseDirectory(ed, view, view.path)
+	case ev.Key() == tcell.KeyRune && ev.Rune() == 'R':
+		err = a.browseDirectory(ed, view, view.path)
+	default:
+		return false
+	}
+
+	if err != nil {
+		a.statusBar.SetTemporaryMessage("Cannot open directory: %v", err)
+	}
+	a.updateStatusBarContent()
+	return true
+}
+
+// openFromDirView replaces the directory buffer with the chosen file, the
+// way netrw does. If the file is already open the existing buffer is used.
+func (a *App) openFromDirView(dirEd *core.Editor, filePath string) {
+	for _, ed := range a.editors {
+		if ed.GetBuffer().FilePath() == filePath {
+			a.OpenFile(filePath) // switches to it
+			return
+		}
+	}
+
+	newEd := a.createEditor(filePath)
+	for i, ed := range a.editors {
+		if ed == dirEd {
+			a.editors[i] = newEd
+			a.activeEditorIndex = i
+			break
+		}
+	}
+	delete(a.dirViews, dirEd)
+	if a.modeHandler != nil {
+		a.modeHandler.SetEditor(newEd)
+	}
+	newEd.MarkAllDirty()
+	a.statusBar.SetTemporaryMessage("Opened %s", filePath)
+}
@@ -0,0 +1,264 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/bethropolis/tide/internal/config"
+	"github.com/bethropolis/tide/internal/highlighter"
+	"github.com/bethropolis/tide/internal/modehandler"
+	"github.com/bethropolis/tide/internal/types"
+	"github.com/gdamore/tcell/v2"
+)
+
+// docTimeout bounds a run of man or go doc.
+const docTimeout = 10 * time.Second
+
+// Styles of the parts of a documentation page.
+const (
+	docHeadingStyle   = "keyword"
+	docBoldStyle      = "function" // Commands and options in man pages
+	docUnderlineStyle = "type"     // Arguments in man pages
+)
+
+// ShowMan shows the manual page for topic ("ls", "3 printf") in the
+// read-only documentation buffer, formatted for the width of the window.
+func (a *App) ShowMan(topic string) error {
+	args := strings.Fields(topic)
+	if len(args) == 0 {
+		return fmt.Errorf("usage: :man [section] <topic>")
+	}
+	w, _ := a.tuiManager.Size()
+	env := append(os.Environ(),
+		fmt.Sprintf("MANWIDTH=%d", max(w-config.GutterWidth(9999, w)-2, 40)),
+		"MANPAGER=cat", "PAGER=cat",
+		"MAN_KEEP_FORMATTING=1", // Keep the bold and underlining we highlight
+		"GROFF_NO_SGR=1",        // as overstrikes rather than escape sequences
+	)
+	a.runDocCommand("man "+topic, "", env, "man", args, renderManPage)
+	return nil
+}
+
+// ShowGoDoc shows the go doc output for symbol ("fmt.Println", "-all
+// strings") in the read-only documentation buffer, with the declarations
+// highlighted as Go. Symbols are looked up from the active file's package.
+func (a *App) ShowGoDoc(symbol string) error {
+	args := strings.Fields(symbol)
+	if len(args) == 0 {
+		return fmt.Errorf("usage: :godoc <package|symbol>")
+	}
+	dir := ""
+	if ed := a.getActiveEditor(); ed != nil {
+		if path := ed.GetBuffer().FilePath(); path != "" {
+			if info, err := os.Stat(filepath.Dir(path)); err == nil && info.IsDir() {
+				dir = filepath.Dir(path)
+			}
+		}
+	}
+	a.runDocCommand("godoc "+symbol, dir, nil, "go", append([]string{"doc"}, args...), a.renderGoDoc)
+	return nil
+}
+
+// runDocCommand runs name with args off the event loop and shows what it
+// prints, as rendered by render back on it, in the documentation buffer.
+func (a *App) runDocCommand(title, dir string, env []string, name string, args []string, render func([]byte) (string, highlighter.HighlightResult, []int)) {
+	a.statusBar.SetTemporaryMessage("Running %s...", title)
+	a.goAsync(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), docTimeout)
+		defer cancel()
+		cmd := exec.CommandContext(ctx, name, args...)
+		cmd.Dir = dir
+		cmd.Env = env
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				err = fmt.Errorf("%s", firstLine(msg))
+			}
+			a.schedule(func() {
+				a.statusBar.SetErrorMessage("%s: %v", name, err)
+				a.requestRedraw()
+			})
+			return
+		}
+		a.schedule(func() {
+			text, highlights, sections := render(out)
+			a.showReport(&a.docs, text)
+			a.docSections = sections
+			if hm := a.docs.GetHighlightManager(); hm != nil {
+				hm.UpdateHighlights(highlights, nil)
+			}
+			a.statusBar.SetTemporaryMessage("%s (%d sections; ] and [ move between them, q closes)", title, len(sections))
+			a.requestRedraw()
+		})
+	})
+}
+
+// handleDocViewKey moves between the sections of the documentation buffer
+// with ] and [ and closes it with q, in normal mode. Returns true if the
+// key was consumed.
+func (a *App) handleDocViewKey(ev *tcell.EventKey) bool {
+	ed := a.getActiveEditor()
+	if ed == nil || ed != a.docs || ev.Key() != tcell.KeyRune || a.modeHandler.GetCurrentMode() != modehandler.ModeNormal {
+		return false
+	}
+	line := ed.GetCursor().Line
+	target := -1
+	switch ev.Rune() {
+	case ']':
+		for _, s := range a.docSections {
+			if s > line {
+				target = s
+				break
+			}
+		}
+	case '[':
+		for _, s := range a.docSections {
+			if s < line {
+				target = s
+			}
+		}
+	case 'q':
+		if len(a.editors) > 1 {
+			a.ForceCloseBuffer()
+		}
+		return true
+	default:
+		return false
+	}
+	if target >= 0 {
+		ed.SetCursor(types.Position{Line: target})
+		ed.ScrollToCursor()
+	}
+	return true
+}
+
+// renderManPage turns man output into plain text, highlighting the
+// section headings and the text man made bold or underlined. The headings
+// are the lines without indentation or lower-case letters.
+func renderManPage(out []byte) (string, highlighter.HighlightResult, []int) {
+	lines := strings.Split(strings.TrimRight(string(out), "\n"), "\n")
+	highlights := make(highlighter.HighlightResult)
+	var sections []int
+	for i, line := range lines {
+		text, ranges := stripOverstrike(line)
+		lines[i] = text
+		if isManHeading(text) && i > 0 && i < len(lines)-1 {
+			sections = append(sections, i)
+			ranges = []types.StyledRange{{StartCol: 0, EndCol: len([]rune(text)), StyleName: docHeadingStyle}}
+		}
+		if len(ranges) > 0 {
+			highlights[i] = ranges
+		}
+	}
+	return strings.Join(lines, "\n") + "\n", highlights, sections
+}
+
+// isManHeading reports whether a line of a man page is a section heading.
+func isManHeading(line string) bool {
+	if line == "" || line[0] == ' ' {
+		return false
+	}
+	return !strings.ContainsFunc(line, unicode.IsLower)
+}
+
+// stripOverstrike removes the backspace overstrikes man uses for bold
+// (c\bc) and underlining (_\bc), and any escape sequences, from line,
+// returning the plain text and the ranges that were bold or underlined.
+func stripOverstrike(line string) (string, []types.StyledRange) {
+	if !strings.ContainsAny(line, "\b\x1b") {
+		return line, nil
+	}
+	runes := []rune(line)
+	plain := make([]rune, 0, len(runes))
+	var styles []string
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\x1b':
+			for i+1 < len(runes) && !unicode.IsLetter(runes[i+1]) {
+				i++
+			}
+			i++ // The final letter
+			continue
+		case r == '\b':
+			continue
+		case i+2 < len(runes) && runes[i+1] == '\b':
+			style := docUnderlineStyle
+			if runes[i+2] == r {
+				style = docBoldStyle
+			}
+			i += 2
+			for i+2 < len(runes) && runes[i+1] == '\b' {
+				i += 2 // Bold and underlined: _\bc\bc
+			}
+			plain = append(plain, runes[i])
+			styles = append(styles, style)
+			continue
+		}
+		plain = append(plain, r)
+		styles = append(styles, "")
+	}
+
+	var ranges []types.StyledRange
+	for col := 0; col < len(styles); {
+		end := col + 1
+		for end < len(styles) && styles[end] == styles[col] {
+			end++
+		}
+		if styles[col] != "" {
+			ranges = append(ranges, types.StyledRange{StartCol: col, EndCol: end, StyleName: styles[col]})
+		}
+		col = end
+	}
+	return string(plain), ranges
+}
+
+// renderGoDoc highlights go doc output: the declarations as Go, with the
+// indented documentation between them as comments. The declarations that
+// start at the left margin are the sections.
+func (a *App) renderGoDoc(out []byte) (string, highlighter.HighlightResult, []int) {
+	text := strings.TrimRight(string(out), "\n") + "\n"
+	lines := strings.Split(text, "\n")
+	var sections []int
+	for i, line := range lines {
+		if line != "" && line[0] != ' ' && line[0] != '\t' && line[0] != '}' && line[0] != ')' {
+			sections = append(sections, i)
+		}
+	}
+
+	// Turn the documentation into Go comments, in place so the columns
+	// stay the same, and let the Go grammar do the rest
+	masked := make([]string, len(lines))
+	for i, line := range lines {
+		masked[i] = line
+		if strings.HasPrefix(line, "    ") {
+			masked[i] = "//" + line[2:]
+		}
+	}
+	highlights := make(highlighter.HighlightResult)
+	if goLang, query := a.highlighterService.GetLanguage("doc.go"); goLang != nil {
+		result, tree, err := a.highlighterService.HighlightBuffer(context.Background(), []byte(strings.Join(masked, "\n")), goLang, query, nil)
+		if err == nil {
+			highlights = result
+		}
+		if tree != nil {
+			tree.Close()
+		}
+	}
+	return text, highlights, sections
+}
+
+// firstLine returns s up to its first newline.
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
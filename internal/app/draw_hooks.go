@@ -0,0 +1,89 @@
+package app
+
+import (
+	"sync"
+
+	"github.com/bethropolis/tide/internal/logger"
+	"github.com/bethropolis/tide/internal/plugin"
+	"github.com/bethropolis/tide/internal/tui"
+	"github.com/gdamore/tcell/v2"
+)
+
+// drawHooks holds the plugin draw hooks in registration order. Plugins may
+// add and remove hooks from any goroutine.
+type drawHooks struct {
+	mu     sync.Mutex
+	nextID plugin.DrawHookID
+	hooks  []registeredDrawHook
+}
+
+type registeredDrawHook struct {
+	id   plugin.DrawHookID
+	hook plugin.DrawHook
+}
+
+// add registers hook and returns its ID.
+func (d *drawHooks) add(hook plugin.DrawHook) plugin.DrawHookID {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.nextID++
+	d.hooks = append(d.hooks, registeredDrawHook{id: d.nextID, hook: hook})
+	return d.nextID
+}
+
+// remove unregisters the hook with the given ID, if any.
+func (d *drawHooks) remove(id plugin.DrawHookID) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for i, h := range d.hooks {
+		if h.id == id {
+			d.hooks = append(d.hooks[:i], d.hooks[i+1:]...)
+			return
+		}
+	}
+}
+
+// snapshot returns the current hooks so they can run without the lock held.
+func (d *drawHooks) snapshot() []plugin.DrawHook {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	hooks := make([]plugin.DrawHook, len(d.hooks))
+	for i, h := range d.hooks {
+		hooks[i] = h.hook
+	}
+	return hooks
+}
+
+// geometry describes the screen and the active editor's view for plugins.
+func (a *App) geometry() plugin.Geometry {
+	w, h := a.tuiManager.Size()
+	geo := plugin.Geometry{ScreenWidth: w, ScreenHeight: h}
+	ed := a.getActiveEditor()
+	if ed == nil {
+		return geo
+	}
+
+	geo.Editor = tui.Rect{Width: w, Height: max(h-a.barHeight()-a.layout.StatusHeight(), 0)}
+	geo.LineCount = ed.GetBuffer().LineCount()
+	geo.GutterWidth = ed.GutterWidth(w)
+	geo.ViewportY, geo.ViewportX = ed.GetViewport()
+	geo.Cursor = ed.GetCursor()
+	return geo
+}
+
+// runDrawHooks lets plugins paint over the editor area. A panicking hook is
+// logged and skipped so it cannot take the draw loop down with it.
+func (a *App) runDrawHooks(screen tcell.Screen, hooks []plugin.DrawHook) {
+	geo := a.geometry()
+	clipped := tui.Clip(screen, geo.Editor)
+	for _, hook := range hooks {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					logger.Errorf("Plugin draw hook panicked: %v", r)
+				}
+			}()
+			hook(clipped, geo)
+		}()
+	}
+}
@@ -7,12 +7,15 @@ import (
 
 	"github.com/bethropolis/tide/internal/commands"
 	"github.com/bethropolis/tide/internal/config"
+	"github.com/bethropolis/tide/internal/core/conflict"
 	"github.com/bethropolis/tide/internal/event"
 	"github.com/bethropolis/tide/internal/logger"
 	"github.com/bethropolis/tide/internal/plugin"
 	"github.com/bethropolis/tide/internal/theme"
 	"github.com/bethropolis/tide/internal/tui"
 	"github.com/bethropolis/tide/internal/types"
+	"github.com/bethropolis/tide/internal/utils"
+	"github.com/bethropolis/tide/internal/version"
 	"github.com/gdamore/tcell/v2"
 )
 
@@ -66,26 +69,79 @@ func (api *appEditorAPI) GetBufferBytes() []byte {
 
 // --- Buffer Modification ---
 
+// InsertText inserts text at pos as an undoable change. The cursor stays
+// where it was.
 func (api *appEditorAPI) InsertText(pos types.Position, text []byte) error {
-	editInfo, err := api.app.getActiveEditor().GetBuffer().Insert(pos, text) // Capture EditInfo
-	if err == nil {
-		api.app.eventManager.Dispatch(event.TypeBufferModified, event.BufferModifiedData{Edit: editInfo})
-		// We likely need to redraw if buffer changes via plugin
-		api.app.requestRedraw()
+	return api.app.editRange(pos, pos, text)
+}
+
+// InsertAtCursor inserts text at the cursor as one undoable change.
+func (api *appEditorAPI) InsertAtCursor(text string) error {
+	if err := api.app.getActiveEditor().InsertAtCursor([]byte(text)); err != nil {
+		return err
 	}
-	// TODO: Does the cursor need updating? Does the editor method handle it?
-	return err
+	api.app.requestRedraw()
+	return nil
 }
 
-func (api *appEditorAPI) DeleteRange(start, end types.Position) error {
-	// Similar delegation and considerations as InsertText
-	editInfo, err := api.app.getActiveEditor().GetBuffer().Delete(start, end) // Capture EditInfo
-	if err == nil {
-		api.app.eventManager.Dispatch(event.TypeBufferModified, event.BufferModifiedData{Edit: editInfo})
-		api.app.requestRedraw()
+// ReplaceSelection replaces the visual selection with transform's result as
+// one undoable change.
+func (api *appEditorAPI) ReplaceSelection(transform func(text string) (string, error)) error {
+	ed := api.app.getActiveEditor()
+	start, end, text, ok := ed.SelectedText()
+	if !ok {
+		return fmt.Errorf("no selection")
+	}
+	result, err := transform(text)
+	if err != nil {
+		return err
+	}
+	if _, err := ed.ReplaceRange(start, end, []byte(result)); err != nil {
+		return err
+	}
+	api.app.requestRedraw()
+	return nil
+}
+
+// ReplaceSelectionOrBuffer rewrites the selection, or the whole buffer when
+// nothing is selected, as one undoable change.
+func (api *appEditorAPI) ReplaceSelectionOrBuffer(transform func(text string) (string, error)) (types.Position, error) {
+	ed := api.app.getActiveEditor()
+	start, end, text, ok := ed.SelectedText()
+	wholeBuffer := !ok
+	if wholeBuffer {
+		content := ed.GetBuffer().Bytes()
+		start = types.Position{}
+		end = utils.EndPosition(start, content)
+		text = string(content)
+	}
+	result, err := transform(text)
+	if err != nil {
+		return start, err
+	}
+	if result == text {
+		return start, nil
 	}
-	// TODO: Cursor update?
-	return err
+	if wholeBuffer {
+		// Only the lines that changed, so the cursor stays on its code
+		if err := ed.Rewrite([]byte(result)); err != nil {
+			return start, err
+		}
+	} else if _, err := ed.ReplaceRange(start, end, []byte(result)); err != nil {
+		return start, err
+	}
+	api.app.requestRedraw()
+	return start, nil
+}
+
+// DeleteRange deletes the text from start up to end as an undoable change.
+// The cursor stays where it was.
+func (api *appEditorAPI) DeleteRange(start, end types.Position) error {
+	return api.app.editRange(start, end, nil)
+}
+
+func (api *appEditorAPI) RunRepeatable(name string, fn func() error) error {
+	return api.app.RunRepeatable(name, fn)
 }
 
 // Replace implements the Replace method for substitution command
@@ -98,11 +154,31 @@ func (api *appEditorAPI) ReplaceAll(pattern, replacement string, caseInsensitive
 	return api.app.getActiveEditor().ReplaceAll(pattern, replacement, caseInsensitive)
 }
 
+// Retab converts the indentation of the buffer to tabs, or spaces with expand_tab (:retab).
+func (api *appEditorAPI) Retab() (int, error) {
+	return api.app.getActiveEditor().Retab()
+}
+
 // ReplaceInRange replaces all occurrences of pattern in [startLine, endLine] (:'<,'>s).
 func (api *appEditorAPI) ReplaceInRange(pattern, replacement string, startLine, endLine int, caseInsensitive bool) (int, error) {
 	return api.app.getActiveEditor().ReplaceInRange(pattern, replacement, startLine, endLine, caseInsensitive)
 }
 
+// ReplaceLines replaces pattern on each line of [startLine, endLine], the first match or all with global (:N,Ms).
+func (api *appEditorAPI) ReplaceLines(pattern, replacement string, startLine, endLine int, global, caseInsensitive bool) (int, error) {
+	return api.app.getActiveEditor().ReplaceLines(pattern, replacement, startLine, endLine, global, caseInsensitive)
+}
+
+// ProjectReplace previews and replaces matches across the working directory (:S).
+func (api *appEditorAPI) ProjectReplace(pattern, replacement string, caseInsensitive bool) error {
+	return api.app.ProjectReplace(pattern, replacement, caseInsensitive)
+}
+
+// RenameSymbol renames the symbol under the cursor through the language server (:lsprename).
+func (api *appEditorAPI) RenameSymbol(newName string) error {
+	return api.app.RenameSymbol(newName)
+}
+
 // --- Cursor & Viewport ---
 
 func (api *appEditorAPI) GetCursor() types.Position {
@@ -118,6 +194,27 @@ func (api *appEditorAPI) GetViewport() (int, int) {
 	return api.app.getActiveEditor().GetViewport()
 }
 
+// GetGeometry returns the screen size and where the active view sits on it.
+func (api *appEditorAPI) GetGeometry() plugin.Geometry {
+	return api.app.geometry()
+}
+
+// --- Drawing ---
+
+// AddDrawHook registers hook to paint over the editor after every frame.
+func (api *appEditorAPI) AddDrawHook(hook plugin.DrawHook) plugin.DrawHookID {
+	id := api.app.drawHooks.add(hook)
+	api.app.requestRedraw()
+	return id
+}
+
+// RemoveDrawHook unregisters a draw hook; its decorations vanish on the next frame.
+func (api *appEditorAPI) RemoveDrawHook(id plugin.DrawHookID) {
+	api.app.drawHooks.remove(id)
+	api.app.getActiveEditor().MarkAllDirty()
+	api.app.requestRedraw()
+}
+
 // --- Event Bus Interaction ---
 
 func (api *appEditorAPI) DispatchEvent(eventType event.Type, data interface{}) {
@@ -144,6 +241,26 @@ func (api *appEditorAPI) RegisterCommand(name string, cmdFunc plugin.CommandFunc
 	return api.app.GetModeHandler().RegisterCommand(name, cmdFunc) // <<< DELEGATE
 }
 
+// SetCommandCompletion registers Tab candidates for a command's first argument.
+func (api *appEditorAPI) SetCommandCompletion(name string, candidates func() []string) {
+	api.app.GetModeHandler().SetCommandCompletion(name, candidates)
+}
+
+// AddHistory records line in the command, search or expression history.
+func (api *appEditorAPI) AddHistory(kind, line string) {
+	if mh := api.app.GetModeHandler(); mh != nil {
+		mh.AddHistory(kind, line)
+	}
+}
+
+// History returns the lines of a history, oldest first.
+func (api *appEditorAPI) History(kind string) []string {
+	if mh := api.app.GetModeHandler(); mh != nil {
+		return mh.History(kind)
+	}
+	return nil
+}
+
 // RegisterThemeCommand implements the theme.ThemeAPI interface
 func (api *appEditorAPI) RegisterThemeCommand(name string, cmdFunc theme.CommandFunc) error {
 	// Since theme.CommandFunc is a type alias for func([]string) error,
@@ -155,6 +272,20 @@ func (api *appEditorAPI) RegisterThemeCommand(name string, cmdFunc theme.Command
 	return api.app.GetModeHandler().RegisterCommand(name, cmdFunc)
 }
 
+// --- Key Mappings ---
+
+func (api *appEditorAPI) MapKeys(modes, lhs, rhs string, noremap bool, source string) error {
+	return api.app.GetModeHandler().Map(modes, lhs, rhs, noremap, source)
+}
+
+func (api *appEditorAPI) UnmapKeys(modes, lhs string) error {
+	return api.app.GetModeHandler().Unmap(modes, lhs)
+}
+
+func (api *appEditorAPI) ShowMappings(modes, lhs string, verbose bool) error {
+	return api.app.ShowMappings(modes, lhs, verbose)
+}
+
 // --- Status Bar ---
 
 func (api *appEditorAPI) SetStatusMessage(format string, args ...interface{}) {
@@ -180,6 +311,16 @@ func (api *appEditorAPI) ShowPicker(title string, items []tui.PickerItem, onSele
 	api.app.requestRedraw()
 }
 
+// ShowConfirm opens the modal confirmation dialog. Each choice's Action runs
+// after the dialog closes; Esc dismisses it without running anything.
+func (api *appEditorAPI) ShowConfirm(title string, message []string, choices []tui.ConfirmChoice) {
+	if api.app.confirm == nil {
+		api.app.confirm = tui.NewConfirmDialog()
+	}
+	api.app.confirm.Show(title, message, choices)
+	api.app.requestRedraw()
+}
+
 // --- Theme Access ---
 func (api *appEditorAPI) GetThemeStyle(styleName string) tcell.Style {
 	return api.app.activeTheme.GetStyle(styleName)
@@ -223,26 +364,201 @@ func (api *appEditorAPI) CloseBuffer() error {
 	return api.app.CloseBuffer()
 }
 
+func (api *appEditorAPI) RenameFile(newPath string) error {
+	return api.app.RenameFile(newPath)
+}
+
+func (api *appEditorAPI) DeleteFile(toTrash bool) error {
+	return api.app.DeleteFile(toTrash)
+}
+
+func (api *appEditorAPI) MakeView() error {
+	return api.app.MakeView()
+}
+
+func (api *appEditorAPI) LoadView() error {
+	return api.app.LoadView()
+}
+
+func (api *appEditorAPI) ToggleTableView() (bool, error) {
+	return api.app.ToggleTableView()
+}
+
+func (api *appEditorAPI) ToggleTableHeader() bool {
+	return api.app.ToggleTableHeader()
+}
+
+func (api *appEditorAPI) ToggleInlineBlame() bool {
+	return api.app.ToggleInlineBlame()
+}
+
+func (api *appEditorAPI) ToggleTail() (bool, error) {
+	return api.app.ToggleTail()
+}
+
+func (api *appEditorAPI) SetMark(name rune) error {
+	return api.app.SetMark(name)
+}
+
+func (api *appEditorAPI) JumpToMark(name rune) error {
+	return api.app.JumpToMark(name)
+}
+
+func (api *appEditorAPI) ListMarks() {
+	api.app.ListMarks()
+}
+
+func (api *appEditorAPI) DeleteMarks(names string) error {
+	return api.app.DeleteMarks(names)
+}
+
+func (api *appEditorAPI) RevertToSaved() error {
+	return api.app.RevertToSaved()
+}
+
+func (api *appEditorAPI) ReloadBuffer() error {
+	return api.app.ReloadBuffer()
+}
+
+func (api *appEditorAPI) Jump(forward bool) error {
+	return api.app.Jump(forward)
+}
+
+func (api *appEditorAPI) StageHunk() error {
+	return api.app.StageHunk()
+}
+
+func (api *appEditorAPI) RevertHunk() error {
+	return api.app.RevertHunk()
+}
+
+func (api *appEditorAPI) PreviewHunk() error {
+	return api.app.PreviewHunk()
+}
+
+func (api *appEditorAPI) DiffSaved() error {
+	return api.app.DiffSaved()
+}
+
+func (api *appEditorAPI) StopDiffSaved() {
+	api.app.StopDiffSaved()
+}
+
+func (api *appEditorAPI) RevertSavedHunk() error {
+	return api.app.RevertSavedHunk()
+}
+
+func (api *appEditorAPI) JumpSavedHunk(forward bool) error {
+	return api.app.JumpSavedHunk(forward)
+}
+
+func (api *appEditorAPI) CheckHealth() {
+	api.app.CheckHealth()
+}
+
+func (api *appEditorAPI) ShowVersion() {
+	api.app.ShowVersion()
+}
+
+func (api *appEditorAPI) ShowMan(topic string) error {
+	return api.app.ShowMan(topic)
+}
+
+func (api *appEditorAPI) ShowGoDoc(symbol string) error {
+	return api.app.ShowGoDoc(symbol)
+}
+
+func (api *appEditorAPI) Version() version.Info {
+	return version.Get()
+}
+
+func (api *appEditorAPI) ResolveConflict(choice conflict.Choice) error {
+	if err := api.app.getActiveEditor().ResolveConflict(choice); err != nil {
+		return err
+	}
+	api.app.requestRedraw()
+	return nil
+}
+
+func (api *appEditorAPI) SetQuickfix(items []types.QuickfixItem) {
+	api.app.SetQuickfix(items)
+}
+
+func (api *appEditorAPI) OpenQuickfix() error {
+	return api.app.OpenQuickfix()
+}
+
+func (api *appEditorAPI) QuickfixStep(delta int) error {
+	return api.app.QuickfixStep(delta)
+}
+
+func (api *appEditorAPI) ShowSymbols() error {
+	return api.app.ShowSymbols()
+}
+
+func (api *appEditorAPI) GoToSymbol(name string) error {
+	return api.app.GoToSymbol(name)
+}
+
+func (api *appEditorAPI) StartRecording(path string) error {
+	return api.app.modeHandler.StartRecording(path)
+}
+
+func (api *appEditorAPI) StopRecording() (string, error) {
+	return api.app.modeHandler.StopRecording()
+}
+
+func (api *appEditorAPI) SourceScript(path string) error {
+	return api.app.SourceScript(path)
+}
+
 func (api *appEditorAPI) ForceCloseBuffer() {
 	api.app.ForceCloseBuffer()
 }
 
-// RequestQuit signals the application to quit
+func (api *appEditorAPI) ListBuffers() {
+	api.app.ListBuffers()
+}
+
+func (api *appEditorAPI) SwitchBuffer(n int) error {
+	return api.app.SwitchBuffer(n)
+}
+
+// --- Windows ---
+
+func (api *appEditorAPI) Split(vertical bool, filePath string) error {
+	return api.app.Split(vertical, filePath)
+}
+
+func (api *appEditorAPI) WindowCommand(c rune) error {
+	return api.app.WindowCommand(c)
+}
+
+func (api *appEditorAPI) WindowCount() int {
+	return len(api.app.layout.Panes())
+}
+
+// --- Concurrency ---
+
+// Go runs fn off the event loop.
+func (api *appEditorAPI) Go(fn func()) {
+	api.app.goAsync(fn)
+}
+
+// Schedule runs fn on the event loop, after the event being handled.
+func (api *appEditorAPI) Schedule(fn func()) {
+	api.app.schedule(fn)
+}
+
+// RequestQuit signals the application to quit. A non-forced quit prompts
+// when any buffer has unsaved changes.
 func (api *appEditorAPI) RequestQuit(force bool) {
 	if force {
 		logger.Debugf("API: Force quit requested.")
-		close(api.app.quit) // Close directly if forced
-	} else {
-		// Check modified status via buffer
-		if api.app.getActiveEditor().GetBuffer().IsModified() {
-			logger.Debugf("API: Quit requested, but buffer modified. Setting status.")
-			api.SetStatusMessage("No write since last change (use :q! or force quit key)")
-			// Don't close the channel here. Let the command fail.
-		} else {
-			logger.Debugf("API: Quit requested (buffer not modified).")
-			close(api.app.quit)
-		}
+		api.app.signalQuit()
+		return
 	}
+	api.app.RequestQuit()
 }
 
 // GetPluginConfigValue retrieves a configuration value for a specific plugin.
@@ -276,3 +592,19 @@ func (api *appEditorAPI) GetPluginConfigValue(pluginName, key string) (interface
 	logger.Debugf("API: GetPluginConfigValue: Found value for plugin '%s', key '%s'.", pluginName, key)
 	return value, true
 }
+
+func (api *appEditorAPI) CollabHost(addr string) error {
+	return api.app.CollabHost(addr)
+}
+
+func (api *appEditorAPI) CollabJoin(addr string) error {
+	return api.app.CollabJoin(addr)
+}
+
+func (api *appEditorAPI) StopCollab() {
+	api.app.StopCollab()
+}
+
+func (api *appEditorAPI) CollabStatus() string {
+	return api.app.CollabStatus()
+}
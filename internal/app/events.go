@@ -37,6 +37,12 @@ func (a *App) handleBufferSavedForStatus(e event.Event) bool {
 	return false               // Not consumed
 }
 
+// handleBufferRenamedForStatus refreshes the file name shown in the status bar
+func (a *App) handleBufferRenamedForStatus(e event.Event) bool {
+	a.updateStatusBarContent()
+	return false // Not consumed
+}
+
 // handleBufferLoadedForStatus updates the status and triggers highlighting
 func (a *App) handleBufferLoadedForStatus(e event.Event) bool {
 	a.updateStatusBarContent() // Update file path, modified status etc.
@@ -0,0 +1,283 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/bethropolis/tide/internal/buffer"
+	"github.com/bethropolis/tide/internal/config"
+	"github.com/bethropolis/tide/internal/core"
+	"github.com/bethropolis/tide/internal/core/clipboard"
+	"github.com/bethropolis/tide/internal/highlighter/lang"
+	"github.com/bethropolis/tide/internal/input"
+	"github.com/bethropolis/tide/internal/tui"
+	"github.com/bethropolis/tide/internal/types"
+	"github.com/bethropolis/tide/internal/utils"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// healthReport collects the lines of a :checkhealth report.
+type healthReport struct {
+	b                strings.Builder
+	errors, warnings int
+}
+
+func (r *healthReport) section(title string) {
+	fmt.Fprintf(&r.b, "\n%s\n%s\n", title, strings.Repeat("-", len(title)))
+}
+
+func (r *healthReport) line(tag, format string, args ...interface{}) {
+	fmt.Fprintf(&r.b, "  %-6s %s\n", tag, fmt.Sprintf(format, args...))
+}
+
+func (r *healthReport) ok(format string, args ...interface{}) { r.line("OK", format, args...) }
+
+func (r *healthReport) warn(format string, args ...interface{}) {
+	r.warnings++
+	r.line("WARN", format, args...)
+}
+
+func (r *healthReport) fail(format string, args ...interface{}) {
+	r.errors++
+	r.line("ERROR", format, args...)
+}
+
+// CheckHealth checks the configuration, themes, keybindings, external
+// tools, grammars and plugins, and shows the findings in a read-only
+// buffer (:checkhealth). Running it again refreshes that buffer.
+func (a *App) CheckHealth() {
+	r := &healthReport{}
+	a.checkConfigHealth(r)
+	a.checkThemeHealth(r)
+	checkToolHealth(r)
+	checkGrammarHealth(r)
+	a.checkPluginHealth(r)
+
+	text := fmt.Sprintf("Tide health check: %d errors, %d warnings\n%s", r.errors, r.warnings, r.b.String())
+
+	a.showReport(&a.health, text)
+	a.statusBar.SetTemporaryMessage("Health check: %d errors, %d warnings", r.errors, r.warnings)
+	a.requestRedraw()
+}
+
+// showReport shows text in the read-only buffer *slot, creating it if it
+// was never opened or has been closed, and makes it the active buffer.
+func (a *App) showReport(slot **core.Editor, text string) {
+	index := slices.Index(a.editors, *slot)
+	if index < 0 {
+		buf := buffer.NewPieceTable()
+		*slot = core.NewEditor(buf, a.highlighterService, a.eventManager)
+		(*slot).SetJumpList(a.jumps)
+		a.sizeView(*slot)
+		a.editors = append(a.editors, *slot)
+		index = len(a.editors) - 1
+	}
+	ed := *slot
+	buf := ed.GetBuffer().(*buffer.PieceTable)
+	buf.SetContent([]byte(text))
+	buf.SetReadOnly(true)
+	ed.SetCursor(types.Position{})
+	ed.MarkAllDirty()
+
+	a.activeEditorIndex = index
+	if a.modeHandler != nil {
+		a.modeHandler.SetEditor(ed)
+	}
+}
+
+// pathStyles are the path_style values utils.DisplayPath knows.
+var pathStyles = []string{"full", "home", "compact", "name"}
+
+func (a *App) checkConfigHealth(r *healthReport) {
+	r.section("Configuration")
+	path := config.FilePath()
+	switch unknown, err := config.CheckFile(path); {
+	case path == "":
+		r.warn("No config directory found; using the defaults")
+	case err != nil:
+		r.fail("%v", err)
+	default:
+		if _, statErr := os.Stat(path); statErr != nil {
+			r.ok("No %s; using the defaults", utils.DisplayPath(path, "home"))
+		} else {
+			r.ok("Read %s", utils.DisplayPath(path, "home"))
+		}
+		for _, key := range unknown {
+			r.warn("Unknown key %q is ignored", key)
+		}
+	}
+
+	if cwd, err := os.Getwd(); err == nil {
+		if local := config.FindLocalConfig(cwd); local != "" {
+			if applied := config.AppliedLocalConfig(); applied == local {
+				r.ok("Project settings %s applied", utils.DisplayPath(local, "home"))
+			} else {
+				r.warn("Project settings %s not loaded (not trusted)", utils.DisplayPath(local, "home"))
+			}
+			if unknown, err := config.CheckFile(local); err != nil {
+				r.fail("%v", err)
+			} else {
+				for _, key := range unknown {
+					r.warn("Unknown key %q in project settings is ignored", key)
+				}
+			}
+		}
+	}
+
+	cfg := config.Get()
+	if !slices.Contains(pathStyles, cfg.Editor.PathStyle) {
+		r.warn("path_style %q is not one of %s; full paths are shown", cfg.Editor.PathStyle, strings.Join(pathStyles, ", "))
+	}
+	if g := cfg.Editor.Gutter; g != config.GutterTotal && g != config.GutterVisible {
+		r.warn("gutter %q is not %s or %s; it is sized for the whole buffer", g, config.GutterTotal, config.GutterVisible)
+	}
+	if a := cfg.Editor.AutoIndent; a != config.AutoIndentOff && a != config.AutoIndentKeep && a != config.AutoIndentSmart {
+		r.warn("auto_indent %q is not %s, %s or %s; new lines keep the indentation above", a, config.AutoIndentOff, config.AutoIndentKeep, config.AutoIndentSmart)
+	}
+	if !slices.Contains(buffer.Backends, cfg.Editor.BufferBackend) {
+		r.warn("buffer_backend %q is not one of %s; the piece table is used", cfg.Editor.BufferBackend, strings.Join(buffer.Backends, ", "))
+	}
+	modes := make([]string, 0, len(cfg.Editor.CursorShape))
+	for mode := range cfg.Editor.CursorShape {
+		modes = append(modes, mode)
+	}
+	sort.Strings(modes)
+	for _, mode := range modes {
+		shape := cfg.Editor.CursorShape[mode]
+		switch mode {
+		case "normal", "insert", "visual", "command":
+		default:
+			r.warn("cursor_shape has an entry for unknown mode %q", mode)
+		}
+		if _, ok := tui.ParseCursorShape(shape); !ok {
+			r.warn("cursor_shape.%s %q is not a cursor shape", mode, shape)
+		}
+	}
+
+	r.section("Keybindings")
+	if problems := input.CheckBindings(&cfg.Keybinds); len(problems) > 0 {
+		for _, p := range problems {
+			r.warn("%s", p)
+		}
+	} else {
+		r.ok("No conflicts")
+	}
+}
+
+func (a *App) checkThemeHealth(r *healthReport) {
+	r.section("Themes")
+	if a.themeManager == nil {
+		return
+	}
+	current := a.themeManager.Current()
+	r.ok("Active theme: %s", current.Name)
+	if missing := current.MissingRoles(); len(missing) > 0 {
+		r.warn("%s does not style %s; the terminal's colors are used", current.Name, strings.Join(missing, ", "))
+	}
+	files, errs := a.themeManager.CheckFiles()
+	for _, path := range files {
+		if err := errs[path]; err != nil {
+			r.fail("%v", err)
+		} else {
+			r.ok("%s", utils.DisplayPath(path, "home"))
+		}
+		delete(errs, path)
+	}
+	for path, err := range errs {
+		r.fail("%s: %v", utils.DisplayPath(path, "home"), err)
+	}
+}
+
+func checkToolHealth(r *healthReport) {
+	r.section("External tools")
+	if path, err := exec.LookPath("git"); err == nil {
+		r.ok("git: %s", path)
+	} else {
+		r.warn("git not found; :blame, :hunk, :diffsaved and inline blame need it")
+	}
+
+	cfg := config.Get()
+	if cfg.Editor.SystemClipboard {
+		switch tool, err := clipboard.SystemTool(); {
+		case err != nil:
+			r.fail("system_clipboard is on, but %v", err)
+		case tool != "":
+			r.ok("Clipboard: %s", tool)
+		default:
+			r.ok("Clipboard: provided by the system")
+		}
+	}
+	if cfg.Editor.PrimarySelection {
+		if tool, err := clipboard.PrimaryTool(); err != nil {
+			r.fail("primary_selection is on, but %v", err)
+		} else {
+			r.ok("Primary selection: %s", tool)
+		}
+	}
+
+	ids := make([]string, 0, len(cfg.LSP))
+	for id := range cfg.LSP {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		srv := cfg.LSP[id]
+		if len(srv.Command) == 0 {
+			continue // Disabled
+		}
+		if path, err := exec.LookPath(srv.Command[0]); err == nil {
+			r.ok("%s language server: %s", id, path)
+		} else {
+			r.warn("%s language server %q not found; %s files get no completion or diagnostics", id, srv.Command[0], strings.Join(srv.Extensions, " "))
+		}
+	}
+}
+
+func checkGrammarHealth(r *healthReport) {
+	r.section("Grammars")
+	for _, l := range lang.GetAll() {
+		if l.TreeSitterLang == nil {
+			r.fail("%s: no grammar", l.Name)
+			continue
+		}
+		query := l.GetQuery()
+		if query == nil {
+			r.warn("%s: no highlight query", l.Name)
+			continue
+		}
+		q, err := sitter.NewQuery(query, l.TreeSitterLang)
+		if err != nil {
+			r.fail("%s: highlight query does not compile: %v", l.Name, err)
+			continue
+		}
+		q.Close()
+		r.ok("%s (%s)", l.Name, strings.Join(l.Extensions, " "))
+	}
+}
+
+func (a *App) checkPluginHealth(r *healthReport) {
+	r.section("Plugins")
+	if a.pluginManager == nil {
+		return
+	}
+	errs := a.pluginManager.Errors()
+	names := a.pluginManager.Names()
+	sort.Strings(names)
+	for _, name := range names {
+		if _, failed := errs[name]; !failed {
+			r.ok("%s", name)
+		}
+	}
+	failed := make([]string, 0, len(errs))
+	for name := range errs {
+		failed = append(failed, name)
+	}
+	sort.Strings(failed)
+	for _, name := range failed {
+		r.fail("%s: %v", name, errs[name])
+	}
+}
@@ -0,0 +1,171 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bethropolis/tide/internal/core"
+	"github.com/bethropolis/tide/internal/git"
+	"github.com/bethropolis/tide/internal/types"
+)
+
+// hunkTimeout bounds the git commands behind one hunk operation.
+const hunkTimeout = 10 * time.Second
+
+// hunkBase returns the version of the file at path that hunks are taken
+// against.
+type hunkBase func(ctx context.Context, path string) ([]byte, error)
+
+// gitVersion is the hunk base of the file as of rev in git: "" for the
+// index, or "HEAD".
+func gitVersion(rev string) hunkBase {
+	return func(ctx context.Context, path string) ([]byte, error) {
+		text, err := git.Show(ctx, rev, path)
+		if err != nil {
+			return nil, fmt.Errorf("git: %w", err)
+		}
+		return text, nil
+	}
+}
+
+// withHunk finds the hunk under the cursor in a diff of the active buffer
+// against base and hands it to done on the event loop. git runs off the
+// event loop; the answer is dropped if the buffer changed meanwhile.
+func (a *App) withHunk(base hunkBase, done func(ed *core.Editor, path string, h git.Hunk)) error {
+	ed := a.getActiveEditor()
+	if ed == nil || ed.GetBuffer().FilePath() == "" {
+		return fmt.Errorf("buffer has no file name")
+	}
+	if _, isDir := a.dirViews[ed]; isDir {
+		return fmt.Errorf("not a file")
+	}
+	path := ed.GetBuffer().FilePath()
+	text := ed.GetBuffer().Bytes()
+	line := ed.GetCursor().Line
+
+	a.goAsync(func() {
+		h, found, err := func() (git.Hunk, bool, error) {
+			ctx, cancel := context.WithTimeout(context.Background(), hunkTimeout)
+			defer cancel()
+			old, err := base(ctx, path)
+			if err != nil {
+				return git.Hunk{}, false, err
+			}
+			hunks, err := git.Diff(ctx, old, text)
+			if err != nil {
+				return git.Hunk{}, false, err
+			}
+			h, found := git.HunkAt(hunks, line)
+			return h, found, nil
+		}()
+		a.schedule(func() {
+			switch {
+			case err != nil:
+				a.statusBar.SetTemporaryMessage("%v", err)
+			case !found:
+				a.statusBar.SetTemporaryMessage("No change under the cursor")
+			case a.getActiveEditor() != ed || !bytes.Equal(ed.GetBuffer().Bytes(), text):
+				// Edited or switched away meanwhile
+			default:
+				done(ed, path, h)
+			}
+			a.requestRedraw()
+		})
+	})
+	return nil
+}
+
+// StageHunk adds the change under the cursor, as it is in the buffer, to
+// the git index (:hunk stage).
+func (a *App) StageHunk() error {
+	return a.withHunk(gitVersion(""), func(ed *core.Editor, path string, h git.Hunk) {
+		a.goAsync(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), hunkTimeout)
+			defer cancel()
+			err := git.StageHunk(ctx, path, h)
+			a.schedule(func() {
+				if err != nil {
+					a.statusBar.SetTemporaryMessage("Stage failed: %v", err)
+				} else {
+					a.statusBar.SetTemporaryMessage("Staged %s", hunkSummary(h))
+				}
+				a.requestRedraw()
+			})
+		})
+	})
+}
+
+// RevertHunk puts the lines of the change under the cursor back as they are
+// in HEAD, as one undoable edit (:hunk revert).
+func (a *App) RevertHunk() error {
+	return a.withHunk(gitVersion("HEAD"), func(ed *core.Editor, _ string, h git.Hunk) {
+		a.revertHunk(ed, h)
+	})
+}
+
+// revertHunk puts the old lines of h back in place of its new ones, as one
+// undoable edit.
+func (a *App) revertHunk(ed *core.Editor, h git.Hunk) {
+	start, end, text := hunkReplacement(ed, h)
+	if _, err := ed.ReplaceRange(start, end, text); err != nil {
+		a.statusBar.SetTemporaryMessage("Revert failed: %v", err)
+		return
+	}
+	ed.SetCursor(types.Position{Line: max(h.NewStart-1, 0)})
+	ed.ScrollToCursor()
+	a.statusBar.SetTemporaryMessage("Reverted %s", hunkSummary(h))
+}
+
+// PreviewHunk shows the change under the cursor, against the index, in a
+// popup closed by the next key (:hunk preview).
+func (a *App) PreviewHunk() error {
+	return a.withHunk(gitVersion(""), func(_ *core.Editor, _ string, h git.Hunk) {
+		a.closeDocPopup(&a.hover)
+		markdown := "```diff\n" + h.Header() + "\n" + strings.Join(h.Lines, "\n") + "\n```"
+		if a.hover = a.showDocPopup(markdown, false); a.hover != nil {
+			a.hover.win.Title = hunkSummary(h)
+		}
+	})
+}
+
+// hunkSummary describes a hunk for the status bar, e.g. "-2 +3 lines at 14".
+func hunkSummary(h git.Hunk) string {
+	return fmt.Sprintf("-%d +%d lines at %d", h.OldCount, h.NewCount, max(h.NewStart, 1))
+}
+
+// hunkReplacement returns the range of the buffer covering the hunk's new
+// lines, and the old lines to put there instead.
+func hunkReplacement(ed *core.Editor, h git.Hunk) (start, end types.Position, text []byte) {
+	buf := ed.GetBuffer()
+	lineEnd := func(line int) types.Position {
+		l, _ := buf.Line(line)
+		return types.Position{Line: line, Col: utf8.RuneCount(l)}
+	}
+	old := h.Old()
+
+	first := h.NewStart - 1 // First line of the hunk in the buffer
+	if h.NewCount == 0 {
+		first = h.NewStart // Removed lines go back after line NewStart
+	}
+	last := first + h.NewCount // Line after the hunk
+	if last < buf.LineCount() {
+		return types.Position{Line: first}, types.Position{Line: last}, old
+	}
+
+	// The hunk reaches the end of a buffer without a final newline, so the
+	// newline ends the line before the hunk instead of the last one
+	end = lineEnd(buf.LineCount() - 1)
+	text = bytes.TrimSuffix(old, []byte("\n"))
+	if first == 0 {
+		return types.Position{}, end, text
+	}
+	start = lineEnd(first - 1)
+	if len(text) == 0 {
+		return start, end, nil
+	}
+	return start, end, append([]byte("\n"), text...)
+}
@@ -0,0 +1,68 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/bethropolis/tide/internal/core"
+	"github.com/bethropolis/tide/internal/core/jumplist"
+)
+
+// Jump goes back (Ctrl+O) or forward (Ctrl+I) through the jump list,
+// switching to the buffer the jump was made in, or opening its file again
+// if the buffer was closed. Jumps made in unnamed buffers that have since
+// been closed are passed over.
+func (a *App) Jump(forward bool) error {
+	ed := a.getActiveEditor()
+	if ed == nil {
+		return nil
+	}
+	for {
+		var (
+			j  jumplist.Jump
+			ok bool
+		)
+		if forward {
+			j, ok = a.jumps.Forward()
+		} else {
+			j, ok = a.jumps.Back(ed.Here())
+		}
+		switch {
+		case !ok && forward:
+			return fmt.Errorf("already at the newest jump")
+		case !ok:
+			return fmt.Errorf("already at the oldest jump")
+		}
+		if a.showJump(j) {
+			return nil
+		}
+	}
+}
+
+// showJump makes the buffer of j active and puts the cursor where j was,
+// or where it now ends if the buffer has been shortened since. It reports
+// false when the buffer is gone and cannot be opened again.
+func (a *App) showJump(j jumplist.Jump) bool {
+	if j.Path != a.getActiveEditor().GetBuffer().FilePath() {
+		i := slices.IndexFunc(a.editors, func(ed *core.Editor) bool { return ed.GetBuffer().FilePath() == j.Path })
+		switch {
+		case i >= 0:
+			a.activeEditorIndex = i
+			if a.modeHandler != nil {
+				a.modeHandler.SetEditor(a.getActiveEditor())
+			}
+		case j.Path == "":
+			return false
+		default:
+			a.openFile(j.Path)
+		}
+	}
+	ed := a.getActiveEditor()
+	pos := j.Pos
+	pos.Line = min(pos.Line, ed.GetBuffer().LineCount()-1)
+	ed.SetCursor(pos)
+	ed.ScrollToCursor()
+	ed.MarkAllDirty()
+	a.requestRedraw()
+	return true
+}
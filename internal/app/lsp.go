@@ -0,0 +1,212 @@
+// internal/app/lsp.go
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/bethropolis/tide/internal/config"
+	"github.com/bethropolis/tide/internal/core"
+	"github.com/bethropolis/tide/internal/logger"
+	"github.com/bethropolis/tide/internal/lsp"
+)
+
+// Timeouts for language server work done off the event loop.
+const (
+	lspStartTimeout   = 10 * time.Second // Launch and initialize handshake
+	lspRequestTimeout = 10 * time.Second // A single request such as rename
+)
+
+// lspClients keeps one running language server per language, started the
+// first time a file of that language needs one.
+type lspClients struct {
+	mu       sync.Mutex
+	clients  map[string]*lsp.Client   // By language id
+	starting map[string]chan struct{} // Closed when the server being started is ready or failed
+	failed   map[string]error         // Servers that failed to start are not retried
+	stopped  bool                     // Set by shutdown; no servers are started after it
+}
+
+// errLSPStopped is returned by get once the servers were shut down.
+var errLSPStopped = errors.New("language servers are shut down")
+
+// get returns the client for languageID, starting the server if needed. It
+// blocks while the server starts, so it must not be called on the event loop.
+// The lock is not held meanwhile: callers for other languages go on, and
+// those for the same one wait for the start under way.
+func (l *lspClients) get(languageID string, server config.LSPServerConfig) (*lsp.Client, error) {
+	l.mu.Lock()
+	for {
+		if l.stopped {
+			l.mu.Unlock()
+			return nil, errLSPStopped
+		}
+		if c, ok := l.clients[languageID]; ok {
+			l.mu.Unlock()
+			return c, nil
+		}
+		if err, ok := l.failed[languageID]; ok {
+			l.mu.Unlock()
+			return nil, err
+		}
+		wait, ok := l.starting[languageID]
+		if !ok {
+			break
+		}
+		l.mu.Unlock()
+		<-wait
+		l.mu.Lock()
+	}
+	if l.starting == nil {
+		l.starting = make(map[string]chan struct{})
+	}
+	done := make(chan struct{})
+	l.starting[languageID] = done
+	l.mu.Unlock()
+
+	c, err := startLSP(languageID, server)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.starting, languageID)
+	close(done)
+	if err != nil {
+		if l.failed == nil {
+			l.failed = make(map[string]error)
+		}
+		l.failed[languageID] = err
+		return nil, err
+	}
+	if l.stopped {
+		go c.Shutdown() // shutdown ran while it started
+		return nil, errLSPStopped
+	}
+	if l.clients == nil {
+		l.clients = make(map[string]*lsp.Client)
+	}
+	l.clients[languageID] = c
+	return c, nil
+}
+
+// startLSP launches the server for languageID in the working directory and
+// waits for its initialize handshake.
+func startLSP(languageID string, server config.LSPServerConfig) (*lsp.Client, error) {
+	root, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), lspStartTimeout)
+	defer cancel()
+	logger.Infof("LSP: starting %v for %s in %s", server.Command, languageID, root)
+	c, err := lsp.Start(ctx, server.Command, root, languageID)
+	if err != nil {
+		return nil, fmt.Errorf("%s language server: %w", languageID, err)
+	}
+	logger.Infof("LSP: %s server ready (position encoding %s)", languageID, c.Encoding())
+	return c, nil
+}
+
+// shutdown stops every running server. A server still starting is stopped
+// once it is ready.
+func (l *lspClients) shutdown() {
+	l.mu.Lock()
+	clients := l.clients
+	l.clients = nil
+	l.stopped = true
+	l.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, c := range clients {
+		wg.Add(1)
+		go func(c *lsp.Client) {
+			defer wg.Done()
+			c.Shutdown()
+		}(c)
+	}
+	wg.Wait()
+}
+
+// lspServerFor returns the language server configured for the editor's file.
+func (a *App) lspServerFor(ed *core.Editor) (string, config.LSPServerConfig, bool) {
+	if _, isDir := a.dirViews[ed]; isDir || ed.GetBuffer().FilePath() == "" {
+		return "", config.LSPServerConfig{}, false
+	}
+	return config.Get().LSPServerFor(ed.GetBuffer().FilePath())
+}
+
+// lspDocument is the text of an open buffer as it was sent to the server.
+type lspDocument struct {
+	editor *core.Editor
+	text   []byte
+}
+
+// lspSnapshot collects the open buffers of one language, by absolute path,
+// so the server can be told about unsaved changes before a request. It must
+// run on the event loop.
+func (a *App) lspSnapshot(languageID string) map[string]*lspDocument {
+	docs := make(map[string]*lspDocument)
+	for _, ed := range a.editors {
+		id, _, ok := a.lspServerFor(ed)
+		if !ok || id != languageID {
+			continue
+		}
+		abs, err := filepath.Abs(ed.GetBuffer().FilePath())
+		if err != nil {
+			continue
+		}
+		docs[abs] = &lspDocument{editor: ed, text: ed.GetBuffer().Bytes()}
+	}
+	return docs
+}
+
+// lspRequest runs a request against the language server for the active
+// buffer. The open buffers of that language are synced first, then request
+// runs on its own goroutine with the client, the active file's absolute path
+// and the synced documents. Its result is handed to done on the event loop.
+func (a *App) lspRequest(
+	request func(ctx context.Context, c *lsp.Client, path string) (interface{}, error),
+	done func(result interface{}, docs map[string]*lspDocument, err error),
+) error {
+	ed := a.getActiveEditor()
+	if ed == nil || ed.GetBuffer().FilePath() == "" {
+		return fmt.Errorf("buffer has no file name")
+	}
+	path, err := filepath.Abs(ed.GetBuffer().FilePath())
+	if err != nil {
+		return err
+	}
+	languageID, server, ok := a.lspServerFor(ed)
+	if !ok {
+		return fmt.Errorf("no language server configured for %s", filepath.Base(path))
+	}
+	docs := a.lspSnapshot(languageID)
+
+	a.goAsync(func() {
+		result, err := func() (interface{}, error) {
+			c, err := a.lsp.get(languageID, server)
+			if err != nil {
+				return nil, err
+			}
+			for p, doc := range docs {
+				if err := c.Sync(p, doc.text); err != nil {
+					return nil, err
+				}
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), lspRequestTimeout)
+			defer cancel()
+			return request(ctx, c, path)
+		}()
+		a.schedule(func() { done(result, docs, err) })
+	})
+	return nil
+}
+
+// lspPosition converts an editor position to an LSP position in line.
+func lspPosition(line []byte, lineIdx, col int, enc lsp.Encoding) lsp.Position {
+	return lsp.Position{Line: lineIdx, Character: lsp.FromRuneCol(line, col, enc)}
+}
@@ -0,0 +1,210 @@
+// internal/app/lsp_popup.go
+package app
+
+import (
+	"context"
+	"strings"
+
+	"github.com/bethropolis/tide/internal/core"
+	"github.com/bethropolis/tide/internal/event"
+	"github.com/bethropolis/tide/internal/logger"
+	"github.com/bethropolis/tide/internal/lsp"
+	"github.com/bethropolis/tide/internal/modehandler"
+	"github.com/bethropolis/tide/internal/tui"
+	"github.com/bethropolis/tide/internal/types"
+	"github.com/gdamore/tcell/v2"
+)
+
+// Size limits for documentation popups, in cells.
+const (
+	docPopupMaxWidth  = 80
+	docPopupMaxHeight = 15
+)
+
+// docPopup is a floating window showing rendered markdown by the cursor.
+type docPopup struct {
+	win    *tui.Window
+	editor *core.Editor   // Buffer the popup belongs to
+	anchor types.Position // Cursor position it was opened for
+}
+
+// showDocPopup renders markdown into a window next to the cursor, above it
+// when above is set, and returns it. Returns nil if there is nothing to show.
+func (a *App) showDocPopup(markdown string, above bool) *docPopup {
+	ed := a.getActiveEditor()
+	if ed == nil || strings.TrimSpace(markdown) == "" {
+		return nil
+	}
+	screenW, screenH := a.tuiManager.Size()
+	lines := tui.RenderMarkdown(markdown, min(docPopupMaxWidth, screenW-4))
+	if len(lines) == 0 {
+		return nil
+	}
+	w := 0
+	for _, l := range lines {
+		w = max(w, l.Width())
+	}
+	h := min(len(lines), docPopupMaxHeight)
+
+	x, y := a.cursorCell()
+	var r tui.Rect
+	if above {
+		r = tui.AboveRect(x, y, w+2, h+2, screenW, screenH)
+	} else {
+		r = tui.AnchoredRect(x-1, y, w+2, h+2, screenW, screenH)
+	}
+	th := a.activeTheme
+	win := tui.NewWindow(r, func(screen tcell.Screen, area tui.Rect, style tcell.Style) {
+		tui.DrawMarkdown(screen, area, lines, 0, style, th)
+	})
+	if len(lines) > h {
+		win.TitleRight = "more"
+	}
+	a.windows.Add(win)
+	return &docPopup{win: win, editor: ed, anchor: ed.GetCursor()}
+}
+
+// closeDocPopup removes the popup in *p, if any, and repaints the text it
+// covered. Returns true if a popup was open.
+func (a *App) closeDocPopup(p **docPopup) bool {
+	if *p == nil {
+		return false
+	}
+	a.windows.Remove((*p).win)
+	*p = nil
+	if ed := a.getActiveEditor(); ed != nil {
+		ed.MarkAllDirty()
+	}
+	return true
+}
+
+// cursorLSPPosition returns a function converting the cursor position, as it
+// is now, to an LSP position once the server's encoding is known.
+func cursorLSPPosition(ed *core.Editor) (func(lsp.Encoding) lsp.Position, error) {
+	cursor := ed.GetCursor()
+	line, err := ed.GetBuffer().Line(cursor.Line)
+	if err != nil {
+		return nil, err
+	}
+	line = append([]byte(nil), line...)
+	return func(enc lsp.Encoding) lsp.Position {
+		return lspPosition(line, cursor.Line, cursor.Col, enc)
+	}, nil
+}
+
+// handleTriggerHover shows the language server's documentation for the
+// symbol under the cursor (K).
+func (a *App) handleTriggerHover(e event.Event) bool {
+	a.closeDocPopup(&a.hover)
+	ed := a.getActiveEditor()
+	if ed == nil {
+		return false
+	}
+	pos, err := cursorLSPPosition(ed)
+	if err != nil {
+		return false
+	}
+	anchor := ed.GetCursor()
+
+	err = a.lspRequest(func(ctx context.Context, c *lsp.Client, path string) (interface{}, error) {
+		return c.Hover(ctx, path, pos(c.Encoding()))
+	}, func(result interface{}, _ map[string]*lspDocument, err error) {
+		if err != nil {
+			a.statusBar.SetTemporaryMessage("Hover failed: %v", err)
+			return
+		}
+		// Drop the answer if the cursor has moved on meanwhile
+		if a.getActiveEditor() != ed || ed.GetCursor() != anchor {
+			return
+		}
+		if a.hover = a.showDocPopup(result.(string), false); a.hover == nil {
+			a.statusBar.SetTemporaryMessage("No documentation under the cursor")
+		}
+	})
+	if err != nil {
+		a.statusBar.SetTemporaryMessage("%v", err)
+	}
+	a.requestRedraw()
+	return false
+}
+
+// updateSignatureHelp runs after each insert-mode edit. Typing ( or , asks
+// the language server for the signature of the surrounding call; typing )
+// closes the popup. Servers that are missing or fail are ignored quietly,
+// as the user did not ask for help explicitly.
+func (a *App) updateSignatureHelp() {
+	ed := a.getActiveEditor()
+	if ed == nil || a.modeHandler.GetCurrentMode() != modehandler.ModeInsert {
+		a.closeDocPopup(&a.signature)
+		return
+	}
+	cursor := ed.GetCursor()
+	line, err := ed.GetBuffer().Line(cursor.Line)
+	if err != nil || cursor.Col == 0 {
+		a.closeDocPopup(&a.signature)
+		return
+	}
+	runes := []rune(string(line))
+	if cursor.Col > len(runes) {
+		return
+	}
+	switch runes[cursor.Col-1] {
+	case ')':
+		a.closeDocPopup(&a.signature)
+		return
+	case '(', ',':
+	default:
+		if a.signature != nil && a.signature.anchor.Line != cursor.Line {
+			a.closeDocPopup(&a.signature)
+		}
+		return
+	}
+	if _, _, ok := a.lspServerFor(ed); !ok {
+		return
+	}
+
+	pos, err := cursorLSPPosition(ed)
+	if err != nil {
+		return
+	}
+	a.signatureSeq++
+	seq := a.signatureSeq
+	type answer struct {
+		help *lsp.SignatureHelp
+		enc  lsp.Encoding
+	}
+	err = a.lspRequest(func(ctx context.Context, c *lsp.Client, path string) (interface{}, error) {
+		help, err := c.SignatureHelp(ctx, path, pos(c.Encoding()))
+		return answer{help, c.Encoding()}, err
+	}, func(result interface{}, _ map[string]*lspDocument, err error) {
+		if seq != a.signatureSeq || a.getActiveEditor() != ed || a.modeHandler.GetCurrentMode() != modehandler.ModeInsert {
+			return // Superseded, or the user has moved on
+		}
+		if err != nil {
+			logger.Debugf("LSP: signature help: %v", err)
+			return
+		}
+		a.closeDocPopup(&a.signature)
+		if ans := result.(answer); ans.help != nil {
+			a.signature = a.showDocPopup(signatureMarkdown(ans.help, ans.enc), true)
+		}
+	})
+	if err != nil {
+		logger.Debugf("LSP: signature help: %v", err)
+	}
+}
+
+// signatureMarkdown renders the active signature with the active parameter
+// in bold, followed by its documentation.
+func signatureMarkdown(help *lsp.SignatureHelp, enc lsp.Encoding) string {
+	sig, param := help.Active()
+	label := "`" + sig.Label + "`"
+	if start, end, ok := sig.ParamRange(param, enc); ok {
+		label = "`" + sig.Label[:start] + "`**" + sig.Label[start:end] + "**`" + sig.Label[end:] + "`"
+	}
+	label = strings.ReplaceAll(label, "``", "")
+	if doc := sig.DocMarkdown(); doc != "" {
+		return label + "\n\n" + doc
+	}
+	return label
+}
@@ -0,0 +1,271 @@
+// internal/app/lsp_rename.go
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/bethropolis/tide/internal/core"
+	"github.com/bethropolis/tide/internal/logger"
+	"github.com/bethropolis/tide/internal/lsp"
+	"github.com/bethropolis/tide/internal/tui"
+	"github.com/bethropolis/tide/internal/types"
+)
+
+// renameResult is what the server answered to a rename request.
+type renameResult struct {
+	edit     *lsp.WorkspaceEdit
+	encoding lsp.Encoding
+}
+
+// editTarget groups the edits a workspace edit makes to one file.
+type editTarget struct {
+	path   string
+	editor *core.Editor // Non-nil if the file is open in a buffer
+	synced []byte       // Buffer text the server computed the edits against
+	edits  []lsp.TextEdit
+}
+
+// RenameSymbol implements :lsprename. It asks the language server to rename
+// the symbol under the cursor and shows the resulting edits grouped by file.
+// On confirmation open buffers are edited in memory as one undo step each;
+// all other files are rewritten on disk.
+func (a *App) RenameSymbol(newName string) error {
+	if strings.IndexFunc(newName, unicode.IsSpace) >= 0 || newName == "" {
+		return fmt.Errorf("usage: :lsprename <newname>")
+	}
+	ed := a.getActiveEditor()
+	if ed == nil {
+		return fmt.Errorf("no active buffer")
+	}
+	cursor := ed.GetCursor()
+	line, err := ed.GetBuffer().Line(cursor.Line)
+	if err != nil {
+		return err
+	}
+	line = append([]byte(nil), line...)
+
+	err = a.lspRequest(func(ctx context.Context, c *lsp.Client, path string) (interface{}, error) {
+		pos := lspPosition(line, cursor.Line, cursor.Col, c.Encoding())
+		edit, err := c.Rename(ctx, path, pos, newName)
+		if err != nil {
+			return nil, err
+		}
+		return &renameResult{edit: edit, encoding: c.Encoding()}, nil
+	}, func(result interface{}, docs map[string]*lspDocument, err error) {
+		if err != nil {
+			a.statusBar.SetTemporaryMessage("Rename failed: %v", err)
+			return
+		}
+		a.previewRename(result.(*renameResult), docs, newName)
+	})
+	if err != nil {
+		return err
+	}
+	a.statusBar.SetTemporaryMessage("Renaming to %s...", newName)
+	a.requestRedraw()
+	return nil
+}
+
+// previewRename shows the edits of a rename and applies them on confirmation.
+func (a *App) previewRename(result *renameResult, docs map[string]*lspDocument, newName string) {
+	targets, err := editTargets(result.edit, docs)
+	if err != nil {
+		a.statusBar.SetTemporaryMessage("Rename failed: %v", err)
+		return
+	}
+	if len(targets) == 0 {
+		a.statusBar.SetTemporaryMessage("Nothing to rename")
+		return
+	}
+
+	root, err := os.Getwd()
+	if err != nil {
+		root = ""
+	}
+	a.confirm.Show("Rename Symbol", renamePreview(root, newName, targets), []tui.ConfirmChoice{
+		{Key: 'a', Label: "pply", Action: func() { a.applyRename(targets, result.encoding) }},
+		{Key: 'c', Label: "ancel"},
+	})
+}
+
+// editTargets splits a workspace edit by file, sorted by path, pairing each
+// file with its buffer if it was among the synced documents.
+func editTargets(edit *lsp.WorkspaceEdit, docs map[string]*lspDocument) ([]*editTarget, error) {
+	files, err := edit.FileEdits()
+	if err != nil {
+		return nil, err
+	}
+	var targets []*editTarget
+	for path, edits := range files {
+		if len(edits) == 0 {
+			continue
+		}
+		t := &editTarget{path: path, edits: edits}
+		if doc, ok := docs[path]; ok {
+			t.editor, t.synced = doc.editor, doc.text
+		}
+		targets = append(targets, t)
+	}
+	sort.Slice(targets, func(i, j int) bool { return targets[i].path < targets[j].path })
+	return targets, nil
+}
+
+// renamePreview builds the dialog text in the same layout as the :S preview:
+// a summary line, then each file with its edit count and a few sample lines.
+func renamePreview(root, newName string, targets []*editTarget) []string {
+	total := 0
+	for _, t := range targets {
+		total += len(t.edits)
+	}
+	lines := []string{
+		fmt.Sprintf("Rename to %q: %d edit(s) in %d file(s)", newName, total, len(targets)),
+		"",
+	}
+
+	for i, t := range targets {
+		if i == replacePreviewFiles {
+			lines = append(lines, fmt.Sprintf("... and %d more file(s)", len(targets)-replacePreviewFiles))
+			break
+		}
+		name, err := filepath.Rel(root, t.path)
+		if err != nil || root == "" {
+			name = t.path
+		}
+		header := fmt.Sprintf("%s (%d)", name, len(t.edits))
+		if t.editor != nil {
+			header += " [open]"
+		}
+		lines = append(lines, header)
+
+		content := t.synced
+		if t.editor == nil {
+			content, _ = os.ReadFile(t.path) // Sample lines are best effort
+		}
+		src := bytes.Split(content, []byte("\n"))
+		shown := map[int]bool{}
+		for _, e := range t.edits {
+			n := e.Range.Start.Line
+			if shown[n] {
+				continue
+			}
+			if len(shown) == replacePreviewLines {
+				lines = append(lines, "    ...")
+				break
+			}
+			shown[n] = true
+			text := ""
+			if n < len(src) {
+				text = strings.TrimSpace(strings.ReplaceAll(string(src[n]), "\t", " "))
+			}
+			if r := []rune(text); len(r) > replacePreviewWidth {
+				text = string(r[:replacePreviewWidth-1]) + "…"
+			}
+			lines = append(lines, fmt.Sprintf("  %4d: %s", n+1, text))
+		}
+	}
+	return lines
+}
+
+// applyRename performs the edits confirmed in the preview.
+func (a *App) applyRename(targets []*editTarget, enc lsp.Encoding) {
+	edited, skipped, failed := a.applyEditTargets(targets, enc)
+	msg := fmt.Sprintf("Renamed in %d file(s)", edited)
+	if skipped > 0 {
+		msg += fmt.Sprintf(", %d modified buffer(s) skipped", skipped)
+	}
+	if failed > 0 {
+		msg += fmt.Sprintf(", %d file(s) failed (see log)", failed)
+	}
+	a.statusBar.SetTemporaryMessage("%s", msg)
+	a.requestRedraw()
+}
+
+// applyEditTargets applies edits to open buffers in memory, one undo step
+// each, and to other files on disk. Buffers edited since the server saw them
+// are skipped, as the edit ranges no longer match.
+func (a *App) applyEditTargets(targets []*editTarget, enc lsp.Encoding) (edited, skipped, failed int) {
+	for _, t := range targets {
+		var err error
+		if t.editor != nil {
+			if !bytes.Equal(t.editor.GetBuffer().Bytes(), t.synced) {
+				logger.Warnf("LSP: %s changed since the request, skipped", t.path)
+				skipped++
+				continue
+			}
+			a.asActive(t.editor, func() { err = applyEditsToEditor(t.editor, t.edits, enc) })
+		} else {
+			err = applyEditsToFile(t.path, t.edits, enc)
+		}
+		if err != nil {
+			logger.Warnf("LSP: %s: %v", t.path, err)
+			failed++
+			continue
+		}
+		edited++
+	}
+	if ed := a.getActiveEditor(); ed != nil {
+		ed.MarkAllDirty()
+	}
+	return edited, skipped, failed
+}
+
+// applyEditsToEditor applies text edits to an open buffer as a single undo
+// step, keeping the cursor where it was.
+func applyEditsToEditor(ed *core.Editor, edits []lsp.TextEdit, enc lsp.Encoding) error {
+	sorted := append([]lsp.TextEdit(nil), edits...)
+	lsp.SortEdits(sorted)
+
+	cursorBefore := ed.GetCursor()
+	hm := ed.GetHistoryManager()
+	if hm != nil {
+		hm.BeginTransaction()
+		defer hm.EndTransaction(cursorBefore)
+	}
+	buf := ed.GetBuffer()
+	toPos := func(p lsp.Position) (types.Position, error) {
+		line, err := buf.Line(p.Line)
+		if err != nil {
+			return types.Position{}, err
+		}
+		return types.Position{Line: p.Line, Col: lsp.ToRuneCol(line, p.Character, enc)}, nil
+	}
+	for _, e := range sorted {
+		start, err := toPos(e.Range.Start)
+		if err != nil {
+			return err
+		}
+		end, err := toPos(e.Range.End)
+		if err != nil {
+			return err
+		}
+		if _, err := ed.ReplaceRange(start, end, []byte(e.NewText)); err != nil {
+			return err
+		}
+	}
+	ed.SetCursor(cursorBefore)
+	return nil
+}
+
+// applyEditsToFile rewrites the file at path with edits applied.
+func applyEditsToFile(path string, edits []lsp.TextEdit, enc lsp.Encoding) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	out, err := lsp.ApplyEdits(data, edits, enc)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, out, info.Mode().Perm())
+}
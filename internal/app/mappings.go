@@ -0,0 +1,64 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bethropolis/tide/internal/input"
+)
+
+// ShowMappings lists the key mappings of modes whose left-hand side starts
+// with lhs (:map, :nmap and the like). A single mapping is shown in the
+// status bar, more in a read-only buffer. verbose (:verbose map) adds
+// where each mapping was made, and for a key with no mapping names the
+// keybinding it runs.
+func (a *App) ShowMappings(modes, lhs string, verbose bool) error {
+	mappings, err := a.modeHandler.Mappings(modes, lhs)
+	if err != nil {
+		return err
+	}
+	if len(mappings) == 0 {
+		if verbose {
+			if line, ok := a.describeBinding(lhs); ok {
+				a.statusBar.SetTemporaryMessage("%s", line)
+				return nil
+			}
+		}
+		a.statusBar.SetTemporaryMessage("No mapping found")
+		return nil
+	}
+
+	lines := make([]string, 0, len(mappings))
+	for _, m := range mappings {
+		noremap := " "
+		if m.NoRemap {
+			noremap = "*"
+		}
+		line := fmt.Sprintf("%c  %-14s %s %s", m.Mode, input.FormatKeySequence(m.LHS), noremap, input.FormatKeySequence(m.RHS))
+		if verbose {
+			line += "\n\tLast set from " + m.Source
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 1 && !verbose {
+		a.statusBar.SetTemporaryMessage("%s", lines[0])
+		return nil
+	}
+	a.showReport(&a.keymaps, strings.Join(lines, "\n")+"\n")
+	a.requestRedraw()
+	return nil
+}
+
+// describeBinding describes the keybinding of a single key lhs.
+func (a *App) describeBinding(lhs string) (string, bool) {
+	processor := a.modeHandler.GetInputProcessor()
+	keys, err := input.ParseKeySequence(lhs, processor.GetLeaderKey())
+	if err != nil || len(keys) != 1 {
+		return "", false
+	}
+	action, origin, ok := processor.Binding(keys[0])
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf("%s runs %s (%s keybinding)", input.FormatKeySequence(keys), input.NameFromAction(action), origin), true
+}
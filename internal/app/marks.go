@@ -0,0 +1,185 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bethropolis/tide/internal/core"
+	"github.com/bethropolis/tide/internal/event"
+	"github.com/bethropolis/tide/internal/logger"
+	"github.com/bethropolis/tide/internal/tui"
+	"github.com/bethropolis/tide/internal/types"
+)
+
+// SetMark sets mark name on the cursor of the active buffer (ma) and
+// remembers it for the file.
+func (a *App) SetMark(name rune) error {
+	ed := a.getActiveEditor()
+	if ed == nil {
+		return fmt.Errorf("no active buffer")
+	}
+	if err := ed.SetMark(name); err != nil {
+		return err
+	}
+	a.saveMarks(ed)
+	a.requestRedraw()
+	return nil
+}
+
+// JumpToMark moves the cursor to mark name of the active buffer ('a).
+func (a *App) JumpToMark(name rune) error {
+	ed := a.getActiveEditor()
+	if ed == nil {
+		return fmt.Errorf("no active buffer")
+	}
+	if err := ed.JumpToMark(name); err != nil {
+		return err
+	}
+	a.requestRedraw()
+	return nil
+}
+
+// ListMarks opens the shared picker listing the marks of the active buffer
+// with their lines (:marks). Choosing one goes to it.
+func (a *App) ListMarks() {
+	ed := a.getActiveEditor()
+	if ed == nil || a.picker == nil {
+		return
+	}
+	names := ed.Marks().Names()
+	if len(names) == 0 {
+		a.statusBar.SetTemporaryMessage("No marks set (m followed by a letter sets one)")
+		return
+	}
+	buf := ed.GetBuffer()
+	items := make([]tui.PickerItem, len(names))
+	for i, name := range names {
+		pos, _ := ed.MarkPosition(name)
+		text, _ := buf.Line(pos.Line)
+		items[i] = tui.PickerItem{
+			Label:       fmt.Sprintf("%c %d:%d", name, pos.Line+1, pos.Col+1),
+			Description: strings.TrimSpace(string(text)),
+			Value:       string(name),
+		}
+	}
+
+	a.picker.Title = "Marks"
+	a.picker.Items = items
+	a.picker.OnSelect = func(val string) {
+		if err := a.JumpToMark([]rune(val)[0]); err != nil {
+			a.statusBar.SetTemporaryMessage("%v", err)
+			a.requestRedraw()
+		}
+	}
+	a.picker.OnCancel = nil
+	a.picker.Activate()
+	a.requestRedraw()
+}
+
+// DeleteMarks removes the marks of the active buffer named by the letters
+// of names, spaces aside, or all of them when names is "!" (:delmarks).
+func (a *App) DeleteMarks(names string) error {
+	ed := a.getActiveEditor()
+	if ed == nil {
+		return fmt.Errorf("no active buffer")
+	}
+	var unset []string
+	if strings.TrimSpace(names) == "!" {
+		for _, name := range ed.Marks().Names() {
+			ed.DeleteMark(name)
+		}
+	} else {
+		for _, name := range strings.ReplaceAll(names, " ", "") {
+			if !ed.DeleteMark(name) {
+				unset = append(unset, string(name))
+			}
+		}
+	}
+	a.saveMarks(ed)
+	a.requestRedraw()
+	if len(unset) > 0 {
+		return fmt.Errorf("mark not set: %s", strings.Join(unset, " "))
+	}
+	return nil
+}
+
+// saveMarks remembers the marks of ed for its file, in its view file,
+// leaving the view saved there as it was.
+func (a *App) saveMarks(ed *core.Editor) {
+	if !a.viewable(ed) {
+		return
+	}
+	viewPath, err := viewFilePath(ed.GetBuffer().FilePath())
+	if err != nil {
+		logger.Warnf("Failed to save marks of '%s': %v", ed.GetBuffer().FilePath(), err)
+		return
+	}
+	state, err := readViewState(viewPath)
+	switch {
+	case errors.Is(err, os.ErrNotExist) && ed.Marks().Len() == 0:
+		return // Nothing to remember
+	case err != nil:
+		state = viewStateOf(ed)
+	default:
+		state.Marks = viewMarksOf(ed)
+	}
+	if err := writeViewState(viewPath, state); err != nil {
+		logger.Warnf("Failed to save marks of '%s': %v", ed.GetBuffer().FilePath(), err)
+	}
+}
+
+// loadMarks sets the marks remembered for the file of a newly opened
+// editor.
+func (a *App) loadMarks(ed *core.Editor) {
+	if !a.viewable(ed) {
+		return
+	}
+	viewPath, err := viewFilePath(ed.GetBuffer().FilePath())
+	if err != nil {
+		return
+	}
+	state, err := readViewState(viewPath)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			logger.Warnf("Failed to restore marks of '%s': %v", ed.GetBuffer().FilePath(), err)
+		}
+		return
+	}
+	restoreMarks(ed, state.Marks)
+}
+
+// restoreMarks replaces the marks of ed with saved ones, dropping those
+// past the end of the file.
+func restoreMarks(ed *core.Editor, saved []viewMark) {
+	ed.Marks().Clear()
+	for _, m := range saved {
+		name := []rune(m.Name)
+		if len(name) != 1 || m.Line >= ed.GetBuffer().LineCount() {
+			continue
+		}
+		ed.PutMark(name[0], types.Position{Line: m.Line, Col: m.Col})
+	}
+	ed.MarkAllDirty()
+}
+
+// handleBufferModifiedForMarks moves the marks of the active buffer with
+// their text.
+func (a *App) handleBufferModifiedForMarks(e event.Event) bool {
+	data, ok := e.Data.(event.BufferModifiedData)
+	if ed := a.getActiveEditor(); ok && ed != nil && ed.Marks().Len() > 0 {
+		ed.AdjustMarks(data.Edit)
+		ed.MarkAllDirty()
+	}
+	return false
+}
+
+// handleBufferSavedForMarks remembers where the marks of the saved buffer
+// are now that its file matches them.
+func (a *App) handleBufferSavedForMarks(e event.Event) bool {
+	if ed := a.getActiveEditor(); ed != nil {
+		a.saveMarks(ed)
+	}
+	return false
+}
@@ -0,0 +1,16 @@
+package app
+
+import "github.com/bethropolis/tide/internal/event"
+
+// handleBufferModifiedForConflicts has the merge conflicts of the edited
+// buffer looked for again the next time they are drawn.
+func (a *App) handleBufferModifiedForConflicts(e event.Event) bool {
+	data, _ := e.Data.(event.BufferModifiedData)
+	active := a.getActiveEditor()
+	for _, ed := range a.editors {
+		if ed == active || (data.FilePath != "" && ed.GetBuffer().FilePath() == data.FilePath) {
+			ed.InvalidateConflicts()
+		}
+	}
+	return false
+}
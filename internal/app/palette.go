@@ -0,0 +1,75 @@
+// internal/app/palette.go
+package app
+
+import (
+	"strings"
+
+	"github.com/bethropolis/tide/internal/commands"
+	"github.com/bethropolis/tide/internal/input"
+	"github.com/bethropolis/tide/internal/logger"
+	"github.com/bethropolis/tide/internal/tui"
+)
+
+// Picker values are prefixed so the palette can tell commands from actions.
+const (
+	paletteCommandPrefix = "cmd:"
+	paletteActionPrefix  = "action:"
+)
+
+// showCommandPalette opens the shared picker listing every registered
+// command and every user-facing action together with its key bindings.
+func (a *App) showCommandPalette() {
+	if a.picker == nil || a.modeHandler == nil {
+		return
+	}
+
+	var items []tui.PickerItem
+
+	for _, name := range a.modeHandler.CommandNames() {
+		items = append(items, tui.PickerItem{
+			Label:       ":" + name,
+			Description: commands.Describe(name),
+			Value:       paletteCommandPrefix + name,
+		})
+	}
+
+	processor := a.modeHandler.GetInputProcessor()
+	for _, action := range input.DescribedActions() {
+		desc := input.ActionDescription(action)
+		if keys := processor.KeysForAction(action); len(keys) > 0 {
+			desc += "  [" + strings.Join(keys, ", ") + "]"
+		}
+		items = append(items, tui.PickerItem{
+			Label:       input.NameFromAction(action),
+			Description: desc,
+			Value:       paletteActionPrefix + input.NameFromAction(action),
+		})
+	}
+
+	a.picker.Title = "Command Palette"
+	a.picker.Items = items
+	a.picker.OnSelect = a.runPaletteEntry
+	a.picker.OnCancel = nil
+	a.picker.ActivateFuzzy()
+	a.requestRedraw()
+}
+
+// runPaletteEntry executes the command or action chosen in the palette.
+func (a *App) runPaletteEntry(val string) {
+	switch {
+	case strings.HasPrefix(val, paletteCommandPrefix):
+		name := strings.TrimPrefix(val, paletteCommandPrefix)
+		logger.Debugf("Palette: running command ':%s'", name)
+		a.modeHandler.RunCommand(name)
+	case strings.HasPrefix(val, paletteActionPrefix):
+		name := strings.TrimPrefix(val, paletteActionPrefix)
+		action, ok := input.ActionFromName(name)
+		if !ok {
+			logger.Warnf("Palette: unknown action %q", name)
+			return
+		}
+		logger.Debugf("Palette: running action '%s'", name)
+		a.modeHandler.RunAction(action)
+	}
+	a.requestRedraw()
+}
@@ -0,0 +1,111 @@
+// internal/app/paste.go
+package app
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/bethropolis/tide/internal/config"
+	"github.com/bethropolis/tide/internal/logger"
+	"github.com/bethropolis/tide/internal/modehandler"
+	"github.com/bethropolis/tide/internal/tui"
+	"github.com/bethropolis/tide/internal/utils"
+	"github.com/gdamore/tcell/v2"
+)
+
+// maxDroppedFiles caps how many pasted paths are offered for opening.
+const maxDroppedFiles = 50
+
+// pastedKeyText converts a key received during a bracketed paste back into
+// the text the terminal pasted.
+func pastedKeyText(ev *tcell.EventKey) string {
+	switch ev.Key() {
+	case tcell.KeyRune:
+		return string(ev.Rune())
+	case tcell.KeyEnter, tcell.KeyLF:
+		return "\n"
+	case tcell.KeyTab:
+		return "\t"
+	}
+	return ""
+}
+
+// handlePaste handles text the terminal delivered as a bracketed paste. In
+// the editing modes it is inserted literally (or, for dragged-in files,
+// offered for opening); elsewhere it is replayed as ordinary key presses so
+// overlays and the command line keep working.
+func (a *App) handlePaste(text string) {
+	if text == "" {
+		return
+	}
+
+	overlayActive := (a.confirm != nil && a.confirm.IsActive()) ||
+		(a.fuzzyFinder != nil && a.fuzzyFinder.IsActive()) ||
+		(a.picker != nil && a.picker.IsActive())
+	mode := a.modeHandler.GetCurrentMode()
+	if overlayActive || (mode != modehandler.ModeNormal && mode != modehandler.ModeInsert) {
+		for _, r := range text {
+			key, ch := tcell.KeyRune, r
+			if r == '\n' {
+				key, ch = tcell.KeyEnter, 0
+			}
+			a.handleKeyEvent(tcell.NewEventKey(key, ch, tcell.ModNone))
+		}
+		return
+	}
+
+	if config.Get().Editor.OpenDroppedFiles {
+		if paths := existingPaths(utils.SplitDroppedPaths(text)); len(paths) > 0 {
+			a.offerOpenDropped(paths, text)
+			return
+		}
+	}
+	a.insertPasted(text)
+}
+
+// existingPaths returns paths if all of them exist and there are not too
+// many, otherwise nil.
+func existingPaths(paths []string) []string {
+	if len(paths) == 0 || len(paths) > maxDroppedFiles {
+		return nil
+	}
+	for _, p := range paths {
+		if _, err := os.Stat(p); err != nil {
+			return nil
+		}
+	}
+	return paths
+}
+
+// offerOpenDropped asks whether to open the pasted paths or insert the text.
+func (a *App) offerOpenDropped(paths []string, text string) {
+	message := []string{fmt.Sprintf("Open %d dropped file(s)?", len(paths))}
+	const maxListed = 8
+	for i, p := range paths {
+		if i == maxListed {
+			message = append(message, fmt.Sprintf("  ... and %d more", len(paths)-maxListed))
+			break
+		}
+		message = append(message, "  "+p)
+	}
+
+	a.confirm.Show("Dropped Files", message, []tui.ConfirmChoice{
+		{Key: 'o', Label: "pen", Action: func() {
+			for _, p := range paths {
+				a.OpenFile(p)
+			}
+		}},
+		{Key: 'i', Label: "nsert as text", Action: func() { a.insertPasted(text) }},
+		{Key: 'c', Label: "ancel"},
+	})
+	a.requestRedraw()
+}
+
+// insertPasted inserts pasted text at the cursor as a single undo step.
+func (a *App) insertPasted(text string) {
+	if err := a.getActiveEditor().InsertPastedText([]byte(text)); err != nil {
+		logger.Warnf("Paste failed: %v", err)
+		a.statusBar.SetTemporaryMessage("Paste failed: %v", err)
+	}
+	a.requestRedraw()
+}
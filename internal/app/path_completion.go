@@ -0,0 +1,50 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bethropolis/tide/internal/core"
+	"github.com/bethropolis/tide/internal/tui"
+	"github.com/bethropolis/tide/internal/types"
+	"github.com/bethropolis/tide/internal/utils"
+)
+
+// pathCompletions returns filesystem completions when the cursor is inside
+// a string that looks like a path. ok is false when it is not, so the caller
+// falls back to identifier completion. The directories are read again only
+// when the path moves to another one or the cursor leaves the string.
+func (a *App) pathCompletions(ed *core.Editor, cursor types.Position) (prefix string, items []tui.CompletionItem, ok bool) {
+	line, err := ed.GetBuffer().Line(cursor.Line)
+	if err != nil {
+		return "", nil, false
+	}
+	token, ok := utils.PathInString([]rune(string(line)), cursor.Col)
+	if !ok {
+		a.pathCompleter = utils.PathCompleter{} // Read afresh for the next path
+		return "", nil, false
+	}
+
+	// Relative paths are tried against the buffer's directory, then the project root.
+	cwd, _ := os.Getwd()
+	bufDir := cwd
+	if path := ed.GetBuffer().FilePath(); path != "" {
+		bufDir = filepath.Dir(path)
+	}
+	root := utils.FindProjectRoot(bufDir)
+	if root == "" {
+		root = cwd
+	}
+
+	prefix = token[strings.LastIndex(token, "/")+1:] // The name being typed
+	replaceLen := len([]rune(prefix))
+	for _, match := range a.pathCompleter.Complete(token, []string{bufDir, root}) {
+		label := match.Name
+		if match.IsDir {
+			label += "/"
+		}
+		items = append(items, tui.CompletionItem{InsertText: label, ReplaceLen: replaceLen, Label: label})
+	}
+	return prefix, items, true
+}
@@ -4,6 +4,7 @@ import (
 	"fmt" // For error wrapping
 
 	"github.com/bethropolis/tide/internal/logger"
+	"github.com/bethropolis/tide/internal/paths"
 	"github.com/bethropolis/tide/internal/plugin"
 
 	// Import desired plugin packages here
@@ -13,8 +14,6 @@ import (
 	// Import other plugins as they are created
 	// "github.com/bethropolis/tide/plugins/anotherplugin"
 	"github.com/bethropolis/tide/internal/plugin/lua"
-	"os"
-	"path/filepath"
 )
 
 // registerPlugins initializes and registers all known plugins with the manager.
@@ -42,6 +41,7 @@ func registerPlugins(pm *plugin.Manager) error {
 			// Log the error but continue registering others
 			wrappedErr := fmt.Errorf("failed to register plugin '%s': %w", pluginName, err)
 			logger.Errorf(wrappedErr.Error())
+			pm.RecordError(pluginName, err)
 			// Collect errors if needed, or just log and continue
 			if finalErr == nil {
 				finalErr = wrappedErr // Store the first error encountered
@@ -56,8 +56,7 @@ func registerPlugins(pm *plugin.Manager) error {
 	}
 
 	// 2. User config directory ~/.config/tide/plugins/lua
-	if configDir, err := os.UserConfigDir(); err == nil {
-		userPluginDir := filepath.Join(configDir, "tide", "plugins", "lua")
+	if userPluginDir, err := paths.Config("plugins", "lua"); err == nil {
 		if err := lua.LoadLuaPlugins(pm, userPluginDir); err != nil {
 			logger.Debugf("Error loading user lua plugins: %v", err)
 		}
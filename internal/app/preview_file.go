@@ -0,0 +1,22 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bethropolis/tide/internal/core"
+	"github.com/bethropolis/tide/internal/preview"
+)
+
+// loadPreview fills ed's buffer, read-only so that the file is never saved
+// over with it, with a text preview of the image or PDF at filePath at most
+// width columns wide, rendered off the event loop (see loadAsync).
+func (a *App) loadPreview(ed *core.Editor, filePath string, width int) {
+	a.loadAsync(ed, filePath, func(ctx context.Context) ([]byte, error) {
+		data, err := preview.Render(filePath, width)
+		if err != nil {
+			return nil, fmt.Errorf("failed to preview '%s': %w", filePath, err)
+		}
+		return data, nil
+	})
+}
@@ -0,0 +1,193 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/bethropolis/tide/internal/config"
+	"github.com/bethropolis/tide/internal/core/find"
+	"github.com/bethropolis/tide/internal/core/index"
+	"github.com/bethropolis/tide/internal/event"
+	"github.com/bethropolis/tide/internal/highlighter"
+	"github.com/bethropolis/tide/internal/logger"
+	"github.com/bethropolis/tide/internal/tui"
+	"github.com/bethropolis/tide/internal/types"
+	"github.com/bethropolis/tide/internal/utils"
+)
+
+// startIndex builds the project index in the background, when
+// editor.project_index is on. The project is the git repository around the
+// working directory, or the working directory itself. The grammars must be
+// registered first, as the highlighter does when it is created.
+func (a *App) startIndex() {
+	if !config.Get().Editor.ProjectIndex {
+		return
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		logger.Warnf("Project index: %v", err)
+		return
+	}
+	root := utils.FindProjectRoot(cwd)
+	if root == "" {
+		root = cwd
+	}
+	a.index = index.New(root)
+	ix := a.index
+	a.goAsync(func() {
+		began := time.Now()
+		files, truncated := ix.Build()
+		if truncated {
+			logger.Warnf("Project index: stopped after %d files in %s", files, root)
+		}
+		logger.Infof("Project index: %d files in %s indexed in %v", files, root, time.Since(began))
+	})
+}
+
+// handleBufferSavedForIndex indexes a saved file again.
+func (a *App) handleBufferSavedForIndex(e event.Event) bool {
+	if data, ok := e.Data.(event.BufferSavedData); ok && a.index != nil {
+		ix := a.index
+		a.goAsync(func() { ix.Update(data.FilePath) })
+	}
+	return false // Not consumed
+}
+
+// handleBufferRenamedForIndex moves a renamed file's entries to its new path.
+func (a *App) handleBufferRenamedForIndex(e event.Event) bool {
+	if data, ok := e.Data.(event.BufferRenamedData); ok && a.index != nil {
+		ix := a.index
+		a.goAsync(func() {
+			ix.Remove(data.OldPath)
+			ix.Update(data.NewPath)
+		})
+	}
+	return false // Not consumed
+}
+
+// handleFileDeletedForIndex drops a deleted file from the index.
+func (a *App) handleFileDeletedForIndex(e event.Event) bool {
+	if data, ok := e.Data.(event.FileDeletedData); ok && a.index != nil {
+		a.index.Remove(data.FilePath)
+	}
+	return false // Not consumed
+}
+
+// indexedSymbols returns the symbols in the project index, or an error
+// saying why there are none.
+func (a *App) indexedSymbols(symbols []index.Symbol, what string) ([]index.Symbol, error) {
+	switch {
+	case len(symbols) > 0:
+		return symbols, nil
+	case a.index == nil:
+		return nil, fmt.Errorf("project index is off (editor.project_index)")
+	case !a.index.Ready():
+		return nil, fmt.Errorf("%s: not found yet, the project is still being indexed", what)
+	}
+	return nil, fmt.Errorf("%s: not found", what)
+}
+
+// ShowSymbols lists the definitions in the project in the shared picker
+// (:symbols). Choosing one opens its file at the definition.
+func (a *App) ShowSymbols() error {
+	var all []index.Symbol
+	if a.index != nil {
+		all = a.index.Symbols()
+	}
+	symbols, err := a.indexedSymbols(all, "symbols")
+	if err != nil {
+		return err
+	}
+	if a.picker == nil {
+		return nil
+	}
+	items := make([]tui.PickerItem, len(symbols))
+	for i, s := range symbols {
+		items[i] = tui.PickerItem{
+			Label:       s.Name,
+			Description: fmt.Sprintf("%s %s:%d", symbolKind(s.Kind), a.indexPath(s.Path), s.Line+1),
+			Value:       strconv.Itoa(i),
+		}
+	}
+	a.picker.Title = fmt.Sprintf("Symbols (%d)", len(symbols))
+	a.picker.Items = items
+	a.picker.OnSelect = func(val string) {
+		if i, err := strconv.Atoi(val); err == nil && i < len(symbols) {
+			a.jumpToSymbol(symbols[i])
+		}
+	}
+	a.picker.OnCancel = nil
+	a.picker.Activate()
+	a.requestRedraw()
+	return nil
+}
+
+// GoToSymbol jumps to the definition of name, or of the word under the
+// cursor when name is empty (:symbol). When several files define it they
+// go to the quickfix list, starting at the first.
+func (a *App) GoToSymbol(name string) error {
+	if name == "" {
+		ed := a.getActiveEditor()
+		cursor := ed.GetCursor()
+		line, err := ed.GetBuffer().Line(cursor.Line)
+		if err != nil {
+			return err
+		}
+		word, _, ok := find.WordAt(line, cursor.Col)
+		if !ok {
+			return fmt.Errorf("no symbol under the cursor")
+		}
+		name = word
+	}
+	var found []index.Symbol
+	if a.index != nil {
+		found = a.index.Lookup(name)
+	}
+	symbols, err := a.indexedSymbols(found, name)
+	if err != nil {
+		return err
+	}
+	if len(symbols) == 1 {
+		a.jumpToSymbol(symbols[0])
+		return nil
+	}
+	items := make([]types.QuickfixItem, len(symbols))
+	for i, s := range symbols {
+		items[i] = types.QuickfixItem{
+			Path: s.Path,
+			Pos:  types.Position{Line: s.Line, Col: s.Col},
+			Text: fmt.Sprintf("%s %s", symbolKind(s.Kind), s.Name),
+		}
+	}
+	a.SetQuickfix(items)
+	a.jumpToQuickfix(0)
+	return nil
+}
+
+// jumpToSymbol opens the file defining s at its name.
+func (a *App) jumpToSymbol(s index.Symbol) {
+	if s.Path != a.getActiveEditor().GetBuffer().FilePath() {
+		a.OpenFile(s.Path)
+	}
+	a.getActiveEditor().SetCursor(types.Position{Line: s.Line, Col: s.Col})
+	a.requestRedraw()
+}
+
+// indexPath shows path relative to the indexed project.
+func (a *App) indexPath(path string) string {
+	if rel, err := filepath.Rel(a.index.Root(), path); err == nil {
+		return rel
+	}
+	return path
+}
+
+// symbolKind names a kind of definition in symbol lists.
+func symbolKind(kind highlighter.DefinitionKind) string {
+	if kind == highlighter.DefinitionClass {
+		return "type"
+	}
+	return "func"
+}
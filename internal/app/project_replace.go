@@ -0,0 +1,235 @@
+// internal/app/project_replace.go
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/bethropolis/tide/internal/core"
+	"github.com/bethropolis/tide/internal/core/find"
+	"github.com/bethropolis/tide/internal/core/grep"
+	"github.com/bethropolis/tide/internal/logger"
+	"github.com/bethropolis/tide/internal/tui"
+)
+
+// Limits for the :S preview dialog.
+const (
+	replacePreviewFiles = 10 // Files listed before "... and N more files"
+	replacePreviewLines = 2  // Sample lines shown per file
+	replacePreviewWidth = 70 // Sample lines are cut to this many runes
+)
+
+// replaceFile groups the matches of a project-wide replace in one file.
+type replaceFile struct {
+	path    string
+	editor  *core.Editor // Non-nil if the file is open in a buffer
+	matches []grep.Match
+}
+
+// ProjectReplace implements :S/pattern/replacement/[i]. It searches the
+// working directory (using the live contents of open buffers) and shows a
+// preview grouped by file. On confirmation open buffers are edited in memory
+// as one undo step each; all other files are rewritten on disk. Like :%s the
+// replacement is literal.
+func (a *App) ProjectReplace(pattern, replacement string, caseInsensitive bool) error {
+	flags := ""
+	if caseInsensitive {
+		flags = "(?i)"
+	}
+	re, err := regexp.Compile(flags + pattern)
+	if err != nil {
+		return fmt.Errorf("invalid search pattern: %w", err)
+	}
+	root, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	// Open buffers inside root are searched in memory, not on disk
+	open := make(map[string]*core.Editor)
+	for _, ed := range a.editors {
+		if _, isDir := a.dirViews[ed]; isDir || ed.GetBuffer().FilePath() == "" {
+			continue
+		}
+		abs, err := filepath.Abs(ed.GetBuffer().FilePath())
+		if err == nil && strings.HasPrefix(abs, root+string(filepath.Separator)) {
+			open[abs] = ed
+		}
+	}
+
+	matches, err := grep.Search(root, re, func(path string) bool {
+		_, ok := open[path]
+		return ok
+	})
+	if err != nil {
+		return err
+	}
+	for path, ed := range open {
+		matches = append(matches, grep.SearchLines(path, ed.GetBuffer().Lines(), re)...)
+	}
+	if len(matches) == 0 {
+		a.statusBar.SetTemporaryMessage("Pattern not found: %s", pattern)
+		return nil
+	}
+
+	files := groupReplaceMatches(matches, open)
+	a.confirm.Show("Replace in Project", replacePreview(root, pattern, replacement, files), []tui.ConfirmChoice{
+		{Key: 'a', Label: "pply", Action: func() { a.applyProjectReplace(files, re, pattern, replacement, caseInsensitive) }},
+		{Key: 'c', Label: "ancel"},
+	})
+	a.requestRedraw()
+	return nil
+}
+
+// groupReplaceMatches groups matches by file, sorted by path and line.
+func groupReplaceMatches(matches []grep.Match, open map[string]*core.Editor) []*replaceFile {
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Path != matches[j].Path {
+			return matches[i].Path < matches[j].Path
+		}
+		return matches[i].Line < matches[j].Line
+	})
+	var files []*replaceFile
+	for _, m := range matches {
+		if len(files) == 0 || files[len(files)-1].path != m.Path {
+			files = append(files, &replaceFile{path: m.Path, editor: open[m.Path]})
+		}
+		f := files[len(files)-1]
+		f.matches = append(f.matches, m)
+	}
+	return files
+}
+
+// replacePreview builds the dialog text: a summary line, then each file with
+// its match count and a few sample lines.
+func replacePreview(root, pattern, replacement string, files []*replaceFile) []string {
+	total := 0
+	for _, f := range files {
+		for _, m := range f.matches {
+			total += m.Count
+		}
+	}
+	lines := []string{
+		fmt.Sprintf("Replace /%s/ with %q: %d match(es) in %d file(s)", pattern, replacement, total, len(files)),
+		"",
+	}
+
+	for i, f := range files {
+		if i == replacePreviewFiles {
+			lines = append(lines, fmt.Sprintf("... and %d more file(s)", len(files)-replacePreviewFiles))
+			break
+		}
+		name, err := filepath.Rel(root, f.path)
+		if err != nil {
+			name = f.path
+		}
+		count := 0
+		for _, m := range f.matches {
+			count += m.Count
+		}
+		header := fmt.Sprintf("%s (%d)", name, count)
+		if f.editor != nil {
+			header += " [open]"
+		}
+		lines = append(lines, header)
+		for j, m := range f.matches {
+			if j == replacePreviewLines {
+				lines = append(lines, fmt.Sprintf("    ... %d more line(s)", len(f.matches)-replacePreviewLines))
+				break
+			}
+			text := strings.TrimSpace(strings.ReplaceAll(m.Text, "\t", " "))
+			if r := []rune(text); len(r) > replacePreviewWidth {
+				text = string(r[:replacePreviewWidth-1]) + "…"
+			}
+			lines = append(lines, fmt.Sprintf("  %4d: %s", m.Line+1, text))
+		}
+	}
+	return lines
+}
+
+// applyProjectReplace performs the replacements confirmed in the preview.
+func (a *App) applyProjectReplace(files []*replaceFile, re *regexp.Regexp, pattern, replacement string, caseInsensitive bool) {
+	replaced, changedFiles, failed := 0, 0, 0
+	for _, f := range files {
+		var n int
+		var err error
+		if f.editor != nil {
+			n, err = a.replaceInEditor(f.editor, pattern, replacement, caseInsensitive)
+		} else {
+			n, err = replaceInFile(f.path, re, replacement)
+		}
+		if err != nil {
+			logger.Warnf("Project replace: %s: %v", f.path, err)
+			failed++
+			continue
+		}
+		if n > 0 {
+			replaced += n
+			changedFiles++
+		}
+	}
+
+	msg := fmt.Sprintf("Replaced %d occurrence(s) in %d file(s)", replaced, changedFiles)
+	if failed > 0 {
+		msg += fmt.Sprintf(", %d file(s) failed (see log)", failed)
+	}
+	a.statusBar.SetTemporaryMessage("%s", msg)
+	if ed := a.getActiveEditor(); ed != nil {
+		ed.MarkAllDirty()
+	}
+	a.requestRedraw()
+}
+
+// replaceInEditor runs the replace in an open buffer as a single undo step.
+func (a *App) replaceInEditor(ed *core.Editor, pattern, replacement string, caseInsensitive bool) (n int, err error) {
+	a.asActive(ed, func() { n, err = ed.ReplaceAll(pattern, replacement, caseInsensitive) })
+	return n, err
+}
+
+// asActive runs fn with ed as the active editor, so the BufferModified
+// events its edits dispatch reach its own highlighter.
+func (a *App) asActive(ed *core.Editor, fn func()) {
+	prev := a.activeEditorIndex
+	for i, other := range a.editors {
+		if other == ed {
+			a.activeEditorIndex = i
+			break
+		}
+	}
+	defer func() { a.activeEditorIndex = prev }()
+	fn()
+}
+
+// replaceInFile replaces every match of re in the file at path, line by line,
+// and rewrites it if anything changed.
+func replaceInFile(path string, re *regexp.Regexp, replacement string) (int, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	lines := bytes.Split(data, []byte("\n"))
+	for i, line := range lines {
+		if n := len(re.FindAllIndex(line, -1)); n > 0 {
+			count += n
+			lines[i] = re.ReplaceAll(line, []byte(find.ReplacementTemplate(replacement)))
+		}
+	}
+	if count == 0 {
+		return 0, nil
+	}
+	if err := os.WriteFile(path, bytes.Join(lines, []byte("\n")), info.Mode().Perm()); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
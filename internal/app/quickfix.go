@@ -0,0 +1,81 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	"github.com/bethropolis/tide/internal/tui"
+	"github.com/bethropolis/tide/internal/types"
+)
+
+// SetQuickfix replaces the quickfix list. An empty list clears it.
+func (a *App) SetQuickfix(items []types.QuickfixItem) {
+	a.quickfix = items
+	a.quickfixIdx = -1
+}
+
+// OpenQuickfix lists the quickfix entries in the shared picker; choosing one
+// jumps to its location.
+func (a *App) OpenQuickfix() error {
+	if len(a.quickfix) == 0 {
+		return fmt.Errorf("quickfix list is empty")
+	}
+	if a.picker == nil {
+		return nil
+	}
+	items := make([]tui.PickerItem, len(a.quickfix))
+	for i, item := range a.quickfix {
+		name := filepath.Base(item.Path)
+		if item.Path == "" {
+			name = "[No Name]"
+		}
+		items[i] = tui.PickerItem{
+			Label:       fmt.Sprintf("%s:%d:%d", name, item.Pos.Line+1, item.Pos.Col+1),
+			Description: item.Text,
+			Value:       strconv.Itoa(i),
+		}
+	}
+	a.picker.Title = "Quickfix"
+	a.picker.Items = items
+	a.picker.OnSelect = func(val string) {
+		if i, err := strconv.Atoi(val); err == nil {
+			a.jumpToQuickfix(i)
+		}
+	}
+	a.picker.OnCancel = nil
+	a.picker.Activate()
+	a.requestRedraw()
+	return nil
+}
+
+// QuickfixStep jumps delta entries forward (or backward) in the quickfix list.
+func (a *App) QuickfixStep(delta int) error {
+	if len(a.quickfix) == 0 {
+		return fmt.Errorf("quickfix list is empty")
+	}
+	i := a.quickfixIdx + delta
+	if a.quickfixIdx < 0 && delta < 0 {
+		i = len(a.quickfix) - 1
+	}
+	if i < 0 || i >= len(a.quickfix) {
+		return fmt.Errorf("no more quickfix items")
+	}
+	a.jumpToQuickfix(i)
+	return nil
+}
+
+// jumpToQuickfix opens the file of entry i if needed and moves the cursor to it.
+func (a *App) jumpToQuickfix(i int) {
+	if i < 0 || i >= len(a.quickfix) {
+		return
+	}
+	item := a.quickfix[i]
+	a.quickfixIdx = i
+	if item.Path != "" && item.Path != a.getActiveEditor().GetBuffer().FilePath() {
+		a.OpenFile(item.Path)
+	}
+	a.getActiveEditor().SetCursor(item.Pos)
+	a.statusBar.SetTemporaryMessage("(%d of %d) %s", i+1, len(a.quickfix), item.Text)
+	a.requestRedraw()
+}
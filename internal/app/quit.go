@@ -0,0 +1,121 @@
+// internal/app/quit.go
+package app
+
+import (
+	"github.com/bethropolis/tide/internal/core"
+	"github.com/bethropolis/tide/internal/i18n"
+	"github.com/bethropolis/tide/internal/logger"
+	"github.com/bethropolis/tide/internal/tui"
+)
+
+// signalQuit closes the quit channel once; later calls are ignored.
+func (a *App) signalQuit() {
+	select {
+	case <-a.quit:
+		// Already closed
+	default:
+		close(a.quit)
+	}
+}
+
+// modifiedEditors returns every open editor with unsaved changes.
+func (a *App) modifiedEditors() []*core.Editor {
+	var modified []*core.Editor
+	for _, ed := range a.editors {
+		if ed.GetBuffer().IsModified() {
+			modified = append(modified, ed)
+		}
+	}
+	return modified
+}
+
+// editorDisplayName returns the file path of an editor or "[No Name]".
+func editorDisplayName(ed *core.Editor) string {
+	if name := ed.GetBuffer().FilePath(); name != "" {
+		return name
+	}
+	return i18n.T("status.no_name")
+}
+
+// RequestQuit quits immediately when nothing is modified; otherwise it shows
+// a dialog summarising the modified buffers with options to save all,
+// review them one by one, or discard all changes.
+func (a *App) RequestQuit() {
+	modified := a.modifiedEditors()
+	if len(modified) == 0 {
+		logger.Debugf("App: Quit requested (no modified buffers).")
+		a.signalQuit()
+		return
+	}
+
+	message := []string{i18n.T("quit.unsaved", len(modified))}
+	const maxListed = 8
+	for i, ed := range modified {
+		if i == maxListed {
+			message = append(message, i18n.T("quit.more", len(modified)-maxListed))
+			break
+		}
+		message = append(message, "  "+editorDisplayName(ed))
+	}
+
+	a.confirm.Show(i18n.T("quit.title"), message, []tui.ConfirmChoice{
+		{Key: 's', Label: "ave all", Action: a.saveAllAndQuit},
+		{Key: 'r', Label: "eview", Action: func() { a.reviewModified(modified) }},
+		{Key: 'd', Label: "iscard all", Action: a.signalQuit},
+		{Key: 'c', Label: "ancel"},
+	})
+	a.requestRedraw()
+}
+
+// saveAllAndQuit saves every modified buffer and quits if all saves succeed.
+func (a *App) saveAllAndQuit() {
+	for _, ed := range a.modifiedEditors() {
+		if err := ed.SaveBuffer(); err != nil {
+			a.switchToEditor(ed)
+			a.statusBar.SetErrorMessage(i18n.T("quit.save_failed", editorDisplayName(ed), err))
+			a.requestRedraw()
+			return
+		}
+	}
+	a.signalQuit()
+}
+
+// reviewModified asks about each modified buffer in turn, switching to it so
+// the user can see what they are deciding on. The app quits after the last
+// one unless the user cancels or a save fails.
+func (a *App) reviewModified(remaining []*core.Editor) {
+	if len(remaining) == 0 {
+		a.signalQuit()
+		return
+	}
+	ed, rest := remaining[0], remaining[1:]
+	a.switchToEditor(ed)
+
+	a.confirm.Show(i18n.T("quit.title"), []string{i18n.T("quit.save_changes", editorDisplayName(ed))}, []tui.ConfirmChoice{
+		{Key: 'y', Label: "es", Action: func() {
+			if err := ed.SaveBuffer(); err != nil {
+				a.statusBar.SetErrorMessage(i18n.T("quit.save_failed", editorDisplayName(ed), err))
+				a.requestRedraw()
+				return
+			}
+			a.reviewModified(rest)
+		}},
+		{Key: 'n', Label: "o", Action: func() { a.reviewModified(rest) }},
+		{Key: 'c', Label: "ancel"},
+	})
+	a.requestRedraw()
+}
+
+// switchToEditor makes ed the active editor if it is open.
+func (a *App) switchToEditor(ed *core.Editor) {
+	for i, other := range a.editors {
+		if other == ed {
+			a.activeEditorIndex = i
+			if a.modeHandler != nil {
+				a.modeHandler.SetEditor(ed)
+			}
+			ed.MarkAllDirty()
+			return
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/bethropolis/tide/internal/core"
+	"github.com/bethropolis/tide/internal/highlighter/lang"
+	"github.com/bethropolis/tide/internal/logger"
+)
+
+const (
+	remoteFetchTimeout = 15 * time.Second
+	remoteMaxBytes     = 16 << 20 // Larger responses are refused rather than truncated
+)
+
+// contentTypeExts maps the media types of fetched resources to the file
+// extension used to pick their language, for URLs whose path has none.
+var contentTypeExts = map[string]string{
+	"application/json":         ".json",
+	"application/javascript":   ".js",
+	"text/javascript":          ".js",
+	"application/x-javascript": ".js",
+	"text/x-python":            ".py",
+	"application/x-python":     ".py",
+	"text/x-go":                ".go",
+	"text/x-rust":              ".rs",
+	"text/rust":                ".rs",
+	"text/markdown":            ".md",
+	"text/html":                ".html",
+	"text/css":                 ".css",
+	"application/xml":          ".xml",
+	"text/xml":                 ".xml",
+	"application/yaml":         ".yaml",
+	"application/x-yaml":       ".yaml",
+	"text/yaml":                ".yaml",
+	"application/toml":         ".toml",
+	"text/x-shellscript":       ".sh",
+	"application/x-sh":         ".sh",
+}
+
+// isURL reports whether filePath names an http(s) resource rather than a
+// file on disk.
+func isURL(filePath string) bool {
+	return strings.HasPrefix(filePath, "http://") || strings.HasPrefix(filePath, "https://")
+}
+
+// fetchURL downloads rawURL, returning its body and media type; canceling
+// ctx stops it.
+func fetchURL(ctx context.Context, rawURL string) ([]byte, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, remoteFetchTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("User-Agent", "tide")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, "", fmt.Errorf("server returned %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, remoteMaxBytes+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if len(data) > remoteMaxBytes {
+		return nil, "", fmt.Errorf("response is larger than %d MiB", remoteMaxBytes>>20)
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	return data, mediaType, nil
+}
+
+// loadURL fetches the resource at rawURL into ed's buffer, read-only and
+// named after the URL, off the event loop (see loadAsync). When the URL's
+// path has no extension the language is taken from the content type
+// instead.
+func (a *App) loadURL(ed *core.Editor, rawURL string) {
+	a.loadAsync(ed, rawURL, func(ctx context.Context) ([]byte, error) {
+		data, mediaType, err := fetchURL(ctx, rawURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch '%s': %w", rawURL, err)
+		}
+		if ext, ok := contentTypeExts[mediaType]; ok {
+			if u, err := url.Parse(rawURL); err == nil && path.Ext(u.Path) == "" {
+				lang.SetFileType(rawURL, ext)
+			}
+		}
+		logger.Infof("Fetched '%s' (%d bytes, %s)", rawURL, len(data), mediaType)
+		return data, nil
+	})
+}
@@ -0,0 +1,44 @@
+package app
+
+import "github.com/bethropolis/tide/internal/types"
+
+// RunRepeatable runs fn as one undoable change of the active buffer and
+// makes "." in normal mode run it again, so plugin operations repeat and
+// undo like built-in ones.
+func (a *App) RunRepeatable(name string, fn func() error) error {
+	if err := a.runChange(fn); err != nil {
+		return err
+	}
+	if a.modeHandler != nil {
+		a.modeHandler.SetRepeat(name, func() error { return a.runChange(fn) })
+	}
+	return nil
+}
+
+// runChange runs fn inside one undo transaction of the active editor. Edits
+// fn makes while another change is open join that one.
+func (a *App) runChange(fn func() error) error {
+	ed := a.getActiveEditor()
+	if ed == nil {
+		return fn()
+	}
+	if hist := ed.GetHistoryManager(); hist != nil && !hist.InTransaction() {
+		hist.BeginTransaction()
+		defer hist.EndTransaction(ed.GetCursor())
+	}
+	defer a.requestRedraw()
+	return fn()
+}
+
+// editRange replaces the text from start up to end with text in the active
+// buffer as an undoable change, leaving the cursor where it was.
+func (a *App) editRange(start, end types.Position, text []byte) error {
+	ed := a.getActiveEditor()
+	cursor := ed.GetCursor()
+	if _, err := ed.ReplaceRange(start, end, text); err != nil {
+		return err
+	}
+	ed.SetCursor(cursor)
+	a.requestRedraw()
+	return nil
+}
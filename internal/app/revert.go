@@ -0,0 +1,78 @@
+package app
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/bethropolis/tide/internal/core"
+)
+
+// RevertToSaved discards the unsaved changes of the active buffer, putting
+// back the text of its file as one undoable edit, so undo brings the
+// changes back (:revert).
+func (a *App) RevertToSaved() error {
+	ed, err := a.reloadableEditor()
+	if err != nil {
+		return err
+	}
+	if !ed.GetBuffer().IsModified() {
+		a.statusBar.SetTemporaryMessage("No unsaved changes")
+		return nil
+	}
+	if err := a.reloadFromDisk(ed); err != nil {
+		return err
+	}
+	a.statusBar.SetTemporaryMessage("Reverted to the saved file (u to undo)")
+	return nil
+}
+
+// ReloadBuffer reads the active buffer's file again, such as after another
+// program changed it, discarding unsaved changes (:e!). Only the lines
+// that differ are edited, as one undoable change, so the undo history, the
+// highlighting and the cursor survive wherever the text did.
+func (a *App) ReloadBuffer() error {
+	ed, err := a.reloadableEditor()
+	if err != nil {
+		return err
+	}
+	if err := a.reloadFromDisk(ed); err != nil {
+		return err
+	}
+	a.statusBar.SetTemporaryMessage("Reloaded %s", ed.GetBuffer().FilePath())
+	return nil
+}
+
+// reloadableEditor returns the active editor when it holds a file that can
+// be read back into it.
+func (a *App) reloadableEditor() (*core.Editor, error) {
+	ed := a.getActiveEditor()
+	if ed == nil || ed.GetBuffer().FilePath() == "" {
+		return nil, fmt.Errorf("buffer has no file name")
+	}
+	if _, isDir := a.dirViews[ed]; isDir {
+		return nil, fmt.Errorf("not a file")
+	}
+	if ed.GetBuffer().ReadOnly() {
+		return nil, fmt.Errorf("buffer is read-only")
+	}
+	return ed, nil
+}
+
+// reloadFromDisk makes ed's text that of its file, editing only what
+// differs, and marks it unmodified.
+func (a *App) reloadFromDisk(ed *core.Editor) error {
+	buf := ed.GetBuffer()
+	saved, err := os.ReadFile(buf.FilePath())
+	if err != nil {
+		return err
+	}
+	if err := ed.Rewrite(saved); err != nil {
+		return err
+	}
+	buf.SetModified(false)
+
+	ed.ScrollToCursor()
+	a.updateStatusBarContent()
+	a.requestRedraw()
+	return nil
+}
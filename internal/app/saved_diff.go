@@ -0,0 +1,271 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bethropolis/tide/internal/core"
+	"github.com/bethropolis/tide/internal/event"
+	"github.com/bethropolis/tide/internal/git"
+	"github.com/bethropolis/tide/internal/tui"
+	"github.com/bethropolis/tide/internal/types"
+	"github.com/bethropolis/tide/internal/utils"
+)
+
+// savedDiffDelay is how long edits settle before the signs of unsaved
+// changes are worked out again.
+const savedDiffDelay = 300 * time.Millisecond
+
+// savedDiff holds the unsaved changes of a buffer shown with :diffsaved, as
+// the hunks of a diff from its file on disk to its text. revision counts
+// the edits, so answers about older text are dropped.
+type savedDiff struct {
+	revision int
+	hunks    []git.Hunk
+	settle   utils.Debouncer
+}
+
+// savedVersion is the hunk base of the file as saved on disk. A file not
+// written yet is empty, so all of the buffer is new.
+func savedVersion(_ context.Context, path string) ([]byte, error) {
+	text, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return text, err
+}
+
+// startSavedDiff turns on the signs of unsaved changes for the active
+// buffer, if they are not already.
+func (a *App) startSavedDiff() (*core.Editor, *savedDiff, error) {
+	ed := a.getActiveEditor()
+	if ed == nil || ed.GetBuffer().FilePath() == "" {
+		return nil, nil, fmt.Errorf("buffer has no file name")
+	}
+	if _, isDir := a.dirViews[ed]; isDir {
+		return nil, nil, fmt.Errorf("not a file")
+	}
+	if a.savedDiffs == nil {
+		a.savedDiffs = make(map[*core.Editor]*savedDiff)
+	}
+	d, ok := a.savedDiffs[ed]
+	if !ok {
+		d = &savedDiff{}
+		a.savedDiffs[ed] = d
+	}
+	return ed, d, nil
+}
+
+// DiffSaved marks the lines of the active buffer that differ from its file
+// on disk in the gutter, keeping the signs up to date as it is edited and
+// saved, and lists the changes in the picker (:diffsaved).
+func (a *App) DiffSaved() error {
+	ed, d, err := a.startSavedDiff()
+	if err != nil {
+		return err
+	}
+	a.refreshSavedDiff(ed, d, func() { a.listSavedHunks(ed, d) })
+	return nil
+}
+
+// StopDiffSaved removes the signs of unsaved changes from the active buffer
+// (:diffsaved off).
+func (a *App) StopDiffSaved() {
+	ed := a.getActiveEditor()
+	if ed == nil {
+		return
+	}
+	delete(a.savedDiffs, ed)
+	ed.SetLineMarks(nil)
+	a.requestRedraw()
+}
+
+// RevertSavedHunk puts the lines of the unsaved change under the cursor
+// back as they are on disk, as one undoable edit (:diffsaved revert).
+func (a *App) RevertSavedHunk() error {
+	return a.withHunk(savedVersion, func(ed *core.Editor, _ string, h git.Hunk) {
+		a.revertHunk(ed, h)
+	})
+}
+
+// JumpSavedHunk moves the cursor to the next unsaved change, or the
+// previous one, wrapping around the buffer (:diffsaved next, prev). It
+// turns the signs on too.
+func (a *App) JumpSavedHunk(forward bool) error {
+	ed, d, err := a.startSavedDiff()
+	if err != nil {
+		return err
+	}
+	a.refreshSavedDiff(ed, d, func() {
+		if len(d.hunks) == 0 {
+			a.statusBar.SetTemporaryMessage("No unsaved changes")
+			return
+		}
+		if a.getActiveEditor() != ed {
+			return
+		}
+		cursor := ed.GetCursor().Line
+		target := -1
+		for i := range d.hunks {
+			h := d.hunks[i]
+			if !forward {
+				h = d.hunks[len(d.hunks)-1-i]
+			}
+			if line := hunkLine(h); (forward && line > cursor) || (!forward && line < cursor) {
+				target = line
+				break
+			}
+		}
+		if target < 0 { // Wrap around
+			target = hunkLine(d.hunks[0])
+			if !forward {
+				target = hunkLine(d.hunks[len(d.hunks)-1])
+			}
+		}
+		ed.SetCursor(types.Position{Line: target})
+		ed.ScrollToCursor()
+	})
+	return nil
+}
+
+// refreshSavedDiff diffs the buffer of ed against its file, off the event
+// loop, then updates its signs and calls then. Nothing happens if the
+// buffer changed or :diffsaved was turned off meanwhile.
+func (a *App) refreshSavedDiff(ed *core.Editor, d *savedDiff, then func()) {
+	revision := d.revision
+	path := ed.GetBuffer().FilePath()
+	text := ed.GetBuffer().Bytes()
+	a.goAsync(func() {
+		hunks, err := func() ([]git.Hunk, error) {
+			ctx, cancel := context.WithTimeout(context.Background(), hunkTimeout)
+			defer cancel()
+			saved, err := savedVersion(ctx, path)
+			if err != nil {
+				return nil, err
+			}
+			return git.Diff(ctx, saved, text)
+		}()
+		a.schedule(func() {
+			if a.savedDiffs[ed] != d || d.revision != revision {
+				return
+			}
+			if err != nil {
+				a.statusBar.SetTemporaryMessage("diffsaved: %v", err)
+				a.requestRedraw()
+				return
+			}
+			d.hunks = hunks
+			ed.SetLineMarks(savedLineMarks(hunks))
+			if then != nil {
+				then()
+			}
+			a.requestRedraw()
+		})
+	})
+}
+
+// handleBufferChangedForSavedDiff works out the signs of unsaved changes
+// again once edits to a buffer settle, and after it was saved, reloaded or
+// renamed, for every editor on the file the event is about.
+func (a *App) handleBufferChangedForSavedDiff(e event.Event) bool {
+	var path string
+	switch data := e.Data.(type) {
+	case event.BufferModifiedData:
+		path = data.FilePath
+	case event.BufferSavedData:
+		path = data.FilePath
+	case event.BufferLoadedData:
+		path = data.FilePath
+	case event.BufferRenamedData:
+		path = data.NewPath
+	}
+	if path == "" {
+		return false // :diffsaved needs a file
+	}
+	for ed, d := range a.savedDiffs {
+		if ed.GetBuffer().FilePath() != path {
+			continue
+		}
+		ed, d := ed, d
+		d.revision++
+		d.settle.Debounce(savedDiffDelay, func() {
+			a.schedule(func() {
+				if a.savedDiffs[ed] == d {
+					a.refreshSavedDiff(ed, d, nil)
+				}
+			})
+		})
+	}
+	return false
+}
+
+// listSavedHunks shows the unsaved changes of ed in the picker; choosing
+// one moves the cursor there.
+func (a *App) listSavedHunks(ed *core.Editor, d *savedDiff) {
+	if len(d.hunks) == 0 {
+		a.statusBar.SetTemporaryMessage("No unsaved changes")
+		return
+	}
+	if a.picker == nil {
+		return
+	}
+	items := make([]tui.PickerItem, len(d.hunks))
+	for i, h := range d.hunks {
+		first := ""
+		if len(h.Lines) > 0 {
+			first = h.Lines[0][:1] + " " + strings.TrimSpace(h.Lines[0][1:])
+		}
+		items[i] = tui.PickerItem{
+			Label:       fmt.Sprintf("%d %s", hunkLine(h)+1, first),
+			Description: hunkSummary(h),
+			Value:       strconv.Itoa(hunkLine(h)),
+		}
+	}
+
+	a.picker.Title = fmt.Sprintf("Unsaved changes (%d)", len(d.hunks))
+	a.picker.Items = items
+	a.picker.OnSelect = func(val string) {
+		line, _ := strconv.Atoi(val)
+		if a.getActiveEditor() != ed {
+			return
+		}
+		ed.SetCursor(types.Position{Line: min(line, ed.GetBuffer().LineCount()-1)})
+		ed.ScrollToCursor()
+		a.requestRedraw()
+	}
+	a.picker.OnCancel = nil
+	a.picker.Activate()
+	a.requestRedraw()
+}
+
+// hunkLine returns the 0-based buffer line a hunk is shown on: its first
+// new line, or for a deletion the line before it.
+func hunkLine(h git.Hunk) int {
+	return max(h.NewStart-1, 0)
+}
+
+// savedLineMarks returns the gutter signs of hunks: added, changed, or a
+// deletion on the line before it.
+func savedLineMarks(hunks []git.Hunk) map[int]core.LineMark {
+	marks := make(map[int]core.LineMark)
+	for _, h := range hunks {
+		if h.NewCount == 0 {
+			marks[hunkLine(h)] = core.LineRemoved
+			continue
+		}
+		mark := core.LineChanged
+		if h.OldCount == 0 {
+			mark = core.LineAdded
+		}
+		for line := h.NewStart - 1; line < h.NewStart-1+h.NewCount; line++ {
+			marks[line] = mark
+		}
+	}
+	return marks
+}
@@ -0,0 +1,59 @@
+package app
+
+import (
+	"runtime/debug"
+	"sync"
+
+	"github.com/bethropolis/tide/internal/logger"
+	"github.com/gdamore/tcell/v2"
+)
+
+// scheduler queues functions from other goroutines to run on the event loop,
+// where keys are handled and the buffer may be touched without racing.
+type scheduler struct {
+	mu      sync.Mutex
+	pending []func()
+}
+
+// schedule queues fn for the event loop and wakes it with an interrupt
+// event. If tcell's queue is full the wake-up is dropped, but the loop is
+// busy with those events and drains the queue after each of them anyway.
+func (a *App) schedule(fn func()) {
+	a.scheduler.mu.Lock()
+	a.scheduler.pending = append(a.scheduler.pending, fn)
+	a.scheduler.mu.Unlock()
+
+	if err := a.tuiManager.GetScreen().PostEvent(tcell.NewEventInterrupt(nil)); err != nil {
+		logger.DebugTagf("plugin", "Schedule: wake-up not posted: %v", err)
+	}
+}
+
+// runScheduled runs the queued functions in order. Functions queued while
+// they run wait for the next call. Returns true if anything ran.
+func (a *App) runScheduled() bool {
+	a.scheduler.mu.Lock()
+	pending := a.scheduler.pending
+	a.scheduler.pending = nil
+	a.scheduler.mu.Unlock()
+
+	for _, fn := range pending {
+		runRecovered("scheduled function", fn)
+	}
+	return len(pending) > 0
+}
+
+// goAsync runs fn on its own goroutine, logging instead of crashing the
+// editor if it panics.
+func (a *App) goAsync(fn func()) {
+	go runRecovered("async function", fn)
+}
+
+// runRecovered calls fn and logs any panic it raises.
+func runRecovered(what string, fn func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Errorf("Plugin %s panicked: %v\n%s", what, r, debug.Stack())
+		}
+	}()
+	fn()
+}
@@ -0,0 +1,51 @@
+package app
+
+import (
+	"strings"
+
+	"github.com/bethropolis/tide/internal/config"
+	"github.com/bethropolis/tide/internal/core"
+	"github.com/bethropolis/tide/internal/i18n"
+	"github.com/bethropolis/tide/internal/utils"
+)
+
+// screenReader reports whether the screen reader mode (ui.screen_reader)
+// is on.
+func (a *App) screenReader() bool {
+	return config.Get().UI.ScreenReader
+}
+
+// barHeight returns the rows below the windows: the command line, the tab
+// bar when several buffers are open, and the announcement line in screen
+// reader mode.
+func (a *App) barHeight() int {
+	height := config.CommandLineHeight
+	if len(a.editors) > 1 {
+		height++
+	}
+	if a.screenReader() {
+		height++
+	}
+	return height
+}
+
+// announceChanges states a switch of mode or of buffer on the announcement
+// line. Modes are announced here rather than where they change, so every
+// way into a mode is covered.
+func (a *App) announceChanges(ed *core.Editor, mode string) {
+	if !a.screenReader() || (mode == a.announcedMode && ed == a.announcedEditor) {
+		return
+	}
+	if ed != a.announcedEditor {
+		buf := ed.GetBuffer()
+		name := utils.DisplayPath(buf.FilePath(), config.Get().Editor.PathStyle)
+		if name == "" {
+			name = i18n.T("status.no_name")
+		}
+		a.statusBar.Announce(i18n.T("announce.buffer", name, buf.LineCount()))
+	} else {
+		key := strings.ReplaceAll(strings.ToLower(mode), " ", "_")
+		a.statusBar.Announce(i18n.T("announce.mode", i18n.T("mode."+key)))
+	}
+	a.announcedMode, a.announcedEditor = mode, ed
+}
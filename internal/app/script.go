@@ -0,0 +1,106 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bethropolis/tide/internal/event"
+	"github.com/bethropolis/tide/internal/logger"
+	"github.com/bethropolis/tide/internal/script"
+	"github.com/bethropolis/tide/internal/tui"
+)
+
+// maxSourceDepth limits scripts sourcing scripts, such as one that
+// sources itself.
+const maxSourceDepth = 16
+
+// SourceScript replays the script at path (:source). It stops at a step
+// that cannot be done at all; a command that fails only leaves its message,
+// as when typed.
+func (a *App) SourceScript(path string) error {
+	steps, err := script.Load(path)
+	if err != nil {
+		return err
+	}
+	if a.sourcing >= maxSourceDepth {
+		return fmt.Errorf("%s: scripts nested too deeply", path)
+	}
+	a.sourcing++
+	defer func() { a.sourcing-- }()
+	return a.replay(path, steps, nil)
+}
+
+// replay does the steps of the script at path in order, until the app
+// quits. report, when not nil, is given the message each command leaves.
+func (a *App) replay(path string, steps []script.Step, report func(step script.Step, msg string)) error {
+	defer a.requestRedraw()
+	for _, step := range steps {
+		select {
+		case <-a.quit:
+			return nil
+		default:
+		}
+		if report != nil {
+			a.statusBar.ResetTemporaryMessage()
+		}
+		if err := a.modeHandler.ReplayStep(step); err != nil {
+			return fmt.Errorf("%s:%d: %w", path, step.Line, err)
+		}
+		if report != nil && step.Kind == script.Command {
+			if msg, _ := a.statusBar.Message(); msg != "" {
+				report(step, msg)
+			}
+		}
+	}
+	return nil
+}
+
+// stopRecording finishes the script being recorded, if any, when the app
+// quits.
+func (a *App) stopRecording() {
+	if a.modeHandler.Recording() == "" {
+		return
+	}
+	if path, err := a.modeHandler.StopRecording(); err != nil {
+		logger.Warnf("Script: could not finish '%s': %v", path, err)
+	}
+}
+
+// RunScript opens filePath without a terminal, replays the script at
+// scriptPath and quits (tide --script). The script saves what it changes
+// itself, with :w; leaving a file unsaved is an error. The messages its
+// commands leave are written to stderr.
+func RunScript(filePath, scriptPath string) error {
+	steps, err := script.Load(scriptPath)
+	if err != nil {
+		return err
+	}
+	a, err := newApp(filePath, tui.NewHeadless)
+	if err != nil {
+		return err
+	}
+	defer a.shutdown()
+
+	go a.eventLoop()
+	a.eventManager.Dispatch(event.TypeAppReady, event.AppReadyData{})
+
+	// Steps run on the event loop, like typed keys
+	done := make(chan error, 1)
+	a.schedule(func() {
+		err := a.replay(scriptPath, steps, func(step script.Step, msg string) {
+			fmt.Fprintf(os.Stderr, "%s:%d: %s\n", scriptPath, step.Line, msg)
+		})
+		if err == nil {
+			var unsaved []string
+			for _, ed := range a.modifiedEditors() {
+				unsaved = append(unsaved, editorDisplayName(ed))
+			}
+			if len(unsaved) > 0 {
+				err = fmt.Errorf("%s: unsaved changes to %s (end the script with :w)", scriptPath, strings.Join(unsaved, ", "))
+			}
+		}
+		done <- err
+	})
+	return <-done
+}
@@ -0,0 +1,181 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/bethropolis/tide/internal/config"
+	"github.com/bethropolis/tide/internal/core/clipboard"
+	"github.com/bethropolis/tide/internal/logger"
+	"github.com/bethropolis/tide/internal/paths"
+	"github.com/bethropolis/tide/internal/session"
+	"github.com/bethropolis/tide/internal/tui"
+	"github.com/bethropolis/tide/internal/types"
+)
+
+// sessionDir returns where session snapshots are kept,
+// ~/.local/state/tide/sessions on Linux.
+func sessionDir() (string, error) {
+	return paths.State(config.SessionsDirName)
+}
+
+// startSessionSnapshots snapshots the session every session_interval
+// seconds until the app quits.
+func (a *App) startSessionSnapshots() {
+	interval := config.Get().Editor.SessionInterval
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(time.Duration(interval) * time.Second)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				a.schedule(a.snapshotSession)
+			case <-a.quit:
+				return
+			}
+		}
+	}()
+}
+
+// sessionState describes the open files and the registers.
+func (a *App) sessionState() session.State {
+	cwd, _ := os.Getwd()
+	state := session.State{PID: os.Getpid(), Saved: time.Now(), Dir: cwd, Active: -1}
+	for i, ed := range a.editors {
+		if !a.viewable(ed) {
+			continue
+		}
+		if i == a.activeEditorIndex {
+			state.Active = len(state.Buffers)
+		}
+		path, _ := filepath.Abs(ed.GetBuffer().FilePath())
+		cursor := ed.GetCursor()
+		top, left := ed.GetViewport()
+		state.Buffers = append(state.Buffers, session.Buffer{Path: path, Line: cursor.Line, Col: cursor.Col, ViewTop: top, ViewLeft: left})
+	}
+	for _, entry := range clipboard.History().Entries() {
+		state.Registers = append(state.Registers, session.Register{Text: string(entry.Text), Linewise: entry.Linewise, Blockwise: entry.Blockwise})
+	}
+	return state
+}
+
+// snapshotSession writes the session state unless it has not changed
+// since the last snapshot. The file is small, and writing it here rather
+// than in the background means none is left behind by a write finishing
+// after removeSessionSnapshot.
+func (a *App) snapshotSession() {
+	state := a.sessionState()
+	unchanged := state
+	unchanged.Saved = time.Time{}
+	key, _ := json.Marshal(unchanged)
+	if bytes.Equal(key, a.lastSession) {
+		return
+	}
+	a.lastSession = key
+
+	dir, err := sessionDir()
+	if err == nil {
+		err = session.Save(dir, state)
+	}
+	if err != nil {
+		logger.Warnf("Session: could not save a snapshot: %v", err)
+	}
+}
+
+// removeSessionSnapshot deletes this instance's snapshot on a normal quit,
+// so the next start does not offer it back.
+func (a *App) removeSessionSnapshot() {
+	dir, err := sessionDir()
+	if err == nil {
+		err = session.Remove(dir, os.Getpid())
+	}
+	if err != nil {
+		logger.Warnf("Session: could not remove the snapshot: %v", err)
+	}
+}
+
+// offerSessionRestore asks whether to reopen the files of the newest
+// session that ended without quitting, if there is one. Whatever the
+// answer, except "later", the snapshots of ended sessions are removed.
+func (a *App) offerSessionRestore() {
+	dir, err := sessionDir()
+	if err != nil || a.confirm == nil {
+		return
+	}
+	snapshots := session.Orphaned(dir)
+	if len(snapshots) == 0 {
+		return
+	}
+	latest := snapshots[0].State
+	discard := func() {
+		for _, s := range snapshots {
+			if err := os.Remove(s.Path); err != nil {
+				logger.Warnf("Session: could not remove '%s': %v", s.Path, err)
+			}
+		}
+	}
+	if len(latest.Buffers) == 0 && len(latest.Registers) == 0 {
+		discard() // Nothing worth asking about
+		return
+	}
+
+	message := []string{fmt.Sprintf("A session ended without quitting at %s.", latest.Saved.Format("2006-01-02 15:04")),
+		fmt.Sprintf("Restore its %d file(s) and %d register(s)?", len(latest.Buffers), len(latest.Registers))}
+	const maxListed = 8
+	for i, b := range latest.Buffers {
+		if i == maxListed {
+			message = append(message, fmt.Sprintf("  ... and %d more", len(latest.Buffers)-maxListed))
+			break
+		}
+		message = append(message, "  "+b.Path)
+	}
+	a.confirm.Show("Restore Session", message, []tui.ConfirmChoice{
+		{Key: 'r', Label: "estore", Action: func() {
+			a.restoreSession(latest)
+			discard()
+		}},
+		{Key: 'd', Label: "iscard", Action: discard},
+		{Key: 'l', Label: "ater"},
+	})
+	a.requestRedraw()
+}
+
+// restoreSession reopens the files of state where their cursors were and
+// puts back its registers.
+func (a *App) restoreSession(state session.State) {
+	opened := 0
+	for _, b := range state.Buffers {
+		if _, err := os.Stat(b.Path); err != nil {
+			logger.Warnf("Session: not reopening '%s': %v", b.Path, err)
+			continue
+		}
+		a.OpenFile(b.Path)
+		ed := a.getActiveEditor()
+		ed.SetCursor(types.Position{Line: b.Line, Col: b.Col})
+		ed.SetViewport(b.ViewTop, b.ViewLeft)
+		ed.ScrollToCursor() // The file may have changed since
+		opened++
+	}
+	if state.Active >= 0 && state.Active < len(state.Buffers) {
+		if path := state.Buffers[state.Active].Path; path != a.getActiveEditor().GetBuffer().FilePath() {
+			a.OpenFile(path)
+		}
+	}
+	a.getActiveEditor().MarkAllDirty()
+
+	// The ring keeps the newest first; push the oldest first
+	ring := clipboard.History()
+	for i := len(state.Registers) - 1; i >= 0; i-- {
+		r := state.Registers[i]
+		ring.PushEntry(clipboard.Entry{Text: []byte(r.Text), Linewise: r.Linewise, Blockwise: r.Blockwise})
+	}
+	a.statusBar.SetTemporaryMessage("Restored %d file(s) and %d register(s)", opened, len(state.Registers))
+	a.requestRedraw()
+}
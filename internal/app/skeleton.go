@@ -0,0 +1,91 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bethropolis/tide/internal/config"
+	"github.com/bethropolis/tide/internal/core"
+	"github.com/bethropolis/tide/internal/core/history"
+	"github.com/bethropolis/tide/internal/logger"
+	"github.com/bethropolis/tide/internal/paths"
+	"github.com/bethropolis/tide/internal/types"
+	"github.com/bethropolis/tide/internal/utils"
+)
+
+// skeletonPath finds the template for a new file, ~/.config/tide/templates/
+// on Linux. A template named after the whole file (e.g. "Makefile") wins
+// over "skeleton.<ext>".
+func skeletonPath(filePath string) string {
+	dir, err := paths.Config(config.TemplatesDirName)
+	if err != nil {
+		return ""
+	}
+
+	candidates := []string{filepath.Join(dir, filepath.Base(filePath))}
+	if ext := strings.TrimPrefix(filepath.Ext(filePath), "."); ext != "" {
+		candidates = append(candidates, filepath.Join(dir, "skeleton."+ext))
+	}
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && info.Mode().IsRegular() {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// applySkeleton pre-populates the empty buffer of a file that does not exist
+// yet from its template. The insert is recorded in the history so it can be
+// undone, but no BufferModified event is sent: the caller highlights the
+// editor afterwards and it may not be the active editor yet.
+func applySkeleton(ed *core.Editor) {
+	buf := ed.GetBuffer()
+	filePath := buf.FilePath()
+	tmplPath := skeletonPath(filePath)
+	if tmplPath == "" {
+		return
+	}
+	tmpl, err := os.ReadFile(tmplPath)
+	if err != nil {
+		logger.Warnf("Failed to read template '%s': %v", tmplPath, err)
+		return
+	}
+
+	absPath, _ := filepath.Abs(filePath)
+	name := filepath.Base(absPath)
+	text := utils.ExpandSkeleton(string(tmpl), map[string]string{
+		"filename": name,
+		"basename": strings.TrimSuffix(name, filepath.Ext(name)),
+		"package":  utils.GoPackageName(filepath.Dir(absPath)),
+		"year":     time.Now().Format("2006"),
+		"date":     time.Now().Format("2006-01-02"),
+	})
+
+	// The cursor placeholder is removed; the cursor lands there afterwards.
+	cursorAt := strings.Index(text, utils.CursorPlaceholder)
+	text = strings.Replace(text, utils.CursorPlaceholder, "", 1)
+	if text == "" {
+		return
+	}
+
+	start := types.Position{}
+	if _, err := buf.Insert(start, []byte(text)); err != nil {
+		logger.Warnf("Failed to insert template '%s': %v", tmplPath, err)
+		return
+	}
+	if histMgr := ed.GetHistoryManager(); histMgr != nil {
+		histMgr.RecordChange(history.Change{
+			Type:          history.InsertAction,
+			Text:          []byte(text),
+			StartPosition: start,
+			EndPosition:   utils.EndPosition(start, []byte(text)),
+			CursorBefore:  start,
+		})
+	}
+	if cursorAt >= 0 {
+		ed.SetCursor(utils.EndPosition(start, []byte(text[:cursorAt])))
+	}
+	logger.Infof("Initialised new file '%s' from template %s", filePath, tmplPath)
+}
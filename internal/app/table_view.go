@@ -0,0 +1,48 @@
+package app
+
+import (
+	"github.com/bethropolis/tide/internal/config"
+	"github.com/bethropolis/tide/internal/core"
+	"github.com/bethropolis/tide/internal/core/table"
+	"github.com/bethropolis/tide/internal/event"
+)
+
+// applyTableView opens CSV/TSV files in table view when table_view is on.
+func applyTableView(ed *core.Editor) {
+	cfg := config.Get().Editor
+	ed.SetPinHeader(cfg.TablePinHeader)
+	if !cfg.TableView {
+		return
+	}
+	if _, ok := table.SeparatorFor(ed.GetBuffer().FilePath()); ok {
+		_ = ed.SetTableView(true)
+	}
+}
+
+// ToggleTableView switches the active buffer between table and plain view.
+func (a *App) ToggleTableView() (bool, error) {
+	ed := a.getActiveEditor()
+	on := !ed.TableView()
+	if err := ed.SetTableView(on); err != nil {
+		return ed.TableView(), err
+	}
+	a.requestRedraw()
+	return on, nil
+}
+
+// ToggleTableHeader pins or unpins the header row of the active buffer.
+func (a *App) ToggleTableHeader() bool {
+	ed := a.getActiveEditor()
+	pin := !ed.HeaderPinned()
+	ed.SetPinHeader(pin)
+	a.requestRedraw()
+	return pin
+}
+
+// handleBufferModifiedForTable re-measures the columns after an edit.
+func (a *App) handleBufferModifiedForTable(e event.Event) bool {
+	if ed := a.getActiveEditor(); ed != nil && ed.TableView() {
+		ed.RefreshTableLayout()
+	}
+	return false
+}
@@ -0,0 +1,235 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bethropolis/tide/internal/buffer"
+	"github.com/bethropolis/tide/internal/core"
+	"github.com/bethropolis/tide/internal/event"
+	"github.com/bethropolis/tide/internal/tail"
+	"github.com/bethropolis/tide/internal/types"
+)
+
+const (
+	tailInterval = 500 * time.Millisecond  // How often followed files are checked
+	tailFade     = 1500 * time.Millisecond // How long appended lines stay set apart
+)
+
+// following is a buffer whose file is followed as it grows (:tail).
+type following struct {
+	file *tail.File
+	fade time.Time // When to stop setting apart the lines appended last; zero when none are
+}
+
+// ToggleTail starts or stops following the active buffer's file as it
+// grows, as tail -f does (:tail). Only what is appended is read, and the
+// view stays at the end while the cursor is on the last line.
+func (a *App) ToggleTail() (bool, error) {
+	ed := a.getActiveEditor()
+	if ed == nil {
+		return false, fmt.Errorf("no active buffer")
+	}
+	if _, on := a.tails[ed]; on {
+		a.stopTail(ed)
+		ed.ClearAppendedLines()
+		a.requestRedraw()
+		return false, nil
+	}
+	buf := ed.GetBuffer()
+	path := buf.FilePath()
+	if !a.viewable(ed) || isURL(path) {
+		return false, fmt.Errorf("no file to follow")
+	}
+	if buf.IsModified() {
+		return false, fmt.Errorf("buffer has unsaved changes (save it or :e! first)")
+	}
+	f, err := tail.Follow(path, int64(bufferLen(buf)))
+	if err != nil {
+		return false, err
+	}
+	if a.tails == nil {
+		a.tails = make(map[*core.Editor]*following)
+	}
+	a.tails[ed] = &following{file: f}
+	if a.tailStop == nil {
+		a.startTailPolling()
+	}
+	a.pollTails() // Take in what was written since the file was opened
+	return true, nil
+}
+
+// bufferLen returns the length of buf's text in bytes.
+func bufferLen(buf buffer.Buffer) int {
+	if b, ok := buf.(interface{ Len() int }); ok {
+		return b.Len()
+	}
+	return len(buf.Bytes())
+}
+
+// startTailPolling checks the followed files every tailInterval until the
+// last one is no longer followed.
+func (a *App) startTailPolling() {
+	stop := make(chan struct{})
+	a.tailStop = stop
+	go func() {
+		ticker := time.NewTicker(tailInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				a.schedule(a.pollTails)
+			case <-stop:
+				return
+			case <-a.quit:
+				return
+			}
+		}
+	}()
+}
+
+// stopTail stops following ed's file.
+func (a *App) stopTail(ed *core.Editor) {
+	if _, ok := a.tails[ed]; !ok {
+		return
+	}
+	delete(a.tails, ed)
+	if len(a.tails) == 0 && a.tailStop != nil {
+		close(a.tailStop)
+		a.tailStop = nil
+	}
+}
+
+// pollTails takes in what was appended to the followed files.
+func (a *App) pollTails() {
+	now := time.Now()
+	redraw := false
+	for ed, f := range a.tails {
+		if !slices.Contains(a.editors, ed) {
+			a.stopTail(ed) // Closed
+			continue
+		}
+		if !f.fade.IsZero() && now.After(f.fade) {
+			f.fade = time.Time{}
+			redraw = ed.ClearAppendedLines() || redraw
+		}
+		grew, err := a.takeTail(ed, f.file)
+		if err != nil {
+			a.stopTail(ed)
+			ed.ClearAppendedLines()
+			a.statusBar.SetErrorMessage("Stopped following %s: %v", filepath.Base(f.file.Path()), err)
+			redraw = true
+			continue
+		}
+		if grew {
+			f.fade = now.Add(tailFade)
+			redraw = true
+		}
+	}
+	if redraw {
+		a.updateStatusBarContent()
+		a.requestRedraw()
+	}
+}
+
+// takeTail puts what was appended to f at the end of ed's buffer, or reads
+// the file again when it was truncated or replaced, and sets apart the new
+// lines. A cursor on the last line follows the end. It returns whether the
+// text changed.
+func (a *App) takeTail(ed *core.Editor, f *tail.File) (bool, error) {
+	c, err := f.Poll()
+	if err != nil {
+		return false, err
+	}
+	if !c.Grew() && !c.Reset {
+		return false, nil
+	}
+	buf := ed.GetBuffer()
+	atEnd := ed.GetCursor().Line >= buf.LineCount()-1
+
+	a.asActive(ed, func() {
+		if c.Reset {
+			err = a.reloadTail(ed)
+		} else {
+			err = a.appendTail(ed, f, c)
+		}
+		if err == nil && atEnd {
+			ed.SetCursor(types.Position{Line: buf.LineCount() - 1})
+			ed.ScrollToCursor()
+		}
+	})
+	return err == nil, err
+}
+
+// appendTail puts the bytes c says were appended to f at the end of ed's
+// buffer and sets apart the lines they make.
+func (a *App) appendTail(ed *core.Editor, f *tail.File, c tail.Change) error {
+	buf := ed.GetBuffer()
+	from := buf.LineCount() - 1
+	var edit types.EditInfo
+	var err error
+	if ext, ok := buf.(interface {
+		Extend() (types.EditInfo, error)
+	}); ok {
+		edit, err = ext.Extend() // Reads the file itself (buffer.Mapped)
+	} else {
+		var data []byte
+		if data, err = f.Read(c); err == nil {
+			edit, err = appendText(buf, data)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	a.eventManager.Dispatch(event.TypeBufferModified, event.BufferModifiedData{Edit: edit, FilePath: ed.GetBuffer().FilePath()})
+
+	to := buf.LineCount() - 1
+	if last, _ := buf.Line(to); len(last) == 0 && to > from {
+		to-- // Text ending in a newline adds no line of its own
+	}
+	ed.SetAppendedLines(from, to)
+	return nil
+}
+
+// appendText puts data at the end of buf, read-only or not, leaving it as
+// modified as it was: the text is the file's.
+func appendText(buf buffer.Buffer, data []byte) (types.EditInfo, error) {
+	lastLine := buf.LineCount() - 1
+	last, err := buf.Line(lastLine)
+	if err != nil {
+		return types.EditInfo{}, err
+	}
+	readOnly, modified := buf.ReadOnly(), buf.IsModified()
+	defer func() {
+		buf.SetReadOnly(readOnly)
+		buf.SetModified(modified)
+	}()
+	buf.SetReadOnly(false)
+	return buf.Insert(types.Position{Line: lastLine, Col: utf8.RuneCount(last)}, bytes.Clone(data))
+}
+
+// reloadTail reads the followed file of ed again whole, after it was
+// truncated or replaced.
+func (a *App) reloadTail(ed *core.Editor) error {
+	buf := ed.GetBuffer()
+	if _, mapped := buf.(*buffer.Mapped); mapped {
+		if err := buf.Load(buf.FilePath()); err != nil {
+			return err
+		}
+		ed.SetCursor(ed.GetCursor()) // Back inside the text
+		ed.InvalidateConflicts()
+		if hm := ed.GetHighlightManager(); hm != nil {
+			hm.Rehighlight()
+		}
+		ed.MarkAllDirty()
+		return nil
+	}
+	readOnly := buf.ReadOnly()
+	defer buf.SetReadOnly(readOnly)
+	buf.SetReadOnly(false)
+	return a.reloadFromDisk(ed)
+}
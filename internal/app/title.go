@@ -0,0 +1,35 @@
+package app
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/bethropolis/tide/internal/config"
+	"github.com/bethropolis/tide/internal/i18n"
+)
+
+// windowTitle returns the terminal title for the title option: the active
+// file, whether it is modified, the script being recorded and the keys of
+// a command being typed, as the status line shows them. It is "" with the
+// option off.
+func (a *App) windowTitle() string {
+	ed := a.getActiveEditor()
+	if !config.Get().Editor.Title || ed == nil {
+		return ""
+	}
+	name := filepath.Base(ed.GetBuffer().FilePath())
+	if ed.GetBuffer().FilePath() == "" {
+		name = i18n.T("status.no_name")
+	}
+	parts := []string{name}
+	if ed.GetBuffer().IsModified() {
+		parts = append(parts, "[+]")
+	}
+	if script := a.modeHandler.Recording(); script != "" {
+		parts = append(parts, "("+i18n.T("status.recording", filepath.Base(script))+")")
+	}
+	if keys := a.modeHandler.PendingKeys(); keys != "" {
+		parts = append(parts, keys)
+	}
+	return strings.Join(parts, " ") + " - tide"
+}
@@ -0,0 +1,198 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/bethropolis/tide/internal/config"
+	"github.com/bethropolis/tide/internal/core"
+	"github.com/bethropolis/tide/internal/logger"
+	"github.com/bethropolis/tide/internal/paths"
+	"github.com/bethropolis/tide/internal/types"
+)
+
+// viewFileVersion is bumped whenever viewState changes incompatibly.
+const viewFileVersion = 1
+
+// viewState is the per-file state persisted by :mkview and restored by
+// :loadview. New view-local state (folds, marks, local options) belongs here.
+type viewState struct {
+	Version  int        `json:"version"`
+	Path     string     `json:"path"`
+	Line     int        `json:"line"`
+	Col      int        `json:"col"`
+	ViewTop  int        `json:"view_top"`
+	ViewLeft int        `json:"view_left"`
+	Marks    []viewMark `json:"marks,omitempty"`
+}
+
+// viewMark is a named mark of the file (ma), kept whether or not views are.
+type viewMark struct {
+	Name string `json:"name"`
+	Line int    `json:"line"`
+	Col  int    `json:"col"`
+}
+
+// viewFilePath returns where the view of filePath is stored,
+// ~/.local/state/tide/views/<name>-<hash>.json on Linux. The hash of the
+// absolute path keeps files with the same name in different directories apart.
+func viewFilePath(filePath string) (string, error) {
+	viewsDir, err := paths.State(config.ViewsDirName)
+	if err != nil {
+		return "", err
+	}
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(absPath))
+	name := fmt.Sprintf("%s-%s.json", filepath.Base(absPath), hex.EncodeToString(sum[:8]))
+	return filepath.Join(viewsDir, name), nil
+}
+
+// viewable reports whether ed is backed by a regular file whose view can be saved.
+func (a *App) viewable(ed *core.Editor) bool {
+	if _, isDir := a.dirViews[ed]; isDir {
+		return false
+	}
+	return ed.GetBuffer().FilePath() != ""
+}
+
+// viewStateOf returns the view state of ed as it is now.
+func viewStateOf(ed *core.Editor) viewState {
+	cursor := ed.GetCursor()
+	top, left := ed.GetViewport()
+	absPath, _ := filepath.Abs(ed.GetBuffer().FilePath())
+	return viewState{
+		Version:  viewFileVersion,
+		Path:     absPath,
+		Line:     cursor.Line,
+		Col:      cursor.Col,
+		ViewTop:  top,
+		ViewLeft: left,
+		Marks:    viewMarksOf(ed),
+	}
+}
+
+// viewMarksOf returns the named marks of ed in order.
+func viewMarksOf(ed *core.Editor) []viewMark {
+	var saved []viewMark
+	for _, name := range ed.Marks().Names() {
+		pos, _ := ed.MarkPosition(name)
+		saved = append(saved, viewMark{Name: string(name), Line: pos.Line, Col: pos.Col})
+	}
+	return saved
+}
+
+// writeView saves the view state of ed.
+func writeView(ed *core.Editor) (string, error) {
+	viewPath, err := viewFilePath(ed.GetBuffer().FilePath())
+	if err != nil {
+		return "", fmt.Errorf("cannot locate view directory: %w", err)
+	}
+	return viewPath, writeViewState(viewPath, viewStateOf(ed))
+}
+
+// writeViewState writes state to the view file at viewPath.
+func writeViewState(viewPath string, state viewState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(viewPath), 0700); err != nil {
+		return fmt.Errorf("cannot create view directory: %w", err)
+	}
+	return os.WriteFile(viewPath, data, 0600)
+}
+
+// readViewState reads the view state saved at viewPath. The
+// returned error wraps os.ErrNotExist when none was saved.
+func readViewState(viewPath string) (viewState, error) {
+	var state viewState
+	data, err := os.ReadFile(viewPath)
+	if err != nil {
+		return state, err
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("corrupt view file %s: %w", viewPath, err)
+	}
+	if state.Version != viewFileVersion {
+		return state, fmt.Errorf("unsupported view file version %d", state.Version)
+	}
+	return state, nil
+}
+
+// readView restores the saved view state of ed. The returned error wraps
+// os.ErrNotExist when no view was saved for the file.
+func readView(ed *core.Editor) error {
+	viewPath, err := viewFilePath(ed.GetBuffer().FilePath())
+	if err != nil {
+		return fmt.Errorf("cannot locate view directory: %w", err)
+	}
+	state, err := readViewState(viewPath)
+	if err != nil {
+		return err
+	}
+
+	restoreMarks(ed, state.Marks)
+	ed.SetCursor(types.Position{Line: state.Line, Col: state.Col})
+	ed.SetViewport(state.ViewTop, state.ViewLeft)
+	ed.ScrollToCursor() // The file may have shrunk or the window changed size
+	ed.MarkAllDirty()
+	return nil
+}
+
+// MakeView saves the cursor and scroll position of the active buffer (:mkview).
+func (a *App) MakeView() error {
+	ed := a.getActiveEditor()
+	if ed == nil || !a.viewable(ed) {
+		return fmt.Errorf("buffer has no file name")
+	}
+	viewPath, err := writeView(ed)
+	if err != nil {
+		return err
+	}
+	logger.Debugf("Saved view of '%s' to %s", ed.GetBuffer().FilePath(), viewPath)
+	return nil
+}
+
+// LoadView restores the saved view of the active buffer (:loadview).
+func (a *App) LoadView() error {
+	ed := a.getActiveEditor()
+	if ed == nil || !a.viewable(ed) {
+		return fmt.Errorf("buffer has no file name")
+	}
+	if err := readView(ed); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("no view saved for %s", ed.GetBuffer().FilePath())
+		}
+		return err
+	}
+	a.requestRedraw()
+	return nil
+}
+
+// autoLoadView restores the view of a newly opened editor when auto_view is on.
+func (a *App) autoLoadView(ed *core.Editor) {
+	if !config.Get().Editor.AutoView || !a.viewable(ed) {
+		return
+	}
+	if err := readView(ed); err != nil && !errors.Is(err, os.ErrNotExist) {
+		logger.Warnf("Failed to restore view of '%s': %v", ed.GetBuffer().FilePath(), err)
+	}
+}
+
+// autoSaveView saves the view of ed when auto_view is on.
+func (a *App) autoSaveView(ed *core.Editor) {
+	if !config.Get().Editor.AutoView || !a.viewable(ed) {
+		return
+	}
+	if _, err := writeView(ed); err != nil {
+		logger.Warnf("Failed to save view of '%s': %v", ed.GetBuffer().FilePath(), err)
+	}
+}
@@ -0,0 +1,242 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/bethropolis/tide/internal/config"
+	"github.com/bethropolis/tide/internal/core"
+	"github.com/bethropolis/tide/internal/event"
+	"github.com/bethropolis/tide/internal/statusbar"
+	"github.com/bethropolis/tide/internal/tui"
+	"github.com/bethropolis/tide/internal/utils"
+	"github.com/gdamore/tcell/v2"
+)
+
+// Split divides the focused window, side by side when vertical (:vsplit)
+// or else stacked (:split), and focuses the new window. It shows the
+// active buffer, or filePath when one is given. Windows on the same
+// buffer show the same text, but each keeps its own cursor and scroll
+// position.
+func (a *App) Split(vertical bool, filePath string) error {
+	ed := a.getActiveEditor()
+	if ed == nil {
+		return fmt.Errorf("no buffer to split")
+	}
+	a.arrangePanes()
+	focused := a.layout.Focused().Rect
+	if (vertical && focused.Width < 3) || (!vertical && focused.Height < 3) {
+		return fmt.Errorf("not enough room to split")
+	}
+	old := a.layout.Focused()
+	old.View = ed.SaveView()
+	a.layout.Split(ed, vertical).View = old.View
+	if filePath != "" {
+		a.OpenFile(filePath) // The new window follows the active buffer
+	}
+	a.markPanesDirty()
+	a.requestRedraw()
+	return nil
+}
+
+// WindowCommand runs a window command, as typed after Ctrl+W (:wincmd):
+// s and v split, w and W cycle the focus, h, j, k and l move it, c and q
+// close the window and o closes the others.
+func (a *App) WindowCommand(c rune) error {
+	a.arrangePanes()
+	prev := a.layout.Focused()
+	prev.View = prev.Editor.SaveView()
+	switch c {
+	case 's', 'S':
+		return a.Split(false, "")
+	case 'v':
+		return a.Split(true, "")
+	case 'w':
+		a.layout.Cycle(1)
+	case 'W':
+		a.layout.Cycle(-1)
+	case 'h', 'j', 'k', 'l':
+		p := a.layout.Neighbour(c)
+		if p == nil {
+			return nil
+		}
+		a.layout.Focus(p)
+	case 'c', 'q':
+		if !a.layout.Close() {
+			return fmt.Errorf("cannot close the last window")
+		}
+	case 'o':
+		a.layout.Only()
+	default:
+		return fmt.Errorf("unknown window command: %c", c)
+	}
+	a.focusPane(a.layout.Focused())
+	a.markPanesDirty()
+	a.requestRedraw()
+	return nil
+}
+
+// focusPane focuses p and makes its buffer the active one, with the
+// cursor and scroll position p had. The window losing the focus keeps its
+// own.
+func (a *App) focusPane(p *tui.Pane) {
+	if old := a.layout.Focused(); old != p {
+		old.View = old.Editor.SaveView()
+		a.layout.Focus(p)
+	}
+	if p.Editor != a.getActiveEditor() {
+		a.switchToEditor(p.Editor)
+	}
+	a.arrangePanes()
+	p.Editor.RestoreView(p.View)
+}
+
+// syncPanes points the focused window at the active buffer, so switching
+// buffers (:bn, :e) changes what it shows, and windows of closed buffers
+// at it too.
+func (a *App) syncPanes() {
+	ed := a.getActiveEditor()
+	a.layout.Focused().Editor = ed
+	for _, p := range a.layout.Panes() {
+		if !a.isOpen(p.Editor) {
+			p.Editor = ed
+			p.View = ed.SaveView()
+		}
+	}
+}
+
+// isOpen reports whether ed is one of the open buffers.
+func (a *App) isOpen(ed *core.Editor) bool {
+	for _, other := range a.editors {
+		if other == ed {
+			return true
+		}
+	}
+	return false
+}
+
+// editorArea returns the screen area the windows share: all but the bars
+// at the bottom.
+func (a *App) editorArea() tui.Rect {
+	w, h := a.tuiManager.Size()
+	return tui.Rect{Width: w, Height: max(0, h-a.barHeight())}
+}
+
+// sizeView sizes a buffer's view to a window filling the editor area, until
+// it is first drawn in one.
+func (a *App) sizeView(ed *core.Editor) {
+	area, status := a.editorArea(), config.StatusBarHeight
+	if a.layout != nil { // Before the first window, assume a status line
+		status = a.layout.StatusHeight()
+	}
+	ed.SetViewSize(area.Width, area.Height-status)
+}
+
+// arrangePanes fits the windows to the editor area and sizes each buffer's
+// view to its window.
+func (a *App) arrangePanes() {
+	a.syncPanes()
+	a.layout.HideLoneStatus = !config.Get().Editor.StatusLine
+	a.layout.Arrange(a.editorArea())
+	for _, p := range a.layout.Panes() {
+		if p != a.layout.Focused() {
+			p.Editor.SetViewSize(p.Rect.Width, p.Rect.Height)
+		}
+	}
+	// Last, so a buffer shown twice scrolls for the focused window
+	focused := a.layout.Focused()
+	focused.Editor.SetViewSize(focused.Rect.Width, focused.Rect.Height)
+}
+
+// markPanesDirty makes the next frame repaint every window.
+func (a *App) markPanesDirty() {
+	for _, p := range a.layout.Panes() {
+		p.Editor.MarkAllDirty()
+	}
+}
+
+// cursorCell returns the screen cell of the cursor in the focused window.
+func (a *App) cursorCell() (int, int) {
+	return tui.CursorCell(a.getActiveEditor(), a.layout.Focused().Rect)
+}
+
+// drawPanes draws the buffer of every window, the focused one last, its
+// status line, and the separators between side-by-side windows.
+func (a *App) drawPanes(screen tcell.Screen) {
+	panes := a.layout.Panes()
+	focused := a.layout.Focused()
+	if len(panes) > 1 {
+		// Windows may share a buffer, whose dirty lines the first draw
+		// clears, and whose cursor and viewport each window sets in turn
+		a.markPanesDirty()
+		live := focused.Editor.SaveView()
+		for _, p := range panes {
+			if p != focused {
+				p.Editor.SetViewSize(p.Rect.Width, p.Rect.Height)
+				p.Editor.RestoreView(p.View)
+				tui.DrawBuffer(a.tuiManager, p.Editor, a.activeTheme, p.Rect)
+				p.View = p.Editor.SaveView()
+			}
+		}
+		focused.Editor.SetViewSize(focused.Rect.Width, focused.Rect.Height)
+		focused.Editor.RestoreView(live)
+	}
+	tui.DrawBuffer(a.tuiManager, focused.Editor, a.activeTheme, focused.Rect)
+
+	for _, p := range panes {
+		if p.Status.Height == 0 {
+			continue
+		}
+		win := a.statusBar.Focused()
+		if p != focused {
+			buf := p.Editor.GetBuffer()
+			win = statusbar.Window{
+				Path:     utils.DisplayPath(buf.FilePath(), config.Get().Editor.PathStyle),
+				Modified: buf.IsModified(),
+				Cursor:   p.View.Cursor,
+			}
+		}
+		statusbar.DrawWindow(screen, p.Status.X, p.Status.Y, p.Status.Width, win, p == focused, a.activeTheme)
+	}
+	a.layout.DrawSeparators(screen, a.activeTheme.GetStyle("LineNumber"))
+}
+
+// handlePaneMouse sends a mouse event to the window under it, focusing
+// that window on a click. Events over separators are dropped.
+func (a *App) handlePaneMouse(ev *tcell.EventMouse) bool {
+	x, y := ev.Position()
+	if len(a.layout.Panes()) == 1 {
+		return a.modeHandler.HandleMouseEvent(ev)
+	}
+	p := a.layout.PaneAt(x, y)
+	if p == nil {
+		return false
+	}
+	redraw := false
+	if p != a.layout.Focused() && ev.Buttons() != tcell.ButtonNone {
+		a.focusPane(p)
+		a.markPanesDirty()
+		redraw = true
+	}
+	if p != a.layout.Focused() {
+		return redraw
+	}
+	local := tcell.NewEventMouse(x-p.Rect.X, y-p.Rect.Y, ev.Buttons(), ev.Modifiers())
+	return a.modeHandler.HandleMouseEvent(local) || redraw
+}
+
+// handleBufferModifiedForWindows keeps the other windows on the edited
+// buffer on the text they showed when lines are inserted or deleted above
+// it.
+func (a *App) handleBufferModifiedForWindows(e event.Event) bool {
+	data, ok := e.Data.(event.BufferModifiedData)
+	if !ok {
+		return false
+	}
+	ed := a.getActiveEditor()
+	for _, p := range a.layout.Panes() {
+		if p != a.layout.Focused() && p.Editor == ed {
+			p.View.Adjust(data.Edit)
+		}
+	}
+	return false
+}
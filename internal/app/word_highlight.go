@@ -0,0 +1,55 @@
+package app
+
+import (
+	"github.com/bethropolis/tide/internal/config"
+	"github.com/bethropolis/tide/internal/event"
+	"github.com/bethropolis/tide/internal/modehandler"
+)
+
+// handleCursorMovedForWordHighlight drops the identifier highlights of the
+// previous position and schedules a CursorHold for the new one.
+func (a *App) handleCursorMovedForWordHighlight(e event.Event) bool {
+	data, ok := e.Data.(event.CursorMovedData)
+	if !ok {
+		return false
+	}
+	if ed := a.getActiveEditor(); ed != nil && ed.ClearWordHighlights() {
+		ed.MarkAllDirty()
+	}
+	a.cursorHold.Debounce(config.CursorHoldDelay, func() {
+		a.eventManager.Dispatch(event.TypeCursorHold, event.CursorHoldData{Position: data.NewPosition})
+	})
+	return false
+}
+
+// handleCursorHoldForWordHighlight highlights the other visible occurrences
+// of the identifier the cursor is resting on.
+func (a *App) handleCursorHoldForWordHighlight(e event.Event) bool {
+	ed := a.getActiveEditor()
+	if ed == nil || a.modeHandler.GetCurrentMode() != modehandler.ModeNormal {
+		return false
+	}
+	if _, isDir := a.dirViews[ed]; isDir {
+		return false
+	}
+	if data, ok := e.Data.(event.CursorHoldData); !ok || data.Position != ed.GetCursor() {
+		return false // Cursor moved again before the hold fired
+	}
+
+	viewY, _ := ed.GetViewport()
+	_, height := a.tuiManager.Size()
+	if ed.HighlightWordUnderCursor(viewY, viewY+height-a.layout.StatusHeight()) {
+		ed.MarkAllDirty()
+		a.requestRedraw()
+	}
+	return false
+}
+
+// handleBufferModifiedForWordHighlight clears identifier highlights, whose
+// positions are stale after an edit.
+func (a *App) handleBufferModifiedForWordHighlight(e event.Event) bool {
+	if ed := a.getActiveEditor(); ed != nil && ed.ClearWordHighlights() {
+		ed.MarkAllDirty()
+	}
+	return false
+}
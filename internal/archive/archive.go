@@ -0,0 +1,244 @@
+// Package archive reads the members of .zip and .tar(.gz) files, so that a
+// path such as project.zip/cmd/main.go can be listed and opened like one on
+// disk.
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrIsDir is returned by ReadFile for a directory inside the archive.
+var ErrIsDir = errors.New("is a directory")
+
+// maxMemberSize is the largest member ReadFile will load.
+const maxMemberSize = 64 << 20
+
+// suffixes are the file name endings recognised as archives.
+var suffixes = []string{".zip", ".jar", ".tar", ".tar.gz", ".tgz"}
+
+// IsArchive reports whether name ends in an archive suffix.
+func IsArchive(name string) bool {
+	lower := strings.ToLower(name)
+	for _, s := range suffixes {
+		if strings.HasSuffix(lower, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Split finds the archive file a path runs through, returning the path of
+// the archive on disk and the slash-separated member path after it ("" for
+// the archive itself). ok is false when no leading part of filePath is an
+// existing archive file.
+func Split(filePath string) (archivePath, member string, ok bool) {
+	for i := 0; i <= len(filePath); i++ {
+		if i < len(filePath) && filePath[i] != '/' && filePath[i] != os.PathSeparator {
+			continue
+		}
+		prefix := filePath[:i]
+		if !IsArchive(prefix) {
+			continue
+		}
+		if info, err := os.Stat(prefix); err == nil && info.Mode().IsRegular() {
+			rest := strings.ReplaceAll(filePath[i:], string(os.PathSeparator), "/")
+			return prefix, cleanMember(rest), true
+		}
+	}
+	return "", "", false
+}
+
+// List returns the entries directly inside dir (a member path, "" for the
+// top level) of the archive, sorted by name. Directories the archive only
+// implies through the paths of its files are listed too.
+func List(archivePath, dir string) ([]fs.FileInfo, error) {
+	dir = cleanMember(dir)
+	found := dir == ""
+	seen := make(map[string]fs.FileInfo)
+	err := walk(archivePath, func(name string, info fs.FileInfo, _ func() (io.Reader, error)) (bool, error) {
+		rel := name
+		if dir != "" {
+			if !strings.HasPrefix(name, dir+"/") {
+				if name == dir {
+					found = true
+				}
+				return false, nil
+			}
+			rel = name[len(dir)+1:]
+		}
+		found = true
+		if rel == "" {
+			return false, nil
+		}
+		if child, _, nested := strings.Cut(rel, "/"); nested {
+			if _, ok := seen[child]; !ok {
+				seen[child] = &entry{name: child, mode: fs.ModeDir | 0o755}
+			}
+		} else {
+			seen[child] = &entry{name: child, size: info.Size(), mode: info.Mode(), modTime: info.ModTime()}
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("%s: %w", dir, fs.ErrNotExist)
+	}
+
+	entries := make([]fs.FileInfo, 0, len(seen))
+	for _, info := range seen {
+		entries = append(entries, info)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+// IsDir reports whether member is a directory in the archive; the archive
+// itself ("") is one.
+func IsDir(archivePath, member string) bool {
+	member = cleanMember(member)
+	if member == "" {
+		return true
+	}
+	isDir := false
+	walk(archivePath, func(name string, info fs.FileInfo, _ func() (io.Reader, error)) (bool, error) {
+		isDir = (name == member && info.IsDir()) || strings.HasPrefix(name, member+"/")
+		return isDir, nil
+	})
+	return isDir
+}
+
+// ReadFile returns the content of member in the archive. It fails with
+// ErrIsDir when member is a directory and fs.ErrNotExist when it is absent.
+func ReadFile(archivePath, member string) ([]byte, error) {
+	member = cleanMember(member)
+	if member == "" {
+		return nil, ErrIsDir
+	}
+	var data []byte
+	isDir := false
+	err := walk(archivePath, func(name string, info fs.FileInfo, open func() (io.Reader, error)) (bool, error) {
+		switch {
+		case name == member && info.IsDir(), strings.HasPrefix(name, member+"/"):
+			isDir = true
+			return true, nil
+		case name != member:
+			return false, nil
+		case info.Size() > maxMemberSize:
+			return true, fmt.Errorf("%s is larger than %d MiB", member, maxMemberSize>>20)
+		}
+		r, err := open()
+		if err != nil {
+			return true, err
+		}
+		data, err = io.ReadAll(r)
+		if data == nil {
+			data = []byte{}
+		}
+		return true, err
+	})
+	switch {
+	case err != nil:
+		return nil, err
+	case isDir:
+		return nil, ErrIsDir
+	case data == nil:
+		return nil, fmt.Errorf("%s: %w", member, fs.ErrNotExist)
+	}
+	return data, nil
+}
+
+// walk calls fn for each member of the archive, with its cleaned path and
+// a function opening its content, until fn returns true or an error.
+func walk(archivePath string, fn func(name string, info fs.FileInfo, open func() (io.Reader, error)) (bool, error)) error {
+	lower := strings.ToLower(archivePath)
+	if strings.HasSuffix(lower, ".zip") || strings.HasSuffix(lower, ".jar") {
+		zr, err := zip.OpenReader(archivePath)
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+		for _, f := range zr.File {
+			var rc io.ReadCloser
+			open := func() (io.Reader, error) {
+				var err error
+				rc, err = f.Open()
+				return rc, err
+			}
+			stop, err := fn(cleanMember(f.Name), f.FileInfo(), open)
+			if rc != nil {
+				rc.Close()
+			}
+			if stop || err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	var r io.Reader = file
+	if !strings.HasSuffix(lower, ".tar") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg && hdr.Typeflag != tar.TypeDir {
+			continue // Links and special files are not shown
+		}
+		open := func() (io.Reader, error) { return tr, nil }
+		if stop, err := fn(cleanMember(hdr.Name), hdr.FileInfo(), open); stop || err != nil {
+			return err
+		}
+	}
+}
+
+// cleanMember normalises a member path: slash-separated, without leading
+// "./" or "/" and without a trailing slash.
+func cleanMember(name string) string {
+	name = path.Clean("/" + name)
+	return strings.TrimPrefix(name, "/")
+}
+
+// entry describes a member found by List.
+type entry struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func (e *entry) Name() string       { return e.name }
+func (e *entry) Size() int64        { return e.size }
+func (e *entry) Mode() fs.FileMode  { return e.mode }
+func (e *entry) ModTime() time.Time { return e.modTime }
+func (e *entry) IsDir() bool        { return e.mode.IsDir() }
+func (e *entry) Sys() any           { return nil }
@@ -0,0 +1,137 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var testMembers = map[string]string{
+	"README.md":        "# hi\n",
+	"cmd/main.go":      "package main\n",
+	"cmd/tool/tool.go": "package tool\n",
+}
+
+func writeZip(t *testing.T, path string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	for name, text := range testMembers {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(text))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeTarGz(t *testing.T, path string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	tw.WriteHeader(&tar.Header{Name: "./cmd/", Typeflag: tar.TypeDir, Mode: 0o755})
+	for name, text := range testMembers {
+		tw.WriteHeader(&tar.Header{Name: "./" + name, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(text))})
+		tw.Write([]byte(text))
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSplit(t *testing.T) {
+	dir := t.TempDir()
+	zipPath := filepath.Join(dir, "src.zip")
+	writeZip(t, zipPath)
+
+	tests := []struct {
+		path    string
+		archive string
+		member  string
+		ok      bool
+	}{
+		{zipPath, zipPath, "", true},
+		{zipPath + "/cmd/main.go", zipPath, "cmd/main.go", true},
+		{zipPath + "/cmd/", zipPath, "cmd", true},
+		{filepath.Join(dir, "missing.zip", "a.go"), "", "", false},
+		{filepath.Join(dir, "plain.go"), "", "", false},
+	}
+	for _, tt := range tests {
+		archive, member, ok := Split(tt.path)
+		if archive != tt.archive || member != tt.member || ok != tt.ok {
+			t.Errorf("Split(%q) = %q, %q, %v; want %q, %q, %v", tt.path, archive, member, ok, tt.archive, tt.member, tt.ok)
+		}
+	}
+}
+
+func TestListAndRead(t *testing.T) {
+	dir := t.TempDir()
+	zipPath := filepath.Join(dir, "src.zip")
+	tgzPath := filepath.Join(dir, "src.tar.gz")
+	writeZip(t, zipPath)
+	writeTarGz(t, tgzPath)
+
+	for _, archive := range []string{zipPath, tgzPath} {
+		entries, err := List(archive, "")
+		if err != nil {
+			t.Fatalf("%s: %v", archive, err)
+		}
+		if len(entries) != 2 || entries[0].Name() != "README.md" || entries[1].Name() != "cmd" || !entries[1].IsDir() {
+			t.Errorf("%s: top level = %v", archive, names(entries))
+		}
+
+		entries, err = List(archive, "cmd")
+		if err != nil {
+			t.Fatalf("%s: %v", archive, err)
+		}
+		if len(entries) != 2 || entries[0].Name() != "main.go" || entries[1].Name() != "tool" || !entries[1].IsDir() {
+			t.Errorf("%s: cmd = %v", archive, names(entries))
+		}
+		if _, err := List(archive, "nope"); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("%s: List(nope) error = %v", archive, err)
+		}
+
+		if !IsDir(archive, "") || !IsDir(archive, "cmd/tool") || IsDir(archive, "cmd/main.go") {
+			t.Errorf("%s: IsDir wrong", archive)
+		}
+
+		data, err := ReadFile(archive, "cmd/tool/tool.go")
+		if err != nil || string(data) != testMembers["cmd/tool/tool.go"] {
+			t.Errorf("%s: ReadFile = %q, %v", archive, data, err)
+		}
+		if _, err := ReadFile(archive, "cmd"); !errors.Is(err, ErrIsDir) {
+			t.Errorf("%s: ReadFile(cmd) error = %v, want ErrIsDir", archive, err)
+		}
+		if _, err := ReadFile(archive, "cmd/none.go"); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("%s: ReadFile(missing) error = %v", archive, err)
+		}
+	}
+}
+
+func names(entries []fs.FileInfo) []string {
+	var out []string
+	for _, e := range entries {
+		out = append(out, e.Name())
+	}
+	return out
+}
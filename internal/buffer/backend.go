@@ -0,0 +1,93 @@
+package buffer
+
+import (
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Capabilities says what a buffer's storage allows; the editor makes the
+// buffer read-only when it is not Writable and leaves it unhighlighted when
+// it cannot be Parsed.
+type Capabilities struct {
+	Writable bool // The text can be edited and saved back where it came from
+	Seekable bool // Any line can be read without reading all the text; false for streams
+	// Parsed is false when the text is too large to be read whole, as syntax
+	// highlighting and the merge conflict scan do; it is then read a screen
+	// at a time.
+	Parsed bool
+}
+
+// Capable is implemented by buffers whose storage limits them. Buffers that
+// do not implement it are taken to be fully capable.
+type Capable interface {
+	Capabilities() Capabilities
+}
+
+// CapabilitiesOf returns what b's storage allows.
+func CapabilitiesOf(b Buffer) Capabilities {
+	if c, ok := b.(Capable); ok {
+		return c.Capabilities()
+	}
+	return Capabilities{Writable: true, Seekable: true, Parsed: true}
+}
+
+// Close releases what b holds besides its memory, such as the mapping and
+// the open file of a Mapped buffer, once it is no longer used.
+func Close(b Buffer) error {
+	if c, ok := b.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+var (
+	schemesMu sync.RWMutex
+	schemes   = map[string]func() Buffer{}
+)
+
+// RegisterScheme makes ForFile use newBuffer for paths starting with
+// scheme followed by "://", such as "sftp", so remote storage can be
+// plugged in. The buffer's Load and Save get the whole path. Registering a
+// scheme again replaces it; a nil newBuffer removes it.
+func RegisterScheme(scheme string, newBuffer func() Buffer) {
+	schemesMu.Lock()
+	defer schemesMu.Unlock()
+	if newBuffer == nil {
+		delete(schemes, scheme)
+		return
+	}
+	schemes[scheme] = newBuffer
+}
+
+// Scheme returns the registered scheme path starts with, if any.
+func Scheme(path string) (string, bool) {
+	scheme, _, ok := strings.Cut(path, "://")
+	if !ok {
+		return "", false
+	}
+	schemesMu.RLock()
+	defer schemesMu.RUnlock()
+	_, ok = schemes[scheme]
+	return scheme, ok
+}
+
+// ForFile returns an empty buffer to load path into: the registered
+// backend for its scheme, a read-only memory-mapped Mapped buffer when it
+// is a file of at least mapThreshold bytes (0 never maps), or else the
+// named backend as New gives it.
+func ForFile(path, backend string, mapThreshold int64) Buffer {
+	if scheme, ok := Scheme(path); ok {
+		schemesMu.RLock()
+		newBuffer := schemes[scheme]
+		schemesMu.RUnlock()
+		return newBuffer()
+	}
+	if mapThreshold > 0 && path != "" {
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() && info.Size() >= mapThreshold {
+			return NewMapped()
+		}
+	}
+	return New(backend)
+}
@@ -1,7 +1,32 @@
 // internal/buffer/buffer.go
 package buffer
 
-import "github.com/bethropolis/tide/internal/types" // Import types instead of core
+import (
+	"errors"
+
+	"github.com/bethropolis/tide/internal/types" // Import types instead of core
+)
+
+// Buffer implementations, as named by the buffer_backend setting.
+const (
+	BackendPieceTable = "piece_table" // The default
+	BackendRope       = "rope"        // For very large files: O(log n) edits
+)
+
+// Backends lists the buffer_backend values New knows.
+var Backends = []string{BackendPieceTable, BackendRope}
+
+// New returns an empty buffer of the named implementation. Unknown names
+// get the piece table.
+func New(backend string) Buffer {
+	if backend == BackendRope {
+		return NewRope()
+	}
+	return NewPieceTable()
+}
+
+// ErrReadOnly is returned by Insert and Delete on a read-only buffer.
+var ErrReadOnly = errors.New("buffer is read-only")
 
 // Buffer defines the interface for text buffer operations.
 type Buffer interface {
@@ -16,5 +41,9 @@ type Buffer interface {
 	Save(filePath string) error
 	Bytes() []byte
 	FilePath() string
+	SetFilePath(filePath string) // Change the associated path without touching disk
 	IsModified() bool
+	SetModified(modified bool) // Mark the text as differing from the file or, false, matching it
+	ReadOnly() bool
+	SetReadOnly(readOnly bool) // Make Insert and Delete fail with ErrReadOnly
 }
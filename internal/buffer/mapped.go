@@ -0,0 +1,353 @@
+package buffer
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+
+	"github.com/bethropolis/tide/internal/logger"
+	"github.com/bethropolis/tide/internal/types"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// Mapped is a read-only buffer for files too big to copy into memory, such
+// as huge logs. The file is memory-mapped (read whole where mapping is not
+// supported) and only the offsets of its lines are kept, so opening it
+// costs one pass over the text and the kernel pages it in as it is viewed.
+// Lines share the mapped memory and must not be written to.
+//
+// Another program may truncate the file while it is mapped (logrotate's
+// copytruncate, "> file"), and reading the pages past its new end then
+// faults. Reads of the text go through guard, which turns the fault into
+// an error, maps the file again at its new size and reads once more;
+// Lines checks the size of the file before handing out the mapped lines.
+type Mapped struct {
+	file       *os.File // Kept open to map again when the file shrinks
+	data       []byte
+	lineStarts []int // Byte offset of each line; always at least one
+
+	filePath string
+	modified bool
+}
+
+// NewMapped creates an empty Mapped buffer.
+func NewMapped() *Mapped {
+	return &Mapped{lineStarts: []int{0}}
+}
+
+// Capabilities reports that the text cannot be edited in place, and is not
+// to be copied out whole for parsing, which is what mapping it avoids.
+func (m *Mapped) Capabilities() Capabilities {
+	return Capabilities{Writable: false, Seekable: true, Parsed: false}
+}
+
+func (m *Mapped) Load(filePath string) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			m.filePath = filePath
+		}
+		return err
+	}
+	if m.file != nil {
+		m.file.Close()
+	}
+	m.file = file
+	m.filePath = filePath
+	m.modified = false
+	return m.remap()
+}
+
+// remap maps the file again at its current size and indexes its lines,
+// releasing the mapping it replaces. The text is empty when the file can
+// no longer be read.
+func (m *Mapped) remap() error {
+	old := m.data
+	m.data, m.lineStarts = nil, []int{0}
+	defer unmapFile(old)
+
+	info, err := m.file.Stat()
+	if err != nil {
+		return err
+	}
+	data, err := mapFile(m.file, info.Size())
+	if err != nil {
+		return err
+	}
+	var starts []int
+	if !guardRead(func() { starts = indexLines(make([]int, 1, info.Size()/64+1), data, 0) }) {
+		unmapFile(data)
+		return fmt.Errorf("%s shrank while being read", m.filePath)
+	}
+	m.data, m.lineStarts = data, starts
+	return nil
+}
+
+// shrank reports whether the file is now shorter than its mapping.
+func (m *Mapped) shrank() bool {
+	if m.file == nil {
+		return false
+	}
+	info, err := m.file.Stat()
+	return err == nil && info.Size() < int64(len(m.data))
+}
+
+// guard runs read, which reads the mapped text. When the file was
+// truncated under the mapping and read faults, the file is mapped again at
+// its new size and read runs once more. It returns false when the text
+// could still not be read.
+func (m *Mapped) guard(read func()) bool {
+	if guardRead(read) {
+		return true
+	}
+	if m.file == nil {
+		return false
+	}
+	if err := m.remap(); err != nil {
+		logger.Warnf("Mapped buffer: mapping %s again: %v", m.filePath, err)
+		return false
+	}
+	return guardRead(read)
+}
+
+// guardRead runs read, returning false instead of crashing when it faults
+// on a page of a mapping whose file has been truncated.
+func guardRead(read func()) (ok bool) {
+	defer debug.SetPanicOnFault(debug.SetPanicOnFault(true))
+	defer func() {
+		if r := recover(); r != nil {
+			if _, fault := r.(interface{ Addr() uintptr }); !fault {
+				panic(r)
+			}
+			ok = false
+		}
+	}()
+	read()
+	return true
+}
+
+// indexLines appends to starts the offset of each line starting after a
+// newline in data from byte from on.
+func indexLines(starts []int, data []byte, from int) []int {
+	for off := from; ; {
+		i := bytes.IndexByte(data[off:], '\n')
+		if i < 0 {
+			return starts
+		}
+		off += i + 1
+		starts = append(starts, off)
+	}
+}
+
+// Extend maps the file again at its current size and takes in what was
+// appended to it, indexing only the new lines (following a log), and
+// releases the mapping it replaces. It returns the edit the appended text
+// amounts to; a file that shrank is an error, and must be loaded again.
+func (m *Mapped) Extend() (types.EditInfo, error) {
+	if m.file == nil {
+		return types.EditInfo{}, fmt.Errorf("no file loaded")
+	}
+	info, err := m.file.Stat()
+	if err != nil {
+		return types.EditInfo{}, err
+	}
+	oldLen := len(m.data)
+	if info.Size() < int64(oldLen) {
+		return types.EditInfo{}, fmt.Errorf("%s shrank", m.filePath)
+	}
+	if info.Size() == int64(oldLen) {
+		return types.EditInfo{}, nil
+	}
+	data, err := mapFile(m.file, info.Size())
+	if err != nil {
+		return types.EditInfo{}, err
+	}
+
+	lastLine := len(m.lineStarts) - 1
+	start := sitter.Point{Row: uint32(lastLine), Column: uint32(oldLen - m.lineStarts[lastLine])}
+	starts := m.lineStarts
+	if !guardRead(func() { starts = indexLines(starts, data, oldLen) }) {
+		unmapFile(data)
+		return types.EditInfo{}, fmt.Errorf("%s shrank", m.filePath)
+	}
+	unmapFile(m.data)
+	m.data = data
+	m.lineStarts = starts
+	lastLine = len(m.lineStarts) - 1
+	return types.EditInfo{
+		StartIndex:     uint32(oldLen),
+		OldEndIndex:    uint32(oldLen),
+		NewEndIndex:    uint32(len(data)),
+		StartPosition:  start,
+		OldEndPosition: start,
+		NewEndPosition: sitter.Point{Row: uint32(lastLine), Column: uint32(len(data) - m.lineStarts[lastLine])},
+	}, nil
+}
+
+// Close releases the mapping and the file. The buffer is empty afterwards.
+func (m *Mapped) Close() error {
+	unmapFile(m.data)
+	m.data, m.lineStarts = nil, []int{0}
+	if m.file == nil {
+		return nil
+	}
+	err := m.file.Close()
+	m.file = nil
+	return err
+}
+
+// Len returns the length of the text in bytes.
+func (m *Mapped) Len() int {
+	return len(m.data)
+}
+
+// lineBounds returns the byte range of line, without its newline; lines
+// past the end are empty at the end of the text.
+func (m *Mapped) lineBounds(line int) (start, end int) {
+	if line < 0 {
+		line = 0
+	}
+	if line >= len(m.lineStarts) {
+		return len(m.data), len(m.data)
+	}
+	start, end = m.lineStarts[line], len(m.data)
+	if line+1 < len(m.lineStarts) {
+		end = m.lineStarts[line+1] - 1
+	}
+	return start, end
+}
+
+// offset returns the byte offset of pos in the text.
+func (m *Mapped) offset(pos types.Position) int {
+	start, end := m.lineBounds(pos.Line)
+	return start + colOffset(m.data[start:end], pos.Col)
+}
+
+// Lines returns the lines as slices of the mapping, mapping the file again
+// first if it shrank. The slices are only valid until the file is mapped
+// again (the next call to Lines, Extend or Load) or the buffer is closed,
+// and must not be kept.
+func (m *Mapped) Lines() [][]byte {
+	if m.shrank() {
+		if err := m.remap(); err != nil {
+			logger.Warnf("Mapped buffer: mapping %s again: %v", m.filePath, err)
+		}
+	}
+	lines := make([][]byte, len(m.lineStarts))
+	for i := range lines {
+		start, end := m.lineBounds(i)
+		lines[i] = m.data[start:end:end] // Capped, so appending copies
+	}
+	return lines
+}
+
+func (m *Mapped) Line(index int) ([]byte, error) {
+	if index < 0 || index >= len(m.lineStarts) {
+		return nil, fmt.Errorf("line index out of bounds")
+	}
+	var line []byte
+	gone := false
+	if !m.guard(func() {
+		if gone = index >= len(m.lineStarts); !gone { // The file may have shrunk
+			start, end := m.lineBounds(index)
+			line = append([]byte(nil), m.data[start:end]...)
+		}
+	}) {
+		return nil, fmt.Errorf("reading %s: file truncated", m.filePath)
+	}
+	if gone {
+		return nil, fmt.Errorf("line index out of bounds")
+	}
+	return line, nil
+}
+
+func (m *Mapped) LineCount() int {
+	return len(m.lineStarts)
+}
+
+func (m *Mapped) GetText(start, end types.Position) string {
+	var text string
+	m.guard(func() {
+		startOff, endOff := m.offset(start), m.offset(end)
+		if startOff > endOff {
+			startOff, endOff = endOff, startOff
+		}
+		text = string(m.data[startOff:endOff])
+	})
+	return text
+}
+
+func (m *Mapped) Insert(pos types.Position, text []byte) (types.EditInfo, error) {
+	return types.EditInfo{}, ErrReadOnly
+}
+
+func (m *Mapped) Delete(start, end types.Position) (types.EditInfo, error) {
+	return types.EditInfo{}, ErrReadOnly
+}
+
+// Save writes a copy of the text to filePath. Writing over the mapped file
+// itself would pull the text out from under the mapping, so it fails with
+// ErrReadOnly.
+func (m *Mapped) Save(filePath string) error {
+	path := filePath
+	if path == "" {
+		path = m.filePath
+	}
+	if path == "" {
+		return fmt.Errorf("no file path specified")
+	}
+	if m.filePath != "" && sameFile(path, m.filePath) {
+		return ErrReadOnly
+	}
+	data := m.Bytes()
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return err
+	}
+	m.filePath = path
+	m.modified = false
+	return nil
+}
+
+// sameFile reports whether paths a and b name the same file.
+func sameFile(a, b string) bool {
+	ai, aErr := os.Stat(a)
+	bi, bErr := os.Stat(b)
+	if aErr == nil && bErr == nil {
+		return os.SameFile(ai, bi)
+	}
+	return filepath.Clean(a) == filepath.Clean(b)
+}
+
+func (m *Mapped) Bytes() []byte {
+	var data []byte
+	m.guard(func() { data = append([]byte(nil), m.data...) })
+	return data
+}
+
+func (m *Mapped) FilePath() string {
+	return m.filePath
+}
+
+// SetFilePath changes the path the buffer is associated with. It does not
+// touch the file on disk or the mapping.
+func (m *Mapped) SetFilePath(filePath string) {
+	m.filePath = filePath
+}
+
+func (m *Mapped) IsModified() bool {
+	return m.modified
+}
+
+func (m *Mapped) SetModified(modified bool) {
+	m.modified = modified
+}
+
+// ReadOnly is always true: the text cannot be edited.
+func (m *Mapped) ReadOnly() bool {
+	return true
+}
+
+// SetReadOnly does nothing; a Mapped buffer stays read-only.
+func (m *Mapped) SetReadOnly(readOnly bool) {}
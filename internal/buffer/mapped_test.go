@@ -0,0 +1,169 @@
+package buffer
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bethropolis/tide/internal/types"
+)
+
+// TestMappedMatchesPieceTable loads the same file into a Mapped buffer and
+// a PieceTable and expects the same text and lines, and no edits.
+func TestMappedMatchesPieceTable(t *testing.T) {
+	dir := t.TempDir()
+	for name, text := range map[string]string{
+		"empty":    "",
+		"trailing": "one\ntwö\n\nthree\n",
+		"no_end":   "日本語\nlast",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+		m, pt := NewMapped(), NewPieceTable()
+		if err := m.Load(path); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		pt.Load(path)
+
+		if m.LineCount() != pt.LineCount() || !bytes.Equal(m.Bytes(), pt.Bytes()) {
+			t.Fatalf("%s: %d lines %q, want %d lines %q", name, m.LineCount(), m.Bytes(), pt.LineCount(), pt.Bytes())
+		}
+		lines := m.Lines()
+		for i, want := range pt.Lines() {
+			got, _ := m.Line(i)
+			if !bytes.Equal(got, want) || !bytes.Equal(lines[i], want) {
+				t.Errorf("%s: line %d = %q, %q; want %q", name, i, got, lines[i], want)
+			}
+		}
+		start, end := types.Position{Line: 0, Col: 1}, types.Position{Line: 1, Col: 2}
+		if got, want := m.GetText(start, end), pt.GetText(start, end); got != want {
+			t.Errorf("%s: GetText = %q, want %q", name, got, want)
+		}
+		if _, err := m.Insert(start, []byte("x")); !errors.Is(err, ErrReadOnly) {
+			t.Errorf("%s: Insert error = %v, want ErrReadOnly", name, err)
+		}
+		if !m.ReadOnly() || CapabilitiesOf(m).Writable {
+			t.Errorf("%s: mapped buffer is writable", name)
+		}
+	}
+}
+
+// TestMappedSave refuses to write over the mapped file but copies it elsewhere.
+func TestMappedSave(t *testing.T) {
+	dir := t.TempDir()
+	path, copyPath := filepath.Join(dir, "log"), filepath.Join(dir, "copy")
+	os.WriteFile(path, []byte("a\nb\n"), 0644)
+	m := NewMapped()
+	if err := m.Load(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Save(""); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Save over the mapped file: error = %v, want ErrReadOnly", err)
+	}
+	if err := m.Save(copyPath); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(copyPath); string(got) != "a\nb\n" {
+		t.Errorf("copy = %q", got)
+	}
+}
+
+// TestForFile picks the backend by scheme and size.
+func TestForFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "big")
+	os.WriteFile(path, bytes.Repeat([]byte("x"), 100), 0644)
+
+	if _, ok := ForFile(path, BackendRope, 100).(*Mapped); !ok {
+		t.Error("file at the threshold is not mapped")
+	}
+	if _, ok := ForFile(path, BackendRope, 101).(*Rope); !ok {
+		t.Error("file under the threshold does not get the configured backend")
+	}
+	if _, ok := ForFile(path, BackendRope, 0).(*Rope); !ok {
+		t.Error("threshold 0 maps files")
+	}
+
+	RegisterScheme("test", func() Buffer { return NewSliceBuffer() })
+	defer RegisterScheme("test", nil)
+	if _, ok := ForFile("test://host/file", BackendPieceTable, 0).(*SliceBuffer); !ok {
+		t.Error("registered scheme does not get its backend")
+	}
+	if _, ok := ForFile("other://host/file", BackendPieceTable, 0).(*PieceTable); !ok {
+		t.Error("unregistered scheme does not get the configured backend")
+	}
+}
+
+// TestMappedExtend takes in what was appended to the file.
+func TestMappedExtend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log")
+	os.WriteFile(path, []byte("one\ntw"), 0644)
+	m := NewMapped()
+	if err := m.Load(path); err != nil {
+		t.Fatal(err)
+	}
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString("o\nthree\n")
+	f.Close()
+
+	edit, err := m.Extend()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(m.Bytes()) != "one\ntwo\nthree\n" || m.LineCount() != 4 {
+		t.Errorf("extended to %q, %d lines", m.Bytes(), m.LineCount())
+	}
+	if line, _ := m.Line(1); string(line) != "two" {
+		t.Errorf("line 1 = %q, want %q", line, "two")
+	}
+	if edit.StartIndex != 6 || edit.NewEndIndex != 14 || edit.StartPosition.Row != 1 || edit.StartPosition.Column != 2 || edit.NewEndPosition.Row != 3 {
+		t.Errorf("edit = %+v", edit)
+	}
+
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Extend(); err == nil || m.LineCount() != 1 {
+		t.Errorf("closed buffer: %d lines, Extend error %v", m.LineCount(), err)
+	}
+}
+
+// TestMappedTruncated reads files truncated by another program while they
+// are mapped without faulting, and sees their new text.
+func TestMappedTruncated(t *testing.T) {
+	dir := t.TempDir()
+	long := bytes.Repeat([]byte("a line of the log\n"), 100000)
+	load := func(name string) (*Mapped, string) {
+		path := filepath.Join(dir, name)
+		os.WriteFile(path, long, 0644)
+		m := NewMapped()
+		if err := m.Load(path); err != nil {
+			t.Fatal(err)
+		}
+		return m, path
+	}
+
+	m, path := load("emptied")
+	if err := os.Truncate(path, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Line(90000); err == nil {
+		t.Error("Line past the new end: no error")
+	}
+	if m.LineCount() != 1 || len(m.Bytes()) != 0 {
+		t.Errorf("after truncation: %d lines, %d bytes; want 1, 0", m.LineCount(), len(m.Bytes()))
+	}
+
+	m, path = load("rewritten")
+	os.WriteFile(path, []byte("short\n"), 0644)
+	if lines := m.Lines(); len(lines) != 2 || string(lines[0]) != "short" {
+		t.Errorf("Lines after truncation = %q", lines)
+	}
+	if got := m.GetText(types.Position{Line: 0, Col: 0}, types.Position{Line: 0, Col: 5}); got != "short" {
+		t.Errorf("GetText = %q, want %q", got, "short")
+	}
+}
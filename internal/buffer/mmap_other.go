@@ -0,0 +1,16 @@
+//go:build !unix
+
+package buffer
+
+import (
+	"io"
+	"os"
+)
+
+// mapFile reads file whole where memory mapping is not supported.
+func mapFile(file *os.File, size int64) ([]byte, error) {
+	return io.ReadAll(file)
+}
+
+// unmapFile does nothing; the text read by mapFile is garbage collected.
+func unmapFile(data []byte) {}
@@ -0,0 +1,23 @@
+//go:build unix
+
+package buffer
+
+import (
+	"os"
+	"syscall"
+)
+
+// mapFile maps the size bytes of file into memory read-only.
+func mapFile(file *os.File, size int64) ([]byte, error) {
+	if size == 0 {
+		return nil, nil
+	}
+	return syscall.Mmap(int(file.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+}
+
+// unmapFile releases a mapping made by mapFile.
+func unmapFile(data []byte) {
+	if len(data) > 0 {
+		syscall.Munmap(data)
+	}
+}
@@ -19,6 +19,7 @@ type PieceTable struct {
 
 	filePath string
 	modified bool
+	readOnly bool
 
 	// Cached flat byte content and line-start offsets.
 	// lineOffsetsDirty is set to true after every mutation; the caches are
@@ -75,6 +76,17 @@ func (pt *PieceTable) Load(filePath string) error {
 	return nil
 }
 
+// SetContent replaces the whole buffer with content and marks it unmodified.
+// It is used for generated buffers (such as directory listings) that are not
+// backed by a regular file.
+func (pt *PieceTable) SetContent(content []byte) {
+	pt.original = content
+	pt.add = []byte{}
+	pt.pieces = []piece{{buffer: originalBuffer, start: 0, length: len(content)}}
+	pt.modified = false
+	pt.invalidateCache()
+}
+
 // Convert absolute position to piece
 func (pt *PieceTable) findPiece(offset int) (int, int) {
 	currentOffset := 0
@@ -289,6 +301,9 @@ func (pt *PieceTable) getBufferStateForEdit(pos types.Position) (byteOffset uint
 
 func (pt *PieceTable) Insert(pos types.Position, text []byte) (types.EditInfo, error) {
 	editInfo := types.EditInfo{}
+	if pt.readOnly {
+		return editInfo, ErrReadOnly
+	}
 	if len(text) == 0 {
 		return editInfo, nil
 	}
@@ -382,6 +397,9 @@ func (pt *PieceTable) Insert(pos types.Position, text []byte) (types.EditInfo, e
 
 func (pt *PieceTable) Delete(start, end types.Position) (types.EditInfo, error) {
 	editInfo := types.EditInfo{}
+	if pt.readOnly {
+		return editInfo, ErrReadOnly
+	}
 
 	// Get state *before* the edit
 	startIndexBytes, startPoint := pt.getBufferStateForEdit(start)
@@ -467,6 +485,28 @@ func (pt *PieceTable) FilePath() string {
 	return pt.filePath
 }
 
+// SetFilePath changes the path the buffer is associated with. It does not
+// touch the file on disk or the modified flag.
+func (pt *PieceTable) SetFilePath(filePath string) {
+	pt.filePath = filePath
+}
+
 func (pt *PieceTable) IsModified() bool {
 	return pt.modified
 }
+
+// SetModified sets the modified flag, as when the text was changed to
+// match the file without saving it.
+func (pt *PieceTable) SetModified(modified bool) {
+	pt.modified = modified
+}
+
+func (pt *PieceTable) ReadOnly() bool {
+	return pt.readOnly
+}
+
+// SetReadOnly makes Insert and Delete fail with ErrReadOnly. SetContent
+// still works, so generated buffers can be refreshed.
+func (pt *PieceTable) SetReadOnly(readOnly bool) {
+	pt.readOnly = readOnly
+}
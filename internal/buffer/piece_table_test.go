@@ -50,3 +50,19 @@ func TestPieceTable_Delete(t *testing.T) {
 		t.Errorf("Expected 'world', got '%s'", string(pt.Bytes()))
 	}
 }
+
+func TestPieceTable_SetContent(t *testing.T) {
+	pt := NewPieceTable()
+	pt.Insert(types.Position{Line: 0, Col: 0}, []byte("old"))
+
+	pt.SetContent([]byte("one\ntwo"))
+	if string(pt.Bytes()) != "one\ntwo" {
+		t.Errorf("Expected 'one\\ntwo', got '%s'", string(pt.Bytes()))
+	}
+	if pt.LineCount() != 2 {
+		t.Errorf("Expected 2 lines, got %d", pt.LineCount())
+	}
+	if pt.IsModified() {
+		t.Errorf("Expected buffer to be unmodified after SetContent")
+	}
+}
@@ -0,0 +1,402 @@
+package buffer
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"unicode/utf8"
+
+	"github.com/bethropolis/tide/internal/types"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// maxLeaf is the most bytes a rope leaf holds. Small edits merge into
+// their neighbouring leaf up to this size, so typing does not leave a leaf
+// per keystroke.
+const maxLeaf = 1024
+
+// Rope is a buffer for large files. The text is kept in a height-balanced
+// tree of byte chunks whose nodes count their bytes and newlines, so
+// inserts, deletes and finding where a line starts take O(log n) instead
+// of copying or scanning the whole text. Line reads one line out of the
+// tree, so drawing a screen does not depend on the size of the file. Lines
+// and Bytes still build the whole text; they cache it until the next edit
+// and hand out the cache itself, which callers must not modify.
+type Rope struct {
+	root *ropeNode
+
+	filePath string
+	modified bool
+	readOnly bool
+
+	cachedBytes []byte   // Whole text, built by Bytes; nil after an edit
+	cachedLines [][]byte // Built by Lines; nil after an edit
+}
+
+// ropeNode is a leaf holding text, or a branch joining two subtrees.
+// Nodes are never changed once built, so subtrees can be shared between
+// the old and new tree of an edit.
+type ropeNode struct {
+	left, right *ropeNode
+	text        []byte // Leaves only
+	length      int    // Bytes in the subtree
+	newlines    int    // '\n' bytes in the subtree
+	height      int    // 1 for leaves
+}
+
+// NewRope creates an empty Rope.
+func NewRope() *Rope {
+	return &Rope{}
+}
+
+func newLeaf(text []byte) *ropeNode {
+	return &ropeNode{text: text, length: len(text), newlines: bytes.Count(text, []byte{'\n'}), height: 1}
+}
+
+func newBranch(left, right *ropeNode) *ropeNode {
+	return &ropeNode{
+		left: left, right: right,
+		length:   left.length + right.length,
+		newlines: left.newlines + right.newlines,
+		height:   max(left.height, right.height) + 1,
+	}
+}
+
+func (n *ropeNode) isLeaf() bool { return n.left == nil }
+
+func height(n *ropeNode) int {
+	if n == nil {
+		return 0
+	}
+	return n.height
+}
+
+// buildRope makes a balanced tree of text in leaves of up to maxLeaf bytes.
+func buildRope(text []byte) *ropeNode {
+	if len(text) == 0 {
+		return nil
+	}
+	if len(text) <= maxLeaf {
+		return newLeaf(text[:len(text):len(text)])
+	}
+	mid := len(text) / 2
+	return newBranch(buildRope(text[:mid]), buildRope(text[mid:]))
+}
+
+// join concatenates two trees, keeping the result balanced: the shorter
+// tree is joined into the taller one along its edge, rotating on the way
+// back up where heights drift more than one apart. Adjacent small leaves
+// are merged.
+func join(l, r *ropeNode) *ropeNode {
+	switch {
+	case l == nil:
+		return r
+	case r == nil:
+		return l
+	case l.isLeaf() && r.isLeaf():
+		if l.length+r.length <= maxLeaf {
+			text := make([]byte, 0, l.length+r.length)
+			return newLeaf(append(append(text, l.text...), r.text...))
+		}
+		return newBranch(l, r)
+	case l.height > r.height+1 || (r.isLeaf() && !l.isLeaf()):
+		return balance(l.left, join(l.right, r))
+	case r.height > l.height+1 || (l.isLeaf() && !r.isLeaf()):
+		return balance(join(l, r.left), r.right)
+	}
+	return newBranch(l, r)
+}
+
+// balance joins two trees whose heights differ by at most two, rotating
+// when they differ by two.
+func balance(l, r *ropeNode) *ropeNode {
+	switch {
+	case height(l) > height(r)+1:
+		if height(l.left) >= height(l.right) {
+			return newBranch(l.left, newBranch(l.right, r))
+		}
+		return newBranch(newBranch(l.left, l.right.left), newBranch(l.right.right, r))
+	case height(r) > height(l)+1:
+		if height(r.right) >= height(r.left) {
+			return newBranch(newBranch(l, r.left), r.right)
+		}
+		return newBranch(newBranch(l, r.left.left), newBranch(r.left.right, r.right))
+	}
+	return newBranch(l, r)
+}
+
+// split cuts a tree at byte offset off into the text before and after it.
+func split(n *ropeNode, off int) (*ropeNode, *ropeNode) {
+	switch {
+	case n == nil:
+		return nil, nil
+	case off <= 0:
+		return nil, n
+	case off >= n.length:
+		return n, nil
+	case n.isLeaf():
+		// Full slice expressions keep appends to one half off the other
+		return newLeaf(n.text[:off:off]), newLeaf(n.text[off:])
+	case off < n.left.length:
+		ll, lr := split(n.left, off)
+		return ll, join(lr, n.right)
+	}
+	rl, rr := split(n.right, off-n.left.length)
+	return join(n.left, rl), rr
+}
+
+// afterNewline returns the byte offset just past the k-th newline (k >= 1)
+// of the tree, which must hold at least k.
+func afterNewline(n *ropeNode, k int) int {
+	offset := 0
+	for !n.isLeaf() {
+		if k <= n.left.newlines {
+			n = n.left
+			continue
+		}
+		k -= n.left.newlines
+		offset += n.left.length
+		n = n.right
+	}
+	for i, b := range n.text {
+		if b == '\n' {
+			if k--; k == 0 {
+				return offset + i + 1
+			}
+		}
+	}
+	return offset + n.length
+}
+
+// appendRange appends the bytes [start, end) of the tree to dst.
+func appendRange(dst []byte, n *ropeNode, start, end int) []byte {
+	if n == nil || start >= end || end <= 0 || start >= n.length {
+		return dst
+	}
+	if n.isLeaf() {
+		return append(dst, n.text[max(start, 0):min(end, n.length)]...)
+	}
+	dst = appendRange(dst, n.left, start, end)
+	return appendRange(dst, n.right, start-n.left.length, end-n.left.length)
+}
+
+func (r *Rope) length() int {
+	if r.root == nil {
+		return 0
+	}
+	return r.root.length
+}
+
+// lineBounds returns the byte offsets where line starts and ends (before
+// its '\n'). Lines past the end start and end at the end of the text.
+func (r *Rope) lineBounds(line int) (start, end int) {
+	count := r.LineCount()
+	if line >= count {
+		return r.length(), r.length()
+	}
+	if line > 0 {
+		start = afterNewline(r.root, line)
+	}
+	end = r.length()
+	if line+1 < count {
+		end = afterNewline(r.root, line+1) - 1
+	}
+	return start, end
+}
+
+// colOffset returns the byte offset of rune column col in line, clamped to
+// the line's end.
+func colOffset(line []byte, col int) int {
+	off := 0
+	for runes := 0; runes < col && off < len(line); runes++ {
+		_, size := utf8.DecodeRune(line[off:])
+		off += size
+	}
+	return off
+}
+
+// locate returns the byte offset of pos in the text and its tree-sitter
+// point, whose column counts bytes.
+func (r *Rope) locate(pos types.Position) (int, sitter.Point) {
+	start, end := r.lineBounds(pos.Line)
+	col := colOffset(appendRange(nil, r.root, start, end), pos.Col)
+	return start + col, sitter.Point{Row: uint32(pos.Line), Column: uint32(col)}
+}
+
+// edited drops the caches after a change to the text.
+func (r *Rope) edited() {
+	r.modified = true
+	r.cachedBytes = nil
+	r.cachedLines = nil
+}
+
+func (r *Rope) Load(filePath string) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			r.filePath = filePath
+		}
+		return err
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return err
+	}
+	r.SetContent(content)
+	r.filePath = filePath
+	return nil
+}
+
+// SetContent replaces the whole buffer with content and marks it unmodified.
+func (r *Rope) SetContent(content []byte) {
+	r.root = buildRope(content)
+	r.edited()
+	r.modified = false
+}
+
+// Bytes returns the whole text. The slice is shared with the buffer until
+// the next edit and must not be modified.
+func (r *Rope) Bytes() []byte {
+	if r.cachedBytes == nil {
+		r.cachedBytes = appendRange(make([]byte, 0, r.length()), r.root, 0, r.length())
+	}
+	return r.cachedBytes
+}
+
+// Lines returns the text split into lines, shared with the buffer like
+// Bytes: neither the slice nor the lines may be modified. Appending to
+// them makes a copy.
+func (r *Rope) Lines() [][]byte {
+	if r.cachedLines == nil {
+		r.cachedLines = bytes.Split(r.Bytes(), []byte{'\n'})
+	}
+	return r.cachedLines[:len(r.cachedLines):len(r.cachedLines)]
+}
+
+func (r *Rope) Line(index int) ([]byte, error) {
+	if index < 0 || index >= r.LineCount() {
+		return nil, fmt.Errorf("line index out of bounds")
+	}
+	start, end := r.lineBounds(index)
+	return appendRange(make([]byte, 0, end-start), r.root, start, end), nil
+}
+
+func (r *Rope) LineCount() int {
+	if r.root == nil {
+		return 1
+	}
+	return r.root.newlines + 1
+}
+
+func (r *Rope) GetText(start, end types.Position) string {
+	startOff, _ := r.locate(start)
+	endOff, _ := r.locate(end)
+	if startOff > endOff {
+		startOff, endOff = endOff, startOff
+	}
+	return string(appendRange(nil, r.root, startOff, endOff))
+}
+
+func (r *Rope) Insert(pos types.Position, text []byte) (types.EditInfo, error) {
+	editInfo := types.EditInfo{}
+	if r.readOnly {
+		return editInfo, ErrReadOnly
+	}
+	if len(text) == 0 {
+		return editInfo, nil
+	}
+
+	offset, startPoint := r.locate(pos)
+	editInfo.StartIndex = uint32(offset)
+	editInfo.StartPosition = startPoint
+	editInfo.OldEndIndex = uint32(offset) // For insert, old range is zero length
+	editInfo.OldEndPosition = startPoint
+
+	// Copy text: the caller may reuse its slice
+	before, after := split(r.root, offset)
+	r.root = join(join(before, buildRope(append([]byte(nil), text...))), after)
+	r.edited()
+
+	editInfo.NewEndIndex = uint32(offset + len(text))
+	editInfo.NewEndPosition = startPoint
+	if nl := bytes.Count(text, []byte{'\n'}); nl == 0 {
+		editInfo.NewEndPosition.Column += uint32(len(text))
+	} else {
+		editInfo.NewEndPosition.Row += uint32(nl)
+		editInfo.NewEndPosition.Column = uint32(len(text) - bytes.LastIndexByte(text, '\n') - 1)
+	}
+	return editInfo, nil
+}
+
+func (r *Rope) Delete(start, end types.Position) (types.EditInfo, error) {
+	editInfo := types.EditInfo{}
+	if r.readOnly {
+		return editInfo, ErrReadOnly
+	}
+
+	startOff, startPoint := r.locate(start)
+	endOff, endPoint := r.locate(end)
+	editInfo.StartIndex = uint32(startOff)
+	editInfo.StartPosition = startPoint
+	editInfo.OldEndIndex = uint32(endOff)
+	editInfo.OldEndPosition = endPoint
+	editInfo.NewEndIndex = uint32(startOff) // After delete, new end is where start was
+	editInfo.NewEndPosition = startPoint
+
+	if startOff >= endOff {
+		return editInfo, nil
+	}
+	before, rest := split(r.root, startOff)
+	_, after := split(rest, endOff-startOff)
+	r.root = join(before, after)
+	r.edited()
+	return editInfo, nil
+}
+
+func (r *Rope) Save(filePath string) error {
+	path := filePath
+	if path == "" {
+		path = r.filePath
+	}
+	if path == "" {
+		return fmt.Errorf("no file path specified")
+	}
+	if err := os.WriteFile(path, r.Bytes(), 0644); err != nil {
+		return err
+	}
+	r.filePath = path
+	r.modified = false
+	return nil
+}
+
+func (r *Rope) FilePath() string {
+	return r.filePath
+}
+
+// SetFilePath changes the path the buffer is associated with. It does not
+// touch the file on disk or the modified flag.
+func (r *Rope) SetFilePath(filePath string) {
+	r.filePath = filePath
+}
+
+func (r *Rope) IsModified() bool {
+	return r.modified
+}
+
+// SetModified sets the modified flag, as when the text was changed to
+// match the file without saving it.
+func (r *Rope) SetModified(modified bool) {
+	r.modified = modified
+}
+
+func (r *Rope) ReadOnly() bool {
+	return r.readOnly
+}
+
+// SetReadOnly makes Insert and Delete fail with ErrReadOnly.
+func (r *Rope) SetReadOnly(readOnly bool) {
+	r.readOnly = readOnly
+}
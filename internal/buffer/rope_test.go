@@ -0,0 +1,99 @@
+package buffer
+
+import (
+	"bytes"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/bethropolis/tide/internal/types"
+)
+
+// TestRopeMatchesPieceTable applies the same random edits to a Rope and a
+// PieceTable and expects the same text, lines and edit info.
+func TestRopeMatchesPieceTable(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	words := []string{"a", "héllo", "\n", "wörld\n", "\n\n", strings.Repeat("x", 700), "tab\tbed", "日本語"}
+
+	rope, pt := NewRope(), NewPieceTable()
+	initial := []byte(strings.Repeat("line of text\n", 400))
+	rope.SetContent(initial)
+	pt.SetContent(append([]byte(nil), initial...))
+
+	randomPos := func() types.Position {
+		line := rng.Intn(pt.LineCount() + 1)
+		return types.Position{Line: line, Col: rng.Intn(20)}
+	}
+	for i := 0; i < 2000; i++ {
+		var ropeInfo, ptInfo types.EditInfo
+		if rng.Intn(3) > 0 {
+			pos, text := randomPos(), []byte(words[rng.Intn(len(words))])
+			ropeInfo, _ = rope.Insert(pos, text)
+			ptInfo, _ = pt.Insert(pos, text)
+		} else {
+			start, end := randomPos(), randomPos()
+			if end.Line < start.Line || (end.Line == start.Line && end.Col < start.Col) {
+				start, end = end, start
+			}
+			ropeInfo, _ = rope.Delete(start, end)
+			ptInfo, _ = pt.Delete(start, end)
+		}
+		if ropeInfo != ptInfo {
+			t.Fatalf("edit %d: rope edit info %+v, piece table %+v", i, ropeInfo, ptInfo)
+		}
+		if !bytes.Equal(rope.Bytes(), pt.Bytes()) {
+			t.Fatalf("edit %d: texts differ", i)
+		}
+	}
+
+	if rope.LineCount() != pt.LineCount() {
+		t.Fatalf("LineCount() = %d, want %d", rope.LineCount(), pt.LineCount())
+	}
+	for i := 0; i < pt.LineCount(); i++ {
+		got, _ := rope.Line(i)
+		want, _ := pt.Line(i)
+		if !bytes.Equal(got, want) {
+			t.Fatalf("Line(%d) = %q, want %q", i, got, want)
+		}
+	}
+	if height(rope.root) > 40 {
+		t.Errorf("tree height %d after random edits; it is not staying balanced", height(rope.root))
+	}
+}
+
+func TestRopeEmptyAndReadOnly(t *testing.T) {
+	r := NewRope()
+	if r.LineCount() != 1 || len(r.Bytes()) != 0 {
+		t.Fatalf("empty rope: %d lines, %q", r.LineCount(), r.Bytes())
+	}
+	if _, err := r.Insert(types.Position{}, []byte("one\ntwo")); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.GetText(types.Position{Line: 0, Col: 2}, types.Position{Line: 1, Col: 1}); got != "e\nt" {
+		t.Errorf("GetText = %q, want %q", got, "e\nt")
+	}
+	if !r.IsModified() {
+		t.Errorf("expected the rope to be modified after Insert")
+	}
+	r.SetReadOnly(true)
+	if _, err := r.Delete(types.Position{}, types.Position{Line: 1}); err != ErrReadOnly {
+		t.Errorf("Delete on a read-only rope: err = %v, want ErrReadOnly", err)
+	}
+}
+
+func BenchmarkRopeTyping(b *testing.B) {
+	content := []byte(strings.Repeat("some line of text in a large file\n", 200000))
+	for _, buf := range []struct {
+		name string
+		buf  Buffer
+	}{{"rope", NewRope()}, {"piece_table", NewPieceTable()}} {
+		b.Run(buf.name, func(b *testing.B) {
+			buf.buf.(interface{ SetContent([]byte) }).SetContent(content)
+			pos := types.Position{Line: 100000, Col: 5}
+			for i := 0; i < b.N; i++ {
+				buf.buf.Insert(pos, []byte("x"))
+				pos.Col++
+			}
+		})
+	}
+}
@@ -20,6 +20,7 @@ type SliceBuffer struct {
 	lines    [][]byte
 	filePath string
 	modified bool // Track if buffer has unsaved changes
+	readOnly bool
 }
 
 // NewSliceBuffer creates an empty SliceBuffer.
@@ -192,10 +193,31 @@ func (sb *SliceBuffer) IsModified() bool {
 	return sb.modified
 }
 
+// SetModified sets the modified flag, as when the text was changed to
+// match the file without saving it.
+func (sb *SliceBuffer) SetModified(modified bool) {
+	sb.modified = modified
+}
+
+func (sb *SliceBuffer) ReadOnly() bool {
+	return sb.readOnly
+}
+
+// SetReadOnly makes Insert and Delete fail with ErrReadOnly.
+func (sb *SliceBuffer) SetReadOnly(readOnly bool) {
+	sb.readOnly = readOnly
+}
+
 func (sb *SliceBuffer) FilePath() string {
 	return sb.filePath
 }
 
+// SetFilePath changes the path the buffer is associated with. It does not
+// touch the file on disk or the modified flag.
+func (sb *SliceBuffer) SetFilePath(filePath string) {
+	sb.filePath = filePath
+}
+
 // --- Buffer Modification Methods ---
 
 // validateAndGetByteOffsets validates start and end positions and returns their byte offsets.
@@ -300,6 +322,9 @@ func (sb *SliceBuffer) getBufferStateForEdit(pos types.Position) (byteOffset uin
 // Insert inserts text at a given position. Handles single/multiple lines.
 func (sb *SliceBuffer) Insert(pos types.Position, text []byte) (types.EditInfo, error) {
 	editInfo := types.EditInfo{}
+	if sb.readOnly {
+		return editInfo, ErrReadOnly
+	}
 	if len(text) == 0 {
 		return editInfo, nil // No change, no edit info
 	}
@@ -373,6 +398,9 @@ func (sb *SliceBuffer) Insert(pos types.Position, text []byte) (types.EditInfo,
 // Delete removes text within a given range (start inclusive, end exclusive).
 func (sb *SliceBuffer) Delete(start, end types.Position) (types.EditInfo, error) {
 	editInfo := types.EditInfo{}
+	if sb.readOnly {
+		return editInfo, ErrReadOnly
+	}
 	if start == end {
 		return editInfo, nil // Nothing to delete
 	}
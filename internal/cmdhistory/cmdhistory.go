@@ -0,0 +1,153 @@
+// Package cmdhistory keeps the lines typed at the command, search and
+// expression prompts, in files that outlive the session. Each history is
+// merged with its file whenever a line is added, so several tide instances
+// running at once share one history instead of overwriting each other's.
+package cmdhistory
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/bethropolis/tide/internal/logger"
+)
+
+// List is one history, oldest line first, without duplicates. A List with
+// no path is kept in memory only.
+type List struct {
+	mu      sync.Mutex
+	path    string
+	max     int
+	entries []string
+}
+
+// Load reads the history saved at path, keeping at most max lines; the
+// file need not exist yet. With max 0 nothing is remembered.
+func Load(path string, max int) *List {
+	l := &List{path: path, max: max}
+	if path != "" && max > 0 {
+		l.entries = trim(readFile(path), max)
+	}
+	return l
+}
+
+// Add records line as the most recent entry, moving it to the end if it
+// is already there, and saves the history merged with what other
+// instances have saved meanwhile. Blank lines are not recorded.
+func (l *List) Add(line string) {
+	if strings.TrimSpace(line) == "" || strings.ContainsAny(line, "\r\n") {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.max <= 0 {
+		return
+	}
+
+	base := l.entries
+	if l.path != "" {
+		base = merge(l.entries, readFile(l.path))
+	}
+	l.entries = trim(appendUnique(base, line), l.max)
+	if l.path != "" {
+		if err := writeFile(l.path, l.entries); err != nil {
+			logger.Warnf("History: could not save '%s': %v", l.path, err)
+		}
+	}
+}
+
+// Entries returns the lines, oldest first.
+func (l *List) Entries() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.entries...)
+}
+
+// Len returns the number of lines.
+func (l *List) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.entries)
+}
+
+// merge returns the lines of ours and theirs (the file's) in the order of
+// theirs, followed by the lines only ours has. Both are oldest first.
+func merge(ours, theirs []string) []string {
+	out := make([]string, 0, len(ours)+len(theirs))
+	seen := make(map[string]bool, len(ours)+len(theirs))
+	for _, lines := range [][]string{theirs, ours} {
+		for _, line := range lines {
+			if !seen[line] {
+				seen[line] = true
+				out = append(out, line)
+			}
+		}
+	}
+	return out
+}
+
+// appendUnique appends line to lines, removing an earlier copy of it.
+func appendUnique(lines []string, line string) []string {
+	for i, existing := range lines {
+		if existing == line {
+			lines = append(lines[:i:i], lines[i+1:]...)
+			break
+		}
+	}
+	return append(lines, line)
+}
+
+// trim keeps the newest max lines.
+func trim(lines []string, max int) []string {
+	if len(lines) > max {
+		return append([]string(nil), lines[len(lines)-max:]...)
+	}
+	return lines
+}
+
+// readFile returns the lines saved at path, without duplicates, or nil if
+// it cannot be read.
+func readFile(path string) []string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Warnf("History: could not read '%s': %v", path, err)
+		}
+		return nil
+	}
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			lines = appendUnique(lines, line)
+		}
+	}
+	return lines
+}
+
+// writeFile saves lines to path through a temporary file, so another
+// instance never reads it half-written.
+func writeFile(path string, lines []string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.WriteString(strings.Join(lines, "\n") + "\n")
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
@@ -0,0 +1,62 @@
+package cmdhistory
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/bethropolis/tide/internal/logger"
+)
+
+func TestMain(m *testing.M) {
+	logger.Init(logger.Config{LogLevel: "error", LogFilePath: os.DevNull})
+	os.Exit(m.Run())
+}
+
+func TestAddDeduplicatesAndTrims(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history", "command")
+	l := Load(path, 3)
+	for _, line := range []string{"w", "q", "  ", "w", "set wrap", "e main.go"} {
+		l.Add(line)
+	}
+	want := []string{"w", "set wrap", "e main.go"}
+	if got := l.Entries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Entries = %q, want %q", got, want)
+	}
+	if got := Load(path, 3).Entries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("reloaded = %q, want %q", got, want)
+	}
+	if got := Load(path, 2).Entries(); !reflect.DeepEqual(got, want[1:]) {
+		t.Errorf("reloaded with max 2 = %q", got)
+	}
+}
+
+func TestConcurrentInstancesMerge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "search")
+	a := Load(path, 10)
+	b := Load(path, 10)
+	a.Add("foo")
+	b.Add("bar")
+	a.Add("baz")
+
+	want := []string{"foo", "bar", "baz"}
+	if got := a.Entries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("a = %q, want %q", got, want)
+	}
+	if got := Load(path, 10).Entries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("file = %q, want %q", got, want)
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "expr")
+	l := Load(path, 0)
+	l.Add("1+1")
+	if l.Len() != 0 {
+		t.Errorf("Len = %d with max 0", l.Len())
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("history file written with max 0: %v", err)
+	}
+}
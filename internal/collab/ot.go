@@ -0,0 +1,177 @@
+// Package collab lets two tide instances edit one buffer together. Edits
+// travel as byte-offset operations over a TCP session and are transformed
+// against the ones still in flight the other way, so both sides end up with
+// the same text whatever order they typed in (two-party operational
+// transformation, as in the Jupiter system).
+//
+// It is experimental: there is no authentication or encryption, one peer
+// joins each session, and only the buffer text and cursors are shared.
+package collab
+
+import (
+	"unicode/utf8"
+
+	"github.com/bethropolis/tide/internal/types"
+)
+
+// Op is one edit: the insertion of Ins at byte offset Pos, or, when Ins is
+// empty, the deletion of Del bytes from Pos. An op with neither is a no-op,
+// left behind when an insertion falls inside text the other side deleted.
+type Op struct {
+	Pos int    `json:"pos"`
+	Del int    `json:"del,omitempty"`
+	Ins string `json:"ins,omitempty"`
+}
+
+// Noop reports whether applying op changes nothing.
+func (op Op) Noop() bool {
+	return op.Del == 0 && op.Ins == ""
+}
+
+// Apply returns text with op applied.
+func (op Op) Apply(text []byte) []byte {
+	if op.Noop() {
+		return text
+	}
+	out := make([]byte, 0, len(text)+len(op.Ins)-op.Del)
+	out = append(out, text[:op.Pos]...)
+	out = append(out, op.Ins...)
+	return append(out, text[op.Pos+op.Del:]...)
+}
+
+// Transform returns a rewritten to apply after b, where a and b were made
+// against the same text. Of two insertions at one offset, a's text goes
+// first when aFirst is set; the other side must pass the opposite. Text
+// inserted inside a range the other side deleted is deleted with it.
+func Transform(a, b Op, aFirst bool) Op {
+	switch {
+	case a.Noop() || b.Noop():
+		return a
+	case a.Ins != "" && b.Ins != "":
+		if a.Pos > b.Pos || (a.Pos == b.Pos && !aFirst) {
+			a.Pos += len(b.Ins)
+		}
+	case a.Ins != "":
+		switch {
+		case a.Pos >= b.Pos+b.Del:
+			a.Pos -= b.Del
+		case a.Pos > b.Pos:
+			return Op{Pos: b.Pos}
+		}
+	case b.Ins != "":
+		switch {
+		case b.Pos <= a.Pos:
+			a.Pos += len(b.Ins)
+		case b.Pos < a.Pos+a.Del:
+			a.Del += len(b.Ins)
+		}
+	default:
+		start := mapDeleted(a.Pos, b)
+		end := mapDeleted(a.Pos+a.Del, b)
+		a.Pos, a.Del = start, end-start
+	}
+	return a
+}
+
+// TransformCursor moves the byte offset of a cursor over op, staying in
+// front of text inserted right at it.
+func TransformCursor(offset int, op Op) int {
+	if op.Ins != "" {
+		return Transform(Op{Pos: offset, Ins: " "}, op, true).Pos
+	}
+	return mapDeleted(offset, op)
+}
+
+// mapDeleted returns where offset p ends up once deletion d is applied.
+func mapDeleted(p int, d Op) int {
+	switch {
+	case p <= d.Pos:
+		return p
+	case p >= d.Pos+d.Del:
+		return p - d.Del
+	default:
+		return d.Pos
+	}
+}
+
+// PositionAt converts a byte offset in text to a line and rune column.
+func PositionAt(text []byte, offset int) types.Position {
+	offset = min(max(offset, 0), len(text))
+	var pos types.Position
+	lineStart := 0
+	for i := 0; i < offset; i++ {
+		if text[i] == '\n' {
+			pos.Line++
+			lineStart = i + 1
+		}
+	}
+	pos.Col = utf8.RuneCount(text[lineStart:offset])
+	return pos
+}
+
+// OffsetOf converts a line and rune column in text to a byte offset,
+// clamping positions past the end of a line or of the text.
+func OffsetOf(text []byte, pos types.Position) int {
+	offset := 0
+	for line := 0; line < pos.Line; line++ {
+		nl := -1
+		for i := offset; i < len(text); i++ {
+			if text[i] == '\n' {
+				nl = i
+				break
+			}
+		}
+		if nl < 0 {
+			return len(text)
+		}
+		offset = nl + 1
+	}
+	for col := 0; col < pos.Col && offset < len(text) && text[offset] != '\n'; col++ {
+		_, size := utf8.DecodeRune(text[offset:])
+		offset += size
+	}
+	return offset
+}
+
+// LinePosition is PositionAt for text read a line at a time through line,
+// which fails past the last line, so a buffer need not join its text to
+// place an offset.
+func LinePosition(line func(int) ([]byte, error), offset int) types.Position {
+	offset = max(offset, 0)
+	var last types.Position
+	for i := 0; ; i++ {
+		text, err := line(i)
+		if err != nil {
+			return last // Past the end of the text
+		}
+		if offset <= len(text) {
+			return types.Position{Line: i, Col: utf8.RuneCount(text[:offset])}
+		}
+		offset -= len(text) + 1
+		last = types.Position{Line: i, Col: utf8.RuneCount(text)}
+	}
+}
+
+// LineOffset is OffsetOf for text read a line at a time through line, as
+// LinePosition is for PositionAt.
+func LineOffset(line func(int) ([]byte, error), pos types.Position) int {
+	offset := 0
+	for i := 0; i < pos.Line; i++ {
+		text, err := line(i)
+		if err != nil {
+			return max(offset-1, 0) // Past the end: the last line has no newline
+		}
+		offset += len(text) + 1
+	}
+	text, err := line(pos.Line)
+	if err != nil {
+		return max(offset-1, 0)
+	}
+	col := 0
+	for i := 0; i < len(text) && col < pos.Col; col++ {
+		_, size := utf8.DecodeRune(text[i:])
+		i += size
+		offset += size
+	}
+	return offset
+}
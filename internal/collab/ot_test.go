@@ -0,0 +1,190 @@
+package collab
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/bethropolis/tide/internal/types"
+)
+
+func TestTransformConverges(t *testing.T) {
+	text := []byte("hello, world")
+	ops := []Op{
+		{Pos: 0, Ins: "A"},
+		{Pos: 5, Ins: "B"},
+		{Pos: 12, Ins: "C"},
+		{Pos: 0, Del: 3},
+		{Pos: 3, Del: 4},
+		{Pos: 5, Del: 2},
+		{Pos: 2, Del: 8},
+		{Pos: 11, Del: 1},
+	}
+	for _, a := range ops {
+		for _, b := range ops {
+			viaA := Transform(b, a, false).Apply(a.Apply(text))
+			viaB := Transform(a, b, true).Apply(b.Apply(text))
+			if string(viaA) != string(viaB) {
+				t.Errorf("a=%+v b=%+v: %q != %q", a, b, viaA, viaB)
+			}
+		}
+	}
+}
+
+// TestPeersConverge edits both sides at random, delivering messages late
+// and in batches as a network might, and checks the texts end up equal.
+func TestPeersConverge(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for round := 0; round < 200; round++ {
+		host, guest := NewPeer(true), NewPeer(false)
+		hostText, guestText := []byte("the quick brown fox"), []byte("the quick brown fox")
+		var toGuest, toHost []Message
+
+		for step := 0; step < 30; step++ {
+			switch rng.Intn(4) {
+			case 0:
+				op := randomOp(rng, hostText)
+				hostText = op.Apply(hostText)
+				toGuest = append(toGuest, host.Local(op))
+			case 1:
+				op := randomOp(rng, guestText)
+				guestText = op.Apply(guestText)
+				toHost = append(toHost, guest.Local(op))
+			case 2:
+				for _, m := range toGuest {
+					guestText = guest.Remote(m).Apply(guestText)
+				}
+				toGuest = nil
+			case 3:
+				for _, m := range toHost {
+					hostText = host.Remote(m).Apply(hostText)
+				}
+				toHost = nil
+			}
+		}
+		for _, m := range toGuest {
+			guestText = guest.Remote(m).Apply(guestText)
+		}
+		for _, m := range toHost {
+			hostText = host.Remote(m).Apply(hostText)
+		}
+		if string(hostText) != string(guestText) {
+			t.Fatalf("round %d: host %q, guest %q", round, hostText, guestText)
+		}
+	}
+}
+
+func randomOp(rng *rand.Rand, text []byte) Op {
+	pos := rng.Intn(len(text) + 1)
+	if rng.Intn(2) == 0 || pos == len(text) {
+		return Op{Pos: pos, Ins: string(rune('a' + rng.Intn(26)))}
+	}
+	return Op{Pos: pos, Del: 1 + rng.Intn(len(text)-pos)}
+}
+
+func TestPositionAtAndOffsetOf(t *testing.T) {
+	text := []byte("ab\nçd\n\nx")
+	lines := bytes.Split(text, []byte("\n"))
+	line := func(i int) ([]byte, error) {
+		if i >= len(lines) {
+			return nil, fmt.Errorf("no line %d", i)
+		}
+		return lines[i], nil
+	}
+	tests := []struct {
+		offset int
+		pos    types.Position
+	}{
+		{0, types.Position{Line: 0, Col: 0}},
+		{2, types.Position{Line: 0, Col: 2}},
+		{3, types.Position{Line: 1, Col: 0}},
+		{5, types.Position{Line: 1, Col: 1}},
+		{7, types.Position{Line: 2, Col: 0}},
+		{9, types.Position{Line: 3, Col: 1}},
+	}
+	for _, tt := range tests {
+		if got := PositionAt(text, tt.offset); got != tt.pos {
+			t.Errorf("PositionAt(%d) = %+v, want %+v", tt.offset, got, tt.pos)
+		}
+		if got := OffsetOf(text, tt.pos); got != tt.offset {
+			t.Errorf("OffsetOf(%+v) = %d, want %d", tt.pos, got, tt.offset)
+		}
+		if got := LinePosition(line, tt.offset); got != tt.pos {
+			t.Errorf("LinePosition(%d) = %+v, want %+v", tt.offset, got, tt.pos)
+		}
+		if got := LineOffset(line, tt.pos); got != tt.offset {
+			t.Errorf("LineOffset(%+v) = %d, want %d", tt.pos, got, tt.offset)
+		}
+	}
+	if got := OffsetOf(text, types.Position{Line: 0, Col: 9}); got != 2 {
+		t.Errorf("OffsetOf past the end of a line = %d, want 2", got)
+	}
+	if got := LineOffset(line, types.Position{Line: 0, Col: 9}); got != 2 {
+		t.Errorf("LineOffset past the end of a line = %d, want 2", got)
+	}
+	if got := LineOffset(line, types.Position{Line: 7}); got != len(text) {
+		t.Errorf("LineOffset past the last line = %d, want %d", got, len(text))
+	}
+	if got := LinePosition(line, 99); got != (types.Position{Line: 3, Col: 1}) {
+		t.Errorf("LinePosition past the end = %+v, want the end", got)
+	}
+}
+
+func TestSessionRoundTrip(t *testing.T) {
+	a, b := net.Pipe()
+	got := make(chan Message, 1)
+	sa := NewSession(a, func(Message) {}, func(error) {})
+	sb := NewSession(b, func(m Message) { got <- m }, func(error) {})
+	defer sa.Close()
+	defer sb.Close()
+
+	sa.Send(Message{Type: MsgOp, Op: Op{Pos: 3, Ins: "x"}, Sent: 1})
+	select {
+	case m := <-got:
+		if m.Type != MsgOp || m.Op != (Op{Pos: 3, Ins: "x"}) || m.Sent != 1 {
+			t.Errorf("received %+v", m)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no message received")
+	}
+}
+
+// TestSessionBurst sends far more messages at once than the network takes
+// in, as a substitution over a large buffer does, and expects the session
+// to deliver them all in order rather than end.
+func TestSessionBurst(t *testing.T) {
+	const n = 5000
+	a, b := net.Pipe()
+	got := make(chan Message, n)
+	sa := NewSession(a, func(Message) {}, func(error) {})
+	defer sa.Close()
+
+	sent := make(chan struct{})
+	go func() {
+		for i := 0; i < n; i++ {
+			sa.Send(Message{Type: MsgOp, Op: Op{Pos: i, Ins: "x"}, Sent: i + 1})
+		}
+		close(sent)
+	}()
+	select {
+	case <-sent:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Send blocked")
+	}
+
+	sb := NewSession(b, func(m Message) { got <- m }, func(error) {})
+	defer sb.Close()
+	for i := 0; i < n; i++ {
+		select {
+		case m := <-got:
+			if m.Sent != i+1 {
+				t.Fatalf("message %d has Sent %d", i, m.Sent)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("received %d of %d messages", i, n)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package collab
+
+// Message types sent over a session.
+const (
+	MsgSnapshot = "snapshot" // The host's text, sent once when a peer joins
+	MsgOp       = "op"       // One edit
+	MsgCursor   = "cursor"   // Where the sender's cursor is
+)
+
+// Message is what a session carries, one JSON object per line.
+type Message struct {
+	Type   string `json:"type"`
+	Text   string `json:"text,omitempty"`   // MsgSnapshot
+	Op     Op     `json:"op"`               // MsgOp
+	Cursor int    `json:"cursor,omitempty"` // MsgCursor, a byte offset
+	Sent   int    `json:"sent"`             // Ops the sender had sent before this message
+	Seen   int    `json:"seen"`             // Ops the sender had received from us
+}
+
+// Peer keeps one side of a session in step with the other: it numbers the
+// edits made here and transforms those arriving against the ones the other
+// side had not yet seen when it made them. Its methods are not safe for
+// concurrent use.
+type Peer struct {
+	first   bool // Our insertions go first on ties; true on exactly one side
+	sent    int
+	seen    int
+	pending []pendingOp // Ops sent but not yet acknowledged, oldest first
+}
+
+type pendingOp struct {
+	sent int
+	op   Op
+}
+
+// NewPeer returns the state for one side of a session. The host passes
+// true and the peer that joins false, so ties resolve the same way on both.
+func NewPeer(host bool) *Peer {
+	return &Peer{first: host}
+}
+
+// Local records op, already applied here, and returns the message that
+// sends it.
+func (p *Peer) Local(op Op) Message {
+	m := Message{Type: MsgOp, Op: op, Sent: p.sent, Seen: p.seen}
+	p.pending = append(p.pending, pendingOp{sent: p.sent, op: op})
+	p.sent++
+	return m
+}
+
+// Remote takes an op message from the other side and returns the op to
+// apply here.
+func (p *Peer) Remote(m Message) Op {
+	p.ack(m.Seen)
+	op := m.Op
+	for i := range p.pending {
+		mine := p.pending[i].op
+		p.pending[i].op = Transform(mine, op, p.first)
+		op = Transform(op, mine, !p.first)
+	}
+	p.seen++
+	return op
+}
+
+// Cursor returns the message telling the other side our cursor is at
+// byte offset.
+func (p *Peer) Cursor(offset int) Message {
+	return Message{Type: MsgCursor, Cursor: offset, Sent: p.sent, Seen: p.seen}
+}
+
+// RemoteCursor takes a cursor message from the other side and returns the
+// byte offset it names here.
+func (p *Peer) RemoteCursor(m Message) int {
+	p.ack(m.Seen)
+	offset := m.Cursor
+	for _, mine := range p.pending {
+		offset = TransformCursor(offset, mine.op)
+	}
+	return offset
+}
+
+// ack forgets the ops the other side has seen.
+func (p *Peer) ack(seen int) {
+	n := 0
+	for n < len(p.pending) && p.pending[n].sent < seen {
+		n++
+	}
+	p.pending = p.pending[n:]
+}
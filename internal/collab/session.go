@@ -0,0 +1,115 @@
+package collab
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync"
+)
+
+// maxMessage bounds one line of the protocol; a snapshot carries the whole
+// buffer.
+const maxMessage = 64 << 20
+
+// Session is a connection to the other side, exchanging Messages as lines
+// of JSON. Sends are queued and written by a goroutine of their own, so a
+// slow network never blocks the caller. The queue has no bound: a burst of
+// edits (a substitution over the whole buffer) waits in it rather than
+// ending the session.
+type Session struct {
+	conn      net.Conn
+	mu        sync.Mutex
+	queue     []Message     // Waiting to be written
+	wake      chan struct{} // Signals the writer that queue is not empty
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewSession starts reading and writing conn. onMessage is called for each
+// message received and onClose once, when the connection ends, both from
+// the session's reading goroutine.
+func NewSession(conn net.Conn, onMessage func(Message), onClose func(error)) *Session {
+	s := &Session{
+		conn: conn,
+		wake: make(chan struct{}, 1),
+		done: make(chan struct{}),
+	}
+	go s.write()
+	go func() {
+		err := s.read(onMessage)
+		s.Close()
+		onClose(err)
+	}()
+	return s
+}
+
+// Send queues m for the other side without waiting for it to be written.
+func (s *Session) Send(m Message) {
+	select {
+	case <-s.done:
+		return
+	default:
+	}
+	s.mu.Lock()
+	s.queue = append(s.queue, m)
+	s.mu.Unlock()
+	select {
+	case s.wake <- struct{}{}:
+	default: // The writer is already woken
+	}
+}
+
+// Close ends the session. It is safe to call more than once.
+func (s *Session) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.done)
+		err = s.conn.Close()
+	})
+	return err
+}
+
+// RemoteAddr returns the address of the other side.
+func (s *Session) RemoteAddr() string {
+	return s.conn.RemoteAddr().String()
+}
+
+func (s *Session) write() {
+	enc := json.NewEncoder(s.conn)
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-s.wake:
+		}
+		s.mu.Lock()
+		queued := s.queue
+		s.queue = nil
+		s.mu.Unlock()
+		for _, m := range queued {
+			if err := enc.Encode(m); err != nil {
+				s.Close()
+				return
+			}
+		}
+	}
+}
+
+func (s *Session) read(onMessage func(Message)) error {
+	scanner := bufio.NewScanner(s.conn)
+	scanner.Buffer(make([]byte, 64*1024), maxMessage)
+	for scanner.Scan() {
+		var m Message
+		if err := json.Unmarshal(scanner.Bytes(), &m); err != nil {
+			return fmt.Errorf("bad message from %s: %w", s.RemoteAddr(), err)
+		}
+		onMessage(m)
+	}
+	select {
+	case <-s.done:
+		return nil // Closed on purpose
+	default:
+		return scanner.Err()
+	}
+}
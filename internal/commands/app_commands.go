@@ -1,18 +1,35 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 
+	"github.com/bethropolis/tide/internal/config"
+	"github.com/bethropolis/tide/internal/core/calc"
+	"github.com/bethropolis/tide/internal/core/conflict"
 	"github.com/bethropolis/tide/internal/core/find"
+	"github.com/bethropolis/tide/internal/core/format"
+	"github.com/bethropolis/tide/internal/core/transform"
+	"github.com/bethropolis/tide/internal/event"
+	"github.com/bethropolis/tide/internal/i18n"
 	"github.com/bethropolis/tide/internal/logger"
 	"github.com/bethropolis/tide/internal/plugin"
+	"github.com/bethropolis/tide/internal/tui"
+	"github.com/bethropolis/tide/internal/types"
+	"github.com/bethropolis/tide/internal/utils"
 )
 
 // RegisterAppCommands registers built-in commands like :theme
 func RegisterAppCommands(api plugin.EditorAPI, themeAPI ThemeAPI) {
 	// Register theme commands
 	RegisterThemeCommands(api, themeAPI)
+	RegisterMapCommands(api)
+	RegisterSetCommands(api)
 
 	// --- Core File/Quit Commands ---
 
@@ -28,19 +45,18 @@ func RegisterAppCommands(api plugin.EditorAPI, themeAPI ThemeAPI) {
 		if err != nil {
 			return fmt.Errorf("failed to save buffer: %w", err) // Return error to show in status
 		}
-		api.SetStatusMessage("Buffer saved successfully.") // Show success
+		api.SetStatusMessage(i18n.T("status.saved")) // Show success
 		return nil
 	}
 
-	// :q - Quit if not modified
+	// :q - Quit, prompting to save/discard if any buffer is modified. With
+	// split windows only the focused window closes.
 	quitCmdFunc := func(args []string) error {
-		if api.IsBufferModified() {
-			// Return an error, ModeHandler will display it via status bar
-			return fmt.Errorf("no write since last change (use :q! to override)")
+		if api.WindowCount() > 1 {
+			return api.WindowCommand('q')
 		}
-		// Buffer not modified, request normal quit
-		api.RequestQuit(false) // Signal App to quit
-		return nil             // Return nil, quit signal is sent
+		api.RequestQuit(false) // App shows the save/discard dialog when needed
+		return nil
 	}
 
 	// :wq - Write and Quit
@@ -49,14 +65,20 @@ func RegisterAppCommands(api plugin.EditorAPI, themeAPI ThemeAPI) {
 		if err != nil {
 			return fmt.Errorf("save failed, not quitting: %w", err) // Report save error
 		}
-		// Save successful, request normal quit
-		api.SetStatusMessage("Buffer saved successfully.") // Show success before quit signal
-		api.RequestQuit(false)                             // Signal App to quit
+		// Save successful, request normal quit (prompts for other modified buffers)
+		api.SetStatusMessage(i18n.T("status.saved")) // Show success before quit signal
+		if api.WindowCount() > 1 {
+			return api.WindowCommand('q')
+		}
+		api.RequestQuit(false) // Signal App to quit
 		return nil
 	}
 
-	// :q! - Force Quit
+	// :q! - Force Quit (or close the focused split window)
 	forceQuitCmdFunc := func(args []string) error {
+		if api.WindowCount() > 1 {
+			return api.WindowCommand('q')
+		}
 		api.RequestQuit(true) // Signal App to force quit
 		return nil            // Return nil, quit signal is sent
 	}
@@ -200,23 +222,46 @@ func RegisterAppCommands(api plugin.EditorAPI, themeAPI ThemeAPI) {
 		if err != nil {
 			return fmt.Errorf("force save failed: %w", err)
 		}
-		api.SetStatusMessage("Buffer saved.")
+		api.SetStatusMessage(i18n.T("status.force_saved"))
 		return nil
 	}
 
-	// :e! - Reload file, discard changes
+	// :e! - Reload file, discard changes; undo brings them back
 	editForceCmdFunc := func(args []string) error {
-		if len(args) == 0 {
-			filePath := api.GetBufferFilePath()
-			if filePath == "" {
-				return fmt.Errorf("no file to reload")
-			}
-			api.OpenFile(filePath)
-		} else {
+		if len(args) > 0 && args[0] != api.GetBufferFilePath() {
 			api.OpenFile(args[0])
+			return nil
+		}
+		if api.GetBufferFilePath() == "" {
+			return fmt.Errorf("no file to reload")
 		}
+		return api.ReloadBuffer()
+	}
+
+	// :revert - Discard unsaved changes, undoably, after confirmation;
+	// :revert! does not ask
+	revertCmdFunc := func(args []string) error {
+		filePath := api.GetBufferFilePath()
+		if filePath == "" {
+			return fmt.Errorf("buffer has no file name")
+		}
+		if !api.IsBufferModified() {
+			api.SetStatusMessage("No unsaved changes")
+			return nil
+		}
+		api.ShowConfirm("Revert", []string{fmt.Sprintf("Discard unsaved changes to '%s'?", filePath), "Undo brings them back."}, []tui.ConfirmChoice{
+			{Key: 'y', Label: "es", Action: func() {
+				if err := api.RevertToSaved(); err != nil {
+					api.SetStatusMessage("Revert failed: %v", err)
+				}
+			}},
+			{Key: 'n', Label: "o"},
+		})
 		return nil
 	}
+	revertForceCmdFunc := func(args []string) error {
+		return api.RevertToSaved()
+	}
 
 	// :enew - New empty buffer
 	enewCmdFunc := func(args []string) error {
@@ -232,11 +277,520 @@ func RegisterAppCommands(api plugin.EditorAPI, themeAPI ThemeAPI) {
 		return nil
 	}
 
+	// :rename <newname> - Rename the current file on disk
+	renameCmdFunc := func(args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("usage: :rename <newname>")
+		}
+		oldPath := api.GetBufferFilePath()
+		if err := api.RenameFile(args[0]); err != nil {
+			return err
+		}
+		api.SetStatusMessage("Renamed %s -> %s", oldPath, api.GetBufferFilePath())
+		return nil
+	}
+
+	// :lsprename - Rename the symbol under the cursor via the language server
+	lspRenameCmdFunc := func(args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("usage: :lsprename <newname>")
+		}
+		return api.RenameSymbol(args[0])
+	}
+
+	// :trash / :delete / :delete! - Remove the current file from disk.
+	// All but :delete! ask for confirmation first.
+	deleteFile := func(toTrash bool) func() {
+		return func() {
+			filePath := api.GetBufferFilePath()
+			if err := api.DeleteFile(toTrash); err != nil {
+				api.SetStatusMessage("%v", err)
+				return
+			}
+			if toTrash {
+				api.SetStatusMessage("Moved %s to trash", filePath)
+			} else {
+				api.SetStatusMessage("Deleted %s", filePath)
+			}
+		}
+	}
+	confirmDelete := func(toTrash bool) error {
+		filePath := api.GetBufferFilePath()
+		if filePath == "" {
+			return fmt.Errorf("buffer has no file name")
+		}
+		title, verb := "Delete file", "Permanently delete"
+		if toTrash {
+			title, verb = "Trash file", "Move to trash"
+		}
+		api.ShowConfirm(title, []string{fmt.Sprintf("%s '%s'?", verb, filePath)}, []tui.ConfirmChoice{
+			{Key: 'y', Label: "es", Action: deleteFile(toTrash)},
+			{Key: 'n', Label: "o"},
+		})
+		return nil
+	}
+	trashCmdFunc := func(args []string) error {
+		return confirmDelete(true)
+	}
+	deleteCmdFunc := func(args []string) error {
+		return confirmDelete(false)
+	}
+	deleteForceCmdFunc := func(args []string) error {
+		if api.GetBufferFilePath() == "" {
+			return fmt.Errorf("buffer has no file name")
+		}
+		deleteFile(false)()
+		return nil
+	}
+
+	// :palette - Open the command palette (same as Ctrl+P)
+	paletteCmdFunc := func(args []string) error {
+		api.DispatchEvent(event.TypeTriggerCommandPalette, event.TriggerCommandPaletteData{})
+		return nil
+	}
+
+	// :calc <expr> - Evaluate arithmetic; :calc! <expr> inserts the result
+	calcCmdFunc := func(insert bool) plugin.CommandFunc {
+		return func(args []string) error {
+			expr := strings.Join(args, " ")
+			if expr == "" {
+				return fmt.Errorf("usage: :calc <expression>")
+			}
+			api.AddHistory("expr", expr)
+			v, err := calc.Eval(expr)
+			if err != nil {
+				return err
+			}
+			if insert {
+				return api.InsertAtCursor(calc.Format(v))
+			}
+			api.SetStatusMessage("%s = %s", expr, calc.Format(v))
+			return nil
+		}
+	}
+
+	// :uuid / :date / :time / :timestamp - Insert generated text at the cursor.
+	// :date and :time take an optional Go time layout.
+	uuidCmdFunc := func(args []string) error {
+		id, err := utils.NewUUID()
+		if err != nil {
+			return err
+		}
+		return api.InsertAtCursor(id)
+	}
+	timeCmdFunc := func(defaultLayout func() string) plugin.CommandFunc {
+		return func(args []string) error {
+			layout := strings.Join(args, " ")
+			if layout == "" {
+				layout = defaultLayout()
+			}
+			return api.InsertAtCursor(time.Now().Format(layout))
+		}
+	}
+	dateCmdFunc := timeCmdFunc(func() string { return config.Get().Editor.DateFormat })
+	clockCmdFunc := timeCmdFunc(func() string { return config.Get().Editor.TimeFormat })
+	timestampCmdFunc := timeCmdFunc(func() string { return time.RFC3339 })
+
+	// :transform <name> - Encode/decode the visual selection in place
+	transformCmdFunc := func(args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("usage: :transform <%s>", strings.Join(transform.Names(), "|"))
+		}
+		err := api.ReplaceSelection(func(text string) (string, error) {
+			return transform.Apply(args[0], text)
+		})
+		if err != nil {
+			return err
+		}
+		api.SetStatusMessage("Applied %s", args[0])
+		return nil
+	}
+
+	// :json fmt|min, :xml fmt, :sql fmt - Reformat the selection or whole
+	// buffer. Syntax errors replace the quickfix list.
+	formatCmdFunc := func(lang string, formatters map[string]func(text, indent string) (string, error)) plugin.CommandFunc {
+		return func(args []string) error {
+			if len(args) != 1 || formatters[args[0]] == nil {
+				names := make([]string, 0, len(formatters))
+				for name := range formatters {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+				return fmt.Errorf("usage: :%s <%s>", lang, strings.Join(names, "|"))
+			}
+			indent := strings.Repeat(" ", config.Get().Editor.TabWidth)
+			start, err := api.ReplaceSelectionOrBuffer(func(text string) (string, error) {
+				return formatters[args[0]](text, indent)
+			})
+			var syn *format.SyntaxError
+			if errors.As(err, &syn) {
+				pos := types.Position{Line: start.Line + syn.Line - 1, Col: syn.Col - 1}
+				if syn.Line == 1 {
+					pos.Col += start.Col
+				}
+				api.SetQuickfix([]types.QuickfixItem{{Path: api.GetBufferFilePath(), Pos: pos, Text: syn.Msg}})
+				return fmt.Errorf("invalid %s at %d:%d: %s (see :copen)", strings.ToUpper(lang), pos.Line+1, pos.Col+1, syn.Msg)
+			}
+			if err != nil {
+				return err
+			}
+			api.SetQuickfix(nil)
+			api.SetStatusMessage("Formatted %s", strings.ToUpper(lang))
+			return nil
+		}
+	}
+	jsonCmdFunc := formatCmdFunc("json", map[string]func(text, indent string) (string, error){
+		"fmt": format.JSON,
+		"min": func(text, _ string) (string, error) { return format.MinifyJSON(text) },
+	})
+	xmlCmdFunc := formatCmdFunc("xml", map[string]func(text, indent string) (string, error){"fmt": format.XML})
+	sqlCmdFunc := formatCmdFunc("sql", map[string]func(text, indent string) (string, error){"fmt": format.SQL})
+
+	// :table - Toggle the CSV/TSV table view; :table header pins the first row
+	tableCmdFunc := func(args []string) error {
+		switch {
+		case len(args) == 0:
+			on, err := api.ToggleTableView()
+			if err != nil {
+				return err
+			}
+			if on {
+				api.SetStatusMessage("Table view on")
+			} else {
+				api.SetStatusMessage("Table view off")
+			}
+		case len(args) == 1 && args[0] == "header":
+			if api.ToggleTableHeader() {
+				api.SetStatusMessage("Table header pinned")
+			} else {
+				api.SetStatusMessage("Table header unpinned")
+			}
+		default:
+			return fmt.Errorf("usage: :table [header]")
+		}
+		return nil
+	}
+
+	// :virtualedit - Toggle free cursor movement past line ends
+	virtualEditCmdFunc := func(args []string) error {
+		editorCfg := &config.Get().Editor
+		editorCfg.VirtualEdit = !editorCfg.VirtualEdit
+		if editorCfg.VirtualEdit {
+			api.SetStatusMessage("Virtual edit on")
+		} else {
+			api.SetCursor(api.GetCursor()) // Snap back inside the line
+			api.SetStatusMessage("Virtual edit off")
+		}
+		return nil
+	}
+
+	// :retab - Convert indentation to tabs, or spaces with expand_tab
+	retabCmdFunc := func(args []string) error {
+		n, err := api.Retab()
+		if err != nil {
+			return err
+		}
+		api.SetStatusMessage("Retabbed %d lines", n)
+		return nil
+	}
+
+	// :stickycontext - Toggle pinning the enclosing function at the top
+	stickyContextCmdFunc := func(args []string) error {
+		editorCfg := &config.Get().Editor
+		editorCfg.StickyContext = !editorCfg.StickyContext
+		if editorCfg.StickyContext {
+			api.SetStatusMessage("Sticky context on")
+		} else {
+			api.SetStatusMessage("Sticky context off")
+		}
+		return nil
+	}
+
+	// :blame - Toggle git blame for the cursor line
+	blameCmdFunc := func(args []string) error {
+		if api.ToggleInlineBlame() {
+			api.SetStatusMessage("Inline blame on")
+		} else {
+			api.SetStatusMessage("Inline blame off")
+		}
+		return nil
+	}
+
+	// :tail - Toggle following the file as it grows
+	tailCmdFunc := func(args []string) error {
+		on, err := api.ToggleTail()
+		if err != nil {
+			return err
+		}
+		if on {
+			api.SetStatusMessage("Following the end of the file (:tail again stops)")
+		} else {
+			api.SetStatusMessage("Stopped following the file")
+		}
+		return nil
+	}
+
+	// :hunk stage|revert|preview - Act on the git change under the cursor
+	hunkCmdFunc := func(args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("usage: :hunk stage|revert|preview")
+		}
+		switch args[0] {
+		case "stage":
+			return api.StageHunk()
+		case "revert":
+			return api.RevertHunk()
+		case "preview":
+			return api.PreviewHunk()
+		}
+		return fmt.Errorf("usage: :hunk stage|revert|preview")
+	}
+
+	// :diffsaved [off|revert|next|prev] - Unsaved changes against the file on disk
+	diffSavedCmdFunc := func(args []string) error {
+		if len(args) == 0 {
+			return api.DiffSaved()
+		}
+		if len(args) == 1 {
+			switch args[0] {
+			case "off":
+				api.StopDiffSaved()
+				return nil
+			case "revert":
+				return api.RevertSavedHunk()
+			case "next", "prev":
+				return api.JumpSavedHunk(args[0] == "next")
+			}
+		}
+		return fmt.Errorf("usage: :diffsaved [off|revert|next|prev]")
+	}
+
+	// :collab host [addr] | join addr | stop - Edit a buffer together with another tide
+	collabCmdFunc := func(args []string) error {
+		if len(args) == 0 {
+			if status := api.CollabStatus(); status != "" {
+				api.SetStatusMessage("Collab: %s", status)
+			} else {
+				api.SetStatusMessage("Collab: not in a session")
+			}
+			return nil
+		}
+		switch {
+		case args[0] == "host" && len(args) <= 2:
+			addr := ""
+			if len(args) == 2 {
+				addr = args[1]
+			}
+			return api.CollabHost(addr)
+		case args[0] == "join" && len(args) == 2:
+			return api.CollabJoin(args[1])
+		case args[0] == "stop" && len(args) == 1:
+			api.StopCollab()
+			return nil
+		}
+		return fmt.Errorf("usage: :collab [host [addr]|join host:port|stop]")
+	}
+
+	// :conflict ours|theirs|both - Resolve the merge conflict under the cursor
+	conflictCmdFunc := func(args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("usage: :conflict ours|theirs|both")
+		}
+		choice, err := conflict.ParseChoice(args[0])
+		if err != nil {
+			return err
+		}
+		if err := api.ResolveConflict(choice); err != nil {
+			return err
+		}
+		api.SetStatusMessage("Kept %s", args[0])
+		return nil
+	}
+
+	// :checkhealth - Diagnose the setup
+	checkhealthCmdFunc := func(args []string) error {
+		api.CheckHealth()
+		return nil
+	}
+
+	// :version - Build info, features, plugins and grammars
+	versionCmdFunc := func(args []string) error {
+		api.ShowVersion()
+		return nil
+	}
+
+	// :man [section] <topic> / :godoc <symbol> - Documentation in a read-only buffer
+	manCmdFunc := func(args []string) error {
+		return api.ShowMan(strings.Join(args, " "))
+	}
+	godocCmdFunc := func(args []string) error {
+		return api.ShowGoDoc(strings.Join(args, " "))
+	}
+
+	// :copen / :cnext / :cprev - Quickfix list
+	copenCmdFunc := func(args []string) error {
+		return api.OpenQuickfix()
+	}
+	cnextCmdFunc := func(args []string) error {
+		return api.QuickfixStep(1)
+	}
+	cprevCmdFunc := func(args []string) error {
+		return api.QuickfixStep(-1)
+	}
+
+	// :symbols / :symbol [name] - Definitions in the project index
+	symbolsCmdFunc := func(args []string) error {
+		return api.ShowSymbols()
+	}
+	symbolCmdFunc := func(args []string) error {
+		if len(args) > 1 {
+			return fmt.Errorf("usage: :symbol [name]")
+		}
+		name := ""
+		if len(args) == 1 {
+			name = args[0]
+		}
+		return api.GoToSymbol(name)
+	}
+
+	// :record {file} / :record / :source {file} - Editing scripts
+	recordCmdFunc := func(args []string) error {
+		switch len(args) {
+		case 0:
+			path, err := api.StopRecording()
+			if err != nil {
+				return fmt.Errorf("usage: :record {file} to start, :record to stop (%v)", err)
+			}
+			api.SetStatusMessage("Recorded %s", path)
+		case 1:
+			if err := api.StartRecording(args[0]); err != nil {
+				return err
+			}
+			api.SetStatusMessage("Recording to %s; :record stops", args[0])
+		default:
+			return fmt.Errorf("usage: :record {file} to start, :record to stop")
+		}
+		return nil
+	}
+	sourceCmdFunc := func(args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("usage: :source {file}")
+		}
+		return api.SourceScript(args[0])
+	}
+
+	// :mkview / :loadview - Save and restore the per-file view
+	mkviewCmdFunc := func(args []string) error {
+		if err := api.MakeView(); err != nil {
+			return err
+		}
+		api.SetStatusMessage("View saved")
+		return nil
+	}
+	loadviewCmdFunc := func(args []string) error {
+		return api.LoadView()
+	}
+
 	// :buffers / :ls - List buffers
 	buffersCmdFunc := func(args []string) error {
-		api.SetStatusMessage("Buffer list not yet implemented")
+		api.ListBuffers()
+		return nil
+	}
+
+	bufferCmdFunc := func(args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("usage: :b <number>")
+		}
+		n, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid buffer number: %s", args[0])
+		}
+		return api.SwitchBuffer(n)
+	}
+
+	// :marks - Pick a mark to go to; :delmarks a b, :delmarks! - Remove marks
+	marksCmdFunc := func(args []string) error {
+		api.ListMarks()
 		return nil
 	}
+	delmarksCmdFunc := func(args []string) error {
+		if len(args) == 0 {
+			return fmt.Errorf("usage: :delmarks <letters> or :delmarks!")
+		}
+		return api.DeleteMarks(strings.Join(args, ""))
+	}
+	delmarksAllCmdFunc := func(args []string) error {
+		return api.DeleteMarks("!")
+	}
+
+	// --- Window Commands ---
+
+	// :split [file] / :vsplit [file] - Split the focused window
+	splitCmdFunc := func(vertical bool) plugin.CommandFunc {
+		return func(args []string) error {
+			if len(args) > 1 {
+				return fmt.Errorf("usage: :split [file]")
+			}
+			file := ""
+			if len(args) == 1 {
+				file = args[0]
+			}
+			return api.Split(vertical, file)
+		}
+	}
+
+	// :wincmd {c} - Run the window command typed after Ctrl+W
+	wincmdCmdFunc := func(args []string) error {
+		if len(args) != 1 || utf8.RuneCountInString(args[0]) != 1 {
+			return fmt.Errorf("usage: :wincmd <s|v|w|W|h|j|k|l|c|q|o>")
+		}
+		r, _ := utf8.DecodeRuneInString(args[0])
+		return api.WindowCommand(r)
+	}
+
+	closeCmdFunc := func(args []string) error {
+		return api.WindowCommand('c')
+	}
+
+	onlyCmdFunc := func(args []string) error {
+		return api.WindowCommand('o')
+	}
+
+	// :split [file] - Split the window, stacked
+	err = api.RegisterCommand("split", splitCmdFunc(false))
+	if err != nil {
+		logger.Warnf("Failed to register ':split' command: %v", err)
+	}
+	err = api.RegisterCommand("sp", splitCmdFunc(false))
+	if err != nil {
+		logger.Warnf("Failed to register ':sp' command: %v", err)
+	}
+
+	// :vsplit [file] - Split the window, side by side
+	err = api.RegisterCommand("vsplit", splitCmdFunc(true))
+	if err != nil {
+		logger.Warnf("Failed to register ':vsplit' command: %v", err)
+	}
+	err = api.RegisterCommand("vs", splitCmdFunc(true))
+	if err != nil {
+		logger.Warnf("Failed to register ':vs' command: %v", err)
+	}
+
+	// :close / :only - Close the focused window or all the others
+	err = api.RegisterCommand("close", closeCmdFunc)
+	if err != nil {
+		logger.Warnf("Failed to register ':close' command: %v", err)
+	}
+	err = api.RegisterCommand("only", onlyCmdFunc)
+	if err != nil {
+		logger.Warnf("Failed to register ':only' command: %v", err)
+	}
+
+	// :wincmd {c} - Window command, as after Ctrl+W
+	err = api.RegisterCommand("wincmd", wincmdCmdFunc)
+	if err != nil {
+		logger.Warnf("Failed to register ':wincmd' command: %v", err)
+	}
 
 	// :x - Save and quit
 	err = api.RegisterCommand("x", writeQuitAliasFunc)
@@ -256,6 +810,16 @@ func RegisterAppCommands(api plugin.EditorAPI, themeAPI ThemeAPI) {
 		logger.Warnf("Failed to register ':e!' command: %v", err)
 	}
 
+	// :revert, :revert! - Discard unsaved changes undoably
+	err = api.RegisterCommand("revert", revertCmdFunc)
+	if err != nil {
+		logger.Warnf("Failed to register ':revert' command: %v", err)
+	}
+	err = api.RegisterCommand("revert!", revertForceCmdFunc)
+	if err != nil {
+		logger.Warnf("Failed to register ':revert!' command: %v", err)
+	}
+
 	// :enew - New buffer
 	err = api.RegisterCommand("enew", enewCmdFunc)
 	if err != nil {
@@ -281,6 +845,244 @@ func RegisterAppCommands(api plugin.EditorAPI, themeAPI ThemeAPI) {
 	if err != nil {
 		logger.Warnf("Failed to register ':ls' command: %v", err)
 	}
+
+	// :b N - Switch to a buffer by number
+	err = api.RegisterCommand("b", bufferCmdFunc)
+	if err != nil {
+		logger.Warnf("Failed to register ':b' command: %v", err)
+	}
+	err = api.RegisterCommand("buffer", bufferCmdFunc)
+	if err != nil {
+		logger.Warnf("Failed to register ':buffer' command: %v", err)
+	}
+
+	// :marks, :delmarks - Named marks
+	err = api.RegisterCommand("marks", marksCmdFunc)
+	if err != nil {
+		logger.Warnf("Failed to register ':marks' command: %v", err)
+	}
+	err = api.RegisterCommand("delmarks", delmarksCmdFunc)
+	if err != nil {
+		logger.Warnf("Failed to register ':delmarks' command: %v", err)
+	}
+	err = api.RegisterCommand("delmarks!", delmarksAllCmdFunc)
+	if err != nil {
+		logger.Warnf("Failed to register ':delmarks!' command: %v", err)
+	}
+
+	// :rename - Rename file
+	err = api.RegisterCommand("rename", renameCmdFunc)
+	if err != nil {
+		logger.Warnf("Failed to register ':rename' command: %v", err)
+	}
+
+	// :lsprename - Rename symbol
+	err = api.RegisterCommand("lsprename", lspRenameCmdFunc)
+	if err != nil {
+		logger.Warnf("Failed to register ':lsprename' command: %v", err)
+	}
+
+	// :trash / :delete / :delete! - Remove file
+	err = api.RegisterCommand("trash", trashCmdFunc)
+	if err != nil {
+		logger.Warnf("Failed to register ':trash' command: %v", err)
+	}
+	err = api.RegisterCommand("delete", deleteCmdFunc)
+	if err != nil {
+		logger.Warnf("Failed to register ':delete' command: %v", err)
+	}
+	err = api.RegisterCommand("delete!", deleteForceCmdFunc)
+	if err != nil {
+		logger.Warnf("Failed to register ':delete!' command: %v", err)
+	}
+
+	// :palette - Command palette
+	err = api.RegisterCommand("palette", paletteCmdFunc)
+	if err != nil {
+		logger.Warnf("Failed to register ':palette' command: %v", err)
+	}
+
+	// :calc / :calc! - Calculator
+	err = api.RegisterCommand("calc", calcCmdFunc(false))
+	if err != nil {
+		logger.Warnf("Failed to register ':calc' command: %v", err)
+	}
+	err = api.RegisterCommand("calc!", calcCmdFunc(true))
+	if err != nil {
+		logger.Warnf("Failed to register ':calc!' command: %v", err)
+	}
+
+	// :uuid / :date / :time / :timestamp - Insertion helpers
+	err = api.RegisterCommand("uuid", uuidCmdFunc)
+	if err != nil {
+		logger.Warnf("Failed to register ':uuid' command: %v", err)
+	}
+	err = api.RegisterCommand("date", dateCmdFunc)
+	if err != nil {
+		logger.Warnf("Failed to register ':date' command: %v", err)
+	}
+	err = api.RegisterCommand("time", clockCmdFunc)
+	if err != nil {
+		logger.Warnf("Failed to register ':time' command: %v", err)
+	}
+	err = api.RegisterCommand("timestamp", timestampCmdFunc)
+	if err != nil {
+		logger.Warnf("Failed to register ':timestamp' command: %v", err)
+	}
+
+	// :transform - Selection encoders/decoders
+	err = api.RegisterCommand("transform", transformCmdFunc)
+	if err != nil {
+		logger.Warnf("Failed to register ':transform' command: %v", err)
+	}
+	api.SetCommandCompletion("transform", transform.Names)
+
+	// :json / :xml / :sql - Data formatters
+	err = api.RegisterCommand("json", jsonCmdFunc)
+	if err != nil {
+		logger.Warnf("Failed to register ':json' command: %v", err)
+	}
+	api.SetCommandCompletion("json", func() []string { return []string{"fmt", "min"} })
+	err = api.RegisterCommand("xml", xmlCmdFunc)
+	if err != nil {
+		logger.Warnf("Failed to register ':xml' command: %v", err)
+	}
+	api.SetCommandCompletion("xml", func() []string { return []string{"fmt"} })
+	err = api.RegisterCommand("sql", sqlCmdFunc)
+	if err != nil {
+		logger.Warnf("Failed to register ':sql' command: %v", err)
+	}
+	api.SetCommandCompletion("sql", func() []string { return []string{"fmt"} })
+
+	// :table - CSV/TSV table view
+	err = api.RegisterCommand("table", tableCmdFunc)
+	if err != nil {
+		logger.Warnf("Failed to register ':table' command: %v", err)
+	}
+	api.SetCommandCompletion("table", func() []string { return []string{"header"} })
+
+	// :retab - Indentation to tabs or spaces
+	err = api.RegisterCommand("retab", retabCmdFunc)
+	if err != nil {
+		logger.Warnf("Failed to register ':retab' command: %v", err)
+	}
+
+	// :virtualedit - Cursor past line ends
+	err = api.RegisterCommand("virtualedit", virtualEditCmdFunc)
+	if err != nil {
+		logger.Warnf("Failed to register ':virtualedit' command: %v", err)
+	}
+
+	// :stickycontext - Enclosing function pinned at the top
+	err = api.RegisterCommand("stickycontext", stickyContextCmdFunc)
+	if err != nil {
+		logger.Warnf("Failed to register ':stickycontext' command: %v", err)
+	}
+
+	// :blame - Inline git blame
+	err = api.RegisterCommand("blame", blameCmdFunc)
+	if err != nil {
+		logger.Warnf("Failed to register ':blame' command: %v", err)
+	}
+
+	// :tail - Follow a growing file
+	err = api.RegisterCommand("tail", tailCmdFunc)
+	if err != nil {
+		logger.Warnf("Failed to register ':tail' command: %v", err)
+	}
+
+	// :hunk - git hunks
+	err = api.RegisterCommand("hunk", hunkCmdFunc)
+	if err != nil {
+		logger.Warnf("Failed to register ':hunk' command: %v", err)
+	}
+	api.SetCommandCompletion("hunk", func() []string { return []string{"preview", "revert", "stage"} })
+
+	// :diffsaved - unsaved changes
+	err = api.RegisterCommand("diffsaved", diffSavedCmdFunc)
+	if err != nil {
+		logger.Warnf("Failed to register ':diffsaved' command: %v", err)
+	}
+	api.SetCommandCompletion("diffsaved", func() []string { return []string{"next", "off", "prev", "revert"} })
+
+	// :collab - Shared editing
+	err = api.RegisterCommand("collab", collabCmdFunc)
+	if err != nil {
+		logger.Warnf("Failed to register ':collab' command: %v", err)
+	}
+	api.SetCommandCompletion("collab", func() []string { return []string{"host", "join", "stop"} })
+
+	// :conflict - Merge conflicts
+	err = api.RegisterCommand("conflict", conflictCmdFunc)
+	if err != nil {
+		logger.Warnf("Failed to register ':conflict' command: %v", err)
+	}
+	api.SetCommandCompletion("conflict", func() []string { return []string{"both", "ours", "theirs"} })
+
+	// :checkhealth - Diagnostics
+	err = api.RegisterCommand("checkhealth", checkhealthCmdFunc)
+	if err != nil {
+		logger.Warnf("Failed to register ':checkhealth' command: %v", err)
+	}
+
+	err = api.RegisterCommand("version", versionCmdFunc)
+	if err != nil {
+		logger.Warnf("Failed to register ':version' command: %v", err)
+	}
+
+	// :man / :godoc - Documentation viewer
+	err = api.RegisterCommand("man", manCmdFunc)
+	if err != nil {
+		logger.Warnf("Failed to register ':man' command: %v", err)
+	}
+	err = api.RegisterCommand("godoc", godocCmdFunc)
+	if err != nil {
+		logger.Warnf("Failed to register ':godoc' command: %v", err)
+	}
+
+	// :copen / :cnext / :cprev - Quickfix list
+	err = api.RegisterCommand("copen", copenCmdFunc)
+	if err != nil {
+		logger.Warnf("Failed to register ':copen' command: %v", err)
+	}
+	err = api.RegisterCommand("cnext", cnextCmdFunc)
+	if err != nil {
+		logger.Warnf("Failed to register ':cnext' command: %v", err)
+	}
+	err = api.RegisterCommand("cprev", cprevCmdFunc)
+	if err != nil {
+		logger.Warnf("Failed to register ':cprev' command: %v", err)
+	}
+
+	// :symbols / :symbol - Project symbols
+	err = api.RegisterCommand("symbols", symbolsCmdFunc)
+	if err != nil {
+		logger.Warnf("Failed to register ':symbols' command: %v", err)
+	}
+	err = api.RegisterCommand("symbol", symbolCmdFunc)
+	if err != nil {
+		logger.Warnf("Failed to register ':symbol' command: %v", err)
+	}
+
+	// :record / :source - Editing scripts
+	err = api.RegisterCommand("record", recordCmdFunc)
+	if err != nil {
+		logger.Warnf("Failed to register ':record' command: %v", err)
+	}
+	err = api.RegisterCommand("source", sourceCmdFunc)
+	if err != nil {
+		logger.Warnf("Failed to register ':source' command: %v", err)
+	}
+
+	// :mkview / :loadview - Per-file view state
+	err = api.RegisterCommand("mkview", mkviewCmdFunc)
+	if err != nil {
+		logger.Warnf("Failed to register ':mkview' command: %v", err)
+	}
+	err = api.RegisterCommand("loadview", loadviewCmdFunc)
+	if err != nil {
+		logger.Warnf("Failed to register ':loadview' command: %v", err)
+	}
 }
 
 // RegisterThemeCommands registers only theme-related commands
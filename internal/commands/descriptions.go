@@ -0,0 +1,129 @@
+package commands
+
+import "github.com/bethropolis/tide/internal/i18n"
+
+// descriptions holds one-line help text for built-in and bundled plugin
+// commands. It is used by the command palette; commands without an entry
+// are still listed, just without a description.
+var descriptions = map[string]string{
+	"w":             "Write buffer to file",
+	"w!":            "Force write buffer to file",
+	"q":             "Quit (asks to save or discard modified buffers), or close the window when split",
+	"q!":            "Quit without saving",
+	"wq":            "Write and quit",
+	"x":             "Write and quit",
+	"s":             "Substitute on the current line (:s/pat/rep/[g][i])",
+	"e":             "Open a file",
+	"e!":            "Reload file, discarding changes",
+	"revert":        "Discard unsaved changes after confirming; undo brings them back",
+	"revert!":       "Discard unsaved changes without asking; undo brings them back",
+	"enew":          "Open a new empty buffer",
+	"bn":            "Next buffer",
+	"bnext":         "Next buffer",
+	"bp":            "Previous buffer",
+	"bprev":         "Previous buffer",
+	"bd":            "Close buffer",
+	"bdelete":       "Close buffer",
+	"bd!":           "Close buffer, discarding changes",
+	"nohlsearch":    "Clear search highlights",
+	"noh":           "Clear search highlights",
+	"buffers":       "List open buffers",
+	"ls":            "List open buffers",
+	"b":             "Switch to buffer N (:b N)",
+	"buffer":        "Switch to buffer N (:buffer N)",
+	"marks":         "List the marks of the buffer and go to one",
+	"delmarks":      "Remove marks (:delmarks a b)",
+	"delmarks!":     "Remove all marks of the buffer",
+	"split":         "Split the window, one above the other (:split [file])",
+	"sp":            "Split the window, one above the other (:sp [file])",
+	"vsplit":        "Split the window, side by side (:vsplit [file])",
+	"vs":            "Split the window, side by side (:vs [file])",
+	"close":         "Close the focused window",
+	"only":          "Close every window but the focused one",
+	"wincmd":        "Run a window command, as typed after Ctrl+W",
+	"theme":         "Show or set the colour theme",
+	"themes":        "List available themes",
+	"palette":       "Open the command palette",
+	"rename":        "Rename the current file on disk",
+	"lsprename":     "Rename the symbol under the cursor across the project (language server)",
+	"trash":         "Move the current file to the trash",
+	"delete":        "Delete the current file from disk",
+	"delete!":       "Delete the current file without confirmation",
+	"calc":          "Evaluate an arithmetic expression",
+	"calc!":         "Evaluate an expression and insert the result",
+	"uuid":          "Insert a random UUID",
+	"date":          "Insert today's date (optional Go time layout)",
+	"time":          "Insert the current time (optional Go time layout)",
+	"timestamp":     "Insert an ISO 8601 timestamp",
+	"transform":     "Encode or decode the selection (base64, url, html, json)",
+	"json":          "Pretty-print (fmt) or minify (min) JSON in the selection or buffer",
+	"xml":           "Pretty-print XML in the selection or buffer",
+	"sql":           "Lay out SQL one clause per line in the selection or buffer",
+	"table":         "Toggle the aligned CSV/TSV table view (:table header pins row 1)",
+	"virtualedit":   "Toggle placing the cursor past the end of lines",
+	"set":           "Change a setting for this session (:set expandtab, :set number!, :set nostatusline)",
+	"retab":         "Convert indentation to tabs, or to spaces with expand_tab",
+	"stickycontext": "Toggle pinning the enclosing function's first line at the top",
+	"blame":         "Toggle git blame for the cursor line",
+	"tail":          "Toggle following the file as it grows, like tail -f",
+	"hunk":          "Stage, revert (to HEAD) or preview the git change under the cursor",
+	"diffsaved":     "Mark and list unsaved changes against the file on disk; revert or jump between them",
+	"collab":        "Share the buffer with another tide (host [addr]), join one (join host:port) or stop",
+	"conflict":      "Resolve the merge conflict under the cursor (ours, theirs or both)",
+	"checkhealth":   "Check the config, themes, keybindings, tools, grammars and plugins",
+	"version":       "Show the build, enabled features, plugins and grammars",
+	"man":           "Show a manual page in a read-only buffer",
+	"godoc":         "Show go doc for a package or symbol in a read-only buffer",
+	"copen":         "List the quickfix entries",
+	"cnext":         "Jump to the next quickfix entry",
+	"cprev":         "Jump to the previous quickfix entry",
+	"symbols":       "List the definitions in the project",
+	"symbol":        "Jump to a definition in the project, by default the word under the cursor",
+	"record":        "Record the editing to a script file, or stop recording",
+	"source":        "Replay the steps of a script file",
+	"mkview":        "Save the cursor and scroll position of this file",
+	"loadview":      "Restore the view saved with :mkview",
+	"files":         "Count files in a directory",
+	"pick":          "Pick a file to open",
+	"wc":            "Count lines, words and bytes",
+}
+
+func init() {
+	for name, c := range mapCommands {
+		var modes string
+		switch c.modes {
+		case "":
+			modes = "normal, visual and operator-pending mode"
+		case "n":
+			modes = "normal mode"
+		case "v":
+			modes = "visual mode"
+		case "o":
+			modes = "operator-pending mode"
+		case "i":
+			modes = "insert mode"
+		case "c":
+			modes = "the command line"
+		case "!":
+			modes = "insert mode and the command line"
+		}
+		switch {
+		case c.unmap:
+			descriptions[name] = "Remove a key mapping in " + modes
+		case c.noremap:
+			descriptions[name] = "Map keys in " + modes + " without remapping the result, or list mappings"
+		default:
+			descriptions[name] = "Map keys in " + modes + " (e.g. jk <Esc>), or list mappings"
+		}
+	}
+	descriptions["verbose"] = "List key mappings with where each was made (:verbose map [lhs])"
+}
+
+// Describe returns the help text for a command name, or "" if none is known.
+// The current locale may translate it as command.<name>.
+func Describe(name string) string {
+	if s, ok := i18n.Translated("command." + name); ok {
+		return s
+	}
+	return descriptions[name]
+}
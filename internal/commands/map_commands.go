@@ -0,0 +1,92 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bethropolis/tide/internal/logger"
+	"github.com/bethropolis/tide/internal/plugin"
+)
+
+// mapCommand is one of the :map family of commands.
+type mapCommand struct {
+	name    string
+	modes   string // Mode letters, see plugin.EditorAPI.MapKeys
+	noremap bool
+	unmap   bool
+}
+
+// mapCommands are the :map commands by Vim's names: a mode prefix (n, v,
+// o, i, c, or none for normal, visual and operator-pending), and a '!'
+// suffix for insert and command line.
+var mapCommands = func() map[string]mapCommand {
+	cmds := make(map[string]mapCommand)
+	for _, prefix := range []string{"", "n", "v", "o", "i", "c", "!"} {
+		modes, suffix := prefix, ""
+		if prefix == "!" {
+			modes, prefix, suffix = "!", "", "!"
+		}
+		for _, c := range []mapCommand{
+			{name: prefix + "map" + suffix},
+			{name: prefix + "noremap" + suffix, noremap: true},
+			{name: prefix + "unmap" + suffix, unmap: true},
+		} {
+			c.modes = modes
+			cmds[c.name] = c
+		}
+	}
+	return cmds
+}()
+
+// run lists mappings (no arguments, or just a left-hand side), maps the
+// first argument to the rest, or unmaps it.
+func (c mapCommand) run(api plugin.EditorAPI, args []string, verbose bool) error {
+	switch {
+	case c.unmap:
+		if len(args) != 1 {
+			return fmt.Errorf("usage: :%s {lhs}", c.name)
+		}
+		if err := api.UnmapKeys(c.modes, args[0]); err != nil {
+			return err
+		}
+		api.SetStatusMessage("Unmapped %s", args[0])
+		return nil
+	case len(args) < 2 || verbose:
+		lhs := ""
+		if len(args) > 0 {
+			lhs = args[0]
+		}
+		return api.ShowMappings(c.modes, lhs, verbose)
+	}
+	// Arguments are split on spaces; spell out spaces in the keys as <Space>
+	rhs := strings.Join(args[1:], " ")
+	return api.MapKeys(c.modes, args[0], rhs, c.noremap, ":"+c.name+" command")
+}
+
+// RegisterMapCommands registers :map, :noremap and :unmap with their mode
+// variants (:nmap, :inoremap, :cunmap, :map!, ...) and :verbose map.
+func RegisterMapCommands(api plugin.EditorAPI) {
+	for name, c := range mapCommands {
+		c := c
+		if err := api.RegisterCommand(name, func(args []string) error {
+			return c.run(api, args, false)
+		}); err != nil {
+			logger.Warnf("Failed to register ':%s' command: %v", name, err)
+		}
+	}
+
+	// :verbose map [lhs] - List mappings with where each was made
+	verboseCmdFunc := func(args []string) error {
+		if len(args) == 0 {
+			return fmt.Errorf("usage: :verbose map [lhs]")
+		}
+		c, ok := mapCommands[args[0]]
+		if !ok || c.unmap {
+			return fmt.Errorf(":verbose only lists mappings (:verbose map, :verbose nmap, ...)")
+		}
+		return c.run(api, args[1:], true)
+	}
+	if err := api.RegisterCommand("verbose", verboseCmdFunc); err != nil {
+		logger.Warnf("Failed to register ':verbose' command: %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bethropolis/tide/internal/config"
+	"github.com/bethropolis/tide/internal/logger"
+	"github.com/bethropolis/tide/internal/plugin"
+)
+
+// setOptions are the on/off settings :set changes, by Vim's names, each
+// with the editor setting it stands for.
+var setOptions = map[string]func(e *config.EditorConfig) *bool{
+	"expandtab":  func(e *config.EditorConfig) *bool { return &e.ExpandTab },
+	"number":     func(e *config.EditorConfig) *bool { return &e.LineNumbers },
+	"gutter":     func(e *config.EditorConfig) *bool { return &e.ShowGutter },
+	"statusline": func(e *config.EditorConfig) *bool { return &e.StatusLine },
+	"title":      func(e *config.EditorConfig) *bool { return &e.Title },
+}
+
+// setOption applies one :set argument as Vim reads it: "name" turns the
+// option on, "noname" off, "name!" or "invname" toggles it, and "name?"
+// shows it. It returns the option's name and value afterwards.
+func setOption(editorCfg *config.EditorConfig, arg string) (string, bool, error) {
+	name, change := arg, func(bool) bool { return true }
+	switch {
+	case strings.HasSuffix(arg, "?"):
+		name, change = strings.TrimSuffix(arg, "?"), func(on bool) bool { return on }
+	case strings.HasSuffix(arg, "!"):
+		name, change = strings.TrimSuffix(arg, "!"), func(on bool) bool { return !on }
+	case strings.HasPrefix(arg, "inv"):
+		name, change = strings.TrimPrefix(arg, "inv"), func(on bool) bool { return !on }
+	case strings.HasPrefix(arg, "no") && setOptions[arg] == nil:
+		name, change = strings.TrimPrefix(arg, "no"), func(bool) bool { return false }
+	}
+	option, ok := setOptions[name]
+	if !ok {
+		return "", false, fmt.Errorf("unknown option: %s", arg)
+	}
+	value := option(editorCfg)
+	*value = change(*value)
+	return name, *value, nil
+}
+
+// optionNames returns the :set options sorted, for completion.
+func optionNames() []string {
+	names := make([]string, 0, len(setOptions))
+	for name := range setOptions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RegisterSetCommands registers :set for the settings in setOptions.
+func RegisterSetCommands(api plugin.EditorAPI) {
+	// :set {option}... - Change or show settings for this session
+	setCmdFunc := func(args []string) error {
+		if len(args) == 0 {
+			return fmt.Errorf("usage: :set {option}[!|?] ... (options: %s)", strings.Join(optionNames(), ", "))
+		}
+		shown := make([]string, 0, len(args))
+		for _, arg := range args {
+			name, on, err := setOption(&config.Get().Editor, arg)
+			if err != nil {
+				return err
+			}
+			if !on {
+				name = "no" + name
+			}
+			shown = append(shown, name)
+		}
+		api.SetStatusMessage("%s", strings.Join(shown, " "))
+		return nil
+	}
+	if err := api.RegisterCommand("set", setCmdFunc); err != nil {
+		logger.Warnf("Failed to register ':set' command: %v", err)
+	}
+	api.SetCommandCompletion("set", optionNames)
+}
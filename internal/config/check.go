@@ -0,0 +1,23 @@
+package config
+
+import (
+	"os"
+
+	"github.com/BurntSushi/toml"
+)
+
+// CheckFile parses the config file at path and returns the keys it does
+// not know, which loading ignores. A missing file has no problems.
+func CheckFile(path string) (unknown []string, err error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, nil
+	}
+	md, err := toml.DecodeFile(path, &Config{})
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range md.Undecoded() {
+		unknown = append(unknown, key.String())
+	}
+	return unknown, nil
+}
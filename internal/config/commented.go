@@ -0,0 +1,115 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Commented renders c as a config.toml that explains every option, for the
+// first-run setup to write out. Logger filters, keybindings and plugin
+// settings are left as commented-out examples.
+func (c *Config) Commented() []byte {
+	var b strings.Builder
+	line := func(format string, args ...interface{}) {
+		fmt.Fprintf(&b, format+"\n", args...)
+	}
+	str := strconv.Quote
+
+	line("# Tide configuration. Delete a line to go back to its default.")
+	line("")
+	line("[logger]")
+	line("log_level = %s # debug, info, warn or error", str(c.Logger.LogLevel))
+	line("log_file_path = %s # \"\" for the default location in the state directory, \"-\" for stderr", str(c.Logger.LogFilePath))
+	line("# disabled_tags = [\"theme\", \"highlight\", \"event\"] # Hide noisy logs")
+	line("")
+
+	e := c.Editor
+	line("[editor]")
+	line("tab_width = %d", e.TabWidth)
+	line("scroll_off = %d # Lines kept visible above and below the cursor", e.ScrollOff)
+	line("leader_key = %s # Starts leader sequences in normal mode, e.g. <leader>w to save", str(e.LeaderKey))
+	line("system_clipboard = %t # Yank and paste through the system clipboard", e.SystemClipboard)
+	line("primary_selection = %t # Linux: mouse selections fill the primary selection, middle-click pastes it", e.PrimarySelection)
+	line("osc52_clipboard = %t # Also copy yanks to the terminal's clipboard with OSC 52, which reaches your own machine over SSH", e.OSC52Clipboard)
+	line("paste_reindent = %t # Reindent linewise pastes to the cursor line", e.PasteReindent)
+	line("open_dropped_files = %t # Offer to open file paths pasted by dragging files into the terminal", e.OpenDroppedFiles)
+	line("language = %s # Locale for messages, e.g. \"de\" (translations go in locales/<locale>.toml); \"\" follows $LANG", str(e.Language))
+	line("date_format = %s # Go time layout used by :date and <leader>D", str(e.DateFormat))
+	line("time_format = %s # Go time layout used by :time and <leader>T", str(e.TimeFormat))
+	line("path_style = %s # Status bar/tab paths: full, home, compact or name", str(e.PathStyle))
+	line("gutter = %s # Size the line-number gutter for the last line of the buffer (total) or of the screen (visible)", str(e.Gutter))
+	line("auto_indent = %s # New lines: off, keep the indentation of the line above, or smart to add a level after {, [, ( or Python's :", str(e.AutoIndent))
+	line("gutter_width = %d # Fixed gutter width in columns, including the space after the numbers; 0 sizes it by gutter", e.GutterWidth)
+	line("buffer_backend = %s # piece_table, or rope to keep editing fast in very large files", str(e.BufferBackend))
+	line("mmap_threshold = %d # Open files of at least this many megabytes read-only and memory-mapped, for huge logs; 0 never", e.MmapThreshold)
+	line("auto_view = %t # Remember the cursor and scroll position of each file between sessions", e.AutoView)
+	line("templates = %t # Fill new files from the templates directory", e.Templates)
+	line("table_view = %t # Open .csv/.tsv files as aligned columns (toggle with :table)", e.TableView)
+	line("table_pin_header = %t # Keep the header row visible while scrolling in table view", e.TablePinHeader)
+	line("virtual_edit = %t # Let the cursor move past line ends (toggle with :virtualedit)", e.VirtualEdit)
+	line("sticky_context = %t # Pin the enclosing function's first line at the top (toggle with :stickycontext)", e.StickyContext)
+	line("inline_blame = %t # Show git blame for the cursor line after its end (toggle with :blame)", e.InlineBlame)
+	line("wrap_scan = %t # Searches that reach the end of the buffer continue from the other end", e.WrapScan)
+	line("normalize_search = %t # Searches and :s match accented letters whether the file stores them composed (NFC) or decomposed (NFD)", e.NormalizeSearch)
+	line("project_index = %t # Index the words and symbols of the project in the background, for completion and :symbols", e.ProjectIndex)
+	line("expand_tab = %t # Tab and indenting insert spaces up to the next tab stop instead of tabs (:set expandtab, :retab)", e.ExpandTab)
+	line("line_numbers = %t # Number the lines in the gutter; off leaves one column for change signs (:set number)", e.LineNumbers)
+	line("show_gutter = %t # Draw the gutter of line numbers and change signs at all (:set gutter)", e.ShowGutter)
+	line("status_line = %t # Give a lone window a status line; split windows always have one (:set statusline)", e.StatusLine)
+	line("title = %t # Show the file, and a script being recorded or a command being typed, in the terminal's title (:set title)", e.Title)
+	line("content_change_interval = %d # Milliseconds between the content_changed events plugins get after edits; 0 sends none", e.ContentChangeInterval)
+	line("history_size = %d # Lines kept in each of the command, search and expression histories; 0 keeps none", e.HistorySize)
+	line("session_interval = %d # Seconds between snapshots of the open files and registers, offered back after a crash; 0 takes none", e.SessionInterval)
+	line("")
+
+	line("[ui]")
+	line("screen_reader = %t # Plain drawing and an announcement line for terminal screen readers", c.UI.ScreenReader)
+	line("reduce_motion = %t # No blinking cursors or other animation", c.UI.ReduceMotion)
+	line("")
+
+	line("# Cursor shape per mode: block, bar, underline (add \"blinking-\" to blink) or default.")
+	line("[editor.cursor_shape]")
+	for _, mode := range sortedKeys(e.CursorShape) {
+		line("%s = %s", mode, str(e.CursorShape[mode]))
+	}
+	line("")
+
+	line("# Language servers by LSP language id, started on first use. An entry")
+	line("# replaces the built-in default and an empty command disables it.")
+	line("# [lsp.go]")
+	line("# command = [\"gopls\"]")
+	line("# extensions = [\".go\"]")
+	line("")
+
+	line("# Keybindings per mode (normal, insert, command, find, visual, visual_line).")
+	line("# [keybindings.normal]")
+	line("# \"ctrl+s\" = \"save\"")
+	line("")
+
+	line("# Plugin settings, by plugin name.")
+	line("# [plugins.autosave]")
+	line("# enabled = true")
+	return []byte(b.String())
+}
+
+// WriteCommented writes c to path as a commented config file, creating the
+// directory if needed.
+func (c *Config) WriteCommented(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, c.Commented(), 0o644)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+)
+
+func TestCommentedRoundTrip(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.Editor.TabWidth = 2
+	cfg.Editor.LeaderKey = `\`
+	cfg.Editor.SystemClipboard = false
+
+	got := NewDefaultConfig()
+	file := &Config{}
+	md, err := toml.Decode(string(cfg.Commented()), file)
+	if err != nil {
+		t.Fatalf("Commented() is not valid TOML: %v", err)
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		t.Errorf("Commented() has unknown keys: %v", undecoded)
+	}
+	got.merge(file, md, true)
+	if !reflect.DeepEqual(got.Editor, cfg.Editor) {
+		t.Errorf("editor settings after reading back:\n got %+v\nwant %+v", got.Editor, cfg.Editor)
+	}
+	if got.Logger.LogLevel != cfg.Logger.LogLevel {
+		t.Errorf("log level = %q, want %q", got.Logger.LogLevel, cfg.Logger.LogLevel)
+	}
+}
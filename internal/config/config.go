@@ -7,25 +7,90 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"unicode/utf8"
 
 	"github.com/BurntSushi/toml"
 	"github.com/bethropolis/tide/internal/logger"
+	"github.com/bethropolis/tide/internal/paths"
 )
 
 // Config holds the application's combined configuration.
 type Config struct {
 	Logger    logger.Config                     `toml:"logger"`    // Embed logger config under [logger] table
 	Editor    EditorConfig                      `toml:"editor"`    // Editor-specific settings
+	UI        UIConfig                          `toml:"ui"`        // How the interface is presented
 	Keybinds  KeybindConfig                     `toml:"keybindings"` // User-defined keybindings under [keybindings]
 	Plugins   map[string]map[string]interface{} `toml:"plugins"`   // Holds plugin configurations
+	LSP       map[string]LSPServerConfig        `toml:"lsp"`       // Language servers by LSP language id
+}
+
+// LSPServerConfig describes how to start a language server and which files
+// it serves. Servers are started on first use.
+type LSPServerConfig struct {
+	Command    []string `toml:"command"`    // Program and arguments, e.g. ["gopls"]
+	Extensions []string `toml:"extensions"` // File extensions, with the leading dot
 }
 
 // EditorConfig holds editor-specific settings.
 type EditorConfig struct {
-	TabWidth        int  `toml:"tab_width"`
-	ScrollOff       int  `toml:"scroll_off"`
-	SystemClipboard bool `toml:"system_clipboard"`
-	StatusBarHeight int  `toml:"status_bar_height"`
+	TabWidth         int  `toml:"tab_width"`
+	ScrollOff        int  `toml:"scroll_off"`
+	SystemClipboard  bool `toml:"system_clipboard"`
+	PasteReindent    bool `toml:"paste_reindent"`     // Reindent linewise pastes to the cursor line
+	PrimarySelection bool `toml:"primary_selection"`  // Use the X11/Wayland primary selection (Linux)
+	OSC52Clipboard   bool `toml:"osc52_clipboard"`    // Also send yanks to the terminal's clipboard with OSC 52
+	OpenDroppedFiles bool `toml:"open_dropped_files"` // Offer to open pasted file paths (drag and drop)
+	AutoView         bool `toml:"auto_view"`          // Save views on close and restore them on open
+	Templates        bool `toml:"templates"`          // Pre-populate new files from templates/skeleton.<ext>
+	TableView        bool `toml:"table_view"`         // Open .csv/.tsv files as aligned columns
+	TablePinHeader   bool `toml:"table_pin_header"`   // Keep the header row visible in table view
+	VirtualEdit      bool `toml:"virtual_edit"`       // Let the cursor move past the end of lines
+	StickyContext    bool `toml:"sticky_context"`     // Pin the enclosing function's first line at the top
+	InlineBlame      bool `toml:"inline_blame"`       // Show git blame for the cursor line after its end
+	WrapScan         bool `toml:"wrap_scan"`          // Searches continue from the other end of the buffer
+	NormalizeSearch  bool `toml:"normalize_search"`   // Searches match accented text whether composed (NFC) or decomposed (NFD)
+	ProjectIndex     bool `toml:"project_index"`      // Index the project's words and symbols in the background
+	ExpandTab        bool `toml:"expand_tab"`         // Tab and indenting insert spaces instead of tabs
+	LineNumbers      bool `toml:"line_numbers"`       // Number the lines in the gutter
+	ShowGutter       bool `toml:"show_gutter"`        // Draw the gutter of line numbers and change signs
+	StatusLine       bool `toml:"status_line"`        // Give a lone window a status line; split windows always have one
+	Title            bool `toml:"title"`              // Show the file and the input state in the terminal's title
+	StatusBarHeight  int  `toml:"status_bar_height"`
+
+	ContentChangeInterval int `toml:"content_change_interval"` // Milliseconds between content_changed events; 0 sends none
+	HistorySize           int `toml:"history_size"`            // Lines kept per command/search/expression history; 0 keeps none
+	SessionInterval       int `toml:"session_interval"`        // Seconds between snapshots of the session for crash recovery; 0 takes none
+
+	DateFormat string `toml:"date_format"` // Go time layout for :date and <leader>D
+	TimeFormat string `toml:"time_format"` // Go time layout for :time and <leader>T
+	PathStyle  string `toml:"path_style"`  // full, home, compact or name (see utils.DisplayPath)
+	Gutter     string `toml:"gutter"`      // total or visible: size the gutter for the last line of the buffer or of the screen
+	AutoIndent string `toml:"auto_indent"` // off, keep or smart: how new lines are indented
+	LeaderKey  string `toml:"leader_key"`  // Single key starting leader sequences in normal mode
+	Language   string `toml:"language"`    // Message locale, e.g. "de" or "pt_BR"; "" follows $LANG
+
+	BufferBackend string `toml:"buffer_backend"` // piece_table or rope (see buffer.New)
+	GutterWidth   int    `toml:"gutter_width"`   // Fixed gutter width in columns; 0 sizes it by gutter
+	MmapThreshold int    `toml:"mmap_threshold"` // Megabytes from which files open read-only and memory-mapped; 0 never
+
+	// CursorShape maps a mode (normal, insert, visual, command) to a cursor
+	// shape: block, bar, underline, optionally prefixed with "blinking-", or
+	// default for the terminal's own cursor. Unlisted modes use normal's shape.
+	CursorShape map[string]string `toml:"cursor_shape"`
+}
+
+// UIConfig holds settings for how the interface is presented, independent
+// of editing behavior.
+type UIConfig struct {
+	// ScreenReader draws plainly for terminal screen readers: no
+	// decorations such as inline blame or the sticky context line, cues
+	// besides color for highlights and the active tab, and an announcement
+	// line at the bottom that states mode changes and messages.
+	ScreenReader bool `toml:"screen_reader"`
+	// ReduceMotion turns off animation: blinking cursor shapes become
+	// steady, and anything that would animate (spinners, smooth scrolling,
+	// plugin effects) shows a static indicator instead.
+	ReduceMotion bool `toml:"reduce_motion"`
 }
 
 // KeybindConfig holds user-defined keybindings per editor mode.
@@ -41,6 +106,9 @@ type KeybindConfig struct {
 
 var (
 	loadedConfig *Config
+	loadedFlags  *Flags // Re-applied over project settings, see LocalConfig.Apply
+	loadedPath   string // Config file LoadConfig read, or would have read
+	appliedLocal string // Project settings file layered on top, if any
 	loadOnce     sync.Once
 	loadErr      error
 )
@@ -54,30 +122,78 @@ func NewDefaultConfig() *Config {
 			// Filter lists default to empty/nil
 		},
 		Editor: EditorConfig{
-			TabWidth:        DefaultTabWidth,
-			ScrollOff:       DefaultScrollOff,
-			SystemClipboard: SystemClipboard,
-			StatusBarHeight: StatusBarHeight, // Initialize with the constant value
+			TabWidth:              DefaultTabWidth,
+			ScrollOff:             DefaultScrollOff,
+			SystemClipboard:       SystemClipboard,
+			WrapScan:              true,
+			NormalizeSearch:       true,
+			ProjectIndex:          true,
+			LineNumbers:           true,
+			ShowGutter:            true,
+			StatusLine:            true,
+			StatusBarHeight:       StatusBarHeight, // Initialize with the constant value
+			DateFormat:            DefaultDateFormat,
+			TimeFormat:            DefaultTimeFormat,
+			PathStyle:             DefaultPathStyle,
+			Gutter:                GutterTotal,
+			AutoIndent:            AutoIndentKeep,
+			BufferBackend:         DefaultBufferBackend,
+			ContentChangeInterval: DefaultContentChangeInterval,
+			HistorySize:           DefaultHistorySize,
+			SessionInterval:       DefaultSessionInterval,
+			LeaderKey:             string(DefaultLeaderKey),
+			CursorShape:           map[string]string{"normal": "block", "insert": "bar"},
 		},
 		Keybinds: KeybindConfig{},                                  // No default overrides — normal defaults come from input package
 		Plugins:  make(map[string]map[string]interface{}), // Initialize Plugins map
+		LSP: map[string]LSPServerConfig{
+			"go":         {Command: []string{"gopls"}, Extensions: []string{".go"}},
+			"rust":       {Command: []string{"rust-analyzer"}, Extensions: []string{".rs"}},
+			"python":     {Command: []string{"pylsp"}, Extensions: []string{".py", ".pyw"}},
+			"javascript": {Command: []string{"typescript-language-server", "--stdio"}, Extensions: []string{".js", ".mjs", ".cjs"}},
+			"typescript": {Command: []string{"typescript-language-server", "--stdio"}, Extensions: []string{".ts", ".tsx"}},
+		},
+	}
+}
+
+// Leader returns the leader key as a rune.
+func (e EditorConfig) Leader() rune {
+	r, _ := utf8.DecodeRuneInString(e.LeaderKey)
+	return r
+}
+
+// LSPServerFor returns the language id and server configuration for a file,
+// matched by extension. ok is false when no server handles it.
+func (c *Config) LSPServerFor(filePath string) (languageID string, server LSPServerConfig, ok bool) {
+	ext := strings.ToLower(filepath.Ext(filePath))
+	if ext == "" {
+		return "", LSPServerConfig{}, false
+	}
+	for id, srv := range c.LSP {
+		for _, e := range srv.Extensions {
+			if strings.ToLower(e) == ext && len(srv.Command) > 0 {
+				return id, srv, true
+			}
+		}
 	}
+	return "", LSPServerConfig{}, false
 }
 
 // loadFromFile attempts to load configuration from a TOML file.
-// It returns the loaded config and an error (nil if file not found or loaded successfully).
-func loadFromFile(filePath string, verbose bool) (*Config, error) {
+// It returns the loaded config, the metadata telling which keys the file
+// sets, and an error (nil if file not found or loaded successfully).
+func loadFromFile(filePath string, verbose bool) (*Config, toml.MetaData, error) {
 	cfg := &Config{} // Start empty, we'll merge later
 	_, err := os.Stat(filePath)
 	if os.IsNotExist(err) {
 		if verbose {
 			logger.Debugf("Config file not found: %s", filePath)
 		}
-		return cfg, nil // File not found is not an error here
+		return cfg, toml.MetaData{}, nil // File not found is not an error here
 	}
 	if err != nil {
 		// Other error stating the file
-		return cfg, fmt.Errorf("error checking config file '%s': %w", filePath, err)
+		return cfg, toml.MetaData{}, fmt.Errorf("error checking config file '%s': %w", filePath, err)
 	}
 
 	if verbose {
@@ -85,7 +201,7 @@ func loadFromFile(filePath string, verbose bool) (*Config, error) {
 	}
 	metadata, err := toml.DecodeFile(filePath, cfg)
 	if err != nil {
-		return cfg, fmt.Errorf("failed to parse config file '%s': %w", filePath, err)
+		return cfg, metadata, fmt.Errorf("failed to parse config file '%s': %w", filePath, err)
 	}
 	if len(metadata.Undecoded()) > 0 && verbose {
 		logger.Warnf("Config file '%s': Unrecognized keys: %v", filePath, metadata.Undecoded())
@@ -103,7 +219,7 @@ func loadFromFile(filePath string, verbose bool) (*Config, error) {
 		cfg.Plugins = lowerPlugins // Replace with lowercase keys
 	}
 
-	return cfg, nil
+	return cfg, metadata, nil
 }
 
 // validate checks config values and resets invalid ones to defaults.
@@ -117,6 +233,9 @@ func (c *Config) validate() {
 	if c.Editor.ScrollOff < 0 { // Allow 0
 		c.Editor.ScrollOff = defaults.Editor.ScrollOff
 	}
+	if utf8.RuneCountInString(c.Editor.LeaderKey) != 1 {
+		c.Editor.LeaderKey = defaults.Editor.LeaderKey
+	}
 
 	// Validate Logger config
 	if c.Logger.LogLevel == "" {
@@ -129,6 +248,154 @@ func (c *Config) validate() {
 	}
 }
 
+// merge applies the settings of a config file on top of c. The user's own
+// config (user is true) is applied as it always was. A project file only
+// overrides the options md says it defines, adds to the keybindings and
+// plugin settings, and cannot change logger settings.
+func (c *Config) merge(file *Config, md toml.MetaData, user bool) {
+	if user && file.Logger.LogLevel != "" {
+		c.Logger = file.Logger
+	}
+
+	// Options that default to something other than their zero value are
+	// only taken from files that set them
+	defined := func(key string) bool { return md.IsDefined("editor", key) }
+	set := func(key string) bool { return user || defined(key) }
+	if file.Editor.TabWidth > 0 {
+		c.Editor.TabWidth = file.Editor.TabWidth
+	}
+	if set("scroll_off") && file.Editor.ScrollOff >= 0 {
+		c.Editor.ScrollOff = file.Editor.ScrollOff
+	}
+	if defined("content_change_interval") && file.Editor.ContentChangeInterval >= 0 {
+		c.Editor.ContentChangeInterval = file.Editor.ContentChangeInterval
+	}
+	if defined("history_size") && file.Editor.HistorySize >= 0 {
+		c.Editor.HistorySize = file.Editor.HistorySize
+	}
+	if defined("session_interval") && file.Editor.SessionInterval >= 0 {
+		c.Editor.SessionInterval = file.Editor.SessionInterval
+	}
+	if file.Editor.DateFormat != "" {
+		c.Editor.DateFormat = file.Editor.DateFormat
+	}
+	if file.Editor.TimeFormat != "" {
+		c.Editor.TimeFormat = file.Editor.TimeFormat
+	}
+	if file.Editor.PathStyle != "" {
+		c.Editor.PathStyle = file.Editor.PathStyle
+	}
+	if file.Editor.Gutter != "" {
+		c.Editor.Gutter = file.Editor.Gutter
+	}
+	if file.Editor.AutoIndent != "" {
+		c.Editor.AutoIndent = file.Editor.AutoIndent
+	}
+	if defined("gutter_width") && file.Editor.GutterWidth >= 0 {
+		c.Editor.GutterWidth = file.Editor.GutterWidth
+	}
+	if file.Editor.LeaderKey != "" {
+		c.Editor.LeaderKey = file.Editor.LeaderKey
+	}
+	if file.Editor.Language != "" {
+		c.Editor.Language = file.Editor.Language
+	}
+	if file.Editor.BufferBackend != "" {
+		c.Editor.BufferBackend = file.Editor.BufferBackend
+	}
+	if defined("mmap_threshold") && file.Editor.MmapThreshold >= 0 {
+		c.Editor.MmapThreshold = file.Editor.MmapThreshold
+	}
+	bools := map[string]struct {
+		dst *bool
+		src bool
+	}{
+		"system_clipboard":   {&c.Editor.SystemClipboard, file.Editor.SystemClipboard},
+		"paste_reindent":     {&c.Editor.PasteReindent, file.Editor.PasteReindent},
+		"primary_selection":  {&c.Editor.PrimarySelection, file.Editor.PrimarySelection},
+		"osc52_clipboard":    {&c.Editor.OSC52Clipboard, file.Editor.OSC52Clipboard},
+		"open_dropped_files": {&c.Editor.OpenDroppedFiles, file.Editor.OpenDroppedFiles},
+		"auto_view":          {&c.Editor.AutoView, file.Editor.AutoView},
+		"templates":          {&c.Editor.Templates, file.Editor.Templates},
+		"table_view":         {&c.Editor.TableView, file.Editor.TableView},
+		"table_pin_header":   {&c.Editor.TablePinHeader, file.Editor.TablePinHeader},
+		"virtual_edit":       {&c.Editor.VirtualEdit, file.Editor.VirtualEdit},
+		"sticky_context":     {&c.Editor.StickyContext, file.Editor.StickyContext},
+		"inline_blame":       {&c.Editor.InlineBlame, file.Editor.InlineBlame},
+		"expand_tab":         {&c.Editor.ExpandTab, file.Editor.ExpandTab},
+		"title":              {&c.Editor.Title, file.Editor.Title},
+	}
+	for key, b := range bools {
+		if set(key) {
+			*b.dst = b.src
+		}
+	}
+	onByDefault := map[string]struct {
+		dst *bool
+		src bool
+	}{
+		"wrap_scan":        {&c.Editor.WrapScan, file.Editor.WrapScan},
+		"normalize_search": {&c.Editor.NormalizeSearch, file.Editor.NormalizeSearch},
+		"project_index":    {&c.Editor.ProjectIndex, file.Editor.ProjectIndex},
+		"line_numbers":     {&c.Editor.LineNumbers, file.Editor.LineNumbers},
+		"show_gutter":      {&c.Editor.ShowGutter, file.Editor.ShowGutter},
+		"status_line":      {&c.Editor.StatusLine, file.Editor.StatusLine},
+	}
+	for key, b := range onByDefault {
+		if defined(key) {
+			*b.dst = b.src
+		}
+	}
+	if md.IsDefined("ui", "screen_reader") {
+		c.UI.ScreenReader = file.UI.ScreenReader
+	}
+	if md.IsDefined("ui", "reduce_motion") {
+		c.UI.ReduceMotion = file.UI.ReduceMotion
+	}
+	for mode, shape := range file.Editor.CursorShape {
+		c.Editor.CursorShape[mode] = shape
+	}
+
+	// A server entry replaces the default for its language;
+	// an empty command disables it
+	for id, srv := range file.LSP {
+		c.LSP[id] = srv
+	}
+	if user {
+		return
+	}
+
+	// A project's keybindings and plugin settings are merged key by key
+	modes := []struct {
+		dst *map[string]string
+		src map[string]string
+	}{
+		{&c.Keybinds.Normal, file.Keybinds.Normal},
+		{&c.Keybinds.Insert, file.Keybinds.Insert},
+		{&c.Keybinds.Command, file.Keybinds.Command},
+		{&c.Keybinds.Find, file.Keybinds.Find},
+		{&c.Keybinds.Visual, file.Keybinds.Visual},
+		{&c.Keybinds.VisualLine, file.Keybinds.VisualLine},
+	}
+	for _, m := range modes {
+		for key, action := range m.src {
+			if *m.dst == nil {
+				*m.dst = make(map[string]string)
+			}
+			(*m.dst)[key] = action
+		}
+	}
+	for name, settings := range file.Plugins {
+		name = strings.ToLower(name)
+		if c.Plugins[name] == nil {
+			c.Plugins[name] = make(map[string]interface{})
+		}
+		for key, value := range settings {
+			c.Plugins[name][key] = value
+		}
+	}
+}
+
 // LoadConfig orchestrates loading defaults, file, applying flags, and validation.
 // It should be called only once, typically from main.
 func LoadConfig(configFilePath string, flags *Flags) (*Config, error) {
@@ -141,9 +408,9 @@ func LoadConfig(configFilePath string, flags *Flags) (*Config, error) {
 		// Determine effective config file path
 		effectivePath := configFilePath
 		if effectivePath == "" { // If flag not set, try default location
-			configDir, err := os.UserConfigDir()
+			path, err := paths.Config(DefaultConfigFileName)
 			if err == nil {
-				effectivePath = filepath.Join(configDir, AppName, DefaultConfigFileName)
+				effectivePath = path
 			} else {
 				// We can't log this yet as logger isn't initialized
 				effectivePath = "" // Cannot load default path
@@ -151,30 +418,20 @@ func LoadConfig(configFilePath string, flags *Flags) (*Config, error) {
 		}
 
 		// Load from file if path is determined
+		loadedPath = effectivePath
 		if effectivePath != "" {
-			fileCfg, err := loadFromFile(effectivePath, verbose)
+			fileCfg, md, err := loadFromFile(effectivePath, verbose)
 			if err != nil {
 				// Store error to return later (can't log yet)
 				loadErr = err
 			} else if fileCfg != nil {
 				// Merge file config settings that are set
-				if fileCfg.Logger.LogLevel != "" {
-					cfg.Logger = fileCfg.Logger
-				}
-
-				// Only apply Editor config if values are valid
-				if fileCfg.Editor.TabWidth > 0 {
-					cfg.Editor.TabWidth = fileCfg.Editor.TabWidth
-				}
-				if fileCfg.Editor.ScrollOff >= 0 {
-					cfg.Editor.ScrollOff = fileCfg.Editor.ScrollOff
-				}
-				// Apply boolean values from config file
-				cfg.Editor.SystemClipboard = fileCfg.Editor.SystemClipboard
+				cfg.merge(fileCfg, md, true)
 			}
 		}
 
 		// Apply flag overrides (if flags were parsed)
+		loadedFlags = flags
 		if flags != nil {
 			flags.ApplyOverrides(cfg, verbose) // Pass verbose flag here
 		}
@@ -192,6 +449,12 @@ func LoadConfig(configFilePath string, flags *Flags) (*Config, error) {
 
 
 
+// FilePath returns the config file in use: the -config flag's or the
+// default one, whether or not it exists.
+func FilePath() string {
+	return loadedPath
+}
+
 // Get returns the loaded application configuration. Panics if LoadConfig wasn't called.
 func Get() *Config {
 	if loadedConfig == nil {
@@ -19,6 +19,34 @@ func GutterWidth(lineCount, screenWidth int) int {
 	return gw
 }
 
+// ViewGutterWidth is GutterWidth for a view showing height lines from line
+// top (0-based), following the gutter settings: show_gutter = false hides
+// it, line_numbers = false narrows it to the column of change signs,
+// gutter_width fixes the width, and gutter = "visible" sizes it for the
+// last line on screen instead of the last line of the buffer.
+func ViewGutterWidth(lineCount, top, height, screenWidth int) int {
+	e := Get().Editor
+	if !e.ShowGutter {
+		return 0
+	}
+	if !e.LineNumbers {
+		if screenWidth <= 1 {
+			return 0
+		}
+		return 1
+	}
+	if e.GutterWidth > 0 {
+		if e.GutterWidth >= screenWidth {
+			return 0
+		}
+		return e.GutterWidth
+	}
+	if e.Gutter == GutterVisible && height > 0 {
+		lineCount = min(lineCount, top+height)
+	}
+	return GutterWidth(lineCount, screenWidth)
+}
+
 // Base application details
 const AppName = "tide"
 const ConfigDirName = "tide"
@@ -26,9 +54,42 @@ const ThemesDirName = "themes"
 const DefaultThemeFileName = "theme.toml"   // Active theme file
 const DefaultConfigFileName = "config.toml" // Main config file
 const DefaultLogFileName = "tide.log"
+const BackupsDirName = "backups"         // Unsaved buffers are written here on forced quit
+const ViewsDirName = "views"             // Per-file view state saved by :mkview
+const TemplatesDirName = "templates"     // Skeleton files for new buffers
+const HistoryDirName = "history"         // Command, search and expression histories
+const SessionsDirName = "sessions"       // Snapshots of running sessions, for restoring after a crash
+const LocalConfigFileName = ".tide.toml" // Project settings, looked up from the working directory upwards
+const TrustedFileName = "trusted.json"   // Project settings files the user agreed to load
 
 // UI Layout
-const StatusBarHeight = 1
+const StatusBarHeight = 1   // Each window's status line
+const CommandLineHeight = 1 // The command and message line at the bottom
+
+// Default layouts (Go time format) for the date/time insertion helpers
+const DefaultDateFormat = "2006-01-02"
+const DefaultTimeFormat = "15:04:05"
+
+// How the line-number gutter is sized: for the buffer's last line, or for
+// the last line on screen
+const (
+	GutterTotal   = "total"
+	GutterVisible = "visible"
+)
+
+// How a new line is indented: not at all, like the line it was split from,
+// or one level deeper after a line opening a block
+const (
+	AutoIndentOff   = "off"
+	AutoIndentKeep  = "keep"
+	AutoIndentSmart = "smart"
+)
+
+// How file paths are shown in the status bar and tab line
+const DefaultPathStyle = "compact"
+
+// Text buffer implementation; "rope" keeps edits fast in very large files
+const DefaultBufferBackend = "piece_table"
 
 // Input Behavior
 const DefaultLeaderKey = ','
@@ -37,7 +98,25 @@ const LeaderTimeout = 500 * time.Millisecond
 // Status Bar
 const MessageTimeout = 4 * time.Second
 
+// CursorHoldDelay is how long the cursor must rest before TypeCursorHold fires
+const CursorHoldDelay = 300 * time.Millisecond
+
+// DefaultContentChangeInterval is the least time, in milliseconds, between
+// two TypeContentChanged events for plugins
+const DefaultContentChangeInterval = 250
+
+// DefaultHistorySize is how many lines each of the command, search and
+// expression histories keeps
+const DefaultHistorySize = 200
+
+// DefaultSessionInterval is how often, in seconds, the open files and
+// registers are snapshotted for restoring a crashed session
+const DefaultSessionInterval = 30
+
 // These could be moved to NewDefaultConfig(), keeping here for now
 const DefaultTabWidth = 4
 const DefaultScrollOff = 3
 const SystemClipboard = true
+
+// Clipboard
+const ClipboardHistorySize = 20 // Number of yanks kept for the clipboard history picker
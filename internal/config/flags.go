@@ -27,6 +27,8 @@ type Flags struct {
 	DisableFiles    *string
 	DebugLog        *bool
 	SystemClipboard *bool
+	StartupTime     *string // File the startup phase timings are appended to
+	Script          *string // Script to replay without a terminal (see app.RunScript)
 }
 
 // DefineFlags sets up the command-line flags and associates them with the Flags struct fields.
@@ -45,6 +47,8 @@ func (f *Flags) DefineFlags() {
 	f.DisableFiles = flag.String("log-disable-files", "", "Comma-separated list of files to disable - Overrides config file")
 	f.DebugLog = flag.Bool("debug-log", false, "Enable verbose debug logging for the logger filtering system")
 	f.SystemClipboard = flag.Bool("system-clipboard", false, "Use system clipboard instead of internal clipboard")
+	f.StartupTime = flag.String("startuptime", "", "Append the time spent in each startup phase to this file")
+	f.Script = flag.String("script", "", "Replay this script (see :record) on the file without a terminal, then exit")
 }
 
 // ParseFlags parses the defined command-line flags into the Flags struct.
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+	"github.com/bethropolis/tide/internal/paths"
+)
+
+// LocalConfig is a project settings file (.tide.toml). It is layered over
+// the user's config, but only once the user has trusted its exact content,
+// since it can change which commands run as language servers.
+type LocalConfig struct {
+	Path string // Absolute path of the file
+	data []byte
+	hash string // sha256 of data, as recorded in the trust store
+}
+
+// FindLocalConfig looks for LocalConfigFileName in dir and its parents and
+// returns the first one found, or "" if there is none.
+func FindLocalConfig(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for {
+		path := filepath.Join(dir, LocalConfigFileName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// ReadLocalConfig reads the project settings file at path.
+func ReadLocalConfig(path string) (*LocalConfig, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(abs)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	return &LocalConfig{Path: abs, data: data, hash: hex.EncodeToString(sum[:])}, nil
+}
+
+// trustStorePath returns where trusted project files are recorded.
+func trustStorePath() (string, error) {
+	return paths.State(TrustedFileName)
+}
+
+// readTrustStore returns the trusted files, by path, with the hash of the
+// content that was trusted.
+func readTrustStore(path string) (map[string]string, error) {
+	trusted := make(map[string]string)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return trusted, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &trusted); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return trusted, nil
+}
+
+// Trusted reports whether the user trusted the file with its current
+// content. Editing a trusted file makes it untrusted again.
+func (l *LocalConfig) Trusted() bool {
+	storePath, err := trustStorePath()
+	if err != nil {
+		return false
+	}
+	trusted, err := readTrustStore(storePath)
+	return err == nil && trusted[l.Path] == l.hash
+}
+
+// Trust records that the user agreed to load the file as it is now.
+func (l *LocalConfig) Trust() error {
+	storePath, err := trustStorePath()
+	if err != nil {
+		return err
+	}
+	trusted, err := readTrustStore(storePath)
+	if err != nil {
+		return err
+	}
+	trusted[l.Path] = l.hash
+	data, err := json.MarshalIndent(trusted, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(storePath), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(storePath, data, 0o600)
+}
+
+// Apply layers the file's settings over the loaded configuration.
+// Command-line flags still take precedence, and logger settings are
+// ignored. LoadConfig must have run first.
+func (l *LocalConfig) Apply() error {
+	if loadedConfig == nil {
+		return errors.New("configuration not loaded")
+	}
+	file := &Config{}
+	md, err := toml.Decode(string(l.data), file)
+	if err != nil {
+		return fmt.Errorf("failed to parse '%s': %w", l.Path, err)
+	}
+	loadedConfig.merge(file, md, false)
+	if loadedFlags != nil {
+		loadedFlags.ApplyOverrides(loadedConfig, false)
+	}
+	loadedConfig.validate()
+	appliedLocal = l.Path
+	return nil
+}
+
+// AppliedLocalConfig returns the project settings file that was applied,
+// or "" if none was.
+func AppliedLocalConfig() string {
+	return appliedLocal
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+)
+
+func TestFindLocalConfig(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "a", "b")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if got := FindLocalConfig(sub); got != "" {
+		t.Fatalf("FindLocalConfig() = %q with no project file", got)
+	}
+	want := filepath.Join(root, "a", LocalConfigFileName)
+	if err := os.WriteFile(want, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := FindLocalConfig(sub); got != want {
+		t.Errorf("FindLocalConfig() = %q, want %q", got, want)
+	}
+}
+
+func TestMergeKeepsUnsetOptions(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.Keybinds.Normal = map[string]string{"ctrl+s": "Save"}
+	file := &Config{}
+	md, err := toml.Decode(`
+[logger]
+log_level = "debug"
+[editor]
+tab_width = 2
+table_view = true
+[keybindings.normal]
+"ctrl+q" = "Quit"
+[plugins.Formatter]
+on_save = true
+`, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.merge(file, md, false)
+
+	if cfg.Editor.TabWidth != 2 || !cfg.Editor.TableView {
+		t.Errorf("set options not applied: %+v", cfg.Editor)
+	}
+	if cfg.Editor.ScrollOff != DefaultScrollOff || !cfg.Editor.SystemClipboard {
+		t.Errorf("unset options changed: %+v", cfg.Editor)
+	}
+	if cfg.Logger.LogLevel != "info" {
+		t.Errorf("project file changed the log level to %q", cfg.Logger.LogLevel)
+	}
+	if cfg.Keybinds.Normal["ctrl+s"] != "Save" || cfg.Keybinds.Normal["ctrl+q"] != "Quit" {
+		t.Errorf("keybindings = %v", cfg.Keybinds.Normal)
+	}
+	if cfg.Plugins["formatter"]["on_save"] != true {
+		t.Errorf("plugins = %v", cfg.Plugins)
+	}
+}
+
+func TestTrust(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), LocalConfigFileName)
+	if err := os.WriteFile(path, []byte("[editor]\ntab_width = 2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	local, err := ReadLocalConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if local.Trusted() {
+		t.Fatal("new file is trusted")
+	}
+	if err := local.Trust(); err != nil {
+		t.Fatal(err)
+	}
+	if local, _ := ReadLocalConfig(path); !local.Trusted() {
+		t.Error("file not trusted after Trust()")
+	}
+
+	if err := os.WriteFile(path, []byte("[editor]\ntab_width = 8\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if local, _ := ReadLocalConfig(path); local.Trusted() {
+		t.Error("edited file still trusted")
+	}
+}
@@ -0,0 +1,24 @@
+package core
+
+// SetAppendedLines sets apart lines from up to and including to, just
+// appended to a followed file, replacing any set apart before.
+func (e *Editor) SetAppendedLines(from, to int) {
+	e.appendedFrom, e.appendedTo = from, to+1
+	e.MarkAllDirty()
+}
+
+// ClearAppendedLines stops setting apart the appended lines. Returns true if
+// there were any.
+func (e *Editor) ClearAppendedLines() bool {
+	if e.appendedTo == 0 {
+		return false
+	}
+	e.appendedFrom, e.appendedTo = 0, 0
+	e.MarkAllDirty()
+	return true
+}
+
+// IsAppendedLine reports whether line was just appended to a followed file.
+func (e *Editor) IsAppendedLine(line int) bool {
+	return line >= e.appendedFrom && line < e.appendedTo
+}
@@ -0,0 +1,236 @@
+// Package calc evaluates arithmetic expressions for :calc. It is a small
+// recursive-descent parser over float64; nothing in the expression can reach
+// the rest of the editor.
+package calc
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// functions are the single-argument functions available in expressions.
+var functions = map[string]func(float64) float64{
+	"abs":   math.Abs,
+	"sqrt":  math.Sqrt,
+	"floor": math.Floor,
+	"ceil":  math.Ceil,
+	"round": math.Round,
+	"ln":    math.Log,
+	"log":   math.Log10,
+	"sin":   math.Sin,
+	"cos":   math.Cos,
+	"tan":   math.Tan,
+}
+
+// constants are the named values available in expressions.
+var constants = map[string]float64{
+	"pi": math.Pi,
+	"e":  math.E,
+}
+
+// Eval evaluates expr. Supported: decimal and 0x/0b/0o integer literals,
+// + - * / % and ^ (power, right-associative), parentheses, unary minus,
+// the constants pi and e, and the functions in functions.
+func Eval(expr string) (float64, error) {
+	p := &parser{input: []rune(expr)}
+	v, err := p.parseExpr()
+	if err != nil {
+		return 0, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.input) {
+		return 0, fmt.Errorf("unexpected %q at position %d", p.input[p.pos], p.pos+1)
+	}
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, fmt.Errorf("result is not a finite number")
+	}
+	return v, nil
+}
+
+// Format renders v without a trailing ".0" for whole numbers.
+func Format(v float64) string {
+	if v == math.Trunc(v) && math.Abs(v) < 1e15 {
+		return strconv.FormatFloat(v, 'f', 0, 64)
+	}
+	return strconv.FormatFloat(v, 'g', 12, 64)
+}
+
+type parser struct {
+	input []rune
+	pos   int
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.input) && unicode.IsSpace(p.input[p.pos]) {
+		p.pos++
+	}
+}
+
+// peek returns the next non-space rune, or 0 at the end of input.
+func (p *parser) peek() rune {
+	p.skipSpace()
+	if p.pos >= len(p.input) {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+// expr := term (('+' | '-') term)*
+func (p *parser) parseExpr() (float64, error) {
+	v, err := p.parseTerm()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		op := p.peek()
+		if op != '+' && op != '-' {
+			return v, nil
+		}
+		p.pos++
+		rhs, err := p.parseTerm()
+		if err != nil {
+			return 0, err
+		}
+		if op == '+' {
+			v += rhs
+		} else {
+			v -= rhs
+		}
+	}
+}
+
+// term := unary (('*' | '/' | '%') unary)*
+func (p *parser) parseTerm() (float64, error) {
+	v, err := p.parseUnary()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		op := p.peek()
+		if op != '*' && op != '/' && op != '%' {
+			return v, nil
+		}
+		p.pos++
+		rhs, err := p.parseUnary()
+		if err != nil {
+			return 0, err
+		}
+		switch op {
+		case '*':
+			v *= rhs
+		case '/':
+			if rhs == 0 {
+				return 0, fmt.Errorf("division by zero")
+			}
+			v /= rhs
+		case '%':
+			if rhs == 0 {
+				return 0, fmt.Errorf("division by zero")
+			}
+			v = math.Mod(v, rhs)
+		}
+	}
+}
+
+// unary := ('-' | '+') unary | power
+func (p *parser) parseUnary() (float64, error) {
+	switch p.peek() {
+	case '-':
+		p.pos++
+		v, err := p.parseUnary()
+		return -v, err
+	case '+':
+		p.pos++
+		return p.parseUnary()
+	}
+	return p.parsePower()
+}
+
+// power := primary ('^' unary)?
+func (p *parser) parsePower() (float64, error) {
+	base, err := p.parsePrimary()
+	if err != nil {
+		return 0, err
+	}
+	if p.peek() != '^' {
+		return base, nil
+	}
+	p.pos++
+	exp, err := p.parseUnary()
+	if err != nil {
+		return 0, err
+	}
+	return math.Pow(base, exp), nil
+}
+
+// primary := number | name | name '(' expr ')' | '(' expr ')'
+func (p *parser) parsePrimary() (float64, error) {
+	r := p.peek()
+	switch {
+	case r == 0:
+		return 0, fmt.Errorf("unexpected end of expression")
+	case r == '(':
+		p.pos++
+		v, err := p.parseExpr()
+		if err != nil {
+			return 0, err
+		}
+		if p.peek() != ')' {
+			return 0, fmt.Errorf("missing ')'")
+		}
+		p.pos++
+		return v, nil
+	case unicode.IsDigit(r) || r == '.':
+		return p.parseNumber()
+	case unicode.IsLetter(r):
+		start := p.pos
+		for p.pos < len(p.input) && (unicode.IsLetter(p.input[p.pos]) || unicode.IsDigit(p.input[p.pos])) {
+			p.pos++
+		}
+		name := strings.ToLower(string(p.input[start:p.pos]))
+		if fn, ok := functions[name]; ok {
+			if p.peek() != '(' {
+				return 0, fmt.Errorf("%s needs an argument in parentheses", name)
+			}
+			arg, err := p.parsePrimary()
+			if err != nil {
+				return 0, err
+			}
+			return fn(arg), nil
+		}
+		if v, ok := constants[name]; ok {
+			return v, nil
+		}
+		return 0, fmt.Errorf("unknown name %q", name)
+	}
+	return 0, fmt.Errorf("unexpected %q at position %d", r, p.pos+1)
+}
+
+func (p *parser) parseNumber() (float64, error) {
+	start := p.pos
+	for p.pos < len(p.input) {
+		r := p.input[p.pos]
+		isExpSign := (r == '+' || r == '-') && (p.input[p.pos-1] == 'e' || p.input[p.pos-1] == 'E') &&
+			!strings.HasPrefix(strings.ToLower(string(p.input[start:p.pos])), "0x")
+		if !(unicode.IsDigit(r) || unicode.IsLetter(r) || r == '.' || r == '_' || isExpSign) {
+			break
+		}
+		p.pos++
+	}
+	literal := string(p.input[start:p.pos])
+	if len(literal) > 1 && literal[0] == '0' && strings.ContainsAny(literal[1:2], "xXbBoO") {
+		n, err := strconv.ParseInt(literal, 0, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number %q", literal)
+		}
+		return float64(n), nil
+	}
+	v, err := strconv.ParseFloat(literal, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", literal)
+	}
+	return v, nil
+}
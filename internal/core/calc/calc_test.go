@@ -0,0 +1,38 @@
+package calc
+
+import "testing"
+
+func TestEval(t *testing.T) {
+	tests := map[string]string{
+		"1 + 2 * 3":          "7",
+		"(1 + 2) * 3":        "9",
+		"-2 ^ 2":             "-4",
+		"2 ^ 3 ^ 2":          "512",
+		"10 / 4":             "2.5",
+		"7 % 3":              "1",
+		"0x10 + 0b11":        "19",
+		"sqrt(16) + abs(-1)": "5",
+		"round(pi * 100)":    "314",
+		"1e3 / 8":            "125",
+		"2.5e-1 * 4":         "1",
+		"0xe-1":              "13",
+	}
+	for expr, want := range tests {
+		v, err := Eval(expr)
+		if err != nil {
+			t.Errorf("Eval(%q) error: %v", expr, err)
+			continue
+		}
+		if got := Format(v); got != want {
+			t.Errorf("Eval(%q) = %s, want %s", expr, got, want)
+		}
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	for _, expr := range []string{"", "1 +", "(1", "1 / 0", "foo(2)", "2 3", "os.Exit(1)"} {
+		if _, err := Eval(expr); err == nil {
+			t.Errorf("Eval(%q) should fail", expr)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package clipboard
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bethropolis/tide/internal/types"
+)
+
+// block is a rectangle of text: columns startCol through endCol, counted
+// in runes, of lines startLine through endLine.
+type block struct {
+	startLine, endLine int
+	startCol, endCol   int
+}
+
+// selectedBlock returns the block-wise selection; ok is false when the
+// selection is not block-wise.
+func (m *Manager) selectedBlock() (b block, ok bool) {
+	if !m.editor.IsBlockwise() {
+		return b, false
+	}
+	b.startLine, b.endLine, b.startCol, b.endCol = m.editor.GetBlockRange()
+	return b, b.startLine >= 0
+}
+
+// span returns the part of line inside the block's columns, which is
+// shorter or empty for a line not reaching its right edge.
+func (b block) span(line []byte) (start, end types.Position) {
+	n := utf8.RuneCount(line)
+	return types.Position{Col: min(b.startCol, n)}, types.Position{Col: min(b.endCol+1, n)}
+}
+
+// blockText returns the text in b, a line of the block each, joined with
+// newlines.
+func (m *Manager) blockText(b block) []byte {
+	buf := m.editor.GetBuffer()
+	var out bytes.Buffer
+	for line := b.startLine; line <= b.endLine; line++ {
+		if line > b.startLine {
+			out.WriteByte('\n')
+		}
+		text, err := buf.Line(line)
+		if err != nil {
+			continue
+		}
+		start, end := b.span(text)
+		runes := []rune(string(text))
+		out.WriteString(string(runes[start.Col:end.Col]))
+	}
+	return out.Bytes()
+}
+
+// deleteBlock removes the text in b from each of its lines, recording each
+// deletion for undo.
+func (m *Manager) deleteBlock(b block) error {
+	buf := m.editor.GetBuffer()
+	for line := b.startLine; line <= b.endLine; line++ {
+		text, err := buf.Line(line)
+		if err != nil {
+			return err
+		}
+		start, end := b.span(text)
+		if start.Col == end.Col {
+			continue
+		}
+		start.Line, end.Line = line, line
+		if err := m.delete(start, end, []byte(buf.GetText(start, end))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CutBlock copies the block-wise selection to the clipboard and deletes it
+// from each of its lines as one undo step, leaving the cursor at its top
+// left corner.
+func (m *Manager) CutBlock() (bool, error) {
+	b, ok := m.selectedBlock()
+	if !ok {
+		return false, nil
+	}
+	if err := m.store(Entry{Text: m.blockText(b), Blockwise: true}); err != nil {
+		return false, err
+	}
+	m.editor.ClearSelection()
+
+	cursorBefore := m.editor.GetCursor()
+	if histMgr := m.editor.GetHistoryManager(); histMgr != nil && !histMgr.InTransaction() {
+		histMgr.BeginTransaction()
+		defer histMgr.EndTransaction(cursorBefore)
+	}
+	if err := m.deleteBlock(b); err != nil {
+		return false, err
+	}
+	m.editor.SetCursor(types.Position{Line: b.startLine, Col: b.startCol})
+	m.editor.ScrollToCursor()
+	return true, nil
+}
+
+// pasteBlock inserts the lines of a block-wise entry at column pos.Col of
+// pos.Line and the lines below, padding lines too short with spaces and
+// adding lines past the end of the buffer, and leaves the cursor at its
+// top left corner.
+func (m *Manager) pasteBlock(pos types.Position, text []byte) error {
+	buf := m.editor.GetBuffer()
+	for i, part := range bytes.Split(text, []byte("\n")) {
+		line := pos.Line + i
+		if line >= buf.LineCount() {
+			last := buf.LineCount() - 1
+			lastText, _ := buf.Line(last)
+			if _, err := m.insert(types.Position{Line: last, Col: utf8.RuneCount(lastText)}, []byte("\n")); err != nil {
+				return err
+			}
+		}
+		if len(part) == 0 {
+			continue
+		}
+		current, err := buf.Line(line)
+		if err != nil {
+			return err
+		}
+		at := types.Position{Line: line, Col: pos.Col}
+		if n := utf8.RuneCount(current); n < pos.Col {
+			at.Col = n
+			part = append([]byte(strings.Repeat(" ", pos.Col-n)), part...)
+		}
+		if _, err := m.insert(at, part); err != nil {
+			return err
+		}
+	}
+	m.finishPaste(pos)
+	return nil
+}
@@ -3,22 +3,37 @@ package clipboard
 import (
 	"bytes"
 	"fmt"
+	"strings"
+	"sync/atomic"
 	"unicode/utf8"
 
-	"github.com/atotto/clipboard"                       // <<< Import clipboard library
-	"github.com/bethropolis/tide/internal/buffer"       // Import the main buffer package
+	"github.com/atotto/clipboard"                 // <<< Import clipboard library
+	"github.com/bethropolis/tide/internal/buffer" // Import the main buffer package
+	"github.com/bethropolis/tide/internal/config"
 	"github.com/bethropolis/tide/internal/core/history" // Add history import
 	"github.com/bethropolis/tide/internal/event"
 	"github.com/bethropolis/tide/internal/logger"
 	"github.com/bethropolis/tide/internal/types"
+	"github.com/bethropolis/tide/internal/utils"
 	// Import for grapheme cluster support
 )
 
+// yankRing is the yank ring shared by all editors. With the internal
+// clipboard its newest entry is what Paste inserts.
+var yankRing = NewRing(config.ClipboardHistorySize)
+
+// History returns the shared yank ring.
+func History() *Ring {
+	return yankRing
+}
+
 // Manager handles clipboard operations
 type Manager struct {
 	editor             EditorInterface
-	internalClipboard  []byte // Renamed for clarity
-	useSystemClipboard bool   // <<< Add flag
+	useSystemClipboard bool // <<< Add flag
+	reindent           bool // Reindent linewise pastes to the cursor line
+	usePrimary         bool // Mirror selections to the X11/Wayland primary selection
+	useOSC52           bool // Also send yanks to the terminal's clipboard (see SetTerminalWriter)
 }
 
 // EditorInterface defines methods needed from editor
@@ -29,6 +44,8 @@ type EditorInterface interface {
 	GetSelection() (start types.Position, end types.Position, ok bool)
 	ClearSelection()
 	IsLinewise() bool
+	IsBlockwise() bool
+	GetBlockRange() (startLine, endLine, startCol, endCol int)
 	GetEventManager() *event.Manager
 	ScrollToCursor()
 	MoveCursor(deltaLine, deltaCol int)
@@ -36,11 +53,13 @@ type EditorInterface interface {
 }
 
 // NewManager creates a new clipboard manager, accepting the config flag
-func NewManager(editor EditorInterface, useSystem bool) *Manager { // <<< Add useSystem bool
+func NewManager(editor EditorInterface, useSystem, reindent, usePrimary, useOSC52 bool) *Manager { // <<< Add useSystem bool
 	return &Manager{
 		editor:             editor,
-		internalClipboard:  nil,
 		useSystemClipboard: useSystem, // <<< Store the flag
+		reindent:           reindent,
+		usePrimary:         usePrimary,
+		useOSC52:           useOSC52,
 	}
 }
 
@@ -50,30 +69,41 @@ func (m *Manager) getEffectiveSelection() (types.Position, types.Position, bool)
 	if !ok {
 		return start, end, false
 	}
-
 	if m.editor.IsLinewise() {
-		// Linewise selection: Start from column 0 of start line, end at end of end line (or beginning of next line)
-		start.Col = 0
-
-		// To include the newline of the last line, we select up to line + 1, col 0
-		end.Line++
-		end.Col = 0
-
-		buf := m.editor.GetBuffer()
-		if end.Line > buf.LineCount() {
-			end.Line = buf.LineCount()
-			// If it's the very last line without a newline, we might just select to the end of the line
-			if lastLine, err := buf.Line(buf.LineCount() - 1); err == nil {
-				end.Line = buf.LineCount() - 1
-				end.Col = utf8.RuneCount(lastLine)
-			}
-		}
+		start, end = m.lineRange(start.Line, end.Line)
 	}
 	return start, end, true
 }
 
+// lineRange returns the range covering lines startLine to endLine in full:
+// up to the start of the next line, or the end of the last line.
+func (m *Manager) lineRange(startLine, endLine int) (types.Position, types.Position) {
+	start := types.Position{Line: startLine}
+	end := types.Position{Line: endLine + 1}
+
+	buf := m.editor.GetBuffer()
+	if end.Line >= buf.LineCount() {
+		// The last line has no newline to take along
+		end.Line = buf.LineCount() - 1
+		if lastLine, err := buf.Line(end.Line); err == nil {
+			end.Col = utf8.RuneCount(lastLine)
+		}
+	}
+	return start, end
+}
+
 // YankSelection copies selected text to clipboard
 func (m *Manager) YankSelection() (bool, error) {
+	if b, ok := m.selectedBlock(); ok {
+		content := m.blockText(b)
+		if err := m.store(Entry{Text: content, Blockwise: true}); err != nil {
+			return false, err
+		}
+		m.writePrimary(content)
+		m.editor.ClearSelection()
+		m.editor.SetCursor(types.Position{Line: b.startLine, Col: b.startCol})
+		return true, nil
+	}
 	start, end, ok := m.getEffectiveSelection()
 	if !ok {
 		// No selection active
@@ -86,16 +116,10 @@ func (m *Manager) YankSelection() (bool, error) {
 		return false, fmt.Errorf("failed to extract selected text for yank: %w", err)
 	}
 
-	if m.useSystemClipboard {
-		err = clipboard.WriteAll(string(content))
-		if err != nil {
-			return false, fmt.Errorf("failed to write to system clipboard: %w", err)
-		}
-		logger.Debugf("ClipboardManager: Yanked %d bytes to system clipboard", len(content))
-	} else {
-		m.internalClipboard = content
-		logger.Debugf("ClipboardManager: Yanked %d bytes to internal clipboard", len(m.internalClipboard))
+	if err := m.store(Entry{Text: content, Linewise: m.editor.IsLinewise()}); err != nil {
+		return false, err
 	}
+	m.writePrimary(content)
 
 	// Clear selection after yank
 	m.editor.ClearSelection()
@@ -103,6 +127,76 @@ func (m *Manager) YankSelection() (bool, error) {
 	return true, nil
 }
 
+// store records entry in the yank ring and, when enabled, the system
+// clipboard. Linewise text always ends in a newline.
+func (m *Manager) store(entry Entry) error {
+	content := entry.Text
+	if entry.Linewise && !bytes.HasSuffix(content, []byte("\n")) {
+		content = append(content, '\n')
+	}
+	entry.Text = content
+	yankRing.PushEntry(entry)
+	if m.useOSC52 {
+		writeTerminal(content)
+	}
+	if m.useSystemClipboard {
+		if err := clipboard.WriteAll(string(content)); err != nil {
+			// The yank ring still has it, for pasting inside tide
+			systemUnavailable(err)
+			return nil
+		}
+		logger.Debugf("ClipboardManager: Stored %d bytes in system clipboard", len(content))
+	} else {
+		logger.Debugf("ClipboardManager: Stored %d bytes in internal clipboard", len(content))
+	}
+	return nil
+}
+
+// writePrimary copies text to the primary selection when enabled. Failures
+// are only logged: the primary selection is a convenience.
+func (m *Manager) writePrimary(text []byte) {
+	if !m.usePrimary || len(text) == 0 {
+		return
+	}
+	if err := WritePrimary(string(text)); err != nil {
+		logger.Warnf("ClipboardManager: %v", err)
+	}
+}
+
+// CopySelectionToPrimary copies the active selection to the primary
+// selection (when enabled) without touching the clipboard.
+func (m *Manager) CopySelectionToPrimary() {
+	if !m.usePrimary {
+		return
+	}
+	if b, ok := m.selectedBlock(); ok {
+		m.writePrimary(m.blockText(b))
+		return
+	}
+	start, end, ok := m.getEffectiveSelection()
+	if !ok {
+		return
+	}
+	content, err := m.extractTextFromRange(start, end)
+	if err != nil {
+		return
+	}
+	m.writePrimary(content)
+}
+
+// PastePrimary inserts the primary selection at the cursor, characterwise.
+func (m *Manager) PastePrimary() (bool, error) {
+	if !m.usePrimary {
+		return false, fmt.Errorf("primary selection is disabled (set primary_selection = true)")
+	}
+	content, err := ReadPrimary()
+	if err != nil {
+		return false, err
+	}
+	logger.Debugf("ClipboardManager: Read %d bytes from primary selection", len(content))
+	return m.PasteEntry(Entry{Text: []byte(content)}, false)
+}
+
 // extractTextFromRange extracts text from a given range in the buffer
 func (m *Manager) extractTextFromRange(start, end types.Position) ([]byte, error) {
 	return []byte(m.editor.GetBuffer().GetText(start, end)), nil
@@ -110,35 +204,69 @@ func (m *Manager) extractTextFromRange(start, end types.Position) ([]byte, error
 
 // CutSelection copies and deletes selected text to clipboard
 func (m *Manager) CutSelection() (bool, error) {
-	start, end, ok := m.getEffectiveSelection()
+	if m.editor.IsBlockwise() {
+		return m.CutBlock()
+	}
+	start, end, ok := m.editor.GetSelection()
 	if !ok {
 		return false, nil
 	}
+	linewise := m.editor.IsLinewise()
+	m.editor.ClearSelection()
+	if err := m.CutRange(start, end, linewise); err != nil {
+		return false, err
+	}
+	return true, nil
+}
 
-	// Extract the selected text from the buffer
+// YankRange copies the text from start up to end to the clipboard, or with
+// linewise, lines start.Line to end.Line in full. The selection is left
+// alone; normal mode operators use it to yank what a motion covers.
+func (m *Manager) YankRange(start, end types.Position, linewise bool) error {
+	if linewise {
+		start, end = m.lineRange(start.Line, end.Line)
+	}
 	content, err := m.extractTextFromRange(start, end)
 	if err != nil {
-		return false, fmt.Errorf("failed to extract selected text for cut: %w", err)
+		return fmt.Errorf("failed to extract text for yank: %w", err)
+	}
+	if err := m.store(Entry{Text: content, Linewise: linewise}); err != nil {
+		return err
 	}
+	m.writePrimary(content)
+	return nil
+}
 
-	if m.useSystemClipboard {
-		err = clipboard.WriteAll(string(content))
-		if err != nil {
-			return false, fmt.Errorf("failed to write to system clipboard: %w", err)
+// CutRange copies the range to the clipboard like YankRange and deletes
+// it as one undo step, leaving the cursor where it started. Cutting the
+// last lines of the buffer also takes the line break before them, so no
+// empty line is left behind.
+func (m *Manager) CutRange(start, end types.Position, linewise bool) error {
+	if err := m.YankRange(start, end, linewise); err != nil {
+		return err
+	}
+
+	buf := m.editor.GetBuffer()
+	cursorAfter := start
+	if linewise {
+		toLastLine := end.Line >= buf.LineCount()-1
+		start, end = m.lineRange(start.Line, end.Line)
+		cursorAfter = start
+		if toLastLine && start.Line > 0 {
+			prev, _ := buf.Line(start.Line - 1)
+			start = types.Position{Line: start.Line - 1, Col: utf8.RuneCount(prev)}
+			cursorAfter = types.Position{Line: start.Line}
 		}
-		logger.Debugf("ClipboardManager: Cut %d bytes to system clipboard", len(content))
-	} else {
-		m.internalClipboard = content
-		logger.Debugf("ClipboardManager: Cut %d bytes to internal clipboard", len(m.internalClipboard))
+	}
+	content, err := m.extractTextFromRange(start, end)
+	if err != nil {
+		return fmt.Errorf("failed to extract text for cut: %w", err)
 	}
 
 	cursorBefore := m.editor.GetCursor()
-
-	// Delete selection
-	m.editor.ClearSelection()
-	editInfo, err := m.editor.GetBuffer().Delete(start, end)
+	editInfo, err := buf.Delete(start, end)
 	if err != nil {
-		return false, fmt.Errorf("failed to delete selection during cut: %w", err)
+		return fmt.Errorf("failed to delete text during cut: %w", err)
 	}
 
 	// Record change for undo/redo
@@ -154,171 +282,269 @@ func (m *Manager) CutSelection() (bool, error) {
 		histMgr.RecordChange(change)
 	}
 
-	m.editor.SetCursor(start)
+	m.editor.SetCursor(cursorAfter)
 	m.editor.ScrollToCursor()
 
 	if eventMgr := m.editor.GetEventManager(); eventMgr != nil {
-		eventMgr.Dispatch(event.TypeBufferModified, event.BufferModifiedData{Edit: editInfo})
+		eventMgr.Dispatch(event.TypeBufferModified, event.BufferModifiedData{Edit: editInfo, FilePath: m.editor.GetBuffer().FilePath()})
 	}
-
-	return true, nil
+	return nil
 }
 
 // Paste inserts clipboard content at cursor. If after is true, it pastes after the cursor (like vim 'p').
 // If after is false, it pastes before the cursor (like vim 'P').
 func (m *Manager) Paste(after bool) (bool, error) {
-	var clipboardContent []byte
-	var err error
+	var entry Entry
 
 	if m.useSystemClipboard {
 		content, err := clipboard.ReadAll()
 		if err != nil {
-			return false, fmt.Errorf("failed to read from system clipboard: %w", err)
+			systemUnavailable(err)
+			return m.PasteEntry(yankRing.Latest(), after)
 		}
-		clipboardContent = []byte(content)
-		logger.Debugf("ClipboardManager: Read %d bytes from system clipboard", len(clipboardContent))
-	} else {
-		if len(m.internalClipboard) == 0 {
-			// Nothing in clipboard
-			return false, nil
+		// Text copied by other programs is linewise if it ends in a newline;
+		// our own last yank keeps the kind it was recorded with.
+		entry = Entry{Text: []byte(content), Linewise: strings.HasSuffix(content, "\n")}
+		if latest := yankRing.Latest(); string(latest.Text) == content {
+			entry = latest
 		}
-		clipboardContent = m.internalClipboard
-		logger.Debugf("ClipboardManager: Read %d bytes from internal clipboard", len(clipboardContent))
+		logger.Debugf("ClipboardManager: Read %d bytes from system clipboard", len(entry.Text))
+	} else {
+		entry = yankRing.Latest()
+		logger.Debugf("ClipboardManager: Read %d bytes from internal clipboard", len(entry.Text))
+	}
+
+	return m.PasteEntry(entry, after)
+}
+
+// systemWarned is set once the system clipboard has been found unreachable.
+var systemWarned atomic.Bool
+
+// systemUnavailable logs that the system clipboard could not be used, as a
+// warning the first time: without a helper tool (see SystemTool) every
+// yank and paste would fail the same way, and falls back to the yank ring.
+func systemUnavailable(err error) {
+	if systemWarned.CompareAndSwap(false, true) {
+		logger.Warnf("ClipboardManager: System clipboard unavailable, using the internal one: %v", err)
+		return
+	}
+	logger.Debugf("ClipboardManager: System clipboard unavailable: %v", err)
+}
+
+// PasteFromHistory makes entry (taken from the yank ring) the current
+// clipboard content again and pastes it.
+func (m *Manager) PasteFromHistory(entry Entry, after bool) (bool, error) {
+	if err := m.store(entry); err != nil {
+		return false, err
+	}
+	return m.PasteEntry(entry, after)
+}
+
+// PasteEntry inserts entry at the cursor as a single undo step, replacing the
+// selection if there is one. Linewise entries go on new lines below (after)
+// or above the cursor line and, with paste_reindent, take on that line's
+// indentation. Blockwise entries go in a column after or before the cursor,
+// and characterwise ones after or before it.
+func (m *Manager) PasteEntry(entry Entry, after bool) (bool, error) {
+	content := entry.Text
+	if len(content) == 0 {
+		// Nothing to paste
+		return false, nil
 	}
 
-	buffer := m.editor.GetBuffer()
-	eventMgr := m.editor.GetEventManager()
-	cursorBefore := m.editor.GetCursor() // Store cursor before change
-	var pastePos types.Position
-	var selectedText []byte
+	buf := m.editor.GetBuffer()
+	cursorBefore := m.editor.GetCursor()
+	if histMgr := m.editor.GetHistoryManager(); histMgr != nil && !histMgr.InTransaction() {
+		histMgr.BeginTransaction()
+		defer histMgr.EndTransaction(cursorBefore)
+	}
+
+	// Replace a block: delete it, then paste at its top left corner
+	if b, ok := m.selectedBlock(); ok {
+		m.editor.ClearSelection()
+		if err := m.deleteBlock(b); err != nil {
+			return false, fmt.Errorf("failed to delete selection before paste: %w", err)
+		}
+		cursorBefore = types.Position{Line: b.startLine, Col: b.startCol}
+		m.editor.SetCursor(cursorBefore)
+		after = false
+	}
 
-	// If there's a selection, delete it first
+	// Replace the selection: delete it, then paste where it started
 	if start, end, ok := m.editor.GetSelection(); ok {
-		// Extract the selected text for history
-		selectedText, err = m.extractTextFromRange(start, end)
+		selectedText, err := m.extractTextFromRange(start, end)
 		if err != nil {
 			return false, fmt.Errorf("failed to extract selected text: %w", err)
 		}
-
 		m.editor.ClearSelection()
-		editInfo, err := buffer.Delete(start, end)
-		if err != nil {
+		if err := m.delete(start, end, selectedText); err != nil {
 			return false, fmt.Errorf("failed to delete selection before paste: %w", err)
 		}
+		m.editor.SetCursor(start)
 
-		// Record the deletion in history
-		histMgr := m.editor.GetHistoryManager()
-		if histMgr != nil && len(selectedText) > 0 {
-			deleteChange := history.Change{
-				Type:          history.DeleteAction,
-				Text:          selectedText,
-				StartPosition: start,
-				EndPosition:   end,
-				CursorBefore:  cursorBefore,
-			}
-			histMgr.RecordChange(deleteChange)
+		endPos, err := m.insert(start, content)
+		if err != nil {
+			return false, fmt.Errorf("buffer insert failed during paste: %w", err)
 		}
+		m.finishPaste(charwiseCursor(start, endPos, content))
+		return true, nil
+	}
 
-		m.editor.SetCursor(start)
-		pastePos = start
-
-		if eventMgr != nil {
-			eventMgr.Dispatch(event.TypeBufferModified, event.BufferModifiedData{Edit: editInfo})
+	if entry.Blockwise {
+		pastePos := cursorBefore
+		if lineBytes, _ := buf.Line(pastePos.Line); after && pastePos.Col < utf8.RuneCount(lineBytes) {
+			pastePos.Col++
 		}
-	} else {
-		pastePos = m.editor.GetCursor()
-
-		// Handle Vim-style linewise paste
-		isLinewise := len(clipboardContent) > 0 && clipboardContent[len(clipboardContent)-1] == '\n'
-
-		if isLinewise {
-			if after {
-				// Paste below current line
-				pastePos.Line++
-				pastePos.Col = 0
-
-				// Make sure the line exists
-				if pastePos.Line >= buffer.LineCount() {
-					// We need to add a newline at the end of the file first
-					lastLineIdx := buffer.LineCount() - 1
-					if lastLine, err := buffer.Line(lastLineIdx); err == nil {
-						endPos := types.Position{Line: lastLineIdx, Col: utf8.RuneCount(lastLine)}
-						buffer.Insert(endPos, []byte("\n"))
-					}
-					pastePos.Line = buffer.LineCount() - 1
-				}
-			} else {
-				// Paste above current line
-				pastePos.Col = 0
-			}
-		} else {
-			if after {
-				// Paste after current character
-				lineBytes, _ := buffer.Line(pastePos.Line)
-				if pastePos.Col < utf8.RuneCount(lineBytes) {
-					pastePos.Col++
-				}
-			}
+		if err := m.pasteBlock(pastePos, content); err != nil {
+			return false, fmt.Errorf("buffer insert failed during paste: %w", err)
 		}
+		return true, nil
 	}
 
-	// Insert content
-	editInfo, err := buffer.Insert(pastePos, clipboardContent)
-	if err != nil {
-		return false, fmt.Errorf("buffer insert failed during paste: %w", err)
+	if !entry.Linewise {
+		pastePos := cursorBefore
+		if after {
+			// Paste after current character
+			lineBytes, _ := buf.Line(pastePos.Line)
+			if pastePos.Col < utf8.RuneCount(lineBytes) {
+				pastePos.Col++
+			}
+		}
+		endPos, err := m.insert(pastePos, content)
+		if err != nil {
+			return false, fmt.Errorf("buffer insert failed during paste: %w", err)
+		}
+		m.finishPaste(charwiseCursor(pastePos, endPos, content))
+		return true, nil
 	}
 
-	// Calculate new cursor position based on pasted content
-	numLines := bytes.Count(clipboardContent, []byte("\n"))
-	lastLine := clipboardContent
-	if numLines > 0 {
-		lastNewLineIndex := bytes.LastIndexByte(clipboardContent, '\n')
-		lastLine = clipboardContent[lastNewLineIndex+1:]
+	// Linewise paste
+	if !bytes.HasSuffix(content, []byte("\n")) {
+		content = append(append([]byte(nil), content...), '\n')
+	}
+	currentLine, _ := buf.Line(cursorBefore.Line)
+	if m.reindent {
+		content = utils.ReindentLines(content, utils.GetLeadingWhitespace(currentLine))
 	}
-	lastLineRuneCount := utf8.RuneCount(lastLine)
 
-	// Move cursor to the start of the pasted content if linewise, or end if character-wise
-	var newPos types.Position
-	isLinewise := len(clipboardContent) > 0 && clipboardContent[len(clipboardContent)-1] == '\n'
-	if isLinewise {
-		newPos = types.Position{Line: pastePos.Line, Col: 0} // Move to start of first pasted line
-	} else {
-		newPos = types.Position{
-			Line: pastePos.Line + numLines,
-			Col:  0,
+	pastePos := types.Position{Line: cursorBefore.Line, Col: 0}
+	if after {
+		pastePos.Line++
+		if pastePos.Line >= buf.LineCount() {
+			// Below the last line: start a new line at the end of the buffer
+			// rather than leaving an empty line after the pasted block.
+			lastLine, _ := buf.Line(buf.LineCount() - 1)
+			pastePos = types.Position{Line: buf.LineCount() - 1, Col: utf8.RuneCount(lastLine)}
+			content = append([]byte("\n"), content[:len(content)-1]...)
 		}
+	}
+	if _, err := m.insert(pastePos, content); err != nil {
+		return false, fmt.Errorf("buffer insert failed during paste: %w", err)
+	}
 
-		if numLines > 0 {
-			newPos.Col = lastLineRuneCount
-		} else {
-			// Check if we paste at the end, so we place the cursor on the last character instead of past it
-			newPos.Col = pastePos.Col + lastLineRuneCount - 1
-			if newPos.Col < 0 {
-				newPos.Col = 0
-			}
-		}
+	// Put the cursor on the first non-blank of the first pasted line
+	firstLine := bytes.TrimPrefix(content, []byte("\n"))
+	if i := bytes.IndexByte(firstLine, '\n'); i >= 0 {
+		firstLine = firstLine[:i]
+	}
+	line := cursorBefore.Line
+	if after {
+		line++
 	}
+	m.finishPaste(types.Position{Line: line, Col: utf8.RuneCount(utils.GetLeadingWhitespace(firstLine))})
+	return true, nil
+}
 
-	// Record the paste in history
-	histMgr := m.editor.GetHistoryManager()
-	if histMgr != nil {
-		pasteChange := history.Change{
-			Type:          history.InsertAction,
-			Text:          clipboardContent,
-			StartPosition: pastePos,
-			EndPosition:   newPos,
-			CursorBefore:  cursorBefore,
-		}
-		histMgr.RecordChange(pasteChange)
+// InsertText inserts text at the cursor as a single undo step and leaves the
+// cursor after it. Used for text pasted through the terminal, which bypasses
+// the clipboard.
+func (m *Manager) InsertText(text []byte) error {
+	if len(text) == 0 {
+		return nil
 	}
+	cursorBefore := m.editor.GetCursor()
+	if histMgr := m.editor.GetHistoryManager(); histMgr != nil && !histMgr.InTransaction() {
+		histMgr.BeginTransaction()
+		defer histMgr.EndTransaction(cursorBefore)
+	}
+	m.editor.ClearSelection()
 
-	m.editor.SetCursor(newPos)
+	end, err := m.insert(cursorBefore, text)
+	if err != nil {
+		return fmt.Errorf("buffer insert failed during paste: %w", err)
+	}
+	m.finishPaste(end)
+	return nil
+}
+
+// finishPaste moves the cursor after a paste.
+func (m *Manager) finishPaste(pos types.Position) {
+	m.editor.SetCursor(pos)
 	m.editor.ScrollToCursor()
+	logger.Debugf("ClipboardManager: Pasted, cursor at %v", pos)
+}
 
-	logger.Debugf("ClipboardManager: Pasted %d bytes", len(clipboardContent))
-	if eventMgr != nil {
-		eventMgr.Dispatch(event.TypeBufferModified, event.BufferModifiedData{Edit: editInfo})
+// charwiseCursor returns where the cursor goes after a characterwise paste
+// of content spanning [start, end): on its last character.
+func charwiseCursor(start, end types.Position, content []byte) types.Position {
+	if bytes.HasSuffix(content, []byte("\n")) {
+		return end
 	}
+	if end.Line == start.Line && end.Col > start.Col {
+		end.Col--
+	}
+	return end
+}
 
-	return true, nil
+// insert inserts text at pos, records it for undo and dispatches the edit.
+// Returns the position just after the inserted text.
+func (m *Manager) insert(pos types.Position, text []byte) (types.Position, error) {
+	editInfo, err := m.editor.GetBuffer().Insert(pos, text)
+	if err != nil {
+		return pos, err
+	}
+
+	end := pos
+	if n := bytes.Count(text, []byte("\n")); n > 0 {
+		end.Line += n
+		end.Col = utf8.RuneCount(text[bytes.LastIndexByte(text, '\n')+1:])
+	} else {
+		end.Col += utf8.RuneCount(text)
+	}
+
+	if histMgr := m.editor.GetHistoryManager(); histMgr != nil {
+		histMgr.RecordChange(history.Change{
+			Type:          history.InsertAction,
+			Text:          text,
+			StartPosition: pos,
+			EndPosition:   end,
+			CursorBefore:  m.editor.GetCursor(),
+		})
+	}
+	if eventMgr := m.editor.GetEventManager(); eventMgr != nil {
+		eventMgr.Dispatch(event.TypeBufferModified, event.BufferModifiedData{Edit: editInfo, FilePath: m.editor.GetBuffer().FilePath()})
+	}
+	return end, nil
+}
+
+// delete removes [start, end) (whose content is text), records it for undo
+// and dispatches the edit.
+func (m *Manager) delete(start, end types.Position, text []byte) error {
+	editInfo, err := m.editor.GetBuffer().Delete(start, end)
+	if err != nil {
+		return err
+	}
+	if histMgr := m.editor.GetHistoryManager(); histMgr != nil && len(text) > 0 {
+		histMgr.RecordChange(history.Change{
+			Type:          history.DeleteAction,
+			Text:          text,
+			StartPosition: start,
+			EndPosition:   end,
+			CursorBefore:  m.editor.GetCursor(),
+		})
+	}
+	if eventMgr := m.editor.GetEventManager(); eventMgr != nil {
+		eventMgr.Dispatch(event.TypeBufferModified, event.BufferModifiedData{Edit: editInfo, FilePath: m.editor.GetBuffer().FilePath()})
+	}
+	return nil
 }
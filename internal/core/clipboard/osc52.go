@@ -0,0 +1,43 @@
+package clipboard
+
+import (
+	"sync"
+
+	"github.com/bethropolis/tide/internal/logger"
+)
+
+// MaxOSC52Size is the most text sent with OSC 52. Terminals drop longer
+// sequences (tmux and hterm stop at about 100 KB of base64), so larger
+// yanks only reach the other clipboards.
+const MaxOSC52Size = 74994
+
+var terminal struct {
+	sync.Mutex
+	write func([]byte)
+}
+
+// SetTerminalWriter sets the function that copies text to the terminal's
+// clipboard with an OSC 52 escape sequence, such as tcell's
+// Screen.SetClipboard. The terminal forwards it to the clipboard of the
+// machine it runs on, which is what makes yanking work over SSH.
+func SetTerminalWriter(write func([]byte)) {
+	terminal.Lock()
+	defer terminal.Unlock()
+	terminal.write = write
+}
+
+// writeTerminal sends text to the terminal's clipboard, if a writer is set
+// and the text is small enough.
+func writeTerminal(text []byte) {
+	terminal.Lock()
+	defer terminal.Unlock()
+	switch {
+	case terminal.write == nil || len(text) == 0:
+		return
+	case len(text) > MaxOSC52Size:
+		logger.Debugf("ClipboardManager: %d bytes is too much for OSC 52, not sent to the terminal", len(text))
+		return
+	}
+	terminal.write(text)
+	logger.Debugf("ClipboardManager: Sent %d bytes to the terminal clipboard", len(text))
+}
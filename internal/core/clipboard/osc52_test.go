@@ -0,0 +1,28 @@
+package clipboard
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/bethropolis/tide/internal/logger"
+)
+
+func TestMain(m *testing.M) {
+	logger.Init(logger.Config{LogLevel: "error", LogFilePath: os.DevNull})
+	os.Exit(m.Run())
+}
+
+func TestWriteTerminal(t *testing.T) {
+	var sent []string
+	SetTerminalWriter(func(b []byte) { sent = append(sent, string(b)) })
+	defer SetTerminalWriter(nil)
+
+	writeTerminal([]byte("yanked\n"))
+	writeTerminal(nil)
+	writeTerminal([]byte(strings.Repeat("x", MaxOSC52Size+1)))
+
+	if len(sent) != 1 || sent[0] != "yanked\n" {
+		t.Errorf("sent = %q, want only the small yank", sent)
+	}
+}
@@ -0,0 +1,100 @@
+package clipboard
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// primaryCommands returns the paste and copy command lines for the X11 /
+// Wayland primary selection, using the first helper tool that is installed.
+func primaryCommands() (paste, copy []string, err error) {
+	switch runtime.GOOS {
+	case "linux", "freebsd", "openbsd", "netbsd", "dragonfly":
+	default:
+		return nil, nil, fmt.Errorf("primary selection is not supported on %s", runtime.GOOS)
+	}
+
+	type tool struct{ paste, copy []string }
+	var tools []tool
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		tools = append(tools, tool{
+			[]string{"wl-paste", "--primary", "--no-newline"},
+			[]string{"wl-copy", "--primary"},
+		})
+	}
+	tools = append(tools,
+		tool{[]string{"xclip", "-out", "-selection", "primary"}, []string{"xclip", "-in", "-selection", "primary"}},
+		tool{[]string{"xsel", "--output", "--primary"}, []string{"xsel", "--input", "--primary"}},
+	)
+
+	for _, t := range tools {
+		if _, err := exec.LookPath(t.paste[0]); err != nil {
+			continue
+		}
+		if _, err := exec.LookPath(t.copy[0]); err != nil {
+			continue
+		}
+		return t.paste, t.copy, nil
+	}
+	return nil, nil, fmt.Errorf("no primary selection tool found (install wl-clipboard, xclip or xsel)")
+}
+
+// ReadPrimary returns the contents of the primary selection.
+func ReadPrimary() (string, error) {
+	pasteCmd, _, err := primaryCommands()
+	if err != nil {
+		return "", err
+	}
+	out, err := exec.Command(pasteCmd[0], pasteCmd[1:]...).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read primary selection: %w", err)
+	}
+	return string(out), nil
+}
+
+// WritePrimary replaces the contents of the primary selection.
+func WritePrimary(text string) error {
+	_, copyCmd, err := primaryCommands()
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(copyCmd[0], copyCmd[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to write primary selection: %w", err)
+	}
+	return nil
+}
+
+// PrimaryTool returns the helper program used for the primary selection.
+func PrimaryTool() (string, error) {
+	pasteCmd, _, err := primaryCommands()
+	if err != nil {
+		return "", err
+	}
+	return pasteCmd[0], nil
+}
+
+// SystemTool returns the helper program the system clipboard goes through,
+// or "" where the operating system provides the clipboard directly.
+func SystemTool() (string, error) {
+	switch runtime.GOOS {
+	case "linux", "freebsd", "openbsd", "netbsd", "dragonfly":
+	default:
+		return "", nil
+	}
+	var tools []string
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		tools = append(tools, "wl-copy")
+	}
+	tools = append(tools, "xclip", "xsel", "termux-clipboard-set")
+	for _, t := range tools {
+		if _, err := exec.LookPath(t); err == nil {
+			return t, nil
+		}
+	}
+	return "", fmt.Errorf("no clipboard tool found (install wl-clipboard, xclip or xsel)")
+}
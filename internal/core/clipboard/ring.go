@@ -0,0 +1,72 @@
+package clipboard
+
+import "sync"
+
+// Entry is one yank: its text and whether it was taken linewise (whole
+// lines, pasted on lines of its own), blockwise (a rectangle, its rows
+// separated by newlines and pasted in a column) or characterwise.
+type Entry struct {
+	Text      []byte
+	Linewise  bool
+	Blockwise bool
+}
+
+// Ring keeps the most recent yanks, newest first. It is shared by every
+// editor so text yanked in one buffer can be pasted in another.
+type Ring struct {
+	mu      sync.Mutex
+	entries []Entry
+	size    int
+}
+
+// NewRing creates a ring that keeps at most size entries.
+func NewRing(size int) *Ring {
+	if size < 1 {
+		size = 1
+	}
+	return &Ring{size: size}
+}
+
+// Push records text as the newest entry. An identical existing entry is
+// moved to the front instead of being stored twice.
+func (r *Ring) Push(text []byte, linewise bool) {
+	r.PushEntry(Entry{Text: text, Linewise: linewise})
+}
+
+// PushEntry records entry as the newest, like Push.
+func (r *Ring) PushEntry(entry Entry) {
+	if len(entry.Text) == 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry.Text = append([]byte(nil), entry.Text...)
+	for i, existing := range r.entries {
+		if string(existing.Text) == string(entry.Text) {
+			r.entries = append(r.entries[:i], r.entries[i+1:]...)
+			break
+		}
+	}
+	r.entries = append([]Entry{entry}, r.entries...)
+	if len(r.entries) > r.size {
+		r.entries = r.entries[:r.size]
+	}
+}
+
+// Latest returns the newest entry, or a zero Entry if the ring is empty.
+func (r *Ring) Latest() Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.entries) == 0 {
+		return Entry{}
+	}
+	return r.entries[0]
+}
+
+// Entries returns a copy of the entries, newest first.
+func (r *Ring) Entries() []Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Entry(nil), r.entries...)
+}
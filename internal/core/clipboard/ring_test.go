@@ -0,0 +1,46 @@
+package clipboard
+
+import "testing"
+
+func TestRing_PushOrderAndLimit(t *testing.T) {
+	r := NewRing(3)
+	for _, s := range []string{"a", "b", "c", "d"} {
+		r.Push([]byte(s), false)
+	}
+
+	got := r.Entries()
+	want := []string{"d", "c", "b"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d entries, got %d", len(want), len(got))
+	}
+	for i := range want {
+		if string(got[i].Text) != want[i] {
+			t.Errorf("entry %d: expected %q, got %q", i, want[i], got[i].Text)
+		}
+	}
+}
+
+func TestRing_PushDuplicateMovesToFront(t *testing.T) {
+	r := NewRing(5)
+	r.Push([]byte("a"), false)
+	r.Push([]byte("b"), false)
+	r.Push([]byte("a"), true)
+	r.Push(nil, false) // ignored
+
+	got := r.Entries()
+	if len(got) != 2 || string(got[0].Text) != "a" || string(got[1].Text) != "b" {
+		t.Fatalf("expected [a b], got %d entries", len(got))
+	}
+	if latest := r.Latest(); string(latest.Text) != "a" || !latest.Linewise {
+		t.Errorf("expected latest linewise 'a', got %q (linewise %v)", latest.Text, latest.Linewise)
+	}
+}
+
+func TestRing_PushEntryKeepsBlockwise(t *testing.T) {
+	r := NewRing(5)
+	r.PushEntry(Entry{Text: []byte("ab\ncd"), Blockwise: true})
+
+	if latest := r.Latest(); string(latest.Text) != "ab\ncd" || !latest.Blockwise || latest.Linewise {
+		t.Errorf("expected blockwise 'ab\\ncd', got %q (linewise %v, blockwise %v)", latest.Text, latest.Linewise, latest.Blockwise)
+	}
+}
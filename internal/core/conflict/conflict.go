@@ -0,0 +1,151 @@
+// Package conflict finds the regions git leaves in a file when a merge
+// conflicts, and resolves them by keeping one side or both:
+//
+//	<<<<<<< ours
+//	our lines
+//	||||||| base (only with merge.conflictStyle=diff3)
+//	common ancestor's lines
+//	=======
+//	their lines
+//	>>>>>>> theirs
+package conflict
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Region is one conflict, by the line indices of its markers. Base is -1
+// when the conflict has no common ancestor section.
+type Region struct {
+	Start     int // <<<<<<<
+	Base      int // |||||||
+	Separator int // =======
+	End       int // >>>>>>>
+}
+
+// Side is the part of a line within a conflict.
+type Side int
+
+const (
+	SideNone   Side = iota // Not in a conflict
+	SideMarker             // One of the marker lines
+	SideOurs               // Between <<<<<<< and ||||||| or =======
+	SideBase               // Between ||||||| and =======
+	SideTheirs             // Between ======= and >>>>>>>
+)
+
+// Choice says which sides of a conflict to keep.
+type Choice int
+
+const (
+	Ours Choice = iota
+	Theirs
+	Both // Ours followed by theirs
+)
+
+// ParseChoice reads "ours", "theirs" or "both".
+func ParseChoice(s string) (Choice, error) {
+	switch s {
+	case "ours":
+		return Ours, nil
+	case "theirs":
+		return Theirs, nil
+	case "both":
+		return Both, nil
+	}
+	return 0, fmt.Errorf("unknown side %q (ours, theirs or both)", s)
+}
+
+// isMarker reports whether line is a conflict marker made of seven c
+// characters, followed by nothing or by a space and a label.
+func isMarker(line []byte, c byte, label bool) bool {
+	line = bytes.TrimRight(line, "\r")
+	if len(line) < 7 || !bytes.Equal(line[:7], bytes.Repeat([]byte{c}, 7)) {
+		return false
+	}
+	if len(line) == 7 {
+		return true
+	}
+	return label && line[7] == ' '
+}
+
+// Find returns the complete conflict regions in lines, in order.
+// Unterminated or malformed regions are skipped.
+func Find(lines [][]byte) []Region {
+	var regions []Region
+	for i := 0; i < len(lines); i++ {
+		if !isMarker(lines[i], '<', true) {
+			continue
+		}
+		if r, ok := regionAt(lines, i); ok {
+			regions = append(regions, r)
+			i = r.End
+		}
+	}
+	return regions
+}
+
+// regionAt reads the conflict whose <<<<<<< marker is on line start.
+func regionAt(lines [][]byte, start int) (Region, bool) {
+	r := Region{Start: start, Base: -1, Separator: -1}
+	for j := start + 1; j < len(lines); j++ {
+		switch {
+		case isMarker(lines[j], '<', true):
+			return Region{}, false // Another conflict starts before this one ends
+		case r.Separator < 0 && r.Base < 0 && isMarker(lines[j], '|', true):
+			r.Base = j
+		case r.Separator < 0 && isMarker(lines[j], '=', false):
+			r.Separator = j
+		case r.Separator >= 0 && isMarker(lines[j], '>', true):
+			r.End = j
+			return r, true
+		}
+	}
+	return Region{}, false
+}
+
+// At returns the region containing line.
+func At(regions []Region, line int) (Region, bool) {
+	for _, r := range regions {
+		if line >= r.Start && line <= r.End {
+			return r, true
+		}
+	}
+	return Region{}, false
+}
+
+// SideOf tells which part of r the line is in.
+func (r Region) SideOf(line int) Side {
+	switch {
+	case line < r.Start || line > r.End:
+		return SideNone
+	case line == r.Start || line == r.Base || line == r.Separator || line == r.End:
+		return SideMarker
+	case line > r.Separator:
+		return SideTheirs
+	case r.Base >= 0 && line > r.Base:
+		return SideBase
+	default:
+		return SideOurs
+	}
+}
+
+// Resolve returns the lines that replace the whole region, markers
+// included, when keeping choice.
+func (r Region) Resolve(lines [][]byte, choice Choice) [][]byte {
+	oursEnd := r.Separator
+	if r.Base >= 0 {
+		oursEnd = r.Base
+	}
+	ours := lines[r.Start+1 : oursEnd]
+	theirs := lines[r.Separator+1 : r.End]
+	switch choice {
+	case Ours:
+		return ours
+	case Theirs:
+		return theirs
+	default:
+		return append(append([][]byte{}, ours...), theirs...)
+	}
+}
@@ -0,0 +1,69 @@
+package conflict
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func split(s string) [][]byte {
+	return bytes.Split([]byte(s), []byte("\n"))
+}
+
+func TestFind(t *testing.T) {
+	lines := split(`a
+<<<<<<< HEAD
+ours
+=======
+theirs
+>>>>>>> topic
+b
+<<<<<<< unterminated
+<<<<<<< ours
+x
+||||||| base
+y
+=======
+z
+>>>>>>> theirs
+=======`)
+	got := Find(lines)
+	want := []Region{
+		{Start: 1, Base: -1, Separator: 3, End: 5},
+		{Start: 8, Base: 10, Separator: 12, End: 14},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Find() = %+v, want %+v", got, want)
+	}
+
+	sides := []Side{SideNone, SideMarker, SideOurs, SideMarker, SideBase, SideMarker, SideTheirs, SideMarker, SideNone}
+	for i, want := range sides {
+		if got := got[1].SideOf(7 + i); got != want {
+			t.Errorf("SideOf(%d) = %v, want %v", 7+i, got, want)
+		}
+	}
+	if r, ok := At(got, 4); !ok || r.Start != 1 {
+		t.Errorf("At(4) = %+v, %v", r, ok)
+	}
+	if _, ok := At(got, 6); ok {
+		t.Errorf("At(6) found a conflict outside the regions")
+	}
+}
+
+func TestResolve(t *testing.T) {
+	lines := split("<<<<<<< HEAD\no1\no2\n||||||| base\nb\n=======\nt\n>>>>>>> topic")
+	r := Find(lines)[0]
+	tests := []struct {
+		choice Choice
+		want   string
+	}{
+		{Ours, "o1\no2"},
+		{Theirs, "t"},
+		{Both, "o1\no2\nt"},
+	}
+	for _, tt := range tests {
+		if got := string(bytes.Join(r.Resolve(lines, tt.choice), []byte("\n"))); got != tt.want {
+			t.Errorf("Resolve(%v) = %q, want %q", tt.choice, got, tt.want)
+		}
+	}
+}
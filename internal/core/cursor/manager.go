@@ -1,8 +1,12 @@
 package cursor
 
 import (
+	"slices"
+	"unicode/utf8"
+
 	"github.com/bethropolis/tide/internal/buffer"
 	"github.com/bethropolis/tide/internal/config"
+	"github.com/bethropolis/tide/internal/core/table"
 	"github.com/bethropolis/tide/internal/logger"
 	"github.com/bethropolis/tide/internal/types"
 )
@@ -15,6 +19,9 @@ type Editor interface {
 	// repainting. The cursor manager calls this whenever the viewport
 	// scrolls so that delta rendering does not skip newly revealed rows.
 	MarkAllDirty()
+	// TableLayout returns the CSV/TSV column layout, or nil when the buffer
+	// is drawn as plain text.
+	TableLayout() *table.Layout
 }
 
 // Manager handles cursor positioning and viewport management
@@ -25,6 +32,8 @@ type Manager struct {
 	viewportLeft int
 	viewWidth    int
 	viewHeight   int
+
+	extra []types.Position // Further cursors edited along with this one (multiple cursors)
 }
 
 // NewManager creates a new cursor manager
@@ -48,6 +57,46 @@ func (m *Manager) GetViewport() (int, int) {
 	return m.viewportTop, m.viewportLeft
 }
 
+// SetViewport sets the viewport top line and left column, clamped to the buffer.
+// Call ScrollToCursor afterwards to keep the cursor visible.
+func (m *Manager) SetViewport(top, left int) {
+	if lineCount := m.editor.GetBuffer().LineCount(); top >= lineCount {
+		top = lineCount - 1
+	}
+	if top < 0 {
+		top = 0
+	}
+	if left < 0 {
+		left = 0
+	}
+	if top != m.viewportTop || left != m.viewportLeft {
+		m.viewportTop, m.viewportLeft = top, left
+		m.editor.MarkAllDirty()
+	}
+}
+
+// ScrollLines moves the viewport down delta lines (up when negative)
+// without moving the cursor, as the mouse wheel does. The cursor only
+// moves when it would leave the view, and then just far enough to stay
+// inside the scroll-off margin.
+func (m *Manager) ScrollLines(delta int) {
+	m.SetViewport(m.viewportTop+delta, m.viewportLeft)
+	if m.viewHeight <= 0 {
+		return
+	}
+
+	scrollOff := max(min(m.editor.ScrollOff(), (m.viewHeight-1)/2), 0)
+	pos := m.position
+	if top := m.viewportTop + scrollOff; pos.Line < top && m.viewportTop > 0 {
+		pos.Line = top
+	} else if bottom := m.viewportTop + m.viewHeight - 1 - scrollOff; pos.Line > bottom {
+		pos.Line = bottom
+	}
+	if pos.Line != m.position.Line {
+		m.SetPosition(pos)
+	}
+}
+
 // GetPosition returns the current cursor position
 func (m *Manager) GetPosition() types.Position {
 	return m.position
@@ -84,9 +133,10 @@ func (m *Manager) SetPosition(pos types.Position) {
 	// Convert []byte to string for processing
 	line := string(lineBytes)
 
-	// Get visual line length (considering tabs)
+	// Get visual line length (considering tabs). With virtual_edit the
+	// cursor may rest past the end of the line.
 	visualLen := GetVisualLineLength(line, config.Get().Editor.TabWidth)
-	if pos.Col > visualLen {
+	if pos.Col > visualLen && !config.Get().Editor.VirtualEdit {
 		pos.Col = visualLen
 	}
 
@@ -94,6 +144,23 @@ func (m *Manager) SetPosition(pos types.Position) {
 	m.ScrollToCursor()
 }
 
+// ExtraCursors returns the cursors kept besides the main one for editing
+// in several places at once.
+func (m *Manager) ExtraCursors() []types.Position {
+	return append([]types.Position(nil), m.extra...)
+}
+
+// SetExtraCursors replaces the extra cursors; nil leaves only the main one.
+// Cursors on the main one or on each other are dropped.
+func (m *Manager) SetExtraCursors(cursors []types.Position) {
+	m.extra = m.extra[:0]
+	for _, pos := range cursors {
+		if pos != m.position && !slices.Contains(m.extra, pos) {
+			m.extra = append(m.extra, pos)
+		}
+	}
+}
+
 // MoveCursor moves the cursor by the given delta
 func (m *Manager) MoveCursor(deltaLine, deltaCol int) {
 	newPos := types.Position{
@@ -200,7 +267,7 @@ func (m *Manager) ScrollToCursor() {
 	// --- Calculate Gutter Width (needed for textAreaWidth) ---
 	buffer := m.editor.GetBuffer()
 	lineCount := buffer.LineCount()
-	gutterWidth := config.GutterWidth(lineCount, m.viewWidth)
+	gutterWidth := config.ViewGutterWidth(lineCount, m.viewportTop, m.viewHeight, m.viewWidth)
 	// --- End Gutter Width ---
 
 	effectiveScrollOff := m.editor.ScrollOff()
@@ -231,6 +298,13 @@ func (m *Manager) ScrollToCursor() {
 	if err == nil {
 		tabWidth := config.Get().Editor.TabWidth // Get current tab width
 		cursorVisualCol = GetVisualCol(string(lineBytes), m.position.Col, tabWidth)
+		if n := utf8.RuneCount(lineBytes); m.position.Col > n {
+			// Virtual position past the end of the line
+			cursorVisualCol = GetVisualLineLength(string(lineBytes), tabWidth) + m.position.Col - n
+		}
+		if layout := m.editor.TableLayout(); layout != nil {
+			cursorVisualCol = layout.VisualCol(lineBytes, m.position.Col)
+		}
 	} else {
 		logger.Warnf("ScrollToCursor: Failed to get line %d: %v", m.position.Line, err)
 	}
@@ -0,0 +1,124 @@
+package cursor
+
+import (
+	"unicode"
+
+	"github.com/bethropolis/tide/internal/types"
+	"github.com/rivo/uniseg"
+)
+
+// WordStarts returns the rune columns where the words of line start. Words
+// are found by Unicode word segmentation (UAX #29), so they are runs of
+// letters and digits in any script, joined by _ and by apostrophes and
+// dots inside them ("don't", "3.14"); spaces and punctuation are not words.
+func WordStarts(line string) []int {
+	var starts []int
+	col, state := 0, -1
+	for line != "" {
+		var segment string
+		segment, line, state = uniseg.FirstWordInString(line, state)
+		isWord := false
+		n := 0
+		for _, r := range segment {
+			isWord = isWord || unicode.IsLetter(r) || unicode.IsDigit(r)
+			n++
+		}
+		if isWord {
+			starts = append(starts, col)
+		}
+		col += n
+	}
+	return starts
+}
+
+// NextWordStart returns the column of the first word starting after col in
+// line, or the end of the line when none does.
+func NextWordStart(line string, col int) int {
+	for _, start := range WordStarts(line) {
+		if start > col {
+			return start
+		}
+	}
+	return len([]rune(line))
+}
+
+// PrevWordStart returns the column of the last word starting before col in
+// line, or 0 when none does.
+func PrevWordStart(line string, col int) int {
+	prev := 0
+	for _, start := range WordStarts(line) {
+		if start >= col {
+			break
+		}
+		prev = start
+	}
+	return prev
+}
+
+// NextWordPosition returns where the next word starts after the cursor
+// (Ctrl+Right): on the cursor line, else its end, or from the end of the
+// line the first word of the next one.
+func (m *Manager) NextWordPosition() types.Position {
+	pos := m.position
+	buf := m.editor.GetBuffer()
+	if buf == nil {
+		return pos
+	}
+	line, err := buf.Line(pos.Line)
+	if err != nil {
+		return pos
+	}
+	if n := len([]rune(string(line))); pos.Col < n {
+		return types.Position{Line: pos.Line, Col: NextWordStart(string(line), pos.Col)}
+	}
+	if pos.Line+1 >= buf.LineCount() {
+		return pos
+	}
+	next, err := buf.Line(pos.Line + 1)
+	if err != nil {
+		return pos
+	}
+	if starts := WordStarts(string(next)); len(starts) > 0 {
+		return types.Position{Line: pos.Line + 1, Col: starts[0]}
+	}
+	return types.Position{Line: pos.Line + 1, Col: len([]rune(string(next)))}
+}
+
+// PrevWordPosition returns where the word before the cursor starts
+// (Ctrl+Left): on the cursor line, else its start, or from the start of the
+// line the end of the one before.
+func (m *Manager) PrevWordPosition() types.Position {
+	pos := m.position
+	buf := m.editor.GetBuffer()
+	if buf == nil {
+		return pos
+	}
+	line, err := buf.Line(pos.Line)
+	if err != nil {
+		return pos
+	}
+	if pos.Col > 0 {
+		col := min(pos.Col, len([]rune(string(line))))
+		return types.Position{Line: pos.Line, Col: PrevWordStart(string(line), col)}
+	}
+	if pos.Line == 0 {
+		return pos
+	}
+	prev, err := buf.Line(pos.Line - 1)
+	if err != nil {
+		return pos
+	}
+	return types.Position{Line: pos.Line - 1, Col: len([]rune(string(prev)))}
+}
+
+// MoveNextWordStart moves the cursor to the start of the next word, by
+// Unicode word boundaries (see NextWordPosition).
+func (m *Manager) MoveNextWordStart() {
+	m.SetPosition(m.NextWordPosition())
+}
+
+// MovePrevWordStart moves the cursor to the start of the previous word, by
+// Unicode word boundaries (see PrevWordPosition).
+func (m *Manager) MovePrevWordStart() {
+	m.SetPosition(m.PrevWordPosition())
+}
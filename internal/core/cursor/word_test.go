@@ -0,0 +1,47 @@
+package cursor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWordStarts(t *testing.T) {
+	tests := []struct {
+		line string
+		want []int
+	}{
+		{"foo bar_baz", []int{0, 4}},
+		{"  x := y.z(1)", []int{2, 7, 11}},
+		{"don't stop", []int{0, 6}},
+		{"π = 3.14", []int{0, 4}},
+		{"日本 語", []int{0, 1, 3}},
+		{"naïve café", []int{0, 6}},
+		{"", nil},
+		{"-- ;", nil},
+	}
+	for _, tt := range tests {
+		if got := WordStarts(tt.line); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("WordStarts(%q) = %v, want %v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestWordStartsAround(t *testing.T) {
+	line := "say héllo, world"
+	for _, tt := range []struct {
+		col, next, prev int
+	}{
+		{0, 4, 0},
+		{4, 11, 0},
+		{6, 11, 4},
+		{11, 16, 4},
+		{16, 16, 11},
+	} {
+		if got := NextWordStart(line, tt.col); got != tt.next {
+			t.Errorf("NextWordStart(%q, %d) = %d, want %d", line, tt.col, got, tt.next)
+		}
+		if got := PrevWordStart(line, tt.col); got != tt.prev {
+			t.Errorf("PrevWordStart(%q, %d) = %d, want %d", line, tt.col, got, tt.prev)
+		}
+	}
+}
@@ -0,0 +1,332 @@
+// Package diff finds the differences between two sequences, the lines of
+// two texts or the runes of two lines, with Myers' algorithm, and turns
+// them into the edits the rest of the editor works with.
+package diff
+
+import (
+	"bytes"
+	"unicode/utf8"
+
+	"github.com/bethropolis/tide/internal/types"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// Change replaces Old[OldStart:OldEnd] with New[NewStart:NewEnd]. A
+// deletion has an empty new range and an insertion an empty old one.
+type Change struct {
+	OldStart, OldEnd int
+	NewStart, NewEnd int
+}
+
+// Diff returns, in order, the changes turning a sequence of n elements into
+// one of m, given whether element i of the first equals element j of the
+// second. The changes are as small as possible (a shortest edit script),
+// with the deletions and insertions between two equal elements merged.
+func Diff(n, m int, equal func(i, j int) bool) []Change {
+	d := differ{equal: equal}
+	d.compare(0, n, 0, m)
+	return d.changes
+}
+
+// Lines returns the changes between two texts split into lines (see
+// SplitLines).
+func Lines(old, new [][]byte) []Change {
+	// Number the distinct lines so that comparing two is cheap
+	ids := make(map[string]int)
+	number := func(lines [][]byte) []int {
+		out := make([]int, len(lines))
+		for i, line := range lines {
+			id, ok := ids[string(line)]
+			if !ok {
+				id = len(ids)
+				ids[string(line)] = id
+			}
+			out[i] = id
+		}
+		return out
+	}
+	a, b := number(old), number(new)
+	return Diff(len(a), len(b), func(i, j int) bool { return a[i] == b[j] })
+}
+
+// Runes returns the changes between two runs of runes, such as a line
+// before and after an edit.
+func Runes(old, new []rune) []Change {
+	return Diff(len(old), len(new), func(i, j int) bool { return old[i] == new[j] })
+}
+
+// SplitLines splits text after each newline, keeping it, so that a last
+// line without one differs from the same line with one.
+func SplitLines(text []byte) [][]byte {
+	var lines [][]byte
+	for len(text) > 0 {
+		n := bytes.IndexByte(text, '\n') + 1
+		if n == 0 {
+			n = len(text)
+		}
+		lines = append(lines, text[:n:n])
+		text = text[n:]
+	}
+	return lines
+}
+
+// refineLimit is the most runes on either side of a group of changed
+// lines that Edits compares rune by rune.
+const refineLimit = 1000
+
+// Edits returns the edits turning old into new: for each changed group of
+// lines, the runes that differ in it, or for a large group the bytes
+// between its common ends. They are in order and each is relative to the
+// text the ones before it leave, as a syntax tree or anything else
+// tracking EditInfo takes them.
+func Edits(old, new []byte) []types.EditInfo {
+	oldLines, newLines := SplitLines(old), SplitLines(new)
+	oldOffsets, newOffsets := offsets(oldLines), offsets(newLines)
+
+	var edits []types.EditInfo
+	// edit adds the replacement of removed by inserted at byte at of the
+	// new text, which is point
+	edit := func(at int, point sitter.Point, removed, inserted []byte) {
+		edits = append(edits, types.EditInfo{
+			StartIndex:     uint32(at),
+			OldEndIndex:    uint32(at + len(removed)),
+			NewEndIndex:    uint32(at + len(inserted)),
+			StartPosition:  point,
+			OldEndPosition: advance(point, removed),
+			NewEndPosition: advance(point, inserted),
+		})
+	}
+	for _, c := range Lines(oldLines, newLines) {
+		newStart := newOffsets[c.NewStart]
+		removed := old[oldOffsets[c.OldStart]:oldOffsets[c.OldEnd]]
+		inserted := new[newStart:newOffsets[c.NewEnd]]
+
+		prefix := commonPrefix(removed, inserted)
+		suffix := commonSuffix(removed[prefix:], inserted[prefix:])
+		removed = removed[prefix : len(removed)-suffix]
+		inserted = inserted[prefix : len(inserted)-suffix]
+		// Everything before a change is as in the new text by now
+		newStart += prefix
+		point := advance(sitter.Point{Row: uint32(c.NewStart)}, new[newStart-prefix:newStart])
+
+		oldRunes, newRunes := []rune(string(removed)), []rune(string(inserted))
+		if len(oldRunes) > refineLimit || len(newRunes) > refineLimit {
+			edit(newStart, point, removed, inserted)
+			continue
+		}
+		oldBytes, newBytes := runeOffsets(oldRunes), runeOffsets(newRunes)
+		for _, r := range Runes(oldRunes, newRunes) {
+			before := inserted[:newBytes[r.NewStart]]
+			edit(newStart+len(before), advance(point, before),
+				removed[oldBytes[r.OldStart]:oldBytes[r.OldEnd]], inserted[newBytes[r.NewStart]:newBytes[r.NewEnd]])
+		}
+	}
+	return edits
+}
+
+// MapPoint returns where p, a point in the text edits were made to, is in
+// the text they leave. A point in text an edit replaced keeps its place in
+// the new text if that is long enough, or goes to the end of it.
+func MapPoint(p sitter.Point, edits []types.EditInfo) sitter.Point {
+	for _, e := range edits {
+		switch {
+		case before(p, e.StartPosition):
+		case !before(p, e.OldEndPosition):
+			if p.Row == e.OldEndPosition.Row {
+				p.Column = e.NewEndPosition.Column + p.Column - e.OldEndPosition.Column
+			}
+			p.Row = p.Row - e.OldEndPosition.Row + e.NewEndPosition.Row
+		case !before(p, e.NewEndPosition):
+			p = e.NewEndPosition
+		}
+	}
+	return p
+}
+
+// before reports whether a comes before b.
+func before(a, b sitter.Point) bool {
+	return a.Row < b.Row || a.Row == b.Row && a.Column < b.Column
+}
+
+// runeOffsets returns the byte offset of each rune, and one more for the
+// end.
+func runeOffsets(runes []rune) []int {
+	out := make([]int, len(runes)+1)
+	for i, r := range runes {
+		out[i+1] = out[i] + utf8.RuneLen(r)
+	}
+	return out
+}
+
+// offsets returns the byte offset at which each line starts, and one more
+// for the end of the text.
+func offsets(lines [][]byte) []int {
+	out := make([]int, len(lines)+1)
+	for i, line := range lines {
+		out[i+1] = out[i] + len(line)
+	}
+	return out
+}
+
+// commonPrefix returns the length of the longest common prefix of a and b
+// that ends on a rune boundary.
+func commonPrefix(a, b []byte) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	for n > 0 && n < len(a) && !utf8.RuneStart(a[n]) {
+		n--
+	}
+	return n
+}
+
+// commonSuffix returns the length of the longest common suffix of a and b
+// that starts on a rune boundary.
+func commonSuffix(a, b []byte) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[len(a)-1-n] == b[len(b)-1-n] {
+		n++
+	}
+	for n > 0 && !utf8.RuneStart(a[len(a)-n]) {
+		n--
+	}
+	return n
+}
+
+// advance returns the point reached from p by going over text, with
+// columns counted in bytes as tree-sitter counts them.
+func advance(p sitter.Point, text []byte) sitter.Point {
+	for {
+		i := bytes.IndexByte(text, '\n')
+		if i < 0 {
+			p.Column += uint32(len(text))
+			return p
+		}
+		p.Row++
+		p.Column = 0
+		text = text[i+1:]
+	}
+}
+
+// differ holds the state of one Diff.
+type differ struct {
+	equal   func(i, j int) bool
+	changes []Change
+}
+
+// compare adds the changes turning the old elements [a0, a1) into the new
+// ones [b0, b1). Once common ends are trimmed, both ranges being non-empty
+// means at least two edits, so splitting at the middle of a shortest path
+// leaves two smaller problems.
+func (d *differ) compare(a0, a1, b0, b1 int) {
+	for a0 < a1 && b0 < b1 && d.equal(a0, b0) {
+		a0++
+		b0++
+	}
+	for a0 < a1 && b0 < b1 && d.equal(a1-1, b1-1) {
+		a1--
+		b1--
+	}
+	if a0 == a1 || b0 == b1 {
+		if a0 < a1 || b0 < b1 {
+			d.add(Change{OldStart: a0, OldEnd: a1, NewStart: b0, NewEnd: b1})
+		}
+		return
+	}
+	x, y, ok := d.middle(a0, a1, b0, b1)
+	if !ok {
+		d.add(Change{OldStart: a0, OldEnd: a1, NewStart: b0, NewEnd: b1})
+		return
+	}
+	d.compare(a0, x, b0, y)
+	d.compare(x, a1, y, b1)
+}
+
+// add appends c, merging it with the last change when they touch.
+func (d *differ) add(c Change) {
+	if n := len(d.changes); n > 0 {
+		last := &d.changes[n-1]
+		if last.OldEnd == c.OldStart && last.NewEnd == c.NewStart {
+			last.OldEnd, last.NewEnd = c.OldEnd, c.NewEnd
+			return
+		}
+	}
+	d.changes = append(d.changes, c)
+}
+
+// middle finds a point on a shortest edit path from (a0, b0) to (a1, b1)
+// by following the paths from both ends at once until they meet, in linear
+// space (Myers 1986, section 4b).
+func (d *differ) middle(a0, a1, b0, b1 int) (x, y int, ok bool) {
+	n, m := a1-a0, b1-b0
+	maxD := (n + m + 1) / 2
+	offset := maxD + 1
+	forward := make([]int, 2*offset+1)  // Furthest x on each diagonal k = x - y from the start
+	backward := make([]int, 2*offset+1) // Same from the end, with x counted back from a1
+	for i := range forward {
+		forward[i], backward[i] = -1, -1
+	}
+	forward[offset+1], backward[offset+1] = 0, 0
+	delta := n - m
+	odd := delta%2 != 0
+
+	// The k ranges shrink when a path runs off an edge
+	fStart, fEnd, bStart, bEnd := 0, 0, 0, 0
+	for step := 0; step <= maxD; step++ {
+		for k := -step + fStart; k <= step-fEnd; k += 2 {
+			i := offset + k
+			var fx int
+			if k == -step || (k != step && forward[i-1] < forward[i+1]) {
+				fx = forward[i+1]
+			} else {
+				fx = forward[i-1] + 1
+			}
+			fy := fx - k
+			for fx < n && fy < m && d.equal(a0+fx, b0+fy) {
+				fx++
+				fy++
+			}
+			forward[i] = fx
+			switch {
+			case fx > n:
+				fEnd += 2
+			case fy > m:
+				fStart += 2
+			case odd:
+				if j := offset + delta - k; j >= 0 && j < len(backward) && backward[j] != -1 && fx >= n-backward[j] {
+					return a0 + fx, b0 + fy, true
+				}
+			}
+		}
+		for k := -step + bStart; k <= step-bEnd; k += 2 {
+			i := offset + k
+			var bx int
+			if k == -step || (k != step && backward[i-1] < backward[i+1]) {
+				bx = backward[i+1]
+			} else {
+				bx = backward[i-1] + 1
+			}
+			by := bx - k
+			for bx < n && by < m && d.equal(a1-bx-1, b1-by-1) {
+				bx++
+				by++
+			}
+			backward[i] = bx
+			switch {
+			case bx > n:
+				bEnd += 2
+			case by > m:
+				bStart += 2
+			case !odd:
+				if j := offset + delta - k; j >= 0 && j < len(forward) && forward[j] != -1 {
+					fx := forward[j]
+					if fx >= n-bx {
+						return a0 + fx, b0 + fx - (j - offset), true
+					}
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
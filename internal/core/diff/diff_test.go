@@ -0,0 +1,172 @@
+package diff
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bethropolis/tide/internal/types"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// apply turns old into new with changes, checking they are in order.
+func apply(t *testing.T, old, new string, changes []Change) string {
+	t.Helper()
+	var out strings.Builder
+	at := 0
+	for _, c := range changes {
+		if c.OldStart < at || c.OldStart > c.OldEnd || c.NewStart > c.NewEnd {
+			t.Fatalf("bad change %+v after %d in %v", c, at, changes)
+		}
+		out.WriteString(old[at:c.OldStart])
+		out.WriteString(new[c.NewStart:c.NewEnd])
+		at = c.OldEnd
+	}
+	out.WriteString(old[at:])
+	return out.String()
+}
+
+// lcs returns the length of the longest common subsequence of a and b.
+func lcs(a, b string) int {
+	row := make([]int, len(b)+1)
+	for i := range a {
+		prev := 0
+		for j := range b {
+			cur := row[j+1]
+			if a[i] == b[j] {
+				row[j+1] = prev + 1
+			} else {
+				row[j+1] = max(row[j+1], row[j])
+			}
+			prev = cur
+		}
+	}
+	return row[len(b)]
+}
+
+func TestRunes(t *testing.T) {
+	got := Runes([]rune("abcabba"), []rune("cbabac"))
+	if want := "cbabac"; apply(t, "abcabba", want, got) != want {
+		t.Fatalf("Runes() = %+v does not turn abcabba into cbabac", got)
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	random := func() string {
+		b := make([]byte, rng.Intn(12))
+		for i := range b {
+			b[i] = "abc"[rng.Intn(3)]
+		}
+		return string(b)
+	}
+	for i := 0; i < 2000; i++ {
+		a, b := random(), random()
+		changes := Runes([]rune(a), []rune(b))
+		if got := apply(t, a, b, changes); got != b {
+			t.Fatalf("Runes(%q, %q) = %+v gives %q", a, b, changes, got)
+		}
+		edits := 0
+		for _, c := range changes {
+			edits += c.OldEnd - c.OldStart + c.NewEnd - c.NewStart
+		}
+		if want := len(a) + len(b) - 2*lcs(a, b); edits != want {
+			t.Fatalf("Runes(%q, %q) = %+v makes %d edits, want %d", a, b, changes, edits, want)
+		}
+	}
+}
+
+func TestLines(t *testing.T) {
+	old := SplitLines([]byte("a\nb\nc\nd"))
+	new := SplitLines([]byte("a\nB\nc\nd\ne\n"))
+	want := []Change{
+		{OldStart: 1, OldEnd: 2, NewStart: 1, NewEnd: 2},
+		{OldStart: 3, OldEnd: 4, NewStart: 3, NewEnd: 5}, // "d" without a newline is another line
+	}
+	if got := Lines(old, new); !reflect.DeepEqual(got, want) {
+		t.Errorf("Lines() = %+v, want %+v", got, want)
+	}
+	if got := Lines(old, old); len(got) != 0 {
+		t.Errorf("Lines() of equal texts = %+v", got)
+	}
+}
+
+func TestEdits(t *testing.T) {
+	old := "package main\n\nfunc f() {\n\treturn 1\n}\n"
+	new := "package main\n\nfunc f() int {\n\treturn 1\n}\n\nfunc g() {}\n"
+	want := []types.EditInfo{
+		{
+			StartIndex: 23, OldEndIndex: 23, NewEndIndex: 27,
+			StartPosition:  sitter.Point{Row: 2, Column: 9},
+			OldEndPosition: sitter.Point{Row: 2, Column: 9},
+			NewEndPosition: sitter.Point{Row: 2, Column: 13},
+		},
+		{
+			StartIndex: 41, OldEndIndex: 41, NewEndIndex: 54,
+			StartPosition:  sitter.Point{Row: 5},
+			OldEndPosition: sitter.Point{Row: 5},
+			NewEndPosition: sitter.Point{Row: 7},
+		},
+	}
+	got := Edits([]byte(old), []byte(new))
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Edits() = %+v, want %+v", got, want)
+	}
+
+	// Applied in order, the edits give the new text
+	text := old
+	for _, e := range got {
+		inserted := new[e.StartIndex:e.NewEndIndex]
+		text = text[:e.StartIndex] + inserted + text[e.OldEndIndex:]
+	}
+	if text != new {
+		t.Errorf("applying Edits() gives %q, want %q", text, new)
+	}
+
+	// Narrowing stops on rune boundaries
+	e := Edits([]byte("é\n"), []byte("è\n"))
+	if len(e) != 1 || e[0].StartIndex != 0 || e[0].OldEndIndex != 2 || e[0].NewEndIndex != 2 {
+		t.Errorf("Edits(é, è) = %+v, want the whole rune replaced", e)
+	}
+}
+
+func TestEditsRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	random := func() string {
+		b := make([]byte, rng.Intn(30))
+		for i := range b {
+			b[i] = "ab \n\t"[rng.Intn(5)]
+		}
+		return string(b)
+	}
+	for i := 0; i < 1000; i++ {
+		old, new := random(), random()
+		text := old
+		for _, e := range Edits([]byte(old), []byte(new)) {
+			if got := advance(sitter.Point{}, []byte(text[:e.StartIndex])); got != e.StartPosition {
+				t.Fatalf("Edits(%q, %q): edit %+v starts at %v", old, new, e, got)
+			}
+			text = text[:e.StartIndex] + new[e.StartIndex:e.NewEndIndex] + text[e.OldEndIndex:]
+		}
+		if text != new {
+			t.Fatalf("applying Edits(%q, %q) gives %q", old, new, text)
+		}
+	}
+}
+
+func TestMapPoint(t *testing.T) {
+	old := "a=1\n  b(x,y)\nc\n"
+	new := "a = 1\n\tb(x, y)\nc\n"
+	edits := Edits([]byte(old), []byte(new))
+	tests := []struct{ in, want sitter.Point }{
+		{sitter.Point{Row: 0, Column: 2}, sitter.Point{Row: 0, Column: 4}}, // On "1"
+		{sitter.Point{Row: 1, Column: 2}, sitter.Point{Row: 1, Column: 1}}, // On "b"
+		{sitter.Point{Row: 1, Column: 1}, sitter.Point{Row: 1, Column: 1}}, // In the indent that went
+		{sitter.Point{Row: 1, Column: 6}, sitter.Point{Row: 1, Column: 6}}, // On "y"
+		{sitter.Point{Row: 2, Column: 0}, sitter.Point{Row: 2, Column: 0}},
+	}
+	for _, tt := range tests {
+		if got := MapPoint(tt.in, edits); got != tt.want {
+			t.Errorf("MapPoint(%v) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
@@ -6,11 +6,15 @@ import (
 	"github.com/bethropolis/tide/internal/buffer"
 	"github.com/bethropolis/tide/internal/config"
 	"github.com/bethropolis/tide/internal/core/clipboard"
+	"github.com/bethropolis/tide/internal/core/conflict"
 	"github.com/bethropolis/tide/internal/core/cursor"
 	"github.com/bethropolis/tide/internal/core/find"
 	"github.com/bethropolis/tide/internal/core/highlight" // Import core highlight
 	"github.com/bethropolis/tide/internal/core/history"
+	"github.com/bethropolis/tide/internal/core/jumplist"
+	"github.com/bethropolis/tide/internal/core/marks"
 	"github.com/bethropolis/tide/internal/core/selection"
+	"github.com/bethropolis/tide/internal/core/table"
 	"github.com/bethropolis/tide/internal/core/text"
 	"github.com/bethropolis/tide/internal/event"
 	hl "github.com/bethropolis/tide/internal/highlighter"
@@ -23,7 +27,7 @@ import (
 type Editor struct {
 	buffer     buffer.Buffer
 	viewWidth  int // Cached terminal width
-	viewHeight int // Cached terminal height (excluding status bar)
+	viewHeight int // Cached height of the text area
 	scrollOff  int // Number of lines to keep visible above/below cursor
 
 	// Event Manager
@@ -45,6 +49,35 @@ type Editor struct {
 	// When forceFullRedraw is true the entire viewport must be redrawn.
 	dirtyLines      map[int]struct{}
 	forceFullRedraw bool
+
+	// Table view (CSV/TSV): non-nil while the buffer is drawn as aligned columns
+	tableLayout *table.Layout
+	pinHeader   bool // Keep the header row on screen while scrolling
+
+	// Merge conflict regions, found again only after the buffer changed
+	conflicts      []conflict.Region
+	conflictsFound bool
+
+	drawnPin    int    // Line drawn pinned over the top row last frame, plus one; 0 for none
+	drawnGutter int    // Gutter width drawn last frame
+	drawnMatch  [2]int // Lines of the bracket pair highlighted last frame, plus one; 0 for none
+
+	// Virtual text drawn after the end of one line (inline git blame)
+	hintLine int
+	hintText string
+
+	// Signs drawn in the gutter by line (unsaved changes, :diffsaved)
+	lineMarks map[int]LineMark
+
+	// Lines just appended to a followed file (:tail), set apart for a moment;
+	// appendedTo is 0 when there are none
+	appendedFrom, appendedTo int
+
+	// Places jumped from, shared with the editors of other buffers (Ctrl+O)
+	jumps *jumplist.List
+
+	// Named marks of the buffer (ma, 'a)
+	marks *marks.Set
 }
 
 // NewEditor creates a new Editor instance with a given buffer.
@@ -62,12 +95,18 @@ func NewEditor(buf buffer.Buffer, highlighterService *hl.Highlighter, eventManag
 	e.textOps = text.NewOperations(e)
 	e.cursorManager = cursor.NewManager(e)
 	e.selectionManager = selection.NewManager(e)
-	e.clipboardManager = clipboard.NewManager(e, cfg.Editor.SystemClipboard)
+	e.clipboardManager = clipboard.NewManager(e, cfg.Editor.SystemClipboard, cfg.Editor.PasteReindent, cfg.Editor.PrimarySelection, cfg.Editor.OSC52Clipboard)
 	e.historyManager = history.NewManager(e, history.DefaultMaxHistory)
 	e.findManager = find.NewManager(e)
+	e.findManager.SetNormalize(cfg.Editor.NormalizeSearch)
+	e.jumps = jumplist.New(jumplist.DefaultMaxJumps)
+	e.marks = marks.New()
 	// Initialize highlight manager with the event manager so it can fire
-	// TypeHighlightComplete when a background pass finishes.
-	e.highlightManager = highlight.NewManager(e, e.highlighter, eventManager)
+	// TypeHighlightComplete when a background pass finishes. Text too large
+	// to parse (a memory-mapped file) is left without one.
+	if buffer.CapabilitiesOf(buf).Parsed {
+		e.highlightManager = highlight.NewManager(e, e.highlighter, eventManager)
+	}
 	e.eventManager = eventManager
 	e.dirtyLines = make(map[int]struct{})
 	e.forceFullRedraw = true // First draw is always a full redraw
@@ -224,6 +263,13 @@ func (e *Editor) SetCursor(pos types.Position) {
 	// Selection update during movement is handled in editor_methods.go/MoveCursor
 }
 
+// SetViewport sets the viewport top line and left column.
+func (e *Editor) SetViewport(top, left int) {
+	if e.cursorManager != nil {
+		e.cursorManager.SetViewport(top, left)
+	}
+}
+
 // ScrollToCursor ensures cursor remains visible
 func (e *Editor) ScrollToCursor() {
 	if e.cursorManager != nil {
@@ -251,12 +297,30 @@ func (e *Editor) ClearHighlights() {
 	e.findManager.ClearHighlights()
 }
 
-// GetCurrentSearchHighlights delegates to findManager
-func (e *Editor) GetCurrentSearchHighlights() []types.HighlightRegion {
+// SearchHighlightsForLine returns the search highlights on a line,
+// delegating to findManager.
+func (e *Editor) SearchHighlightsForLine(lineNum int) []types.HighlightRegion {
 	if e.findManager == nil {
 		return nil
 	}
-	return e.findManager.GetHighlights()
+	return e.findManager.HighlightsForLine(lineNum)
+}
+
+// HighlightWordUnderCursor highlights other occurrences of the identifier
+// under the cursor within [fromLine, toLine]. Returns true if a repaint is needed.
+func (e *Editor) HighlightWordUnderCursor(fromLine, toLine int) bool {
+	if e.findManager == nil {
+		return false
+	}
+	return e.findManager.HighlightWordAt(e.GetCursor(), fromLine, toLine)
+}
+
+// ClearWordHighlights removes identifier highlights. Returns true if there were any.
+func (e *Editor) ClearWordHighlights() bool {
+	if e.findManager == nil {
+		return false
+	}
+	return e.findManager.ClearWordHighlights()
 }
 
 // --- Syntax Highlighting Methods (Delegated to Highlight Manager) ---
@@ -291,29 +355,37 @@ func (e *Editor) FilePath() string {
 
 // --- View Size ---
 
-// SetViewSize updates the view dimensions
+// SetViewSize updates the view dimensions: the cells of the window's text
+// area, not counting its status line. A new size redraws every row.
 func (e *Editor) SetViewSize(width, height int) {
-	e.viewWidth = width
-
-	// Calculate the usable height (excluding status bar)
-	adjustedHeight := height
-	cfg := config.Get() // Get config for status bar height
-	if adjustedHeight > cfg.Editor.StatusBarHeight {
-		adjustedHeight -= cfg.Editor.StatusBarHeight
-	} else {
-		adjustedHeight = 0
+	if width != e.viewWidth || max(height, 0) != e.viewHeight {
+		e.MarkAllDirty()
 	}
-	e.viewHeight = adjustedHeight
+	e.viewWidth = width
+	e.viewHeight = max(height, 0)
 
-	// Inform the cursor manager of the new view size.
-	// Pass adjustedHeight (usable text area height) so ScrollToCursor and
-	// PageMove use the correct boundary — not the full terminal height which
-	// would include the status bar row(s).
+	// Inform the cursor manager of the new view size so ScrollToCursor and
+	// PageMove use the correct boundary.
 	if e.cursorManager != nil {
-		e.cursorManager.SetViewSize(width, adjustedHeight)
+		e.cursorManager.SetViewSize(width, e.viewHeight)
 	}
 }
 
+// GutterWidth returns the width of the line-number gutter when the editor
+// is drawn screenWidth cells wide (see config.ViewGutterWidth).
+func (e *Editor) GutterWidth(screenWidth int) int {
+	top, _ := e.GetViewport()
+	return config.ViewGutterWidth(e.buffer.LineCount(), top, e.viewHeight, screenWidth)
+}
+
+// SetDrawnGutter records the gutter width drawn in this frame and reports
+// whether it differs from the last frame's, in which case every row moves.
+func (e *Editor) SetDrawnGutter(width int) bool {
+	changed := e.drawnGutter != width
+	e.drawnGutter = width
+	return changed
+}
+
 // --- History Methods ---
 
 // GetHistoryManager returns the history manager for undo/redo
@@ -359,6 +431,9 @@ func (e *Editor) GetFindManager() *find.Manager {
 
 // ScrollOff returns the scrolloff setting
 func (e *Editor) ScrollOff() int {
+	if e.HeaderPinned() && e.scrollOff < 1 {
+		return 1 // The pinned header covers the top row
+	}
 	return e.scrollOff
 }
 
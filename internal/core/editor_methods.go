@@ -1,9 +1,19 @@
 package core
 
 import (
+	"bytes"
 	"fmt"
+	"unicode/utf8"
 
+	"github.com/bethropolis/tide/internal/buffer"
+	"github.com/bethropolis/tide/internal/config"
+	"github.com/bethropolis/tide/internal/core/clipboard"
+	"github.com/bethropolis/tide/internal/core/cursor"
+	"github.com/bethropolis/tide/internal/core/text"
 	"github.com/bethropolis/tide/internal/event"
+	hl "github.com/bethropolis/tide/internal/highlighter"
+	"github.com/bethropolis/tide/internal/highlighter/lang"
+	"github.com/bethropolis/tide/internal/highlighter/utils"
 	"github.com/bethropolis/tide/internal/logger"
 	"github.com/bethropolis/tide/internal/types"
 )
@@ -14,7 +24,7 @@ func (e *Editor) InsertRune(r rune) error {
 		logger.Warnf("Editor.InsertRune: textOps manager is nil")
 		return nil
 	}
-	return e.textOps.InsertRune(r)
+	return e.atCursors(func(int) error { return e.textOps.InsertRune(r) })
 }
 
 func (e *Editor) InsertNewLine() error {
@@ -22,7 +32,33 @@ func (e *Editor) InsertNewLine() error {
 		logger.Warnf("Editor.InsertNewLine: textOps manager is nil")
 		return nil
 	}
-	return e.textOps.InsertNewLine()
+	return e.atCursors(func(int) error { return e.textOps.InsertNewLine(e.newLineIndent()) })
+}
+
+// newLineIndent returns how new lines are indented in this buffer, by the
+// auto_indent setting and the language of the file. A smart indent level
+// is a tab, or spaces with expand_tab or when the cursor line is indented
+// with spaces.
+func (e *Editor) newLineIndent() text.Indent {
+	cfg := config.Get().Editor
+	if cfg.AutoIndent == config.AutoIndentOff {
+		return text.Indent{}
+	}
+	if cfg.AutoIndent != config.AutoIndentSmart {
+		return text.Indent{Keep: true}
+	}
+
+	indent := text.Indent{Keep: true, Openers: "{[(", Unit: indentUnit()}
+	switch l := lang.GetForFile(e.buffer.FilePath()); {
+	case l == nil:
+		return text.Indent{Keep: true} // Plain text has no blocks
+	case l.Name == "Python":
+		indent.Openers += ":"
+	}
+	if line, err := e.buffer.Line(e.GetCursor().Line); err == nil && len(line) > 0 && line[0] == ' ' {
+		indent.Unit = bytes.Repeat([]byte{' '}, max(cfg.TabWidth, 1))
+	}
+	return indent
 }
 
 func (e *Editor) InsertTab() error {
@@ -30,7 +66,8 @@ func (e *Editor) InsertTab() error {
 		logger.Warnf("Editor.InsertTab: textOps manager is nil")
 		return nil
 	}
-	return e.textOps.InsertTab()
+	cfg := config.Get().Editor
+	return e.atCursors(func(int) error { return e.textOps.InsertTab(cfg.ExpandTab, cfg.TabWidth) })
 }
 
 func (e *Editor) DeleteBackward() error {
@@ -38,7 +75,7 @@ func (e *Editor) DeleteBackward() error {
 		logger.Warnf("Editor.DeleteBackward: textOps manager is nil")
 		return nil
 	}
-	return e.textOps.DeleteBackward()
+	return e.atCursors(func(int) error { return e.textOps.DeleteBackward() })
 }
 
 func (e *Editor) DeleteForward() error {
@@ -46,7 +83,7 @@ func (e *Editor) DeleteForward() error {
 		logger.Warnf("Editor.DeleteForward: textOps manager is nil")
 		return nil
 	}
-	return e.textOps.DeleteForward()
+	return e.atCursors(func(int) error { return e.textOps.DeleteForward() })
 }
 
 // Clipboard operations delegated to clipboardManager
@@ -66,6 +103,25 @@ func (e *Editor) CutSelection() (bool, error) {
 	return e.clipboardManager.CutSelection()
 }
 
+// YankRange copies the text from start up to end, or with linewise the
+// lines from start.Line to end.Line, to the clipboard.
+func (e *Editor) YankRange(start, end types.Position, linewise bool) error {
+	if e.clipboardManager == nil {
+		logger.Warnf("Editor.YankRange: clipboardManager is nil")
+		return nil
+	}
+	return e.clipboardManager.YankRange(start, end, linewise)
+}
+
+// CutRange copies the range like YankRange and deletes it.
+func (e *Editor) CutRange(start, end types.Position, linewise bool) error {
+	if e.clipboardManager == nil {
+		logger.Warnf("Editor.CutRange: clipboardManager is nil")
+		return nil
+	}
+	return e.clipboardManager.CutRange(start, end, linewise)
+}
+
 func (e *Editor) Paste(after bool) (bool, error) {
 	if e.clipboardManager == nil {
 		logger.Warnf("Editor.Paste: clipboardManager is nil")
@@ -74,6 +130,43 @@ func (e *Editor) Paste(after bool) (bool, error) {
 	return e.clipboardManager.Paste(after)
 }
 
+// PasteFromHistory pastes an entry from the clipboard history ring after the
+// cursor and makes it the current clipboard content.
+func (e *Editor) PasteFromHistory(entry clipboard.Entry) (bool, error) {
+	if e.clipboardManager == nil {
+		logger.Warnf("Editor.PasteFromHistory: clipboardManager is nil")
+		return false, nil
+	}
+	return e.clipboardManager.PasteFromHistory(entry, true)
+}
+
+// InsertPastedText inserts text pasted through the terminal at the cursor.
+func (e *Editor) InsertPastedText(text []byte) error {
+	if e.clipboardManager == nil {
+		logger.Warnf("Editor.InsertPastedText: clipboardManager is nil")
+		return nil
+	}
+	return e.clipboardManager.InsertText(text)
+}
+
+// CopySelectionToPrimary copies the selection to the primary selection.
+func (e *Editor) CopySelectionToPrimary() {
+	if e.clipboardManager == nil {
+		logger.Warnf("Editor.CopySelectionToPrimary: clipboardManager is nil")
+		return
+	}
+	e.clipboardManager.CopySelectionToPrimary()
+}
+
+// PastePrimary pastes the primary selection at the cursor.
+func (e *Editor) PastePrimary() (bool, error) {
+	if e.clipboardManager == nil {
+		logger.Warnf("Editor.PastePrimary: clipboardManager is nil")
+		return false, nil
+	}
+	return e.clipboardManager.PastePrimary()
+}
+
 // Cursor operations delegated to cursorManager
 func (e *Editor) MoveCursor(deltaLine, deltaCol int) {
 	if e.cursorManager == nil {
@@ -109,6 +202,21 @@ func (e *Editor) PageMove(deltaPages int) {
 		deltaPages, e.GetCursor().Line, e.GetCursor().Col)
 }
 
+// ScrollLines scrolls the view by delta lines, moving the cursor only as
+// far as needed to keep it visible.
+func (e *Editor) ScrollLines(delta int) {
+	if e.cursorManager == nil {
+		logger.Warnf("Editor.ScrollLines: cursorManager is nil")
+		return
+	}
+
+	e.cursorManager.ScrollLines(delta)
+
+	if e.selectionManager != nil && e.selectionManager.IsSelecting() {
+		e.selectionManager.UpdateSelectionEnd()
+	}
+}
+
 func (e *Editor) Home() {
 	if e.cursorManager == nil {
 		logger.Warnf("Editor.Home: cursorManager is nil")
@@ -185,11 +293,36 @@ func (e *Editor) WordEnd() {
 	}
 }
 
+// NextWordStart moves the cursor to the start of the next word by Unicode
+// word boundaries (Ctrl+Right), unlike WordForward's ASCII words.
+func (e *Editor) NextWordStart() {
+	if e.cursorManager == nil {
+		return
+	}
+	e.cursorManager.MoveNextWordStart()
+	if e.selectionManager != nil && e.selectionManager.IsSelecting() {
+		e.selectionManager.UpdateSelectionEnd()
+	}
+}
+
+// PrevWordStart moves the cursor to the start of the previous word by
+// Unicode word boundaries (Ctrl+Left).
+func (e *Editor) PrevWordStart() {
+	if e.cursorManager == nil {
+		return
+	}
+	e.cursorManager.MovePrevWordStart()
+	if e.selectionManager != nil && e.selectionManager.IsSelecting() {
+		e.selectionManager.UpdateSelectionEnd()
+	}
+}
+
 // GoToFileStart moves the cursor to the first line of the buffer (Vim 'gg').
 func (e *Editor) GoToFileStart() {
 	if e.cursorManager == nil {
 		return
 	}
+	e.PushJump()
 	e.cursorManager.SetPosition(types.Position{Line: 0, Col: 0})
 	e.cursorManager.ScrollToCursor()
 	if e.selectionManager != nil && e.selectionManager.IsSelecting() {
@@ -206,6 +339,7 @@ func (e *Editor) GoToFileEnd() {
 	if lastLine < 0 {
 		lastLine = 0
 	}
+	e.PushJump()
 	e.cursorManager.SetPosition(types.Position{Line: lastLine, Col: 0})
 	e.cursorManager.ScrollToCursor()
 	if e.selectionManager != nil && e.selectionManager.IsSelecting() {
@@ -213,35 +347,76 @@ func (e *Editor) GoToFileEnd() {
 	}
 }
 
-// DeleteWordForward deletes from the cursor to the start of the next word (Vim 'dw').
+// JumpToDefinition moves the cursor to the start of the next (or previous)
+// function or class definition, found in the buffer's syntax tree (Vim-style
+// ]f/[f). Returns false if there is none in that direction or the buffer has
+// not been parsed.
+func (e *Editor) JumpToDefinition(kind hl.DefinitionKind, forward bool) bool {
+	if e.cursorManager == nil || e.buffer == nil {
+		return false
+	}
+	cursor := e.GetCursor()
+	var target *types.Position
+	for _, d := range e.Definitions() {
+		if d.Kind != kind || d.StartLine >= e.buffer.LineCount() {
+			continue
+		}
+		line, err := e.buffer.Line(d.StartLine)
+		if err != nil {
+			continue
+		}
+		pos := types.Position{Line: d.StartLine, Col: utils.ByteOffsetToRuneIndex(line, d.StartCol)}
+		after := pos.Line > cursor.Line || (pos.Line == cursor.Line && pos.Col > cursor.Col)
+		before := pos.Line < cursor.Line || (pos.Line == cursor.Line && pos.Col < cursor.Col)
+		if forward && after {
+			target = &pos
+			break
+		}
+		if !forward && before {
+			target = &pos // Keep the last one before the cursor
+		}
+	}
+	if target == nil {
+		return false
+	}
+	e.PushJump()
+	e.cursorManager.SetPosition(*target)
+	e.cursorManager.ScrollToCursor()
+	if e.selectionManager != nil && e.selectionManager.IsSelecting() {
+		e.selectionManager.UpdateSelectionEnd()
+	}
+	return true
+}
+
+// DeleteWordForward deletes from the cursor to the start of the next word,
+// by Unicode word boundaries (Ctrl+Delete), as one undoable change. At the
+// end of a line it joins the next one, up to its first word.
 func (e *Editor) DeleteWordForward() error {
-	buf := e.GetBuffer()
-	if buf == nil {
+	if e.textOps == nil || e.cursorManager == nil {
+		logger.Warnf("Editor.DeleteWordForward: textOps manager is nil")
 		return nil
 	}
-	start := e.GetCursor()
-	e.WordForward()
-	end := e.GetCursor()
+	start, end := e.GetCursor(), e.cursorManager.NextWordPosition()
 	if start == end {
 		return nil
 	}
-	_, err := buf.Delete(start, end)
+	_, err := e.textOps.ReplaceRange(start, end, nil)
 	return err
 }
 
-// DeleteWordBackward deletes from the cursor back to the start of the current/previous word (Vim 'db').
+// DeleteWordBackward deletes from the cursor back to the start of the
+// previous word (Ctrl+Backspace), as one undoable change. At the start of a
+// line it joins it to the line before.
 func (e *Editor) DeleteWordBackward() error {
-	buf := e.GetBuffer()
-	if buf == nil {
+	if e.textOps == nil || e.cursorManager == nil {
+		logger.Warnf("Editor.DeleteWordBackward: textOps manager is nil")
 		return nil
 	}
-	start := e.GetCursor()
-	e.WordBackward()
-	end := e.GetCursor()
+	start, end := e.cursorManager.PrevWordPosition(), e.GetCursor()
 	if start == end {
 		return nil
 	}
-	_, err := buf.Delete(end, start)
+	_, err := e.textOps.ReplaceRange(start, end, nil)
 	return err
 }
 
@@ -255,7 +430,7 @@ func (e *Editor) Find(term string, startPos types.Position, forward bool) (types
 	if err != nil {
 		return types.Position{}, false
 	}
-	matchPos, found, _ := e.findManager.FindNext(forward) // Ignore wrapped status
+	matchPos, found, _ := e.findManager.FindNext(forward, config.Get().Editor.WrapScan) // Ignore wrapped status
 	if found {
 		return matchPos, true
 	}
@@ -315,12 +490,35 @@ func (e *Editor) ReplaceInRange(pattern, replacement string, startLine, endLine
 	return count, err
 }
 
+// ReplaceLines replaces the first occurrence of pattern on each line of
+// [startLine, endLine], or every one when global is set, as one undoable change.
+func (e *Editor) ReplaceLines(pattern, replacement string, startLine, endLine int, global, caseInsensitive bool) (int, error) {
+	if e.findManager == nil {
+		logger.Warnf("Editor.ReplaceLines: findManager is nil")
+		return 0, fmt.Errorf("find manager not initialized")
+	}
+	cursorBefore := e.GetCursor()
+	if e.historyManager != nil {
+		e.historyManager.BeginTransaction()
+	}
+	count, err := e.findManager.ReplaceLines(pattern, replacement, startLine, endLine, global, caseInsensitive)
+	if e.historyManager != nil {
+		e.historyManager.EndTransaction(cursorBefore)
+	}
+	return count, err
+}
+
 // SaveBuffer handles buffer saving, accepting an optional override path.
 func (e *Editor) SaveBuffer(filePath ...string) error { // Use variadic string
 	savePath := ""
 	if len(filePath) > 0 {
 		savePath = filePath[0] // Use first provided path if given
 	}
+	// Read-only buffers show an archive member, a URL or a preview rather
+	// than their file's text, so they may only be written elsewhere
+	if e.buffer.ReadOnly() && (savePath == "" || savePath == e.buffer.FilePath()) {
+		return buffer.ErrReadOnly
+	}
 	// Delegate to buffer's save method
 	err := e.buffer.Save(savePath)
 	if err != nil {
@@ -338,6 +536,209 @@ func (e *Editor) SaveBuffer(filePath ...string) error { // Use variadic string
 	return nil
 }
 
+// ReplaceRange replaces the text between start and end as one undoable
+// change, leaving the cursor at start. Returns the position after the new text.
+func (e *Editor) ReplaceRange(start, end types.Position, text []byte) (types.Position, error) {
+	if e.textOps == nil {
+		logger.Warnf("Editor.ReplaceRange: textOps manager is nil")
+		return start, nil
+	}
+	return e.textOps.ReplaceRange(start, end, text)
+}
+
+// ShiftLines indents lines startLine to endLine one level (right) or takes
+// one level of indentation off them, as one undoable change.
+func (e *Editor) ShiftLines(startLine, endLine int, right bool) error {
+	if e.textOps == nil {
+		logger.Warnf("Editor.ShiftLines: textOps manager is nil")
+		return nil
+	}
+	return e.textOps.ShiftLines(startLine, endLine, right, indentUnit(), config.Get().Editor.TabWidth)
+}
+
+// ShiftSelection indents (right) or outdents the lines of the selection as
+// one undoable change, and ends the selection. A characterwise selection
+// ending at the start of a line leaves that line alone. With nothing
+// selected the cursor line shifts, and the cursor stays on the same text.
+func (e *Editor) ShiftSelection(right bool) error {
+	if startLine, endLine, ok := e.selectedLines(); ok {
+		e.ClearSelection()
+		return e.ShiftLines(startLine, endLine, right)
+	}
+
+	cursor := e.GetCursor()
+	before, err := e.buffer.Line(cursor.Line)
+	if err != nil {
+		return err
+	}
+	width := utf8.RuneCount(before)
+	if err := e.ShiftLines(cursor.Line, cursor.Line, right); err != nil {
+		return err
+	}
+	after, _ := e.buffer.Line(cursor.Line)
+	cursor.Col = max(cursor.Col+utf8.RuneCount(after)-width, 0)
+	e.SetCursor(cursor)
+	return nil
+}
+
+// selectedLines returns the first and last line of the selection. A
+// characterwise selection ending at the start of a line leaves that line
+// out.
+func (e *Editor) selectedLines() (startLine, endLine int, ok bool) {
+	start, end, ok := e.GetSelection()
+	if !ok {
+		return 0, 0, false
+	}
+	endLine = end.Line
+	if !e.IsLinewise() && !e.IsBlockwise() && endLine > start.Line && end.Col == 0 {
+		endLine-- // The selection stops before this line
+	}
+	return start.Line, endLine, true
+}
+
+// editLines returns the lines an edit on whole lines works on: those of
+// the selection, ending it, or count lines from the cursor line.
+func (e *Editor) editLines(count int) (startLine, endLine int) {
+	if startLine, endLine, ok := e.selectedLines(); ok {
+		e.ClearSelection()
+		return startLine, endLine
+	}
+	line := e.GetCursor().Line
+	return line, min(line+max(count, 1)-1, e.buffer.LineCount()-1)
+}
+
+// DuplicateLines puts copies of the selected lines, or of the cursor line,
+// below them as one undoable change.
+func (e *Editor) DuplicateLines(copies int) error {
+	if e.textOps == nil {
+		logger.Warnf("Editor.DuplicateLines: textOps manager is nil")
+		return nil
+	}
+	startLine, endLine := e.editLines(1)
+	return e.textOps.DuplicateLines(startLine, endLine, max(copies, 1))
+}
+
+// DeleteLines deletes the selected lines, or count lines from the cursor
+// line, as one undoable change.
+func (e *Editor) DeleteLines(count int) error {
+	if e.textOps == nil {
+		logger.Warnf("Editor.DeleteLines: textOps manager is nil")
+		return nil
+	}
+	startLine, endLine := e.editLines(count)
+	return e.textOps.DeleteLines(startLine, endLine)
+}
+
+// JoinLines joins the selected lines, or count lines from the cursor line
+// (at least two), into one as one undoable change.
+func (e *Editor) JoinLines(count int) error {
+	if e.textOps == nil {
+		logger.Warnf("Editor.JoinLines: textOps manager is nil")
+		return nil
+	}
+	startLine, endLine := e.editLines(max(count, 2))
+	return e.textOps.JoinLines(startLine, max(endLine, startLine+1))
+}
+
+// MoveLines moves the selected lines, or the cursor line, down by lines, or
+// up when it is negative, as one undoable change.
+func (e *Editor) MoveLines(lines int) error {
+	if e.textOps == nil {
+		logger.Warnf("Editor.MoveLines: textOps manager is nil")
+		return nil
+	}
+	startLine, endLine := e.editLines(1)
+	return e.textOps.MoveLines(startLine, endLine, lines)
+}
+
+// ToggleComment comments out the lines of the selection, or the cursor
+// line, with the line comment marker of the file's language, or uncomments
+// them when they all are, as one undoable change, and ends the selection.
+// The cursor stays on the same text.
+func (e *Editor) ToggleComment() error {
+	if e.textOps == nil {
+		logger.Warnf("Editor.ToggleComment: textOps manager is nil")
+		return nil
+	}
+	marker := lang.LineComment(e.buffer.FilePath())
+	if marker == "" {
+		return fmt.Errorf("no line comment is known for this file type")
+	}
+	cursor := e.GetCursor()
+	startLine, endLine, ok := e.selectedLines()
+	if !ok {
+		startLine, endLine = cursor.Line, cursor.Line
+	}
+	e.ClearSelection()
+
+	before, err := e.buffer.Line(cursor.Line)
+	if err != nil {
+		return err
+	}
+	width := utf8.RuneCount(before)
+	if err := e.textOps.ToggleComment(startLine, endLine, marker); err != nil {
+		return err
+	}
+	after, _ := e.buffer.Line(cursor.Line)
+	cursor.Col = max(cursor.Col+utf8.RuneCount(after)-width, 0)
+	e.SetCursor(cursor)
+	e.ScrollToCursor()
+	return nil
+}
+
+// Retab rewrites the indentation of the buffer to use tabs, or spaces with
+// expand_tab (:retab), and returns how many lines changed.
+func (e *Editor) Retab() (int, error) {
+	if e.textOps == nil {
+		logger.Warnf("Editor.Retab: textOps manager is nil")
+		return 0, nil
+	}
+	cfg := config.Get().Editor
+	retabbed, changed := text.Retab(e.buffer.Bytes(), cfg.ExpandTab, cfg.TabWidth)
+	if changed == 0 {
+		return 0, nil
+	}
+	return changed, e.Rewrite(retabbed)
+}
+
+// indentUnit returns one level of indentation: a tab, or tab_width spaces
+// with expand_tab.
+func indentUnit() []byte {
+	cfg := config.Get().Editor
+	if cfg.ExpandTab {
+		return bytes.Repeat([]byte{' '}, max(cfg.TabWidth, 1))
+	}
+	return []byte{'\t'}
+}
+
+// InsertAtCursor inserts text at the cursor as one undoable change and moves
+// the cursor after it.
+func (e *Editor) InsertAtCursor(text []byte) error {
+	end, err := e.ReplaceRange(e.GetCursor(), e.GetCursor(), text)
+	if err != nil {
+		return err
+	}
+	e.SetCursor(end)
+	e.ScrollToCursor()
+	return nil
+}
+
+// SelectedText returns the range and text of the selection. A linewise
+// selection covers its lines in full, without the final newline.
+func (e *Editor) SelectedText() (start, end types.Position, text string, ok bool) {
+	start, end, ok = e.GetSelection()
+	if !ok {
+		return start, end, "", false
+	}
+	if e.IsLinewise() {
+		start.Col = 0
+		if line, err := e.buffer.Line(end.Line); err == nil {
+			end.Col = utf8.RuneCount(line)
+		}
+	}
+	return start, end, e.buffer.GetText(start, end), true
+}
+
 // GetVisualSelectionLines returns the start and end line numbers of the current
 // visual selection. If no selection is active it returns the cursor line for both.
 func (e *Editor) GetVisualSelectionLines() (startLine, endLine int) {
@@ -361,5 +762,46 @@ func (e *Editor) GetBufferCol(lineIdx int, visualCol int) int {
 		return visualCol
 	}
 
+	if e.tableLayout != nil {
+		return e.tableLayout.BufferCol(lineBytes, visualCol)
+	}
+	if config.Get().Editor.VirtualEdit {
+		// Clicks past the end of the line keep their column
+		width := cursor.GetVisualLineLength(string(lineBytes), config.Get().Editor.TabWidth)
+		if visualCol > width {
+			return utf8.RuneCount(lineBytes) + visualCol - width
+		}
+	}
 	return e.cursorManager.GetBufferCol(string(lineBytes), visualCol)
 }
+
+// InsertColumn inserts text at column col of lines startLine to endLine as
+// one undoable change, as typing on a visual block does. Lines too short
+// to reach the column are padded with spaces with pad, otherwise skipped.
+func (e *Editor) InsertColumn(startLine, endLine, col int, text []byte, pad bool) error {
+	if len(text) == 0 {
+		return nil
+	}
+	if e.historyManager != nil && !e.historyManager.InTransaction() {
+		e.historyManager.BeginTransaction()
+		defer e.historyManager.EndTransaction(e.GetCursor())
+	}
+	for line := startLine; line <= endLine; line++ {
+		current, err := e.buffer.Line(line)
+		if err != nil {
+			return err
+		}
+		at, insert := types.Position{Line: line, Col: col}, text
+		if n := utf8.RuneCount(current); n < col {
+			if !pad {
+				continue
+			}
+			at.Col = n
+			insert = append(bytes.Repeat([]byte{' '}, col-n), text...)
+		}
+		if _, err := e.ReplaceRange(at, at, insert); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package find
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ReplacementTemplate turns the replacement of a substitute into a template
+// for regexp.Expand. Groups are written $1, ${1} or ${name} as in Go, or
+// \1 as in Vim (\0 is the whole match); $$, \$ and \\ stand for a literal
+// $ and \. A number after $ ends at the first non-digit, so "$1_x" is group
+// 1 followed by "_x" rather than Go's group "1_x". Any other $ is kept as
+// it is, so "$HOME" is put in literally instead of as an unknown group.
+func ReplacementTemplate(replacement string) string {
+	if !strings.ContainsAny(replacement, `\$`) {
+		return replacement
+	}
+	var b strings.Builder
+	for i := 0; i < len(replacement); i++ {
+		c := replacement[i]
+		if c == '$' {
+			next := byte(0)
+			if i+1 < len(replacement) {
+				next = replacement[i+1]
+			}
+			switch {
+			case next == '$':
+				b.WriteString("$$")
+				i++
+			case isDigit(next):
+				n, _ := leadingDigits(replacement[i+1:])
+				b.WriteString("${" + n + "}")
+				i += len(n)
+			case next == '{':
+				b.WriteByte('$') // ${1} or ${name}, as Go reads it
+			default:
+				b.WriteString("$$")
+			}
+			continue
+		}
+		if c == '\\' && i+1 < len(replacement) {
+			next := replacement[i+1]
+			switch {
+			case isDigit(next):
+				b.WriteString("${" + string(next) + "}")
+				i++
+				continue
+			case next == '\\':
+				b.WriteByte('\\')
+				i++
+				continue
+			case next == '$':
+				b.WriteString("$$")
+				i++
+				continue
+			}
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// expandMatches replaces the first n matches of re in line (all when n is
+// negative) with template, expanded for each match. It returns the new
+// line along with the byte range of each match and the text put there.
+func expandMatches(re *regexp.Regexp, line []byte, template string, n int) (out []byte, locs [][]int, texts [][]byte) {
+	locs = re.FindAllSubmatchIndex(line, n)
+	if len(locs) == 0 {
+		return line, nil, nil
+	}
+	last := 0
+	for _, loc := range locs {
+		out = append(out, line[last:loc[0]]...)
+		text := re.Expand(nil, []byte(template), line, loc)
+		out = append(out, text...)
+		texts = append(texts, text)
+		last = loc[1]
+	}
+	return append(out, line[last:]...), locs, texts
+}
+
+// leadingDigits splits s after its leading decimal digits.
+func leadingDigits(s string) (digits, rest string) {
+	i := 0
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	return s[:i], s[i:]
+}
@@ -0,0 +1,56 @@
+package find
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestReplacementTemplate(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"plain", "plain"},
+		{"$2=$1", "${2}=${1}"},
+		{"$1_x", "${1}_x"},
+		{"$12", "${12}"},
+		{"${name} $$1", "${name} $$1"},
+		{"$HOME/x", "$$HOME/x"},
+		{`\$1 costs $`, "$$1 costs $$"},
+		{`\2=\1`, "${2}=${1}"},
+		{`\0!`, "${0}!"},
+		{`a\\1`, `a\1`},
+		{`\n`, `\n`},
+		{`end\`, `end\`},
+	}
+	for _, tt := range tests {
+		if got := ReplacementTemplate(tt.in); got != tt.want {
+			t.Errorf("ReplacementTemplate(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestExpandMatches(t *testing.T) {
+	re := regexp.MustCompile(`(\w+)=(\w+)`)
+	line := []byte("a=1, bb=22")
+
+	out, locs, texts := expandMatches(re, line, ReplacementTemplate(`\2=\1`), -1)
+	if string(out) != "1=a, 22=bb" {
+		t.Errorf("global: got %q", out)
+	}
+	if len(locs) != 2 || string(texts[1]) != "22=bb" {
+		t.Errorf("global: locs %v, texts %q", locs, texts)
+	}
+
+	out, locs, _ = expandMatches(re, line, "${2}:${1}", 1)
+	if string(out) != "1:a, bb=22" || len(locs) != 1 {
+		t.Errorf("first only: got %q, %d match(es)", out, len(locs))
+	}
+
+	out, _, _ = expandMatches(re, line, ReplacementTemplate(`$HOME:\$1`), 1)
+	if string(out) != "$HOME:$1, bb=22" {
+		t.Errorf("literal $: got %q", out)
+	}
+
+	out, locs, _ = expandMatches(re, []byte("none"), "x", -1)
+	if string(out) != "none" || locs != nil {
+		t.Errorf("no match: got %q, %v", out, locs)
+	}
+}
@@ -1,7 +1,6 @@
 package find
 
 import (
-	"bytes"
 	"fmt"
 	"regexp"
 	"strings"
@@ -28,12 +27,15 @@ type EditorInterface interface {
 // Manager handles find, replace, and search highlighting logic.
 type Manager struct {
 	editor            EditorInterface
-	mutex             sync.RWMutex // Protects internal state
-	searchHighlights  []types.HighlightRegion
+	mutex             sync.RWMutex            // Protects internal state
+	searchHighlights  []types.HighlightRegion // Set regions, such as the match :s/.../c asks about
+	highlightRegex    *regexp.Regexp          // Matches highlighted line by line as they are drawn
+	wordHighlights    []types.HighlightRegion // Other occurrences of the identifier under the cursor
 	lastSearchTerm    string
 	lastSearchRegex   *regexp.Regexp // Cache compiled regex
 	lastMatchPos      *types.Position
 	lastSearchForward bool
+	normalize         bool // Match text whatever its Unicode normalization form (see compile)
 }
 
 // NewManager creates a find manager.
@@ -45,9 +47,10 @@ func NewManager(editor EditorInterface) *Manager {
 	}
 }
 
-// FindNext finds the next occurrence and moves cursor to it.
-// Now returns wrapped status as the third return value.
-func (m *Manager) FindNext(forward bool) (types.Position, bool, bool) {
+// FindNext finds the next occurrence and moves cursor to it. With wrap
+// set the search continues from the other end of the buffer (Vim's
+// 'wrapscan'); the third return value reports whether it did.
+func (m *Manager) FindNext(forward, wrap bool) (types.Position, bool, bool) {
 	m.mutex.Lock() // Lock for accessing lastSearchTerm etc.
 	term := m.lastSearchTerm
 	re := m.lastSearchRegex
@@ -69,7 +72,7 @@ func (m *Manager) FindNext(forward bool) (types.Position, bool, bool) {
 		// For backward search, we'll find matches before the current match
 	}
 
-	foundPos, found, wrapped := m.findInternal(re, startPos, forward)
+	foundPos, found, wrapped := m.findInternal(re, startPos, forward, wrap)
 
 	if found {
 		// Update last match position
@@ -84,9 +87,10 @@ func (m *Manager) FindNext(forward bool) (types.Position, bool, bool) {
 	return types.Position{}, false, false
 }
 
-// findInternal performs the actual search using buffer access.
+// findInternal performs the actual search using buffer access, going
+// around the end of the buffer only when wrap is set.
 // Returns position, found status, and wrap status.
-func (m *Manager) findInternal(re *regexp.Regexp, startPos types.Position, forward bool) (pos types.Position, found bool, wrapped bool) {
+func (m *Manager) findInternal(re *regexp.Regexp, startPos types.Position, forward, wrap bool) (pos types.Position, found bool, wrapped bool) {
 	buf := m.editor.GetBuffer()
 	lineCount := buf.LineCount()
 	if lineCount == 0 {
@@ -122,10 +126,10 @@ func (m *Manager) findInternal(re *regexp.Regexp, startPos types.Position, forwa
 				}
 			}
 
-			searchBytes := lineBytes[searchStartByteOffset:]
-			loc := re.FindIndex(searchBytes)
-			if loc != nil {
-				matchByteOffset := searchStartByteOffset + loc[0]
+			// Match against the whole line so anchors and \b see the real
+			// surrounding text, then take the first match at/after the start.
+			if loc := firstMatchFrom(re, lineBytes, searchStartByteOffset); loc != nil {
+				matchByteOffset := loc[0]
 				matchRuneCol := byteOffsetToRuneIndex(lineBytes, matchByteOffset)
 				return types.Position{Line: lineIdx, Col: matchRuneCol}, true, false // Found, not wrapped
 			}
@@ -133,6 +137,9 @@ func (m *Manager) findInternal(re *regexp.Regexp, startPos types.Position, forwa
 		}
 
 		// --- Phase 2: Wrap around - Search from start of buffer to original startPos ---
+		if !wrap {
+			return types.Position{}, false, false
+		}
 		logger.DebugTagf("find", "Wrapping forward search to beginning.")
 		for lineIdx := 0; lineIdx <= originalStartLine; lineIdx++ { // Include original line
 			lineBytes, err := buf.Line(lineIdx)
@@ -140,7 +147,6 @@ func (m *Manager) findInternal(re *regexp.Regexp, startPos types.Position, forwa
 				continue
 			}
 
-			searchBytes := lineBytes
 			searchEndByteOffset := len(lineBytes) // Default: search whole line
 
 			if lineIdx == originalStartLine {
@@ -149,11 +155,10 @@ func (m *Manager) findInternal(re *regexp.Regexp, startPos types.Position, forwa
 				if searchEndByteOffset < 0 {
 					searchEndByteOffset = 0
 				} // Clamp
-				searchBytes = lineBytes[:searchEndByteOffset]
 			}
 
-			loc := re.FindIndex(searchBytes) // Find *first* match on wrapped lines
-			if loc != nil {
+			loc := firstMatchFrom(re, lineBytes, 0) // Find *first* match on wrapped lines
+			if loc != nil && (lineIdx != originalStartLine || loc[0] < searchEndByteOffset) {
 				matchByteOffset := loc[0]
 				matchRuneCol := byteOffsetToRuneIndex(lineBytes, matchByteOffset)
 				return types.Position{Line: lineIdx, Col: matchRuneCol}, true, true // Found, wrapped
@@ -177,10 +182,7 @@ func (m *Manager) findInternal(re *regexp.Regexp, startPos types.Position, forwa
 				} // Clamp
 			}
 
-			searchBytes := lineBytes[:searchEndByteOffset]
-			locs := re.FindAllIndex(searchBytes, -1)
-			if len(locs) > 0 {
-				lastMatch := locs[len(locs)-1] // Get last match before cursor/end offset
+			if lastMatch := lastMatchBefore(re, lineBytes, searchEndByteOffset); lastMatch != nil {
 				matchByteOffset := lastMatch[0]
 				matchRuneCol := byteOffsetToRuneIndex(lineBytes, matchByteOffset)
 				return types.Position{Line: lineIdx, Col: matchRuneCol}, true, false // Found, not wrapped
@@ -189,6 +191,9 @@ func (m *Manager) findInternal(re *regexp.Regexp, startPos types.Position, forwa
 		}
 
 		// --- Phase 2: Wrap around - Search from end of buffer down to original startPos ---
+		if !wrap {
+			return types.Position{}, false, false
+		}
 		logger.DebugTagf("find", "Wrapping backward search to end.")
 		for lineIdx := lineCount - 1; lineIdx >= originalStartLine; lineIdx-- { // Include original line
 			lineBytes, err := buf.Line(lineIdx)
@@ -196,29 +201,20 @@ func (m *Manager) findInternal(re *regexp.Regexp, startPos types.Position, forwa
 				continue
 			}
 
-			searchBytes := lineBytes
-			searchStartByteOffset := 0 // Default: Start from beginning
-
+			// Find matches on wrapped lines
+			var locs [][]int
 			if lineIdx == originalStartLine {
-				// On the original line, only search *from or after* the original start column
-				searchStartByteOffset = runeIndexToByteOffset(lineBytes, originalStartCol)
+				// On the original line, only take the first match *from or after* the original start column
+				searchStartByteOffset := runeIndexToByteOffset(lineBytes, originalStartCol)
 				if searchStartByteOffset < 0 {
 					searchStartByteOffset = 0
 				}
-				searchBytes = lineBytes[searchStartByteOffset:]
-			}
-
-			// Find matches on wrapped lines
-			var locs [][]int
-			if lineIdx == originalStartLine {
-				// Find first match after start offset
-				loc := re.FindIndex(searchBytes)
-				if loc != nil {
-					locs = [][]int{{searchStartByteOffset + loc[0], searchStartByteOffset + loc[1]}} // Adjust offset back
+				if loc := firstMatchFrom(re, lineBytes, searchStartByteOffset); loc != nil {
+					locs = [][]int{loc}
 				}
 			} else {
 				// Find all matches on the line (when wrapping from end)
-				locs = re.FindAllIndex(searchBytes, -1)
+				locs = re.FindAllIndex(lineBytes, -1)
 			}
 
 			if len(locs) > 0 {
@@ -241,7 +237,62 @@ func (m *Manager) findInternal(re *regexp.Regexp, startPos types.Position, forwa
 	return types.Position{}, false, false // Not found, wrap status irrelevant
 }
 
-// HighlightMatches finds and stores all occurrences for highlighting.
+// firstMatchFrom returns the first match in line starting at or after byte
+// offset from, or nil.
+func firstMatchFrom(re *regexp.Regexp, line []byte, from int) []int {
+	for _, loc := range re.FindAllIndex(line, -1) {
+		if loc[0] >= from {
+			return loc
+		}
+	}
+	return nil
+}
+
+// lastMatchBefore returns the last match in line starting before byte
+// offset before, or nil.
+func lastMatchBefore(re *regexp.Regexp, line []byte, before int) []int {
+	var last []int
+	for _, loc := range re.FindAllIndex(line, -1) {
+		if loc[0] >= before {
+			break
+		}
+		last = loc
+	}
+	return last
+}
+
+// SetLastMatch makes the next FindNext continue from pos, as if a match had
+// just been found there. Used by '*' and '#' to skip the word under the cursor.
+func (m *Manager) SetLastMatch(pos types.Position) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.lastMatchPos = &pos
+}
+
+// MatchEnd returns where the match of the last search starting at pos
+// ends (exclusive), for search offsets measured from the end. It returns
+// pos when no match starts there.
+func (m *Manager) MatchEnd(pos types.Position) types.Position {
+	m.mutex.RLock()
+	re := m.lastSearchRegex
+	m.mutex.RUnlock()
+	if re == nil {
+		return pos
+	}
+	line, err := m.editor.GetBuffer().Line(pos.Line)
+	if err != nil {
+		return pos
+	}
+	start := runeIndexToByteOffset(line, pos.Col)
+	if loc := firstMatchFrom(re, line, start); loc != nil && loc[0] == start {
+		return types.Position{Line: pos.Line, Col: byteOffsetToRuneIndex(line, loc[1])}
+	}
+	return pos
+}
+
+// HighlightMatches makes term the search and highlights its matches. They
+// are found a line at a time as lines are drawn (see HighlightsForLine), so
+// a pattern matching all over a huge file costs no more than a rare one.
 func (m *Manager) HighlightMatches(term string) error {
 	m.ClearHighlights() // Clear previous search highlights
 
@@ -254,7 +305,7 @@ func (m *Manager) HighlightMatches(term string) error {
 		return nil // Nothing to highlight
 	}
 
-	re, err := regexp.Compile(term)
+	re, err := m.compile(term)
 	if err != nil {
 		m.mutex.Lock()
 		m.lastSearchTerm = term
@@ -271,35 +322,10 @@ func (m *Manager) HighlightMatches(term string) error {
 	m.lastMatchPos = nil // Reset last match position on new highlight
 	m.mutex.Unlock()
 
-	m.mutex.Lock() // Lock highlights for writing
-	defer m.mutex.Unlock()
-
-	buf := m.editor.GetBuffer()
-	lineCount := buf.LineCount()
-	newHighlights := make([]types.HighlightRegion, 0)
-
-	for lineIdx := 0; lineIdx < lineCount; lineIdx++ {
-		lineBytes, err := buf.Line(lineIdx)
-		if err != nil {
-			continue
-		}
-
-		locs := re.FindAllIndex(lineBytes, -1)
-		for _, loc := range locs {
-			matchStartByteOffset := loc[0]
-			matchEndByteOffset := loc[1]
-			matchStartCol := byteOffsetToRuneIndex(lineBytes, matchStartByteOffset)
-			matchEndCol := byteOffsetToRuneIndex(lineBytes, matchEndByteOffset)
-
-			newHighlights = append(newHighlights, types.HighlightRegion{
-				Start: types.Position{Line: lineIdx, Col: matchStartCol},
-				End:   types.Position{Line: lineIdx, Col: matchEndCol},
-				Type:  types.HighlightSearch,
-			})
-		}
-	}
-	m.searchHighlights = newHighlights // Assign new highlights
-	logger.DebugTagf("core", "FindManager: Added %d search highlights for '%s'", len(m.searchHighlights), term)
+	m.mutex.Lock()
+	m.highlightRegex = re
+	m.mutex.Unlock()
+	logger.DebugTagf("core", "FindManager: Highlighting matches of '%s'", term)
 	return nil
 }
 
@@ -311,56 +337,158 @@ func (m *Manager) ClearHighlights() {
 		logger.DebugTagf("core", "FindManager: Clearing %d search highlights", len(m.searchHighlights))
 		m.searchHighlights = make([]types.HighlightRegion, 0) // Clear slice
 	}
+	m.highlightRegex = nil
+}
+
+// HighlightWordAt highlights every other occurrence of the identifier at pos
+// within lines [fromLine, toLine] (normally the visible part of the buffer).
+// Any previous word highlights are replaced. Returns true if there were
+// highlights before or after, i.e. the screen needs repainting.
+func (m *Manager) HighlightWordAt(pos types.Position, fromLine, toLine int) bool {
+	buf := m.editor.GetBuffer()
+	var highlights []types.HighlightRegion
+
+	if line, err := buf.Line(pos.Line); err == nil {
+		if word, _, ok := IdentifierAt(line, pos.Col); ok {
+			re := regexp.MustCompile(WordPattern(word))
+			if fromLine < 0 {
+				fromLine = 0
+			}
+			if toLine >= buf.LineCount() {
+				toLine = buf.LineCount() - 1
+			}
+			for lineIdx := fromLine; lineIdx <= toLine; lineIdx++ {
+				lineBytes, err := buf.Line(lineIdx)
+				if err != nil {
+					continue
+				}
+				for _, loc := range re.FindAllIndex(lineBytes, -1) {
+					startCol := byteOffsetToRuneIndex(lineBytes, loc[0])
+					endCol := byteOffsetToRuneIndex(lineBytes, loc[1])
+					if lineIdx == pos.Line && startCol <= pos.Col && pos.Col < endCol {
+						continue // The occurrence under the cursor itself
+					}
+					highlights = append(highlights, types.HighlightRegion{
+						Start: types.Position{Line: lineIdx, Col: startCol},
+						End:   types.Position{Line: lineIdx, Col: endCol},
+						Type:  types.HighlightWord,
+					})
+				}
+			}
+		}
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	changed := len(m.wordHighlights) > 0 || len(highlights) > 0
+	m.wordHighlights = highlights
+	return changed
+}
+
+// ClearWordHighlights removes the identifier highlights. Returns true if
+// there were any.
+func (m *Manager) ClearWordHighlights() bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	had := len(m.wordHighlights) > 0
+	m.wordHighlights = nil
+	return had
+}
+
+// GetWordHighlights returns a copy of the identifier highlights.
+func (m *Manager) GetWordHighlights() []types.HighlightRegion {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return append([]types.HighlightRegion(nil), m.wordHighlights...)
 }
 
 // HasHighlights checks if there are any search highlights.
 func (m *Manager) HasHighlights() bool {
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
-	return len(m.searchHighlights) > 0
+	return len(m.searchHighlights) > 0 || m.highlightRegex != nil
 }
 
-// GetHighlights returns the current search highlight regions.
-func (m *Manager) GetHighlights() []types.HighlightRegion {
+// HighlightsForLine returns the search highlights touching line lineIdx:
+// those of the set regions when there are any, or else the search's
+// matches on that line.
+func (m *Manager) HighlightsForLine(lineIdx int) []types.HighlightRegion {
 	m.mutex.RLock()
-	defer m.mutex.RUnlock()
+	re := m.highlightRegex
+	regions := len(m.searchHighlights) > 0
+	var highlights []types.HighlightRegion
+	for _, h := range m.searchHighlights {
+		if h.Start.Line <= lineIdx && lineIdx <= h.End.Line {
+			highlights = append(highlights, h)
+		}
+	}
+	m.mutex.RUnlock()
+	if re == nil || regions {
+		return highlights
+	}
 
-	// Return a copy to avoid race conditions
-	highlights := make([]types.HighlightRegion, len(m.searchHighlights))
-	copy(highlights, m.searchHighlights)
+	lineBytes, err := m.editor.GetBuffer().Line(lineIdx)
+	if err != nil {
+		return nil
+	}
+	for _, loc := range re.FindAllIndex(lineBytes, -1) {
+		if loc[0] == loc[1] {
+			continue // Empty matches, as of ^ or x*, show nothing
+		}
+		highlights = append(highlights, types.HighlightRegion{
+			Start: types.Position{Line: lineIdx, Col: byteOffsetToRuneIndex(lineBytes, loc[0])},
+			End:   types.Position{Line: lineIdx, Col: byteOffsetToRuneIndex(lineBytes, loc[1])},
+			Type:  types.HighlightSearch,
+		})
+	}
 	return highlights
 }
 
 // --- Replace Logic ---
 
-// ParseSubstituteCommand parses the :s/pattern/replacement/[flags] command string.
-// Supported flags: 'g' (global on line), 'i' (case-insensitive).
-// Flags can be combined: e.g. ":s/foo/bar/gi".
-func ParseSubstituteCommand(cmdStr string) (pattern, replacement string, global, caseInsensitive bool, err error) {
+// Substitute is a parsed :s/pattern/replacement/[flags] command.
+type Substitute struct {
+	Pattern         string
+	Replacement     string
+	Global          bool // 'g': every match on a line, not just the first
+	CaseInsensitive bool // 'i'
+	Confirm         bool // 'c': ask before each replacement
+}
+
+// ParseSubstitute parses the /pattern/replacement/[flags] part of a :s
+// command. Flags can be combined: e.g. "/foo/bar/gci".
+func ParseSubstitute(cmdStr string) (Substitute, error) {
+	var sub Substitute
 	parts := strings.SplitN(cmdStr, "/", 4)
 	if len(parts) < 3 || parts[0] != "" {
-		err = fmt.Errorf("invalid format: use /pattern/replacement/[g][i]")
-		return
+		return sub, fmt.Errorf("invalid format: use /pattern/replacement/[g][i][c]")
 	}
 
-	pattern = parts[1]
-	replacement = parts[2]
+	sub.Pattern = parts[1]
+	sub.Replacement = parts[2]
 
-	if pattern == "" {
-		err = fmt.Errorf("search pattern cannot be empty")
-		return
+	if sub.Pattern == "" {
+		return sub, fmt.Errorf("search pattern cannot be empty")
 	}
 
 	if len(parts) > 3 {
 		flags := parts[3]
-		global = strings.Contains(flags, "g")
-		caseInsensitive = strings.Contains(flags, "i")
+		sub.Global = strings.Contains(flags, "g")
+		sub.CaseInsensitive = strings.Contains(flags, "i")
+		sub.Confirm = strings.Contains(flags, "c")
 	}
+	return sub, nil
+}
 
-	return
+// ParseSubstituteCommand parses the :s/pattern/replacement/[flags] command string.
+// Supported flags: 'g' (global on line), 'i' (case-insensitive).
+// Flags can be combined: e.g. ":s/foo/bar/gi".
+func ParseSubstituteCommand(cmdStr string) (pattern, replacement string, global, caseInsensitive bool, err error) {
+	sub, err := ParseSubstitute(cmdStr)
+	return sub.Pattern, sub.Replacement, sub.Global, sub.CaseInsensitive, err
 }
 
-// Replace replaces occurrences on the current line.
+// Replace replaces occurrences on the current line as one undoable change.
 // Supports global 'g' flag and case-insensitive 'i' flag.
 func (m *Manager) Replace(patternStr, replacement string, global, caseInsensitive bool) (int, error) {
 	if patternStr == "" {
@@ -371,7 +499,7 @@ func (m *Manager) Replace(patternStr, replacement string, global, caseInsensitiv
 	if caseInsensitive {
 		flags = "(?i)"
 	}
-	re, err := regexp.Compile(flags + patternStr)
+	re, err := m.compile(flags + patternStr)
 	if err != nil {
 		return 0, fmt.Errorf("invalid search pattern: %w", err)
 	}
@@ -386,52 +514,28 @@ func (m *Manager) Replace(patternStr, replacement string, global, caseInsensitiv
 	if err != nil {
 		return 0, fmt.Errorf("cannot get current line %d: %w", lineIdx, err)
 	}
+	originalLineBytes = append([]byte(nil), originalLineBytes...) // The buffer may reuse its storage once edited
 
-	matches := re.FindAllIndex(originalLineBytes, -1)
+	n := 1
+	if global {
+		n = -1
+	}
+	newLineBytes, matches, replacements := expandMatches(re, originalLineBytes, ReplacementTemplate(replacement), n)
 	if len(matches) == 0 {
 		return 0, nil
 	} // No matches
-
-	replaceCount := 0
-	var finalLine bytes.Buffer // Buffer to build the new line content
-	lastIndex := 0             // Tracks end position of last match/start position
+	replaceCount := len(matches)
 
 	var firstMatchStartPos types.Position // Cursor position after first replace
-
-	if global {
-		// --- Global Replace: Rebuild the line ---
-		for _, loc := range matches {
-			matchStartByte := loc[0]
-			matchEndByte := loc[1]
-
-			// Append text before the current match
-			finalLine.Write(originalLineBytes[lastIndex:matchStartByte])
-			// Append the replacement text
-			finalLine.Write([]byte(replacement))
-			// Update lastIndex to point after the current match
-			lastIndex = matchEndByte
-			replaceCount++
-		}
-		// Append any remaining text after the last match
-		finalLine.Write(originalLineBytes[lastIndex:])
-
-	} else {
-		// --- Single Replace (First Occurrence Only) ---
-		loc := matches[0]
-		matchStartByte := loc[0]
-		matchEndByte := loc[1]
-
-		// Build the new line
-		finalLine.Write(originalLineBytes[:matchStartByte])
-		finalLine.Write([]byte(replacement))
-		finalLine.Write(originalLineBytes[matchEndByte:])
-		replaceCount = 1
-
-		// Calculate start/end positions for undo/cursor
-		firstMatchStartPos = types.Position{Line: lineIdx, Col: byteOffsetToRuneIndex(originalLineBytes, matchStartByte)}
+	if !global {
+		firstMatchStartPos = types.Position{Line: lineIdx, Col: byteOffsetToRuneIndex(originalLineBytes, matches[0][0])}
 	}
 
-	newLineBytes := finalLine.Bytes()
+	// The delete and insert below undo and redo as one step
+	if histMgr != nil {
+		histMgr.BeginGroup(cursor)
+		defer histMgr.EndGroup()
+	}
 
 	// --- Apply Change to Buffer (Delete original line, Insert new line) ---
 	originalStartPos := types.Position{Line: lineIdx, Col: 0}
@@ -453,7 +557,7 @@ func (m *Manager) Replace(patternStr, replacement string, global, caseInsensitiv
 	// --- Record Undo ---
 	if histMgr != nil {
 		if global {
-			// For global replace, record the entire line as a single delete+insert
+			// For global replace, record the entire line as a delete+insert
 			histMgr.RecordChange(history.Change{
 				Type:          history.DeleteAction,
 				Text:          originalLineBytes,
@@ -485,9 +589,9 @@ func (m *Manager) Replace(patternStr, replacement string, global, caseInsensitiv
 			})
 			histMgr.RecordChange(history.Change{
 				Type:          history.InsertAction,
-				Text:          []byte(replacement),
+				Text:          replacements[0],
 				StartPosition: matchStartPos,
-				EndPosition:   types.Position{Line: lineIdx, Col: matchStartPos.Col + utf8.RuneCountInString(replacement)},
+				EndPosition:   types.Position{Line: lineIdx, Col: matchStartPos.Col + utf8.RuneCount(replacements[0])},
 				CursorBefore:  matchStartPos,
 			})
 		}
@@ -503,7 +607,7 @@ func (m *Manager) Replace(patternStr, replacement string, global, caseInsensitiv
 		NewEndPosition: editInfoIns.NewEndPosition,
 	}
 	if eventMgr != nil {
-		eventMgr.Dispatch(event.TypeBufferModified, event.BufferModifiedData{Edit: netEditInfo})
+		eventMgr.Dispatch(event.TypeBufferModified, event.BufferModifiedData{Edit: netEditInfo, FilePath: m.editor.GetBuffer().FilePath()})
 	}
 
 	// --- Update Cursor ---
@@ -535,7 +639,7 @@ func (m *Manager) ReplaceAll(patternStr, replacement string, caseInsensitive boo
 	if caseInsensitive {
 		flags = "(?i)"
 	}
-	re, err := regexp.Compile(flags + patternStr)
+	re, err := m.compile(flags + patternStr)
 	if err != nil {
 		return 0, fmt.Errorf("invalid search pattern: %w", err)
 	}
@@ -547,6 +651,7 @@ func (m *Manager) ReplaceAll(patternStr, replacement string, caseInsensitive boo
 
 	totalReplaced := 0
 	lineCount := buf.LineCount()
+	template := ReplacementTemplate(replacement)
 
 	for lineIdx := 0; lineIdx < lineCount; lineIdx++ {
 		originalLineBytes, err := buf.Line(lineIdx)
@@ -554,22 +659,13 @@ func (m *Manager) ReplaceAll(patternStr, replacement string, caseInsensitive boo
 			continue
 		}
 
-		matches := re.FindAllIndex(originalLineBytes, -1)
+		// Rebuild the line with all replacements applied
+		newLineBytes, matches, _ := expandMatches(re, originalLineBytes, template, -1)
 		if len(matches) == 0 {
 			continue
 		}
-
-		// Rebuild the line with all replacements applied
-		var finalLine bytes.Buffer
-		lastIndex := 0
-		for _, loc := range matches {
-			finalLine.Write(originalLineBytes[lastIndex:loc[0]])
-			finalLine.Write([]byte(replacement))
-			lastIndex = loc[1]
-			totalReplaced++
-		}
-		finalLine.Write(originalLineBytes[lastIndex:])
-		newLineBytes := finalLine.Bytes()
+		totalReplaced += len(matches)
+		originalLineBytes = append([]byte(nil), originalLineBytes...) // The buffer may reuse its storage once edited
 
 		// Delete original line content
 		originalStartPos := types.Position{Line: lineIdx, Col: 0}
@@ -627,7 +723,7 @@ func (m *Manager) ReplaceAll(patternStr, replacement string, caseInsensitive boo
 			NewEndPosition: editInfoIns.NewEndPosition,
 		}
 		if eventMgr != nil {
-			eventMgr.Dispatch(event.TypeBufferModified, event.BufferModifiedData{Edit: netEditInfo})
+			eventMgr.Dispatch(event.TypeBufferModified, event.BufferModifiedData{Edit: netEditInfo, FilePath: m.editor.GetBuffer().FilePath()})
 		}
 	}
 
@@ -643,6 +739,13 @@ func (m *Manager) ReplaceAll(patternStr, replacement string, caseInsensitive boo
 // ReplaceInRange replaces all occurrences of pattern in the given line range [startLine, endLine].
 // Each replacement is recorded individually; wrap with Begin/EndTransaction for atomic undo.
 func (m *Manager) ReplaceInRange(patternStr, replacement string, startLine, endLine int, caseInsensitive bool) (int, error) {
+	return m.ReplaceLines(patternStr, replacement, startLine, endLine, true, caseInsensitive)
+}
+
+// ReplaceLines replaces the first occurrence of pattern on each line of
+// [startLine, endLine], or every occurrence when global is set (:N,Ms).
+// Each replacement is recorded individually; wrap with Begin/EndTransaction for atomic undo.
+func (m *Manager) ReplaceLines(patternStr, replacement string, startLine, endLine int, global, caseInsensitive bool) (int, error) {
 	if patternStr == "" {
 		return 0, fmt.Errorf("search pattern cannot be empty")
 	}
@@ -651,7 +754,7 @@ func (m *Manager) ReplaceInRange(patternStr, replacement string, startLine, endL
 	if caseInsensitive {
 		flags = "(?i)"
 	}
-	re, err := regexp.Compile(flags + patternStr)
+	re, err := m.compile(flags + patternStr)
 	if err != nil {
 		return 0, fmt.Errorf("invalid search pattern: %w", err)
 	}
@@ -670,6 +773,11 @@ func (m *Manager) ReplaceInRange(patternStr, replacement string, startLine, endL
 	}
 
 	totalReplaced := 0
+	template := ReplacementTemplate(replacement)
+	n := -1
+	if !global {
+		n = 1
+	}
 
 	for lineIdx := startLine; lineIdx <= endLine; lineIdx++ {
 		originalLineBytes, err := buf.Line(lineIdx)
@@ -677,21 +785,12 @@ func (m *Manager) ReplaceInRange(patternStr, replacement string, startLine, endL
 			continue
 		}
 
-		matches := re.FindAllIndex(originalLineBytes, -1)
+		newLineBytes, matches, _ := expandMatches(re, originalLineBytes, template, n)
 		if len(matches) == 0 {
 			continue
 		}
-
-		var finalLine bytes.Buffer
-		lastIndex := 0
-		for _, loc := range matches {
-			finalLine.Write(originalLineBytes[lastIndex:loc[0]])
-			finalLine.Write([]byte(replacement))
-			lastIndex = loc[1]
-			totalReplaced++
-		}
-		finalLine.Write(originalLineBytes[lastIndex:])
-		newLineBytes := finalLine.Bytes()
+		totalReplaced += len(matches)
+		originalLineBytes = append([]byte(nil), originalLineBytes...) // The buffer may reuse its storage once edited
 
 		originalStartPos := types.Position{Line: lineIdx, Col: 0}
 		originalEndCol := utf8.RuneCount(originalLineBytes)
@@ -744,7 +843,7 @@ func (m *Manager) ReplaceInRange(patternStr, replacement string, startLine, endL
 			NewEndPosition: editInfoIns.NewEndPosition,
 		}
 		if eventMgr != nil {
-			eventMgr.Dispatch(event.TypeBufferModified, event.BufferModifiedData{Edit: netEditInfo})
+			eventMgr.Dispatch(event.TypeBufferModified, event.BufferModifiedData{Edit: netEditInfo, FilePath: m.editor.GetBuffer().FilePath()})
 		}
 	}
 
@@ -753,7 +852,7 @@ func (m *Manager) ReplaceInRange(patternStr, replacement string, startLine, endL
 		m.editor.ScrollToCursor()
 	}
 
-	logger.DebugTagf("find", "ReplaceInRange: Replaced %d occurrence(s) in lines %d-%d", totalReplaced, startLine, endLine)
+	logger.DebugTagf("find", "ReplaceLines: Replaced %d occurrence(s) in lines %d-%d", totalReplaced, startLine, endLine)
 	return totalReplaced, nil
 }
 func runeIndexToByteOffset(line []byte, runeIndex int) int {
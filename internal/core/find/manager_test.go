@@ -0,0 +1,64 @@
+package find
+
+import (
+	"testing"
+
+	"github.com/bethropolis/tide/internal/buffer"
+	"github.com/bethropolis/tide/internal/types"
+)
+
+func TestFindNextWrap(t *testing.T) {
+	buf := buffer.NewSliceBuffer()
+	if _, err := buf.Insert(types.Position{}, []byte("foo\nbar\nfoo")); err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		forward, wrap bool
+		from          types.Position
+		want          types.Position
+		found         bool
+	}{
+		{forward: true, wrap: true, from: types.Position{Line: 2, Col: 1}, want: types.Position{}, found: true},
+		{forward: true, wrap: false, from: types.Position{Line: 2, Col: 1}},
+		{forward: false, wrap: true, from: types.Position{Line: 0}, want: types.Position{Line: 2}, found: true},
+		{forward: false, wrap: false, from: types.Position{Line: 0}},
+	} {
+		m := NewManager(&testEditor{buf: buf, cursor: tc.from})
+		if err := m.HighlightMatches("foo"); err != nil {
+			t.Fatal(err)
+		}
+		pos, found, wrapped := m.FindNext(tc.forward, tc.wrap)
+		if found != tc.found || pos != tc.want || wrapped != tc.found {
+			t.Errorf("forward=%v wrap=%v: FindNext = %v, %v, %v; want %v, %v, %v",
+				tc.forward, tc.wrap, pos, found, wrapped, tc.want, tc.found, tc.found)
+		}
+	}
+}
+
+func TestHighlightsForLine(t *testing.T) {
+	buf := buffer.NewSliceBuffer()
+	if _, err := buf.Insert(types.Position{}, []byte("a.a\nb\néa")); err != nil {
+		t.Fatal(err)
+	}
+	m := NewManager(&testEditor{buf: buf})
+	if err := m.HighlightMatches("a"); err != nil {
+		t.Fatal(err)
+	}
+	if !m.HasHighlights() {
+		t.Fatalf("HasHighlights = false after HighlightMatches")
+	}
+	if got := m.HighlightsForLine(0); len(got) != 2 || got[1].Start.Col != 2 {
+		t.Errorf("line 0 highlights = %v; want the two a's", got)
+	}
+	if got := m.HighlightsForLine(1); len(got) != 0 {
+		t.Errorf("line 1 highlights = %v; want none", got)
+	}
+	if got := m.HighlightsForLine(2); len(got) != 1 || got[0].Start.Col != 1 || got[0].End.Col != 2 {
+		t.Errorf("line 2 highlights = %v; want rune columns 1-2", got)
+	}
+
+	m.ClearHighlights()
+	if m.HasHighlights() || len(m.HighlightsForLine(0)) != 0 {
+		t.Errorf("highlights left after ClearHighlights")
+	}
+}
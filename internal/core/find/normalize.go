@@ -0,0 +1,94 @@
+package find
+
+import (
+	"regexp"
+	"regexp/syntax"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// compile compiles a search pattern. With normalization on, the literal
+// text in it matches whether the buffer holds it composed (NFC, "é") or
+// decomposed (NFD, "e" and a combining accent), however it was typed.
+func (m *Manager) compile(pattern string) (*regexp.Regexp, error) {
+	m.mutex.RLock()
+	normalize := m.normalize
+	m.mutex.RUnlock()
+	if normalize {
+		pattern = NormalizePattern(pattern)
+	}
+	return regexp.Compile(pattern)
+}
+
+// SetNormalize turns normalization-insensitive matching (see compile) on
+// or off for the searches and substitutions compiled from now on.
+func (m *Manager) SetNormalize(on bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.normalize = on
+}
+
+// NormalizePattern rewrites the literal text of a regular expression so
+// that each character with a canonical decomposition matches both its
+// composed and its decomposed form: "café" becomes "caf(?:é|e\x{301})".
+// Character classes are left alone, as is a pattern that does not parse,
+// for regexp.Compile to report.
+func NormalizePattern(pattern string) string {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return pattern
+	}
+	if !normalizeLiterals(re) {
+		return pattern
+	}
+	return re.String()
+}
+
+// normalizeLiterals rewrites the literals in re in place and reports
+// whether any changed.
+func normalizeLiterals(re *syntax.Regexp) bool {
+	changed := false
+	for _, sub := range re.Sub {
+		if normalizeLiterals(sub) {
+			changed = true
+		}
+	}
+	if re.Op != syntax.OpLiteral {
+		return changed
+	}
+
+	composed := []rune(norm.NFC.String(string(re.Rune)))
+	literal := func(runes []rune) *syntax.Regexp {
+		return &syntax.Regexp{Op: syntax.OpLiteral, Flags: re.Flags, Rune: runes}
+	}
+	var parts []*syntax.Regexp
+	var plain []rune
+	for _, r := range composed {
+		decomposed := []rune(norm.NFD.String(string(r)))
+		if len(decomposed) == 1 {
+			plain = append(plain, r)
+			continue
+		}
+		if len(plain) > 0 {
+			parts = append(parts, literal(plain))
+			plain = nil
+		}
+		parts = append(parts, &syntax.Regexp{
+			Op:  syntax.OpAlternate,
+			Sub: []*syntax.Regexp{literal([]rune{r}), literal(decomposed)},
+		})
+	}
+	if len(parts) == 0 {
+		return changed
+	}
+	if len(plain) > 0 {
+		parts = append(parts, literal(plain))
+	}
+
+	// Replace the literal with the concatenation of its parts
+	*re = syntax.Regexp{Op: syntax.OpConcat, Flags: re.Flags, Sub: parts}
+	if len(parts) == 1 {
+		*re = *parts[0]
+	}
+	return true
+}
@@ -0,0 +1,106 @@
+package find
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/bethropolis/tide/internal/buffer"
+	"github.com/bethropolis/tide/internal/types"
+)
+
+const (
+	composed   = "caf\u00e9"  // é as one character (NFC)
+	decomposed = "cafe\u0301" // e and a combining acute accent (NFD)
+)
+
+func TestNormalizePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		match   []string
+		noMatch []string
+	}{
+		{composed, []string{composed, decomposed}, []string{"cafe"}},
+		{decomposed, []string{composed, decomposed}, []string{"cafe"}},
+		{"(?i)CAFÉ", []string{composed, decomposed}, nil},
+		{"é+s", []string{"éés"}, []string{"es"}},
+		{"^caf.$", []string{composed}, nil},
+		{"[é]", []string{composed}, nil},
+		{"plain", []string{"plain"}, []string{"pla"}},
+	}
+	for _, tt := range tests {
+		re, err := regexp.Compile(NormalizePattern(tt.pattern))
+		if err != nil {
+			t.Fatalf("NormalizePattern(%q) = %q: %v", tt.pattern, NormalizePattern(tt.pattern), err)
+		}
+		for _, s := range tt.match {
+			if !re.MatchString(s) {
+				t.Errorf("NormalizePattern(%q) = %q does not match %q", tt.pattern, re, s)
+			}
+		}
+		for _, s := range tt.noMatch {
+			if re.MatchString(s) {
+				t.Errorf("NormalizePattern(%q) = %q matches %q", tt.pattern, re, s)
+			}
+		}
+	}
+	if got := NormalizePattern("a("); got != "a(" {
+		t.Errorf("NormalizePattern of a bad pattern = %q, want it unchanged", got)
+	}
+}
+
+func TestFindDecomposed(t *testing.T) {
+	buf := buffer.NewSliceBuffer()
+	if _, err := buf.Insert(types.Position{}, []byte("x "+decomposed+"\n"+composed+" "+decomposed)); err != nil {
+		t.Fatal(err)
+	}
+	for _, normalize := range []bool{true, false} {
+		m := NewManager(&testEditor{buf: buf})
+		m.SetNormalize(normalize)
+		if err := m.HighlightMatches(composed); err != nil {
+			t.Fatal(err)
+		}
+
+		var found []types.Position
+		for i := 0; i < 3; i++ {
+			pos, ok, _ := m.FindNext(true, false)
+			if !ok {
+				break
+			}
+			found = append(found, pos)
+		}
+		want := []types.Position{{Line: 0, Col: 2}, {Line: 1, Col: 0}, {Line: 1, Col: 5}}
+		if !normalize {
+			want = []types.Position{{Line: 1, Col: 0}}
+		}
+		if len(found) != len(want) {
+			t.Fatalf("normalize=%v: FindNext found %v, want %v", normalize, found, want)
+		}
+		for i := range want {
+			if found[i] != want[i] {
+				t.Errorf("normalize=%v: match %d at %v, want %v", normalize, i, found[i], want[i])
+			}
+		}
+
+		if normalize {
+			got := m.HighlightsForLine(1)
+			if len(got) != 2 || got[1].Start.Col != 5 || got[1].End.Col != 10 {
+				t.Errorf("line 1 highlights = %v; want rune columns 0-4 and 5-10", got)
+			}
+		}
+	}
+}
+
+func TestReplaceDecomposed(t *testing.T) {
+	buf := buffer.NewSliceBuffer()
+	if _, err := buf.Insert(types.Position{}, []byte(composed+" "+decomposed)); err != nil {
+		t.Fatal(err)
+	}
+	m := NewManager(&testEditor{buf: buf})
+	m.SetNormalize(true)
+	if n, err := m.ReplaceAll(decomposed, "tea", false); err != nil || n != 2 {
+		t.Fatalf("ReplaceAll = %d, %v; want 2 replacements", n, err)
+	}
+	if got := string(buf.Bytes()); got != "tea tea" {
+		t.Errorf("text = %q, want %q", got, "tea tea")
+	}
+}
@@ -0,0 +1,111 @@
+package find
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bethropolis/tide/internal/types"
+)
+
+// Offset says where a search leaves the cursor relative to the match, as
+// written after the pattern in Vim: "/foo/e" (last character of the
+// match), "/foo/e+1", "/foo/s-2" or "/foo/b+1" (characters from its start)
+// and "/foo/+1" or "/foo/-" (lines below or above, in the first column).
+type Offset struct {
+	Anchor rune // 0 for a line offset, 's' for the start or 'e' for the end of the match
+	N      int
+}
+
+// ParseSearch splits what was typed after '/' into the pattern and the
+// offset after its first unescaped '/'. Without one the offset is zero,
+// which leaves the cursor on the start of the match.
+func ParseSearch(input string) (pattern string, off Offset, err error) {
+	sep := -1
+	for i := 0; i < len(input); i++ {
+		if input[i] == '\\' {
+			i++
+			continue
+		}
+		if input[i] == '/' {
+			sep = i
+			break
+		}
+	}
+	if sep < 0 {
+		return input, Offset{}, nil
+	}
+	off, err = ParseOffset(input[sep+1:])
+	return input[:sep], off, err
+}
+
+// ParseOffset parses a search offset such as "e", "e-1", "s+2", "b", "+3",
+// "-" or "" (no offset).
+func ParseOffset(s string) (Offset, error) {
+	var off Offset
+	rest := s
+	switch {
+	case rest == "":
+		return off, nil
+	case rest[0] == 'e':
+		off.Anchor, rest = 'e', rest[1:]
+	case rest[0] == 's' || rest[0] == 'b':
+		off.Anchor, rest = 's', rest[1:]
+	}
+
+	switch {
+	case rest == "":
+	case rest == "+" || rest == "-":
+		off.N = 1
+		if rest == "-" {
+			off.N = -1
+		}
+	default:
+		if rest[0] != '+' && rest[0] != '-' && off.Anchor != 0 {
+			return Offset{}, fmt.Errorf("invalid search offset: %s", s)
+		}
+		n, err := strconv.Atoi(strings.TrimPrefix(rest, "+"))
+		if err != nil {
+			return Offset{}, fmt.Errorf("invalid search offset: %s", s)
+		}
+		off.N = n
+	}
+	return off, nil
+}
+
+// String writes the offset as typed after the pattern, "" for none.
+func (o Offset) String() string {
+	var b strings.Builder
+	if o.Anchor != 0 {
+		b.WriteRune(o.Anchor)
+	}
+	if o.N != 0 {
+		if o.N > 0 {
+			b.WriteByte('+')
+		}
+		b.WriteString(strconv.Itoa(o.N))
+	}
+	return b.String()
+}
+
+// Apply returns where the cursor goes for a match from start up to end
+// (exclusive) on one line, clamped to the buffer. lineLength gives the
+// rune length of a line; lineCount is how many there are. Character
+// offsets do not leave the match's line.
+func (o Offset) Apply(start, end types.Position, lineCount int, lineLength func(line int) int) types.Position {
+	switch o.Anchor {
+	case 's':
+		return types.Position{Line: start.Line, Col: clamp(start.Col+o.N, 0, lineLength(start.Line))}
+	case 'e':
+		last := max(start.Col, end.Col-1) // The last character of the match
+		return types.Position{Line: end.Line, Col: clamp(last+o.N, 0, lineLength(end.Line))}
+	}
+	if o.N == 0 {
+		return start
+	}
+	return types.Position{Line: clamp(start.Line+o.N, 0, lineCount-1)}
+}
+
+func clamp(v, lo, hi int) int {
+	return max(lo, min(v, hi))
+}
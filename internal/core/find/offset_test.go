@@ -0,0 +1,79 @@
+package find
+
+import (
+	"testing"
+
+	"github.com/bethropolis/tide/internal/types"
+)
+
+func TestParseSearch(t *testing.T) {
+	tests := []struct {
+		input   string
+		pattern string
+		offset  Offset
+		wantErr bool
+	}{
+		{"foo", "foo", Offset{}, false},
+		{"foo/", "foo", Offset{}, false},
+		{"foo/e", "foo", Offset{Anchor: 'e'}, false},
+		{"foo/e-1", "foo", Offset{Anchor: 'e', N: -1}, false},
+		{"foo/b+2", "foo", Offset{Anchor: 's', N: 2}, false},
+		{"foo/s-", "foo", Offset{Anchor: 's', N: -1}, false},
+		{"foo/+1", "foo", Offset{N: 1}, false},
+		{"foo/3", "foo", Offset{N: 3}, false},
+		{"foo/-", "foo", Offset{N: -1}, false},
+		{`a\/b/e`, `a\/b`, Offset{Anchor: 'e'}, false},
+		{"/e", "", Offset{Anchor: 'e'}, false},
+		{"foo/x", "foo", Offset{}, true},
+		{"foo/e2", "foo", Offset{}, true},
+	}
+	for _, tt := range tests {
+		pattern, off, err := ParseSearch(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseSearch(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if err == nil && (pattern != tt.pattern || off != tt.offset) {
+			t.Errorf("ParseSearch(%q) = %q, %+v; want %q, %+v", tt.input, pattern, off, tt.pattern, tt.offset)
+		}
+	}
+}
+
+func TestOffsetApply(t *testing.T) {
+	lines := []string{"hello world", "foo", "bar"}
+	lineLength := func(line int) int { return len(lines[line]) }
+	start := types.Position{Line: 0, Col: 6}
+	end := types.Position{Line: 0, Col: 11} // "world"
+
+	tests := []struct {
+		offset Offset
+		want   types.Position
+	}{
+		{Offset{}, start},
+		{Offset{Anchor: 'e'}, types.Position{Line: 0, Col: 10}},
+		{Offset{Anchor: 'e', N: -2}, types.Position{Line: 0, Col: 8}},
+		{Offset{Anchor: 'e', N: 5}, types.Position{Line: 0, Col: 11}}, // Clamped to the line
+		{Offset{Anchor: 's', N: 1}, types.Position{Line: 0, Col: 7}},
+		{Offset{Anchor: 's', N: -10}, types.Position{Line: 0, Col: 0}},
+		{Offset{N: 1}, types.Position{Line: 1, Col: 0}},
+		{Offset{N: 9}, types.Position{Line: 2, Col: 0}},
+		{Offset{N: -1}, types.Position{Line: 0, Col: 0}},
+	}
+	for _, tt := range tests {
+		if got := tt.offset.Apply(start, end, len(lines), lineLength); got != tt.want {
+			t.Errorf("%+v.Apply() = %v, want %v", tt.offset, got, tt.want)
+		}
+	}
+}
+
+func TestOffsetString(t *testing.T) {
+	for _, s := range []string{"", "e", "e-1", "s+2", "+3", "-1"} {
+		off, err := ParseOffset(s)
+		if err != nil {
+			t.Fatalf("ParseOffset(%q): %v", s, err)
+		}
+		if got := off.String(); got != s {
+			t.Errorf("ParseOffset(%q).String() = %q", s, got)
+		}
+	}
+}
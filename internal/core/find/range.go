@@ -0,0 +1,128 @@
+package find
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// LineRange is a span of buffer lines, zero-based and inclusive, as given
+// before an Ex command such as ":1,20s".
+type LineRange struct {
+	Start, End int
+}
+
+// RangeContext supplies what the addresses of a line range refer to.
+type RangeContext struct {
+	Cursor   int // Line of "."
+	LastLine int // Line of "$"
+	// Clamp takes lines past the end as the last line instead of
+	// rejecting them, as Vim does for a bare ":N".
+	Clamp bool
+	// Mark returns the line of mark r ("'<" and "'>" for the visual
+	// selection), and false when it is not set.
+	Mark func(r rune) (int, bool)
+}
+
+// ParseLineRange reads the line range at the start of cmd: "%" (every
+// line) or one or two addresses separated by ',', each a line number, "."
+// (the cursor line), "$" (the last line) or "'x" (mark x), followed by
+// any number of "+N"/"-N" offsets; an address of offsets only counts from
+// the cursor line. Line numbers are one-based, as typed; the returned
+// range is zero-based, with a backwards range swapped. ok is false when
+// cmd does not start with a range, and rest is what follows it.
+func ParseLineRange(cmd string, ctx RangeContext) (r LineRange, rest string, ok bool, err error) {
+	if cmd == "" {
+		return r, cmd, false, nil
+	}
+	if cmd[0] == '%' {
+		return LineRange{Start: 0, End: ctx.LastLine}, cmd[1:], true, nil
+	}
+
+	start, rest, ok, err := parseAddress(cmd, ctx)
+	if err != nil || !ok {
+		return r, cmd, false, err
+	}
+	end := start
+	if rest != "" && rest[0] == ',' {
+		end, rest, ok, err = parseAddress(rest[1:], ctx)
+		if err != nil {
+			return r, cmd, false, err
+		}
+		if !ok {
+			return r, cmd, false, fmt.Errorf("missing address after ','")
+		}
+	}
+	if start > end {
+		start, end = end, start
+	}
+	if ctx.Clamp && end > ctx.LastLine {
+		end = ctx.LastLine
+		start = min(start, end)
+	}
+	if start < 0 || end > ctx.LastLine {
+		return r, cmd, false, fmt.Errorf("invalid range: lines %d to %d, buffer has %d", start+1, end+1, ctx.LastLine+1)
+	}
+	return LineRange{Start: start, End: end}, rest, true, nil
+}
+
+// parseAddress reads one address of a line range, returning its
+// zero-based line.
+func parseAddress(s string, ctx RangeContext) (line int, rest string, ok bool, err error) {
+	rest = s
+	switch {
+	case rest == "":
+		return 0, s, false, nil
+	case rest[0] == '.':
+		line, rest, ok = ctx.Cursor, rest[1:], true
+	case rest[0] == '$':
+		line, rest, ok = ctx.LastLine, rest[1:], true
+	case rest[0] == '\'':
+		if len(rest) < 2 {
+			return 0, s, false, fmt.Errorf("missing mark name after '")
+		}
+		mark := rune(rest[1])
+		var set bool
+		if ctx.Mark != nil {
+			line, set = ctx.Mark(mark)
+		}
+		if !set {
+			return 0, s, false, fmt.Errorf("mark not set: '%c", mark)
+		}
+		rest, ok = rest[2:], true
+	case isDigit(rest[0]):
+		n, tail := leadingNumber(rest)
+		line, rest, ok = n-1, tail, true
+		if n == 0 {
+			line = 0 // Vim reads line 0 as the first line in a range
+		}
+	case rest[0] == '+' || rest[0] == '-':
+		line = ctx.Cursor
+	default:
+		return 0, s, false, nil
+	}
+
+	for rest != "" && (rest[0] == '+' || rest[0] == '-') {
+		sign := 1
+		if rest[0] == '-' {
+			sign = -1
+		}
+		n, tail := leadingNumber(rest[1:])
+		if tail == rest[1:] {
+			n = 1 // a bare "+" or "-" means one line
+		}
+		line += sign * n
+		rest, ok = tail, true
+	}
+	return line, rest, ok, nil
+}
+
+// leadingNumber parses the decimal digits at the start of s.
+func leadingNumber(s string) (int, string) {
+	digits, rest := leadingDigits(s)
+	n, _ := strconv.Atoi(digits)
+	return n, rest
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
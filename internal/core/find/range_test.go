@@ -0,0 +1,77 @@
+package find
+
+import "testing"
+
+func TestParseLineRange(t *testing.T) {
+	ctx := RangeContext{
+		Cursor:   4,
+		LastLine: 49,
+		Mark: func(r rune) (int, bool) {
+			switch r {
+			case '<':
+				return 9, true
+			case '>':
+				return 14, true
+			}
+			return 0, false
+		},
+	}
+	tests := []struct {
+		cmd     string
+		want    LineRange
+		rest    string
+		ok      bool
+		wantErr bool
+	}{
+		{"%s/a/b/", LineRange{0, 49}, "s/a/b/", true, false},
+		{"1,20s/a/b/", LineRange{0, 19}, "s/a/b/", true, false},
+		{"7s/a/b/", LineRange{6, 6}, "s/a/b/", true, false},
+		{".,$s/a/b/", LineRange{4, 49}, "s/a/b/", true, false},
+		{".,+3s/a/b/", LineRange{4, 7}, "s/a/b/", true, false},
+		{"-,.s/a/b/", LineRange{3, 4}, "s/a/b/", true, false},
+		{"$-2,$s/a/b/", LineRange{47, 49}, "s/a/b/", true, false},
+		{"'<,'>s/a/b/", LineRange{9, 14}, "s/a/b/", true, false},
+		{"'<+1,'>-1s/a/b/", LineRange{10, 13}, "s/a/b/", true, false},
+		{"20,10s/a/b/", LineRange{9, 19}, "s/a/b/", true, false},
+		{"0,3s/a/b/", LineRange{0, 2}, "s/a/b/", true, false},
+		{"s/a/b/", LineRange{}, "s/a/b/", false, false},
+		{"w", LineRange{}, "w", false, false},
+		{"", LineRange{}, "", false, false},
+		{"1,60s/a/b/", LineRange{}, "", false, true},
+		{"'a,.s/a/b/", LineRange{}, "", false, true},
+		{"1,s/a/b/", LineRange{}, "", false, true},
+		{"999", LineRange{}, "", false, true},
+	}
+	for _, tt := range tests {
+		r, rest, ok, err := ParseLineRange(tt.cmd, ctx)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseLineRange(%q) error = %v, wantErr %v", tt.cmd, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if r != tt.want || rest != tt.rest || ok != tt.ok {
+			t.Errorf("ParseLineRange(%q) = %+v, %q, %v; want %+v, %q, %v", tt.cmd, r, rest, ok, tt.want, tt.rest, tt.ok)
+		}
+	}
+}
+
+func TestParseLineRangeClamp(t *testing.T) {
+	ctx := RangeContext{Cursor: 4, LastLine: 19, Clamp: true}
+	tests := []struct {
+		cmd  string
+		want LineRange
+	}{
+		{"999", LineRange{19, 19}},
+		{"15,999", LineRange{14, 19}},
+		{"$+5", LineRange{19, 19}},
+		{"7", LineRange{6, 6}},
+	}
+	for _, tt := range tests {
+		r, _, ok, err := ParseLineRange(tt.cmd, ctx)
+		if err != nil || !ok || r != tt.want {
+			t.Errorf("ParseLineRange(%q) = %+v, %v, %v; want %+v", tt.cmd, r, ok, err, tt.want)
+		}
+	}
+}
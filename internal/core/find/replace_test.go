@@ -0,0 +1,72 @@
+package find
+
+import (
+	"testing"
+
+	"github.com/bethropolis/tide/internal/buffer"
+	"github.com/bethropolis/tide/internal/core/history"
+	"github.com/bethropolis/tide/internal/types"
+)
+
+func TestReplaceUndoesInOneStep(t *testing.T) {
+	for _, global := range []bool{true, false} {
+		buf := buffer.NewSliceBuffer()
+		if _, err := buf.Insert(types.Position{}, []byte("a a a\nb")); err != nil {
+			t.Fatal(err)
+		}
+		ed := &testEditor{buf: buf}
+		ed.hist = history.NewManager(ed, 0)
+		m := NewManager(ed)
+
+		if _, err := m.Replace("a", "xy", global, false); err != nil {
+			t.Fatal(err)
+		}
+		replaced := string(buf.Bytes())
+
+		if ok, err := ed.hist.Undo(); !ok || err != nil {
+			t.Fatalf("global=%v: Undo = %v, %v", global, ok, err)
+		}
+		if got := string(buf.Bytes()); got != "a a a\nb" {
+			t.Errorf("global=%v: after undo text = %q", global, got)
+		}
+		if ed.hist.CanUndo() {
+			t.Errorf("global=%v: replace took more than one undo step", global)
+		}
+
+		if ok, err := ed.hist.Redo(); !ok || err != nil {
+			t.Fatalf("global=%v: Redo = %v, %v", global, ok, err)
+		}
+		if got := string(buf.Bytes()); got != replaced {
+			t.Errorf("global=%v: after redo text = %q, want %q", global, got, replaced)
+		}
+	}
+}
+
+func TestBeginGroupNests(t *testing.T) {
+	buf := buffer.NewSliceBuffer()
+	if _, err := buf.Insert(types.Position{}, []byte("a\na")); err != nil {
+		t.Fatal(err)
+	}
+	ed := &testEditor{buf: buf}
+	ed.hist = history.NewManager(ed, 0)
+	m := NewManager(ed)
+
+	ed.hist.BeginGroup(types.Position{})
+	for line := 0; line < 2; line++ {
+		ed.cursor = types.Position{Line: line}
+		if _, err := m.Replace("a", "b", true, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ed.hist.EndGroup()
+
+	if ok, _ := ed.hist.Undo(); !ok {
+		t.Fatal("nothing to undo")
+	}
+	if got := string(buf.Bytes()); got != "a\na" {
+		t.Errorf("after undo text = %q", got)
+	}
+	if ed.hist.CanUndo() {
+		t.Error("nested replaces took more than one undo step")
+	}
+}
@@ -0,0 +1,189 @@
+package find
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"unicode/utf8"
+
+	"github.com/bethropolis/tide/internal/core/history"
+	"github.com/bethropolis/tide/internal/event"
+	"github.com/bethropolis/tide/internal/types"
+)
+
+// Substitution steps through the matches of a substitute over a line range
+// one at a time, for the confirm ('c') flag: Next finds a match, then
+// Replace or Skip deals with it. Each replacement is recorded
+// individually; wrap the whole walk in Begin/EndTransaction for atomic undo.
+type Substitution struct {
+	m        *Manager
+	re       *regexp.Regexp
+	template string
+	global   bool
+	endLine  int
+
+	line, col int   // Where the next match is looked for (col in bytes)
+	match     []int // Submatch indices of the current match in line, nil if none
+	lineBytes []byte
+
+	Count int // Matches replaced so far
+}
+
+// NewSubstitution prepares to replace pattern with replacement on lines
+// [startLine, endLine]: every match on each line with global, otherwise
+// the first.
+func (m *Manager) NewSubstitution(patternStr, replacement string, startLine, endLine int, global, caseInsensitive bool) (*Substitution, error) {
+	if patternStr == "" {
+		return nil, fmt.Errorf("search pattern cannot be empty")
+	}
+	flags := ""
+	if caseInsensitive {
+		flags = "(?i)"
+	}
+	re, err := m.compile(flags + patternStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid search pattern: %w", err)
+	}
+	lineCount := m.editor.GetBuffer().LineCount()
+	return &Substitution{
+		m:        m,
+		re:       re,
+		template: ReplacementTemplate(replacement),
+		global:   global,
+		line:     max(startLine, 0),
+		endLine:  min(endLine, lineCount-1),
+	}, nil
+}
+
+// Next finds the next match, highlights it and returns its range. It
+// returns false when there are no more matches in the range.
+func (s *Substitution) Next() (start, end types.Position, ok bool) {
+	buf := s.m.editor.GetBuffer()
+	for ; s.line <= s.endLine; s.line, s.col = s.line+1, 0 {
+		lineBytes, err := buf.Line(s.line)
+		if err != nil {
+			continue
+		}
+		for _, loc := range s.re.FindAllSubmatchIndex(lineBytes, -1) {
+			if loc[0] >= s.col {
+				s.match, s.lineBytes = loc, lineBytes
+				start = types.Position{Line: s.line, Col: byteOffsetToRuneIndex(lineBytes, loc[0])}
+				end = types.Position{Line: s.line, Col: byteOffsetToRuneIndex(lineBytes, loc[1])}
+				s.m.mutex.Lock()
+				s.m.searchHighlights = []types.HighlightRegion{{Start: start, End: end, Type: types.HighlightSearch}}
+				s.m.mutex.Unlock()
+				return start, end, true
+			}
+		}
+	}
+	s.match = nil
+	s.m.ClearHighlights()
+	return start, end, false
+}
+
+// Replacement returns what the current match would be replaced with.
+func (s *Substitution) Replacement() string {
+	if s.match == nil {
+		return ""
+	}
+	return string(s.re.Expand(nil, []byte(s.template), s.lineBytes, s.match))
+}
+
+// Skip leaves the current match as it is.
+func (s *Substitution) Skip() {
+	if s.match == nil {
+		return
+	}
+	s.advance(s.match[1], s.match[0] == s.match[1])
+}
+
+// Replace replaces the current match.
+func (s *Substitution) Replace() error {
+	if s.match == nil {
+		return nil
+	}
+	loc, lineBytes := s.match, s.lineBytes
+	text := s.re.Expand(nil, []byte(s.template), lineBytes, loc)
+	start := types.Position{Line: s.line, Col: byteOffsetToRuneIndex(lineBytes, loc[0])}
+	end := types.Position{Line: s.line, Col: byteOffsetToRuneIndex(lineBytes, loc[1])}
+	deleted := append([]byte(nil), lineBytes[loc[0]:loc[1]]...)
+	cursorBefore := s.m.editor.GetCursor()
+
+	buf := s.m.editor.GetBuffer()
+	editInfoDel, err := buf.Delete(start, end)
+	if err != nil {
+		return fmt.Errorf("replace failed during delete on line %d: %w", s.line, err)
+	}
+	editInfoIns, err := buf.Insert(start, text)
+	if err != nil {
+		return fmt.Errorf("replace failed during insert on line %d: %w", s.line, err)
+	}
+
+	newEnd := types.Position{Line: start.Line, Col: start.Col + utf8.RuneCount(text)}
+	if nl := bytes.LastIndexByte(text, '\n'); nl >= 0 {
+		newEnd = types.Position{Line: start.Line + bytes.Count(text, []byte{'\n'}), Col: utf8.RuneCount(text[nl+1:])}
+	}
+	if histMgr := s.m.editor.GetHistoryManager(); histMgr != nil {
+		histMgr.RecordChange(history.Change{
+			Type:          history.DeleteAction,
+			Text:          deleted,
+			StartPosition: start,
+			EndPosition:   end,
+			CursorBefore:  cursorBefore,
+		})
+		histMgr.RecordChange(history.Change{
+			Type:          history.InsertAction,
+			Text:          text,
+			StartPosition: start,
+			EndPosition:   newEnd,
+			CursorBefore:  start,
+		})
+	}
+	if eventMgr := s.m.editor.GetEventManager(); eventMgr != nil {
+		eventMgr.Dispatch(event.TypeBufferModified, event.BufferModifiedData{Edit: types.EditInfo{
+			StartIndex:     editInfoDel.StartIndex,
+			StartPosition:  editInfoDel.StartPosition,
+			OldEndIndex:    editInfoDel.OldEndIndex,
+			OldEndPosition: editInfoDel.OldEndPosition,
+			NewEndIndex:    editInfoIns.NewEndIndex,
+			NewEndPosition: editInfoIns.NewEndPosition,
+		}, FilePath: s.m.editor.GetBuffer().FilePath()})
+	}
+
+	// Text put in by the replacement is not searched again
+	s.Count++
+	s.endLine += newEnd.Line - start.Line
+	col := loc[0] + len(text)
+	if nl := bytes.LastIndexByte(text, '\n'); nl >= 0 {
+		col = len(text) - nl - 1
+	}
+	s.line = newEnd.Line
+	s.advance(col, loc[0] == loc[1])
+	return nil
+}
+
+// ReplaceRest replaces the current match and all the ones after it.
+func (s *Substitution) ReplaceRest() error {
+	for s.match != nil {
+		if err := s.Replace(); err != nil {
+			return err
+		}
+		s.Next()
+	}
+	return nil
+}
+
+// advance moves the search past byte offset col of the current line, or
+// on to the next line unless global. An empty match moves it one more
+// character, so the same spot is not matched again.
+func (s *Substitution) advance(col int, empty bool) {
+	s.match = nil
+	if !s.global {
+		s.line, s.col = s.line+1, 0
+		return
+	}
+	s.col = col
+	if empty {
+		s.col++
+	}
+}
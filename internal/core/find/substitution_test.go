@@ -0,0 +1,94 @@
+package find
+
+import (
+	"os"
+	"testing"
+
+	"github.com/bethropolis/tide/internal/buffer"
+	"github.com/bethropolis/tide/internal/core/history"
+	"github.com/bethropolis/tide/internal/event"
+	"github.com/bethropolis/tide/internal/logger"
+	"github.com/bethropolis/tide/internal/types"
+)
+
+func TestMain(m *testing.M) {
+	logger.Init(logger.Config{LogLevel: "error", LogFilePath: os.DevNull})
+	os.Exit(m.Run())
+}
+
+// testEditor is the least of an editor a Manager needs: a buffer and a
+// cursor, with optional history and no events.
+type testEditor struct {
+	buf    buffer.Buffer
+	cursor types.Position
+	hist   *history.Manager
+}
+
+func (e *testEditor) GetBuffer() buffer.Buffer            { return e.buf }
+func (e *testEditor) GetCursor() types.Position           { return e.cursor }
+func (e *testEditor) SetCursor(p types.Position)          { e.cursor = p }
+func (e *testEditor) GetEventManager() *event.Manager     { return nil }
+func (e *testEditor) ScrollToCursor()                     {}
+func (e *testEditor) GetHistoryManager() *history.Manager { return e.hist }
+
+func newTestManager(t *testing.T, text string) (*Manager, buffer.Buffer) {
+	t.Helper()
+	buf := buffer.NewSliceBuffer()
+	if _, err := buf.Insert(types.Position{}, []byte(text)); err != nil {
+		t.Fatal(err)
+	}
+	return NewManager(&testEditor{buf: buf}), buf
+}
+
+func TestSubstitutionConfirm(t *testing.T) {
+	m, buf := newTestManager(t, "a=1 b=2\nc=3\nd=4")
+	s, err := m.NewSubstitution(`(\w)=(\w)`, `$2=$1`, 0, 2, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// y, n, y (the next line), then q
+	answers := []rune{'y', 'n', 'y', 'q'}
+	for _, answer := range answers {
+		if _, _, ok := s.Next(); !ok {
+			t.Fatalf("ran out of matches before %c", answer)
+		}
+		switch answer {
+		case 'y':
+			if err := s.Replace(); err != nil {
+				t.Fatal(err)
+			}
+		case 'n':
+			s.Skip()
+		}
+		if answer == 'q' {
+			break
+		}
+	}
+	if got := string(buf.Bytes()); got != "1=a b=2\n3=c\nd=4" {
+		t.Errorf("text = %q", got)
+	}
+	if s.Count != 2 {
+		t.Errorf("Count = %d, want 2", s.Count)
+	}
+}
+
+func TestSubstitutionReplaceRest(t *testing.T) {
+	m, buf := newTestManager(t, "xx\nxx\nxx")
+	s, err := m.NewSubstitution("x", "yy", 1, 2, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok := s.Next(); !ok {
+		t.Fatal("no match")
+	}
+	if err := s.ReplaceRest(); err != nil {
+		t.Fatal(err)
+	}
+	if got := string(buf.Bytes()); got != "xx\nyyx\nyyx" {
+		t.Errorf("text = %q", got)
+	}
+	if _, _, ok := s.Next(); ok {
+		t.Error("match found after ReplaceRest")
+	}
+}
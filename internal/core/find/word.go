@@ -0,0 +1,103 @@
+package find
+
+import (
+	"regexp"
+	"unicode"
+	"unicode/utf8"
+)
+
+// isIdentRune reports whether r can be part of an identifier.
+func isIdentRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// WordAt returns the identifier under rune column col of line and the rune
+// column it starts at. Like Vim's '*', if the cursor is not on an identifier
+// the first one after it on the line is used.
+func WordAt(line []byte, col int) (word string, startCol int, ok bool) {
+	runes := []rune(string(line))
+	if col < 0 {
+		col = 0
+	}
+	for col < len(runes) && !isIdentRune(runes[col]) {
+		col++
+	}
+	if col >= len(runes) {
+		return "", 0, false
+	}
+	start, end := col, col
+	for start > 0 && isIdentRune(runes[start-1]) {
+		start--
+	}
+	for end < len(runes) && isIdentRune(runes[end]) {
+		end++
+	}
+	return string(runes[start:end]), start, true
+}
+
+// IdentifierAt is like WordAt but only succeeds when col is on an
+// identifier character.
+func IdentifierAt(line []byte, col int) (word string, startCol int, ok bool) {
+	runes := []rune(string(line))
+	if col < 0 || col >= len(runes) || !isIdentRune(runes[col]) {
+		return "", 0, false
+	}
+	return WordAt(line, col)
+}
+
+// WordPattern returns a regular expression matching word literally and only
+// as a whole identifier. Go's \b only understands ASCII word characters, so
+// it is added only on sides where word begins or ends with one.
+func WordPattern(word string) string {
+	pattern := regexp.QuoteMeta(word)
+	if first, _ := utf8.DecodeRuneInString(word); first < utf8.RuneSelf && isIdentRune(first) {
+		pattern = `\b` + pattern
+	}
+	if last, _ := utf8.DecodeLastRuneInString(word); last < utf8.RuneSelf && isIdentRune(last) {
+		pattern += `\b`
+	}
+	return pattern
+}
+
+// WordBefore returns the identifier characters immediately before rune column
+// col of line and the rune column they start at. word is empty when the
+// character before col is not part of an identifier.
+func WordBefore(line []byte, col int) (word string, startCol int) {
+	runes := []rune(string(line))
+	if col > len(runes) {
+		col = len(runes)
+	}
+	start := col
+	for start > 0 && isIdentRune(runes[start-1]) {
+		start--
+	}
+	return string(runes[start:col]), start
+}
+
+// CollectWords returns the distinct identifiers in lines that start with
+// prefix and are longer than it, in order of appearance. Words already in
+// seen are skipped and new ones are added to it, so several calls can build
+// one list across buffers.
+func CollectWords(lines [][]byte, prefix string, seen map[string]bool) []string {
+	var words []string
+	for _, line := range lines {
+		runes := []rune(string(line))
+		for i := 0; i < len(runes); {
+			if !isIdentRune(runes[i]) {
+				i++
+				continue
+			}
+			j := i
+			for j < len(runes) && isIdentRune(runes[j]) {
+				j++
+			}
+			word := string(runes[i:j])
+			if len(word) > len(prefix) && word[:len(prefix)] == prefix && !seen[word] {
+				seen[word] = true
+				words = append(words, word)
+			}
+			i = j
+		}
+	}
+	return words
+}
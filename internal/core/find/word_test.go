@@ -0,0 +1,72 @@
+package find
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestWordAt(t *testing.T) {
+	tests := []struct {
+		line      string
+		col       int
+		word      string
+		startCol  int
+		wantFound bool
+	}{
+		{"foo.bar(baz)", 5, "bar", 4, true},
+		{"foo.bar(baz)", 3, "bar", 4, true}, // on '.', takes the next identifier
+		{"  x_1 = 2", 0, "x_1", 2, true},
+		{"héllo wörld", 8, "wörld", 6, true},
+		{"a + ", 2, "", 0, false},
+	}
+	for _, tt := range tests {
+		word, start, ok := WordAt([]byte(tt.line), tt.col)
+		if ok != tt.wantFound || word != tt.word || start != tt.startCol {
+			t.Errorf("WordAt(%q, %d) = %q, %d, %v; want %q, %d, %v",
+				tt.line, tt.col, word, start, ok, tt.word, tt.startCol, tt.wantFound)
+		}
+	}
+}
+
+func TestWordPattern(t *testing.T) {
+	re := regexp.MustCompile(WordPattern("a.b"))
+	if re.MatchString("axb") {
+		t.Errorf("metacharacters should be escaped")
+	}
+	re = regexp.MustCompile(WordPattern("foo"))
+	if !re.MatchString("x := foo(1)") || re.MatchString("foobar") || re.MatchString("_foo") {
+		t.Errorf("pattern %q has wrong boundaries", re)
+	}
+}
+
+func TestIdentifierAt(t *testing.T) {
+	if word, start, ok := IdentifierAt([]byte("foo.bar"), 5); !ok || word != "bar" || start != 4 {
+		t.Errorf("IdentifierAt on 'bar' = %q, %d, %v", word, start, ok)
+	}
+	if _, _, ok := IdentifierAt([]byte("foo.bar"), 3); ok {
+		t.Errorf("IdentifierAt on '.' should not find a word")
+	}
+}
+
+func TestWordBefore(t *testing.T) {
+	if word, start := WordBefore([]byte("x := fooBa"), 10); word != "fooBa" || start != 5 {
+		t.Errorf("WordBefore = %q, %d", word, start)
+	}
+	if word, _ := WordBefore([]byte("foo("), 4); word != "" {
+		t.Errorf("WordBefore after '(' = %q, want empty", word)
+	}
+}
+
+func TestCollectWords(t *testing.T) {
+	seen := map[string]bool{"fo": true}
+	lines := [][]byte{[]byte("foo fo forEach"), []byte("bar foo(format)")}
+	got := CollectWords(lines, "fo", seen)
+	want := []string{"foo", "forEach", "format"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("CollectWords = %v, want %v", got, want)
+	}
+	if more := CollectWords(lines, "fo", seen); len(more) != 0 {
+		t.Errorf("second call should skip seen words, got %v", more)
+	}
+}
@@ -0,0 +1,80 @@
+// Package format pretty-prints and minifies structured text (JSON, XML and
+// SQL) for the :json, :xml and :sql commands.
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// SyntaxError reports invalid input. Line and Col are 1-based and relative
+// to the start of the formatted text; Col counts runes.
+type SyntaxError struct {
+	Line int
+	Col  int
+	Msg  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Col, e.Msg)
+}
+
+// errorAt builds a SyntaxError for the byte offset in text.
+func errorAt(text string, offset int, msg string) *SyntaxError {
+	if offset > len(text) {
+		offset = len(text)
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	before := text[:offset]
+	lineStart := strings.LastIndexByte(before, '\n') + 1
+	return &SyntaxError{
+		Line: strings.Count(before, "\n") + 1,
+		Col:  utf8.RuneCountInString(before[lineStart:]) + 1,
+		Msg:  msg,
+	}
+}
+
+// keepFinalNewline ends out with a newline if text ended with one, so
+// formatting a whole file keeps its last line terminated.
+func keepFinalNewline(text, out string) string {
+	if strings.HasSuffix(text, "\n") && !strings.HasSuffix(out, "\n") {
+		return out + "\n"
+	}
+	return out
+}
+
+// JSON re-indents a JSON document using indent for each nesting level.
+func JSON(text, indent string) (string, error) {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(strings.TrimSpace(text)), "", indent); err != nil {
+		return "", jsonError(text, err)
+	}
+	return keepFinalNewline(text, buf.String()), nil
+}
+
+// MinifyJSON removes all insignificant whitespace from a JSON document.
+func MinifyJSON(text string) (string, error) {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, []byte(strings.TrimSpace(text))); err != nil {
+		return "", jsonError(text, err)
+	}
+	return keepFinalNewline(text, buf.String()), nil
+}
+
+// jsonError converts an encoding/json error into a SyntaxError. Offsets are
+// reported against the trimmed document, so leading space is added back.
+func jsonError(text string, err error) error {
+	var syn *json.SyntaxError
+	if !errors.As(err, &syn) {
+		return err
+	}
+	lead := len(text) - len(strings.TrimLeft(text, " \t\r\n"))
+	// Offset points just past the offending byte
+	return errorAt(text, lead+int(syn.Offset)-1, syn.Error())
+}
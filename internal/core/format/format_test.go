@@ -0,0 +1,71 @@
+package format
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestJSON(t *testing.T) {
+	got, err := JSON(` {"a":[1,{"b":null}]}`, "  ")
+	want := "{\n  \"a\": [\n    1,\n    {\n      \"b\": null\n    }\n  ]\n}"
+	if err != nil || got != want {
+		t.Errorf("JSON = %q, %v; want %q", got, err, want)
+	}
+	if got, err := MinifyJSON("{\n  \"a\": [1, 2]\n}"); err != nil || got != `{"a":[1,2]}` {
+		t.Errorf("MinifyJSON = %q, %v", got, err)
+	}
+}
+
+func TestJSONErrorPosition(t *testing.T) {
+	_, err := JSON("\n{\n  \"a\": }", "  ")
+	var syn *SyntaxError
+	if !errors.As(err, &syn) || syn.Line != 3 || syn.Col != 8 {
+		t.Fatalf("err = %v; want SyntaxError at 3:8", err)
+	}
+}
+
+func TestXML(t *testing.T) {
+	got, err := XML(`<?xml version="1.0"?><a x="1&amp;2"><b> text </b><c></c><ns:d><!-- hi --></ns:d></a>`, "  ")
+	want := "<?xml version=\"1.0\"?>\n<a x=\"1&amp;2\">\n  <b>text</b>\n  <c/>\n  <ns:d>\n    <!-- hi -->\n  </ns:d>\n</a>"
+	if err != nil || got != want {
+		t.Errorf("XML =\n%s\n%v; want\n%s", got, err, want)
+	}
+	for _, bad := range []string{"<a><b></a>", "<a>", "<a x=1/>"} {
+		if _, err := XML(bad, "  "); err == nil {
+			t.Errorf("XML(%q) should fail", bad)
+		}
+	}
+}
+
+func TestSQL(t *testing.T) {
+	got, err := SQL("select a, count(*) as n from t left join u on t.id=u.id where x between 1 and 2 and y = 'it''s' group by a;", "  ")
+	want := "SELECT a,\n  count(*) AS n\nFROM t\nLEFT JOIN u ON t.id = u.id\nWHERE x BETWEEN 1 AND 2\n  AND y = 'it''s'\nGROUP BY a;"
+	if err != nil || got != want {
+		t.Errorf("SQL =\n%s\n%v; want\n%s", got, err, want)
+	}
+	for _, bad := range []string{"select (a", "select 'a", "select a)", "/* x"} {
+		if _, err := SQL(bad, "  "); err == nil {
+			t.Errorf("SQL(%q) should fail", bad)
+		}
+	}
+}
+
+func TestFinalNewline(t *testing.T) {
+	formatters := map[string]func(string) (string, error){
+		"JSON":       func(s string) (string, error) { return JSON(s, "  ") },
+		"MinifyJSON": MinifyJSON,
+		"XML":        func(s string) (string, error) { return XML(s, "  ") },
+		"SQL":        func(s string) (string, error) { return SQL(s, "  ") },
+	}
+	inputs := map[string]string{"JSON": `{"a":1}`, "MinifyJSON": `{"a": 1}`, "XML": "<a><b/></a>", "SQL": "select a from t;"}
+	for name, f := range formatters {
+		with, err := f(inputs[name] + "\n")
+		if err != nil || !strings.HasSuffix(with, "\n") || strings.HasSuffix(with, "\n\n") {
+			t.Errorf("%s with a final newline = %q, %v; want one newline at the end", name, with, err)
+		}
+		if without, err := f(inputs[name]); err != nil || strings.HasSuffix(without, "\n") {
+			t.Errorf("%s without a final newline = %q, %v", name, without, err)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package format
+
+import (
+	"strings"
+	"unicode"
+)
+
+// sqlClauses start a new line when they appear in a statement.
+var sqlClauses = map[string]bool{
+	"SELECT": true, "FROM": true, "WHERE": true, "GROUP": true, "ORDER": true,
+	"HAVING": true, "LIMIT": true, "OFFSET": true, "UNION": true, "INTERSECT": true,
+	"EXCEPT": true, "INSERT": true, "VALUES": true, "UPDATE": true, "SET": true,
+	"DELETE": true, "JOIN": true, "LEFT": true, "RIGHT": true, "INNER": true,
+	"FULL": true, "CROSS": true, "NATURAL": true, "RETURNING": true,
+}
+
+// sqlClauseModifiers keep the following clause keyword on their line
+// (LEFT JOIN, DELETE FROM, ...).
+var sqlClauseModifiers = map[string]bool{
+	"LEFT": true, "RIGHT": true, "INNER": true, "FULL": true, "CROSS": true,
+	"NATURAL": true, "OUTER": true, "DELETE": true,
+}
+
+// sqlKeywords are upper-cased on output.
+var sqlKeywords = map[string]bool{
+	"ALL": true, "AND": true, "AS": true, "ASC": true, "BETWEEN": true, "BY": true,
+	"CASE": true, "CREATE": true, "DESC": true, "DISTINCT": true, "DROP": true,
+	"ELSE": true, "END": true, "EXISTS": true, "IN": true, "INTO": true, "IS": true,
+	"LIKE": true, "NOT": true, "NULL": true, "ON": true, "OR": true, "OUTER": true,
+	"TABLE": true, "THEN": true, "WHEN": true, "WITH": true,
+}
+
+type sqlToken struct {
+	text   string
+	word   bool // identifier or keyword
+	offset int
+}
+
+// SQL lays out a SQL script with one clause per line, upper-case keywords,
+// one select-list column per line and AND/OR conditions indented beneath
+// their clause. It only checks quoting, comments and parentheses.
+func SQL(text, indent string) (string, error) {
+	tokens, err := tokenizeSQL(text)
+	if err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	depth := 0
+	var opens []int // offsets of unclosed '('
+	clause := ""
+	prev := ""
+	between := false
+	lineStart := true
+	newline := func(extra int) {
+		if out.Len() > 0 && !lineStart {
+			out.WriteByte('\n')
+			out.WriteString(strings.Repeat(indent, depth+extra))
+			lineStart = true
+		}
+	}
+
+	for i, tok := range tokens {
+		t := tok.text
+		upper := strings.ToUpper(t)
+		isKeyword := tok.word && (sqlClauses[upper] || sqlKeywords[upper])
+		if isKeyword {
+			t = upper
+		}
+
+		switch {
+		case isKeyword && sqlClauses[upper] && !sqlClauseModifiers[prev]:
+			newline(0)
+			clause = upper
+		case isKeyword && (upper == "AND" || upper == "OR") && !between:
+			newline(1)
+		case !lineStart && needsSpace(prev, tokens[i-1], tok):
+			out.WriteByte(' ')
+		}
+
+		out.WriteString(t)
+		lineStart = false
+
+		switch {
+		case t == "(":
+			depth++
+			opens = append(opens, tok.offset)
+		case t == ")":
+			if len(opens) == 0 {
+				return "", errorAt(text, tok.offset, "unmatched ')'")
+			}
+			opens = opens[:len(opens)-1]
+			depth--
+		case t == "," && clause == "SELECT" && len(opens) == 0:
+			newline(1)
+		case t == ";":
+			newline(0)
+			clause = ""
+		case strings.HasPrefix(t, "--"):
+			newline(0)
+		case upper == "BETWEEN":
+			between = true
+		case upper == "AND":
+			between = false
+		}
+		if tok.word {
+			prev = upper
+		} else {
+			prev = t
+		}
+	}
+	if len(opens) > 0 {
+		return "", errorAt(text, opens[len(opens)-1], "unclosed '('")
+	}
+	return keepFinalNewline(text, strings.TrimSpace(out.String())), nil
+}
+
+// needsSpace reports whether a space separates two adjacent tokens.
+func needsSpace(prevUpper string, prev, cur sqlToken) bool {
+	switch {
+	case prev.text == "(" || prev.text == ".":
+		return false
+	case cur.text == ")" || cur.text == "," || cur.text == ";" || cur.text == ".":
+		return false
+	case cur.text == "(" && prev.word && !sqlClauses[prevUpper] && !sqlKeywords[prevUpper]:
+		return false // function call
+	}
+	return true
+}
+
+// tokenizeSQL splits text into words, literals, comments and punctuation.
+func tokenizeSQL(text string) ([]sqlToken, error) {
+	var tokens []sqlToken
+	runes := []rune(text)
+	offsets := make([]int, len(runes)+1)
+	for i, off := 0, 0; i < len(runes); i++ {
+		offsets[i] = off
+		off += len(string(runes[i]))
+		offsets[i+1] = off
+	}
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		start := i
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			continue
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			i += 2
+			for i+1 < len(runes) && !(runes[i] == '*' && runes[i+1] == '/') {
+				i++
+			}
+			if i+1 >= len(runes) {
+				return nil, errorAt(text, offsets[start], "unterminated comment")
+			}
+			i += 2
+		case r == '\'' || r == '"' || r == '`':
+			i++
+			for {
+				if i >= len(runes) {
+					return nil, errorAt(text, offsets[start], "unterminated quoted string")
+				}
+				if runes[i] == r {
+					if i+1 < len(runes) && runes[i+1] == r { // doubled quote escape
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				i++
+			}
+		case isSQLWordRune(r):
+			for i < len(runes) && isSQLWordRune(runes[i]) {
+				i++
+			}
+		case strings.ContainsRune("<>!=|:", r) && i+1 < len(runes) && strings.ContainsRune("=>|:", runes[i+1]):
+			i += 2
+		default:
+			i++
+		}
+		tokens = append(tokens, sqlToken{
+			text:   string(runes[start:i]),
+			word:   isSQLWordRune(r) && !unicode.IsDigit(r),
+			offset: offsets[start],
+		})
+	}
+	return tokens, nil
+}
+
+func isSQLWordRune(r rune) bool {
+	return r == '_' || r == '$' || r == '@' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,131 @@
+package format
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// XML re-indents an XML document using indent for each nesting level.
+// Whitespace-only text between elements is dropped, other text is trimmed,
+// and empty elements are written self-closing.
+func XML(text, indent string) (string, error) {
+	dec := xml.NewDecoder(strings.NewReader(text))
+	dec.Strict = true
+	fail := func(msg string) error {
+		line, col := dec.InputPos()
+		return &SyntaxError{Line: line, Col: col, Msg: msg}
+	}
+
+	var out bytes.Buffer
+	var stack []string
+	pending := ""       // start tag not yet written, so it can self-close
+	inlineText := false // text follows the open tag on the same line
+	newline := func() {
+		if out.Len() > 0 {
+			out.WriteByte('\n')
+		}
+		out.WriteString(strings.Repeat(indent, len(stack)))
+	}
+	flush := func() {
+		if pending != "" {
+			out.WriteString(pending + ">")
+			pending = ""
+		}
+	}
+
+	for {
+		tok, err := dec.RawToken()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var syn *xml.SyntaxError
+			if errors.As(err, &syn) {
+				return "", fail(syn.Msg)
+			}
+			return "", fail(err.Error())
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			flush()
+			newline()
+			var b strings.Builder
+			b.WriteString("<" + rawName(t.Name))
+			for _, a := range t.Attr {
+				b.WriteString(" " + rawName(a.Name) + `="`)
+				xml.EscapeText(&b, []byte(a.Value))
+				b.WriteString(`"`)
+			}
+			pending = b.String()
+			stack = append(stack, rawName(t.Name))
+			inlineText = false
+		case xml.EndElement:
+			name := rawName(t.Name)
+			if len(stack) == 0 {
+				return "", fail(fmt.Sprintf("unexpected end element </%s>", name))
+			}
+			if open := stack[len(stack)-1]; open != name {
+				return "", fail(fmt.Sprintf("element <%s> closed by </%s>", open, name))
+			}
+			stack = stack[:len(stack)-1]
+			switch {
+			case pending != "":
+				out.WriteString(pending + "/>")
+				pending = ""
+			case inlineText:
+				out.WriteString("</" + name + ">")
+			default:
+				newline()
+				out.WriteString("</" + name + ">")
+			}
+			inlineText = false
+		case xml.CharData:
+			trimmed := bytes.TrimSpace(t)
+			if len(trimmed) == 0 {
+				continue
+			}
+			if pending != "" {
+				flush()
+				inlineText = true
+			} else {
+				newline()
+				inlineText = false
+			}
+			xml.EscapeText(&out, trimmed)
+		case xml.Comment:
+			flush()
+			newline()
+			out.WriteString("<!--" + string(t) + "-->")
+			inlineText = false
+		case xml.ProcInst:
+			flush()
+			newline()
+			out.WriteString("<?" + t.Target)
+			if len(t.Inst) > 0 {
+				out.WriteString(" " + string(t.Inst))
+			}
+			out.WriteString("?>")
+		case xml.Directive:
+			flush()
+			newline()
+			out.WriteString("<!" + string(t) + ">")
+		}
+	}
+	if len(stack) > 0 {
+		return "", fail(fmt.Sprintf("unclosed element <%s>", stack[len(stack)-1]))
+	}
+	return keepFinalNewline(text, out.String()), nil
+}
+
+// rawName joins a namespace prefix kept by RawToken with the local name.
+func rawName(n xml.Name) string {
+	if n.Space == "" {
+		return n.Local
+	}
+	return n.Space + ":" + n.Local
+}
@@ -0,0 +1,121 @@
+// Package grep searches files for regular expression matches. It backs
+// project-wide commands such as :S.
+package grep
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// IgnoredDirs are directory names that Search never descends into.
+var IgnoredDirs = map[string]bool{
+	".git":         true,
+	"node_modules": true,
+	"vendor":       true,
+}
+
+// MaxFileSize is the largest file Search will read.
+const MaxFileSize = 4 << 20
+
+// binarySniffLen is how much of a file is checked for NUL bytes.
+const binarySniffLen = 8000
+
+// Match is one line containing at least one match.
+type Match struct {
+	Path  string // File path (root joined with the relative path)
+	Line  int    // 0-based line index
+	Col   int    // Byte offset of the first match in the line
+	Count int    // Number of matches on the line
+	Text  string // The line, without its newline
+}
+
+// Search walks root and returns every line of every text file that matches
+// re, in walk order. Directories named in IgnoredDirs, binary
+// files and files over MaxFileSize are skipped. When skip returns true for a
+// path that file is not read (callers use this to search open buffers
+// instead). Unreadable files are ignored.
+func Search(root string, re *regexp.Regexp, skip func(path string) bool) ([]Match, error) {
+	var matches []Match
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if path != root && IgnoredDirs[info.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() || info.Size() > MaxFileSize {
+			return nil
+		}
+		if skip != nil && skip(path) {
+			return nil
+		}
+		found, err := SearchFile(path, re)
+		if err == nil {
+			matches = append(matches, found...)
+		}
+		return nil
+	})
+	return matches, err
+}
+
+// SearchFile returns the matching lines of a single file. Binary files
+// yield no matches.
+func SearchFile(path string, re *regexp.Regexp) ([]Match, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	head, err := r.Peek(binarySniffLen)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, err
+	}
+	if IsBinary(head) {
+		return nil, nil
+	}
+
+	var lines [][]byte
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), MaxFileSize)
+	for scanner.Scan() {
+		lines = append(lines, append([]byte(nil), scanner.Bytes()...))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return SearchLines(path, lines, re), nil
+}
+
+// IsBinary reports whether a file starting with head is binary: whether
+// its first few kilobytes hold a NUL byte.
+func IsBinary(head []byte) bool {
+	return bytes.IndexByte(head[:min(len(head), binarySniffLen)], 0) >= 0
+}
+
+// SearchLines returns the matching lines among lines, reported as path.
+func SearchLines(path string, lines [][]byte, re *regexp.Regexp) []Match {
+	var matches []Match
+	for i, line := range lines {
+		locs := re.FindAllIndex(line, -1)
+		if len(locs) == 0 {
+			continue
+		}
+		matches = append(matches, Match{
+			Path:  path,
+			Line:  i,
+			Col:   locs[0][0],
+			Count: len(locs),
+			Text:  string(bytes.TrimRight(line, "\r")),
+		})
+	}
+	return matches
+}
@@ -0,0 +1,53 @@
+package grep
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+func TestSearch(t *testing.T) {
+	root := t.TempDir()
+	write := func(rel, content string) {
+		path := filepath.Join(root, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("a.go", "foo := 1\nbar\nfoo(foo)\n")
+	write("sub/b.txt", "no match\nfood\n")
+	write(".git/config", "foo\n")
+	write("bin.dat", "foo\x00bar")
+	write("skipped.go", "foo\n")
+
+	re := regexp.MustCompile("foo")
+	skip := func(path string) bool { return filepath.Base(path) == "skipped.go" }
+	matches, err := Search(root, re, skip)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type key struct {
+		rel   string
+		line  int
+		count int
+	}
+	var got []key
+	for _, m := range matches {
+		rel, _ := filepath.Rel(root, m.Path)
+		got = append(got, key{rel, m.Line, m.Count})
+	}
+	want := []key{{"a.go", 0, 1}, {"a.go", 2, 2}, {filepath.Join("sub", "b.txt"), 1, 1}}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("match %d: expected %v, got %v", i, want[i], got[i])
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/bethropolis/tide/internal/buffer"
 	"github.com/bethropolis/tide/internal/event"
 	hl "github.com/bethropolis/tide/internal/highlighter"
+	"github.com/bethropolis/tide/internal/highlighter/lang"
 	"github.com/bethropolis/tide/internal/logger"
 	"github.com/bethropolis/tide/internal/types"
 	sitter "github.com/smacker/go-tree-sitter"
@@ -29,15 +30,17 @@ type Manager struct {
 	editor           EditorInterface
 	highlighter      *hl.Highlighter
 	eventManager     *event.Manager // dispatches TypeHighlightComplete; may be nil
-	mutex            sync.RWMutex   // Protects syntaxHighlights, syntaxTree
+	mutex            sync.RWMutex   // Protects syntaxHighlights, syntaxTree, definitions
 	debMutex         sync.Mutex     // Protects debouncer state (timer, pending*, isRunning)
 	timer            *time.Timer
 	pendingCtx       context.Context
 	cancelFunc       context.CancelFunc
 	isRunning        bool
+	fullPending      bool // Rehighlight asked for a parse from scratch
 	pendingEdits     []types.EditInfo
 	syntaxHighlights hl.HighlightResult
 	syntaxTree       *sitter.Tree
+	definitions      []hl.Definition // Function and class extents in syntaxTree
 }
 
 // NewManager creates a new highlight manager.
@@ -86,6 +89,26 @@ func (m *Manager) AccumulateEdit(edit types.EditInfo) {
 	m.timer = time.AfterFunc(DebounceHighlightDuration, m.runHighlightUpdate)
 }
 
+// Rehighlight parses the whole buffer again in the background, without
+// waiting for the debounce, and dispatches TypeHighlightComplete when the
+// highlights are ready. It is used when a buffer is first shown, so the
+// screen can be drawn before a large file has been parsed.
+func (m *Manager) Rehighlight() {
+	m.debMutex.Lock()
+	m.fullPending = true
+	if m.timer != nil {
+		m.timer.Stop()
+		m.timer = nil
+	}
+	if m.cancelFunc != nil {
+		m.cancelFunc()
+	}
+	m.pendingCtx, m.cancelFunc = context.WithCancel(context.Background())
+	m.debMutex.Unlock()
+
+	m.runHighlightUpdate()
+}
+
 // shiftHighlights adjusts the current cached highlights synchronously.
 // This prevents highlights below an inserted/deleted line from appearing
 // out-of-sync for the duration of the debounce.
@@ -141,7 +164,7 @@ func (m *Manager) runHighlightUpdate() {
 		m.debMutex.Unlock()
 		return
 	}
-	if len(m.pendingEdits) == 0 {
+	if len(m.pendingEdits) == 0 && !m.fullPending {
 		logger.DebugTagf("highlight", "HighlightManager: No pending edits, skipping highlight run.")
 		m.debMutex.Unlock()
 		return
@@ -151,6 +174,8 @@ func (m *Manager) runHighlightUpdate() {
 	ctx := m.pendingCtx // Capture context
 	m.pendingCtx = nil
 	m.cancelFunc = nil
+	full := m.fullPending
+	m.fullPending = false
 
 	// --- Capture Edits ---
 	editsToProcess := make([]types.EditInfo, len(m.pendingEdits))
@@ -169,16 +194,24 @@ func (m *Manager) runHighlightUpdate() {
 
 	// --- Start Background Goroutine ---
 	// Pass the snapshot []byte instead of the buffer interface
-	go func(snapshot []byte, fp string, edits []types.EditInfo, taskCtx context.Context) {
+	go func(snapshot []byte, fp string, edits []types.EditInfo, taskCtx context.Context, full bool) {
 		defer func() {
 			m.debMutex.Lock()
 			m.isRunning = false
 			logger.DebugTagf("highlight", "HighlightManager: Background highlight task finished.")
+			// A Rehighlight that came in while this task ran still has to run
+			rerun := m.fullPending && m.timer == nil
 			m.debMutex.Unlock()
+			if rerun {
+				m.runHighlightUpdate()
+			}
 		}()
 
 		// --- Get Old Tree and Apply Edits ---
-		oldTree := m.GetCurrentTree() // Get tree safely
+		var oldTree *sitter.Tree
+		if !full {
+			oldTree = m.GetCurrentTree() // Get tree safely
+		}
 		if oldTree != nil {
 			for _, edit := range edits {
 				inputEdit := sitter.EditInput{
@@ -219,7 +252,7 @@ func (m *Manager) runHighlightUpdate() {
 		m.UpdateHighlights(newHighlights, newTree)
 		m.notifyComplete()
 
-	}(snapshotBytes, filePath, editsToProcess, ctx) // <<< Pass snapshotBytes
+	}(snapshotBytes, filePath, editsToProcess, ctx, full) // <<< Pass snapshotBytes
 }
 
 // Shutdown cancels any pending/running tasks.
@@ -254,6 +287,7 @@ func (m *Manager) UpdateHighlights(newHighlights hl.HighlightResult, newTree *si
 	}
 	m.syntaxHighlights = newHighlights
 	m.syntaxTree = newTree
+	m.definitions = hl.Definitions(newTree, lang.GetForFile(m.editor.FilePath()))
 	logger.DebugTagf("highlight", "HighlightManager state updated. Tree: %p", newTree)
 }
 
@@ -277,6 +311,14 @@ func (m *Manager) GetCurrentTree() *sitter.Tree {
 	return m.syntaxTree
 }
 
+// Definitions returns the function and class definitions found in the
+// current syntax tree, ordered by start line (thread-safe).
+func (m *Manager) Definitions() []hl.Definition {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.definitions
+}
+
 // ClearHighlights explicitly clears the syntax highlighting state.
 func (m *Manager) ClearHighlights() {
 	m.UpdateHighlights(make(hl.HighlightResult), nil)
@@ -346,3 +388,46 @@ func (m *Manager) GetLocalSymbols() []string {
 	}
 	return out
 }
+
+// MatchingBracket returns where the bracket token starting at at pairs up
+// in the current syntax tree: the sibling of the opposite kind, skipping
+// nested pairs of the same kind. Brackets inside strings and comments are
+// not tokens, so ok is false for them, as it is without a tree or when no
+// bracket token starts at at.
+func (m *Manager) MatchingBracket(at sitter.Point, bracket, partner rune, opens bool) (sitter.Point, bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	if m.syntaxTree == nil {
+		return sitter.Point{}, false
+	}
+	// The bracket is an unnamed child of the smallest named node around it
+	parent := m.syntaxTree.RootNode().NamedDescendantForPointRange(at, at)
+	if parent == nil {
+		return sitter.Point{}, false
+	}
+	count := int(parent.ChildCount())
+	index := -1
+	for i := 0; i < count; i++ {
+		if child := parent.Child(i); child.Type() == string(bracket) && child.StartPoint() == at {
+			index = i
+			break
+		}
+	}
+	step := 1
+	if !opens {
+		step = -1
+	}
+	depth := 0
+	for i := index + step; index >= 0 && i >= 0 && i < count; i += step {
+		switch parent.Child(i).Type() {
+		case string(bracket):
+			depth++
+		case string(partner):
+			if depth == 0 {
+				return parent.Child(i).StartPoint(), true
+			}
+			depth--
+		}
+	}
+	return sitter.Point{}, false
+}
@@ -0,0 +1,98 @@
+package history
+
+import (
+	"bytes"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultCoalesceWindow is how soon after one change the next must come to
+// join its undo step.
+const DefaultCoalesceWindow = time.Second
+
+// Seal ends the current burst of typing, so the next change starts a new
+// undo step even if it carries straight on from the last one. Call it when
+// the cursor is moved or the mode changes.
+func (m *Manager) Seal() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.sealed = true
+}
+
+// SetCoalesceWindow sets how close together changes must be to coalesce;
+// zero or less records every change as its own undo step.
+func (m *Manager) SetCoalesceWindow(d time.Duration) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.coalesceWindow = d
+}
+
+// coalesce merges change into the last recorded one when it continues it
+// soon enough: typing on from where the last insertion ended, or deleting
+// on from where the last deletion was, on the same line. A word typed after
+// whitespace starts a new step, so undo goes back a word at a time. It
+// returns false when change has to be recorded on its own. The caller holds
+// the mutex.
+func (m *Manager) coalesce(change Change) bool {
+	now := m.now()
+	defer func() {
+		m.lastRecorded = now
+		m.sealed = false
+	}()
+
+	if m.sealed || m.coalesceWindow <= 0 || now.Sub(m.lastRecorded) > m.coalesceWindow ||
+		len(m.changes) == 0 || m.currentIndex != len(m.changes) {
+		return false
+	}
+	merged, ok := mergeChanges(m.changes[len(m.changes)-1], change)
+	if ok {
+		m.changes[len(m.changes)-1] = merged
+	}
+	return ok
+}
+
+// mergeChanges returns last and next as one change, if next continues last.
+func mergeChanges(last, next Change) (Change, bool) {
+	if last.Type != next.Type || last.StartPosition.Line != next.StartPosition.Line ||
+		bytes.IndexByte(last.Text, '\n') >= 0 || bytes.IndexByte(next.Text, '\n') >= 0 {
+		return last, false
+	}
+
+	switch last.Type {
+	case InsertAction:
+		if next.StartPosition != last.EndPosition || startsWord(last.Text, next.Text) {
+			return last, false
+		}
+		last.Text = concat(last.Text, next.Text)
+		last.EndPosition = next.EndPosition
+	case DeleteAction:
+		switch {
+		case next.EndPosition == last.StartPosition: // Backspace
+			last.Text = concat(next.Text, last.Text)
+			last.StartPosition = next.StartPosition
+		case next.StartPosition == last.StartPosition: // Delete
+			last.Text = concat(last.Text, next.Text)
+			last.EndPosition.Col += next.EndPosition.Col - next.StartPosition.Col
+		default:
+			return last, false
+		}
+	default:
+		return last, false
+	}
+	return last, true
+}
+
+// startsWord reports whether next begins a word after the whitespace that
+// before ends with.
+func startsWord(before, next []byte) bool {
+	last, _ := utf8.DecodeLastRune(before)
+	first, _ := utf8.DecodeRune(next)
+	return unicode.IsSpace(last) && !unicode.IsSpace(first)
+}
+
+// concat returns a new slice holding a followed by b.
+func concat(a, b []byte) []byte {
+	out := make([]byte, 0, len(a)+len(b))
+	return append(append(out, a...), b...)
+}
@@ -0,0 +1,124 @@
+package history
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bethropolis/tide/internal/buffer"
+	"github.com/bethropolis/tide/internal/event"
+	"github.com/bethropolis/tide/internal/logger"
+	"github.com/bethropolis/tide/internal/types"
+)
+
+func TestMain(m *testing.M) {
+	logger.Init(logger.Config{LogLevel: "error", LogFilePath: os.DevNull})
+	os.Exit(m.Run())
+}
+
+type testEditor struct {
+	buf    buffer.Buffer
+	cursor types.Position
+}
+
+func (e *testEditor) GetBuffer() buffer.Buffer        { return e.buf }
+func (e *testEditor) SetCursor(p types.Position)      { e.cursor = p }
+func (e *testEditor) GetEventManager() *event.Manager { return nil }
+func (e *testEditor) ScrollToCursor()                 {}
+
+// typist types into a buffer the way the editor does, recording each
+// keystroke, on a clock that only moves when told to.
+type typist struct {
+	t     *testing.T
+	ed    *testEditor
+	hist  *Manager
+	clock time.Time
+}
+
+func newTypist(t *testing.T) *typist {
+	ed := &testEditor{buf: buffer.NewSliceBuffer()}
+	tp := &typist{t: t, ed: ed, hist: NewManager(ed, 0), clock: time.Unix(0, 0)}
+	tp.hist.now = func() time.Time { return tp.clock }
+	return tp
+}
+
+func (tp *typist) typeText(text string) {
+	for _, r := range text {
+		at := tp.ed.cursor
+		if _, err := tp.ed.buf.Insert(at, []byte(string(r))); err != nil {
+			tp.t.Fatal(err)
+		}
+		end := types.Position{Line: at.Line, Col: at.Col + 1}
+		tp.hist.RecordChange(Change{Type: InsertAction, Text: []byte(string(r)), StartPosition: at, EndPosition: end, CursorBefore: at})
+		tp.ed.cursor = end
+		tp.clock = tp.clock.Add(100 * time.Millisecond)
+	}
+}
+
+func (tp *typist) backspace(n int) {
+	for i := 0; i < n; i++ {
+		end := tp.ed.cursor
+		start := types.Position{Line: end.Line, Col: end.Col - 1}
+		text := tp.ed.buf.GetText(start, end)
+		if _, err := tp.ed.buf.Delete(start, end); err != nil {
+			tp.t.Fatal(err)
+		}
+		tp.hist.RecordChange(Change{Type: DeleteAction, Text: []byte(text), StartPosition: start, EndPosition: end, CursorBefore: end})
+		tp.ed.cursor = start
+		tp.clock = tp.clock.Add(100 * time.Millisecond)
+	}
+}
+
+func (tp *typist) undo(want string) {
+	tp.t.Helper()
+	if ok, err := tp.hist.Undo(); !ok || err != nil {
+		tp.t.Fatalf("Undo = %v, %v", ok, err)
+	}
+	if got := string(tp.ed.buf.Bytes()); got != want {
+		tp.t.Errorf("after undo text = %q, want %q", got, want)
+	}
+}
+
+func TestCoalesceWords(t *testing.T) {
+	tp := newTypist(t)
+	tp.typeText("hello world")
+	tp.undo("hello ")
+	tp.undo("")
+	if tp.hist.CanUndo() {
+		t.Error("more undo steps than words")
+	}
+
+	if ok, _ := tp.hist.Redo(); !ok {
+		t.Fatal("nothing to redo")
+	}
+	tp.hist.Redo()
+	if got := string(tp.ed.buf.Bytes()); got != "hello world" {
+		t.Errorf("after redo text = %q", got)
+	}
+}
+
+func TestCoalesceBackspace(t *testing.T) {
+	tp := newTypist(t)
+	tp.typeText("abcdef")
+	tp.hist.Seal()
+	tp.backspace(3)
+	tp.undo("abcdef")
+	tp.undo("")
+}
+
+func TestCoalesceBreaks(t *testing.T) {
+	tp := newTypist(t)
+	tp.typeText("ab")
+	tp.clock = tp.clock.Add(5 * time.Second) // A pause
+	tp.typeText("cd")
+	tp.hist.Seal() // The cursor moved, or the mode changed
+	tp.typeText("ef")
+	tp.undo("abcd")
+	tp.undo("ab")
+	tp.undo("")
+
+	tp = newTypist(t)
+	tp.hist.SetCoalesceWindow(0)
+	tp.typeText("ab")
+	tp.undo("a")
+}
@@ -3,6 +3,7 @@ package history
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/bethropolis/tide/internal/buffer"
 	"github.com/bethropolis/tide/internal/event"
@@ -31,6 +32,17 @@ type Manager struct {
 	// Transaction support
 	inTransaction  bool
 	transactionBuf []Change // Accumulates sub-changes during an open transaction
+
+	// Group support (nestable transactions, see BeginGroup)
+	groupDepth  int
+	groupOwned  bool           // The outermost group opened the transaction
+	groupCursor types.Position // Cursor before the outermost group
+
+	// Coalescing of typing (see coalesce.go)
+	coalesceWindow time.Duration
+	lastRecorded   time.Time
+	sealed         bool             // The next change starts a new undo step
+	now            func() time.Time // Clock, replaced in tests
 }
 
 // NewManager creates a history manager.
@@ -39,10 +51,12 @@ func NewManager(editor EditorInterface, maxHistory int) *Manager {
 		maxHistory = DefaultMaxHistory
 	}
 	return &Manager{
-		editor:       editor,
-		changes:      make([]Change, 0, maxHistory),
-		currentIndex: 0,
-		maxHistory:   maxHistory,
+		editor:         editor,
+		changes:        make([]Change, 0, maxHistory),
+		currentIndex:   0,
+		maxHistory:     maxHistory,
+		coalesceWindow: DefaultCoalesceWindow,
+		now:            time.Now,
 	}
 }
 
@@ -73,7 +87,58 @@ func (m *Manager) EndTransaction(cursorBefore types.Position) {
 		logger.Debugf("History: EndTransaction called without matching BeginTransaction — ignored")
 		return
 	}
+	if m.groupOwned {
+		// The transaction belongs to an open group, which commits it
+		return
+	}
+	m.commitTransaction(cursorBefore)
+}
+
+// BeginGroup starts grouping the changes recorded until the matching
+// EndGroup into one undo step, like BeginTransaction, but groups nest: an
+// editing operation can group its own changes whether or not its caller
+// has opened a group or transaction around it, and only the outermost
+// EndGroup commits. cursorBefore is where undo puts the cursor back.
+func (m *Manager) BeginGroup(cursorBefore types.Position) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.groupDepth++
+	if m.groupDepth > 1 || m.inTransaction {
+		return
+	}
+	m.inTransaction = true
+	m.groupOwned = true
+	m.groupCursor = cursorBefore
+	m.transactionBuf = m.transactionBuf[:0]
+	logger.Debugf("History: Group started")
+}
+
+// EndGroup closes the group opened by the matching BeginGroup, committing
+// the transaction when it is the outermost one and opened it.
+func (m *Manager) EndGroup() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.groupDepth == 0 {
+		logger.Debugf("History: EndGroup called without matching BeginGroup — ignored")
+		return
+	}
+	m.groupDepth--
+	if m.groupDepth > 0 || !m.groupOwned {
+		return
+	}
+	m.groupOwned = false
+	if m.inTransaction {
+		m.commitTransaction(m.groupCursor)
+	}
+}
+
+// commitTransaction closes the open transaction and records its changes.
+// The caller holds the mutex.
+func (m *Manager) commitTransaction(cursorBefore types.Position) {
 	m.inTransaction = false
+	m.sealed = true
 
 	if len(m.transactionBuf) == 0 {
 		logger.Debugf("History: Empty transaction, nothing to record")
@@ -115,6 +180,11 @@ func (m *Manager) RecordChange(change Change) {
 		return
 	}
 
+	if m.coalesce(change) {
+		logger.Debugf("History: Merged change %v into the previous one. Index: %d", change.Type, m.currentIndex)
+		return
+	}
+
 	// If current index isn't at the end, truncate the redo history
 	if m.currentIndex < len(m.changes) {
 		m.changes = m.changes[:m.currentIndex]
@@ -172,6 +242,8 @@ func (m *Manager) Undo() (bool, error) {
 		return false, nil // Nothing to undo
 	}
 
+	m.sealed = true
+
 	// Get the last applied change
 	m.currentIndex--
 	changeToUndo := m.changes[m.currentIndex]
@@ -205,7 +277,7 @@ func (m *Manager) Undo() (bool, error) {
 	// Dispatch buffer modified event
 	eventMgr := m.editor.GetEventManager()
 	if eventMgr != nil {
-		eventMgr.Dispatch(event.TypeBufferModified, event.BufferModifiedData{Edit: editInfo})
+		eventMgr.Dispatch(event.TypeBufferModified, event.BufferModifiedData{Edit: editInfo, FilePath: m.editor.GetBuffer().FilePath()})
 		logger.Debugf("History: Dispatched BufferModified after Undo.")
 	}
 
@@ -222,6 +294,8 @@ func (m *Manager) Redo() (bool, error) {
 		return false, nil // Nothing to redo
 	}
 
+	m.sealed = true
+
 	// Get the next change to redo
 	changeToRedo := m.changes[m.currentIndex]
 	logger.DebugTagf("core", "History: Redoing change %d (%v)", m.currentIndex, changeToRedo.Type)
@@ -277,7 +351,7 @@ func (m *Manager) Redo() (bool, error) {
 	// Dispatch buffer modified event
 	eventMgr := m.editor.GetEventManager()
 	if eventMgr != nil {
-		eventMgr.Dispatch(event.TypeBufferModified, event.BufferModifiedData{Edit: editInfo})
+		eventMgr.Dispatch(event.TypeBufferModified, event.BufferModifiedData{Edit: editInfo, FilePath: m.editor.GetBuffer().FilePath()})
 		logger.Debugf("History: Dispatched BufferModified after Redo.")
 	}
 
@@ -296,6 +370,8 @@ func (m *Manager) Clear() {
 	m.currentIndex = 0
 	m.inTransaction = false
 	m.transactionBuf = m.transactionBuf[:0]
+	m.groupDepth = 0
+	m.groupOwned = false
 	logger.Debugf("History: Cleared.")
 }
 
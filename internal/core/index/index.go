@@ -0,0 +1,252 @@
+// Package index keeps an in-memory index of the words and symbols in the
+// files of a project. It is built in the background and updated a file at
+// a time as files are saved, and backs cross-file word completion and
+// symbol search (:symbols, :symbol).
+package index
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/bethropolis/tide/internal/core/find"
+	"github.com/bethropolis/tide/internal/core/grep"
+	"github.com/bethropolis/tide/internal/highlighter"
+	"github.com/bethropolis/tide/internal/highlighter/lang"
+	"github.com/bethropolis/tide/internal/utils"
+)
+
+// MaxFiles is the most files Build indexes, so starting the editor in a
+// huge directory such as $HOME does not read the whole disk.
+const MaxFiles = 20000
+
+// Symbol is a definition found in a project file.
+type Symbol struct {
+	Name string
+	Kind highlighter.DefinitionKind
+	Path string
+	Line int // 0-based
+	Col  int // Rune column of the name
+}
+
+// Index holds the words and symbols of the files under a root directory.
+// It is safe for concurrent use, and answers queries while it is being
+// built, from the files read so far.
+type Index struct {
+	root string
+
+	mu    sync.RWMutex
+	files map[string]*file // By path
+	words map[string]int   // Each word, with how many files have it
+	ready bool
+}
+
+// file is what the index knows of one file.
+type file struct {
+	words   []string
+	symbols []Symbol
+}
+
+// New creates an empty index of the files under root.
+func New(root string) *Index {
+	if abs, err := filepath.Abs(root); err == nil {
+		root = abs
+	}
+	return &Index{
+		root:  root,
+		files: make(map[string]*file),
+		words: make(map[string]int),
+	}
+}
+
+// Root returns the directory the index covers.
+func (ix *Index) Root() string {
+	return ix.root
+}
+
+// Build walks the root and indexes every text file, skipping the
+// directories grep skips, files over grep.MaxFileSize and binary files. It
+// takes a while in a big project, so run it on its own goroutine. It
+// returns how many files were indexed and whether MaxFiles cut it short.
+func (ix *Index) Build() (files int, truncated bool) {
+	filepath.WalkDir(ix.root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path != ix.root && grep.IgnoredDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if files >= MaxFiles {
+			truncated = true
+			return filepath.SkipAll
+		}
+		if d.Type().IsRegular() && ix.Update(path) {
+			files++
+		}
+		return nil
+	})
+
+	ix.mu.Lock()
+	ix.ready = true
+	ix.mu.Unlock()
+	return files, truncated
+}
+
+// Ready reports whether Build has finished.
+func (ix *Index) Ready() bool {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return ix.ready
+}
+
+// Update reads path again, as after it was saved, and reports whether it
+// is now indexed. Files outside the root are ignored, and ones that can no
+// longer be read, or are binary or too big, are dropped.
+func (ix *Index) Update(path string) bool {
+	path, err := filepath.Abs(path)
+	if err != nil || !ix.covers(path) {
+		return false
+	}
+	data, ok := readText(path)
+	if !ok {
+		ix.Remove(path)
+		return false
+	}
+	f := scan(path, data)
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.drop(path)
+	ix.files[path] = f
+	for _, w := range f.words {
+		ix.words[w]++
+	}
+	return true
+}
+
+// Remove drops path from the index, as after it was deleted.
+func (ix *Index) Remove(path string) {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.drop(path)
+}
+
+// drop forgets path. ix.mu must be held for writing.
+func (ix *Index) drop(path string) {
+	old := ix.files[path]
+	if old == nil {
+		return
+	}
+	for _, w := range old.words {
+		if ix.words[w]--; ix.words[w] <= 0 {
+			delete(ix.words, w)
+		}
+	}
+	delete(ix.files, path)
+}
+
+// covers reports whether path is under the root.
+func (ix *Index) covers(path string) bool {
+	rel, err := filepath.Rel(ix.root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// Words returns the indexed words longer than prefix that start with it,
+// sorted, leaving out and adding to seen.
+func (ix *Index) Words(prefix string, seen map[string]bool) []string {
+	ix.mu.RLock()
+	var words []string
+	for w := range ix.words {
+		if len(w) > len(prefix) && strings.HasPrefix(w, prefix) && !seen[w] {
+			words = append(words, w)
+		}
+	}
+	ix.mu.RUnlock()
+
+	sort.Strings(words)
+	for _, w := range words {
+		seen[w] = true
+	}
+	return words
+}
+
+// Symbols returns every indexed symbol, sorted by name and then location.
+func (ix *Index) Symbols() []Symbol {
+	return ix.collect(func(Symbol) bool { return true })
+}
+
+// Lookup returns the symbols named name, sorted by location.
+func (ix *Index) Lookup(name string) []Symbol {
+	return ix.collect(func(s Symbol) bool { return s.Name == name })
+}
+
+// collect returns the symbols keep accepts, sorted.
+func (ix *Index) collect(keep func(Symbol) bool) []Symbol {
+	ix.mu.RLock()
+	var symbols []Symbol
+	for _, f := range ix.files {
+		for _, s := range f.symbols {
+			if keep(s) {
+				symbols = append(symbols, s)
+			}
+		}
+	}
+	ix.mu.RUnlock()
+
+	sort.Slice(symbols, func(i, j int) bool {
+		a, b := symbols[i], symbols[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Line < b.Line
+	})
+	return symbols
+}
+
+// readText returns the contents of path when it is a text file small
+// enough to index.
+func readText(path string) ([]byte, bool) {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() || info.Size() > grep.MaxFileSize {
+		return nil, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || grep.IsBinary(data) {
+		return nil, false
+	}
+	return data, true
+}
+
+// scan extracts the words of a file, and its symbols when its language
+// has a grammar.
+func scan(path string, data []byte) *file {
+	lines := bytes.Split(data, []byte{'\n'})
+	f := &file{}
+	for _, w := range find.CollectWords(lines, "", make(map[string]bool)) {
+		if r, _ := utf8.DecodeRuneInString(w); !unicode.IsDigit(r) {
+			f.words = append(f.words, w)
+		}
+	}
+	for _, s := range highlighter.Symbols(data, lang.GetForFile(path)) {
+		col := s.Col
+		if s.Line < len(lines) {
+			col = utils.ByteOffsetToRuneIndex(lines[s.Line], s.Col)
+		}
+		f.symbols = append(f.symbols, Symbol{Name: s.Name, Kind: s.Kind, Path: path, Line: s.Line, Col: col})
+	}
+	return f
+}
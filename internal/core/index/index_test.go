@@ -0,0 +1,81 @@
+package index
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/bethropolis/tide/internal/highlighter"
+	"github.com/bethropolis/tide/internal/logger"
+)
+
+func TestMain(m *testing.M) {
+	cfg := logger.NewConfig()
+	cfg.LogFilePath = os.DevNull
+	logger.Init(cfg)
+	highlighter.RegisterLanguages() // The app does this when it starts its highlighter
+	os.Exit(m.Run())
+}
+
+func writeFile(t *testing.T, path, text string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIndex(t *testing.T) {
+	root := t.TempDir()
+	main := filepath.Join(root, "main.go")
+	writeFile(t, main, "package main\n\nfunc greetWorld() {}\n\ntype Greeter struct{}\n")
+	writeFile(t, filepath.Join(root, "notes.txt"), "greeting 42greet\n")
+	writeFile(t, filepath.Join(root, "node_modules", "dep.js"), "function greetIgnored() {}\n")
+	writeFile(t, filepath.Join(root, "image.bin"), "greetBinary\x00")
+
+	ix := New(root)
+	if files, truncated := ix.Build(); files != 2 || truncated || !ix.Ready() {
+		t.Fatalf("Build() = %d, %v, ready %v; want 2 files", files, truncated, ix.Ready())
+	}
+
+	seen := map[string]bool{"greeting": true}
+	if got, want := ix.Words("greet", seen), []string{"greetWorld"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Words(greet) = %q, want %q", got, want)
+	}
+	if !seen["greetWorld"] {
+		t.Error("Words did not add to seen")
+	}
+
+	want := []Symbol{
+		{Name: "Greeter", Kind: highlighter.DefinitionClass, Path: main, Line: 4, Col: 5},
+		{Name: "greetWorld", Kind: highlighter.DefinitionFunction, Path: main, Line: 2, Col: 5},
+	}
+	if got := ix.Symbols(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Symbols() = %+v, want %+v", got, want)
+	}
+
+	// Saving replaces what was known of the file
+	writeFile(t, main, "package main\n\nfunc farewell() {}\n")
+	ix.Update(main)
+	if got := ix.Lookup("greetWorld"); len(got) != 0 {
+		t.Errorf("Lookup(greetWorld) after update = %+v, want none", got)
+	}
+	if got := ix.Lookup("farewell"); len(got) != 1 || got[0].Line != 2 {
+		t.Errorf("Lookup(farewell) = %+v, want one on line 2", got)
+	}
+	if got := ix.Words("greetW", map[string]bool{}); len(got) != 0 {
+		t.Errorf("Words(greetW) after update = %q, want none", got)
+	}
+
+	ix.Remove(main)
+	if got := ix.Symbols(); len(got) != 0 {
+		t.Errorf("Symbols() after remove = %+v, want none", got)
+	}
+
+	if ix.Update(filepath.Join(filepath.Dir(root), "elsewhere.go")) {
+		t.Error("Update indexed a file outside the root")
+	}
+}
@@ -0,0 +1,78 @@
+// Package jumplist keeps the places the cursor jumped from, in the order
+// it left them, to go back and forth through them as with Vim's jump list
+// (Ctrl+O and Ctrl+I).
+package jumplist
+
+import "github.com/bethropolis/tide/internal/types"
+
+// DefaultMaxJumps is how many jumps a list keeps, as in Vim.
+const DefaultMaxJumps = 100
+
+// Jump is a place in a file: a position in a buffer with that path.
+type Jump struct {
+	Path string
+	Pos  types.Position
+}
+
+// List is a jump list. Jumps are added at the end; going back and forth
+// moves through them without dropping any, until the next jump is added.
+type List struct {
+	jumps []Jump
+	index int // Jump gone back to; len(jumps) when not going through the list
+	size  int
+}
+
+// New returns an empty list keeping up to size jumps, or DefaultMaxJumps
+// when size is not positive.
+func New(size int) *List {
+	if size <= 0 {
+		size = DefaultMaxJumps
+	}
+	return &List{size: size}
+}
+
+// Push records a jump from j. An older jump to the same line of the same
+// file is dropped, so going back does not visit the line twice.
+func (l *List) Push(j Jump) {
+	kept := l.jumps[:0]
+	for _, old := range l.jumps {
+		if old.Path != j.Path || old.Pos.Line != j.Pos.Line {
+			kept = append(kept, old)
+		}
+	}
+	l.jumps = append(kept, j)
+	if len(l.jumps) > l.size {
+		l.jumps = l.jumps[len(l.jumps)-l.size:]
+	}
+	l.index = len(l.jumps)
+}
+
+// Back returns the jump before the one gone back to last (Ctrl+O); from is
+// where the cursor is, recorded on the first step back so Forward can
+// return to it. ok is false at the oldest jump.
+func (l *List) Back(from Jump) (j Jump, ok bool) {
+	if l.index == len(l.jumps) {
+		l.Push(from)
+		l.index = len(l.jumps) - 1
+	}
+	if l.index == 0 {
+		return Jump{}, false
+	}
+	l.index--
+	return l.jumps[l.index], true
+}
+
+// Forward returns the jump after the one gone back to last (Ctrl+I). ok is
+// false when Back has not been used since the last jump was added.
+func (l *List) Forward() (j Jump, ok bool) {
+	if l.index+1 >= len(l.jumps) {
+		return Jump{}, false
+	}
+	l.index++
+	return l.jumps[l.index], true
+}
+
+// Len returns the number of jumps kept.
+func (l *List) Len() int {
+	return len(l.jumps)
+}
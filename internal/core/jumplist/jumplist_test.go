@@ -0,0 +1,68 @@
+package jumplist
+
+import (
+	"testing"
+
+	"github.com/bethropolis/tide/internal/types"
+)
+
+func at(path string, line int) Jump {
+	return Jump{Path: path, Pos: types.Position{Line: line}}
+}
+
+func TestBackAndForward(t *testing.T) {
+	l := New(0)
+	l.Push(at("a.go", 1))
+	l.Push(at("a.go", 40))
+	l.Push(at("b.go", 7))
+
+	// From where the cursor ended up, back through the jumps in turn
+	for _, want := range []Jump{at("b.go", 7), at("a.go", 40), at("a.go", 1)} {
+		if got, ok := l.Back(at("b.go", 90)); !ok || got != want {
+			t.Fatalf("Back = %v, %v; want %v", got, ok, want)
+		}
+	}
+	if _, ok := l.Back(at("a.go", 1)); ok {
+		t.Error("Back went past the oldest jump")
+	}
+
+	// Forward again, up to where Back started from
+	for _, want := range []Jump{at("a.go", 40), at("b.go", 7), at("b.go", 90)} {
+		if got, ok := l.Forward(); !ok || got != want {
+			t.Fatalf("Forward = %v, %v; want %v", got, ok, want)
+		}
+	}
+	if _, ok := l.Forward(); ok {
+		t.Error("Forward went past the newest jump")
+	}
+}
+
+func TestPushDropsSameLine(t *testing.T) {
+	l := New(0)
+	l.Push(at("a.go", 1))
+	l.Push(at("a.go", 5))
+	l.Push(at("b.go", 1))
+	l.Push(at("a.go", 1))
+	if l.Len() != 3 {
+		t.Fatalf("Len = %d; want 3", l.Len())
+	}
+	if got, _ := l.Back(at("a.go", 9)); got != at("a.go", 1) {
+		t.Errorf("Back = %v; want the newest jump to a.go:1", got)
+	}
+	if got, _ := l.Back(at("a.go", 9)); got != at("b.go", 1) {
+		t.Errorf("Back = %v; want b.go:1 before it", got)
+	}
+}
+
+func TestMaxJumps(t *testing.T) {
+	l := New(2)
+	for line := 0; line < 5; line++ {
+		l.Push(at("a.go", line))
+	}
+	if l.Len() != 2 {
+		t.Fatalf("Len = %d; want 2", l.Len())
+	}
+	if got, _ := l.Back(at("a.go", 9)); got != at("a.go", 4) {
+		t.Errorf("Back = %v; want the newest jump", got)
+	}
+}
@@ -0,0 +1,28 @@
+package core
+
+import "github.com/bethropolis/tide/internal/core/jumplist"
+
+// SetJumpList makes the editor record its jumps in l. The editors of all
+// buffers share one list, so going back can return to another file.
+func (e *Editor) SetJumpList(l *jumplist.List) {
+	e.jumps = l
+}
+
+// JumpList returns the list the editor records its jumps in.
+func (e *Editor) JumpList() *jumplist.List {
+	return e.jumps
+}
+
+// Here returns the cursor's place, as the jump list records it.
+func (e *Editor) Here() jumplist.Jump {
+	return jumplist.Jump{Path: e.buffer.FilePath(), Pos: e.GetCursor()}
+}
+
+// PushJump records the cursor's place as one jumped from, for Ctrl+O to
+// return to. Searches, gg and G, %, ]f and opening another file call it
+// before moving the cursor; moving by lines or words does not.
+func (e *Editor) PushJump() {
+	if e.jumps != nil && e.buffer != nil {
+		e.jumps.Push(e.Here())
+	}
+}
@@ -0,0 +1,25 @@
+package core
+
+// SetLineHint shows text after the end of line, dimmed, without it being
+// part of the buffer. Only one line carries a hint at a time.
+func (e *Editor) SetLineHint(line int, text string) {
+	e.ClearLineHint()
+	e.hintLine, e.hintText = line, text
+	e.MarkDirty(line)
+}
+
+// ClearLineHint removes the line hint. Returns true if there was one.
+func (e *Editor) ClearLineHint() bool {
+	if e.hintText == "" {
+		return false
+	}
+	e.MarkDirty(e.hintLine)
+	e.hintText = ""
+	return true
+}
+
+// LineHint returns the line carrying a hint and its text; ok is false when
+// there is none.
+func (e *Editor) LineHint() (line int, text string, ok bool) {
+	return e.hintLine, e.hintText, e.hintText != ""
+}
@@ -0,0 +1,27 @@
+package core
+
+// LineMark is a sign drawn in the gutter beside a line. Its value is the
+// character shown, so the mark reads without color too.
+type LineMark rune
+
+const (
+	LineAdded   LineMark = '+' // The line is new
+	LineChanged LineMark = '~' // The line was edited
+	LineRemoved LineMark = '_' // Lines were removed after this one
+)
+
+// SetLineMarks replaces the gutter signs with marks, by line. nil removes
+// them all.
+func (e *Editor) SetLineMarks(marks map[int]LineMark) {
+	if len(marks) == 0 && len(e.lineMarks) == 0 {
+		return
+	}
+	e.lineMarks = marks
+	e.MarkAllDirty()
+}
+
+// LineMark returns the gutter sign of line; ok is false when it has none.
+func (e *Editor) LineMark(line int) (mark LineMark, ok bool) {
+	mark, ok = e.lineMarks[line]
+	return mark, ok
+}
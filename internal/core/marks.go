@@ -0,0 +1,86 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/bethropolis/tide/internal/core/marks"
+	"github.com/bethropolis/tide/internal/types"
+)
+
+// Marks returns the named marks of the buffer.
+func (e *Editor) Marks() *marks.Set {
+	return e.marks
+}
+
+// MarkOnLine returns the first by name of the marks on line, drawn in the
+// gutter; ok is false when there are none.
+func (e *Editor) MarkOnLine(line int) (name rune, ok bool) {
+	if e.marks == nil {
+		return 0, false
+	}
+	return e.marks.OnLine(line)
+}
+
+// MarkPosition returns where mark name is; ok is false when it is not
+// set.
+func (e *Editor) MarkPosition(name rune) (pos types.Position, ok bool) {
+	p, ok := e.marks.Get(name)
+	if !ok {
+		return pos, false
+	}
+	return e.position(p), true
+}
+
+// PutMark sets mark name at pos, without checking the name.
+func (e *Editor) PutMark(name rune, pos types.Position) {
+	if old, ok := e.marks.Get(name); ok {
+		e.MarkDirty(int(old.Row))
+	}
+	e.marks.Put(name, e.point(pos))
+	e.MarkDirty(pos.Line)
+}
+
+// SetMark sets mark name on the cursor (ma).
+func (e *Editor) SetMark(name rune) error {
+	if !marks.Valid(name) {
+		return fmt.Errorf("invalid mark name %q (use a letter)", name)
+	}
+	e.PutMark(name, e.GetCursor())
+	return nil
+}
+
+// JumpToMark moves the cursor to mark name ('a), recording a jump.
+func (e *Editor) JumpToMark(name rune) error {
+	pos, ok := e.MarkPosition(name)
+	if !ok {
+		if !marks.Valid(name) {
+			return fmt.Errorf("invalid mark name %q (use a letter)", name)
+		}
+		return fmt.Errorf("mark %c is not set", name)
+	}
+	e.PushJump()
+	e.SetCursor(types.Position{Line: min(pos.Line, e.buffer.LineCount()-1), Col: pos.Col})
+	e.ScrollToCursor()
+	return nil
+}
+
+// DeleteMark removes mark name. Returns true if it was set.
+func (e *Editor) DeleteMark(name rune) bool {
+	p, ok := e.marks.Get(name)
+	if ok {
+		e.marks.Delete(name)
+		e.MarkDirty(int(p.Row))
+	}
+	return ok
+}
+
+// AdjustMarks moves the marks with their text after edit.
+func (e *Editor) AdjustMarks(edit types.EditInfo) {
+	e.marks.Adjust(edit, func(line int) int {
+		text, err := e.buffer.Line(line)
+		if err != nil {
+			return 0
+		}
+		return len(text)
+	})
+}
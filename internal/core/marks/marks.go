@@ -0,0 +1,119 @@
+// Package marks keeps the named marks of a buffer (ma, 'a), and moves them
+// with their text as lines are added and removed above them, as Vim does.
+package marks
+
+import (
+	"sort"
+
+	"github.com/bethropolis/tide/internal/types"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// Valid reports whether name can name a mark: a letter, a-z or A-Z.
+func Valid(name rune) bool {
+	return name >= 'a' && name <= 'z' || name >= 'A' && name <= 'Z'
+}
+
+// Set holds the marks of one buffer by name. Marks are points, their
+// columns counting bytes of the line as those of edits do, so that Adjust
+// needs no text to move them.
+type Set struct {
+	marks map[rune]sitter.Point
+}
+
+// New returns an empty set.
+func New() *Set {
+	return &Set{marks: make(map[rune]sitter.Point)}
+}
+
+// Put sets mark name at p, moving it if it was set elsewhere.
+func (s *Set) Put(name rune, p sitter.Point) {
+	s.marks[name] = p
+}
+
+// Get returns where mark name is; ok is false when it is not set.
+func (s *Set) Get(name rune) (p sitter.Point, ok bool) {
+	p, ok = s.marks[name]
+	return p, ok
+}
+
+// Delete removes mark name. Returns true if it was set.
+func (s *Set) Delete(name rune) bool {
+	_, ok := s.marks[name]
+	delete(s.marks, name)
+	return ok
+}
+
+// Clear removes every mark.
+func (s *Set) Clear() {
+	clear(s.marks)
+}
+
+// Len returns how many marks are set.
+func (s *Set) Len() int {
+	return len(s.marks)
+}
+
+// Names returns the names of the set marks in order.
+func (s *Set) Names() []rune {
+	names := make([]rune, 0, len(s.marks))
+	for name := range s.marks {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
+
+// OnLine returns the first by name of the marks on line; ok is false when
+// there are none.
+func (s *Set) OnLine(line int) (name rune, ok bool) {
+	for n, p := range s.marks {
+		if int(p.Row) == line && (!ok || n < name) {
+			name, ok = n, true
+		}
+	}
+	return name, ok
+}
+
+// Adjust moves the marks after edit to stay on their text; lineLen gives
+// the length in bytes of a line of the text as edited. Marks after the
+// edit move with the text following it. A mark in removed text is deleted
+// when its whole line was removed along with a line break (dd), and moves
+// to the start of the edit otherwise, its line joined to what is left
+// there (dw, J).
+func (s *Set) Adjust(edit types.EditInfo, lineLen func(line int) int) {
+	start, oldEnd, newEnd := edit.StartPosition, edit.OldEndPosition, edit.NewEndPosition
+	for name, p := range s.marks {
+		switch {
+		case before(p, start):
+			// Before the edit
+		case !before(p, oldEnd):
+			if p.Row == oldEnd.Row {
+				p.Column = p.Column - oldEnd.Column + newEnd.Column
+			}
+			p.Row = p.Row - oldEnd.Row + newEnd.Row
+			s.marks[name] = p
+		case lineRemoved(p.Row, start, oldEnd, newEnd, lineLen):
+			delete(s.marks, name)
+		default:
+			s.marks[name] = start
+		}
+	}
+}
+
+// before reports whether p comes before q.
+func before(p, q sitter.Point) bool {
+	return p.Row < q.Row || p.Row == q.Row && p.Column < q.Column
+}
+
+// lineRemoved reports whether an edit from start to oldEnd, whose text ends
+// at newEnd, removed all of line row and a line break next to it: the
+// edit starts at or before the start of the line, and either goes on past
+// its end or, starting on an earlier line, leaves nothing of the line
+// after it.
+func lineRemoved(row uint32, start, oldEnd, newEnd sitter.Point, lineLen func(line int) int) bool {
+	if start.Row == row && start.Column > 0 {
+		return false
+	}
+	return oldEnd.Row > row || start.Row < row && lineLen(int(newEnd.Row)) == int(newEnd.Column)
+}
@@ -0,0 +1,77 @@
+package marks
+
+import (
+	"testing"
+
+	"github.com/bethropolis/tide/internal/types"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+func edit(startRow, startCol, oldRow, oldCol, newRow, newCol uint32) types.EditInfo {
+	return types.EditInfo{
+		StartPosition:  sitter.Point{Row: startRow, Column: startCol},
+		OldEndPosition: sitter.Point{Row: oldRow, Column: oldCol},
+		NewEndPosition: sitter.Point{Row: newRow, Column: newCol},
+	}
+}
+
+// TestAdjust keeps marks on their text through line edits.
+func TestAdjust(t *testing.T) {
+	tests := []struct {
+		name   string
+		edit   types.EditInfo
+		endLen int                   // Bytes in the line the edit ends on, once made
+		want   map[rune]sitter.Point // Marks left, from a on line 2, b on 5 and c on 8
+	}{
+		{"lines added above", edit(0, 0, 0, 0, 3, 0), 10,
+			map[rune]sitter.Point{'a': {Row: 5, Column: 2}, 'b': {Row: 8, Column: 2}, 'c': {Row: 11, Column: 2}}},
+		{"line deleted (dd)", edit(5, 0, 6, 0, 5, 0), 10,
+			map[rune]sitter.Point{'a': {Row: 2, Column: 2}, 'c': {Row: 7, Column: 2}}},
+		{"last line deleted (dd)", edit(7, 6, 8, 4, 7, 6), 6,
+			map[rune]sitter.Point{'a': {Row: 2, Column: 2}, 'b': {Row: 5, Column: 2}}},
+		{"lines joined (J)", edit(4, 9, 5, 0, 4, 9), 20,
+			map[rune]sitter.Point{'a': {Row: 2, Column: 2}, 'b': {Row: 4, Column: 11}, 'c': {Row: 7, Column: 2}}},
+		{"lines joined past a mark", edit(7, 6, 8, 4, 7, 6), 12,
+			map[rune]sitter.Point{'a': {Row: 2, Column: 2}, 'b': {Row: 5, Column: 2}, 'c': {Row: 7, Column: 6}}},
+		{"text typed before a mark", edit(5, 0, 5, 0, 5, 3), 10,
+			map[rune]sitter.Point{'a': {Row: 2, Column: 2}, 'b': {Row: 5, Column: 5}, 'c': {Row: 8, Column: 2}}},
+		{"text deleted around a mark", edit(5, 1, 5, 4, 5, 1), 10,
+			map[rune]sitter.Point{'a': {Row: 2, Column: 2}, 'b': {Row: 5, Column: 1}, 'c': {Row: 8, Column: 2}}},
+		{"first word deleted (dw)", edit(5, 0, 5, 4, 5, 0), 6,
+			map[rune]sitter.Point{'a': {Row: 2, Column: 2}, 'b': {Row: 5, Column: 0}, 'c': {Row: 8, Column: 2}}},
+		{"edit below", edit(9, 0, 12, 0, 9, 0), 10,
+			map[rune]sitter.Point{'a': {Row: 2, Column: 2}, 'b': {Row: 5, Column: 2}, 'c': {Row: 8, Column: 2}}},
+	}
+	for _, tt := range tests {
+		s := New()
+		s.Put('a', sitter.Point{Row: 2, Column: 2})
+		s.Put('b', sitter.Point{Row: 5, Column: 2})
+		s.Put('c', sitter.Point{Row: 8, Column: 2})
+		s.Adjust(tt.edit, func(int) int { return tt.endLen })
+		if s.Len() != len(tt.want) {
+			t.Errorf("%s: %d marks left, want %d", tt.name, s.Len(), len(tt.want))
+		}
+		for name, want := range tt.want {
+			if got, ok := s.Get(name); !ok || got != want {
+				t.Errorf("%s: mark %c at %v (set %t), want %v", tt.name, name, got, ok, want)
+			}
+		}
+	}
+}
+
+// TestOnLine returns the first mark of a line by name.
+func TestOnLine(t *testing.T) {
+	s := New()
+	s.Put('q', sitter.Point{Row: 3})
+	s.Put('b', sitter.Point{Row: 3, Column: 4})
+	s.Put('a', sitter.Point{Row: 1})
+	if name, ok := s.OnLine(3); !ok || name != 'b' {
+		t.Errorf("OnLine(3) = %c, %t; want b", name, ok)
+	}
+	if _, ok := s.OnLine(2); ok {
+		t.Error("OnLine(2) found a mark")
+	}
+	if got := string(s.Names()); got != "abq" {
+		t.Errorf("Names = %q, want %q", got, "abq")
+	}
+}
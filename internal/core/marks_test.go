@@ -0,0 +1,51 @@
+package core
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/bethropolis/tide/internal/buffer"
+	"github.com/bethropolis/tide/internal/config"
+	"github.com/bethropolis/tide/internal/event"
+	"github.com/bethropolis/tide/internal/logger"
+	"github.com/bethropolis/tide/internal/paths"
+	"github.com/bethropolis/tide/internal/types"
+)
+
+// TestMarksNonASCII keeps marks on their character through edits of lines
+// whose characters take more than a byte.
+func TestMarksNonASCII(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv(paths.HomeEnv, home)
+	logger.Init(logger.Config{LogLevel: "error", LogFilePath: "-"})
+	config.LoadConfig(filepath.Join(home, config.DefaultConfigFileName), nil) // Defaults; the file does not exist
+
+	buf := buffer.NewSliceBuffer()
+	buf.Load("")
+	buf.Insert(types.Position{}, []byte("héllo wörld\n"))
+	e := NewEditor(buf, nil, event.NewManager())
+	e.PutMark('a', types.Position{Line: 0, Col: 8}) // The r of wörld
+	e.PutMark('b', types.Position{Line: 0, Col: 1}) // The é of héllo
+
+	edit, err := buf.Insert(types.Position{Line: 0, Col: 0}, []byte("ü"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.AdjustMarks(edit)
+	if pos, _ := e.MarkPosition('a'); pos != (types.Position{Line: 0, Col: 9}) {
+		t.Errorf("after typing ü: mark a at %v, want 0:9", pos)
+	}
+
+	// dw on the first word keeps the line, and the mark in the word with it
+	edit, err = buf.Delete(types.Position{Line: 0, Col: 0}, types.Position{Line: 0, Col: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.AdjustMarks(edit)
+	if pos, ok := e.MarkPosition('b'); !ok || pos != (types.Position{}) {
+		t.Errorf("after dw: mark b at %v (set %t), want 0:0", pos, ok)
+	}
+	if pos, _ := e.MarkPosition('a'); pos != (types.Position{Line: 0, Col: 2}) {
+		t.Errorf("after dw: mark a at %v, want 0:2", pos)
+	}
+}
@@ -0,0 +1,94 @@
+package core
+
+import (
+	"github.com/bethropolis/tide/internal/core/textobj"
+	"github.com/bethropolis/tide/internal/types"
+	"github.com/bethropolis/tide/internal/utils"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// maxBracketLines is how far MatchingBracket counts brackets textually.
+const maxBracketLines = 2000
+
+// MatchingBracket returns a bracket next to the cursor and the one it
+// pairs with: the bracket under the cursor, or with searchLine the first
+// one after it on the line, as % takes. The pair comes from the syntax
+// tree when the buffer has one, so brackets in strings and comments do not
+// get in the way; otherwise, and for brackets that are not code, or while
+// the tree has not caught up with an edit, brackets are counted as text.
+func (e *Editor) MatchingBracket(searchLine bool) (from, to types.Position, ok bool) {
+	if e.buffer == nil {
+		return from, to, false
+	}
+	from = e.GetCursor()
+	line, err := e.buffer.Line(from.Line)
+	if err != nil {
+		return from, to, false
+	}
+	runes := []rune(string(line))
+	col, found := textobj.BracketOnLine(runes, from.Col)
+	if !found || (col != from.Col && !searchLine) {
+		return from, to, false
+	}
+	from.Col = col
+	bracket := runes[col]
+	partner, opens, _ := textobj.Partner(bracket)
+
+	if e.highlightManager != nil {
+		at := sitter.Point{Row: uint32(from.Line), Column: uint32(utils.RuneIndexToByteOffset(line, col))}
+		if point, ok := e.highlightManager.MatchingBracket(at, bracket, partner, opens); ok {
+			if match, ok := e.bracketAt(point, partner); ok {
+				return from, match, true
+			}
+		}
+	}
+	to, ok = textobj.MatchBracket(e.buffer.Line, from, maxBracketLines)
+	return from, to, ok
+}
+
+// bracketAt converts a tree position to a buffer one, checking that the
+// buffer still holds bracket there.
+func (e *Editor) bracketAt(point sitter.Point, bracket rune) (types.Position, bool) {
+	line, err := e.buffer.Line(int(point.Row))
+	if err != nil || int(point.Column) >= len(line) {
+		return types.Position{}, false
+	}
+	pos := types.Position{Line: int(point.Row), Col: utils.ByteOffsetToRuneIndex(line, int(point.Column))}
+	runes := []rune(string(line))
+	return pos, pos.Col < len(runes) && runes[pos.Col] == bracket
+}
+
+// JumpToMatchingBracket moves the cursor to the bracket pairing with the
+// one under it or next after it on the line (Vim's %). It reports false
+// when there is none.
+func (e *Editor) JumpToMatchingBracket() bool {
+	if e.cursorManager == nil {
+		return false
+	}
+	_, to, ok := e.MatchingBracket(true)
+	if !ok {
+		return false
+	}
+	e.PushJump()
+	e.cursorManager.SetPosition(to)
+	if e.selectionManager != nil && e.selectionManager.IsSelecting() {
+		e.selectionManager.UpdateSelectionEnd()
+	}
+	return true
+}
+
+// SetDrawnMatch records the lines of the bracket pair highlighted in this
+// frame (both -1 for none) and marks the lines of the last frame's pair
+// and of this one dirty when they differ.
+func (e *Editor) SetDrawnMatch(from, to int) {
+	drawn := [2]int{from + 1, to + 1}
+	if drawn == e.drawnMatch {
+		return
+	}
+	for _, line := range append(e.drawnMatch[:], drawn[:]...) {
+		if line > 0 {
+			e.MarkDirty(line - 1)
+		}
+	}
+	e.drawnMatch = drawn
+}
@@ -0,0 +1,62 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/bethropolis/tide/internal/buffer"
+	"github.com/bethropolis/tide/internal/core/conflict"
+	"github.com/bethropolis/tide/internal/types"
+)
+
+// Conflicts returns the merge conflict regions in the buffer. They are
+// looked for once and kept until InvalidateConflicts, as the drawing asks
+// for them every frame.
+func (e *Editor) Conflicts() []conflict.Region {
+	if !e.conflictsFound && buffer.CapabilitiesOf(e.buffer).Parsed {
+		e.conflicts = conflict.Find(e.buffer.Lines())
+		e.conflictsFound = true
+	}
+	return e.conflicts
+}
+
+// InvalidateConflicts has Conflicts look through the buffer again, after
+// it changed.
+func (e *Editor) InvalidateConflicts() {
+	e.conflictsFound = false
+}
+
+// ResolveConflict replaces the merge conflict under the cursor, markers
+// included, with the side or sides kept by choice, as one undoable change.
+func (e *Editor) ResolveConflict(choice conflict.Choice) error {
+	lines := e.buffer.Lines()
+	r, ok := conflict.At(conflict.Find(lines), e.GetCursor().Line)
+	if !ok {
+		return fmt.Errorf("no merge conflict under the cursor")
+	}
+	kept := r.Resolve(lines, choice)
+	text := bytes.Join(kept, []byte("\n"))
+
+	start := types.Position{Line: r.Start}
+	var end types.Position
+	if r.End+1 < len(lines) {
+		end = types.Position{Line: r.End + 1}
+		if len(kept) > 0 {
+			text = append(text, '\n')
+		}
+	} else {
+		// The conflict ends the buffer: keep the last line without a newline
+		end = types.Position{Line: r.End, Col: utf8.RuneCount(lines[r.End])}
+		if len(kept) == 0 && r.Start > 0 {
+			start = types.Position{Line: r.Start - 1, Col: utf8.RuneCount(lines[r.Start-1])}
+		}
+	}
+	if _, err := e.ReplaceRange(start, end, text); err != nil {
+		return err
+	}
+	e.SetCursor(types.Position{Line: start.Line})
+	e.ScrollToCursor()
+	e.MarkAllDirty()
+	return nil
+}
@@ -0,0 +1,198 @@
+package core
+
+import (
+	"bytes"
+	"unicode/utf8"
+
+	"github.com/bethropolis/tide/internal/core/find"
+	"github.com/bethropolis/tide/internal/core/selection"
+	"github.com/bethropolis/tide/internal/types"
+	"github.com/bethropolis/tide/internal/utils"
+)
+
+// SelectNextOccurrence selects the word under the cursor or, with text
+// already selected, keeps the selection and selects the next occurrence
+// of its text as well, wrapping around the end of the buffer (Ctrl+D).
+// Each selection has a cursor at its end, and edits made at the cursor
+// are made at all of them. It returns false when there is no word to
+// select or no occurrence left.
+func (e *Editor) SelectNextOccurrence() bool {
+	if e.selectionManager == nil || e.cursorManager == nil {
+		return false
+	}
+	start, end, ok := e.GetSelection()
+	if !ok {
+		cursor := e.GetCursor()
+		line, err := e.buffer.Line(cursor.Line)
+		if err != nil {
+			return false
+		}
+		word, col, ok := find.WordAt(line, cursor.Col)
+		if !ok {
+			return false
+		}
+		e.ClearSelection()
+		e.selectionManager.SetAnchors(types.Position{Line: cursor.Line, Col: col},
+			types.Position{Line: cursor.Line, Col: col + utf8.RuneCountInString(word)})
+		e.cursorManager.SetPosition(types.Position{Line: cursor.Line, Col: col + utf8.RuneCountInString(word)})
+		return true
+	}
+
+	// Look on from the newest selection, past those already made
+	text := []byte(e.buffer.GetText(start, end))
+	selected := append(e.selectionManager.ExtraSelections(), selection.Range{Start: start, End: end})
+	from, to := start, end
+	for range selected {
+		if from, to, ok = e.nextOccurrence(text, to); !ok {
+			return false
+		}
+		if !containsRange(selected, from, to) {
+			break
+		}
+	}
+	if containsRange(selected, from, to) {
+		return false
+	}
+
+	cursor := e.GetCursor()
+	e.selectionManager.AddSelection(start, end)
+	e.selectionManager.SetAnchors(from, to)
+	e.cursorManager.SetPosition(to)
+	e.cursorManager.SetExtraCursors(append(e.cursorManager.ExtraCursors(), cursor))
+	e.MarkAllDirty()
+	return true
+}
+
+// containsRange reports whether one of ranges runs from start to end.
+func containsRange(ranges []selection.Range, start, end types.Position) bool {
+	for _, r := range ranges {
+		if r.Start == start && r.End == end {
+			return true
+		}
+	}
+	return false
+}
+
+// nextOccurrence returns the range of the first occurrence of text at or
+// after pos, or failing that from the start of the buffer.
+func (e *Editor) nextOccurrence(text []byte, pos types.Position) (start, end types.Position, ok bool) {
+	content := e.buffer.Bytes()
+	at := min(len(e.buffer.GetText(types.Position{}, pos)), len(content))
+	i := bytes.Index(content[at:], text)
+	if i >= 0 {
+		i += at
+	} else if i = bytes.Index(content, text); i < 0 {
+		return start, end, false
+	}
+	start = utils.EndPosition(types.Position{}, content[:i])
+	return start, utils.EndPosition(start, text), true
+}
+
+// ExtraCursors returns the cursors besides the main one that edits are made
+// at (multiple cursors).
+func (e *Editor) ExtraCursors() []types.Position {
+	if e.cursorManager == nil {
+		return nil
+	}
+	return e.cursorManager.ExtraCursors()
+}
+
+// ExtraSelections returns the selections made besides the main one by
+// SelectNextOccurrence.
+func (e *Editor) ExtraSelections() []selection.Range {
+	if e.selectionManager == nil {
+		return nil
+	}
+	return e.selectionManager.ExtraSelections()
+}
+
+// ClearExtraCursors leaves only the main cursor and selection.
+func (e *Editor) ClearExtraCursors() {
+	if e.cursorManager == nil || e.selectionManager == nil || len(e.cursorManager.ExtraCursors()) == 0 {
+		return
+	}
+	e.cursorManager.SetExtraCursors(nil)
+	if start, end, ok := e.selectionManager.Anchors(); ok {
+		e.selectionManager.ClearSelection()
+		e.selectionManager.SetAnchors(start, end)
+	}
+	e.MarkAllDirty()
+}
+
+// atCursors makes edit(i), an edit at the cursor, at each cursor in turn,
+// i being 0 for the main one and 1 on for the extra ones, as one undo
+// step, and moves each cursor to where its edit leaves it.
+func (e *Editor) atCursors(edit func(i int) error) error {
+	extra := e.ExtraCursors()
+	if len(extra) == 0 || e.textOps == nil {
+		return edit(0)
+	}
+	cursorBefore := e.GetCursor()
+	if e.historyManager != nil && !e.historyManager.InTransaction() {
+		e.historyManager.BeginTransaction()
+		defer e.historyManager.EndTransaction(cursorBefore)
+	}
+	placed, err := e.textOps.AtCursors(append([]types.Position{cursorBefore}, extra...), edit)
+	if err != nil {
+		e.ClearExtraCursors()
+		return err
+	}
+	e.cursorManager.SetPosition(placed[0])
+	e.cursorManager.SetExtraCursors(placed[1:])
+	e.MarkAllDirty()
+	return nil
+}
+
+// selections returns the main selection and those made besides it, in
+// the order of the cursors: the main one first, then the extra ones.
+func (e *Editor) selections() []selection.Range {
+	start, end, ok := e.GetSelection()
+	if !ok || e.IsLinewise() || e.IsBlockwise() {
+		return nil
+	}
+	return append([]selection.Range{{Start: start, End: end}}, e.selectionManager.ExtraSelections()...)
+}
+
+// CursorsToSelections puts the cursor and each extra cursor at the start
+// of its selection, or at its end with end, and clears the selections, to
+// type at all of them (I and A in visual mode). It returns false when
+// there is no character-wise selection.
+func (e *Editor) CursorsToSelections(end bool) bool {
+	ranges := e.selections()
+	if len(ranges) == 0 {
+		return false
+	}
+	cursors := make([]types.Position, len(ranges))
+	for i, r := range ranges {
+		cursors[i] = r.Start
+		if end {
+			cursors[i] = r.End
+		}
+	}
+	e.ClearSelection()
+	e.cursorManager.SetPosition(cursors[0])
+	e.cursorManager.SetExtraCursors(cursors[1:])
+	e.MarkAllDirty()
+	return true
+}
+
+// DeleteSelections deletes the text of every selection as one undo step,
+// leaving a cursor where each was (c and d on several selections). It
+// returns false when there is a single selection or none.
+func (e *Editor) DeleteSelections() (bool, error) {
+	ranges := e.selections()
+	if len(ranges) < 2 || e.textOps == nil {
+		return false, nil
+	}
+	e.ClearSelection()
+	e.cursorManager.SetPosition(ranges[0].Start)
+	extra := make([]types.Position, 0, len(ranges)-1)
+	for _, r := range ranges[1:] {
+		extra = append(extra, r.Start)
+	}
+	e.cursorManager.SetExtraCursors(extra)
+	return true, e.atCursors(func(i int) error {
+		_, err := e.textOps.ReplaceRange(ranges[i].Start, ranges[i].End, nil)
+		return err
+	})
+}
@@ -0,0 +1,66 @@
+package core
+
+import (
+	"github.com/bethropolis/tide/internal/core/diff"
+	"github.com/bethropolis/tide/internal/types"
+	"github.com/bethropolis/tide/internal/utils"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// Rewrite replaces the text of the buffer with text, such as a formatter's
+// output, as one undoable change that edits only what differs. The cursor,
+// the selection and the viewport are carried through the differences, so
+// they stay on the same code instead of jumping to the top; other windows
+// on the buffer follow the edits as they do any others.
+func (e *Editor) Rewrite(text []byte) error {
+	if e.buffer == nil || e.textOps == nil || e.cursorManager == nil {
+		return nil
+	}
+	edits := diff.Edits(e.buffer.Bytes(), text)
+	if len(edits) == 0 {
+		return nil
+	}
+
+	cursor := e.point(e.GetCursor())
+	top, left := e.GetViewport()
+	var anchors [2]sitter.Point
+	selecting, linewise, blockwise := false, false, false
+	if e.selectionManager != nil {
+		var start, end types.Position
+		start, end, selecting = e.selectionManager.Anchors()
+		anchors = [2]sitter.Point{e.point(start), e.point(end)}
+		linewise, blockwise = e.selectionManager.IsLinewise(), e.selectionManager.IsBlockwise()
+	}
+
+	if e.historyManager != nil && !e.historyManager.InTransaction() {
+		e.historyManager.BeginTransaction()
+		defer e.historyManager.EndTransaction(e.GetCursor())
+	}
+	for _, edit := range edits {
+		start, end := e.position(edit.StartPosition), e.position(edit.OldEndPosition)
+		if _, err := e.textOps.ReplaceRange(start, end, text[edit.StartIndex:edit.NewEndIndex]); err != nil {
+			return err
+		}
+	}
+
+	e.cursorManager.SetViewport(int(diff.MapPoint(sitter.Point{Row: uint32(top)}, edits).Row), left)
+	e.cursorManager.SetPosition(e.position(diff.MapPoint(cursor, edits)))
+	if selecting {
+		e.selectionManager.SetLinewise(linewise)
+		e.selectionManager.SetBlockwise(blockwise)
+		e.selectionManager.SetAnchors(e.position(diff.MapPoint(anchors[0], edits)), e.position(diff.MapPoint(anchors[1], edits)))
+	}
+	e.MarkAllDirty()
+	return nil
+}
+
+// point converts a buffer position to a tree-sitter point, whose column
+// counts bytes.
+func (e *Editor) point(pos types.Position) sitter.Point {
+	return utils.PositionToPoint(pos, e.buffer.Line)
+}
+
+// position converts a tree-sitter point to a buffer position.
+func (e *Editor) position(p sitter.Point) types.Position {
+	return utils.PointToPosition(p, e.buffer.Line)
+}
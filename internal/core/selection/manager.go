@@ -15,6 +15,12 @@ type Manager struct {
 	blockwise      bool           // True when in block-wise visual mode (Vim Ctrl+V)
 	selectionStart types.Position // Anchor point
 	selectionEnd   types.Position // Usually follows cursor
+	extra          []Range        // Further selections made along with this one (multiple cursors)
+}
+
+// Range is a character-wise selection from Start up to End.
+type Range struct {
+	Start, End types.Position
 }
 
 // EditorInterface defines what the selection manager needs from editor.
@@ -74,6 +80,7 @@ func (m *Manager) ClearSelection() {
 	m.blockwise = false
 	m.selectionStart = types.Position{Line: -1, Col: -1}
 	m.selectionEnd = types.Position{Line: -1, Col: -1}
+	m.extra = nil
 }
 
 // IsLinewise returns whether the selection is line-wise (Vim 'V' mode).
@@ -151,3 +158,32 @@ func (m *Manager) UpdateSelectionEnd() {
 func (m *Manager) IsSelecting() bool {
 	return m.selecting
 }
+
+// Anchors returns where the selection was started and where it ends, as
+// they are rather than in order; ok is false when not selecting.
+func (m *Manager) Anchors() (start, end types.Position, ok bool) {
+	return m.selectionStart, m.selectionEnd, m.selecting
+}
+
+// SetAnchors selects from start to end, keeping the line- or block-wise
+// mode.
+func (m *Manager) SetAnchors(start, end types.Position) {
+	m.selecting = true
+	m.selectionStart = start
+	m.selectionEnd = end
+}
+
+// ExtraSelections returns the selections kept besides the main one in the
+// order they were added, each with its start before its end.
+func (m *Manager) ExtraSelections() []Range {
+	return append([]Range(nil), m.extra...)
+}
+
+// AddSelection adds a further character-wise selection from start to end,
+// kept until the selection is cleared.
+func (m *Manager) AddSelection(start, end types.Position) {
+	if start.Line > end.Line || (start.Line == end.Line && start.Col > end.Col) {
+		start, end = end, start
+	}
+	m.extra = append(m.extra, Range{Start: start, End: end})
+}
@@ -0,0 +1,63 @@
+package core
+
+import (
+	"github.com/bethropolis/tide/internal/config"
+	hl "github.com/bethropolis/tide/internal/highlighter"
+)
+
+// Definitions returns the function and class definitions found by the last
+// syntax pass, ordered by start line.
+func (e *Editor) Definitions() []hl.Definition {
+	if e.highlightManager == nil {
+		return nil
+	}
+	return e.highlightManager.Definitions()
+}
+
+// PinnedLine returns the buffer line drawn over the top row of the view: the
+// pinned table header, or the sticky context line. ok is false when the top
+// row shows the buffer as usual.
+func (e *Editor) PinnedLine() (line int, ok bool) {
+	viewY, _ := e.GetViewport()
+	if viewY == 0 {
+		return 0, false
+	}
+	if e.HeaderPinned() {
+		return 0, true
+	}
+	if cfg := config.Get(); !cfg.Editor.StickyContext || cfg.UI.ScreenReader {
+		return 0, false
+	}
+	return e.StickyContextLine()
+}
+
+// StickyContextLine returns the first line of the innermost function or
+// class around the cursor whose start is scrolled off the top of the view.
+// There is none while the cursor sits on the top row, which the context
+// line would otherwise hide.
+func (e *Editor) StickyContextLine() (int, bool) {
+	viewY, _ := e.GetViewport()
+	cursor := e.GetCursor()
+	if cursor.Line <= viewY {
+		return 0, false
+	}
+	line, found := 0, false
+	for _, d := range e.Definitions() {
+		if d.StartLine >= viewY {
+			break // Sorted by start line; the rest are on screen
+		}
+		if d.StartLine < e.buffer.LineCount() && d.EndLine >= cursor.Line {
+			line, found = d.StartLine, true // Later starts are nested deeper
+		}
+	}
+	return line, found
+}
+
+// SetDrawnPin records the line drawn pinned at the top of the view in this
+// frame (-1 for none) and reports whether it differs from the last frame's,
+// in which case the top row must be redrawn.
+func (e *Editor) SetDrawnPin(line int) bool {
+	changed := e.drawnPin != line+1
+	e.drawnPin = line + 1
+	return changed
+}
@@ -0,0 +1,169 @@
+// Package table lays out delimiter-separated rows (CSV/TSV) as aligned
+// columns for display. The buffer text is never changed; a Layout only maps
+// rune columns of a line to screen columns.
+package table
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/rivo/uniseg"
+)
+
+// Gap is the number of screen columns between two cells (" │ ").
+const Gap = 3
+
+// Border is drawn in place of each delimiter.
+const Border = '│'
+
+// Cell is the rune range [Start, End) of one field's raw text, quotes included.
+type Cell struct {
+	Start int
+	End   int
+}
+
+// SeparatorFor returns the delimiter used by files with path's extension.
+func SeparatorFor(path string) (rune, bool) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return ',', true
+	case ".tsv", ".tab":
+		return '\t', true
+	}
+	return 0, false
+}
+
+// SplitRow splits line into cells. Delimiters inside double-quoted fields
+// do not split; quoted fields spanning several lines are not supported.
+func SplitRow(line []byte, sep rune) []Cell {
+	var cells []Cell
+	start, col := 0, 0
+	quoted := false
+	for _, r := range string(line) {
+		switch {
+		case r == '"':
+			quoted = !quoted // "" inside a quoted field toggles twice
+		case r == sep && !quoted:
+			cells = append(cells, Cell{Start: start, End: col})
+			start = col + 1
+		}
+		col++
+	}
+	return append(cells, Cell{Start: start, End: col})
+}
+
+// Layout holds the display width of every column.
+type Layout struct {
+	Sep    rune
+	Widths []int
+}
+
+// NewLayout measures the widest cell of each column over lines.
+func NewLayout(lines [][]byte, sep rune) *Layout {
+	l := &Layout{Sep: sep}
+	for _, line := range lines {
+		runes := []rune(string(line))
+		for i, c := range SplitRow(line, sep) {
+			w := uniseg.StringWidth(string(runes[c.Start:c.End]))
+			if i == len(l.Widths) {
+				l.Widths = append(l.Widths, w)
+			} else if w > l.Widths[i] {
+				l.Widths[i] = w
+			}
+		}
+	}
+	return l
+}
+
+// ColumnStart returns the screen column where cell i begins.
+func (l *Layout) ColumnStart(i int) int {
+	x := 0
+	for j := 0; j < i && j < len(l.Widths); j++ {
+		x += l.Widths[j] + Gap
+	}
+	return x
+}
+
+// Row is the display layout of one line.
+type Row struct {
+	Cells []Cell
+	Cols  []int // Screen column of each rune, plus one entry for end of line
+}
+
+// IsSeparator reports whether the rune at col is a delimiter between cells.
+func (r Row) IsSeparator(col int) bool {
+	for _, c := range r.Cells[:len(r.Cells)-1] {
+		if c.End == col {
+			return true
+		}
+	}
+	return false
+}
+
+// Row lays out line, placing each delimiter in the middle of its gap.
+func (l *Layout) Row(line []byte) Row {
+	runes := []rune(string(line))
+	row := Row{Cells: SplitRow(line, l.Sep), Cols: make([]int, len(runes)+1)}
+	for i, c := range row.Cells {
+		x := l.ColumnStart(i)
+		for j := c.Start; j < c.End; j++ {
+			row.Cols[j] = x
+			x += runeWidth(runes[j])
+		}
+		row.Cols[c.End] = x // delimiter, or end of line for the last cell
+		if i < len(row.Cells)-1 {
+			row.Cols[c.End] = l.ColumnStart(i) + l.width(i, x-l.ColumnStart(i)) + 1
+		}
+	}
+	return row
+}
+
+// VisualCol returns the screen column of rune column col in line.
+func (l *Layout) VisualCol(line []byte, col int) int {
+	cols := l.Row(line).Cols
+	if col < 0 {
+		return 0
+	}
+	if col >= len(cols) {
+		return cols[len(cols)-1]
+	}
+	return cols[col]
+}
+
+// BufferCol returns the rune column drawn at (or just before) screen column x.
+func (l *Layout) BufferCol(line []byte, x int) int {
+	cols := l.Row(line).Cols
+	for i := len(cols) - 1; i > 0; i-- {
+		if cols[i] <= x {
+			return i
+		}
+	}
+	return 0
+}
+
+// CellIndex returns the index of the cell containing col; a delimiter
+// belongs to the cell before it.
+func CellIndex(cells []Cell, col int) int {
+	for i, c := range cells {
+		if col <= c.End {
+			return i
+		}
+	}
+	return len(cells) - 1
+}
+
+// width returns the width of column i, falling back to min for rows that
+// grew past the measured layout.
+func (l *Layout) width(i, min int) int {
+	if i < len(l.Widths) && l.Widths[i] > min {
+		return l.Widths[i]
+	}
+	return min
+}
+
+func runeWidth(r rune) int {
+	if r == '\t' {
+		return 1
+	}
+	return uniseg.StringWidth(string(r))
+}
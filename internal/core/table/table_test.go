@@ -0,0 +1,45 @@
+package table
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitRow(t *testing.T) {
+	got := SplitRow([]byte(`a,"b,c",,d`), ',')
+	want := []Cell{{0, 1}, {2, 7}, {8, 8}, {9, 10}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SplitRow = %v; want %v", got, want)
+	}
+}
+
+func TestLayout(t *testing.T) {
+	lines := [][]byte{[]byte("id,name"), []byte("1,Alice"), []byte("42,Bo")}
+	l := NewLayout(lines, ',')
+	if !reflect.DeepEqual(l.Widths, []int{2, 5}) {
+		t.Fatalf("Widths = %v", l.Widths)
+	}
+	// "1,Alice" is drawn as "1  │ Alice"
+	row := l.Row(lines[1])
+	if want := []int{0, 3, 5, 6, 7, 8, 9, 10}; !reflect.DeepEqual(row.Cols, want) {
+		t.Errorf("Cols = %v; want %v", row.Cols, want)
+	}
+	if !row.IsSeparator(1) || row.IsSeparator(2) {
+		t.Errorf("IsSeparator wrong for %v", row.Cells)
+	}
+	if got := l.BufferCol(lines[1], 6); got != 3 {
+		t.Errorf("BufferCol(6) = %d; want 3", got)
+	}
+	if got := l.BufferCol(lines[1], 2); got != 0 {
+		t.Errorf("BufferCol(2) = %d; want 0 (padding belongs to the cell)", got)
+	}
+}
+
+func TestSeparatorFor(t *testing.T) {
+	if sep, ok := SeparatorFor("data/x.TSV"); !ok || sep != '\t' {
+		t.Errorf("tsv: %q %v", sep, ok)
+	}
+	if _, ok := SeparatorFor("main.go"); ok {
+		t.Errorf("go files are not tables")
+	}
+}
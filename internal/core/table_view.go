@@ -0,0 +1,95 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/bethropolis/tide/internal/core/table"
+	"github.com/bethropolis/tide/internal/types"
+)
+
+// SetTableView shows a CSV/TSV buffer as aligned columns (or back as plain
+// text). Only the display changes; the buffer bytes are left untouched.
+func (e *Editor) SetTableView(on bool) error {
+	if !on {
+		e.tableLayout = nil
+		e.MarkAllDirty()
+		e.cursorManager.ScrollToCursor()
+		return nil
+	}
+	sep, ok := table.SeparatorFor(e.buffer.FilePath())
+	if !ok {
+		return fmt.Errorf("table view needs a .csv or .tsv file")
+	}
+	e.tableLayout = table.NewLayout(e.buffer.Lines(), sep)
+	e.MarkAllDirty()
+	e.cursorManager.ScrollToCursor()
+	return nil
+}
+
+// TableView reports whether the buffer is shown as a table.
+func (e *Editor) TableView() bool {
+	return e.tableLayout != nil
+}
+
+// TableLayout returns the column layout, or nil when table view is off.
+func (e *Editor) TableLayout() *table.Layout {
+	return e.tableLayout
+}
+
+// RefreshTableLayout re-measures the columns after the buffer changed.
+func (e *Editor) RefreshTableLayout() {
+	if e.tableLayout == nil {
+		return
+	}
+	e.tableLayout = table.NewLayout(e.buffer.Lines(), e.tableLayout.Sep)
+	e.MarkAllDirty()
+}
+
+// SetPinHeader keeps the first line visible at the top while scrolling in
+// table view.
+func (e *Editor) SetPinHeader(pin bool) {
+	e.pinHeader = pin
+	e.MarkAllDirty()
+}
+
+// HeaderPinned reports whether the header row is drawn pinned.
+func (e *Editor) HeaderPinned() bool {
+	return e.tableLayout != nil && e.pinHeader
+}
+
+// MoveToCell moves the cursor to the start of the cell delta cells away,
+// wrapping across lines. It returns false at the start or end of the buffer.
+func (e *Editor) MoveToCell(delta int) bool {
+	if e.tableLayout == nil || delta == 0 {
+		return false
+	}
+	pos := e.GetCursor()
+	line, err := e.buffer.Line(pos.Line)
+	if err != nil {
+		return false
+	}
+	cells := table.SplitRow(line, e.tableLayout.Sep)
+	i := table.CellIndex(cells, pos.Col) + delta
+
+	for i < 0 || i >= len(cells) {
+		if i < 0 {
+			if pos.Line == 0 {
+				return false
+			}
+			pos.Line--
+		} else {
+			if pos.Line >= e.buffer.LineCount()-1 {
+				return false
+			}
+			i -= len(cells)
+			pos.Line++
+		}
+		line, _ = e.buffer.Line(pos.Line)
+		cells = table.SplitRow(line, e.tableLayout.Sep)
+		if i < 0 {
+			i += len(cells)
+		}
+	}
+	e.SetCursor(types.Position{Line: pos.Line, Col: cells[i].Start})
+	return true
+}
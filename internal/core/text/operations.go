@@ -1,20 +1,28 @@
 package text
 
 import (
+	"bytes"
 	"fmt"
+	"sort"
+	"strings"
 	"unicode/utf8"
 
-	"github.com/bethropolis/tide/internal/buffer"       // Import main buffer package
+	"github.com/bethropolis/tide/internal/buffer" // Import main buffer package
+	"github.com/bethropolis/tide/internal/core/diff"
 	"github.com/bethropolis/tide/internal/core/history" // Add history import
 	"github.com/bethropolis/tide/internal/event"
 	"github.com/bethropolis/tide/internal/logger"
 	"github.com/bethropolis/tide/internal/types"
 	"github.com/bethropolis/tide/internal/utils" // Import utility package as utils
+	sitter "github.com/smacker/go-tree-sitter"
 )
 
 // Operations handles text insertion/deletion
 type Operations struct {
 	editor EditorInterface
+
+	recording bool             // Keep the edits made, for AtCursors
+	edits     []types.EditInfo // Edits made while recording
 }
 
 // EditorInterface defines editor methods needed
@@ -45,7 +53,13 @@ func (o *Operations) InsertRune(r rune) error {
 	utf8.EncodeRune(runeBytes, r)
 
 	cursorBefore := o.editor.GetCursor() // Store cursor before change
-	editInfo, err := o.editor.GetBuffer().Insert(cursorBefore, runeBytes)
+	insertAt := cursorBefore
+	if r != '\n' {
+		var pad []byte
+		insertAt, pad = o.virtualPadding(cursorBefore)
+		runeBytes = append(pad, runeBytes...)
+	}
+	editInfo, err := o.editor.GetBuffer().Insert(insertAt, runeBytes)
 	if err != nil {
 		return err
 	}
@@ -66,7 +80,7 @@ func (o *Operations) InsertRune(r rune) error {
 		change := history.Change{
 			Type:          history.InsertAction,
 			Text:          runeBytes,
-			StartPosition: cursorBefore,
+			StartPosition: insertAt,
 			EndPosition:   cursorAfter,
 			CursorBefore:  cursorBefore,
 		}
@@ -76,23 +90,25 @@ func (o *Operations) InsertRune(r rune) error {
 	// Ensure cursor remains visible after insertion/movement
 	o.editor.ScrollToCursor()
 
-	// Dispatch event WITH EditInfo
-	eventManager := o.editor.GetEventManager()
-	if eventManager != nil {
-		eventManager.Dispatch(event.TypeBufferModified, event.BufferModifiedData{Edit: editInfo})
-	}
+	o.modified(editInfo)
 
 	return nil
 }
 
+// Indent says how InsertNewLine indents the new line.
+type Indent struct {
+	Keep    bool   // Copy the leading whitespace of the line being split
+	Openers string // Characters opening a block; ending the text before the cursor, they add a level
+	Unit    []byte // One level of indentation
+}
+
 // InsertNewLine inserts a newline and applies auto-indentation.
-func (o *Operations) InsertNewLine() error {
+func (o *Operations) InsertNewLine(indent Indent) error {
 	o.editor.ClearSelection() // Clear selection first
 
 	cursorBefore := o.editor.GetCursor()
 	buf := o.editor.GetBuffer()
 	histMgr := o.editor.GetHistoryManager()
-	eventMgr := o.editor.GetEventManager()
 
 	// --- Get Leading Whitespace from Current Line ---
 	currentLineBytes, err := buf.Line(cursorBefore.Line)
@@ -100,7 +116,13 @@ func (o *Operations) InsertNewLine() error {
 		// Fallback: just insert newline if we can't get current line
 		return o.InsertRune('\n') // Fallback to simpler insert
 	}
-	leadingWhitespace := utils.GetLeadingWhitespace(currentLineBytes)
+	var leadingWhitespace []byte
+	if indent.Keep {
+		leadingWhitespace = utils.GetLeadingWhitespace(currentLineBytes)
+		if opensBlock(currentLineBytes, cursorBefore.Col, indent.Openers) {
+			leadingWhitespace = append(bytes.Clone(leadingWhitespace), indent.Unit...)
+		}
+	}
 	// --- End Get Whitespace ---
 
 	// --- 1. Insert the Newline Character ---
@@ -124,9 +146,7 @@ func (o *Operations) InsertNewLine() error {
 		histMgr.RecordChange(changeNL)
 	}
 	// Dispatch event for newline insertion
-	if eventMgr != nil {
-		eventMgr.Dispatch(event.TypeBufferModified, event.BufferModifiedData{Edit: editInfoNL})
-	}
+	o.modified(editInfoNL)
 	// --- End Insert Newline ---
 
 	// --- 2. Insert Leading Whitespace (Auto Indent) ---
@@ -156,9 +176,7 @@ func (o *Operations) InsertNewLine() error {
 				histMgr.RecordChange(changeWS)
 			}
 			// Dispatch event for whitespace insertion
-			if eventMgr != nil {
-				eventMgr.Dispatch(event.TypeBufferModified, event.BufferModifiedData{Edit: editInfoWS})
-			}
+			o.modified(editInfoWS)
 		}
 	}
 	// --- End Insert Whitespace ---
@@ -170,23 +188,48 @@ func (o *Operations) InsertNewLine() error {
 	return nil // Overall success (even if whitespace insert had issues)
 }
 
-// InsertTab inserts a tab character at the current cursor position
-func (o *Operations) InsertTab() error {
+// opensBlock reports whether the text of line before rune column col ends,
+// ignoring trailing blanks, in one of the openers.
+func opensBlock(line []byte, col int, openers string) bool {
+	if openers == "" {
+		return false
+	}
+	end := utils.RuneIndexToByteOffset(line, col)
+	if end < 0 {
+		end = len(line)
+	}
+	before := bytes.TrimRight(line[:end], " \t")
+	r, _ := utf8.DecodeLastRune(before)
+	return len(before) > 0 && strings.ContainsRune(openers, r)
+}
+
+// InsertTab inserts a tab character at the current cursor position, or
+// with expand the spaces up to the next tab stop.
+func (o *Operations) InsertTab(expand bool, tabWidth int) error {
 	// Clear any selection when inserting a tab
 	o.editor.ClearSelection()
 
-	// Tab is just a single character ('\t')
-	runeBytes := []byte{'\t'}
-
+	// A tab goes after any virtual_edit padding
 	cursorBefore := o.editor.GetCursor() // Store cursor before change
-	editInfo, err := o.editor.GetBuffer().Insert(cursorBefore, runeBytes)
+	insertAt, runeBytes := o.virtualPadding(cursorBefore)
+	tab := []byte{'\t'}
+	if expand {
+		line, _ := o.editor.GetBuffer().Line(insertAt.Line)
+		end := utils.RuneIndexToByteOffset(line, insertAt.Col)
+		if end < 0 {
+			end = len(line)
+		}
+		tab = tabStopSpaces(indentWidth(line[:end], tabWidth)+len(runeBytes), tabWidth)
+	}
+	runeBytes = append(runeBytes, tab...)
+	editInfo, err := o.editor.GetBuffer().Insert(insertAt, runeBytes)
 	if err != nil {
 		return err
 	}
 
-	// Update cursor position after insertion (move it one column to the right)
+	// Update cursor position after insertion (tabs and spaces are a column each)
 	cursorAfter := cursorBefore
-	cursorAfter.Col++
+	cursorAfter.Col += len(tab)
 	o.editor.SetCursor(cursorAfter)
 
 	// Record change for undo/redo
@@ -195,7 +238,7 @@ func (o *Operations) InsertTab() error {
 		change := history.Change{
 			Type:          history.InsertAction,
 			Text:          runeBytes,
-			StartPosition: cursorBefore,
+			StartPosition: insertAt,
 			EndPosition:   cursorAfter,
 			CursorBefore:  cursorBefore,
 		}
@@ -205,11 +248,7 @@ func (o *Operations) InsertTab() error {
 	// Ensure cursor remains visible after insertion
 	o.editor.ScrollToCursor()
 
-	// Dispatch event with EditInfo
-	eventManager := o.editor.GetEventManager()
-	if eventManager != nil {
-		eventManager.Dispatch(event.TypeBufferModified, event.BufferModifiedData{Edit: editInfo})
-	}
+	o.modified(editInfo)
 
 	return nil
 }
@@ -255,9 +294,7 @@ func (o *Operations) DeleteBackward() error {
 
 		o.editor.ScrollToCursor()
 
-		if o.editor.GetEventManager() != nil {
-			o.editor.GetEventManager().Dispatch(event.TypeBufferModified, event.BufferModifiedData{Edit: editInfo})
-		}
+		o.modified(editInfo)
 
 		return nil
 	}
@@ -267,6 +304,12 @@ func (o *Operations) DeleteBackward() error {
 	start = currentPos
 	end = currentPos
 
+	// In virtual space past the end of the line there is nothing to delete
+	if _, pad := o.virtualPadding(currentPos); len(pad) > 0 {
+		o.editor.SetCursor(types.Position{Line: currentPos.Line, Col: currentPos.Col - 1})
+		return nil
+	}
+
 	if currentPos.Col > 0 {
 		// Deleting character within the current line
 		start.Col--
@@ -324,9 +367,7 @@ func (o *Operations) DeleteBackward() error {
 	// Ensure cursor is visible after deletion/movement
 	o.editor.ScrollToCursor()
 
-	if o.editor.GetEventManager() != nil {
-		o.editor.GetEventManager().Dispatch(event.TypeBufferModified, event.BufferModifiedData{Edit: editInfo})
-	}
+	o.modified(editInfo)
 
 	return nil
 }
@@ -371,9 +412,7 @@ func (o *Operations) DeleteForward() error {
 		o.editor.SetCursor(start)
 		o.editor.ScrollToCursor()
 
-		if o.editor.GetEventManager() != nil {
-			o.editor.GetEventManager().Dispatch(event.TypeBufferModified, event.BufferModifiedData{Edit: editInfo})
-		}
+		o.modified(editInfo)
 
 		return nil
 	}
@@ -435,14 +474,500 @@ func (o *Operations) DeleteForward() error {
 	// Ensure cursor is visible
 	o.editor.ScrollToCursor()
 
-	if o.editor.GetEventManager() != nil {
-		o.editor.GetEventManager().Dispatch(event.TypeBufferModified, event.BufferModifiedData{Edit: editInfo})
-	}
+	o.modified(editInfo)
 
 	return nil
 }
 
+// modified tells the rest of the editor about an edit made to the buffer.
+func (o *Operations) modified(edit types.EditInfo) {
+	if o.recording {
+		o.edits = append(o.edits, edit)
+	}
+	if eventManager := o.editor.GetEventManager(); eventManager != nil {
+		eventManager.Dispatch(event.TypeBufferModified, event.BufferModifiedData{Edit: edit, FilePath: o.editor.GetBuffer().FilePath()})
+	}
+}
+
+// AtCursors makes an edit at each of cursors in turn, as edit(i) makes it
+// at the cursor, which is put on cursors[i] first, and returns where each
+// cursor is left. The edits are made from the end of the buffer back, so
+// each is made where its cursor was, and the cursors left by those made
+// earlier are carried through the later ones.
+func (o *Operations) AtCursors(cursors []types.Position, edit func(i int) error) ([]types.Position, error) {
+	order := make([]int, len(cursors))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		p, q := cursors[order[a]], cursors[order[b]]
+		return p.Line > q.Line || (p.Line == q.Line && p.Col > q.Col)
+	})
+
+	o.recording, o.edits = true, nil
+	defer func() { o.recording, o.edits = false, nil }()
+	points := make([]sitter.Point, len(cursors))
+	for n, i := range order {
+		o.editor.SetCursor(cursors[i])
+		from := len(o.edits)
+		if err := edit(i); err != nil {
+			return nil, err
+		}
+		for _, j := range order[:n] {
+			points[j] = diff.MapPoint(points[j], o.edits[from:])
+		}
+		points[i] = o.point(o.editor.GetCursor())
+	}
+
+	placed := make([]types.Position, len(cursors))
+	for i, p := range points {
+		placed[i] = o.position(p)
+	}
+	return placed, nil
+}
+
+// point converts a buffer position to a tree-sitter point, whose column
+// counts bytes.
+func (o *Operations) point(pos types.Position) sitter.Point {
+	return utils.PositionToPoint(pos, o.editor.GetBuffer().Line)
+}
+
+// position converts a tree-sitter point to a buffer position.
+func (o *Operations) position(p sitter.Point) types.Position {
+	return utils.PointToPosition(p, o.editor.GetBuffer().Line)
+}
+
 // extractTextFromRange gets the text content between start and end positions
 func (o *Operations) extractTextFromRange(start, end types.Position) ([]byte, error) {
 	return []byte(o.editor.GetBuffer().GetText(start, end)), nil
 }
+
+// virtualPadding returns the spaces needed to reach pos when virtual_edit
+// has placed it past the end of its line, and where they must be inserted.
+func (o *Operations) virtualPadding(pos types.Position) (types.Position, []byte) {
+	line, err := o.editor.GetBuffer().Line(pos.Line)
+	if err != nil {
+		return pos, nil
+	}
+	n := utf8.RuneCount(line)
+	if pos.Col <= n {
+		return pos, nil
+	}
+	return types.Position{Line: pos.Line, Col: n}, bytes.Repeat([]byte{' '}, pos.Col-n)
+}
+
+// ReplaceRange replaces the text between start and end with text as a single
+// undoable change and leaves the cursor at the start of the new text. It
+// returns the position just after the new text.
+func (o *Operations) ReplaceRange(start, end types.Position, text []byte) (types.Position, error) {
+	buf := o.editor.GetBuffer()
+	cursorBefore := o.editor.GetCursor()
+	histMgr := o.editor.GetHistoryManager()
+	if histMgr != nil && !histMgr.InTransaction() {
+		histMgr.BeginTransaction()
+		defer histMgr.EndTransaction(cursorBefore)
+	}
+	o.editor.ClearSelection()
+
+	if start != end {
+		deletedText, err := o.extractTextFromRange(start, end)
+		if err != nil {
+			return start, err
+		}
+		editInfo, err := buf.Delete(start, end)
+		if err != nil {
+			return start, err
+		}
+		if histMgr != nil {
+			histMgr.RecordChange(history.Change{
+				Type:          history.DeleteAction,
+				Text:          deletedText,
+				StartPosition: start,
+				EndPosition:   end,
+				CursorBefore:  cursorBefore,
+			})
+		}
+		o.modified(editInfo)
+	}
+
+	if start == end && len(text) > 0 {
+		var pad []byte
+		start, pad = o.virtualPadding(start)
+		text = append(pad, text...)
+	}
+
+	if len(text) > 0 {
+		editInfo, err := buf.Insert(start, text)
+		if err != nil {
+			return start, err
+		}
+		if histMgr != nil {
+			histMgr.RecordChange(history.Change{
+				Type:          history.InsertAction,
+				Text:          text,
+				StartPosition: start,
+				EndPosition:   utils.EndPosition(start, text),
+				CursorBefore:  start,
+			})
+		}
+		o.modified(editInfo)
+	}
+
+	o.editor.SetCursor(start)
+	o.editor.ScrollToCursor()
+	return utils.EndPosition(start, text), nil
+}
+
+// ShiftLines indents lines startLine to endLine by unit (a tab, or spaces
+// with expand_tab), or with right false takes one level of indentation off them: a tab, or up to tabWidth
+// spaces. Blank lines are left alone. The lines change in one undo step
+// and the cursor goes to the first non-blank of startLine.
+func (o *Operations) ShiftLines(startLine, endLine int, right bool, unit []byte, tabWidth int) error {
+	buf := o.editor.GetBuffer()
+	cursorBefore := o.editor.GetCursor()
+	histMgr := o.editor.GetHistoryManager()
+	if histMgr != nil && !histMgr.InTransaction() {
+		histMgr.BeginTransaction()
+		defer histMgr.EndTransaction(cursorBefore)
+	}
+
+	endLine = min(endLine, buf.LineCount()-1)
+	for line := max(startLine, 0); line <= endLine; line++ {
+		text, err := buf.Line(line)
+		if err != nil {
+			return err
+		}
+		if len(bytes.TrimSpace(text)) == 0 {
+			continue
+		}
+		lineStart := types.Position{Line: line}
+		if right {
+			_, err = o.ReplaceRange(lineStart, lineStart, unit)
+		} else if n := outdentWidth(text, tabWidth); n > 0 {
+			_, err = o.ReplaceRange(lineStart, types.Position{Line: line, Col: n}, nil)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	text, _ := buf.Line(startLine)
+	indent := len(text) - len(bytes.TrimLeft(text, " \t"))
+	o.editor.SetCursor(types.Position{Line: startLine, Col: indent})
+	o.editor.ScrollToCursor()
+	return nil
+}
+
+// ToggleComment comments out lines startLine to endLine by putting marker
+// and a space after their least indentation, or uncomments them when every
+// one already starts with marker, as one undoable change. Blank lines are
+// left alone.
+func (o *Operations) ToggleComment(startLine, endLine int, marker string) error {
+	buf := o.editor.GetBuffer()
+	cursorBefore := o.editor.GetCursor()
+	histMgr := o.editor.GetHistoryManager()
+	if histMgr != nil && !histMgr.InTransaction() {
+		histMgr.BeginTransaction()
+		defer histMgr.EndTransaction(cursorBefore)
+	}
+
+	startLine = max(startLine, 0)
+	endLine = min(endLine, buf.LineCount()-1)
+	var lines [][]byte
+	for line := startLine; line <= endLine; line++ {
+		text, err := buf.Line(line)
+		if err != nil {
+			return err
+		}
+		lines = append(lines, text)
+	}
+	commented, column := commentState(lines, []byte(marker))
+	for i, text := range lines {
+		line := startLine + i
+		if len(bytes.TrimSpace(text)) == 0 {
+			continue
+		}
+		var err error
+		if commented {
+			start, end, _ := uncommentSpan(text, []byte(marker))
+			_, err = o.ReplaceRange(types.Position{Line: line, Col: start}, types.Position{Line: line, Col: end}, nil)
+		} else {
+			at := types.Position{Line: line, Col: column}
+			_, err = o.ReplaceRange(at, at, []byte(marker+" "))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// commentState reports whether every non-blank line starts with marker
+// after its indentation, and the least indentation among them, in bytes
+// (-1 when all lines are blank).
+func commentState(lines [][]byte, marker []byte) (commented bool, column int) {
+	commented, column = true, -1
+	for _, text := range lines {
+		rest := bytes.TrimLeft(text, " \t")
+		if len(bytes.TrimSpace(rest)) == 0 {
+			continue
+		}
+		if indent := len(text) - len(rest); column < 0 || indent < column {
+			column = indent
+		}
+		if !bytes.HasPrefix(rest, marker) {
+			commented = false
+		}
+	}
+	return commented && column >= 0, column
+}
+
+// uncommentSpan returns where the comment marker of a commented line
+// starts and ends, taking in the space after it.
+func uncommentSpan(line, marker []byte) (start, end int, ok bool) {
+	start = len(line) - len(bytes.TrimLeft(line, " \t"))
+	if !bytes.HasPrefix(line[start:], marker) {
+		return 0, 0, false
+	}
+	end = start + len(marker)
+	if end < len(line) && line[end] == ' ' {
+		end++
+	}
+	return start, end, true
+}
+
+// lineEnd returns the position at the end of line.
+func (o *Operations) lineEnd(line int) types.Position {
+	text, _ := o.editor.GetBuffer().Line(line)
+	return types.Position{Line: line, Col: utf8.RuneCount(text)}
+}
+
+// DuplicateLines puts copies of lines startLine to endLine below them, as
+// one undoable change. The cursor moves down onto the last copy, keeping
+// its column.
+func (o *Operations) DuplicateLines(startLine, endLine, copies int) error {
+	buf := o.editor.GetBuffer()
+	cursorBefore := o.editor.GetCursor()
+	histMgr := o.editor.GetHistoryManager()
+	if histMgr != nil && !histMgr.InTransaction() {
+		histMgr.BeginTransaction()
+		defer histMgr.EndTransaction(cursorBefore)
+	}
+
+	startLine = max(startLine, 0)
+	endLine = min(endLine, buf.LineCount()-1)
+	if startLine > endLine || copies < 1 {
+		return nil
+	}
+	block := buf.GetText(types.Position{Line: startLine}, o.lineEnd(endLine))
+	end := o.lineEnd(endLine)
+	if _, err := o.ReplaceRange(end, end, []byte(strings.Repeat("\n"+block, copies))); err != nil {
+		return err
+	}
+
+	cursorBefore.Line += copies * (endLine - startLine + 1)
+	o.editor.SetCursor(cursorBefore)
+	o.editor.ScrollToCursor()
+	return nil
+}
+
+// DeleteLines deletes lines startLine to endLine whole, as one undoable
+// change, and puts the cursor on the first non-blank of the line that
+// takes their place.
+func (o *Operations) DeleteLines(startLine, endLine int) error {
+	buf := o.editor.GetBuffer()
+	cursorBefore := o.editor.GetCursor()
+	histMgr := o.editor.GetHistoryManager()
+	if histMgr != nil && !histMgr.InTransaction() {
+		histMgr.BeginTransaction()
+		defer histMgr.EndTransaction(cursorBefore)
+	}
+
+	last := buf.LineCount() - 1
+	startLine = max(startLine, 0)
+	endLine = min(endLine, last)
+	if startLine > endLine {
+		return nil
+	}
+	start, end := types.Position{Line: startLine}, types.Position{Line: endLine + 1}
+	if endLine == last {
+		// No line follows: take the line break before them instead
+		end = o.lineEnd(last)
+		if startLine > 0 {
+			start = o.lineEnd(startLine - 1)
+		}
+	}
+	if _, err := o.ReplaceRange(start, end, nil); err != nil {
+		return err
+	}
+
+	line := min(startLine, buf.LineCount()-1)
+	text, _ := buf.Line(line)
+	indent := utf8.RuneCount(text) - utf8.RuneCount(bytes.TrimLeft(text, " \t"))
+	o.editor.SetCursor(types.Position{Line: line, Col: indent})
+	o.editor.ScrollToCursor()
+	return nil
+}
+
+// JoinLines joins lines startLine to endLine into one, as Vim's J does:
+// the indentation of each joined line gives way to a single space, or to
+// none after a blank or before a closing bracket. It is one undoable change
+// and leaves the cursor where the last line was joined.
+func (o *Operations) JoinLines(startLine, endLine int) error {
+	buf := o.editor.GetBuffer()
+	cursorBefore := o.editor.GetCursor()
+	histMgr := o.editor.GetHistoryManager()
+	if histMgr != nil && !histMgr.InTransaction() {
+		histMgr.BeginTransaction()
+		defer histMgr.EndTransaction(cursorBefore)
+	}
+
+	startLine = max(startLine, 0)
+	endLine = min(endLine, buf.LineCount()-1)
+	for n := startLine; n < endLine; n++ {
+		text, err := buf.Line(startLine)
+		if err != nil {
+			return err
+		}
+		next, err := buf.Line(startLine + 1)
+		if err != nil {
+			return err
+		}
+		rest := bytes.TrimLeft(next, " \t")
+		indent := utf8.RuneCount(next) - utf8.RuneCount(rest)
+		end := types.Position{Line: startLine + 1, Col: indent}
+		if _, err := o.ReplaceRange(o.lineEnd(startLine), end, []byte(joinSeparator(text, rest))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// joinSeparator returns what goes between line and the unindented line
+// joined to it.
+func joinSeparator(line, next []byte) string {
+	switch {
+	case len(next) == 0 || next[0] == ')' || next[0] == ']' || next[0] == '}':
+		return ""
+	case len(line) == 0 || line[len(line)-1] == ' ' || line[len(line)-1] == '\t':
+		return ""
+	}
+	return " "
+}
+
+// MoveLines moves lines startLine to endLine down by lines, or up when it is
+// negative, stopping at either end of the buffer, as one undoable change.
+// The cursor moves with them.
+func (o *Operations) MoveLines(startLine, endLine, lines int) error {
+	buf := o.editor.GetBuffer()
+	cursorBefore := o.editor.GetCursor()
+	histMgr := o.editor.GetHistoryManager()
+	if histMgr != nil && !histMgr.InTransaction() {
+		histMgr.BeginTransaction()
+		defer histMgr.EndTransaction(cursorBefore)
+	}
+
+	startLine = max(startLine, 0)
+	endLine = min(endLine, buf.LineCount()-1)
+	if startLine > endLine {
+		return nil
+	}
+	if lines > 0 {
+		// Take the lines below and put them back above
+		lines = min(lines, buf.LineCount()-1-endLine)
+		if lines == 0 {
+			return nil
+		}
+		below := buf.GetText(o.lineEnd(endLine), o.lineEnd(endLine+lines))
+		if _, err := o.ReplaceRange(o.lineEnd(endLine), o.lineEnd(endLine+lines), nil); err != nil {
+			return err
+		}
+		at := types.Position{Line: startLine}
+		if _, err := o.ReplaceRange(at, at, []byte(below[1:]+"\n")); err != nil {
+			return err
+		}
+	} else {
+		// Take the lines above and put them back below
+		lines = -min(-lines, startLine)
+		if lines == 0 {
+			return nil
+		}
+		above := buf.GetText(types.Position{Line: startLine + lines}, types.Position{Line: startLine})
+		if _, err := o.ReplaceRange(types.Position{Line: startLine + lines}, types.Position{Line: startLine}, nil); err != nil {
+			return err
+		}
+		at := o.lineEnd(endLine + lines)
+		if _, err := o.ReplaceRange(at, at, []byte("\n"+strings.TrimSuffix(above, "\n"))); err != nil {
+			return err
+		}
+	}
+
+	cursorBefore.Line += lines
+	o.editor.SetCursor(cursorBefore)
+	o.editor.ScrollToCursor()
+	return nil
+}
+
+// outdentWidth returns how many leading blanks of line make up one level
+// of indentation: a tab, or up to tabWidth spaces (ending at a tab).
+func outdentWidth(line []byte, tabWidth int) int {
+	n := 0
+	for n < len(line) && n < max(tabWidth, 1) && line[n] == ' ' {
+		n++
+	}
+	if n < len(line) && n < max(tabWidth, 1) && line[n] == '\t' {
+		n++
+	}
+	return n
+}
+
+// Retab returns text with the indentation of every line rewritten with
+// tabs, or with expand with spaces only, keeping its width. Spaces short of
+// a tab stop stay spaces. It also returns how many lines changed.
+func Retab(text []byte, expand bool, tabWidth int) ([]byte, int) {
+	var out bytes.Buffer
+	changed := 0
+	for len(text) > 0 {
+		line := text
+		if n := bytes.IndexByte(text, '\n'); n >= 0 {
+			line = text[:n+1]
+		}
+		text = text[len(line):]
+		body := bytes.TrimLeft(line, " \t")
+		indent := line[:len(line)-len(body)]
+		width := indentWidth(indent, tabWidth)
+		var retabbed []byte
+		if expand {
+			retabbed = bytes.Repeat([]byte{' '}, width)
+		} else {
+			retabbed = append(bytes.Repeat([]byte{'\t'}, width/max(tabWidth, 1)), bytes.Repeat([]byte{' '}, width%max(tabWidth, 1))...)
+		}
+		if !bytes.Equal(indent, retabbed) {
+			changed++
+		}
+		out.Write(retabbed)
+		out.Write(body)
+	}
+	return out.Bytes(), changed
+}
+
+// indentWidth returns how many screen columns the blanks and text of
+// prefix take, with tab stops every tabWidth columns.
+func indentWidth(prefix []byte, tabWidth int) int {
+	tabWidth = max(tabWidth, 1)
+	width := 0
+	for _, r := range string(prefix) {
+		if r == '\t' {
+			width = (width/tabWidth + 1) * tabWidth
+		} else {
+			width++
+		}
+	}
+	return width
+}
+
+// tabStopSpaces returns the spaces from column col to the next tab stop.
+func tabStopSpaces(col, tabWidth int) []byte {
+	tabWidth = max(tabWidth, 1)
+	return bytes.Repeat([]byte{' '}, tabWidth-col%tabWidth)
+}
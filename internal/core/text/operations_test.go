@@ -0,0 +1,126 @@
+package text
+
+import "testing"
+
+func TestOpensBlock(t *testing.T) {
+	tests := []struct {
+		line    string
+		col     int
+		openers string
+		want    bool
+	}{
+		{"func main() {", 13, "{[(", true},
+		{"func main() {  ", 15, "{[(", true},
+		{"func main() {}", 13, "{[(", true}, // Split between the braces
+		{"func main() {}", 14, "{[(", false},
+		{"if x:", 5, "{[(:", true},
+		{"if x:", 5, "{[(", false},
+		{"x := «y» {", 10, "{", true},
+		{"", 0, "{", false},
+		{"{", 1, "", false},
+	}
+	for _, tt := range tests {
+		if got := opensBlock([]byte(tt.line), tt.col, tt.openers); got != tt.want {
+			t.Errorf("opensBlock(%q, %d, %q) = %v, want %v", tt.line, tt.col, tt.openers, got, tt.want)
+		}
+	}
+}
+
+func TestTabStops(t *testing.T) {
+	tests := []struct {
+		prefix string
+		width  int // Columns taken by prefix with tab_width 4
+		spaces int // Spaces expand_tab inserts after it
+	}{
+		{"", 0, 4},
+		{"ab", 2, 2},
+		{"\t", 4, 4},
+		{"  \t", 4, 4},
+		{"\tabc", 7, 1},
+		{"é", 1, 3},
+	}
+	for _, tt := range tests {
+		width := indentWidth([]byte(tt.prefix), 4)
+		if width != tt.width {
+			t.Errorf("indentWidth(%q) = %d, want %d", tt.prefix, width, tt.width)
+		}
+		if got := len(tabStopSpaces(width, 4)); got != tt.spaces {
+			t.Errorf("tabStopSpaces after %q = %d spaces, want %d", tt.prefix, got, tt.spaces)
+		}
+	}
+}
+
+func TestCommentState(t *testing.T) {
+	tests := []struct {
+		lines     []string
+		commented bool
+		column    int
+	}{
+		{[]string{"\tfoo()", "", "\t\tbar()"}, false, 1},
+		{[]string{"\t// foo()", "  ", "\t\t//bar()"}, true, 1},
+		{[]string{"// a", "b"}, false, 0},
+		{[]string{"", " "}, false, -1},
+	}
+	for _, tt := range tests {
+		var lines [][]byte
+		for _, l := range tt.lines {
+			lines = append(lines, []byte(l))
+		}
+		commented, column := commentState(lines, []byte("//"))
+		if commented != tt.commented || column != tt.column {
+			t.Errorf("commentState(%q) = %v, %d, want %v, %d", tt.lines, commented, column, tt.commented, tt.column)
+		}
+	}
+
+	for _, tt := range []struct {
+		line       string
+		start, end int
+		ok         bool
+	}{
+		{"  # x", 2, 4, true},
+		{"\t#x", 1, 2, true},
+		{"x # y", 0, 0, false},
+	} {
+		start, end, ok := uncommentSpan([]byte(tt.line), []byte("#"))
+		if start != tt.start || end != tt.end || ok != tt.ok {
+			t.Errorf("uncommentSpan(%q) = %d, %d, %v, want %d, %d, %v", tt.line, start, end, ok, tt.start, tt.end, tt.ok)
+		}
+	}
+}
+
+func TestJoinSeparator(t *testing.T) {
+	tests := []struct {
+		line, next, want string
+	}{
+		{"foo(a,", "b)", " "},
+		{"foo(a, ", "b)", ""},
+		{"foo(a,\t", "b)", ""},
+		{"call(x", ")", ""},
+		{"items := []int{1, 2", "}", ""},
+		{"text", "", ""},
+		{"", "text", ""},
+	}
+	for _, tt := range tests {
+		if got := joinSeparator([]byte(tt.line), []byte(tt.next)); got != tt.want {
+			t.Errorf("joinSeparator(%q, %q) = %q, want %q", tt.line, tt.next, got, tt.want)
+		}
+	}
+}
+
+func TestRetab(t *testing.T) {
+	in := "a\n    b\n  \tc\n      d\n\t\n"
+	tests := []struct {
+		expand  bool
+		want    string
+		changed int
+	}{
+		{false, "a\n\tb\n\tc\n\t  d\n\t\n", 3},
+		{true, "a\n    b\n    c\n      d\n    \n", 2},
+	}
+	for _, tt := range tests {
+		got, changed := Retab([]byte(in), tt.expand, 4)
+		if string(got) != tt.want || changed != tt.changed {
+			t.Errorf("Retab(expand=%v) = %q, %d; want %q, %d", tt.expand, got, changed, tt.want, tt.changed)
+		}
+	}
+}
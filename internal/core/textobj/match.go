@@ -0,0 +1,87 @@
+package textobj
+
+import "github.com/bethropolis/tide/internal/types"
+
+// bracketPartners pairs the brackets % jumps between.
+var bracketPartners = map[rune]rune{'(': ')', ')': '(', '[': ']', ']': '[', '{': '}', '}': '{'}
+
+// Partner returns the bracket that pairs with r, and whether r opens its
+// pair. ok is false when r is not a bracket.
+func Partner(r rune) (partner rune, opens bool, ok bool) {
+	partner, ok = bracketPartners[r]
+	return partner, r == '(' || r == '[' || r == '{', ok
+}
+
+// BracketOnLine returns the column of the bracket % starts from: the one
+// at col, or else the first one after it on the line.
+func BracketOnLine(line []rune, col int) (int, bool) {
+	for i := max(col, 0); i < len(line); i++ {
+		if _, ok := bracketPartners[line[i]]; ok {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// MatchBracket returns the position of the bracket pairing with the one
+// at pos, counting the brackets of the same kind in between, and looking
+// at most maxLines lines away. Lines are read through line, which fails
+// past the end of the text, so only the lines looked at are read. Brackets
+// are taken as they appear, even in strings and comments.
+func MatchBracket(line func(int) ([]byte, error), pos types.Position, maxLines int) (types.Position, bool) {
+	if pos.Line < 0 {
+		return types.Position{}, false
+	}
+	first, err := line(pos.Line)
+	if err != nil {
+		return types.Position{}, false
+	}
+	text := []rune(string(first))
+	if pos.Col < 0 || pos.Col >= len(text) {
+		return types.Position{}, false
+	}
+	bracket := text[pos.Col]
+	partner, opens, ok := Partner(bracket)
+	if !ok {
+		return types.Position{}, false
+	}
+
+	step := 1
+	if !opens {
+		step = -1
+	}
+	depth := 0
+	col := pos.Col + step
+	for l := pos.Line; l >= 0 && abs(l-pos.Line) <= maxLines; l += step {
+		if l != pos.Line {
+			next, err := line(l)
+			if err != nil {
+				break
+			}
+			text = []rune(string(next))
+			col = 0
+			if step < 0 {
+				col = len(text) - 1
+			}
+		}
+		for ; col >= 0 && col < len(text); col += step {
+			switch text[col] {
+			case bracket:
+				depth++
+			case partner:
+				if depth == 0 {
+					return types.Position{Line: l, Col: col}, true
+				}
+				depth--
+			}
+		}
+	}
+	return types.Position{}, false
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
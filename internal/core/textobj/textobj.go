@@ -0,0 +1,237 @@
+// Package textobj finds the text Vim's text objects cover: words (iw, aw),
+// quoted strings (i", a') and bracket blocks (i(, a{). Operators in normal
+// mode act on the ranges it returns.
+package textobj
+
+import (
+	"unicode"
+
+	"github.com/bethropolis/tide/internal/types"
+)
+
+// Range is a span of text an operator acts on.
+type Range struct {
+	Start, End types.Position // End is exclusive; columns count runes
+	Linewise   bool           // Covers lines Start.Line to End.Line in full
+}
+
+// Object returns the range of the text object named by r after 'i'
+// (around false) or 'a' (around true) with the cursor at pos: w for a
+// word, a quote character for a string, or a bracket (b is '(', B is '{')
+// for a block. ok is false for other runes or when there is no such
+// object at pos.
+func Object(lines [][]byte, pos types.Position, r rune, around bool) (Range, bool) {
+	if pos.Line < 0 || pos.Line >= len(lines) {
+		return Range{}, false
+	}
+	line := []rune(string(lines[pos.Line]))
+	span := func(start, end int, ok bool) (Range, bool) {
+		return Range{
+			Start: types.Position{Line: pos.Line, Col: start},
+			End:   types.Position{Line: pos.Line, Col: end},
+		}, ok
+	}
+
+	switch r {
+	case 'w':
+		return span(Word(line, pos.Col, around))
+	case '"', '\'', '`':
+		return span(Quote(line, pos.Col, r, around))
+	case '(', ')', 'b':
+		return Bracket(lines, pos, '(', ')', around)
+	case '[', ']':
+		return Bracket(lines, pos, '[', ']', around)
+	case '{', '}', 'B':
+		return Bracket(lines, pos, '{', '}', around)
+	case '<', '>':
+		return Bracket(lines, pos, '<', '>', around)
+	}
+	return Range{}, false
+}
+
+// class sorts runes into blanks (0), word characters (1) and other
+// non-blank characters (2), the runs Vim's words are made of.
+func class(r rune) int {
+	switch {
+	case r == ' ' || r == '\t':
+		return 0
+	case r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+		return 1
+	}
+	return 2
+}
+
+// Word returns the rune columns [start, end) of the word, run of
+// punctuation or run of blanks at col of line (iw). With around, the
+// blanks after it are included, or the ones before it when none follow
+// (aw); on blanks, aw takes them and the word after.
+func Word(line []rune, col int, around bool) (start, end int, ok bool) {
+	if len(line) == 0 {
+		return 0, 0, false
+	}
+	col = max(0, min(col, len(line)-1))
+	run := func(from int) (int, int) {
+		s, e := from, from
+		for s > 0 && class(line[s-1]) == class(line[from]) {
+			s--
+		}
+		for e < len(line) && class(line[e]) == class(line[from]) {
+			e++
+		}
+		return s, e
+	}
+
+	start, end = run(col)
+	if !around {
+		return start, end, true
+	}
+	if class(line[col]) == 0 {
+		if end < len(line) {
+			_, end = run(end)
+		}
+		return start, end, true
+	}
+	if end < len(line) && class(line[end]) == 0 {
+		_, end = run(end)
+	} else if start > 0 && class(line[start-1]) == 0 {
+		start, _ = run(start - 1)
+	}
+	return start, end, true
+}
+
+// Quote returns the rune columns [start, end) inside the string quoted
+// with q that col is in, or with around, of the string with its quotes
+// and the blanks after it (or before it, when none follow). Quotes pair up
+// from the start of the line, and backslash-escaped ones are skipped. As
+// in Vim, a cursor before the strings of the line uses the next one.
+func Quote(line []rune, col int, q rune, around bool) (start, end int, ok bool) {
+	var quotes []int
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case q:
+			quotes = append(quotes, i)
+		}
+	}
+
+	open, close := -1, -1
+	for i := 0; i+1 < len(quotes); i += 2 {
+		if col <= quotes[i+1] {
+			open, close = quotes[i], quotes[i+1]
+			break
+		}
+	}
+	if open < 0 {
+		return 0, 0, false
+	}
+	if !around {
+		return open + 1, close, true
+	}
+	start, end = open, close+1
+	if end < len(line) && class(line[end]) == 0 {
+		for end < len(line) && class(line[end]) == 0 {
+			end++
+		}
+	} else {
+		for start > 0 && class(line[start-1]) == 0 {
+			start--
+		}
+	}
+	return start, end, true
+}
+
+// Bracket returns the block between open and the matching close that
+// encloses pos (a cursor on either bracket counts as inside), across lines.
+// Without around only the text between the brackets is covered; when that
+// starts at a line end and ends after only blanks, the lines in between
+// are covered whole, so "di{" empties a block without joining its braces.
+func Bracket(lines [][]byte, pos types.Position, open, close rune, around bool) (Range, bool) {
+	runes := func(line int) []rune { return []rune(string(lines[line])) }
+
+	// Walk back to the unmatched open bracket
+	depth := 0
+	from, found := pos, false
+	cur := runes(pos.Line)
+	if pos.Col < len(cur) && cur[pos.Col] == close {
+		from.Col-- // Look for the open of the cursor's close bracket
+	}
+	for line := from.Line; line >= 0 && !found; line-- {
+		text := runes(line)
+		col := len(text) - 1
+		if line == from.Line {
+			col = min(from.Col, len(text)-1)
+		}
+		for ; col >= 0; col-- {
+			switch text[col] {
+			case close:
+				depth++
+			case open:
+				if depth == 0 {
+					from, found = types.Position{Line: line, Col: col}, true
+				}
+				depth--
+			}
+			if found {
+				break
+			}
+		}
+	}
+	if !found {
+		return Range{}, false
+	}
+
+	// And forward to its close
+	depth = 0
+	to, found := from, false
+	for line := from.Line; line < len(lines) && !found; line++ {
+		text := runes(line)
+		col := 0
+		if line == from.Line {
+			col = from.Col + 1
+		}
+		for ; col < len(text); col++ {
+			switch text[col] {
+			case open:
+				depth++
+			case close:
+				if depth == 0 {
+					to, found = types.Position{Line: line, Col: col}, true
+				}
+				depth--
+			}
+			if found {
+				break
+			}
+		}
+	}
+	if !found {
+		return Range{}, false
+	}
+
+	if around {
+		return Range{Start: from, End: types.Position{Line: to.Line, Col: to.Col + 1}}, true
+	}
+	r := Range{Start: types.Position{Line: from.Line, Col: from.Col + 1}, End: to}
+	if r.Start.Line < r.End.Line && r.Start.Col == len(runes(r.Start.Line)) && onlyBlanks(runes(to.Line)[:to.Col]) {
+		if r.End.Line-r.Start.Line == 1 {
+			// Nothing but a line break between the brackets
+			return Range{Start: r.Start, End: r.Start}, true
+		}
+		return Range{
+			Start:    types.Position{Line: r.Start.Line + 1},
+			End:      types.Position{Line: r.End.Line - 1, Col: len(runes(r.End.Line - 1))},
+			Linewise: true,
+		}, true
+	}
+	return r, true
+}
+
+func onlyBlanks(text []rune) bool {
+	for _, r := range text {
+		if class(r) != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,138 @@
+package textobj
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/bethropolis/tide/internal/types"
+)
+
+func TestWord(t *testing.T) {
+	tests := []struct {
+		line       string
+		col        int
+		around     bool
+		start, end int
+	}{
+		{"foo bar baz", 5, false, 4, 7},
+		{"foo bar baz", 5, true, 4, 8},   // Trailing blank
+		{"foo bar", 5, true, 3, 7},       // No trailing blank: leading one
+		{"foo   bar", 4, false, 3, 6},    // On blanks: the blanks
+		{"foo   bar", 4, true, 3, 9},     // And the word after
+		{"a.b(c)", 1, false, 1, 2},       // Punctuation is its own word
+		{"héllo wörld", 8, false, 6, 11}, // Rune columns
+		{"abc", 10, false, 0, 3},         // Past the end: the last word
+	}
+	for _, tt := range tests {
+		start, end, ok := Word([]rune(tt.line), tt.col, tt.around)
+		if !ok || start != tt.start || end != tt.end {
+			t.Errorf("Word(%q, %d, %v) = %d, %d, %v; want %d, %d", tt.line, tt.col, tt.around, start, end, ok, tt.start, tt.end)
+		}
+	}
+	if _, _, ok := Word(nil, 0, false); ok {
+		t.Errorf("Word on an empty line should find nothing")
+	}
+}
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		line       string
+		col        int
+		around     bool
+		start, end int
+		ok         bool
+	}{
+		{`x := "hello" + y`, 7, false, 6, 11, true},
+		{`x := "hello" + y`, 7, true, 5, 13, true},
+		{`x := "hello" + y`, 0, false, 6, 11, true}, // Before the string: the next one
+		{`x := "hello" + y`, 5, false, 6, 11, true}, // On the opening quote
+		{`f("a", "b")`, 8, false, 8, 9, true},       // The second string
+		{`"a \" b"`, 2, false, 1, 7, true},          // Escaped quote skipped
+		{`f("a")`, 5, false, 0, 0, false},           // After the last string
+		{`say("hi")`, 6, true, 4, 8, true},          // No blanks around
+		{`say( "hi")`, 6, true, 4, 9, true},         // Leading blank when none follow
+	}
+	for _, tt := range tests {
+		start, end, ok := Quote([]rune(tt.line), tt.col, '"', tt.around)
+		if ok != tt.ok || (ok && (start != tt.start || end != tt.end)) {
+			t.Errorf("Quote(%q, %d, %v) = %d, %d, %v; want %d, %d, %v", tt.line, tt.col, tt.around, start, end, ok, tt.start, tt.end, tt.ok)
+		}
+	}
+}
+
+func lines(text string) [][]byte {
+	var out [][]byte
+	for _, l := range strings.Split(text, "\n") {
+		out = append(out, []byte(l))
+	}
+	return out
+}
+
+func pos(line, col int) types.Position { return types.Position{Line: line, Col: col} }
+
+func TestBracket(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		at     types.Position
+		r      rune
+		around bool
+		want   Range
+		ok     bool
+	}{
+		{"inner", "f(a, (b), c)", pos(0, 3), '(', false, Range{Start: pos(0, 2), End: pos(0, 11)}, true},
+		{"nested", "f(a, (b), c)", pos(0, 6), 'b', false, Range{Start: pos(0, 6), End: pos(0, 7)}, true},
+		{"around", "f(a, (b), c)", pos(0, 6), ')', true, Range{Start: pos(0, 5), End: pos(0, 8)}, true},
+		{"on open", "f(a)", pos(0, 1), '(', false, Range{Start: pos(0, 2), End: pos(0, 3)}, true},
+		{"on close", "f((a))", pos(0, 5), '(', false, Range{Start: pos(0, 2), End: pos(0, 5)}, true},
+		{"across lines", "if x {\n\ta()\n\tb()\n}", pos(1, 1), 'B', false,
+			Range{Start: pos(1, 0), End: pos(2, 4), Linewise: true}, true},
+		{"across lines around", "if x {\n\ta()\n}", pos(1, 1), '{', true, Range{Start: pos(0, 5), End: pos(2, 1)}, true},
+		{"empty block", "f {\n}", pos(0, 2), '{', false, Range{Start: pos(0, 3), End: pos(0, 3)}, true},
+		{"mid-line across", "[1,\n2]", pos(1, 0), '[', false, Range{Start: pos(0, 1), End: pos(1, 1)}, true},
+		{"none", "a (b) c", pos(0, 6), '(', false, Range{}, false},
+		{"unclosed", "f(a", pos(0, 2), '(', false, Range{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := Object(lines(tt.text), tt.at, tt.r, tt.around)
+		if ok != tt.ok || (ok && got != tt.want) {
+			t.Errorf("%s: Object(%q, %v, %q, %v) = %+v, %v; want %+v, %v", tt.name, tt.text, tt.at, tt.r, tt.around, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestMatchBracket(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		at   types.Position
+		want types.Position
+		ok   bool
+	}{
+		{"forward", "f(a, (b), c)", pos(0, 1), pos(0, 11), true},
+		{"backward", "f(a, (b), c)", pos(0, 11), pos(0, 1), true},
+		{"inner", "f(a, (b), c)", pos(0, 7), pos(0, 5), true},
+		{"other kinds ignored", "{ a[0] = ( }", pos(0, 0), pos(0, 11), true},
+		{"across lines", "func f() {\n\tif x {\n\t}\n}", pos(3, 0), pos(0, 9), true},
+		{"unmatched", "(a", pos(0, 0), types.Position{}, false},
+		{"not a bracket", "a(b)", pos(0, 0), types.Position{}, false},
+		{"too far", "(\n\n\n\n)", pos(0, 0), types.Position{}, false},
+	}
+	for _, tt := range tests {
+		text := lines(tt.text)
+		line := func(i int) ([]byte, error) {
+			if i >= len(text) {
+				return nil, fmt.Errorf("line %d out of range", i)
+			}
+			return text[i], nil
+		}
+		got, ok := MatchBracket(line, tt.at, 3)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("%s: MatchBracket(%q, %v) = %v, %v; want %v, %v", tt.name, tt.text, tt.at, got, ok, tt.want, tt.ok)
+		}
+	}
+	if col, ok := BracketOnLine([]rune("x := f(y)"), 2); !ok || col != 6 {
+		t.Errorf("BracketOnLine = %d, %v; want 6, true", col, ok)
+	}
+}
@@ -0,0 +1,86 @@
+// Package transform holds the text encoders/decoders offered by :transform.
+package transform
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Func converts text, failing when the input is not valid for a decoder.
+type Func func(text string) (string, error)
+
+var transforms = map[string]Func{
+	"base64-encode": func(s string) (string, error) {
+		return base64.StdEncoding.EncodeToString([]byte(s)), nil
+	},
+	"base64-decode": base64Decode,
+	"url-encode": func(s string) (string, error) {
+		return url.QueryEscape(s), nil
+	},
+	"url-decode":    url.QueryUnescape,
+	"html-escape":   func(s string) (string, error) { return html.EscapeString(s), nil },
+	"html-unescape": func(s string) (string, error) { return html.UnescapeString(s), nil },
+	"json-escape":   jsonEscape,
+	"json-unescape": jsonUnescape,
+}
+
+// Names returns the available transform names, sorted.
+func Names() []string {
+	names := make([]string, 0, len(transforms))
+	for name := range transforms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Apply runs the named transform on text.
+func Apply(name, text string) (string, error) {
+	fn, ok := transforms[name]
+	if !ok {
+		return "", fmt.Errorf("unknown transform %q (available: %s)", name, strings.Join(Names(), ", "))
+	}
+	return fn(text)
+}
+
+// base64Decode accepts standard and URL-safe alphabets, with or without
+// padding, and ignores line breaks.
+func base64Decode(s string) (string, error) {
+	s = strings.NewReplacer("\n", "", "\r", "").Replace(strings.TrimSpace(s))
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if b, err := enc.DecodeString(s); err == nil {
+			return string(b), nil
+		}
+	}
+	return "", fmt.Errorf("not valid base64")
+}
+
+// jsonEscape returns s escaped for use inside a JSON string, without the
+// surrounding quotes.
+func jsonEscape(s string) (string, error) {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(s); err != nil {
+		return "", err
+	}
+	quoted := strings.TrimSuffix(b.String(), "\n")
+	return quoted[1 : len(quoted)-1], nil
+}
+
+// jsonUnescape reverses jsonEscape. Surrounding quotes are optional.
+func jsonUnescape(s string) (string, error) {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		s = `"` + s + `"`
+	}
+	var out string
+	if err := json.Unmarshal([]byte(s), &out); err != nil {
+		return "", fmt.Errorf("not a valid JSON string: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,38 @@
+package transform
+
+import "testing"
+
+func TestRoundTrips(t *testing.T) {
+	text := "a <b> & \"c\" /d?e=f\n\tü"
+	pairs := [][2]string{
+		{"base64-encode", "base64-decode"},
+		{"url-encode", "url-decode"},
+		{"html-escape", "html-unescape"},
+		{"json-escape", "json-unescape"},
+	}
+	for _, p := range pairs {
+		encoded, err := Apply(p[0], text)
+		if err != nil {
+			t.Fatalf("%s: %v", p[0], err)
+		}
+		decoded, err := Apply(p[1], encoded)
+		if err != nil || decoded != text {
+			t.Errorf("%s(%s(x)) = %q, %v; want %q", p[1], p[0], decoded, err, text)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	if got, _ := Apply("json-escape", "say \"hi\"\n"); got != `say \"hi\"\n` {
+		t.Errorf("json-escape = %q", got)
+	}
+	if got, _ := Apply("base64-decode", "aGk"); got != "hi" {
+		t.Errorf("unpadded base64-decode = %q", got)
+	}
+	if _, err := Apply("base64-decode", "%%%"); err == nil {
+		t.Errorf("invalid base64 should fail")
+	}
+	if _, err := Apply("rot13", "x"); err == nil {
+		t.Errorf("unknown transform should fail")
+	}
+}
@@ -0,0 +1,46 @@
+package core
+
+import "github.com/bethropolis/tide/internal/types"
+
+// View is where a window looks into a buffer: its cursor and scroll
+// position. Windows on the same buffer each keep their own; the editor's
+// cursor and viewport belong to whichever of them is being used.
+type View struct {
+	Cursor    types.Position
+	Top, Left int // Viewport
+}
+
+// SaveView returns the cursor and scroll position.
+func (e *Editor) SaveView() View {
+	top, left := e.GetViewport()
+	return View{Cursor: e.GetCursor(), Top: top, Left: left}
+}
+
+// RestoreView moves the cursor and viewport back to v, clamped to the
+// buffer as it is now and scrolled if need be to show the cursor.
+func (e *Editor) RestoreView(v View) {
+	if e.cursorManager == nil {
+		return
+	}
+	e.cursorManager.SetViewport(v.Top, v.Left)
+	e.cursorManager.SetPosition(v.Cursor)
+}
+
+// Adjust keeps v on the same text after an edit made through another
+// window: lines inserted or deleted above it move it down or up, and a
+// cursor inside deleted lines moves to where they were.
+func (v *View) Adjust(edit types.EditInfo) {
+	start, oldEnd := int(edit.StartPosition.Row), int(edit.OldEndPosition.Row)
+	delta := int(edit.NewEndPosition.Row) - oldEnd
+	shift := func(line int) int {
+		switch {
+		case line > oldEnd:
+			return line + delta
+		case line > start:
+			return start
+		}
+		return line
+	}
+	v.Cursor.Line = shift(v.Cursor.Line)
+	v.Top = shift(v.Top)
+}
@@ -29,9 +29,17 @@ const (
 
 	// Plugin specific events can be defined later or use custom data
 
-	TypeThemeChanged      // Fired when the theme is changed
-	TypeTriggerFuzzyFind  // Fired to open fuzzy finder
-	TypeHighlightComplete // Fired when async syntax highlighting finishes
+	TypeThemeChanged            // Fired when the theme is changed
+	TypeTriggerFuzzyFind        // Fired to open fuzzy finder
+	TypeHighlightComplete       // Fired when async syntax highlighting finishes
+	TypeTriggerCommandPalette   // Fired to open the command palette
+	TypeTriggerClipboardHistory // Fired to open the clipboard history picker
+	TypeTriggerHover            // Fired to show documentation for the symbol under the cursor (K)
+	TypeTriggerCodeActions      // Fired to open the code action menu at the cursor (<leader>a)
+	TypeContentChanged          // Fired at most once per content_change_interval after edits, with the whole text
+	TypeBufferRenamed           // Fired after the buffer's file is renamed (:rename)
+	TypeFileDeleted             // Fired after the buffer's file is deleted or trashed
+	TypeCursorHold              // Fired once the cursor has rested in one place for a moment
 )
 
 // Event is the structure passed through the event bus.
@@ -46,7 +54,16 @@ type Event struct {
 
 // BufferModifiedData contains info about buffer changes, including EditInfo.
 type BufferModifiedData struct {
-	Edit types.EditInfo // Information about the change for incremental parsing
+	Edit     types.EditInfo // Information about the change for incremental parsing
+	FilePath string         // File of the buffer changed; empty for an unnamed buffer
+}
+
+// ContentChangedData carries the whole text of a buffer that was edited,
+// for plugins that mirror it elsewhere (live previews, sync). Edits made
+// within one content_change_interval arrive as one event.
+type ContentChangedData struct {
+	FilePath string
+	Text     []byte // A copy; safe to keep
 }
 
 // BufferLoadedData contains info about the loaded buffer.
@@ -60,12 +77,30 @@ type BufferSavedData struct {
 	FilePath string
 }
 
+// BufferRenamedData contains the previous and new file paths.
+type BufferRenamedData struct {
+	OldPath string
+	NewPath string
+}
+
+// FileDeletedData describes a file removed from disk. TrashPath is set when
+// the file was moved to the trash rather than unlinked.
+type FileDeletedData struct {
+	FilePath  string
+	TrashPath string
+}
+
 // CursorMovedData contains the new cursor position.
 type CursorMovedData struct {
 	OldPosition types.Position
 	NewPosition types.Position
 }
 
+// CursorHoldData contains the position the cursor is resting at.
+type CursorHoldData struct {
+	Position types.Position
+}
+
 // KeyPressedData contains the raw tcell key event.
 type KeyPressedData struct {
 	KeyEvent *tcell.EventKey
@@ -86,6 +121,18 @@ type ThemeChangedData struct {
 // TriggerFuzzyFindData is empty for now
 type TriggerFuzzyFindData struct{}
 
+// TriggerCommandPaletteData is empty for now
+type TriggerCommandPaletteData struct{}
+
+// TriggerClipboardHistoryData is empty for now
+type TriggerClipboardHistoryData struct{}
+
+// TriggerHoverData is empty for now
+type TriggerHoverData struct{}
+
+// TriggerCodeActionsData is empty for now
+type TriggerCodeActionsData struct{}
+
 // HighlightCompleteData is fired by the highlight manager when a background
 // highlighting pass finishes (successfully or with cleared results).
 type HighlightCompleteData struct{}
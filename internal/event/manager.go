@@ -57,6 +57,14 @@ func (m *Manager) Unsubscribe(eventType Type, id SubscriptionID) {
 	}
 }
 
+// HasSubscribers reports whether any handler listens for eventType, so
+// events that are costly to build can be skipped when nobody would see them.
+func (m *Manager) HasSubscribers(eventType Type) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.handlers[eventType]) > 0
+}
+
 func (m *Manager) Dispatch(eventType Type, data interface{}) {
 	event := Event{
 		Type: eventType,
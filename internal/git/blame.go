@@ -0,0 +1,152 @@
+// Package git asks the git command line tool about the files being edited.
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BlameLine is the commit that last changed one line.
+type BlameLine struct {
+	Hash    string
+	Author  string
+	Time    time.Time // Author date
+	Summary string    // First line of the commit message
+}
+
+// Committed reports whether the line is part of a commit; lines changed in
+// the working tree or the editor are not.
+func (b BlameLine) Committed() bool {
+	return strings.Trim(b.Hash, "0") != ""
+}
+
+// Describe renders the line's commit as "author, 3 days ago • summary",
+// with the age measured from now.
+func (b BlameLine) Describe(now time.Time) string {
+	if !b.Committed() {
+		return "Not committed yet"
+	}
+	return fmt.Sprintf("%s, %s • %s", b.Author, RelativeTime(now.Sub(b.Time)), b.Summary)
+}
+
+// Blame returns the commit of each line of contents, taken as the current
+// text of the file at path, so unsaved edits are blamed as uncommitted
+// instead of shifting the lines below them.
+func Blame(ctx context.Context, path string, contents []byte) ([]BlameLine, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	out, err := run(ctx, filepath.Dir(abs), contents, "blame", "--porcelain", "--contents", "-", "--", filepath.Base(abs))
+	if err != nil {
+		return nil, err
+	}
+	return parseBlame(out)
+}
+
+// parseBlame reads the output of git blame --porcelain. Each line of the
+// file comes as a header naming its commit and line number, followed by
+// the commit's details the first time that commit appears, and then the
+// line itself prefixed with a tab.
+func parseBlame(out []byte) ([]BlameLine, error) {
+	commits := make(map[string]*BlameLine)
+	var lines []BlameLine
+	var current *BlameLine
+	finalLine := 0
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		text := scanner.Text()
+		if strings.HasPrefix(text, "\t") {
+			if current == nil || finalLine < 1 {
+				return nil, fmt.Errorf("blame: line content before its header")
+			}
+			for len(lines) < finalLine {
+				lines = append(lines, BlameLine{})
+			}
+			lines[finalLine-1] = *current
+			continue
+		}
+		key, value, _ := strings.Cut(text, " ")
+		if len(key) == 40 && isHex(key) {
+			fields := strings.Fields(value)
+			if len(fields) < 2 {
+				return nil, fmt.Errorf("blame: malformed header %q", text)
+			}
+			n, err := strconv.Atoi(fields[1])
+			if err != nil {
+				return nil, fmt.Errorf("blame: malformed header %q", text)
+			}
+			finalLine = n
+			if current = commits[key]; current == nil {
+				current = &BlameLine{Hash: key}
+				commits[key] = current
+			}
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		switch key {
+		case "author":
+			current.Author = value
+		case "author-time":
+			if secs, err := strconv.ParseInt(value, 10, 64); err == nil {
+				current.Time = time.Unix(secs, 0)
+			}
+		case "summary":
+			current.Summary = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// isHex reports whether s consists of lowercase hex digits only.
+func isHex(s string) bool {
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// RelativeTime renders an age the way people say it: "just now",
+// "5 minutes ago", "3 months ago".
+func RelativeTime(d time.Duration) string {
+	const (
+		day   = 24 * time.Hour
+		month = 30 * day
+		year  = 365 * day
+	)
+	plural := func(n int64, unit string) string {
+		if n == 1 {
+			return "1 " + unit + " ago"
+		}
+		return fmt.Sprintf("%d %ss ago", n, unit)
+	}
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return plural(int64(d/time.Minute), "minute")
+	case d < day:
+		return plural(int64(d/time.Hour), "hour")
+	case d < month:
+		return plural(int64(d/day), "day")
+	case d < year:
+		return plural(int64(d/month), "month")
+	default:
+		return plural(int64(d/year), "year")
+	}
+}
@@ -0,0 +1,74 @@
+package git
+
+import (
+	"testing"
+	"time"
+)
+
+const porcelain = `e962ba840e28613085b51a25f6f028524d8761e8 1 1 1
+author Ann
+author-mail <a@x>
+author-time 1700000000
+author-tz +0000
+summary Add f
+boundary
+filename f.txt
+	a
+0000000000000000000000000000000000000000 2 2 1
+author Not Committed Yet
+author-time 1700000100
+summary Version of f.txt from standard input
+filename f.txt
+	z
+e962ba840e28613085b51a25f6f028524d8761e8 2 3 1
+	b
+`
+
+func TestParseBlame(t *testing.T) {
+	lines, err := parseBlame([]byte(porcelain))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3", len(lines))
+	}
+	for _, i := range []int{0, 2} {
+		l := lines[i]
+		if l.Author != "Ann" || l.Summary != "Add f" || !l.Time.Equal(time.Unix(1700000000, 0)) || !l.Committed() {
+			t.Errorf("line %d = %+v", i+1, l)
+		}
+	}
+	if lines[1].Committed() {
+		t.Errorf("line 2 should be uncommitted: %+v", lines[1])
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	l := BlameLine{Hash: "e962ba840e28613085b51a25f6f028524d8761e8", Author: "Ann", Time: now.Add(-3 * 24 * time.Hour), Summary: "Add f"}
+	if got, want := l.Describe(now), "Ann, 3 days ago • Add f"; got != want {
+		t.Errorf("Describe() = %q, want %q", got, want)
+	}
+	l.Hash = "0000000000000000000000000000000000000000"
+	if got, want := l.Describe(now), "Not committed yet"; got != want {
+		t.Errorf("Describe() = %q, want %q", got, want)
+	}
+}
+
+func TestRelativeTime(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{10 * time.Second, "just now"},
+		{time.Minute, "1 minute ago"},
+		{5 * time.Hour, "5 hours ago"},
+		{45 * 24 * time.Hour, "1 month ago"},
+		{800 * 24 * time.Hour, "2 years ago"},
+	}
+	for _, tt := range tests {
+		if got := RelativeTime(tt.d); got != tt.want {
+			t.Errorf("RelativeTime(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
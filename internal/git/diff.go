@@ -0,0 +1,216 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/bethropolis/tide/internal/core/diff"
+)
+
+// Hunk is one changed region between two versions of a file, as in a
+// unified diff without context lines.
+type Hunk struct {
+	OldStart, OldCount int      // First line (1-based) and line count in the old version
+	NewStart, NewCount int      // Same for the new version
+	Lines              []string // Removed lines prefixed with "-", then added ones with "+"
+}
+
+// Header returns the hunk's "@@ -a,b +c,d @@" line.
+func (h Hunk) Header() string {
+	return fmt.Sprintf("@@ -%d,%d +%d,%d @@", h.OldStart, h.OldCount, h.NewStart, h.NewCount)
+}
+
+// Contains reports whether the 0-based line of the new version belongs to
+// the hunk. A deletion belongs to the line it follows.
+func (h Hunk) Contains(line int) bool {
+	if h.NewCount == 0 {
+		return line == max(h.NewStart-1, 0)
+	}
+	return line >= h.NewStart-1 && line < h.NewStart-1+h.NewCount
+}
+
+// Old returns the text the hunk removes, that is its lines in the old version.
+func (h Hunk) Old() []byte {
+	var b bytes.Buffer
+	removing := false
+	for _, l := range h.Lines {
+		switch {
+		case strings.HasPrefix(l, "-"):
+			b.WriteString(l[1:])
+			b.WriteByte('\n')
+			removing = true
+		case strings.HasPrefix(l, `\`) && removing:
+			b.Truncate(b.Len() - 1) // "\ No newline at end of file"
+			removing = false
+		default:
+			removing = false
+		}
+	}
+	return b.Bytes()
+}
+
+// HunkAt returns the hunk containing the 0-based line, if any.
+func HunkAt(hunks []Hunk, line int) (Hunk, bool) {
+	for _, h := range hunks {
+		if h.Contains(line) {
+			return h, true
+		}
+	}
+	return Hunk{}, false
+}
+
+// run runs git in dir, feeding it stdin, and returns its output. Errors
+// carry git's own message when it printed one.
+func run(ctx context.Context, dir string, stdin []byte, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return out, errors.New(msg)
+		}
+	}
+	return out, err
+}
+
+// Show returns the content of the file at path as of rev: "HEAD", any other
+// commit, or "" for the version in the index.
+func Show(ctx context.Context, rev, path string) ([]byte, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	return run(ctx, filepath.Dir(abs), nil, "show", rev+":./"+filepath.Base(abs))
+}
+
+// Diff returns the hunks turning old into new, as git diff -U0 would. The
+// diff is worked out in process, so git need not be installed.
+func Diff(ctx context.Context, old, new []byte) ([]Hunk, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	oldLines, newLines := diff.SplitLines(old), diff.SplitLines(new)
+	var hunks []Hunk
+	for _, c := range diff.Lines(oldLines, newLines) {
+		h := Hunk{
+			OldStart: hunkStart(c.OldStart, c.OldEnd), OldCount: c.OldEnd - c.OldStart,
+			NewStart: hunkStart(c.NewStart, c.NewEnd), NewCount: c.NewEnd - c.NewStart,
+		}
+		h.Lines = appendHunkLines(h.Lines, "-", oldLines[c.OldStart:c.OldEnd])
+		h.Lines = appendHunkLines(h.Lines, "+", newLines[c.NewStart:c.NewEnd])
+		hunks = append(hunks, h)
+	}
+	return hunks, nil
+}
+
+// hunkStart returns the 1-based start line of a hunk header for the
+// 0-based range [start, end): an empty range names the line before it.
+func hunkStart(start, end int) int {
+	if start == end {
+		return start
+	}
+	return start + 1
+}
+
+// appendHunkLines adds lines to a hunk with prefix, marking a last line
+// without a newline as git does.
+func appendHunkLines(out []string, prefix string, lines [][]byte) []string {
+	for _, line := range lines {
+		text, ended := bytes.CutSuffix(line, []byte("\n"))
+		out = append(out, prefix+string(text))
+		if !ended {
+			out = append(out, `\ No newline at end of file`)
+		}
+	}
+	return out
+}
+
+// parseHunks reads the hunks of a unified diff made with -U0.
+func parseHunks(out []byte) ([]Hunk, error) {
+	var hunks []Hunk
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		text := scanner.Text()
+		switch {
+		case strings.HasPrefix(text, "@@ "):
+			h, err := parseHunkHeader(text)
+			if err != nil {
+				return nil, err
+			}
+			hunks = append(hunks, h)
+		case len(hunks) == 0:
+			// File header
+		case strings.HasPrefix(text, "-"), strings.HasPrefix(text, "+"), strings.HasPrefix(text, `\`):
+			h := &hunks[len(hunks)-1]
+			h.Lines = append(h.Lines, text)
+		}
+	}
+	return hunks, scanner.Err()
+}
+
+// parseHunkHeader parses "@@ -a[,b] +c[,d] @@ ...".
+func parseHunkHeader(line string) (Hunk, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 3 || !strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") {
+		return Hunk{}, fmt.Errorf("diff: malformed hunk header %q", line)
+	}
+	var h Hunk
+	var err error
+	if h.OldStart, h.OldCount, err = parseRange(fields[1][1:]); err != nil {
+		return Hunk{}, fmt.Errorf("diff: malformed hunk header %q", line)
+	}
+	if h.NewStart, h.NewCount, err = parseRange(fields[2][1:]); err != nil {
+		return Hunk{}, fmt.Errorf("diff: malformed hunk header %q", line)
+	}
+	return h, nil
+}
+
+// parseRange parses "start[,count]"; the count defaults to 1.
+func parseRange(s string) (start, count int, err error) {
+	startStr, countStr, hasCount := strings.Cut(s, ",")
+	if start, err = strconv.Atoi(startStr); err != nil {
+		return 0, 0, err
+	}
+	if !hasCount {
+		return start, 1, nil
+	}
+	count, err = strconv.Atoi(countStr)
+	return start, count, err
+}
+
+// StageHunk adds a hunk of a diff between the index and the file at path to
+// the index, leaving the working tree as it is.
+func StageHunk(ctx context.Context, path string, h Hunk) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(abs)
+	prefix, err := run(ctx, dir, nil, "rev-parse", "--show-prefix")
+	if err != nil {
+		return err
+	}
+	name := strings.TrimSpace(string(prefix)) + filepath.Base(abs)
+
+	var patch strings.Builder
+	fmt.Fprintf(&patch, "diff --git a/%s b/%s\n--- a/%s\n+++ b/%s\n", name, name, name, name)
+	patch.WriteString(h.Header() + "\n")
+	for _, l := range h.Lines {
+		patch.WriteString(l + "\n")
+	}
+	_, err = run(ctx, dir, []byte(patch.String()), "apply", "--cached", "--unidiff-zero", "-")
+	return err
+}
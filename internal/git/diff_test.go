@@ -0,0 +1,112 @@
+package git
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func requireGit(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+}
+
+func TestParseHunks(t *testing.T) {
+	out := `diff --git a/old b/new
+--- a/old
++++ b/new
+@@ -2 +2 @@ a
+-b
++B
+@@ -3,0 +4 @@ c
++d
+@@ -5,2 +6,0 @@
+-e
+-f
+\ No newline at end of file
+`
+	hunks, err := parseHunks([]byte(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Hunk{
+		{OldStart: 2, OldCount: 1, NewStart: 2, NewCount: 1, Lines: []string{"-b", "+B"}},
+		{OldStart: 3, OldCount: 0, NewStart: 4, NewCount: 1, Lines: []string{"+d"}},
+		{OldStart: 5, OldCount: 2, NewStart: 6, NewCount: 0, Lines: []string{"-e", "-f", `\ No newline at end of file`}},
+	}
+	if !reflect.DeepEqual(hunks, want) {
+		t.Fatalf("parseHunks() = %+v, want %+v", hunks, want)
+	}
+	if got := string(hunks[2].Old()); got != "e\nf" {
+		t.Errorf("Old() = %q, want %q", got, "e\nf")
+	}
+	if h, ok := HunkAt(hunks, 5); !ok || h.OldStart != 5 {
+		t.Errorf("HunkAt(5) = %+v, %v; want the deletion after line 6", h, ok)
+	}
+	if _, ok := HunkAt(hunks, 2); ok {
+		t.Errorf("HunkAt(2) found a hunk on an unchanged line")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	hunks, err := Diff(context.Background(), []byte("a\nb\nc\n"), []byte("a\nB\nc\nd\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hunks) != 2 || hunks[0].Header() != "@@ -2,1 +2,1 @@" || hunks[1].Header() != "@@ -3,0 +4,1 @@" {
+		t.Fatalf("Diff() = %+v", hunks)
+	}
+	if hunks, err := Diff(context.Background(), []byte("same\n"), []byte("same\n")); err != nil || len(hunks) != 0 {
+		t.Errorf("Diff() of equal texts = %+v, %v", hunks, err)
+	}
+	hunks, err = Diff(context.Background(), []byte("a\ne\nf"), []byte("a\n"))
+	want := []Hunk{{OldStart: 2, OldCount: 2, NewStart: 1, NewCount: 0, Lines: []string{"-e", "-f", `\ No newline at end of file`}}}
+	if err != nil || !reflect.DeepEqual(hunks, want) {
+		t.Errorf("Diff() = %+v, %v; want %+v", hunks, err, want)
+	}
+}
+
+func TestStageHunk(t *testing.T) {
+	requireGit(t)
+	repo := t.TempDir()
+	gitIn := func(args ...string) string {
+		t.Helper()
+		out, err := run(context.Background(), repo, nil, append([]string{"-c", "user.name=T", "-c", "user.email=t@example.com"}, args...)...)
+		if err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+		return string(out)
+	}
+	gitIn("init", "-q")
+	path := filepath.Join(repo, "sub", "f.txt")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("a\nb\nc\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	gitIn("add", ".")
+	gitIn("commit", "-qm", "init")
+
+	ctx := context.Background()
+	text := []byte("a\nB\nc\nd\n")
+	base, err := Show(ctx, "", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hunks, err := Diff(ctx, base, text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := StageHunk(ctx, path, hunks[1]); err != nil {
+		t.Fatal(err)
+	}
+	if got := gitIn("show", ":sub/f.txt"); got != "a\nb\nc\nd\n" {
+		t.Errorf("index after staging = %q", got)
+	}
+}
@@ -0,0 +1,186 @@
+package highlighter
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/bethropolis/tide/internal/highlighter/lang"
+	"github.com/bethropolis/tide/internal/logger"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// DefinitionKind tells functions from classes and other type definitions.
+type DefinitionKind int
+
+const (
+	DefinitionFunction DefinitionKind = iota // Functions and methods
+	DefinitionClass                          // Classes, structs, traits, impls, type declarations
+)
+
+// Definition is the extent of one definition in the syntax tree. Columns
+// are byte offsets, as tree-sitter reports them.
+type Definition struct {
+	Kind      DefinitionKind
+	StartLine int
+	StartCol  int
+	EndLine   int
+}
+
+// definitionQueries caches the compiled textobjects query per language; a
+// nil entry means the language has none or it failed to compile.
+var definitionQueries sync.Map // *lang.Language -> *sitter.Query
+
+// definitionQuery returns the compiled textobjects query for language.
+func definitionQuery(language *lang.Language) *sitter.Query {
+	if q, ok := definitionQueries.Load(language); ok {
+		return q.(*sitter.Query)
+	}
+	var query *sitter.Query
+	if src := language.GetNamedQuery("textobjects"); src != nil {
+		q, err := sitter.NewQuery(src, language.TreeSitterLang)
+		if err != nil {
+			logger.Warnf("Failed to parse textobjects query for %s: %v", language.Name, err)
+		} else {
+			query = q
+		}
+	}
+	actual, _ := definitionQueries.LoadOrStore(language, query)
+	return actual.(*sitter.Query)
+}
+
+// Definitions returns the function and class definitions in tree, ordered
+// by where they start. Languages without a textobjects query have none.
+func Definitions(tree *sitter.Tree, language *lang.Language) []Definition {
+	if tree == nil || language == nil {
+		return nil
+	}
+	query := definitionQuery(language)
+	if query == nil {
+		return nil
+	}
+	qc := sitter.NewQueryCursor()
+	defer qc.Close()
+	qc.Exec(query, tree.RootNode())
+
+	var defs []Definition
+	for {
+		match, ok := qc.NextMatch()
+		if !ok {
+			break
+		}
+		for _, capture := range match.Captures {
+			kind, ok := captureKind(query.CaptureNameForId(capture.Index))
+			if !ok {
+				continue
+			}
+			start, end := capture.Node.StartPoint(), capture.Node.EndPoint()
+			defs = append(defs, Definition{
+				Kind:      kind,
+				StartLine: int(start.Row),
+				StartCol:  int(start.Column),
+				EndLine:   int(end.Row),
+			})
+		}
+	}
+	sort.SliceStable(defs, func(i, j int) bool {
+		if defs[i].StartLine != defs[j].StartLine {
+			return defs[i].StartLine < defs[j].StartLine
+		}
+		return defs[i].StartCol < defs[j].StartCol
+	})
+	return defs
+}
+
+// captureKind returns the kind of definition a textobjects capture marks.
+func captureKind(name string) (DefinitionKind, bool) {
+	switch {
+	case strings.HasPrefix(name, "function"):
+		return DefinitionFunction, true
+	case strings.HasPrefix(name, "class"):
+		return DefinitionClass, true
+	}
+	return 0, false
+}
+
+// Symbol is a named definition, as listed by symbol search. Line and Col
+// locate the name; Col is a byte offset.
+type Symbol struct {
+	Name string
+	Kind DefinitionKind
+	Line int
+	Col  int
+}
+
+// Symbols parses src as language and returns its named definitions,
+// ordered by where they start. It uses a parser of its own, so it may run
+// on any goroutine.
+func Symbols(src []byte, language *lang.Language) []Symbol {
+	if language == nil {
+		return nil
+	}
+	query := definitionQuery(language)
+	if query == nil {
+		return nil
+	}
+	parser := sitter.NewParser()
+	defer parser.Close()
+	parser.SetLanguage(language.TreeSitterLang)
+	tree, err := parser.ParseCtx(context.Background(), nil, src)
+	if err != nil {
+		return nil
+	}
+	defer tree.Close()
+
+	qc := sitter.NewQueryCursor()
+	defer qc.Close()
+	qc.Exec(query, tree.RootNode())
+
+	var symbols []Symbol
+	for {
+		match, ok := qc.NextMatch()
+		if !ok {
+			break
+		}
+		for _, capture := range match.Captures {
+			kind, ok := captureKind(query.CaptureNameForId(capture.Index))
+			if !ok {
+				continue
+			}
+			name := definitionName(capture.Node)
+			if name == nil {
+				continue
+			}
+			start := name.StartPoint()
+			symbols = append(symbols, Symbol{
+				Name: name.Content(src),
+				Kind: kind,
+				Line: int(start.Row),
+				Col:  int(start.Column),
+			})
+		}
+	}
+	sort.SliceStable(symbols, func(i, j int) bool {
+		if symbols[i].Line != symbols[j].Line {
+			return symbols[i].Line < symbols[j].Line
+		}
+		return symbols[i].Col < symbols[j].Col
+	})
+	return symbols
+}
+
+// definitionName returns the node naming a definition: its name field, the
+// name of the first child having one (a Go type_spec in a
+// type_declaration), or else the type a Rust impl is for.
+func definitionName(n *sitter.Node) *sitter.Node {
+	if name := n.ChildByFieldName("name"); name != nil {
+		return name
+	}
+	for i := 0; i < int(n.NamedChildCount()); i++ {
+		if name := n.NamedChild(i).ChildByFieldName("name"); name != nil {
+			return name
+		}
+	}
+	return n.ChildByFieldName("type")
+}
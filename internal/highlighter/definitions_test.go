@@ -0,0 +1,122 @@
+package highlighter
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/bethropolis/tide/internal/highlighter/lang"
+	"github.com/bethropolis/tide/internal/logger"
+)
+
+func TestMain(m *testing.M) {
+	// The highlighter logs; keep it out of the user's log file
+	cfg := logger.NewConfig()
+	cfg.LogFilePath = os.DevNull
+	logger.Init(cfg)
+	os.Exit(m.Run())
+}
+
+// parseDefinitions parses src as the language of path and returns its
+// definitions.
+func parseDefinitions(t *testing.T, path, src string) []Definition {
+	t.Helper()
+	h := NewHighlighter()
+	t.Cleanup(func() { h.parser.Close() })
+	language := lang.GetForFile(path)
+	if language == nil {
+		t.Fatalf("no language for %s", path)
+	}
+	_, tree, err := h.HighlightBuffer(context.Background(), []byte(src), language.TreeSitterLang, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tree.Close()
+	return Definitions(tree, language)
+}
+
+func TestDefinitions(t *testing.T) {
+	tests := []struct {
+		path, src string
+		want      []Definition
+	}{
+		{"x.go", "package x\n\ntype T struct{}\n\nfunc (T) M() {\n}\n\nfunc F() {}\n", []Definition{
+			{DefinitionClass, 2, 0, 2},
+			{DefinitionFunction, 4, 0, 5},
+			{DefinitionFunction, 7, 0, 7},
+		}},
+		{"x.py", "class C:\n    def m(self):\n        pass\n", []Definition{
+			{DefinitionClass, 0, 0, 2},
+			{DefinitionFunction, 1, 4, 2},
+		}},
+		{"x.js", "class C { m() {} }\nconst f = () => 1;\nfunction g() {}\n", []Definition{
+			{DefinitionClass, 0, 0, 0},
+			{DefinitionFunction, 0, 10, 0},
+			{DefinitionFunction, 1, 6, 1},
+			{DefinitionFunction, 2, 0, 2},
+		}},
+		{"x.rs", "struct S;\nimpl S {\n    fn f() {}\n}\n", []Definition{
+			{DefinitionClass, 0, 0, 0},
+			{DefinitionClass, 1, 0, 3},
+			{DefinitionFunction, 2, 4, 2},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got := parseDefinitions(t, tt.path, tt.src)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d definitions %v, want %v", len(got), got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("definition %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDefinitionsWithoutQuery(t *testing.T) {
+	if defs := parseDefinitions(t, "x.json", `{"a": 1}`); len(defs) != 0 {
+		t.Errorf("expected no definitions for JSON, got %v", defs)
+	}
+}
+
+func TestSymbols(t *testing.T) {
+	h := NewHighlighter() // Registers the languages
+	defer h.parser.Close()
+	tests := []struct {
+		path, src string
+		want      []Symbol
+	}{
+		{"x.go", "package x\n\ntype T struct{}\n\nfunc (T) M() {\n}\n", []Symbol{
+			{"T", DefinitionClass, 2, 5},
+			{"M", DefinitionFunction, 4, 9},
+		}},
+		{"x.py", "class C:\n    def m(self):\n        pass\n", []Symbol{
+			{"C", DefinitionClass, 0, 6},
+			{"m", DefinitionFunction, 1, 8},
+		}},
+		{"x.js", "const f = () => 1;\n", []Symbol{
+			{"f", DefinitionFunction, 0, 6},
+		}},
+		{"x.rs", "struct S;\nimpl S {\n    fn f() {}\n}\n", []Symbol{
+			{"S", DefinitionClass, 0, 7},
+			{"S", DefinitionClass, 1, 5},
+			{"f", DefinitionFunction, 2, 7},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got := Symbols([]byte(tt.src), lang.GetForFile(tt.path))
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d symbols %v, want %v", len(got), got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("symbol %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -24,6 +24,10 @@ type Language struct {
 
 	// QueryPath is the path to the highlight query file
 	QueryPath string
+
+	// LineComment starts a comment that runs to the end of the line, such
+	// as "//" or "#"; empty if the language has none
+	LineComment string
 }
 
 // GetQuery loads and returns the highlight query for this language
@@ -57,3 +61,16 @@ func (l *Language) GetQuery() []byte {
 	logger.Warnf("Failed to load query for language %s: %v", l.Name, err)
 	return nil
 }
+
+// GetNamedQuery loads queries/<QueryPath>/<name>.scm, such as the
+// "textobjects" query. Returns nil if the language has no such query.
+func (l *Language) GetNamedQuery(name string) []byte {
+	if QueryFS == nil || l.QueryPath == "" {
+		return nil
+	}
+	query, err := fs.ReadFile(QueryFS, fmt.Sprintf("queries/%s/%s.scm", l.QueryPath, name))
+	if err != nil {
+		return nil
+	}
+	return query
+}
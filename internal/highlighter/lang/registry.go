@@ -14,6 +14,7 @@ var (
 		sync.RWMutex
 		languages     []*Language
 		extToLanguage map[string]*Language
+		fileTypes     map[string]string // Extension to use for a path that has none
 		initialized   bool
 	}
 
@@ -26,6 +27,7 @@ func Initialize() {
 	initOnce.Do(func() {
 		registry.extToLanguage = make(map[string]*Language)
 		registry.languages = make([]*Language, 0)
+		registry.fileTypes = make(map[string]string)
 		registry.initialized = true
 		logger.Debugf("Language registry initialized")
 	})
@@ -62,14 +64,85 @@ func GetForFile(filePath string) *Language {
 	registry.RLock()
 	defer registry.RUnlock()
 
-	ext := strings.ToLower(filepath.Ext(filePath))
-	lang, ok := registry.extToLanguage[ext]
+	lang, ok := registry.extToLanguage[fileExt(filePath)]
 	if !ok {
 		return nil
 	}
 	return lang
 }
 
+// fileExt returns the extension that decides the type of filePath: the one
+// given with SetFileType, or that of its name, in lower case. The caller
+// holds the registry lock.
+func fileExt(filePath string) string {
+	if ext, ok := registry.fileTypes[filePath]; ok {
+		return ext
+	}
+	return strings.ToLower(filepath.Ext(trimURL(filePath)))
+}
+
+// lineComments are the line comment markers of common file types that
+// have no grammar, by extension or, for files named without one, by name.
+var lineComments = map[string]string{
+	".sh": "#", ".bash": "#", ".zsh": "#", ".fish": "#", ".rb": "#", ".pl": "#",
+	".r": "#", ".toml": "#", ".yaml": "#", ".yml": "#", ".conf": "#",
+	".gitignore": "#", "makefile": "#", "dockerfile": "#",
+	".c": "//", ".h": "//", ".cc": "//", ".cpp": "//", ".hpp": "//", ".cs": "//",
+	".java": "//", ".kt": "//", ".swift": "//", ".ts": "//", ".tsx": "//",
+	".jsx": "//", ".php": "//", ".scss": "//", ".zig": "//", ".dart": "//",
+	".lua": "--", ".sql": "--", ".hs": "--", ".elm": "--",
+	".ini": ";", ".el": ";", ".lisp": ";", ".clj": ";",
+	".tex": "%", ".erl": "%", ".vim": "\"",
+}
+
+// LineComment returns the marker that starts a comment running to the end
+// of the line in the language of filePath, such as "//", "#" or "--", or
+// "" when there is none or it is not known. Registered languages say
+// themselves; other common file types are looked up in a table.
+func LineComment(filePath string) string {
+	Initialize()
+
+	registry.RLock()
+	ext := fileExt(filePath)
+	lang, ok := registry.extToLanguage[ext]
+	registry.RUnlock()
+	if ok {
+		return lang.LineComment
+	}
+	if marker, ok := lineComments[ext]; ok {
+		return marker
+	}
+	return lineComments[strings.ToLower(filepath.Base(trimURL(filePath)))]
+}
+
+// SetFileType makes GetForFile treat filePath as if it had extension ext
+// (such as ".json"), for paths whose name does not tell their type. An empty
+// ext removes the association.
+func SetFileType(filePath, ext string) {
+	Initialize()
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	if ext == "" {
+		delete(registry.fileTypes, filePath)
+		return
+	}
+	registry.fileTypes[filePath] = strings.ToLower(ext)
+}
+
+// trimURL drops the query and fragment of a URL, so that the extension of
+// its path is found; other paths are returned as they are.
+func trimURL(filePath string) string {
+	if !strings.Contains(filePath, "://") {
+		return filePath
+	}
+	if i := strings.IndexAny(filePath, "?#"); i >= 0 {
+		return filePath[:i]
+	}
+	return filePath
+}
+
 // GetAll returns all registered languages
 func GetAll() []*Language {
 	Initialize()
@@ -32,6 +32,7 @@ func RegisterLanguages() {
 		TreeSitterLang: gosrc.GetLanguage(),
 		Extensions:     []string{".go"},
 		QueryPath:      "go", // Matches directory name under queries/
+		LineComment:    "//",
 	})
 
 	// Python
@@ -40,6 +41,7 @@ func RegisterLanguages() {
 		TreeSitterLang: pythonsrc.GetLanguage(),
 		Extensions:     []string{".py", ".pyw"}, // Add relevant extensions
 		QueryPath:      "python",
+		LineComment:    "#",
 	})
 
 	// JavaScript (Using JS parser)
@@ -48,6 +50,7 @@ func RegisterLanguages() {
 		TreeSitterLang: jssrc.GetLanguage(),
 		Extensions:     []string{".js", ".mjs", ".cjs"},
 		QueryPath:      "javascript", // Assumes queries/javascript/highlights.scm
+		LineComment:    "//",
 	})
 
 	lang.Register(&lang.Language{
@@ -63,6 +66,7 @@ func RegisterLanguages() {
 		TreeSitterLang: rustsrc.GetLanguage(),
 		Extensions:     []string{".rs"},
 		QueryPath:      "rust",
+		LineComment:    "//",
 	})
 
 
@@ -0,0 +1,6 @@
+; Definitions for structural navigation (]f/[f, ]t/[t)
+
+(function_declaration) @function.outer
+(method_declaration) @function.outer
+
+(type_declaration) @class.outer
@@ -0,0 +1,9 @@
+; Definitions for structural navigation (]f/[f, ]t/[t)
+
+(function_declaration) @function.outer
+(generator_function_declaration) @function.outer
+(method_definition) @function.outer
+(variable_declarator
+  value: [(arrow_function) (function_expression)]) @function.outer
+
+(class_declaration) @class.outer
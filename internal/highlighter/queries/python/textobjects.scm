@@ -0,0 +1,5 @@
+; Definitions for structural navigation (]f/[f, ]t/[t)
+
+(function_definition) @function.outer
+
+(class_definition) @class.outer
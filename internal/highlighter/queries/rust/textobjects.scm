@@ -0,0 +1,9 @@
+; Definitions for structural navigation (]f/[f, ]t/[t)
+
+(function_item) @function.outer
+
+(struct_item) @class.outer
+(enum_item) @class.outer
+(union_item) @class.outer
+(trait_item) @class.outer
+(impl_item) @class.outer
@@ -0,0 +1,190 @@
+// Package i18n looks up the user-facing strings of the editor (status
+// messages, prompts, mode names, help text) in a message catalog.
+//
+// The English catalog is built in. Translations live in the config
+// directory as locales/<locale>.toml, e.g. locales/de.toml or
+// locales/pt_BR.toml, and only need the keys they translate; the rest fall
+// back to English. An en.toml there rewords the English strings. Keys are
+// grouped into tables:
+//
+//	[status]
+//	saved = "Gespeichert: %s"
+//
+// is looked up as "status.saved". Values are fmt format strings taking the
+// same arguments, in the same order, as the English ones.
+package i18n
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+	"github.com/bethropolis/tide/internal/paths"
+)
+
+// LocalesDirName is the directory in the config dir holding translations.
+const LocalesDirName = "locales"
+
+//go:embed en.toml
+var englishTOML []byte
+
+var (
+	mu      sync.RWMutex
+	english = mustParse(englishTOML)
+	catalog map[string]string // Strings of the chosen locale; nil for English
+	locale  = "en"
+)
+
+// Load selects the locale lang ("de", "pt_BR", ...) or, when lang is
+// empty, the one named by $LC_ALL, $LC_MESSAGES or $LANG. It looks for
+// the most specific catalog first (pt_BR, then pt); without one the
+// English strings are used. An error means a catalog exists but could not
+// be read, and English is used instead.
+func Load(lang string) error {
+	if lang == "" {
+		lang = envLocale()
+	}
+	dir, err := paths.Config(LocalesDirName)
+	if err != nil {
+		setCatalog("en", nil)
+		return nil
+	}
+	for _, name := range candidates(lang) {
+		path := filepath.Join(dir, name+".toml")
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err == nil {
+			var strs map[string]string
+			if strs, err = parse(data); err == nil {
+				setCatalog(name, strs)
+				return nil
+			}
+		}
+		setCatalog("en", nil)
+		return fmt.Errorf("locale %s: %w", path, err)
+	}
+	setCatalog("en", nil)
+	return nil
+}
+
+// Locale returns the locale whose catalog is in use, "en" for the built-in one.
+func Locale() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return locale
+}
+
+// T returns the string for key in the current locale, formatted with args
+// when there are any. Keys missing from the locale use the English string,
+// and keys missing from both are returned as they are.
+func T(key string, args ...interface{}) string {
+	s, ok := Lookup(key)
+	if !ok {
+		s = key
+	}
+	if len(args) == 0 {
+		return s
+	}
+	return fmt.Sprintf(s, args...)
+}
+
+// Lookup returns the unformatted string for key in the current locale or
+// in English, and whether either has it.
+func Lookup(key string) (string, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	if s, ok := catalog[key]; ok {
+		return s, true
+	}
+	s, ok := english[key]
+	return s, ok
+}
+
+// Translated returns the string for key only when the current locale
+// translates it. Help text kept next to the code it documents (command and
+// action descriptions) uses it to stay in English otherwise.
+func Translated(key string) (string, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	s, ok := catalog[key]
+	return s, ok
+}
+
+func setCatalog(name string, strs map[string]string) {
+	mu.Lock()
+	defer mu.Unlock()
+	locale, catalog = name, strs
+}
+
+// envLocale returns the message locale from the environment, as POSIX
+// orders the variables.
+func envLocale() string {
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := os.Getenv(env); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// candidates turns a locale such as "pt_BR.UTF-8@euro" into the catalog
+// names to try: "pt_BR", "pt". "C" and "POSIX" mean English.
+func candidates(lang string) []string {
+	if i := strings.IndexAny(lang, ".@"); i >= 0 {
+		lang = lang[:i]
+	}
+	lang = strings.ReplaceAll(lang, "-", "_")
+	switch lang {
+	case "", "C", "POSIX":
+		return []string{"en"}
+	}
+	names := []string{lang}
+	if i := strings.IndexByte(lang, '_'); i > 0 {
+		names = append(names, lang[:i])
+	}
+	return names
+}
+
+// parse reads a catalog, flattening tables into dotted keys.
+func parse(data []byte) (map[string]string, error) {
+	var raw map[string]interface{}
+	if err := toml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	strs := make(map[string]string)
+	var flatten func(prefix string, m map[string]interface{}) error
+	flatten = func(prefix string, m map[string]interface{}) error {
+		for k, v := range m {
+			switch v := v.(type) {
+			case string:
+				strs[prefix+k] = v
+			case map[string]interface{}:
+				if err := flatten(prefix+k+".", v); err != nil {
+					return err
+				}
+			default:
+				return fmt.Errorf("%s%s: want a string, got %T", prefix, k, v)
+			}
+		}
+		return nil
+	}
+	if err := flatten("", raw); err != nil {
+		return nil, err
+	}
+	return strs, nil
+}
+
+func mustParse(data []byte) map[string]string {
+	strs, err := parse(data)
+	if err != nil {
+		panic("i18n: built-in catalog: " + err.Error())
+	}
+	return strs
+}
@@ -0,0 +1,74 @@
+package i18n
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCandidates(t *testing.T) {
+	tests := map[string][]string{
+		"pt_BR.UTF-8@euro": {"pt_BR", "pt"},
+		"de":               {"de"},
+		"en-US":            {"en_US", "en"},
+		"C":                {"en"},
+		"":                 {"en"},
+	}
+	for lang, want := range tests {
+		if got := candidates(lang); !reflect.DeepEqual(got, want) {
+			t.Errorf("candidates(%q) = %v, want %v", lang, got, want)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("TIDE_HOME", home)
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LANG", "de_DE.UTF-8")
+	defer setCatalog("en", nil)
+
+	dir := filepath.Join(home, LocalesDirName)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	de := "[status]\nsaved_to = \"Gespeichert: %s\"\n\n[command]\nw = \"Puffer schreiben\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "de.toml"), []byte(de), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Load(""); err != nil {
+		t.Fatal(err)
+	}
+	if Locale() != "de" {
+		t.Errorf("Locale() = %q, want de", Locale())
+	}
+	if got := T("status.saved_to", "a.txt"); got != "Gespeichert: a.txt" {
+		t.Errorf("translated string = %q", got)
+	}
+	if got := T("status.no_name"); got != "[No Name]" {
+		t.Errorf("untranslated string = %q, want the English one", got)
+	}
+	if got := T("no.such.key"); got != "no.such.key" {
+		t.Errorf("unknown key = %q, want the key", got)
+	}
+	if s, ok := Translated("command.w"); !ok || s != "Puffer schreiben" {
+		t.Errorf("Translated(command.w) = %q, %v", s, ok)
+	}
+	if _, ok := Translated("status.no_name"); ok {
+		t.Errorf("Translated reports an English-only key as translated")
+	}
+
+	if err := Load("fr"); err != nil || Locale() != "en" {
+		t.Errorf("Load(fr) without a catalog = %v, locale %q; want English", err, Locale())
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "de.toml"), []byte("[status]\nsaved_to = 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Load("de"); err == nil || Locale() != "en" {
+		t.Errorf("Load of a bad catalog = %v, locale %q; want an error and English", err, Locale())
+	}
+}
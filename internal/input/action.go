@@ -1,6 +1,12 @@
 // internal/input/action.go
 package input
 
+import (
+	"sort"
+
+	"github.com/bethropolis/tide/internal/i18n"
+)
+
 // Action represents a command or operation to be performed by the editor.
 type Action int
 
@@ -17,12 +23,19 @@ const (
 	ActionMoveDown
 	ActionMoveLeft
 	ActionMoveRight
+	ActionMoveWordForward  // Start of the next word, by Unicode word boundaries (Ctrl+Right)
+	ActionMoveWordBackward // Start of the previous word (Ctrl+Left)
+	ActionMatchBracket     // Bracket pairing with the one at the cursor (%)
 	ActionMovePageUp
 	ActionMovePageDown
 	ActionMoveHome // Beginning of line
 	ActionMoveEnd  // End of line
 	ActionMoveFileStart // Beginning of file (gg)
 	ActionMoveFileEnd   // End of file (G)
+	ActionJumpBack      // Back to where the cursor jumped from (Ctrl+O)
+	ActionJumpForward   // Forward again through the jump list (Ctrl+I)
+	ActionSetMark       // Set the mark named by the next key on the cursor (m)
+	ActionJumpToMark    // Go to the mark named by the next key (')
 
 	// --- Text Manipulation ---
 	ActionInsertRune         // Requires Rune argument
@@ -35,10 +48,11 @@ const (
 	ActionCut                // Cut selection to clipboard
 	ActionPaste              // Insert clipboard content
 	ActionPasteBefore        // Insert clipboard content before cursor
+	ActionPastePrimary       // Insert the X11/Wayland primary selection at cursor
 	ActionUndo               // Undo last edit
 	ActionRedo               // Redo previously undone edit
-	ActionDeleteWordForward  // Delete word forward (dw)
-	ActionDeleteWordBackward // Delete word backward (db)
+	ActionDeleteWordForward  // Delete to the next word start (Ctrl+Delete)
+	ActionDeleteWordBackward // Delete back to the previous word start (Ctrl+Backspace)
 
 	// --- Editor Mode ---
 	ActionEnterNormalMode   // Special action to return to Normal Mode
@@ -56,6 +70,40 @@ const (
 	ActionFindNext      // Find next occurrence (e.g., 'n')
 	ActionFindPrevious  // Find previous occurrence (e.g., 'N')
 	ActionFuzzyFind     // Fuzzy find files
+	ActionSearchWordForward  // Search forward for the identifier under the cursor ('*')
+	ActionSearchWordBackward // Search backward for the identifier under the cursor ('#')
+
+	// --- Discoverability ---
+	ActionCommandPalette   // Open the command palette (Ctrl+P)
+	ActionClipboardHistory // Pick an earlier yank to paste (<leader>")
+	ActionFileInfo         // Show the full path and size of the buffer (Ctrl+G)
+	ActionHover            // Show language server documentation for the symbol under the cursor (K)
+	ActionWindowCommand    // Wait for a window command key: split, move between or close windows (Ctrl+W)
+
+	// --- Completion ---
+	ActionCompleteNext // Complete the word before the cursor from buffer words (Ctrl+N)
+	ActionCompletePrev // Same, cycling backwards (Ctrl+P in insert mode)
+
+	// --- Transforms ---
+	ActionEvalSelection // Replace the selected arithmetic expression with its value ('=' in visual mode)
+	ActionCodeActions   // Pick a language server code action or quick fix at the cursor (<leader>a)
+	ActionToggleComment // Comment or uncomment the cursor line or the selected lines (Ctrl+/, <leader>c)
+
+	// --- Line editing ---
+	ActionDuplicateLine // Copy the cursor line or the selected lines below them (Alt+Shift+Down)
+	ActionDeleteLine    // Delete the cursor line or the selected lines (Ctrl+K)
+	ActionJoinLines     // Join the cursor line with the next, or the selected lines (J, <leader>j)
+	ActionMoveLineUp    // Move the cursor line or the selected lines up (Alt+Up)
+	ActionMoveLineDown  // Move the cursor line or the selected lines down (Alt+Down)
+
+	// --- Multiple cursors ---
+	ActionSelectNextOccurrence // Select the word under the cursor, then add its next occurrence with a cursor (Ctrl+D)
+
+	// --- Insertion helpers ---
+	ActionInsertUUID      // Insert a random UUID at the cursor (<leader>U)
+	ActionInsertDate      // Insert today's date (<leader>D)
+	ActionInsertTime      // Insert the current time (<leader>T)
+	ActionInsertTimestamp // Insert an ISO 8601 timestamp (<leader>I)
 
 	// --- Viewport / Other ---
 	// ActionScrollUp? ActionScrollDown? (Usually tied to cursor movement)
@@ -81,12 +129,19 @@ var actionNames = map[string]Action{
 	"move_down":         ActionMoveDown,
 	"move_left":         ActionMoveLeft,
 	"move_right":        ActionMoveRight,
+	"move_word_forward":    ActionMoveWordForward,
+	"move_word_backward":   ActionMoveWordBackward,
+	"match_bracket":        ActionMatchBracket,
 	"move_page_up":      ActionMovePageUp,
 	"move_page_down":    ActionMovePageDown,
 	"move_home":         ActionMoveHome,
 	"move_end":          ActionMoveEnd,
 	"move_file_start":   ActionMoveFileStart,
 	"move_file_end":     ActionMoveFileEnd,
+	"jump_back":            ActionJumpBack,
+	"jump_forward":         ActionJumpForward,
+	"set_mark":             ActionSetMark,
+	"jump_to_mark":         ActionJumpToMark,
 	"insert_rune":       ActionInsertRune,
 	"insert_new_line":   ActionInsertNewLine,
 	"insert_tab":        ActionInsertTab,
@@ -97,6 +152,7 @@ var actionNames = map[string]Action{
 	"cut":               ActionCut,
 	"paste":             ActionPaste,
 	"paste_before":      ActionPasteBefore,
+	"paste_primary":        ActionPastePrimary,
 	"undo":              ActionUndo,
 	"redo":              ActionRedo,
 	"delete_word_forward":  ActionDeleteWordForward,
@@ -114,6 +170,92 @@ var actionNames = map[string]Action{
 	"find_next":         ActionFindNext,
 	"find_previous":     ActionFindPrevious,
 	"fuzzy_find":        ActionFuzzyFind,
+	"search_word_forward":  ActionSearchWordForward,
+	"search_word_backward": ActionSearchWordBackward,
+	"command_palette":      ActionCommandPalette,
+	"clipboard_history":    ActionClipboardHistory,
+	"file_info":            ActionFileInfo,
+	"hover":                ActionHover,
+	"window_command":       ActionWindowCommand,
+	"complete_next":        ActionCompleteNext,
+	"complete_prev":        ActionCompletePrev,
+	"eval_selection":       ActionEvalSelection,
+	"code_actions":         ActionCodeActions,
+	"toggle_comment":       ActionToggleComment,
+	"duplicate_line":       ActionDuplicateLine,
+	"delete_line":          ActionDeleteLine,
+	"join_lines":           ActionJoinLines,
+	"move_line_up":         ActionMoveLineUp,
+	"move_line_down":       ActionMoveLineDown,
+	"select_next_match":    ActionSelectNextOccurrence,
+	"insert_uuid":          ActionInsertUUID,
+	"insert_date":          ActionInsertDate,
+	"insert_time":          ActionInsertTime,
+	"insert_timestamp":     ActionInsertTimestamp,
+}
+
+// actionDescriptions holds the one-line help text shown in the command
+// palette. Actions without an entry are internal and are not listed.
+var actionDescriptions = map[Action]string{
+	ActionQuit:                 "Quit (prompts when there are unsaved changes)",
+	ActionForceQuit:            "Quit without saving",
+	ActionSave:                 "Save the current buffer",
+	ActionMoveUp:               "Move cursor up",
+	ActionMoveDown:             "Move cursor down",
+	ActionMoveLeft:             "Move cursor left",
+	ActionMoveRight:            "Move cursor right",
+	ActionMoveWordForward:      "Move to the start of the next word",
+	ActionMoveWordBackward:     "Move to the start of the previous word",
+	ActionMatchBracket:         "Jump to the matching bracket",
+	ActionMovePageUp:           "Scroll one page up",
+	ActionMovePageDown:         "Scroll one page down",
+	ActionMoveHome:             "Move to start of line",
+	ActionMoveEnd:              "Move to end of line",
+	ActionMoveFileStart:        "Go to first line",
+	ActionMoveFileEnd:          "Go to last line",
+	ActionJumpBack:             "Go back to where the cursor jumped from",
+	ActionJumpForward:          "Go forward again through the jump list",
+	ActionSetMark:              "Set a mark on the cursor, named by the next key",
+	ActionJumpToMark:           "Go to the mark named by the next key",
+	ActionYank:                 "Copy selection",
+	ActionCut:                  "Cut selection",
+	ActionPaste:                "Paste after cursor",
+	ActionPasteBefore:          "Paste before cursor",
+	ActionPastePrimary:         "Paste the primary selection (Linux)",
+	ActionUndo:                 "Undo last change",
+	ActionRedo:                 "Redo last undone change",
+	ActionDeleteWordForward:    "Delete to the start of the next word",
+	ActionDeleteWordBackward:   "Delete back to the start of the previous word",
+	ActionEnterInsertMode:      "Enter insert mode",
+	ActionEnterVisualMode:      "Enter visual mode",
+	ActionEnterVisualBlockMode: "Enter visual block mode",
+	ActionEnterCommandMode:     "Enter command mode",
+	ActionEnterFindMode:        "Search forward",
+	ActionFindNext:             "Jump to next search match",
+	ActionFindPrevious:         "Jump to previous search match",
+	ActionFuzzyFind:            "Fuzzy find files in the working directory",
+	ActionSearchWordForward:    "Search forward for the word under the cursor",
+	ActionSearchWordBackward:   "Search backward for the word under the cursor",
+	ActionCommandPalette:       "Open the command palette",
+	ActionClipboardHistory:     "Paste an entry from the clipboard history",
+	ActionFileInfo:             "Show the full path of the current file",
+	ActionHover:                "Show documentation for the symbol under the cursor (language server)",
+	ActionWindowCommand:        "Split, move between or close windows (then s, v, w, h/j/k/l, c or o)",
+	ActionCompleteNext:         "Complete the word before the cursor (insert mode)",
+	ActionCompletePrev:         "Complete the word before the cursor, backwards (insert mode)",
+	ActionEvalSelection:        "Replace the selected expression with its value",
+	ActionCodeActions:          "Show code actions and quick fixes at the cursor (language server)",
+	ActionToggleComment:        "Comment or uncomment the current line or the selected lines",
+	ActionDuplicateLine:        "Duplicate the current line or the selected lines",
+	ActionDeleteLine:           "Delete the current line or the selected lines",
+	ActionJoinLines:            "Join the current line with the next, or the selected lines",
+	ActionMoveLineUp:           "Move the current line or the selected lines up",
+	ActionMoveLineDown:         "Move the current line or the selected lines down",
+	ActionSelectNextOccurrence: "Select the word under the cursor, then its next occurrence with another cursor",
+	ActionInsertUUID:           "Insert a random UUID",
+	ActionInsertDate:           "Insert today's date",
+	ActionInsertTime:           "Insert the current time",
+	ActionInsertTimestamp:      "Insert an ISO 8601 timestamp",
 }
 
 // ActionFromName resolves a config action name (e.g., "save") to an Action.
@@ -123,6 +265,33 @@ func ActionFromName(name string) (Action, bool) {
 	return a, ok
 }
 
+// ActionDescription returns the help text for an Action, or "" for internal
+// actions that should not be offered to the user. The current locale may
+// translate it as action.<name>.
+func ActionDescription(a Action) string {
+	desc := actionDescriptions[a]
+	if desc == "" {
+	return ""
+	}
+	if s, ok := i18n.Translated("action." + NameFromAction(a)); ok {
+		return s
+	}
+	return desc
+}
+
+// DescribedActions returns every user-facing action (those with a
+// description), sorted by config name.
+func DescribedActions() []Action {
+	actions := make([]Action, 0, len(actionDescriptions))
+	for a := range actionDescriptions {
+		actions = append(actions, a)
+	}
+	sort.Slice(actions, func(i, j int) bool {
+		return NameFromAction(actions[i]) < NameFromAction(actions[j])
+	})
+	return actions
+}
+
 // NameFromAction returns the config name for an Action, or "" if unknown.
 func NameFromAction(a Action) string {
 	for name, act := range actionNames {
@@ -0,0 +1,100 @@
+package input
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/bethropolis/tide/internal/config"
+	"github.com/gdamore/tcell/v2"
+)
+
+// CheckBindings reports problems with the user's keybindings, for
+// :checkhealth. Bindings are shared by every mode, so one key bound to
+// different actions in two modes is a conflict, and a plain character key
+// also stops typing that character in insert mode.
+func CheckBindings(cfg *config.KeybindConfig) []string {
+	if cfg == nil {
+		return nil
+	}
+	defaults := NewInputProcessor()
+	modes := []struct {
+		name     string
+		bindings map[string]string
+	}{
+		{"normal", cfg.Normal}, {"insert", cfg.Insert}, {"command", cfg.Command},
+		{"find", cfg.Find}, {"visual", cfg.Visual}, {"visual_line", cfg.VisualLine},
+	}
+
+	type binding struct{ mode, key, action string }
+	bound := make(map[KeyStroke]binding)
+	var problems []string
+	for _, m := range modes {
+		keys := make([]string, 0, len(m.bindings))
+		for k := range m.bindings {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, keyStr := range keys {
+			actionName := m.bindings[keyStr]
+			ks, err := ParseKeyString(keyStr)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("[keybindings.%s] invalid key %q: %v; it stops the other keybindings from loading", m.name, keyStr, err))
+				continue
+			}
+			action, ok := ActionFromName(actionName)
+			if !ok {
+				problems = append(problems, fmt.Sprintf("[keybindings.%s] %q: unknown action %q; it stops the other keybindings from loading", m.name, keyStr, actionName))
+				continue
+			}
+
+			if prev, ok := bound[ks]; ok && prev.action != actionName {
+				problems = append(problems, fmt.Sprintf("%q is bound to %q in [keybindings.%s] and %q in [keybindings.%s]; bindings apply in every mode, so only one takes effect",
+					keyStr, prev.action, prev.mode, actionName, m.name))
+			}
+			bound[ks] = binding{m.name, keyStr, actionName}
+
+			if def, ok := defaults.lookup(ks); ok && def != action {
+				if defaults.ctrlDefault(ks) {
+					problems = append(problems, fmt.Sprintf("[keybindings.%s] %q is taken by the default binding %q, which comes first; the binding has no effect", m.name, keyStr, NameFromAction(def)))
+				} else {
+					problems = append(problems, fmt.Sprintf("[keybindings.%s] %q replaces the default binding %q", m.name, keyStr, NameFromAction(def)))
+				}
+			}
+			if ks.Key == tcell.KeyRune && ks.Mod == 0 {
+				problems = append(problems, fmt.Sprintf("[keybindings.%s] %q is a plain character; it can no longer be typed in insert mode", m.name, keyStr))
+			}
+		}
+	}
+	return problems
+}
+
+// lookup returns the action a key event of ks gets, in the order
+// ProcessEvent looks: the defaults of Ctrl+letter keys, in the Ctrl layer,
+// come before the bindings installed by setModeBinding.
+func (p *InputProcessor) lookup(ks KeyStroke) (Action, bool) {
+	if p.ctrlDefault(ks) {
+		return p.modKeymap[tcell.ModCtrl][ks.Key], true
+	}
+	var action Action
+	var ok bool
+	switch {
+	case ks.Key == tcell.KeyRune && ks.Mod == 0:
+		action, ok = p.runeKeymap[ks.Rune]
+	case ks.Mod != 0:
+		action, ok = p.modKeymap[ks.Mod][ks.Key]
+	default:
+		action, ok = p.keymap[ks.Key]
+	}
+	return action, ok
+}
+
+// ctrlDefault reports whether ks is a Ctrl+letter key with a default in the
+// Ctrl layer, which ProcessEvent finds before any binding of the key.
+func (p *InputProcessor) ctrlDefault(ks KeyStroke) bool {
+	if ks.Mod != 0 || !isCtrlLetter(ks.Key) {
+		return false
+	}
+	_, ok := p.modKeymap[tcell.ModCtrl][ks.Key]
+	return ok
+}
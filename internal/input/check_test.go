@@ -0,0 +1,33 @@
+package input
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bethropolis/tide/internal/config"
+)
+
+func TestCheckBindings(t *testing.T) {
+	problems := CheckBindings(&config.KeybindConfig{
+		Normal: map[string]string{"ctrl+s": "save", "alt+x": "quit", "x": "save"},
+		Insert: map[string]string{"alt+x": "save", "ctrl+z": "save", "ctrl+b": "nope"},
+	})
+	want := []string{
+		`"x" is a plain character`,
+		`"alt+x" is bound to "quit" in [keybindings.normal] and "save" in [keybindings.insert]`,
+		`"ctrl+z" is taken by the default binding "undo"`,
+		`unknown action "nope"`,
+	}
+	if len(problems) != len(want) {
+		t.Fatalf("CheckBindings() = %q, want %d problems", problems, len(want))
+	}
+	for _, w := range want {
+		found := false
+		for _, p := range problems {
+			found = found || strings.Contains(p, w)
+		}
+		if !found {
+			t.Errorf("no problem mentions %s in %q", w, problems)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package input
 
 import (
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/bethropolis/tide/internal/config"
@@ -28,8 +29,9 @@ type InputProcessor struct {
 	keymap     Keymap
 	runeKeymap RuneKeymap // Primarily for ActionInsertRune default
 	modKeymap  ModKeymap
-	leaderMap  LeaderSequenceMap // Maps keys following the leader
-	leaderKey  rune              // Configurable leader key
+	leaderMap  LeaderSequenceMap    // Maps keys following the leader
+	leaderKey  rune                 // Configurable leader key
+	origins    map[KeyStroke]string // Mode table of each binding from [keybindings]
 	// TODO: Add state for multi-key sequences (e.g., leader keys)
 }
 
@@ -41,6 +43,7 @@ func NewInputProcessor() *InputProcessor {
 		modKeymap:  make(ModKeymap),
 		leaderMap:  make(LeaderSequenceMap),
 		leaderKey:  DefaultLeaderKey, // Use default leader key
+		origins:    make(map[KeyStroke]string),
 	}
 	p.loadDefaultBindings()
 	return p
@@ -76,7 +79,36 @@ func (p *InputProcessor) loadDefaultBindings() {
 	ctrlMap[tcell.KeyCtrlV] = ActionEnterVisualBlockMode
 	ctrlMap[tcell.KeyCtrlA] = ActionMoveHome
 	ctrlMap[tcell.KeyCtrlE] = ActionMoveEnd
+	ctrlMap[tcell.KeyCtrlP] = ActionCommandPalette // Completes backwards in insert mode
+	ctrlMap[tcell.KeyCtrlN] = ActionCompleteNext
+	ctrlMap[tcell.KeyCtrlG] = ActionFileInfo
+	ctrlMap[tcell.KeyCtrlW] = ActionWindowCommand
+	ctrlMap[tcell.KeyCtrlUnderscore] = ActionToggleComment // Ctrl+/ in most terminals
+	ctrlMap[tcell.KeyCtrlK] = ActionDeleteLine
+	ctrlMap[tcell.KeyCtrlD] = ActionSelectNextOccurrence
+	ctrlMap[tcell.KeyCtrlO] = ActionJumpBack // Ctrl+I is Tab, which jumps forward in normal mode
+	ctrlMap[tcell.KeyRight] = ActionMoveWordForward
+	ctrlMap[tcell.KeyLeft] = ActionMoveWordBackward
+	ctrlMap[tcell.KeyDelete] = ActionDeleteWordForward
+	ctrlMap[tcell.KeyBackspace] = ActionDeleteWordBackward
+	ctrlMap[tcell.KeyBackspace2] = ActionDeleteWordBackward
 	p.modKeymap[tcell.ModCtrl] = ctrlMap
+	ctrlShiftMap := make(Keymap) // Shift extends the selection
+	ctrlShiftMap[tcell.KeyRight] = ActionMoveWordForward
+	ctrlShiftMap[tcell.KeyLeft] = ActionMoveWordBackward
+	p.modKeymap[tcell.ModCtrl|tcell.ModShift] = ctrlShiftMap
+
+	// --- Modifier Keys (Alt) ---
+	altMap := make(Keymap)
+	altMap[tcell.KeyUp] = ActionMoveLineUp
+	altMap[tcell.KeyDown] = ActionMoveLineDown
+	// Many terminals send Ctrl+Backspace as a plain Backspace
+	altMap[tcell.KeyBackspace] = ActionDeleteWordBackward
+	altMap[tcell.KeyBackspace2] = ActionDeleteWordBackward
+	p.modKeymap[tcell.ModAlt] = altMap
+	altShiftMap := make(Keymap)
+	altShiftMap[tcell.KeyDown] = ActionDuplicateLine
+	p.modKeymap[tcell.ModAlt|tcell.ModShift] = altShiftMap
 
 	// --- Leader Key Sequences ---
 	p.leaderMap['/'] = ActionEnterFindMode
@@ -90,15 +122,26 @@ func (p *InputProcessor) loadDefaultBindings() {
 	p.leaderMap['r'] = ActionRedo
 	p.leaderMap['y'] = ActionYank
 	p.leaderMap['p'] = ActionPaste
+	p.leaderMap['P'] = ActionPastePrimary
+	p.leaderMap['"'] = ActionClipboardHistory
+	p.leaderMap['a'] = ActionCodeActions
+	p.leaderMap['c'] = ActionToggleComment
 	p.leaderMap['d'] = ActionCut
+	p.leaderMap['j'] = ActionJoinLines
 	p.leaderMap['x'] = ActionCut
+	p.leaderMap['U'] = ActionInsertUUID
+	p.leaderMap['D'] = ActionInsertDate
+	p.leaderMap['T'] = ActionInsertTime
+	p.leaderMap['I'] = ActionInsertTimestamp
+	p.leaderMap['m'] = ActionSetMark
+	p.leaderMap['\''] = ActionJumpToMark
 }
 
 // setModeBinding parses a key string → action name pair and installs the
 // result into the correct mode layer. The mode name matches the fields of
 // config.KeybindConfig ("normal", "insert", "command", "find", "visual",
 // "visual_line").
-func (p *InputProcessor) setModeBinding(_ string, keyStr, actionName string) error {
+func (p *InputProcessor) setModeBinding(mode string, keyStr, actionName string) error {
 	ks, err := ParseKeyString(keyStr)
 	if err != nil {
 		return fmt.Errorf("invalid key %q: %w", keyStr, err)
@@ -108,6 +151,8 @@ func (p *InputProcessor) setModeBinding(_ string, keyStr, actionName string) err
 		return fmt.Errorf("unknown action %q", actionName)
 	}
 
+	p.origins[ks] = mode
+
 	// Place into the appropriate map based on key/run/mod composition.
 	if ks.Key == tcell.KeyRune && ks.Mod == 0 {
 		p.runeKeymap[ks.Rune] = action
@@ -133,15 +178,23 @@ func (p *InputProcessor) LoadUserBindings(cfg *config.KeybindConfig) error {
 	p.runeKeymap = make(RuneKeymap)
 	p.modKeymap = make(ModKeymap)
 	p.leaderMap = make(LeaderSequenceMap)
+	p.origins = make(map[KeyStroke]string)
 	p.loadDefaultBindings()
 
 	if cfg == nil {
 		return nil
 	}
 
-	for _, mode := range []map[string]string{cfg.Normal, cfg.Insert, cfg.Command, cfg.Find, cfg.Visual, cfg.VisualLine} {
-		for keyStr, actionName := range mode {
-			if err := p.setModeBinding("", keyStr, actionName); err != nil {
+	modes := []struct {
+		name     string
+		bindings map[string]string
+	}{
+		{"normal", cfg.Normal}, {"insert", cfg.Insert}, {"command", cfg.Command},
+		{"find", cfg.Find}, {"visual", cfg.Visual}, {"visual_line", cfg.VisualLine},
+	}
+	for _, mode := range modes {
+		for keyStr, actionName := range mode.bindings {
+			if err := p.setModeBinding(mode.name, keyStr, actionName); err != nil {
 				return err
 			}
 		}
@@ -197,6 +250,11 @@ func (p *InputProcessor) ProcessEvent(ev *tcell.EventKey) ActionEvent {
 	return ActionEvent{Action: ActionUnknown}
 }
 
+// SetLeaderKey changes the key that starts leader sequences.
+func (p *InputProcessor) SetLeaderKey(r rune) {
+	p.leaderKey = r
+}
+
 // GetLeaderKey returns the configured leader key rune.
 func (p *InputProcessor) GetLeaderKey() rune {
 	return p.leaderKey
@@ -207,3 +265,83 @@ func (p *InputProcessor) IsLeaderSequenceKey(r rune) (Action, bool) {
 	action, exists := p.leaderMap[r]
 	return action, exists
 }
+
+// Binding returns the action bound to ks, leader sequences aside, and
+// where the binding was made: "[keybindings.<mode>]" for the user's
+// config, "built-in" for the defaults.
+func (p *InputProcessor) Binding(ks KeyStroke) (action Action, origin string, ok bool) {
+	if action, ok = p.lookup(ks); !ok {
+		return ActionUnknown, "", false
+	}
+	if mode, user := p.origins[ks]; user && !p.ctrlDefault(ks) {
+		return action, "[keybindings." + mode + "]", true
+	}
+	return action, "built-in", true
+}
+
+// KeysForAction returns human-readable descriptions of every key currently
+// bound to the given action (e.g. "Ctrl+S", ",w"), sorted for display.
+func (p *InputProcessor) KeysForAction(a Action) []string {
+	var keys []string
+	for k, act := range p.keymap {
+		if act == a {
+			keys = append(keys, FormatKeyStroke(KeyStroke{Key: k}))
+		}
+	}
+	for mod, km := range p.modKeymap {
+		for k, act := range km {
+			if act == a {
+				keys = append(keys, FormatKeyStroke(KeyStroke{Key: k, Mod: mod}))
+			}
+		}
+	}
+	for r, act := range p.runeKeymap {
+		if act == a {
+			keys = append(keys, FormatKeyStroke(KeyStroke{Key: tcell.KeyRune, Rune: r}))
+		}
+	}
+	for r, act := range p.leaderMap {
+		if act == a {
+			keys = append(keys, string(p.leaderKey)+string(r))
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// KeyStrokesForAction returns keys that trigger the given action, for
+// replaying it: a single key when one is bound to it, otherwise the leader
+// and the key after it. Of several bindings the same one is chosen every
+// time. Returns false when nothing is bound to the action.
+func (p *InputProcessor) KeyStrokesForAction(a Action) ([]KeyStroke, bool) {
+	var best []KeyStroke
+	consider := func(keys ...KeyStroke) {
+		if best == nil || len(keys) < len(best) ||
+			len(keys) == len(best) && FormatKeySequence(keys) < FormatKeySequence(best) {
+			best = keys
+		}
+	}
+	for k, act := range p.keymap {
+		if act == a {
+			consider(KeyStroke{Key: k})
+		}
+	}
+	for mod, km := range p.modKeymap {
+		for k, act := range km {
+			if act == a {
+				consider(KeyStroke{Key: k, Mod: mod})
+			}
+		}
+	}
+	for r, act := range p.runeKeymap {
+		if act == a {
+			consider(KeyStroke{Key: tcell.KeyRune, Rune: r})
+		}
+	}
+	for r, act := range p.leaderMap {
+		if act == a {
+			consider(KeyStroke{Key: tcell.KeyRune, Rune: p.leaderKey}, KeyStroke{Key: tcell.KeyRune, Rune: r})
+		}
+	}
+	return best, best != nil
+}
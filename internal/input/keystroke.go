@@ -4,6 +4,8 @@ package input
 import (
 	"fmt"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/gdamore/tcell/v2"
 )
@@ -57,6 +59,8 @@ func ParseKeyString(s string) (KeyStroke, error) {
 		ks.Key = tcell.KeyEnter
 	case "tab":
 		ks.Key = tcell.KeyTab
+	case "backtab":
+		ks.Key = tcell.KeyBacktab
 	case "backspace", "bs":
 		ks.Key = tcell.KeyBackspace
 	case "delete", "del":
@@ -101,6 +105,12 @@ func ParseKeyString(s string) (KeyStroke, error) {
 					ks.Rune = 0
 					ks.Mod = 0
 				}
+				// Terminals send the same code for Ctrl+/ and Ctrl+_, which
+				// tcell reports with the Ctrl modifier
+				if lr == '/' || lr == '_' {
+					ks.Key = tcell.KeyCtrlUnderscore
+					ks.Rune = 0
+				}
 			}
 		} else {
 			return KeyStroke{}, fmt.Errorf("unrecognized key %q", rawKey)
@@ -110,6 +120,165 @@ func ParseKeyString(s string) (KeyStroke, error) {
 	return ks, nil
 }
 
+// FormatKeyStroke renders a KeyStroke in the same "ctrl+s" style accepted by
+// ParseKeyString, but capitalised for display (e.g. "Ctrl+S", "Alt+X", "Up").
+func FormatKeyStroke(ks KeyStroke) string {
+	// Ctrl+letter arrives as a dedicated tcell key (KeyCtrlS) rather than a
+	// modifier, so it is rendered from the key itself below.
+	isCtrlLetter := ks.Mod&tcell.ModCtrl != 0 && ks.Key >= tcell.KeyCtrlA && ks.Key <= tcell.KeyCtrlZ
+	isCtrlSlash := ks.Key == tcell.KeyCtrlUnderscore // Also sent for Ctrl+_
+
+	var parts []string
+	if ks.Mod&tcell.ModCtrl != 0 && !isCtrlLetter && !isCtrlSlash {
+		parts = append(parts, "Ctrl")
+	}
+	if ks.Mod&tcell.ModAlt != 0 {
+		parts = append(parts, "Alt")
+	}
+	if ks.Mod&tcell.ModShift != 0 {
+		parts = append(parts, "Shift")
+	}
+
+	switch {
+	case ks.Key == tcell.KeyRune && ks.Rune == ' ':
+		parts = append(parts, "Space")
+	case ks.Key == tcell.KeyRune:
+		parts = append(parts, string(ks.Rune))
+	case isCtrlLetter:
+		parts = append(parts, "Ctrl", string(rune('A'+int(ks.Key-tcell.KeyCtrlA))))
+	case isCtrlSlash:
+		parts = append(parts, "Ctrl", "/")
+	default:
+		name, ok := tcell.KeyNames[ks.Key]
+		if !ok {
+			name = fmt.Sprintf("Key(%d)", ks.Key)
+		}
+		parts = append(parts, strings.ReplaceAll(name, "-", "+"))
+	}
+	return strings.Join(parts, "+")
+}
+
+// ParseKeySequence reads keys written as in :map, such as "jk",
+// "<leader>w", "<C-s>" or ":w<CR>". Characters stand for themselves and
+// <...> names a key, optionally after the modifiers C- (Ctrl), A- or M-
+// (Alt) and S- (Shift): <C-s>, <A-x>, <S-Tab>. Besides the names
+// ParseKeyString knows, <CR>, <lt> ('<'), <Bar> ('|'), <Bslash> ('\') and
+// <leader> are understood, and so is the "<Ctrl+S>" form FormatKeySequence
+// writes. As in Vim, a '<' that does not start a key name is a literal '<'.
+func ParseKeySequence(s string, leader rune) ([]KeyStroke, error) {
+	if s == "" {
+		return nil, fmt.Errorf("empty key sequence")
+	}
+	var keys []KeyStroke
+	for i := 0; i < len(s); {
+		if s[i] == '<' {
+			if end := strings.IndexByte(s[i:], '>'); end > 1 {
+				if ks, ok := parseKeyName(s[i+1:i+end], leader); ok {
+					keys = append(keys, ks)
+					i += end + 1
+					continue
+				}
+			}
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		keys = append(keys, KeyStroke{Key: tcell.KeyRune, Rune: r})
+		i += size
+	}
+	return keys, nil
+}
+
+// parseKeyName parses the inside of a <...> key name.
+func parseKeyName(name string, leader rune) (KeyStroke, bool) {
+	var mods []string
+	for len(name) > 2 && name[1] == '-' {
+		switch unicode.ToLower(rune(name[0])) {
+		case 'c':
+			mods = append(mods, "ctrl")
+		case 'a', 'm':
+			mods = append(mods, "alt")
+		case 's':
+			mods = append(mods, "shift")
+		default:
+			return KeyStroke{}, false
+		}
+		name = name[2:]
+	}
+	switch strings.ToLower(name) {
+	case "cr":
+		name = "enter"
+	case "lt":
+		name = "<"
+	case "bar":
+		name = "|"
+	case "bslash":
+		name = "\\"
+	case "leader":
+		name = string(leader)
+		if leader == ' ' {
+			name = "space"
+		}
+	}
+
+	ks, err := ParseKeyString(strings.Join(append(mods, name), "+"))
+	if err != nil {
+		return KeyStroke{}, false
+	}
+	// Match what the terminal sends: Shift+Tab is its own key, and a
+	// shifted character arrives as the character itself
+	switch {
+	case ks.Key == tcell.KeyTab && ks.Mod == tcell.ModShift:
+		ks = KeyStroke{Key: tcell.KeyBacktab}
+	case ks.Key == tcell.KeyRune && ks.Mod&tcell.ModShift != 0:
+		ks.Rune = unicode.ToUpper(ks.Rune)
+		ks.Mod &^= tcell.ModShift
+	}
+	return ks, true
+}
+
+// FormatKeySequence writes keys in the notation ParseKeySequence reads:
+// plain characters as they are and other keys as <Ctrl+S>, <Enter> and so
+// on.
+func FormatKeySequence(keys []KeyStroke) string {
+	var b strings.Builder
+	for _, ks := range keys {
+		switch {
+		case ks.Key == tcell.KeyRune && ks.Mod == 0 && ks.Rune == '<':
+			b.WriteString("<lt>")
+		case ks.Key == tcell.KeyRune && ks.Mod == 0 && ks.Rune != ' ':
+			b.WriteRune(ks.Rune)
+		default:
+			b.WriteString("<" + FormatKeyStroke(ks) + ">")
+		}
+	}
+	return b.String()
+}
+
+// KeyStrokeOf returns the key stroke of a key event in the form
+// ParseKeyString and ParseKeySequence produce, so the two can be compared.
+func KeyStrokeOf(ev *tcell.EventKey) KeyStroke {
+	ks := KeyStroke{Key: ev.Key(), Mod: ev.Modifiers()}
+	switch {
+	case ks.Key == tcell.KeyRune:
+		ks.Rune = ev.Rune()
+		ks.Mod &^= tcell.ModShift // Already in the character's case
+	case ks.Key == tcell.KeyBackspace2:
+		ks.Key = tcell.KeyBackspace
+	case isCtrlLetter(ks.Key):
+		ks.Mod &^= tcell.ModCtrl
+	}
+	return ks
+}
+
+// Event returns a key event for ks as the terminal would report it.
+func (ks KeyStroke) Event() *tcell.EventKey {
+	mod, ch := ks.Mod, ks.Rune
+	if isCtrlLetter(ks.Key) {
+		mod |= tcell.ModCtrl
+		ch = rune(ks.Key)
+	}
+	return tcell.NewEventKey(ks.Key, ch, mod)
+}
+
 // splitModifiers splits a key spec on '+' but preserves the final segment
 // (the key itself) verbatim, so single-character runes keep their case.
 func splitModifiers(s string) []string {
@@ -121,3 +290,13 @@ func splitModifiers(s string) []string {
 	}
 	return parts
 }
+
+// isCtrlLetter reports whether k is a Ctrl+letter key other than the ones
+// that share their code with Tab, Backspace and Enter.
+func isCtrlLetter(k tcell.Key) bool {
+	switch k {
+	case tcell.KeyTab, tcell.KeyBackspace, tcell.KeyEnter:
+		return false
+	}
+	return k >= tcell.KeyCtrlA && k <= tcell.KeyCtrlZ
+}
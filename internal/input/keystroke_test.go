@@ -16,6 +16,7 @@ func TestParseKeyString(t *testing.T) {
 	}{
 		{name: "ctrl+s", in: "ctrl+s", want: KeyStroke{Key: tcell.KeyCtrlS}},
 		{name: "ctrl+z", in: "ctrl+z", want: KeyStroke{Key: tcell.KeyCtrlZ}},
+		{name: "ctrl+/", in: "ctrl+/", want: KeyStroke{Key: tcell.KeyCtrlUnderscore, Mod: tcell.ModCtrl}},
 		{name: "escape", in: "escape", want: KeyStroke{Key: tcell.KeyEscape}},
 		{name: "esc alias", in: "esc", want: KeyStroke{Key: tcell.KeyEscape}},
 		{name: "enter", in: "enter", want: KeyStroke{Key: tcell.KeyEnter}},
@@ -57,3 +58,76 @@ func TestParseKeyString(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatKeyStroke(t *testing.T) {
+	tests := []struct {
+		in   KeyStroke
+		want string
+	}{
+		{in: KeyStroke{Key: tcell.KeyCtrlS, Mod: tcell.ModCtrl}, want: "Ctrl+S"},
+		{in: KeyStroke{Key: tcell.KeyTab}, want: "Tab"},
+		{in: KeyStroke{Key: tcell.KeyUp}, want: "Up"},
+		{in: KeyStroke{Key: tcell.KeyRune, Rune: 'x', Mod: tcell.ModAlt}, want: "Alt+x"},
+		{in: KeyStroke{Key: tcell.KeyRune, Rune: ' '}, want: "Space"},
+		{in: KeyStroke{Key: tcell.KeyCtrlUnderscore, Mod: tcell.ModCtrl}, want: "Ctrl+/"},
+	}
+
+	for _, tc := range tests {
+		if got := FormatKeyStroke(tc.in); got != tc.want {
+			t.Errorf("FormatKeyStroke(%+v) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestParseKeySequence(t *testing.T) {
+	r := func(c rune) KeyStroke { return KeyStroke{Key: tcell.KeyRune, Rune: c} }
+	tests := []struct {
+		in   string
+		want []KeyStroke
+	}{
+		{in: "jk", want: []KeyStroke{r('j'), r('k')}},
+		{in: "<leader>w", want: []KeyStroke{r(','), r('w')}},
+		{in: ":w<CR>", want: []KeyStroke{r(':'), r('w'), {Key: tcell.KeyEnter}}},
+		{in: "<C-s>", want: []KeyStroke{{Key: tcell.KeyCtrlS}}},
+		{in: "<Ctrl+S>", want: []KeyStroke{{Key: tcell.KeyCtrlS}}},
+		{in: "<A-x>", want: []KeyStroke{{Key: tcell.KeyRune, Rune: 'x', Mod: tcell.ModAlt}}},
+		{in: "<S-Tab>", want: []KeyStroke{{Key: tcell.KeyBacktab}}},
+		{in: "<Esc><lt><Space>", want: []KeyStroke{{Key: tcell.KeyEscape}, r('<'), r(' ')}},
+		{in: "a<b", want: []KeyStroke{r('a'), r('<'), r('b')}},
+		{in: "<nope>", want: []KeyStroke{r('<'), r('n'), r('o'), r('p'), r('e'), r('>')}},
+	}
+
+	for _, tc := range tests {
+		got, err := ParseKeySequence(tc.in, ',')
+		if err != nil {
+			t.Errorf("ParseKeySequence(%q): %v", tc.in, err)
+			continue
+		}
+		if len(got) != len(tc.want) {
+			t.Errorf("ParseKeySequence(%q) = %+v, want %+v", tc.in, got, tc.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Errorf("ParseKeySequence(%q)[%d] = %+v, want %+v", tc.in, i, got[i], tc.want[i])
+			}
+		}
+		back, err := ParseKeySequence(FormatKeySequence(got), ',')
+		if err != nil || len(back) != len(got) {
+			t.Errorf("FormatKeySequence(%q) = %q does not parse back", tc.in, FormatKeySequence(got))
+		}
+	}
+}
+
+func TestKeyStrokeOfEvent(t *testing.T) {
+	for _, in := range []string{"<C-s>", "x", "<Esc>", "<A-x>", "<S-Tab>", "<BS>"} {
+		keys, _ := ParseKeySequence(in, ',')
+		if got := KeyStrokeOf(keys[0].Event()); got != keys[0] {
+			t.Errorf("%s: KeyStrokeOf(Event()) = %+v, want %+v", in, got, keys[0])
+		}
+	}
+	typed := tcell.NewEventKey(tcell.KeyBackspace2, 0, tcell.ModNone)
+	if got := KeyStrokeOf(typed); got.Key != tcell.KeyBackspace {
+		t.Errorf("KeyStrokeOf(Backspace2) = %+v, want Backspace", got)
+	}
+}
@@ -11,29 +11,29 @@ import (
 // Config holds all settings for the logger.
 type Config struct {
 	// LogLevel specifies the minimum level to log (e.g., "debug", "info", "warn", "error").
-	LogLevel string
+	LogLevel string `toml:"log_level"`
 
 	// LogFilePath is the path to the output log file. Use empty or "-" for stderr.
-	LogFilePath string
+	LogFilePath string `toml:"log_file_path"`
 
 	// --- Filtering Options ---
 
 	// EnabledTags only logs messages with these tags (if non-empty).
-	EnabledTags []string
+	EnabledTags []string `toml:"enabled_tags"`
 	// DisabledTags prevents logging messages with these tags. Overrides EnabledTags.
-	DisabledTags []string
+	DisabledTags []string `toml:"disabled_tags"`
 
 	// EnabledPackages only logs messages originating from these packages (if non-empty).
 	// Package name is the immediate directory name (e.g., "core", "theme", "app").
-	EnabledPackages []string
+	EnabledPackages []string `toml:"enabled_packages"`
 	// DisabledPackages prevents logging from these packages. Overrides EnabledPackages.
-	DisabledPackages []string
+	DisabledPackages []string `toml:"disabled_packages"`
 
 	// EnabledFiles only logs messages originating from these filenames (if non-empty).
 	// Filename is the base name (e.g., "editor.go", "manager.go").
-	EnabledFiles []string
+	EnabledFiles []string `toml:"enabled_files"`
 	// DisabledFiles prevents logging from these filenames. Overrides EnabledFiles.
-	DisabledFiles []string
+	DisabledFiles []string `toml:"disabled_files"`
 
 	// --- Internal processed fields ---
 	level               slog.Leveler
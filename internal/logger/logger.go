@@ -7,11 +7,12 @@ import (
 	"io"
 	"log/slog"
 	"os"
-	"os/user"
 	"path/filepath"
 	"runtime"
 	"sync"
 	"time"
+
+	"github.com/bethropolis/tide/internal/paths"
 )
 
 var (
@@ -52,20 +53,18 @@ func Init(cfg Config) {
 		if cfg.LogFilePath == "-" {
 			logOutput = os.Stderr
 		} else if cfg.LogFilePath == "" {
-			// Use ~/.config/tide/tide.log as default
-			usr, err := user.Current()
+			// Use the state directory (~/.local/state/tide/tide.log) as default
+			stateDir, err := paths.StateDir()
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "ERROR: Failed to get current user: %v\n", err)
+				fmt.Fprintf(os.Stderr, "ERROR: Failed to locate state directory: %v\n", err)
 				logOutput = os.Stderr
 			} else {
-				configDir := filepath.Join(usr.HomeDir, ".config", "tide")
-
 				// Create directory if it doesn't exist
-				if err := os.MkdirAll(configDir, 0755); err != nil {
-					fmt.Fprintf(os.Stderr, "ERROR: Failed to create directory '%s': %v\n", configDir, err)
+				if err := os.MkdirAll(stateDir, 0755); err != nil {
+					fmt.Fprintf(os.Stderr, "ERROR: Failed to create directory '%s': %v\n", stateDir, err)
 					logOutput = os.Stderr
 				} else {
-					logFilePath := filepath.Join(configDir, "tide.log")
+					logFilePath := filepath.Join(stateDir, "tide.log")
 					var file *os.File
 					file, err = os.OpenFile(logFilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
 					if err != nil {
@@ -0,0 +1,289 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// shutdownTimeout bounds how long Shutdown waits for a polite exit.
+const shutdownTimeout = 2 * time.Second
+
+// Client talks to one language server process.
+type Client struct {
+	conn       *Conn
+	cmd        *exec.Cmd
+	stdin      io.Closer
+	languageID string
+	encoding   Encoding
+
+	mu          sync.Mutex
+	docs        map[string]*document // Open documents by URI
+	diagnostics map[string]published // Latest diagnostics by URI
+}
+
+// document is the state of a file the server has been told about.
+type document struct {
+	version int
+	text    string
+}
+
+// published is one textDocument/publishDiagnostics notification.
+type published struct {
+	version     *int // Document version they were computed for, if known
+	diagnostics []Diagnostic
+}
+
+// Start launches the server command, performs the initialize handshake with
+// rootDir as the workspace and returns a ready client.
+func Start(ctx context.Context, command []string, rootDir, languageID string) (*Client, error) {
+	if len(command) == 0 {
+		return nil, fmt.Errorf("no language server command")
+	}
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Dir = rootDir
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start %s: %w", command[0], err)
+	}
+
+	c := &Client{
+		cmd:         cmd,
+		stdin:       stdin,
+		languageID:  languageID,
+		encoding:    EncodingUTF16,
+		docs:        make(map[string]*document),
+		diagnostics: make(map[string]published),
+	}
+	c.conn = NewConn(stdout, stdin, c.handleServer)
+
+	if err := c.initialize(ctx, rootDir); err != nil {
+		c.kill()
+		go c.cmd.Wait() // Reap the process
+		return nil, err
+	}
+	return c, nil
+}
+
+// initialize sends the initialize request and the initialized notification.
+func (c *Client) initialize(ctx context.Context, rootDir string) error {
+	rootURI := PathToURI(rootDir)
+	params := map[string]interface{}{
+		"processId": os.Getpid(),
+		"clientInfo": map[string]string{
+			"name": "tide",
+		},
+		"rootUri": rootURI,
+		"workspaceFolders": []map[string]string{
+			{"uri": rootURI, "name": rootDir},
+		},
+		"capabilities": clientCapabilities,
+	}
+	var result struct {
+		Capabilities struct {
+			PositionEncoding Encoding `json:"positionEncoding"`
+		} `json:"capabilities"`
+	}
+	if err := c.conn.Call(ctx, "initialize", params, &result); err != nil {
+		return fmt.Errorf("initialize: %w", err)
+	}
+	if enc := result.Capabilities.PositionEncoding; enc != "" {
+		c.encoding = enc
+	}
+	return c.conn.Notify("initialized", struct{}{})
+}
+
+// clientCapabilities tells the server which features the editor handles.
+var clientCapabilities = map[string]interface{}{
+	"general": map[string]interface{}{
+		"positionEncodings": []Encoding{EncodingUTF32, EncodingUTF16},
+	},
+	"textDocument": map[string]interface{}{
+		"synchronization":    map[string]interface{}{"didSave": false},
+		"rename":             map[string]interface{}{"prepareSupport": false},
+		"publishDiagnostics": map[string]interface{}{"versionSupport": true},
+		"codeAction": map[string]interface{}{
+			"codeActionLiteralSupport": map[string]interface{}{
+				"codeActionKind": map[string]interface{}{
+					"valueSet": []string{"", "quickfix", "refactor", "refactor.extract", "refactor.inline", "refactor.rewrite", "source", "source.organizeImports"},
+				},
+			},
+			"isPreferredSupport": true,
+			"disabledSupport":    true,
+			"dataSupport":        true,
+			"resolveSupport":     map[string]interface{}{"properties": []string{"edit"}},
+		},
+	},
+	"workspace": map[string]interface{}{
+		"workspaceEdit":    map[string]interface{}{"documentChanges": true},
+		"workspaceFolders": true,
+	},
+}
+
+// handleServer answers the requests servers commonly make of clients.
+func (c *Client) handleServer(method string, params json.RawMessage) (interface{}, error) {
+	switch method {
+	case "workspace/configuration":
+		var p struct {
+			Items []json.RawMessage `json:"items"`
+		}
+		_ = json.Unmarshal(params, &p)
+		return make([]interface{}, len(p.Items)), nil // No settings for any section
+	case "window/workDoneProgress/create", "client/registerCapability", "client/unregisterCapability":
+		return nil, nil
+	case "workspace/workspaceFolders":
+		return nil, nil
+	case "textDocument/publishDiagnostics":
+		var p struct {
+			URI         string       `json:"uri"`
+			Version     *int         `json:"version"`
+			Diagnostics []Diagnostic `json:"diagnostics"`
+		}
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, err
+		}
+		c.mu.Lock()
+		c.diagnostics[p.URI] = published{version: p.Version, diagnostics: p.Diagnostics}
+		c.mu.Unlock()
+		return nil, nil
+	}
+	return nil, fmt.Errorf("method not supported: %s", method)
+}
+
+// Encoding returns the position encoding agreed with the server.
+func (c *Client) Encoding() Encoding {
+	return c.encoding
+}
+
+// LanguageID returns the language identifier sent with opened documents.
+func (c *Client) LanguageID() string {
+	return c.languageID
+}
+
+// Sync makes the server's copy of the file match text, opening the document
+// on first use and sending the full text again whenever it has changed.
+func (c *Client) Sync(path string, text []byte) error {
+	uri := PathToURI(path)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	doc, open := c.docs[uri]
+	if !open {
+		c.docs[uri] = &document{version: 1, text: string(text)}
+		return c.conn.Notify("textDocument/didOpen", map[string]interface{}{
+			"textDocument": map[string]interface{}{
+				"uri":        uri,
+				"languageId": c.languageID,
+				"version":    1,
+				"text":       string(text),
+			},
+		})
+	}
+	if doc.text == string(text) {
+		return nil
+	}
+	doc.version++
+	doc.text = string(text)
+	return c.conn.Notify("textDocument/didChange", map[string]interface{}{
+		"textDocument":   map[string]interface{}{"uri": uri, "version": doc.version},
+		"contentChanges": []map[string]string{{"text": doc.text}},
+	})
+}
+
+// Diagnostics returns the latest diagnostics the server published for path.
+// Diagnostics computed for an older version of the document are left out, as
+// their ranges may no longer match the text.
+func (c *Client) Diagnostics(path string) []Diagnostic {
+	uri := PathToURI(path)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p, ok := c.diagnostics[uri]
+	if !ok {
+		return nil
+	}
+	if doc, open := c.docs[uri]; open && p.version != nil && *p.version != doc.version {
+		return nil
+	}
+	return p.diagnostics
+}
+
+// CloseDocument tells the server the file is no longer open in the editor.
+func (c *Client) CloseDocument(path string) error {
+	uri := PathToURI(path)
+	c.mu.Lock()
+	_, open := c.docs[uri]
+	delete(c.docs, uri)
+	delete(c.diagnostics, uri)
+	c.mu.Unlock()
+	if !open {
+		return nil
+	}
+	return c.conn.Notify("textDocument/didClose", map[string]interface{}{
+		"textDocument": TextDocumentIdentifier{URI: uri},
+	})
+}
+
+// positionParams builds the payload of a request about pos in path.
+func positionParams(path string, pos Position) TextDocumentPositionParams {
+	return TextDocumentPositionParams{
+		TextDocument: TextDocumentIdentifier{URI: PathToURI(path)},
+		Position:     pos,
+	}
+}
+
+// Rename asks the server for the edits that rename the symbol at pos.
+func (c *Client) Rename(ctx context.Context, path string, pos Position, newName string) (*WorkspaceEdit, error) {
+	params := struct {
+		TextDocumentPositionParams
+		NewName string `json:"newName"`
+	}{positionParams(path, pos), newName}
+
+	var edit *WorkspaceEdit
+	if err := c.conn.Call(ctx, "textDocument/rename", params, &edit); err != nil {
+		return nil, err
+	}
+	if edit == nil {
+		return nil, fmt.Errorf("nothing to rename at the cursor")
+	}
+	return edit, nil
+}
+
+// Shutdown asks the server to exit and kills it if it does not.
+func (c *Client) Shutdown() {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := c.conn.Call(ctx, "shutdown", nil, nil); err == nil {
+		_ = c.conn.Notify("exit", nil)
+	}
+	c.stdin.Close()
+
+	exited := make(chan struct{})
+	go func() {
+		_ = c.cmd.Wait()
+		close(exited)
+	}()
+	select {
+	case <-exited:
+	case <-ctx.Done():
+		c.kill()
+	}
+}
+
+// kill stops the server process without ceremony.
+func (c *Client) kill() {
+	if c.cmd.Process != nil {
+		_ = c.cmd.Process.Kill()
+	}
+}
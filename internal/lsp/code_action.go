@@ -0,0 +1,63 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// CodeActions asks for the fixes and refactorings available for rng, given
+// the diagnostics that overlap it. Actions the server disabled and bare
+// commands are left out: the editor only applies workspace edits.
+func (c *Client) CodeActions(ctx context.Context, path string, rng Range, diagnostics []Diagnostic) ([]CodeAction, error) {
+	if diagnostics == nil {
+		diagnostics = []Diagnostic{}
+	}
+	params := map[string]interface{}{
+		"textDocument": TextDocumentIdentifier{URI: PathToURI(path)},
+		"range":        rng,
+		"context": map[string]interface{}{
+			"diagnostics": diagnostics,
+			"triggerKind": 1, // Invoked
+		},
+	}
+	var result []json.RawMessage
+	if err := c.conn.Call(ctx, "textDocument/codeAction", params, &result); err != nil {
+		return nil, err
+	}
+
+	var actions []CodeAction
+	for _, raw := range result {
+		var probe struct {
+			Command json.RawMessage `json:"command"`
+		}
+		if err := json.Unmarshal(raw, &probe); err != nil {
+			continue
+		}
+		var name string
+		if json.Unmarshal(probe.Command, &name) == nil {
+			continue // A Command, not a CodeAction
+		}
+		var action CodeAction
+		if err := json.Unmarshal(raw, &action); err != nil {
+			continue
+		}
+		if action.Disabled != nil || (action.Edit == nil && len(action.Data) == 0) {
+			continue
+		}
+		actions = append(actions, action)
+	}
+	return actions, nil
+}
+
+// ResolveCodeAction fills in the edit of an action the server sent without
+// one. Actions that already carry an edit are returned unchanged.
+func (c *Client) ResolveCodeAction(ctx context.Context, action CodeAction) (CodeAction, error) {
+	if action.Edit != nil {
+		return action, nil
+	}
+	var resolved CodeAction
+	if err := c.conn.Call(ctx, "codeAction/resolve", action, &resolved); err != nil {
+		return action, err
+	}
+	return resolved, nil
+}
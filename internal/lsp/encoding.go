@@ -0,0 +1,124 @@
+package lsp
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"unicode/utf8"
+)
+
+// Encoding is the unit LSP positions count characters in. The editor's
+// columns are runes, which is UTF-32; servers that only speak the default
+// UTF-16 need converting.
+type Encoding string
+
+const (
+	EncodingUTF8  Encoding = "utf-8"
+	EncodingUTF16 Encoding = "utf-16"
+	EncodingUTF32 Encoding = "utf-32"
+)
+
+// FromRuneCol converts a rune column in line to a character offset.
+func FromRuneCol(line []byte, col int, enc Encoding) int {
+	if enc == EncodingUTF32 {
+		return col
+	}
+	n := 0
+	for i := 0; i < col && len(line) > 0; i++ {
+		r, size := utf8.DecodeRune(line)
+		line = line[size:]
+		n += unitLen(r, size, enc)
+	}
+	return n
+}
+
+// ToRuneCol converts a character offset in line to a rune column. Offsets
+// past the end of the line clamp to its length.
+func ToRuneCol(line []byte, character int, enc Encoding) int {
+	if enc == EncodingUTF32 {
+		return min(character, utf8.RuneCount(line))
+	}
+	col, n := 0, 0
+	for len(line) > 0 && n < character {
+		r, size := utf8.DecodeRune(line)
+		line = line[size:]
+		n += unitLen(r, size, enc)
+		col++
+	}
+	return col
+}
+
+// unitLen is how many code units of enc the rune takes.
+func unitLen(r rune, size int, enc Encoding) int {
+	switch enc {
+	case EncodingUTF8:
+		return size
+	case EncodingUTF16:
+		if r >= 0x10000 {
+			return 2
+		}
+		return 1
+	}
+	return 1
+}
+
+// byteOffset returns the offset into content of an LSP position.
+func byteOffset(content []byte, pos Position, enc Encoding) (int, error) {
+	offset := 0
+	for line := 0; line < pos.Line; line++ {
+		i := bytes.IndexByte(content[offset:], '\n')
+		if i < 0 {
+			return 0, fmt.Errorf("line %d is past the end of the document", pos.Line+1)
+		}
+		offset += i + 1
+	}
+	end := bytes.IndexByte(content[offset:], '\n')
+	if end < 0 {
+		end = len(content) - offset
+	}
+	line := content[offset : offset+end]
+	col := ToRuneCol(line, pos.Character, enc)
+	for i := 0; i < col; i++ {
+		_, size := utf8.DecodeRune(line)
+		line = line[size:]
+		offset += size
+	}
+	return offset, nil
+}
+
+// SortEdits orders edits from the end of the document to the start, so
+// applying them in turn leaves the earlier ranges valid.
+func SortEdits(edits []TextEdit) {
+	sort.SliceStable(edits, func(i, j int) bool {
+		a, b := edits[i].Range.Start, edits[j].Range.Start
+		if a.Line != b.Line {
+			return a.Line > b.Line
+		}
+		return a.Character > b.Character
+	})
+}
+
+// ApplyEdits returns content with edits applied. Edits must not overlap.
+func ApplyEdits(content []byte, edits []TextEdit, enc Encoding) ([]byte, error) {
+	sorted := append([]TextEdit(nil), edits...)
+	SortEdits(sorted)
+
+	result := append([]byte(nil), content...)
+	limit := len(content) // Start of the previously applied edit
+	for _, edit := range sorted {
+		start, err := byteOffset(content, edit.Range.Start, enc)
+		if err != nil {
+			return nil, err
+		}
+		end, err := byteOffset(content, edit.Range.End, enc)
+		if err != nil {
+			return nil, err
+		}
+		if end < start || end > limit {
+			return nil, fmt.Errorf("overlapping edits at line %d", edit.Range.Start.Line+1)
+		}
+		result = append(result[:start], append([]byte(edit.NewText), result[end:]...)...)
+		limit = start
+	}
+	return result, nil
+}
@@ -0,0 +1,84 @@
+package lsp
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestColumnConversion(t *testing.T) {
+	line := []byte("a😀é b") // 😀 is two UTF-16 units and four bytes
+	cases := []struct {
+		enc  Encoding
+		col  int
+		want int
+	}{
+		{EncodingUTF32, 3, 3},
+		{EncodingUTF16, 1, 1},
+		{EncodingUTF16, 2, 3},
+		{EncodingUTF16, 3, 4},
+		{EncodingUTF8, 2, 5},
+		{EncodingUTF8, 3, 7},
+	}
+	for _, c := range cases {
+		got := FromRuneCol(line, c.col, c.enc)
+		if got != c.want {
+			t.Errorf("FromRuneCol(%d, %s) = %d, want %d", c.col, c.enc, got, c.want)
+		}
+		if back := ToRuneCol(line, got, c.enc); back != c.col {
+			t.Errorf("ToRuneCol(%d, %s) = %d, want %d", got, c.enc, back, c.col)
+		}
+	}
+	if got := ToRuneCol(line, 99, EncodingUTF16); got != 5 {
+		t.Errorf("ToRuneCol past the end = %d, want 5", got)
+	}
+}
+
+func TestApplyEdits(t *testing.T) {
+	content := []byte("func föo() {\n\tföo()\n}\n")
+	edits := []TextEdit{
+		{Range: Range{Start: Position{0, 5}, End: Position{0, 8}}, NewText: "bar"},
+		{Range: Range{Start: Position{1, 1}, End: Position{1, 4}}, NewText: "bar"},
+	}
+	got, err := ApplyEdits(content, edits, EncodingUTF16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "func bar() {\n\tbar()\n}\n"; string(got) != want {
+		t.Errorf("ApplyEdits = %q, want %q", got, want)
+	}
+
+	overlapping := []TextEdit{
+		{Range: Range{Start: Position{0, 0}, End: Position{0, 6}}},
+		{Range: Range{Start: Position{0, 4}, End: Position{0, 8}}},
+	}
+	if _, err := ApplyEdits(content, overlapping, EncodingUTF16); err == nil {
+		t.Errorf("overlapping edits should fail")
+	}
+	if _, err := ApplyEdits(content, []TextEdit{{Range: Range{Start: Position{9, 0}, End: Position{9, 0}}}}, EncodingUTF16); err == nil {
+		t.Errorf("edit past the end should fail")
+	}
+}
+
+func TestFileEdits(t *testing.T) {
+	var w WorkspaceEdit
+	raw := `{"documentChanges":[{"textDocument":{"uri":"file:///tmp/a.go","version":3},"edits":[{"range":{"start":{"line":0,"character":0},"end":{"line":0,"character":1}},"newText":"x"}]}]}`
+	if err := json.Unmarshal([]byte(raw), &w); err != nil {
+		t.Fatal(err)
+	}
+	files, err := w.FileEdits()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if edits := files["/tmp/a.go"]; len(edits) != 1 || edits[0].NewText != "x" {
+		t.Errorf("FileEdits = %v", files)
+	}
+
+	raw = `{"documentChanges":[{"kind":"rename","oldUri":"file:///a","newUri":"file:///b"}]}`
+	w = WorkspaceEdit{}
+	if err := json.Unmarshal([]byte(raw), &w); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.FileEdits(); err == nil {
+		t.Errorf("file operations should be rejected")
+	}
+}
@@ -0,0 +1,66 @@
+package lsp
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+)
+
+// unusedImportRe matches the unused import messages of gopls, pyflakes/ruff
+// and rust-analyzer, capturing the quoted import name and gopls's alias.
+var unusedImportRe = regexp.MustCompile("[\"'`]([^\"'`]+)[\"'`] imported (?:as (\\S+) )?(?:and not used|but unused)|^unused import: `([^`]+)`")
+
+// BuiltinFixes returns quick fixes the editor can make itself for the
+// diagnostics of the file at path, whose content is text. So far it removes
+// unused imports that sit on a line of their own.
+func BuiltinFixes(path string, text []byte, diagnostics []Diagnostic, enc Encoding) []CodeAction {
+	lines := bytes.Split(text, []byte("\n"))
+	var fixes []CodeAction
+	seen := make(map[int]bool)
+	for _, d := range diagnostics {
+		m := unusedImportRe.FindStringSubmatch(d.Message)
+		if m == nil || d.Range.Start.Line != d.Range.End.Line {
+			continue
+		}
+		name, alias := m[1]+m[3], m[2]
+		n := d.Range.Start.Line
+		if n >= len(lines) || seen[n] || !importOnlyLine(string(lines[n]), name, alias) {
+			continue
+		}
+		seen[n] = true
+
+		// Delete the line with its newline, or its text if it is the last
+		rng := Range{Start: Position{Line: n}, End: Position{Line: n + 1}}
+		if n == len(lines)-1 {
+			rng.End = Position{Line: n, Character: FromRuneCol(lines[n], len([]rune(string(lines[n]))), enc)}
+		}
+		fixes = append(fixes, CodeAction{
+			Title:       "Remove unused import " + name,
+			Kind:        "quickfix",
+			Diagnostics: []Diagnostic{d},
+			Edit: &WorkspaceEdit{Changes: map[string][]TextEdit{
+				PathToURI(path): {{Range: rng}},
+			}},
+		})
+	}
+	return fixes
+}
+
+// importOnlyLine reports whether line holds nothing but the import of name:
+// once the name is taken out, only its alias, keywords such as import or
+// use, quotes and semicolons may remain.
+func importOnlyLine(line, name, alias string) bool {
+	if !strings.Contains(line, name) {
+		return false
+	}
+	s := strings.Replace(line, name, " ", 1)
+	s = strings.NewReplacer(`"`, " ", "'", " ", "`", " ", ";", " ").Replace(s)
+	for _, word := range strings.Fields(s) {
+		switch word {
+		case "import", "use", "pub", alias:
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,87 @@
+package lsp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuiltinFixesRemoveUnusedImport(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		diag  Diagnostic
+		want  string // Text after the fix
+		noFix bool
+	}{
+		{
+			name: "go grouped",
+			text: "import (\n\t\"fmt\"\n\t\"os\"\n)\n",
+			diag: Diagnostic{Range: Range{Position{1, 1}, Position{1, 6}}, Message: `"fmt" imported and not used`},
+			want: "import (\n\t\"os\"\n)\n",
+		},
+		{
+			name: "go aliased",
+			text: "import f \"fmt\"\n",
+			diag: Diagnostic{Range: Range{Position{0, 7}, Position{0, 14}}, Message: `"fmt" imported as f and not used`},
+			want: "",
+		},
+		{
+			name:  "python multiple names are left alone",
+			text:  "import os, sys\n",
+			diag:  Diagnostic{Range: Range{Position{0, 0}, Position{0, 14}}, Message: `'sys' imported but unused`},
+			noFix: true,
+		},
+		{
+			name: "python without range",
+			text: "import os\nimport sys",
+			diag: Diagnostic{Range: Range{Position{1, 0}, Position{1, 0}}, Message: `'sys' imported but unused`},
+			want: "import os\n",
+		},
+		{
+			name: "rust",
+			text: "use std::io;\nfn main() {}\n",
+			diag: Diagnostic{Range: Range{Position{0, 4}, Position{0, 11}}, Message: "unused import: `std::io`"},
+			want: "fn main() {}\n",
+		},
+		{
+			name:  "python from import is left alone",
+			text:  "from os import path, sep\n",
+			diag:  Diagnostic{Range: Range{Position{0, 0}, Position{0, 24}}, Message: `'os.path' imported but unused`},
+			noFix: true,
+		},
+		{
+			name:  "other diagnostics are ignored",
+			text:  "x := 1\n",
+			diag:  Diagnostic{Range: Range{Position{0, 0}, Position{0, 1}}, Message: "declared and not used: x"},
+			noFix: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixes := BuiltinFixes("/tmp/x", []byte(tt.text), []Diagnostic{tt.diag}, EncodingUTF16)
+			if tt.noFix {
+				if len(fixes) != 0 {
+					t.Fatalf("expected no fix, got %q", fixes[0].Title)
+				}
+				return
+			}
+			if len(fixes) != 1 {
+				t.Fatalf("expected one fix, got %d", len(fixes))
+			}
+			if !strings.HasPrefix(fixes[0].Title, "Remove unused import ") {
+				t.Errorf("title = %q", fixes[0].Title)
+			}
+			files, err := fixes[0].Edit.FileEdits()
+			if err != nil {
+				t.Fatal(err)
+			}
+			out, err := ApplyEdits([]byte(tt.text), files["/tmp/x"], EncodingUTF16)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(out) != tt.want {
+				t.Errorf("got %q, want %q", out, tt.want)
+			}
+		})
+	}
+}
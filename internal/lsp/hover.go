@@ -0,0 +1,156 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+)
+
+// Hover asks for documentation of the symbol at pos and returns it as
+// markdown, or "" when the server has nothing to show.
+func (c *Client) Hover(ctx context.Context, path string, pos Position) (string, error) {
+	var result *struct {
+		Contents json.RawMessage `json:"contents"`
+	}
+	if err := c.conn.Call(ctx, "textDocument/hover", positionParams(path, pos), &result); err != nil {
+		return "", err
+	}
+	if result == nil {
+		return "", nil
+	}
+	return hoverMarkdown(result.Contents), nil
+}
+
+// hoverMarkdown converts the three shapes hover contents come in
+// (MarkupContent, MarkedString and MarkedString[]) to markdown.
+func hoverMarkdown(raw json.RawMessage) string {
+	var list []json.RawMessage
+	if err := json.Unmarshal(raw, &list); err == nil {
+		parts := make([]string, 0, len(list))
+		for _, item := range list {
+			if s := markedString(item); s != "" {
+				parts = append(parts, s)
+			}
+		}
+		return strings.Join(parts, "\n\n")
+	}
+	return markedString(raw)
+}
+
+// markedString converts a MarkupContent or MarkedString to markdown.
+func markedString(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return strings.TrimSpace(s)
+	}
+	var v struct {
+		Kind     string `json:"kind"`
+		Language string `json:"language"`
+		Value    string `json:"value"`
+	}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return ""
+	}
+	if v.Language != "" {
+		return "```" + v.Language + "\n" + strings.TrimSpace(v.Value) + "\n```"
+	}
+	return strings.TrimSpace(v.Value) // Plain text reads fine as markdown
+}
+
+// SignatureHelp describes the call surrounding the cursor.
+type SignatureHelp struct {
+	Signatures      []SignatureInformation `json:"signatures"`
+	ActiveSignature int                    `json:"activeSignature"`
+	ActiveParameter int                    `json:"activeParameter"`
+}
+
+// SignatureInformation is one overload of the function being called.
+type SignatureInformation struct {
+	Label           string                 `json:"label"`
+	Documentation   json.RawMessage        `json:"documentation,omitempty"`
+	Parameters      []ParameterInformation `json:"parameters,omitempty"`
+	ActiveParameter *int                   `json:"activeParameter,omitempty"`
+}
+
+// ParameterInformation names a parameter either by its text or by an
+// offset range into the signature label.
+type ParameterInformation struct {
+	Label json.RawMessage `json:"label"`
+}
+
+// SignatureHelp asks for the signature of the call around pos. It returns
+// nil when the cursor is not inside a call.
+func (c *Client) SignatureHelp(ctx context.Context, path string, pos Position) (*SignatureHelp, error) {
+	var result *SignatureHelp
+	if err := c.conn.Call(ctx, "textDocument/signatureHelp", positionParams(path, pos), &result); err != nil {
+		return nil, err
+	}
+	if result == nil || len(result.Signatures) == 0 {
+		return nil, nil
+	}
+	if result.ActiveSignature < 0 || result.ActiveSignature >= len(result.Signatures) {
+		result.ActiveSignature = 0
+	}
+	return result, nil
+}
+
+// Active returns the signature the server marked as active and the index
+// of its active parameter.
+func (h *SignatureHelp) Active() (SignatureInformation, int) {
+	sig := h.Signatures[h.ActiveSignature]
+	if sig.ActiveParameter != nil {
+		return sig, *sig.ActiveParameter
+	}
+	return sig, h.ActiveParameter
+}
+
+// DocMarkdown returns the signature's documentation as markdown.
+func (s SignatureInformation) DocMarkdown() string {
+	if len(s.Documentation) == 0 {
+		return ""
+	}
+	return markedString(s.Documentation)
+}
+
+// ParamRange returns the byte range of parameter i inside Label, using enc
+// for offset labels. ok is false when the parameter cannot be located.
+func (s SignatureInformation) ParamRange(i int, enc Encoding) (start, end int, ok bool) {
+	if i < 0 || i >= len(s.Parameters) {
+		return 0, 0, false
+	}
+	raw := s.Parameters[i].Label
+
+	var offsets [2]int
+	if err := json.Unmarshal(raw, &offsets); err == nil {
+		label := []byte(s.Label)
+		startCol := ToRuneCol(label, offsets[0], enc)
+		endCol := ToRuneCol(label, offsets[1], enc)
+		start, end = runeByteOffset(s.Label, startCol), runeByteOffset(s.Label, endCol)
+		return start, end, start < end
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err != nil || text == "" {
+		return 0, 0, false
+	}
+	// Search after the opening parenthesis so a parameter sharing the
+	// function's name is not matched in the name.
+	from := strings.IndexByte(s.Label, '(') + 1
+	idx := strings.Index(s.Label[from:], text)
+	if idx < 0 {
+		return 0, 0, false
+	}
+	return from + idx, from + idx + len(text), true
+}
+
+// runeByteOffset returns the byte offset of rune index col in s.
+func runeByteOffset(s string, col int) int {
+	n := 0
+	for i := range s {
+		if n == col {
+			return i
+		}
+		n++
+	}
+	return len(s)
+}
@@ -0,0 +1,44 @@
+package lsp
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestHoverMarkdown(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{`{"kind":"markdown","value":"**x** int\n"}`, "**x** int"},
+		{`"plain"`, "plain"},
+		{`{"language":"go","value":"func f()"}`, "```go\nfunc f()\n```"},
+		{`["a", {"language":"go","value":"b"}]`, "a\n\n```go\nb\n```"},
+	}
+	for _, tt := range tests {
+		if got := hoverMarkdown(json.RawMessage(tt.raw)); got != tt.want {
+			t.Errorf("hoverMarkdown(%s) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestParamRange(t *testing.T) {
+	sig := SignatureInformation{
+		Label: "f(f int, ü string)",
+		Parameters: []ParameterInformation{
+			{Label: json.RawMessage(`"f int"`)},
+			{Label: json.RawMessage(`[9,17]`)},
+		},
+	}
+	start, end, ok := sig.ParamRange(0, EncodingUTF16)
+	if !ok || sig.Label[start:end] != "f int" || start != 2 {
+		t.Errorf("text label: got %d..%d %v", start, end, ok)
+	}
+	start, end, ok = sig.ParamRange(1, EncodingUTF16)
+	if !ok || sig.Label[start:end] != "ü string" {
+		t.Errorf("offset label: got %q %v", sig.Label[start:end], ok)
+	}
+	if _, _, ok := sig.ParamRange(2, EncodingUTF16); ok {
+		t.Error("out of range parameter should not be found")
+	}
+}
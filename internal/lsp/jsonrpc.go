@@ -0,0 +1,255 @@
+// Package lsp is a small Language Server Protocol client: JSON-RPC framing
+// over a server's stdin/stdout, document synchronisation and the requests
+// the editor uses.
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrClosed is returned for calls made after the connection has shut down.
+var ErrClosed = errors.New("lsp: connection closed")
+
+// Handler answers requests and notifications sent by the server. For
+// notifications the result is ignored.
+type Handler func(method string, params json.RawMessage) (interface{}, error)
+
+// ResponseError is an error returned by the server.
+type ResponseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("lsp: %s (code %d)", e.Message, e.Code)
+}
+
+// message is any JSON-RPC 2.0 message; which fields are set tells requests,
+// notifications and responses apart.
+type message struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+	Result  json.RawMessage  `json:"result,omitempty"`
+	Error   *ResponseError   `json:"error,omitempty"`
+}
+
+// Conn is a JSON-RPC connection using the LSP base protocol
+// (Content-Length framed messages).
+type Conn struct {
+	handler Handler
+
+	wmu sync.Mutex // Serialises writes
+	w   io.Writer
+
+	mu      sync.Mutex
+	nextID  int64
+	pending map[int64]chan *message
+	err     error // Set once the read loop stops
+	done    chan struct{}
+}
+
+// NewConn starts reading messages from r and returns a connection writing
+// to w. handler may be nil, in which case server requests get an error reply.
+func NewConn(r io.Reader, w io.Writer, handler Handler) *Conn {
+	c := &Conn{
+		handler: handler,
+		w:       w,
+		pending: make(map[int64]chan *message),
+		done:    make(chan struct{}),
+	}
+	go c.readLoop(bufio.NewReader(r))
+	return c
+}
+
+// Done is closed when the connection stops reading.
+func (c *Conn) Done() <-chan struct{} {
+	return c.done
+}
+
+// Call sends a request and decodes the response into result (which may be
+// nil). It returns when the response arrives, ctx ends or the connection
+// closes.
+func (c *Conn) Call(ctx context.Context, method string, params, result interface{}) error {
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return c.err
+	}
+	c.nextID++
+	id := c.nextID
+	ch := make(chan *message, 1)
+	c.pending[id] = ch
+	c.mu.Unlock()
+
+	cleanup := func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}
+
+	rawID := json.RawMessage(strconv.FormatInt(id, 10))
+	if err := c.send(&message{ID: &rawID, Method: method}, params); err != nil {
+		cleanup()
+		return err
+	}
+
+	select {
+	case resp := <-ch:
+		if resp.Error != nil {
+			return resp.Error
+		}
+		if result == nil || len(resp.Result) == 0 {
+			return nil
+		}
+		return json.Unmarshal(resp.Result, result)
+	case <-ctx.Done():
+		cleanup()
+		_ = c.Notify("$/cancelRequest", map[string]int64{"id": id})
+		return ctx.Err()
+	case <-c.done:
+		return c.closedErr()
+	}
+}
+
+// Notify sends a notification, which has no response.
+func (c *Conn) Notify(method string, params interface{}) error {
+	return c.send(&message{Method: method}, params)
+}
+
+// send encodes params into msg and writes it with its header.
+func (c *Conn) send(msg *message, params interface{}) error {
+	msg.JSONRPC = "2.0"
+	if params != nil {
+		raw, err := json.Marshal(params)
+		if err != nil {
+			return err
+		}
+		msg.Params = raw
+	}
+	return c.write(msg)
+}
+
+func (c *Conn) write(msg *message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	if _, err := fmt.Fprintf(c.w, "Content-Length: %d\r\n\r\n", len(body)); err != nil {
+		return err
+	}
+	_, err = c.w.Write(body)
+	return err
+}
+
+func (c *Conn) closedErr() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return c.err
+	}
+	return ErrClosed
+}
+
+// readLoop dispatches incoming messages until the reader fails.
+func (c *Conn) readLoop(r *bufio.Reader) {
+	var err error
+	for {
+		var msg *message
+		msg, err = readMessage(r)
+		if err != nil {
+			break
+		}
+		switch {
+		case msg.Method != "":
+			// Handlers may block (e.g. waiting on the editor), so they must
+			// not hold up responses to our own calls.
+			go c.handle(msg)
+		case msg.ID != nil:
+			c.deliver(msg)
+		}
+	}
+
+	c.mu.Lock()
+	if errors.Is(err, io.EOF) {
+		err = ErrClosed
+	}
+	c.err = err
+	c.pending = nil
+	c.mu.Unlock()
+	close(c.done)
+}
+
+// deliver hands a response to the call waiting for it.
+func (c *Conn) deliver(msg *message) {
+	id, err := strconv.ParseInt(string(*msg.ID), 10, 64)
+	if err != nil {
+		return // Not one of ours
+	}
+	c.mu.Lock()
+	ch := c.pending[id]
+	delete(c.pending, id)
+	c.mu.Unlock()
+	if ch != nil {
+		ch <- msg
+	}
+}
+
+// handle runs the handler for a server request or notification and replies
+// to requests.
+func (c *Conn) handle(msg *message) {
+	var result interface{}
+	err := fmt.Errorf("method not supported: %s", msg.Method)
+	if c.handler != nil {
+		result, err = c.handler(msg.Method, msg.Params)
+	}
+	if msg.ID == nil {
+		return // Notification
+	}
+
+	reply := &message{JSONRPC: "2.0", ID: msg.ID}
+	if err != nil {
+		reply.Error = &ResponseError{Code: -32601, Message: err.Error()}
+	} else {
+		raw, merr := json.Marshal(result)
+		if merr != nil {
+			reply.Error = &ResponseError{Code: -32603, Message: merr.Error()}
+		} else {
+			reply.Result = raw
+		}
+	}
+	_ = c.write(reply)
+}
+
+// readMessage reads one Content-Length framed message.
+func readMessage(r *bufio.Reader) (*message, error) {
+	header, err := textproto.NewReader(r).ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+	length, err := strconv.Atoi(strings.TrimSpace(header.Get("Content-Length")))
+	if err != nil || length < 0 {
+		return nil, fmt.Errorf("lsp: bad Content-Length %q", header.Get("Content-Length"))
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	msg := &message{}
+	if err := json.Unmarshal(body, msg); err != nil {
+		return nil, fmt.Errorf("lsp: bad message: %w", err)
+	}
+	return msg, nil
+}
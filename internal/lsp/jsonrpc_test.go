@@ -0,0 +1,52 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// pipeConns returns two connections talking to each other.
+func pipeConns(client, server Handler) (*Conn, *Conn) {
+	cr, sw := io.Pipe()
+	sr, cw := io.Pipe()
+	return NewConn(cr, cw, client), NewConn(sr, sw, server)
+}
+
+func TestCall(t *testing.T) {
+	client, _ := pipeConns(nil, func(method string, params json.RawMessage) (interface{}, error) {
+		switch method {
+		case "echo":
+			var s string
+			_ = json.Unmarshal(params, &s)
+			return s + "!", nil
+		case "fail":
+			return nil, errors.New("boom")
+		}
+		return nil, nil
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var got string
+	if err := client.Call(ctx, "echo", "hi", &got); err != nil || got != "hi!" {
+		t.Fatalf("echo = %q, %v", got, err)
+	}
+	var rerr *ResponseError
+	if err := client.Call(ctx, "fail", nil, nil); !errors.As(err, &rerr) || rerr.Message != "boom" {
+		t.Errorf("fail = %v, want ResponseError boom", err)
+	}
+}
+
+func TestCallAfterClose(t *testing.T) {
+	cr, sw := io.Pipe()
+	client := NewConn(cr, io.Discard, nil)
+	sw.Close()
+	<-client.Done()
+	if err := client.Call(context.Background(), "x", nil, nil); !errors.Is(err, ErrClosed) {
+		t.Errorf("Call after close = %v, want ErrClosed", err)
+	}
+}
@@ -0,0 +1,140 @@
+package lsp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path/filepath"
+)
+
+// Position is a zero-based line and character offset, counted in the
+// connection's position encoding.
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// Range is a half-open span between two positions.
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// Location is a range inside a document.
+type Location struct {
+	URI   string `json:"uri"`
+	Range Range  `json:"range"`
+}
+
+// TextEdit replaces Range with NewText.
+type TextEdit struct {
+	Range   Range  `json:"range"`
+	NewText string `json:"newText"`
+}
+
+// TextDocumentIdentifier names a document by URI.
+type TextDocumentIdentifier struct {
+	URI string `json:"uri"`
+}
+
+// TextDocumentPositionParams is the common payload of position requests.
+type TextDocumentPositionParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	Position     Position               `json:"position"`
+}
+
+// TextDocumentEdit is a set of edits to one document version.
+type TextDocumentEdit struct {
+	TextDocument struct {
+		URI     string `json:"uri"`
+		Version *int   `json:"version"`
+	} `json:"textDocument"`
+	Edits []TextEdit `json:"edits"`
+}
+
+// WorkspaceEdit is a change to several documents. Servers send either
+// Changes or DocumentChanges.
+type WorkspaceEdit struct {
+	Changes         map[string][]TextEdit `json:"changes,omitempty"`
+	DocumentChanges []json.RawMessage     `json:"documentChanges,omitempty"`
+}
+
+// FileEdits flattens the edit into text edits per file path. Create, rename
+// and delete operations are not supported and make it fail.
+func (w *WorkspaceEdit) FileEdits() (map[string][]TextEdit, error) {
+	files := make(map[string][]TextEdit)
+	for uri, edits := range w.Changes {
+		path, err := URIToPath(uri)
+		if err != nil {
+			return nil, err
+		}
+		files[path] = append(files[path], edits...)
+	}
+	for _, raw := range w.DocumentChanges {
+		var op struct {
+			Kind string `json:"kind"`
+		}
+		if err := json.Unmarshal(raw, &op); err != nil {
+			return nil, err
+		}
+		if op.Kind != "" {
+			return nil, fmt.Errorf("file %s operations are not supported", op.Kind)
+		}
+		var edit TextDocumentEdit
+		if err := json.Unmarshal(raw, &edit); err != nil {
+			return nil, err
+		}
+		path, err := URIToPath(edit.TextDocument.URI)
+		if err != nil {
+			return nil, err
+		}
+		files[path] = append(files[path], edit.Edits...)
+	}
+	return files, nil
+}
+
+// PathToURI converts a file path to a file:// URI.
+func PathToURI(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(path)}
+	return u.String()
+}
+
+// URIToPath converts a file:// URI back to a file path.
+func URIToPath(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "file" {
+		return "", fmt.Errorf("unsupported URI scheme %q", u.Scheme)
+	}
+	return filepath.FromSlash(u.Path), nil
+}
+
+// Diagnostic is an error or warning the server reported for a range.
+type Diagnostic struct {
+	Range    Range           `json:"range"`
+	Severity int             `json:"severity,omitempty"` // 1 error, 2 warning, 3 info, 4 hint
+	Code     json.RawMessage `json:"code,omitempty"`
+	Source   string          `json:"source,omitempty"`
+	Message  string          `json:"message"`
+	Data     json.RawMessage `json:"data,omitempty"`
+}
+
+// CodeAction is a fix or refactoring offered for a range. Edit may be nil
+// until the action is resolved.
+type CodeAction struct {
+	Title       string       `json:"title"`
+	Kind        string       `json:"kind,omitempty"`
+	Diagnostics []Diagnostic `json:"diagnostics,omitempty"`
+	IsPreferred bool         `json:"isPreferred,omitempty"`
+	Disabled    *struct {
+		Reason string `json:"reason"`
+	} `json:"disabled,omitempty"`
+	Edit    *WorkspaceEdit  `json:"edit,omitempty"`
+	Command json.RawMessage `json:"command,omitempty"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
@@ -1,10 +1,20 @@
 package modehandler
 
 import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bethropolis/tide/internal/config"
+	"github.com/bethropolis/tide/internal/core/calc"
+	"github.com/bethropolis/tide/internal/core/find"
 	"github.com/bethropolis/tide/internal/event"
+	"github.com/bethropolis/tide/internal/highlighter"
+	"github.com/bethropolis/tide/internal/i18n"
 	"github.com/bethropolis/tide/internal/input"
 	"github.com/bethropolis/tide/internal/logger"
 	"github.com/bethropolis/tide/internal/types"
+	"github.com/bethropolis/tide/internal/utils"
 	"github.com/gdamore/tcell/v2"
 )
 
@@ -22,11 +32,19 @@ func (mh *ModeHandler) executeAction(action input.Action, actionEvent input.Acti
 
 	hasHighlights := mh.editor.HasHighlights()
 
+	// Typing in insert mode joins the undo step it carries on; anything
+	// else ends it
+	typing := mh.currentMode == ModeInsert && (action == input.ActionInsertRune || action == input.ActionInsertTab ||
+		action == input.ActionDeleteCharBackward || action == input.ActionDeleteCharForward)
+	if hist := mh.editor.GetHistoryManager(); hist != nil && !typing {
+		hist.Seal()
+	}
+
 	// Determine if it's a movement action
 	isMovementAction := false
 	switch action {
 	case input.ActionMoveUp, input.ActionMoveDown, input.ActionMoveLeft, input.ActionMoveRight,
-		input.ActionMovePageUp, input.ActionMovePageDown, input.ActionMoveHome, input.ActionMoveEnd,
+		input.ActionMoveWordForward, input.ActionMoveWordBackward, input.ActionMatchBracket, input.ActionMovePageUp, input.ActionMovePageDown, input.ActionMoveHome, input.ActionMoveEnd,
 		input.ActionMoveFileStart, input.ActionMoveFileEnd:
 		isMovementAction = true
 	}
@@ -45,32 +63,43 @@ func (mh *ModeHandler) executeAction(action input.Action, actionEvent input.Acti
 	switch action {
 	// Mode Switching
 	case input.ActionEnterInsertMode:
+		if mh.editor.GetBuffer().ReadOnly() {
+			mh.statusBar.SetTemporaryMessage(i18n.T("status.read_only"))
+			break
+		}
 		mh.editor.ClearSelection()
+		mh.editor.ClearExtraCursors()
+		mh.blockInsert = nil
 		mh.currentMode = ModeInsert
-		mh.statusBar.SetTemporaryMessage("-- INSERT --")
+		mh.statusBar.SetTemporaryMessage(modeMessage("insert"))
 		logger.Debugf("ModeHandler: Entering Insert Mode")
 
 	case input.ActionEnterNormalMode:
 		mh.editor.ClearSelection()
+		mh.editor.ClearExtraCursors()
 		mh.currentMode = ModeNormal
-		mh.statusBar.SetTemporaryMessage("-- NORMAL --")
+		mh.statusBar.SetTemporaryMessage(modeMessage("normal"))
 		logger.Debugf("ModeHandler: Entering Normal Mode")
 
 	case input.ActionEnterVisualMode:
 		mh.editor.StartOrUpdateSelection()
 		mh.currentMode = ModeVisual
-		mh.statusBar.SetTemporaryMessage("-- VISUAL --")
+		mh.statusBar.SetTemporaryMessage(modeMessage("visual"))
 		logger.Debugf("ModeHandler: Entering Visual Mode")
 
 	case input.ActionEnterVisualBlockMode:
 		mh.editor.StartOrUpdateSelection()
 		mh.editor.SetBlockwise(true)
 		mh.currentMode = ModeVisualBlock
-		mh.statusBar.SetTemporaryMessage("-- VISUAL BLOCK --")
+		mh.statusBar.SetTemporaryMessage(modeMessage("visual_block"))
 		logger.Debugf("ModeHandler: Entering Visual Block Mode")
 
 	case input.ActionEnterCommandMode:
-		mh.editor.ClearSelection()
+		// Keep a visual selection so commands like :transform and :'<,'>s
+		// can act on it; it is cleared when command mode exits.
+		if mh.currentMode != ModeVisual && mh.currentMode != ModeVisualLine {
+			mh.editor.ClearSelection()
+		}
 		mh.currentMode = ModeCommand
 		mh.cmdBuffer = ""
 		mh.statusBar.SetTemporaryMessage(":")
@@ -81,6 +110,7 @@ func (mh *ModeHandler) executeAction(action input.Action, actionEvent input.Acti
 		mh.currentMode = ModeFind
 		mh.findBuffer = ""
 		mh.editor.ClearHighlights()
+		mh.beginIncSearch()
 		mh.statusBar.SetTemporaryMessage("/")
 		logger.Debugf("ModeHandler: Entering Find Mode")
 
@@ -91,11 +121,16 @@ func (mh *ModeHandler) executeAction(action input.Action, actionEvent input.Acti
 		if hasHighlights {
 			// If highlights exist, ESC just clears them
 			mh.editor.ClearHighlights()
-			mh.statusBar.SetTemporaryMessage("Highlights cleared")
+			mh.statusBar.SetTemporaryMessage(i18n.T("status.highlights_cleared"))
 			actionProcessed = true // Action processed, need redraw
+		} else if mh.api != nil {
+			// The App quits directly or shows the save/discard dialog
+			// when any open buffer has unsaved changes.
+			mh.api.RequestQuit(false)
+			actionProcessed = true
 		} else if mh.editor.GetBuffer().IsModified() && !mh.forceQuitPending {
 			// No highlights, but buffer modified -> Show quit prompt
-			mh.statusBar.SetTemporaryMessage("Unsaved changes! Press ESC again or Ctrl+Q to force quit.")
+			mh.statusBar.SetTemporaryMessage(i18n.T("status.unsaved_quit"))
 			mh.forceQuitPending = true
 			actionProcessed = false // Don't process further, redraw needed for status
 		} else {
@@ -112,15 +147,67 @@ func (mh *ModeHandler) executeAction(action input.Action, actionEvent input.Acti
 		err := mh.editor.SaveBuffer()
 		savedPath := mh.editor.GetBuffer().FilePath()
 		if savedPath == "" {
-			savedPath = "[No Name]"
+			savedPath = i18n.T("status.no_name")
 		}
 		if err != nil {
-			mh.statusBar.SetTemporaryMessage("Save FAILED: %v", err)
+			mh.statusBar.SetErrorMessage(i18n.T("status.save_failed", err))
 		} else {
-			mh.statusBar.SetTemporaryMessage("Buffer saved to %s", savedPath)
+			mh.statusBar.SetTemporaryMessage(i18n.T("status.saved_to", savedPath))
 			mh.eventManager.Dispatch(event.TypeBufferSaved, event.BufferSavedData{FilePath: savedPath})
 		}
 
+	// Overlays (opened by the App in response to these events)
+	case input.ActionFuzzyFind:
+		mh.eventManager.Dispatch(event.TypeTriggerFuzzyFind, event.TriggerFuzzyFindData{})
+	case input.ActionSearchWordForward:
+		mh.searchWordUnderCursor(true)
+	case input.ActionSearchWordBackward:
+		mh.searchWordUnderCursor(false)
+	case input.ActionCommandPalette:
+		mh.eventManager.Dispatch(event.TypeTriggerCommandPalette, event.TriggerCommandPaletteData{})
+	case input.ActionClipboardHistory:
+		mh.eventManager.Dispatch(event.TypeTriggerClipboardHistory, event.TriggerClipboardHistoryData{})
+	case input.ActionFileInfo:
+		mh.showFileInfo()
+	case input.ActionHover:
+		mh.eventManager.Dispatch(event.TypeTriggerHover, event.TriggerHoverData{})
+	case input.ActionCodeActions:
+		mh.eventManager.Dispatch(event.TypeTriggerCodeActions, event.TriggerCodeActionsData{})
+
+	case input.ActionEvalSelection:
+		actionProcessed = mh.evalSelection()
+	case input.ActionToggleComment:
+		actionProcessed = mh.toggleComment()
+
+	// Line editing
+	case input.ActionDuplicateLine, input.ActionDeleteLine, input.ActionJoinLines,
+		input.ActionMoveLineUp, input.ActionMoveLineDown:
+		actionProcessed = mh.lineEdit(action, mh.drainCount())
+
+	// Insertion helpers
+	case input.ActionInsertUUID:
+		id, err := utils.NewUUID()
+		if err != nil {
+			mh.statusBar.SetTemporaryMessage("Failed to generate UUID: %v", err)
+			actionProcessed = false
+		} else {
+			actionProcessed = mh.insertAtCursor(id)
+		}
+	case input.ActionInsertDate:
+		actionProcessed = mh.insertAtCursor(time.Now().Format(config.Get().Editor.DateFormat))
+	case input.ActionInsertTime:
+		actionProcessed = mh.insertAtCursor(time.Now().Format(config.Get().Editor.TimeFormat))
+	case input.ActionInsertTimestamp:
+		actionProcessed = mh.insertAtCursor(time.Now().Format(time.RFC3339))
+
+	// Buffer-word completion (insert mode only)
+	case input.ActionCompleteNext, input.ActionCompletePrev:
+		if mh.currentMode == ModeInsert {
+			actionProcessed = mh.completeWord(action == input.ActionCompleteNext)
+		} else {
+			actionProcessed = false
+		}
+
 	// Find Next/Previous
 	case input.ActionFindNext:
 		if mh.lastSearchTerm != "" {
@@ -146,6 +233,12 @@ func (mh *ModeHandler) executeAction(action input.Action, actionEvent input.Acti
 		mh.editor.MoveCursor(0, -1)
 	case input.ActionMoveRight:
 		mh.editor.MoveCursor(0, 1)
+	case input.ActionMoveWordForward:
+		mh.editor.NextWordStart()
+	case input.ActionMoveWordBackward:
+		mh.editor.PrevWordStart()
+	case input.ActionMatchBracket:
+		mh.editor.JumpToMatchingBracket()
 	case input.ActionMovePageUp:
 		mh.editor.PageMove(-1)
 	case input.ActionMovePageDown:
@@ -193,6 +286,9 @@ func (mh *ModeHandler) executeAction(action input.Action, actionEvent input.Acti
 			mh.statusBar.SetTemporaryMessage("Text pasted from clipboard")
 		}
 
+	case input.ActionPastePrimary:
+		mh.pastePrimary()
+
 	case input.ActionPasteBefore:
 		pasted, err := mh.editor.Paste(false) // Paste before
 		if err != nil {
@@ -245,30 +341,42 @@ func (mh *ModeHandler) executeAction(action input.Action, actionEvent input.Acti
 			logger.Debugf("Err InsertRune: %v", err)
 			actionProcessed = false
 		} else {
-			mh.eventManager.Dispatch(event.TypeBufferModified, event.BufferModifiedData{})
+			mh.eventManager.Dispatch(event.TypeBufferModified, event.BufferModifiedData{FilePath: mh.editor.GetBuffer().FilePath()})
 		}
 	case input.ActionInsertTab:
 		if hasHighlights {
 			mh.editor.ClearHighlights()
 		}
-		err := mh.editor.InsertTab()
+		insertTab := mh.editor.InsertTab
+		if mh.editor.HasSelection() {
+			insertTab = func() error { return mh.editor.ShiftSelection(true) } // Tab indents selected lines
+		}
+		err := insertTab()
 		if err != nil {
 			logger.Debugf("Err InsertTab: %v", err)
 			actionProcessed = false
 		} else {
-			mh.eventManager.Dispatch(event.TypeBufferModified, event.BufferModifiedData{})
+			mh.eventManager.Dispatch(event.TypeBufferModified, event.BufferModifiedData{FilePath: mh.editor.GetBuffer().FilePath()})
+		}
+	case input.ActionInsertBacktab:
+		// Shift+Tab outdents the selected lines, or the cursor line
+		if mh.currentMode != ModeInsert {
+			actionProcessed = false
+		} else if err := mh.editor.ShiftSelection(false); err != nil {
+			logger.Debugf("Err ShiftSelection: %v", err)
+			actionProcessed = false
 		}
 	case input.ActionInsertNewLine:
 		if hasHighlights {
 			mh.editor.ClearHighlights()
-			mh.statusBar.SetTemporaryMessage("Highlights cleared")
+			mh.statusBar.SetTemporaryMessage(i18n.T("status.highlights_cleared"))
 		} else {
 			err := mh.editor.InsertNewLine()
 			if err != nil {
 				logger.Debugf("Err InsertNewLine: %v", err)
 				actionProcessed = false
 			} else {
-				mh.eventManager.Dispatch(event.TypeBufferModified, event.BufferModifiedData{})
+				mh.eventManager.Dispatch(event.TypeBufferModified, event.BufferModifiedData{FilePath: mh.editor.GetBuffer().FilePath()})
 			}
 		}
 	case input.ActionDeleteCharBackward:
@@ -280,7 +388,7 @@ func (mh *ModeHandler) executeAction(action input.Action, actionEvent input.Acti
 			logger.Debugf("Err DeleteBackward: %v", err)
 			actionProcessed = false
 		} else {
-			mh.eventManager.Dispatch(event.TypeBufferModified, event.BufferModifiedData{})
+			mh.eventManager.Dispatch(event.TypeBufferModified, event.BufferModifiedData{FilePath: mh.editor.GetBuffer().FilePath()})
 		}
 	case input.ActionDeleteCharForward:
 		if hasHighlights {
@@ -291,7 +399,7 @@ func (mh *ModeHandler) executeAction(action input.Action, actionEvent input.Acti
 			logger.Debugf("Err DeleteForward: %v", err)
 			actionProcessed = false
 		} else {
-			mh.eventManager.Dispatch(event.TypeBufferModified, event.BufferModifiedData{})
+			mh.eventManager.Dispatch(event.TypeBufferModified, event.BufferModifiedData{FilePath: mh.editor.GetBuffer().FilePath()})
 		}
 
 	case input.ActionDeleteWordForward:
@@ -303,7 +411,7 @@ func (mh *ModeHandler) executeAction(action input.Action, actionEvent input.Acti
 			logger.Debugf("Err DeleteWordForward: %v", err)
 			actionProcessed = false
 		} else {
-			mh.eventManager.Dispatch(event.TypeBufferModified, event.BufferModifiedData{})
+			mh.eventManager.Dispatch(event.TypeBufferModified, event.BufferModifiedData{FilePath: mh.editor.GetBuffer().FilePath()})
 		}
 
 	case input.ActionDeleteWordBackward:
@@ -315,15 +423,27 @@ func (mh *ModeHandler) executeAction(action input.Action, actionEvent input.Acti
 			logger.Debugf("Err DeleteWordBackward: %v", err)
 			actionProcessed = false
 		} else {
-			mh.eventManager.Dispatch(event.TypeBufferModified, event.BufferModifiedData{})
+			mh.eventManager.Dispatch(event.TypeBufferModified, event.BufferModifiedData{FilePath: mh.editor.GetBuffer().FilePath()})
 		}
 
+	case input.ActionSelectNextOccurrence:
+		actionProcessed = mh.selectNextOccurrence()
+
 	case input.ActionMoveFileStart:
 		mh.editor.GoToFileStart()
 
 	case input.ActionMoveFileEnd:
 		mh.editor.GoToFileEnd()
 
+	case input.ActionJumpBack, input.ActionJumpForward:
+		actionProcessed = mh.jump(action == input.ActionJumpForward)
+
+	case input.ActionSetMark:
+		actionProcessed = mh.awaitMark('m')
+
+	case input.ActionJumpToMark:
+		actionProcessed = mh.awaitMark('\'')
+
 	case input.ActionUnknown:
 		actionProcessed = false
 	default:
@@ -351,32 +471,79 @@ func (mh *ModeHandler) executeAction(action input.Action, actionEvent input.Acti
 func (mh *ModeHandler) handleActionInsert(actionEvent input.ActionEvent, ev *tcell.EventKey) bool {
 	if actionEvent.Action == input.ActionQuit {
 		mh.recordingInsert = false
+		mh.wordCompletion = nil
+		mh.finishBlockInsert()
 		return mh.executeAction(input.ActionEnterNormalMode, actionEvent, ev)
 	}
+	if !typesAtCursors(actionEvent.Action) {
+		mh.editor.ClearExtraCursors() // Typing goes to every cursor; anything else ends it
+	}
 	// Ctrl+V in insert mode should paste, not enter visual block mode
 	if actionEvent.Action == input.ActionEnterVisualBlockMode {
 		actionEvent.Action = input.ActionPaste
 	}
-	// Record edit actions for dot-repeat (only actual edits, not cursor movement)
-	if actionEvent.Action != input.ActionUnknown {
-		if !mh.recordingInsert {
-			mh.lastInsertActions = nil
-			mh.recordingInsert = true
-		}
-		mh.lastInsertActions = append(mh.lastInsertActions, input.ActionEvent{
-			Action: actionEvent.Action,
-			Rune:   actionEvent.Rune,
-		})
+	// Ctrl+P in insert mode completes backwards, as in Vim
+	if actionEvent.Action == input.ActionCommandPalette {
+		actionEvent.Action = input.ActionCompletePrev
+	}
+	isCompletion := actionEvent.Action == input.ActionCompleteNext || actionEvent.Action == input.ActionCompletePrev
+	if !isCompletion {
+		mh.wordCompletion = nil // Any other key accepts the current candidate
+	}
+	// Record edit actions for dot-repeat (only actual edits, not cursor movement).
+	// Completion records the edits it makes instead, as candidates may differ on replay.
+	if actionEvent.Action != input.ActionUnknown && !isCompletion {
+		mh.recordInsertAction(actionEvent)
 	}
 	processed := mh.executeAction(actionEvent.Action, actionEvent, ev)
-	if processed && mh.onInsertEdit != nil {
+	if processed && !isCompletion && mh.onInsertEdit != nil {
 		mh.onInsertEdit()
 	}
 	return processed
 }
 
+// recordInsertAction appends an insert-mode action to the dot-repeat record,
+// starting a new record at the first action of an insert session.
+func (mh *ModeHandler) recordInsertAction(actionEvent input.ActionEvent) {
+	if !mh.recordingInsert {
+		mh.lastInsertActions = nil
+		mh.recordingInsert = true
+		mh.repeatFn = nil // The insert is now the last change
+	}
+	mh.lastInsertActions = append(mh.lastInsertActions, input.ActionEvent{
+		Action: actionEvent.Action,
+		Rune:   actionEvent.Rune,
+	})
+}
+
 // handleActionNormal handles key events specific to Normal Mode.
 func (mh *ModeHandler) handleActionNormal(actionEvent input.ActionEvent, ev *tcell.EventKey) bool {
+	// Tab/Shift+Tab step between cells in the CSV/TSV table view
+	if mh.editor.TableView() && (actionEvent.Action == input.ActionInsertTab || actionEvent.Action == input.ActionInsertBacktab) {
+		if actionEvent.Action == input.ActionInsertTab {
+			return mh.editor.MoveToCell(1)
+		}
+		return mh.editor.MoveToCell(-1)
+	}
+
+	if mh.operator != nil {
+		return mh.handleOperatorKey(actionEvent, ev)
+	}
+	if mh.windowPending {
+		return mh.handleWindowKey(actionEvent)
+	}
+	if actionEvent.Action == input.ActionWindowCommand {
+		mh.windowPending = true
+		mh.countAccumulator = 0
+		mh.statusBar.SetTemporaryMessage("^W (pending)")
+		return true
+	}
+
+	// Tab is Ctrl+I to the terminal, which goes forward through the jump list
+	if actionEvent.Action == input.ActionInsertTab {
+		return mh.executeAction(input.ActionJumpForward, input.ActionEvent{Action: input.ActionJumpForward}, ev)
+	}
+
 	// Non-rune actions go directly to executeAction
 	if actionEvent.Action != input.ActionInsertRune && actionEvent.Action != input.ActionUnknown {
 		return mh.executeAction(actionEvent.Action, actionEvent, ev)
@@ -385,7 +552,8 @@ func (mh *ModeHandler) handleActionNormal(actionEvent input.ActionEvent, ev *tce
 	if actionEvent.Action == input.ActionInsertRune {
 		r := actionEvent.Rune
 
-		// Handle pending operators (dd, yy, dw, db, gg)
+		// Handle pending two-key commands (gg, ]f, [t); operators such as
+		// d and y wait in mh.operator instead
 		if mh.pendingOperator != 0 {
 			op := mh.pendingOperator
 			mh.statusBar.ResetTemporaryMessage()
@@ -395,6 +563,7 @@ func (mh *ModeHandler) handleActionNormal(actionEvent input.ActionEvent, ev *tce
 				mh.pendingOperator = 0
 				count := mh.drainCount()
 				if count > 1 {
+					mh.editor.PushJump()
 					mh.editor.SetCursor(types.Position{Line: count - 1, Col: 0})
 				} else {
 					mh.editor.GoToFileStart()
@@ -411,34 +580,26 @@ func (mh *ModeHandler) handleActionNormal(actionEvent input.ActionEvent, ev *tce
 				count := mh.drainCount()
 
 				switch {
-				case r == op && (op == 'd' || op == 'y'):
-					mh.editor.ClearSelection()
-					for i := 0; i < count; i++ {
-						mh.editor.StartOrUpdateSelection()
-						mh.editor.SetLinewise(true)
+				case (op == ']' || op == '[') && (r == 'f' || r == 't'):
+					// Jump to the next/previous function or type definition
+					kind, what := highlighter.DefinitionFunction, "function"
+					if r == 't' {
+						kind, what = highlighter.DefinitionClass, "type"
 					}
-					if op == 'd' {
-						return mh.executeAction(input.ActionCut, input.ActionEvent{Action: input.ActionCut}, ev)
-					}
-					return mh.executeAction(input.ActionYank, input.ActionEvent{Action: input.ActionYank}, ev)
-
-				case op == 'd' && r == 'w':
-					for i := 0; i < count; i++ {
-						if err := mh.editor.DeleteWordForward(); err != nil {
-							logger.Debugf("dw error: %v", err)
-						}
+					moved := false
+					for i := 0; i < count && mh.editor.JumpToDefinition(kind, op == ']'); i++ {
+						moved = true
 					}
-					mh.eventManager.Dispatch(event.TypeBufferModified, event.BufferModifiedData{})
-					return true
-
-				case op == 'd' && r == 'b':
-					for i := 0; i < count; i++ {
-						if err := mh.editor.DeleteWordBackward(); err != nil {
-							logger.Debugf("db error: %v", err)
+					if !moved {
+						dir := "below"
+						if op == '[' {
+							dir = "above"
 						}
+						mh.statusBar.SetTemporaryMessage("No %s definition %s the cursor", what, dir)
 					}
-					mh.eventManager.Dispatch(event.TypeBufferModified, event.BufferModifiedData{})
 					return true
+				case op == 'm' || op == '\'' || op == '`':
+					return mh.markKey(op, r)
 				}
 
 				mh.statusBar.SetTemporaryMessage("Operator cancelled")
@@ -447,30 +608,22 @@ func (mh *ModeHandler) handleActionNormal(actionEvent input.ActionEvent, ev *tce
 		}
 
 		// Count prefix: accumulate digits in normal mode
-		if r >= '1' && r <= '9' {
-			mh.countAccumulator = int(r - '0')
+		if r >= '1' && r <= '9' || (r == '0' && mh.countAccumulator > 0) {
+			mh.countAccumulator = mh.countAccumulator*10 + int(r-'0')
 			mh.statusBar.SetTemporaryMessage("%d", mh.countAccumulator)
 			return true
 		}
-		if r == '0' && mh.countAccumulator > 0 {
-			mh.countAccumulator *= 10
-			mh.statusBar.SetTemporaryMessage("%d", mh.countAccumulator)
-			return true
+
+		if strings.ContainsRune(operators, r) {
+			return mh.startOperator(r)
 		}
 
 		// Resolve count (default 1)
 		count := mh.drainCount()
 
-		// Dot-repeat: replay last insert actions
+		// Dot-repeat: run the last change again
 		if r == '.' {
-			if len(mh.lastInsertActions) == 0 {
-				mh.statusBar.SetTemporaryMessage("Nothing to repeat")
-				return true
-			}
-			for _, ae := range mh.lastInsertActions {
-				mh.executeAction(ae.Action, ae, ev)
-			}
-			return true
+			return mh.repeatLastChange(count, ev)
 		}
 
 		// gg / G
@@ -481,16 +634,25 @@ func (mh *ModeHandler) handleActionNormal(actionEvent input.ActionEvent, ev *tce
 		}
 
 		// * and # (search word under cursor)
-		if r == '*' || r == '#' {
-			return mh.searchWordUnderCursor(r == '*')
+		if r == '*' {
+			return mh.executeAction(input.ActionSearchWordForward, input.ActionEvent{Action: input.ActionSearchWordForward}, ev)
+		}
+		if r == '#' {
+			return mh.executeAction(input.ActionSearchWordBackward, input.ActionEvent{Action: input.ActionSearchWordBackward}, ev)
 		}
 
 		switch r {
-		case 'd', 'y':
+		case ']', '[':
+			// ]f/[f and ]t/[t: keep the count for the motion
 			mh.pendingOperator = r
-			mh.statusBar.SetTemporaryMessage(string(r)+" (pending)")
+			mh.countAccumulator = count
+			mh.statusBar.SetTemporaryMessage(string(r) + " (pending)")
 			return true
 
+		case 'm', '\'', '`':
+			// ma sets mark a; 'a and `a go to it
+			return mh.awaitMark(r)
+
 		case 'i':
 			for i := 0; i < count-1; i++ {
 				mh.executeAction(input.ActionMoveLeft, input.ActionEvent{Action: input.ActionMoveLeft}, ev)
@@ -507,7 +669,7 @@ func (mh *ModeHandler) handleActionNormal(actionEvent input.ActionEvent, ev *tce
 			mh.editor.StartOrUpdateSelection()
 			mh.editor.SetLinewise(true)
 			mh.currentMode = ModeVisualLine
-			mh.statusBar.SetTemporaryMessage("-- VISUAL LINE --")
+			mh.statusBar.SetTemporaryMessage(modeMessage("visual_line"))
 			return true
 		case 'A':
 			mh.executeAction(input.ActionMoveEnd, input.ActionEvent{Action: input.ActionMoveEnd}, ev)
@@ -561,6 +723,9 @@ func (mh *ModeHandler) handleActionNormal(actionEvent input.ActionEvent, ev *tce
 				mh.editor.WordEnd()
 			}
 			return true
+		case '%':
+			mh.editor.JumpToMatchingBracket()
+			return true
 
 		case '0':
 			mh.editor.HardHome()
@@ -588,14 +753,17 @@ func (mh *ModeHandler) handleActionNormal(actionEvent input.ActionEvent, ev *tce
 			return mh.executeAction(input.ActionFindPrevious, input.ActionEvent{Action: input.ActionFindPrevious}, ev)
 		case 'G':
 			if count > 1 {
+				mh.editor.PushJump()
 				mh.editor.SetCursor(types.Position{Line: count - 1, Col: 0})
 			} else {
 				mh.editor.GoToFileEnd()
 			}
 			return true
 		case 'J':
-			// Join current line with next
-			return mh.joinLines()
+			// Join count lines (at least the current line with the next)
+			return mh.lineEdit(input.ActionJoinLines, count)
+		case 'K':
+			return mh.executeAction(input.ActionHover, input.ActionEvent{Action: input.ActionHover}, ev)
 		}
 
 		mh.statusBar.SetTemporaryMessage("Unmapped key in Normal mode: %c", r)
@@ -615,7 +783,9 @@ func (mh *ModeHandler) drainCount() int {
 	return c
 }
 
-// searchWordUnderCursor searches forward (*) or backward (#) for the word under the cursor.
+// searchWordUnderCursor searches forward (*) or backward (#) for the
+// identifier under the cursor as a whole word. The escaped pattern becomes
+// the last search term, so n/N and match highlighting continue from it.
 func (mh *ModeHandler) searchWordUnderCursor(forward bool) bool {
 	buf := mh.editor.GetBuffer()
 	if buf == nil {
@@ -626,85 +796,145 @@ func (mh *ModeHandler) searchWordUnderCursor(forward bool) bool {
 	if err != nil {
 		return false
 	}
-	if len(line) == 0 {
-		return false
-	}
-
-	// Extract word boundaries at cursor
-	col := pos.Col
-	if col >= len(line) {
-		col = len(line) - 1
+	word, startCol, ok := find.WordAt(line, pos.Col)
+	if !ok {
+		mh.statusBar.SetTemporaryMessage("No identifier under cursor")
+		return true
 	}
 
-	// Find word start
-	start := col
-	for start > 0 && line[start-1] != ' ' && line[start-1] != '\t' {
-		start--
-	}
-	// Find word end
-	end := col
-	for end < len(line)-1 && line[end+1] != ' ' && line[end+1] != '\t' {
-		end++
+	mh.lastSearchTerm = find.WordPattern(word)
+	mh.lastSearchForward = forward
+	mh.lastSearchOffset = find.Offset{}
+	if err := mh.editor.HighlightMatches(mh.lastSearchTerm); err != nil {
+		mh.statusBar.SetTemporaryMessage("Invalid pattern: %s", err)
+		return true
 	}
+	mh.editor.MarkAllDirty()
 
-	if start >= end {
-		return false
+	// Continue from the start of the word so the occurrence under the
+	// cursor itself is skipped.
+	if findManager := mh.editor.GetFindManager(); findManager != nil {
+		findManager.SetLastMatch(types.Position{Line: pos.Line, Col: startCol})
 	}
+	mh.executeFind(forward, true)
+	return true
+}
 
-	term := string(line[start : end+1])
-	mh.lastSearchTerm = term
-	mh.lastSearchForward = forward
-	mh.editor.HighlightMatches(term)
+// lineEditNames name the edits on whole lines, for "." and for messages.
+var lineEditNames = map[input.Action]string{
+	input.ActionDuplicateLine: "duplicate line",
+	input.ActionDeleteLine:    "delete line",
+	input.ActionJoinLines:     "join lines",
+	input.ActionMoveLineUp:    "move line up",
+	input.ActionMoveLineDown:  "move line down",
+}
 
-	matchPos, found := mh.editor.Find(term, pos, forward)
-	if found {
-		mh.editor.SetCursor(matchPos)
-		mh.editor.ScrollToCursor()
-		mh.statusBar.SetTemporaryMessage("/%s", term)
-	} else {
-		mh.statusBar.SetTemporaryMessage("Pattern not found: %s", term)
+// lineEdit duplicates, deletes, joins or moves the selected lines, or the
+// cursor line. count is how many lines to delete or join from the cursor,
+// how many copies to make or how far to move; the cursor line case is what
+// "." repeats.
+func (mh *ModeHandler) lineEdit(action input.Action, count int) bool {
+	edit := func() error {
+		switch action {
+		case input.ActionDuplicateLine:
+			return mh.editor.DuplicateLines(count)
+		case input.ActionDeleteLine:
+			return mh.editor.DeleteLines(count)
+		case input.ActionJoinLines:
+			return mh.editor.JoinLines(count)
+		case input.ActionMoveLineUp:
+			return mh.editor.MoveLines(-count)
+		}
+		return mh.editor.MoveLines(count)
+	}
+	_, _, selected := mh.editor.GetSelection()
+	if err := edit(); err != nil {
+		mh.statusBar.SetTemporaryMessage("Could not %s: %v", lineEditNames[action], err)
+		return true
+	}
+	if !selected {
+		mh.SetRepeat(lineEditNames[action], edit)
 	}
 	return true
 }
 
-// joinLines joins the current line with the next line (Vim 'J').
-func (mh *ModeHandler) joinLines() bool {
-	buf := mh.editor.GetBuffer()
-	if buf == nil {
-		return false
-	}
-	pos := mh.editor.GetCursor()
-	lineCount := buf.LineCount()
-	if pos.Line >= lineCount-1 {
-		return false
+// visualLineEdit reports whether a key edits the selected lines in the
+// visual modes, J joining them as in Vim, and with which action.
+func visualLineEdit(actionEvent input.ActionEvent) (input.Action, bool) {
+	if actionEvent.Action == input.ActionInsertRune && actionEvent.Rune == 'J' {
+		return input.ActionJoinLines, true
 	}
+	_, ok := lineEditNames[actionEvent.Action]
+	return actionEvent.Action, ok
+}
 
-	line, _ := buf.Line(pos.Line)
-	nextLine, _ := buf.Line(pos.Line + 1)
+// lineEditVisual edits the selected lines, then returns to Normal Mode as
+// shiftVisual does.
+func (mh *ModeHandler) lineEditVisual(action input.Action, actionEvent input.ActionEvent, ev *tcell.EventKey) bool {
+	mh.lineEdit(action, 1)
+	mh.editor.SetBlockwise(false)
+	return mh.executeAction(input.ActionEnterNormalMode, actionEvent, ev)
+}
 
-	trimmedNext := nextLine
-	for len(trimmedNext) > 0 && (trimmedNext[0] == ' ' || trimmedNext[0] == '\t') {
-		trimmedNext = trimmedNext[1:]
+// visualShift reports whether a key shifts the selected lines in the visual
+// modes (> or Tab indent, < or Shift+Tab outdent), and which way.
+func visualShift(actionEvent input.ActionEvent) (right, ok bool) {
+	switch {
+	case actionEvent.Action == input.ActionInsertTab || (actionEvent.Action == input.ActionInsertRune && actionEvent.Rune == '>'):
+		return true, true
+	case actionEvent.Action == input.ActionInsertBacktab || (actionEvent.Action == input.ActionInsertRune && actionEvent.Rune == '<'):
+		return false, true
 	}
+	return false, false
+}
 
-	_, err := buf.Delete(types.Position{Line: pos.Line, Col: len(line)}, types.Position{Line: pos.Line + 1, Col: 0})
-	if err != nil {
-		return false
+// shiftVisual indents or outdents the selected lines, then returns to
+// Normal Mode as Vim does.
+func (mh *ModeHandler) shiftVisual(right bool, actionEvent input.ActionEvent, ev *tcell.EventKey) bool {
+	if err := mh.editor.ShiftSelection(right); err != nil {
+		mh.statusBar.SetTemporaryMessage("Shift failed: %v", err)
 	}
-	_, err = buf.Insert(types.Position{Line: pos.Line, Col: len(line)}, []byte(" "+string(trimmedNext)))
-	if err != nil {
-		return false
+	mh.editor.SetBlockwise(false)
+	return mh.executeAction(input.ActionEnterNormalMode, actionEvent, ev)
+}
+
+// toggleComment comments or uncomments the selected lines, or the cursor
+// line; the cursor line case is what "." repeats.
+func (mh *ModeHandler) toggleComment() bool {
+	_, _, selected := mh.editor.GetSelection()
+	if err := mh.editor.ToggleComment(); err != nil {
+		mh.statusBar.SetTemporaryMessage("Toggle comment failed: %v", err)
+		return true
+	}
+	if !selected {
+		mh.SetRepeat("toggle comment", func() error { return mh.editor.ToggleComment() })
 	}
-	mh.eventManager.Dispatch(event.TypeBufferModified, event.BufferModifiedData{})
 	return true
 }
 
+// commentVisual comments or uncomments the selected lines, then returns
+// to Normal Mode as shiftVisual does.
+func (mh *ModeHandler) commentVisual(actionEvent input.ActionEvent, ev *tcell.EventKey) bool {
+	mh.toggleComment()
+	mh.editor.SetBlockwise(false)
+	return mh.executeAction(input.ActionEnterNormalMode, actionEvent, ev)
+}
+
 // handleActionVisual handles key events specific to Visual Mode.
 func (mh *ModeHandler) handleActionVisual(actionEvent input.ActionEvent, ev *tcell.EventKey) bool {
 	if actionEvent.Action == input.ActionQuit {
 		mh.editor.ClearSelection()
 		return mh.executeAction(input.ActionEnterNormalMode, actionEvent, ev)
 	}
+	if right, ok := visualShift(actionEvent); ok {
+		return mh.shiftVisual(right, actionEvent, ev)
+	}
+	if actionEvent.Action == input.ActionToggleComment {
+		return mh.commentVisual(actionEvent, ev)
+	}
+	if action, ok := visualLineEdit(actionEvent); ok {
+		return mh.lineEditVisual(action, actionEvent, ev)
+	}
 
 	if actionEvent.Action >= input.ActionMoveUp && actionEvent.Action <= input.ActionMoveEnd {
 		mockShiftEv := tcell.NewEventKey(ev.Key(), ev.Rune(), ev.Modifiers()|tcell.ModShift)
@@ -719,6 +949,12 @@ func (mh *ModeHandler) handleActionVisual(actionEvent input.ActionEvent, ev *tce
 	}
 
 	if actionEvent.Action == input.ActionDeleteCharForward || actionEvent.Action == input.ActionDeleteCharBackward || (actionEvent.Action == input.ActionInsertRune && (actionEvent.Rune == 'd' || actionEvent.Rune == 'x')) {
+		if ok, err := mh.editor.DeleteSelections(); ok || err != nil {
+			if err != nil {
+				mh.statusBar.SetTemporaryMessage("Delete failed: %v", err)
+			}
+			return mh.executeAction(input.ActionEnterNormalMode, actionEvent, ev)
+		}
 		res := mh.executeAction(input.ActionCut, actionEvent, ev)
 		mh.editor.ClearSelection()
 		mh.executeAction(input.ActionEnterNormalMode, actionEvent, ev)
@@ -741,6 +977,35 @@ func (mh *ModeHandler) handleActionVisual(actionEvent input.ActionEvent, ev *tce
 		return res
 	}
 
+	// : in visual mode: run a command against the selection
+	if actionEvent.Action == input.ActionInsertRune && actionEvent.Rune == ':' {
+		return mh.executeAction(input.ActionEnterCommandMode, input.ActionEvent{Action: input.ActionEnterCommandMode}, ev)
+	}
+
+	// = in visual mode: replace the selected expression with its value
+	if actionEvent.Action == input.ActionEvalSelection || (actionEvent.Action == input.ActionInsertRune && actionEvent.Rune == '=') {
+		res := mh.executeAction(input.ActionEvalSelection, input.ActionEvent{Action: input.ActionEvalSelection}, ev)
+		mh.editor.ClearSelection()
+		mh.executeAction(input.ActionEnterNormalMode, actionEvent, ev)
+		return res
+	}
+
+	// Ctrl+D selects the next occurrence too; c, s, I and A type at every
+	// selection
+	if actionEvent.Action == input.ActionSelectNextOccurrence {
+		return mh.selectNextOccurrence()
+	}
+	if actionEvent.Action == input.ActionInsertRune {
+		switch actionEvent.Rune {
+		case 'c', 's':
+			return mh.insertAtSelections(true, false)
+		case 'I':
+			return mh.insertAtSelections(false, false)
+		case 'A':
+			return mh.insertAtSelections(false, true)
+		}
+	}
+
 	return false
 }
 
@@ -751,6 +1016,15 @@ func (mh *ModeHandler) handleActionVisualLine(actionEvent input.ActionEvent, ev
 		mh.editor.ClearSelection()
 		return mh.executeAction(input.ActionEnterNormalMode, actionEvent, ev)
 	}
+	if right, ok := visualShift(actionEvent); ok {
+		return mh.shiftVisual(right, actionEvent, ev)
+	}
+	if actionEvent.Action == input.ActionToggleComment {
+		return mh.commentVisual(actionEvent, ev)
+	}
+	if action, ok := visualLineEdit(actionEvent); ok {
+		return mh.lineEditVisual(action, actionEvent, ev)
+	}
 
 	// Movement: update line-wise selection (cursor moves, selection follows)
 	isMovement := actionEvent.Action >= input.ActionMoveUp && actionEvent.Action <= input.ActionMoveEnd
@@ -793,6 +1067,10 @@ func (mh *ModeHandler) handleActionVisualLine(actionEvent input.ActionEvent, ev
 			mh.editor.WordEnd()
 			mh.editor.SetLinewise(true)
 			return true
+		case '%':
+			mh.editor.JumpToMatchingBracket()
+			mh.editor.SetLinewise(true)
+			return true
 		case '0':
 			mh.editor.HardHome()
 			mh.editor.SetLinewise(true)
@@ -821,6 +1099,15 @@ func (mh *ModeHandler) handleActionVisualLine(actionEvent input.ActionEvent, ev
 			mh.editor.ClearSelection()
 			mh.executeAction(input.ActionEnterNormalMode, actionEvent, ev)
 			return res
+		case ':':
+			// Run a command against the selected lines
+			return mh.executeAction(input.ActionEnterCommandMode, input.ActionEvent{Action: input.ActionEnterCommandMode}, ev)
+		case '=':
+			// Replace the selected expression with its value then return to Normal
+			res := mh.executeAction(input.ActionEvalSelection, input.ActionEvent{Action: input.ActionEvalSelection}, ev)
+			mh.editor.ClearSelection()
+			mh.executeAction(input.ActionEnterNormalMode, actionEvent, ev)
+			return res
 		}
 	}
 
@@ -848,6 +1135,15 @@ func (mh *ModeHandler) handleActionVisualBlock(actionEvent input.ActionEvent, ev
 		mh.editor.SetBlockwise(false)
 		return mh.executeAction(input.ActionEnterNormalMode, actionEvent, ev)
 	}
+	if right, ok := visualShift(actionEvent); ok {
+		return mh.shiftVisual(right, actionEvent, ev)
+	}
+	if actionEvent.Action == input.ActionToggleComment {
+		return mh.commentVisual(actionEvent, ev)
+	}
+	if action, ok := visualLineEdit(actionEvent); ok {
+		return mh.lineEditVisual(action, actionEvent, ev)
+	}
 
 	isMovement := actionEvent.Action >= input.ActionMoveUp && actionEvent.Action <= input.ActionMoveEnd
 	if isMovement {
@@ -878,6 +1174,9 @@ func (mh *ModeHandler) handleActionVisualBlock(actionEvent input.ActionEvent, ev
 		case 'e':
 			mh.editor.WordEnd()
 			return true
+		case '%':
+			mh.editor.JumpToMatchingBracket()
+			return true
 		case '0':
 			mh.editor.HardHome()
 			return true
@@ -906,21 +1205,11 @@ func (mh *ModeHandler) handleActionVisualBlock(actionEvent input.ActionEvent, ev
 			mh.executeAction(input.ActionEnterNormalMode, actionEvent, ev)
 			return res
 		case 'c', 's':
-			// Change block: delete block, enter insert mode at start of block
-			startLine, endLine, startCol, _ := mh.editor.GetBlockRange()
-			if startLine < 0 {
-				return false
-			}
-			for line := startLine; line <= endLine; line++ {
-				mh.editor.SetCursor(types.Position{Line: line, Col: startCol})
-				_ = mh.editor.DeleteForward()
-			}
-			mh.editor.ClearSelection()
-			mh.editor.SetBlockwise(false)
-			mh.editor.SetCursor(types.Position{Line: startLine, Col: startCol})
-			mh.currentMode = ModeInsert
-			mh.statusBar.SetTemporaryMessage("-- INSERT --")
-			return true
+			return mh.startBlockInsert(false, true)
+		case 'I':
+			return mh.startBlockInsert(false, false)
+		case 'A':
+			return mh.startBlockInsert(true, false)
 		}
 	}
 
@@ -941,3 +1230,53 @@ func (mh *ModeHandler) handleActionVisualBlock(actionEvent input.ActionEvent, ev
 
 	return false
 }
+
+// evalSelection replaces the selected arithmetic expression with its value.
+func (mh *ModeHandler) evalSelection() bool {
+	start, end, text, ok := mh.editor.SelectedText()
+	if !ok {
+		mh.statusBar.SetTemporaryMessage("No selection to evaluate")
+		return false
+	}
+	mh.AddHistory(HistoryExpr, text)
+	v, err := calc.Eval(text)
+	if err != nil {
+		mh.statusBar.SetTemporaryMessage("Cannot evaluate selection: %v", err)
+		return false
+	}
+	result := calc.Format(v)
+	if _, err := mh.editor.ReplaceRange(start, end, []byte(result)); err != nil {
+		mh.statusBar.SetTemporaryMessage("Failed to replace selection: %v", err)
+		return false
+	}
+	mh.statusBar.SetTemporaryMessage("= %s", result)
+	return true
+}
+
+// insertAtCursor inserts text at the cursor as one undoable change.
+func (mh *ModeHandler) insertAtCursor(text string) bool {
+	if err := mh.editor.InsertAtCursor([]byte(text)); err != nil {
+		mh.statusBar.SetTemporaryMessage("Insert failed: %v", err)
+		return false
+	}
+	return true
+}
+
+// showFileInfo shows the buffer's full path, line count and cursor position
+// in the status bar, like Vim's Ctrl+G.
+func (mh *ModeHandler) showFileInfo() {
+	buf := mh.editor.GetBuffer()
+	path := buf.FilePath()
+	if path == "" {
+		path = "[No Name]"
+	} else if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	modified := ""
+	if buf.IsModified() {
+		modified = " [Modified]"
+	}
+	lines := buf.LineCount()
+	percent := 100 * (mh.editor.GetCursor().Line + 1) / max(lines, 1)
+	mh.statusBar.SetTemporaryMessage("%q%s %d lines --%d%%--", path, modified, lines, percent)
+}
@@ -0,0 +1,101 @@
+package modehandler
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bethropolis/tide/internal/i18n"
+	"github.com/bethropolis/tide/internal/logger"
+	"github.com/bethropolis/tide/internal/types"
+)
+
+// blockInsert is an insert started on a visual block with I, A or c: what
+// is typed on the first line of the block goes on the others as well, at
+// the same column, when insert mode ends.
+type blockInsert struct {
+	startLine, endLine int
+	col                int
+	lineLen            int  // Runes on the first line before typing
+	lineCount          int  // Lines in the buffer before typing
+	pad                bool // Pad lines too short to reach col (A) instead of skipping them
+}
+
+// startBlockInsert enters insert mode on the first line of the selected
+// block, before its left edge (I), after its right edge with after (A), or
+// in place of it with change (c), and clears it. Text typed after the
+// block also goes on lines too short to reach it, padded with spaces.
+func (mh *ModeHandler) startBlockInsert(after, change bool) bool {
+	startLine, endLine, startCol, endCol := mh.editor.GetBlockRange()
+	if startLine < 0 {
+		return false
+	}
+	if mh.editor.GetBuffer().ReadOnly() {
+		mh.statusBar.SetTemporaryMessage(i18n.T("status.read_only"))
+		return true
+	}
+	if change {
+		if _, err := mh.editor.CutSelection(); err != nil {
+			mh.statusBar.SetTemporaryMessage("Cut failed: %v", err)
+			return true
+		}
+	}
+	mh.editor.ClearSelection()
+	if after {
+		return mh.enterBlockInsert(startLine, endLine, endCol+1, true)
+	}
+	return mh.enterBlockInsert(startLine, endLine, startCol, false)
+}
+
+// enterBlockInsert enters insert mode at column col of startLine for a
+// block insert on lines startLine to endLine.
+func (mh *ModeHandler) enterBlockInsert(startLine, endLine, col int, pad bool) bool {
+	line, err := mh.editor.GetBuffer().Line(startLine)
+	if err != nil {
+		return false
+	}
+	if n := utf8.RuneCount(line); n < col && pad {
+		end := types.Position{Line: startLine, Col: n}
+		if _, err := mh.editor.ReplaceRange(end, end, []byte(strings.Repeat(" ", col-n))); err != nil {
+			logger.Debugf("ModeHandler: Padding for block insert failed: %v", err)
+		}
+		line, _ = mh.editor.GetBuffer().Line(startLine)
+	}
+	lineLen := utf8.RuneCount(line)
+	mh.editor.SetCursor(types.Position{Line: startLine, Col: min(col, lineLen)})
+	mh.blockInsert = &blockInsert{
+		startLine: startLine,
+		endLine:   endLine,
+		col:       min(col, lineLen),
+		lineLen:   lineLen,
+		lineCount: mh.editor.GetBuffer().LineCount(),
+		pad:       pad,
+	}
+	mh.currentMode = ModeInsert
+	mh.statusBar.SetTemporaryMessage(modeMessage("insert"))
+	return true
+}
+
+// finishBlockInsert copies the text typed on the first line of a block
+// insert to the other lines of the block. Typing that did more than add
+// text at the column, such as starting a new line, is left as it is.
+func (mh *ModeHandler) finishBlockInsert() {
+	b := mh.blockInsert
+	mh.blockInsert = nil
+	if b == nil || b.endLine == b.startLine || mh.editor.GetBuffer().LineCount() != b.lineCount {
+		return
+	}
+	line, err := mh.editor.GetBuffer().Line(b.startLine)
+	if err != nil {
+		return
+	}
+	runes := []rune(string(line))
+	typed := len(runes) - b.lineLen
+	if typed <= 0 || b.col+typed > len(runes) {
+		return
+	}
+	cursor := mh.editor.GetCursor()
+	if err := mh.editor.InsertColumn(b.startLine+1, b.endLine, b.col, []byte(string(runes[b.col:b.col+typed])), b.pad); err != nil {
+		mh.statusBar.SetTemporaryMessage("Block insert failed: %v", err)
+	}
+	mh.editor.SetCursor(cursor)
+}
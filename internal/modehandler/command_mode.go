@@ -7,6 +7,7 @@ import (
 	"github.com/bethropolis/tide/internal/core/find"
 	"github.com/bethropolis/tide/internal/input"
 	"github.com/bethropolis/tide/internal/logger"
+	"github.com/bethropolis/tide/internal/types"
 )
 
 // handleActionCommand handles actions when in ModeCommand.
@@ -17,16 +18,19 @@ func (mh *ModeHandler) handleActionCommand(actionEvent input.ActionEvent) bool {
 	switch actionEvent.Action {
 	case input.ActionInsertRune:
 		mh.resetCommandAutocomplete()
+		mh.resetRecall()
 		mh.cmdBuffer += string(actionEvent.Rune)
 		needsUpdate = true
 
 	case input.ActionDeleteCharBackward: // Backspace
 		mh.resetCommandAutocomplete()
+		mh.resetRecall()
 		if len(mh.cmdBuffer) > 0 {
 			// Correct handling for multi-byte runes might be needed here
 			mh.cmdBuffer = mh.cmdBuffer[:len(mh.cmdBuffer)-1]
 			needsUpdate = true
 		} else {
+			mh.editor.ClearSelection()
 			mh.currentMode = ModeNormal
 			mh.statusBar.SetTemporaryMessage("") // Clear status explicitly
 			logger.Debugf("ModeHandler: Exiting Command Mode via Backspace")
@@ -40,14 +44,23 @@ func (mh *ModeHandler) handleActionCommand(actionEvent input.ActionEvent) bool {
 		mh.handleCommandAutocomplete(true)
 		needsUpdate = true
 
+	case input.ActionMoveUp, input.ActionMoveDown: // Recall earlier commands
+		mh.resetCommandAutocomplete()
+		needsUpdate = mh.recallHistory(HistoryCommand, &mh.cmdBuffer, actionEvent.Action == input.ActionMoveUp)
+
 	case input.ActionInsertNewLine: // Enter: Execute command
 		mh.resetCommandAutocomplete()
+		mh.resetRecall()
+		mh.AddHistory(HistoryCommand, mh.cmdBuffer)
 		mh.executeCommand()
+		mh.editor.ClearSelection()
 		mh.currentMode = ModeNormal // Return to normal mode
 		// executeCommand sets status message, redraw is needed
 
 	case input.ActionQuit: // Escape: Cancel command
 		mh.resetCommandAutocomplete()
+		mh.resetRecall()
+		mh.editor.ClearSelection()
 		mh.currentMode = ModeNormal
 		mh.cmdBuffer = ""
 		mh.statusBar.SetTemporaryMessage("") // Clear status
@@ -75,19 +88,26 @@ func (mh *ModeHandler) resetCommandAutocomplete() {
 func (mh *ModeHandler) handleCommandAutocomplete(reverse bool) {
 	// If starting fresh or typing a new word
 	if mh.cmdSuggestionIdx == -1 {
-		// Only attempt to autocomplete if there's no space in the buffer
-		if strings.Contains(mh.cmdBuffer, " ") {
-			return // Don't autocomplete arguments yet
-		}
-
 		mh.cmdOriginalBuf = mh.cmdBuffer
 		mh.cmdSuggestions = []string{mh.cmdOriginalBuf}
 
-		// Find matches
 		var matches []string
-		for name := range mh.commands {
-			if strings.HasPrefix(name, mh.cmdOriginalBuf) {
-				matches = append(matches, name)
+		if name, arg, found := strings.Cut(mh.cmdBuffer, " "); found {
+			// Complete the first argument of commands that registered candidates
+			complete, ok := mh.argCompleters[name]
+			if !ok || strings.Contains(arg, " ") {
+				return
+			}
+			for _, candidate := range complete() {
+				if strings.HasPrefix(candidate, arg) {
+					matches = append(matches, name+" "+candidate)
+				}
+			}
+		} else {
+			for name := range mh.commands {
+				if strings.HasPrefix(name, mh.cmdOriginalBuf) {
+					matches = append(matches, name)
+				}
 			}
 		}
 
@@ -129,6 +149,8 @@ func (mh *ModeHandler) updateCommandStatusBar() {
 			if i == 0 {
 				continue // Skip the original buffer in the list
 			}
+			// Argument candidates are shown without the command name
+			sug = sug[strings.LastIndex(sug, " ")+1:]
 			if i == mh.cmdSuggestionIdx {
 				parts = append(parts, "["+sug+"]")
 			} else {
@@ -154,36 +176,74 @@ func (mh *ModeHandler) executeCommand() {
 	}
 	cmdStr := mh.cmdBuffer // Copy buffer before clearing
 	mh.cmdBuffer = ""      // Clear buffer now
+	mh.recordCommand(cmdStr)
 
-	// --- Handle range-prefixed substitute commands before splitting on whitespace ---
-	// :%s/pattern/replacement/[g][i]  → ReplaceAll across entire buffer
-	if strings.HasPrefix(cmdStr, "%s/") {
-		subStr := "/" + cmdStr[2:] // strip the leading '%', keep the /pat/rep/[g][i] part
-		pattern, replacement, _, caseInsensitive, err := find.ParseSubstituteCommand(subStr)
+	// --- Handle substitute commands before splitting on whitespace ---
+	// :s/pattern/replacement/[g][i][c] on the cursor line, or over a range:
+	// :%s/..., :1,20s/..., :.,$s/..., :'<,'>s/...
+	ctx := mh.rangeContext()
+	rng, rest, ranged, err := find.ParseLineRange(cmdStr, ctx)
+	if err != nil {
+		// A bare :N past the end goes to the last line, as in Vim; a range
+		// given to a command must still lie within the buffer
+		ctx.Clamp = true
+		if r, tail, ok, cerr := find.ParseLineRange(cmdStr, ctx); cerr == nil && ok && strings.TrimSpace(tail) == "" {
+			rng, rest, ranged, err = r, tail, ok, nil
+		}
+	}
+	if err != nil {
+		mh.statusBar.SetTemporaryMessage("Invalid range: %v", err)
+		return
+	}
+	if subStr, ok := substituteArgs(rest); ok {
+		sub, err := find.ParseSubstitute(subStr)
 		if err != nil {
 			mh.statusBar.SetTemporaryMessage("Invalid substitute: %v", err)
 			return
 		}
+		if !ranged {
+			line := mh.editor.GetCursor().Line
+			rng = find.LineRange{Start: line, End: line}
+		}
+		if sub.Confirm {
+			if err := mh.startSubstituteConfirm(sub, rng.Start, rng.End); err != nil {
+				mh.statusBar.SetTemporaryMessage("Replace failed: %v", err)
+			}
+			return
+		}
 		if mh.api == nil {
 			mh.statusBar.SetTemporaryMessage("No editor API available")
 			return
 		}
-		count, err := mh.api.ReplaceAll(pattern, replacement, caseInsensitive)
+		var count int
+		if ranged {
+			count, err = mh.api.ReplaceLines(sub.Pattern, sub.Replacement, rng.Start, rng.End, sub.Global, sub.CaseInsensitive)
+		} else {
+			count, err = mh.api.Replace(sub.Pattern, sub.Replacement, sub.Global, sub.CaseInsensitive)
+		}
 		if err != nil {
 			mh.statusBar.SetTemporaryMessage("Replace failed: %v", err)
 			return
 		}
 		if count == 0 {
-			mh.statusBar.SetTemporaryMessage("Pattern not found: %s", pattern)
+			mh.statusBar.SetTemporaryMessage("Pattern not found: %s", sub.Pattern)
 		} else {
 			mh.statusBar.SetTemporaryMessage("Replaced %d occurrence(s)", count)
 		}
 		return
 	}
 
-	// :'<,'>s/pattern/replacement/[g][i]  → ReplaceInRange using current visual selection
-	if strings.HasPrefix(cmdStr, "'<,'>s/") {
-		subStr := "/" + cmdStr[6:] // strip "'<,'>", keep /pat/rep/[g][i]
+	// :N goes to line N, or to the last line of a range, as a jump
+	if ranged && strings.TrimSpace(rest) == "" {
+		mh.editor.PushJump()
+		mh.editor.SetCursor(types.Position{Line: rng.End})
+		mh.editor.ScrollToCursor()
+		return
+	}
+
+	// :S/pattern/replacement/[i]  → replace across every file in the project
+	if strings.HasPrefix(cmdStr, "S/") || strings.HasPrefix(cmdStr, "S /") {
+		subStr := strings.TrimLeft(cmdStr[1:], " ")
 		pattern, replacement, _, caseInsensitive, err := find.ParseSubstituteCommand(subStr)
 		if err != nil {
 			mh.statusBar.SetTemporaryMessage("Invalid substitute: %v", err)
@@ -193,17 +253,8 @@ func (mh *ModeHandler) executeCommand() {
 			mh.statusBar.SetTemporaryMessage("No editor API available")
 			return
 		}
-		// Determine range from the stored visual selection on the editor
-		startLine, endLine := mh.editor.GetVisualSelectionLines()
-		count, err := mh.api.ReplaceInRange(pattern, replacement, startLine, endLine, caseInsensitive)
-		if err != nil {
+		if err := mh.api.ProjectReplace(pattern, replacement, caseInsensitive); err != nil {
 			mh.statusBar.SetTemporaryMessage("Replace failed: %v", err)
-			return
-		}
-		if count == 0 {
-			mh.statusBar.SetTemporaryMessage("Pattern not found: %s", pattern)
-		} else {
-			mh.statusBar.SetTemporaryMessage("Replaced %d occurrence(s)", count)
 		}
 		return
 	}
@@ -219,10 +270,40 @@ func (mh *ModeHandler) executeCommand() {
 		logger.Debugf("ModeHandler: Executing command ':%s' with args %v", cmdName, args)
 		err := cmdFunc(args) // Execute
 		if err != nil {
-			mh.statusBar.SetTemporaryMessage("Error executing command '%s': %v", cmdName, err)
+			mh.statusBar.SetErrorMessage("Error executing command '%s': %v", cmdName, err)
 		}
 		// Success message usually set by the command itself via API
 	} else {
 		mh.statusBar.SetTemporaryMessage("Unknown command: %s", cmdName)
 	}
 }
+
+// substituteArgs returns the "/pattern/replacement/flags" of a command that
+// is a substitute ("s/a/b/" or "s /a/b/"), once any range is taken off.
+func substituteArgs(cmd string) (string, bool) {
+	if !strings.HasPrefix(cmd, "s") {
+		return "", false
+	}
+	args := strings.TrimLeft(cmd[1:], " ")
+	return args, strings.HasPrefix(args, "/")
+}
+
+// rangeContext resolves the addresses of a command-line range against the
+// active buffer: "'<" and "'>" are the first and last lines of the visual
+// selection.
+func (mh *ModeHandler) rangeContext() find.RangeContext {
+	return find.RangeContext{
+		Cursor:   mh.editor.GetCursor().Line,
+		LastLine: mh.editor.GetBuffer().LineCount() - 1,
+		Mark: func(r rune) (int, bool) {
+			start, end := mh.editor.GetVisualSelectionLines()
+			switch r {
+			case '<':
+				return start, true
+			case '>':
+				return end, true
+			}
+			return 0, false
+		},
+	}
+}
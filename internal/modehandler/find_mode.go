@@ -1,6 +1,9 @@
 package modehandler
 
 import (
+	"github.com/bethropolis/tide/internal/config"
+	"github.com/bethropolis/tide/internal/core/find"
+	"github.com/bethropolis/tide/internal/i18n"
 	"github.com/bethropolis/tide/internal/input"
 	"github.com/bethropolis/tide/internal/logger"
 )
@@ -12,24 +15,51 @@ func (mh *ModeHandler) handleActionFind(actionEvent input.ActionEvent) bool {
 
 	switch actionEvent.Action {
 	case input.ActionInsertRune: // Append to find buffer
+		mh.resetRecall()
 		mh.findBuffer += string(actionEvent.Rune)
 		needsUpdate = true
+		mh.scheduleIncSearch()
 
 	case input.ActionDeleteCharBackward: // Backspace in find buffer
+		mh.resetRecall()
 		if len(mh.findBuffer) > 0 {
 			// TODO: Correct multi-byte rune handling for backspace if needed
 			mh.findBuffer = mh.findBuffer[:len(mh.findBuffer)-1]
 			needsUpdate = true
+			mh.scheduleIncSearch()
 		} else {
 			// Backspace on empty find buffer returns to Normal mode
 			mh.cancelFindMode() // Use the new helper function
 		}
 
+	case input.ActionMoveUp, input.ActionMoveDown: // Recall earlier searches
+		if mh.recallHistory(HistorySearch, &mh.findBuffer, actionEvent.Action == input.ActionMoveUp) {
+			needsUpdate = true
+			mh.scheduleIncSearch()
+		}
+
 	case input.ActionInsertNewLine: // Enter key: Execute search
+		// The search starts over from where find mode did
+		mh.stopIncSearch()
+		mh.restoreFindOrigin()
+		mh.resetRecall()
 		if mh.findBuffer != "" {
-			mh.lastSearchTerm = mh.findBuffer // Store for 'n'/'N'
-			mh.lastSearchForward = true       // Initial search is forward
-			mh.executeFind(true, false)       // Execute find (forward), not subsequent
+			mh.AddHistory(HistorySearch, mh.findBuffer)
+			pattern, offset, err := find.ParseSearch(mh.findBuffer)
+			if pattern == "" {
+				pattern = mh.lastSearchTerm // "//e" searches for the last pattern again
+			}
+			switch {
+			case err != nil:
+				mh.statusBar.SetTemporaryMessage("%v", err)
+			case pattern == "":
+				mh.statusBar.SetTemporaryMessage("No previous search term")
+			default:
+				mh.lastSearchTerm = pattern  // Store for 'n'/'N'
+				mh.lastSearchOffset = offset // Which they land with too
+				mh.lastSearchForward = true  // Initial search is forward
+				mh.executeFind(true, false)  // Execute find (forward), not subsequent
+			}
 		} else {
 			mh.statusBar.SetTemporaryMessage("") // Clear "/" if nothing typed
 			mh.editor.ClearHighlights()          // Clear highlights if no search term
@@ -57,6 +87,9 @@ func (mh *ModeHandler) handleActionFind(actionEvent input.ActionEvent) bool {
 func (mh *ModeHandler) cancelFindMode() {
 	mh.currentMode = ModeNormal
 	mh.findBuffer = ""
+	mh.resetRecall()
+	mh.stopIncSearch()
+	mh.restoreFindOrigin()      // Back to where the search started
 	mh.editor.ClearHighlights() // Always clear highlights when canceling
 	mh.editor.MarkAllDirty()    // Clearing highlights affects all visible lines
 	mh.statusBar.SetTemporaryMessage("")
@@ -89,20 +122,33 @@ func (mh *ModeHandler) executeFind(forward bool, isSubsequent bool) {
 	}
 
 	// Call FindNext which now returns wrap status
-	foundPos, found, wrapped := findManager.FindNext(forward)
+	wrap := config.Get().Editor.WrapScan
+	foundPos, found, wrapped := findManager.FindNext(forward, wrap)
 
 	if found {
-		mh.editor.SetCursor(foundPos)  // Move cursor to start of match
+		// The search offset says where on the match the cursor goes; the
+		// next 'n'/'N' still continues from the start of the match
+		buf := mh.editor.GetBuffer()
+		end := findManager.MatchEnd(foundPos)
+		mh.editor.PushJump()
+		mh.editor.SetCursor(mh.lastSearchOffset.Apply(foundPos, end, buf.LineCount(), mh.lineLength))
 		mh.editor.ScrollToCursor()     // Ensure cursor is visible
 		mh.lastMatchPos = &foundPos    // Store found position
 		mh.lastSearchForward = forward // Remember direction for next 'n'/'N'
 
-		message := "Found: '" + mh.lastSearchTerm + "'"
-		if wrapped {
-			message += " (wrapped)" // Add wrap indicator
+		switch {
+		case wrapped && forward:
+			mh.statusBar.SetTemporaryMessage(i18n.T("search.wrapped_bottom"))
+		case wrapped:
+			mh.statusBar.SetTemporaryMessage(i18n.T("search.wrapped_top"))
+		default:
+			mh.statusBar.SetTemporaryMessage("Found: '%s'", mh.lastSearchTerm)
 		}
-		mh.statusBar.SetTemporaryMessage(message)
 		logger.Debugf("ModeHandler: Found '%s' at %v (wrapped: %v)", mh.lastSearchTerm, foundPos, wrapped)
+	} else if !wrap && forward {
+		mh.statusBar.SetErrorMessage(i18n.T("search.hit_bottom", mh.lastSearchTerm))
+	} else if !wrap {
+		mh.statusBar.SetErrorMessage(i18n.T("search.hit_top", mh.lastSearchTerm))
 	} else {
 		message := "Pattern not found: " + mh.lastSearchTerm
 		if isSubsequent {
@@ -0,0 +1,93 @@
+package modehandler
+
+import (
+	"strings"
+
+	"github.com/bethropolis/tide/internal/cmdhistory"
+	"github.com/bethropolis/tide/internal/config"
+	"github.com/bethropolis/tide/internal/logger"
+	"github.com/bethropolis/tide/internal/paths"
+)
+
+// History kinds, also the names of their files in the history directory.
+const (
+	HistoryCommand = "command"
+	HistorySearch  = "search"
+	HistoryExpr    = "expr"
+)
+
+// loadHistories reads the saved histories, keeping them in memory only
+// when the state directory cannot be found.
+func loadHistories() map[string]*cmdhistory.List {
+	size := config.Get().Editor.HistorySize
+	histories := make(map[string]*cmdhistory.List, 3)
+	for _, kind := range []string{HistoryCommand, HistorySearch, HistoryExpr} {
+		path, err := paths.State(config.HistoryDirName, kind)
+		if err != nil {
+			logger.Warnf("History: no state directory for the %s history: %v", kind, err)
+			path = ""
+		}
+		histories[kind] = cmdhistory.Load(path, size)
+	}
+	return histories
+}
+
+// AddHistory records line in the history of the given kind.
+func (mh *ModeHandler) AddHistory(kind, line string) {
+	if h := mh.histories[kind]; h != nil {
+		h.Add(line)
+	}
+}
+
+// History returns the lines of the history of the given kind, oldest first.
+func (mh *ModeHandler) History(kind string) []string {
+	if h := mh.histories[kind]; h != nil {
+		return h.Entries()
+	}
+	return nil
+}
+
+// historyRecall steps through a history with Up and Down at a prompt,
+// showing only the lines starting with what was typed before the first
+// step, as Vim does.
+type historyRecall struct {
+	kind    string
+	entries []string
+	prefix  string
+	idx     int // Into entries; len(entries) is the typed line itself
+}
+
+// recallHistory replaces *buf with the previous (older) or next line of the
+// kind's history that starts with what was typed. Returns false when there
+// is no such line.
+func (mh *ModeHandler) recallHistory(kind string, buf *string, older bool) bool {
+	r := mh.recall
+	if r == nil || r.kind != kind {
+		entries := mh.History(kind)
+		r = &historyRecall{kind: kind, entries: entries, prefix: *buf, idx: len(entries)}
+		mh.recall = r
+	}
+	step := 1
+	if older {
+		step = -1
+	}
+	for i := r.idx + step; i >= 0 && i <= len(r.entries); i += step {
+		if i == len(r.entries) {
+			r.idx = i
+			*buf = r.prefix
+			return true
+		}
+		if strings.HasPrefix(r.entries[i], r.prefix) && r.entries[i] != *buf {
+			r.idx = i
+			*buf = r.entries[i]
+			return true
+		}
+	}
+	return false
+}
+
+// resetRecall forgets the history position, after the prompt line is
+// edited or left.
+func (mh *ModeHandler) resetRecall() {
+	mh.recall = nil
+}
@@ -0,0 +1,86 @@
+package modehandler
+
+import (
+	"time"
+
+	"github.com/bethropolis/tide/internal/config"
+	"github.com/bethropolis/tide/internal/core/find"
+	"github.com/bethropolis/tide/internal/types"
+)
+
+// IncSearchDelay is how long typing in find mode pauses before the pattern
+// typed so far is highlighted and its nearest match shown, as with Vim's
+// incsearch.
+const IncSearchDelay = 80 * time.Millisecond
+
+// findOrigin is where the cursor and view were when find mode started, so
+// the search can start from there and cancelling it can go back.
+type findOrigin struct {
+	cursor    types.Position
+	top, left int
+}
+
+// beginIncSearch remembers where find mode starts.
+func (mh *ModeHandler) beginIncSearch() {
+	top, left := mh.editor.GetViewport()
+	mh.findOrigin = findOrigin{cursor: mh.editor.GetCursor(), top: top, left: left}
+}
+
+// restoreFindOrigin puts the cursor and view back where find mode started.
+func (mh *ModeHandler) restoreFindOrigin() {
+	mh.editor.SetCursor(mh.findOrigin.cursor)
+	mh.editor.SetViewport(mh.findOrigin.top, mh.findOrigin.left)
+}
+
+// scheduleIncSearch searches for the find buffer once typing pauses, so a
+// long pattern typed quickly searches the buffer once rather than per key.
+// The search runs on the event loop, and is dropped if the pattern changed
+// or find mode ended while it waited.
+func (mh *ModeHandler) scheduleIncSearch() {
+	mh.stopIncSearch()
+	if mh.api == nil {
+		mh.incSearch()
+		return
+	}
+	gen := mh.incSearchGen
+	mh.incSearchTimer = time.AfterFunc(IncSearchDelay, func() {
+		mh.api.Schedule(func() {
+			if gen == mh.incSearchGen && mh.currentMode == ModeFind {
+				mh.incSearch()
+			}
+		})
+	})
+}
+
+// stopIncSearch drops a pending incremental search.
+func (mh *ModeHandler) stopIncSearch() {
+	if mh.incSearchTimer != nil {
+		mh.incSearchTimer.Stop()
+		mh.incSearchTimer = nil
+	}
+	mh.incSearchGen++
+}
+
+// incSearch highlights the matches of the find buffer and moves to the
+// first one after where the search started. A pattern that does not
+// compile yet, such as "foo(" on the way to "foo(x)", shows nothing.
+func (mh *ModeHandler) incSearch() {
+	mh.restoreFindOrigin()
+	mh.editor.MarkAllDirty()
+	pattern, _, err := find.ParseSearch(mh.findBuffer)
+	if err != nil || pattern == "" {
+		mh.editor.ClearHighlights()
+		return
+	}
+	if err := mh.editor.HighlightMatches(pattern); err != nil {
+		return
+	}
+	findManager := mh.editor.GetFindManager()
+	if findManager == nil {
+		return
+	}
+	if pos, found, _ := findManager.FindNext(true, config.Get().Editor.WrapScan); found {
+		mh.editor.SetCursor(pos)
+		mh.editor.ScrollToCursor()
+	}
+}
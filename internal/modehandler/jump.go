@@ -0,0 +1,17 @@
+package modehandler
+
+// jump goes back (Ctrl+O) or forward (Ctrl+I) through the jump list, count
+// jumps at a time, to another file when a jump was made from there.
+func (mh *ModeHandler) jump(forward bool) bool {
+	count := mh.drainCount()
+	if mh.api == nil {
+		return false
+	}
+	for i := 0; i < count; i++ {
+		if err := mh.api.Jump(forward); err != nil {
+			mh.statusBar.SetTemporaryMessage("%v", err)
+			break
+		}
+	}
+	return true
+}
@@ -0,0 +1,290 @@
+package modehandler
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bethropolis/tide/internal/input"
+	"github.com/gdamore/tcell/v2"
+)
+
+// MapTimeout is how long a key that starts a longer mapping waits for the
+// rest of it before it is taken on its own.
+const MapTimeout = time.Second
+
+// maxMapDepth bounds how deep recursive mappings expand, so mappings that
+// lead back to themselves end with an error instead of hanging the editor.
+// Reaching it drops the whole expansion (see mapAborted), not only the
+// innermost mapping, as mappings of several keys would otherwise still
+// expand exponentially.
+const maxMapDepth = 100
+
+// KeyMapping makes a key sequence typed in one mode stand for another,
+// like Vim's :map. Mappings come on top of the keybindings: the keys of
+// the right-hand side are handled as if typed.
+type KeyMapping struct {
+	Mode    rune // 'n' normal, 'v' visual, 'o' operator-pending, 'i' insert or 'c' command line and find
+	LHS     []input.KeyStroke
+	RHS     []input.KeyStroke
+	NoRemap bool   // The keys of RHS are not mapped again
+	Source  string // Where the mapping was made, for :verbose map
+}
+
+// mapModeLetters are the modes of mappings, in listing order.
+const mapModeLetters = "nvoic"
+
+// mapModes returns the mode letters a :map variant covers: "" (:map) is
+// normal, visual and operator-pending mode, "!" (:map!) insert and command
+// line, and otherwise each letter names one mode.
+func mapModes(modes string) (string, error) {
+	switch modes {
+	case "":
+		return "nvo", nil
+	case "!":
+		return "ic", nil
+	}
+	for _, m := range modes {
+		if !strings.ContainsRune(mapModeLetters, m) {
+			return "", fmt.Errorf("unknown mode %q; use n, v, o, i or c", m)
+		}
+	}
+	return modes, nil
+}
+
+// mapMode returns the mode letter of the mappings that apply now.
+func (mh *ModeHandler) mapMode() rune {
+	if mh.operator != nil {
+		return 'o'
+	}
+	switch mh.currentMode {
+	case ModeInsert:
+		return 'i'
+	case ModeVisual, ModeVisualLine, ModeVisualBlock:
+		return 'v'
+	case ModeCommand, ModeFind:
+		return 'c'
+	case ModeSubstitute:
+		return 0 // y/n/a/q/l answer the prompt as typed
+	}
+	return 'n'
+}
+
+// Map makes lhs stand for rhs in modes (see mapModes), replacing any
+// mapping of lhs there. Both are written as in :map ("jk", "<leader>w",
+// ":w<CR>"). With noremap the keys of rhs are not mapped again.
+func (mh *ModeHandler) Map(modes, lhs, rhs string, noremap bool, source string) error {
+	modes, err := mapModes(modes)
+	if err != nil {
+		return err
+	}
+	lhsKeys, err := input.ParseKeySequence(lhs, mh.leaderKey)
+	if err != nil {
+		return fmt.Errorf("left-hand side: %w", err)
+	}
+	rhsKeys, err := input.ParseKeySequence(rhs, mh.leaderKey)
+	if err != nil {
+		return fmt.Errorf("right-hand side: %w", err)
+	}
+
+	if mh.mappings == nil {
+		mh.mappings = make(map[rune]map[string]*KeyMapping)
+	}
+	key := input.FormatKeySequence(lhsKeys)
+	for _, m := range modes {
+		if mh.mappings[m] == nil {
+			mh.mappings[m] = make(map[string]*KeyMapping)
+		}
+		mh.mappings[m][key] = &KeyMapping{Mode: m, LHS: lhsKeys, RHS: rhsKeys, NoRemap: noremap, Source: source}
+	}
+	return nil
+}
+
+// Unmap removes the mappings of lhs in modes. It fails when there is none.
+func (mh *ModeHandler) Unmap(modes, lhs string) error {
+	modes, err := mapModes(modes)
+	if err != nil {
+		return err
+	}
+	lhsKeys, err := input.ParseKeySequence(lhs, mh.leaderKey)
+	if err != nil {
+		return err
+	}
+	key := input.FormatKeySequence(lhsKeys)
+	removed := false
+	for _, m := range modes {
+		if _, ok := mh.mappings[m][key]; ok {
+			delete(mh.mappings[m], key)
+			removed = true
+		}
+	}
+	if !removed {
+		return fmt.Errorf("no such mapping: %s", lhs)
+	}
+	return nil
+}
+
+// Mappings returns the mappings in modes whose left-hand side starts with
+// the keys of prefix (all of them when prefix is empty), by mode and then
+// by left-hand side.
+func (mh *ModeHandler) Mappings(modes, prefix string) ([]KeyMapping, error) {
+	modes, err := mapModes(modes)
+	if err != nil {
+		return nil, err
+	}
+	var prefixKeys []input.KeyStroke
+	if prefix != "" {
+		if prefixKeys, err = input.ParseKeySequence(prefix, mh.leaderKey); err != nil {
+			return nil, err
+		}
+	}
+
+	var list []KeyMapping
+	for _, m := range mapModeLetters {
+		if !strings.ContainsRune(modes, m) {
+			continue
+		}
+		for _, mapping := range mh.mappings[m] {
+			if hasKeyPrefix(mapping.LHS, prefixKeys) {
+				list = append(list, *mapping)
+			}
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].Mode != list[j].Mode {
+			return strings.IndexRune(mapModeLetters, list[i].Mode) < strings.IndexRune(mapModeLetters, list[j].Mode)
+		}
+		return input.FormatKeySequence(list[i].LHS) < input.FormatKeySequence(list[j].LHS)
+	})
+	return list, nil
+}
+
+// hasKeyPrefix reports whether keys starts with prefix.
+func hasKeyPrefix(keys, prefix []input.KeyStroke) bool {
+	if len(prefix) > len(keys) {
+		return false
+	}
+	for i, ks := range prefix {
+		if keys[i] != ks {
+			return false
+		}
+	}
+	return true
+}
+
+// feedKey handles a typed key, or a key of a recursive mapping, through
+// the mappings of the current mode. A key that could start a longer
+// mapping is held until the mapping is complete, a key rules it out, or
+// MapTimeout passes. depth counts the mappings the key came through.
+func (mh *ModeHandler) feedKey(ev *tcell.EventKey, depth int) bool {
+	if depth > 0 && mh.mapAborted {
+		return false
+	}
+	if len(mh.pendingKeys) == 0 && len(mh.mappings[mh.mapMode()]) == 0 {
+		return mh.handleKey(ev)
+	}
+	mh.stopMapTimer()
+	keys := append(mh.pendingKeys, ev)
+	mh.pendingKeys = nil
+	return mh.resolveKeys(keys, depth, false)
+}
+
+// resolveKeys runs the mapping keys spell out, holds them if they could
+// still grow into a longer one (unless final), or else handles the first
+// key unmapped and looks the others up again.
+func (mh *ModeHandler) resolveKeys(keys []*tcell.EventKey, depth int, final bool) bool {
+	strokes := make([]input.KeyStroke, len(keys))
+	for i, ev := range keys {
+		strokes[i] = input.KeyStrokeOf(ev)
+	}
+
+	var exact *KeyMapping
+	longer := false
+	for _, m := range mh.mappings[mh.mapMode()] {
+		if !hasKeyPrefix(m.LHS, strokes) {
+			continue
+		}
+		if len(m.LHS) == len(strokes) {
+			exact = m
+		} else {
+			longer = true
+		}
+	}
+
+	switch {
+	case longer && !final:
+		mh.pendingKeys = keys
+		mh.startMapTimer()
+		return false
+	case exact != nil:
+		return mh.runMapping(exact, depth)
+	}
+
+	redraw := mh.handleKey(keys[0])
+	for _, ev := range keys[1:] {
+		if depth > 0 && mh.mapAborted {
+			break
+		}
+		redraw = mh.feedKey(ev, depth) || redraw
+	}
+	return redraw
+}
+
+// runMapping handles the keys of m's right-hand side as if typed. As in
+// Vim, when the right-hand side starts with the left-hand side its first
+// key is not mapped again, so "nmap x xh" does not loop.
+func (mh *ModeHandler) runMapping(m *KeyMapping, depth int) bool {
+	if depth == 0 {
+		mh.mapAborted = false
+	}
+	if depth >= maxMapDepth {
+		mh.pendingKeys = nil
+		mh.mapAborted = true
+		mh.statusBar.SetErrorMessage("Recursive mapping: %s", input.FormatKeySequence(m.LHS))
+		return true
+	}
+	selfFirst := hasKeyPrefix(m.RHS, m.LHS[:1])
+	redraw := false
+	for i, ks := range m.RHS {
+		if mh.mapAborted {
+			break
+		}
+		if m.NoRemap || (i == 0 && selfFirst) {
+			redraw = mh.handleKey(ks.Event()) || redraw
+		} else {
+			redraw = mh.feedKey(ks.Event(), depth+1) || redraw
+		}
+	}
+	return redraw
+}
+
+// startMapTimer takes the held keys as they are once MapTimeout passes.
+// The timer only schedules the work on the event loop; a generation count
+// makes a timer that fires after more keys arrived do nothing.
+func (mh *ModeHandler) startMapTimer() {
+	if mh.api == nil {
+		return
+	}
+	mh.mapTimerGen++
+	gen := mh.mapTimerGen
+	mh.mapTimer = time.AfterFunc(MapTimeout, func() {
+		mh.api.Schedule(func() {
+			if gen != mh.mapTimerGen || len(mh.pendingKeys) == 0 {
+				return
+			}
+			keys := mh.pendingKeys
+			mh.pendingKeys = nil
+			mh.resolveKeys(keys, 0, true)
+		})
+	})
+}
+
+// stopMapTimer stops the timer of the held keys.
+func (mh *ModeHandler) stopMapTimer() {
+	if mh.mapTimer != nil {
+		mh.mapTimer.Stop()
+		mh.mapTimer = nil
+	}
+	mh.mapTimerGen++
+}
@@ -0,0 +1,79 @@
+package modehandler
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bethropolis/tide/internal/buffer"
+	"github.com/bethropolis/tide/internal/config"
+	"github.com/bethropolis/tide/internal/core"
+	"github.com/bethropolis/tide/internal/event"
+	"github.com/bethropolis/tide/internal/input"
+	"github.com/bethropolis/tide/internal/logger"
+	"github.com/bethropolis/tide/internal/paths"
+	"github.com/bethropolis/tide/internal/statusbar"
+	"github.com/gdamore/tcell/v2"
+)
+
+func newTestHandler(t *testing.T) *ModeHandler {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv(paths.HomeEnv, home)
+	logger.Init(logger.Config{LogLevel: "error", LogFilePath: "-"})
+	config.LoadConfig(filepath.Join(home, config.DefaultConfigFileName), nil) // Defaults; the file does not exist
+	buf := buffer.NewSliceBuffer()
+	if err := buf.Load(""); err != nil {
+		t.Fatal(err)
+	}
+	events := event.NewManager()
+	return New(Config{
+		Editor:         core.NewEditor(buf, nil, events),
+		InputProcessor: input.NewInputProcessor(),
+		EventManager:   events,
+		StatusBar:      statusbar.New(statusbar.Config{}),
+		QuitSignal:     make(chan struct{}, 1),
+	})
+}
+
+func TestRecursiveMapping(t *testing.T) {
+	tests := []struct {
+		name     string
+		mappings [][2]string
+	}{
+		{"self", [][2]string{{"q", "wq"}}},
+		{"mutual", [][2]string{{"q", "ww"}, {"w", "qq"}}},
+		{"mutual three keys", [][2]string{{"q", "www"}, {"w", "qqq"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mh := newTestHandler(t)
+			for _, m := range tt.mappings {
+				if err := mh.Map("n", m[0], m[1], false, ""); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			done := make(chan struct{})
+			go func() {
+				mh.HandleKeyEvent(tcell.NewEventKey(tcell.KeyRune, 'q', tcell.ModNone))
+				close(done)
+			}()
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatal("recursive mapping did not stop")
+			}
+			if msg, isErr := mh.statusBar.Message(); !isErr || msg == "" {
+				t.Errorf("status = %q (error %v), want the recursive mapping error", msg, isErr)
+			}
+
+			// The next key is mapped afresh
+			mh.Map("n", "x", "l", false, "")
+			mh.HandleKeyEvent(tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone))
+			if mh.mapAborted {
+				t.Error("mapAborted still set after a mapping that ended normally")
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package modehandler
+
+// awaitMark waits for the name of a mark to set (m) or go to (' and `),
+// typed next.
+func (mh *ModeHandler) awaitMark(op rune) bool {
+	if mh.currentMode != ModeNormal {
+		return false
+	}
+	mh.pendingOperator = op
+	mh.statusBar.SetTemporaryMessage(string(op) + " (pending)")
+	return true
+}
+
+// markKey sets mark name on the cursor (ma) or goes to it ('a and `a).
+func (mh *ModeHandler) markKey(op, name rune) bool {
+	if mh.api == nil {
+		return false
+	}
+	var err error
+	if op == 'm' {
+		err = mh.api.SetMark(name)
+	} else {
+		err = mh.api.JumpToMark(name)
+	}
+	if err != nil {
+		mh.statusBar.SetTemporaryMessage("%v", err)
+	}
+	return true
+}
@@ -3,14 +3,18 @@ package modehandler
 
 import (
 	"fmt"
+	"sort"
 	"time"
 
-	"github.com/bethropolis/tide/internal/config"
+	"github.com/bethropolis/tide/internal/cmdhistory"
 	"github.com/bethropolis/tide/internal/core"
+	"github.com/bethropolis/tide/internal/core/find"
 	"github.com/bethropolis/tide/internal/event"
+	"github.com/bethropolis/tide/internal/i18n"
 	"github.com/bethropolis/tide/internal/input"
 	"github.com/bethropolis/tide/internal/logger"
 	"github.com/bethropolis/tide/internal/plugin"
+	"github.com/bethropolis/tide/internal/script"
 	"github.com/bethropolis/tide/internal/statusbar"
 	"github.com/bethropolis/tide/internal/types"
 	"github.com/gdamore/tcell/v2"
@@ -27,6 +31,7 @@ const (
 	ModeVisual
 	ModeVisualLine  // Line-wise visual mode (Vim 'V')
 	ModeVisualBlock // Block-wise visual mode (Vim Ctrl+V)
+	ModeSubstitute  // Asking y/n/a/q/l about each match of :s with the c flag
 )
 
 // ModeHandler manages input modes, command execution, and related state.
@@ -43,13 +48,27 @@ type ModeHandler struct {
 	cmdBuffer        string
 	findBuffer       string
 	commands         map[string]plugin.CommandFunc
+	argCompleters    map[string]func() []string // Tab candidates for a command's first argument
 	forceQuitPending bool
 
 	// Find State
 	lastSearchTerm    string
 	lastSearchForward bool
+	lastSearchOffset  find.Offset // Where the cursor lands on a match ("/foo/e")
 	lastMatchPos      *types.Position
 
+	// Incremental search while typing in find mode
+	findOrigin     findOrigin
+	incSearchTimer *time.Timer
+	incSearchGen   int // Bumped when the find buffer changes; see scheduleIncSearch
+
+	// :s///c waiting for an answer about the current match
+	substitute *substituteConfirm
+
+	// Command, search and expression histories, and Up/Down recall of them
+	histories map[string]*cmdhistory.List
+	recall    *historyRecall // Non-nil while stepping through a history
+
 	// Command Autocomplete State
 	cmdSuggestions   []string
 	cmdSuggestionIdx int
@@ -60,8 +79,18 @@ type ModeHandler struct {
 	leaderTimer   *time.Timer
 	leaderKey     rune
 
+	// Runtime key mappings (:map) by mode letter and left-hand side
+	mappings    map[rune]map[string]*KeyMapping
+	pendingKeys []*tcell.EventKey // Typed keys that may start a longer mapping
+	mapTimer    *time.Timer
+	mapTimerGen int  // Bumped when the held keys change; see startMapTimer
+	mapAborted  bool // A mapping recursed too deep; the rest of its expansion is dropped
+
 	// Multi-key operator state
 	pendingOperator rune
+	operator        *operatorPending // An operator waiting for its motion (see operators)
+	windowPending   bool             // Ctrl+W typed; the next key is a window command
+	blockInsert     *blockInsert     // Insert started on a visual block, copied to its lines on Esc
 
 	// Count prefix state (e.g., 3j, 5dd)
 	countAccumulator int
@@ -69,6 +98,8 @@ type ModeHandler struct {
 	// Dot-repeat state
 	lastInsertActions []input.ActionEvent // recorded insert-mode actions for . repeat
 	recordingInsert  bool                // true while in insert mode, recording actions
+	repeatFn         func() error        // Runs the last change when it was not an insert (SetRepeat)
+	repeatName       string
 
 	// Mouse drag state
 	mouseDragging  bool
@@ -77,8 +108,16 @@ type ModeHandler struct {
 	// Insert-mode edit callback (for completion overlay)
 	onInsertEdit func()
 
+	// Ctrl+N/Ctrl+P buffer-word completion
+	completionWords func(prefix string) []string
+	wordCompletion  *wordCompletion // Non-nil while cycling candidates
+
 	// Editor API (for range substitution commands)
 	api plugin.EditorAPI
+
+	// :record and :source
+	recorder  *script.Recorder // Non-nil while recording a script
+	replaying int              // Script steps being replayed, nested ones included
 }
 
 // Config holds dependencies for the ModeHandler.
@@ -91,6 +130,9 @@ type Config struct {
 	// OnInsertEdit is called after every insert-mode edit so the app can
 	// rebuild the completion overlay.
 	OnInsertEdit func()
+	// CompletionWords returns buffer words starting with prefix for
+	// Ctrl+N/Ctrl+P completion, nearest first.
+	CompletionWords func(prefix string) []string
 }
 
 // New creates a new ModeHandler.
@@ -117,28 +159,51 @@ func New(cfg Config) *ModeHandler {
 		quitSignal:        cfg.QuitSignal,
 		currentMode:       ModeNormal,
 		commands:          make(map[string]plugin.CommandFunc),
+		argCompleters:     make(map[string]func() []string),
 		cmdBuffer:         "",
 		cmdSuggestionIdx:  -1,
 		lastSearchForward: true,
 		onInsertEdit:      cfg.OnInsertEdit,
+		completionWords:   cfg.CompletionWords,
+		histories:         loadHistories(),
 	}
 	mh.leaderKey = cfg.InputProcessor.GetLeaderKey() // Cache leader key
 	return mh
 }
 
+// mouseScrollLines is how far one step of the mouse wheel scrolls.
+const mouseScrollLines = 3
+
 // HandleMouseEvent processes mouse input events.
 func (mh *ModeHandler) HandleMouseEvent(ev *tcell.EventMouse) bool {
 	// Allow scroll wheel in any mode
 	x, y := ev.Position()
 	button := ev.Buttons()
 
-	// Handle Scrolling (works in all modes)
+	// Handle Scrolling (works in all modes). The view scrolls; the cursor
+	// stays put unless it would leave the screen.
 	if button&tcell.WheelUp != 0 {
-		mh.editor.MoveCursor(-3, 0) // Scroll up 3 lines
+		mh.editor.ScrollLines(-mouseScrollLines)
 		return true
 	}
 	if button&tcell.WheelDown != 0 {
-		mh.editor.MoveCursor(3, 0) // Scroll down 3 lines
+		mh.editor.ScrollLines(mouseScrollLines)
+		return true
+	}
+
+	// Middle click pastes the primary selection at the clicked position
+	if button&tcell.Button3 != 0 {
+		if mh.currentMode != ModeNormal && mh.currentMode != ModeVisual && mh.currentMode != ModeInsert {
+			return false
+		}
+		targetPos, _ := mh.mouseTarget(x, y)
+		mh.editor.ClearSelection()
+		if mh.currentMode == ModeVisual {
+			mh.currentMode = ModeNormal
+			mh.statusBar.SetTemporaryMessage("")
+		}
+		mh.editor.SetCursor(targetPos)
+		mh.pastePrimary()
 		return true
 	}
 
@@ -149,28 +214,12 @@ func (mh *ModeHandler) HandleMouseEvent(ev *tcell.EventMouse) bool {
 
 	// Handle Clicking (Button1 is left click)
 	if button&tcell.Button1 != 0 {
-		viewportY, viewportX := mh.editor.GetViewport()
-
-		targetLine := viewportY + y
-
-		buf := mh.editor.GetBuffer()
-		lineCount := buf.LineCount()
-
-		// Calculate gutter width using shared helper (use large screen width to avoid overflow-to-0)
-		gutterWidth := config.GutterWidth(lineCount, 1<<20)
-
-		// Clamp targetLine to valid range
-		if targetLine < 0 {
-			targetLine = 0
-		}
-		if targetLine >= lineCount {
-			targetLine = lineCount - 1
-		}
+		targetPos, inGutter := mh.mouseTarget(x, y)
 
 		// If click is in the gutter, ignore or select line
-		if x < gutterWidth {
+		if inGutter {
 			// Clicked on line number, could select line
-			mh.editor.SetCursor(types.Position{Line: targetLine, Col: 0})
+			mh.editor.SetCursor(types.Position{Line: targetPos.Line, Col: 0})
 			mh.editor.ClearSelection()
 			if mh.currentMode == ModeVisual {
 				mh.currentMode = ModeNormal
@@ -179,18 +228,13 @@ func (mh *ModeHandler) HandleMouseEvent(ev *tcell.EventMouse) bool {
 			return true
 		}
 
-		// Calculate visual column clicked
-		visualCol := x - gutterWidth + viewportX
-		if visualCol < 0 {
-			visualCol = 0
-		}
-
-		// Translate visual column to actual byte column based on runes/tabs
-		targetCol := mh.editor.GetBufferCol(targetLine, visualCol)
-		targetPos := types.Position{Line: targetLine, Col: targetCol}
-
 		if mh.mouseDragging {
 			// --- Drag: extend selection to new cursor position ---
+			if _, _, ok := mh.editor.GetSelection(); !ok {
+				// Anchor the selection where the button went down
+				mh.editor.SetCursor(mh.mouseDragStart)
+				mh.editor.StartOrUpdateSelection()
+			}
 			mh.editor.SetCursor(targetPos)
 			mh.editor.StartOrUpdateSelection()
 			return true
@@ -214,7 +258,8 @@ func (mh *ModeHandler) HandleMouseEvent(ev *tcell.EventMouse) bool {
 		// If selection is active after drag, enter Visual mode
 		if _, _, ok := mh.editor.GetSelection(); ok {
 			mh.currentMode = ModeVisual
-			mh.statusBar.SetTemporaryMessage("-- VISUAL --")
+			mh.statusBar.SetTemporaryMessage(modeMessage("visual"))
+			mh.editor.CopySelectionToPrimary()
 		}
 		return true
 	}
@@ -222,13 +267,67 @@ func (mh *ModeHandler) HandleMouseEvent(ev *tcell.EventMouse) bool {
 	return false
 }
 
+// mouseTarget converts a screen cell to a buffer position, clamped to the
+// buffer. inGutter reports whether the cell is in the line-number gutter.
+func (mh *ModeHandler) mouseTarget(x, y int) (pos types.Position, inGutter bool) {
+	viewportY, viewportX := mh.editor.GetViewport()
+
+	targetLine := viewportY + y
+
+	buf := mh.editor.GetBuffer()
+	lineCount := buf.LineCount()
+
+	// Calculate gutter width using shared helper (use large screen width to avoid overflow-to-0)
+	gutterWidth := mh.editor.GutterWidth(1 << 20)
+
+	// Clamp targetLine to valid range
+	if targetLine < 0 {
+		targetLine = 0
+	}
+	if targetLine >= lineCount {
+		targetLine = lineCount - 1
+	}
+	if x < gutterWidth {
+		return types.Position{Line: targetLine, Col: 0}, true
+	}
+
+	// Calculate visual column clicked
+	visualCol := x - gutterWidth + viewportX
+	if visualCol < 0 {
+		visualCol = 0
+	}
+
+	// Translate visual column to actual byte column based on runes/tabs
+	targetCol := mh.editor.GetBufferCol(targetLine, visualCol)
+	return types.Position{Line: targetLine, Col: targetCol}, false
+}
+
+// pastePrimary pastes the primary selection at the cursor and reports the
+// result in the status bar.
+func (mh *ModeHandler) pastePrimary() {
+	pasted, err := mh.editor.PastePrimary()
+	if err != nil {
+		mh.statusBar.SetTemporaryMessage("Paste failed: %v", err)
+		logger.Debugf("Primary paste error: %v", err)
+	} else if !pasted {
+		mh.statusBar.SetTemporaryMessage("Primary selection empty - nothing to paste")
+	}
+}
+
 // HandleKeyEvent decides what to do based on current mode and key event.
 // Returns true if the event resulted in an action requiring redraw.
 func (mh *ModeHandler) HandleKeyEvent(ev *tcell.EventKey) bool {
 	// Dispatch raw key event first
 	mh.eventManager.Dispatch(event.TypeKeyPressed, event.KeyPressedData{KeyEvent: ev})
+	return mh.feedKey(ev, 0)
+}
 
+// handleKey handles a key event after key mappings have been applied.
+func (mh *ModeHandler) handleKey(ev *tcell.EventKey) bool {
 	actionEvent := mh.inputProcessor.ProcessEvent(ev) // Get base action
+	if mh.recorder != nil {
+		defer mh.recordKey(actionEvent, ev, mh.currentMode)
+	}
 
 	var actionProcessed bool
 	needsRedraw := false
@@ -292,6 +391,8 @@ func (mh *ModeHandler) HandleKeyEvent(ev *tcell.EventKey) bool {
 		actionProcessed = mh.handleActionCommand(actionEvent)
 	case ModeFind:
 		actionProcessed = mh.handleActionFind(actionEvent)
+	case ModeSubstitute:
+		actionProcessed = mh.handleActionSubstitute(actionEvent)
 	default:
 		logger.Debugf("Warning: Unknown input mode: %v", mh.currentMode)
 		actionProcessed = false
@@ -314,11 +415,52 @@ func (mh *ModeHandler) RegisterCommand(name string, cmdFunc plugin.CommandFunc)
 	return nil
 }
 
+// SetCommandCompletion registers a source of Tab-completion candidates for
+// the first argument of the named command.
+func (mh *ModeHandler) SetCommandCompletion(name string, candidates func() []string) {
+	mh.argCompleters[name] = candidates
+}
+
+// CommandNames returns the names of all registered commands, sorted.
+func (mh *ModeHandler) CommandNames() []string {
+	names := make([]string, 0, len(mh.commands))
+	for name := range mh.commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RunCommand executes a command line (without the leading ':') exactly as if
+// it had been typed in command mode. Used by the command palette.
+func (mh *ModeHandler) RunCommand(cmdLine string) {
+	mh.currentMode = ModeNormal
+	mh.cmdBuffer = cmdLine
+	mh.executeCommand()
+}
+
+// RunAction executes an action as if its key had been pressed in normal mode.
+// Returns true if a redraw is needed.
+func (mh *ModeHandler) RunAction(action input.Action) bool {
+	return mh.executeAction(action, input.ActionEvent{Action: action, Count: 1}, nil)
+}
+
+// GetInputProcessor returns the processor used to resolve key bindings.
+func (mh *ModeHandler) GetInputProcessor() *input.InputProcessor {
+	return mh.inputProcessor
+}
+
 // GetCurrentMode returns the current input mode.
 func (mh *ModeHandler) GetCurrentMode() InputMode {
 	return mh.currentMode
 }
 
+// modeMessage returns the "-- INSERT --" style message announcing a switch
+// to the mode with the given catalog key.
+func modeMessage(key string) string {
+	return i18n.T("status.mode", i18n.T("mode."+key))
+}
+
 // GetCurrentModeString returns the current input mode as a user-friendly string.
 func (mh *ModeHandler) GetCurrentModeString() string {
 	switch mh.currentMode {
@@ -336,6 +478,8 @@ func (mh *ModeHandler) GetCurrentModeString() string {
 		return "COMMAND"
 	case ModeFind:
 		return "FIND"
+	case ModeSubstitute:
+		return "SUBSTITUTE"
 	// Add other modes later
 	default:
 		return "UNKNOWN"
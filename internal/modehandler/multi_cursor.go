@@ -0,0 +1,69 @@
+package modehandler
+
+import (
+	"github.com/bethropolis/tide/internal/i18n"
+	"github.com/bethropolis/tide/internal/input"
+)
+
+// selectNextOccurrence selects the word under the cursor and enters Visual
+// mode or, in Visual mode, adds the next occurrence of the selected text
+// with a cursor of its own (Ctrl+D).
+func (mh *ModeHandler) selectNextOccurrence() bool {
+	if mh.currentMode != ModeNormal && mh.currentMode != ModeVisual {
+		return false
+	}
+	if !mh.editor.SelectNextOccurrence() {
+		if mh.currentMode == ModeNormal {
+			mh.statusBar.SetTemporaryMessage("No identifier under cursor")
+		} else {
+			mh.statusBar.SetTemporaryMessage("No other occurrence")
+		}
+		return true
+	}
+	if mh.currentMode == ModeNormal {
+		mh.currentMode = ModeVisual
+		mh.statusBar.SetTemporaryMessage(modeMessage("visual"))
+		return true
+	}
+	mh.statusBar.SetTemporaryMessage("%d selections", len(mh.editor.ExtraSelections())+1)
+	return true
+}
+
+// insertAtSelections enters Insert mode with a cursor on each selection:
+// in place of its text with change (c, s), or before (I) or after (A) it
+// otherwise.
+func (mh *ModeHandler) insertAtSelections(change, after bool) bool {
+	if mh.editor.GetBuffer().ReadOnly() {
+		mh.statusBar.SetTemporaryMessage(i18n.T("status.read_only"))
+		return true
+	}
+	if change {
+		if ok, err := mh.editor.DeleteSelections(); err != nil {
+			mh.statusBar.SetTemporaryMessage("Change failed: %v", err)
+			return true
+		} else if !ok {
+			if _, err := mh.editor.CutSelection(); err != nil {
+				mh.statusBar.SetTemporaryMessage("Cut failed: %v", err)
+				return true
+			}
+		}
+	} else if !mh.editor.CursorsToSelections(after) {
+		return false
+	}
+	mh.editor.ClearSelection()
+	mh.blockInsert = nil
+	mh.currentMode = ModeInsert
+	mh.statusBar.SetTemporaryMessage(modeMessage("insert"))
+	return true
+}
+
+// typesAtCursors reports whether action, in Insert mode, is made at every
+// cursor; any other action leaves only the main one.
+func typesAtCursors(action input.Action) bool {
+	switch action {
+	case input.ActionInsertRune, input.ActionInsertNewLine, input.ActionInsertTab,
+		input.ActionDeleteCharBackward, input.ActionDeleteCharForward:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,322 @@
+package modehandler
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bethropolis/tide/internal/core/textobj"
+	"github.com/bethropolis/tide/internal/i18n"
+	"github.com/bethropolis/tide/internal/input"
+	"github.com/bethropolis/tide/internal/types"
+	"github.com/gdamore/tcell/v2"
+)
+
+// operators are the normal mode keys that wait for a motion or text object
+// naming the text they act on: delete, yank, change, indent and dedent.
+// Any operator works with any motion, so "d3w", "yi(", "c$" and ">j" need
+// no bindings of their own, and doubling an operator ("dd", ">>") acts on
+// whole lines.
+const operators = "dyc><"
+
+// operatorPending is an operator waiting for its motion.
+type operatorPending struct {
+	op          rune
+	count       int  // Typed before the operator; 0 when none
+	motionCount int  // Typed after it, as in "d3w"
+	prefix      rune // 'i' or 'a' (text objects) or 'g' (gg) once typed
+}
+
+// keys returns what has been typed of the command, for the status bar.
+func (p *operatorPending) keys() string {
+	var b strings.Builder
+	if p.count > 0 {
+		b.WriteString(strconv.Itoa(p.count))
+	}
+	b.WriteRune(p.op)
+	if p.motionCount > 0 {
+		b.WriteString(strconv.Itoa(p.motionCount))
+	}
+	if p.prefix != 0 {
+		b.WriteRune(p.prefix)
+	}
+	return b.String()
+}
+
+// motionKeys are the motions for keys with their own actions.
+var motionKeys = map[input.Action]rune{
+	input.ActionMoveLeft:  'h',
+	input.ActionMoveDown:  'j',
+	input.ActionMoveUp:    'k',
+	input.ActionMoveRight: 'l',
+	input.ActionMoveHome:  '^',
+	input.ActionMoveEnd:   '$',
+}
+
+// startOperator makes op wait for its motion.
+func (mh *ModeHandler) startOperator(op rune) bool {
+	mh.operator = &operatorPending{op: op, count: mh.countAccumulator}
+	mh.countAccumulator = 0
+	mh.statusBar.SetTemporaryMessage("%s (pending)", mh.operator.keys())
+	return true
+}
+
+// handleOperatorKey takes a key typed while an operator waits: a count, a
+// motion, the first or second key of a text object, or anything else,
+// which cancels the operator.
+func (mh *ModeHandler) handleOperatorKey(actionEvent input.ActionEvent, ev *tcell.EventKey) bool {
+	p := mh.operator
+	r, isRune := actionEvent.Rune, actionEvent.Action == input.ActionInsertRune
+	if m, ok := motionKeys[actionEvent.Action]; ok && p.prefix == 0 {
+		r, isRune = m, true
+	}
+	if !isRune {
+		// Escape and any other command key cancel the operator
+		mh.operator = nil
+		mh.statusBar.SetTemporaryMessage("Operator cancelled")
+		return true
+	}
+
+	count := max(p.count, 1) * max(p.motionCount, 1)
+	counted := p.count > 0 || p.motionCount > 0
+	var (
+		rng textobj.Range
+		ok  bool
+	)
+	switch {
+	case p.prefix == 'i' || p.prefix == 'a':
+		rng, ok = mh.textObjectRange(r, p.prefix == 'a')
+	case p.prefix == 'g':
+		if r == 'g' {
+			target := 0
+			if counted {
+				target = count - 1
+			}
+			rng, ok = mh.lineRange(mh.editor.GetCursor().Line, target), true
+		}
+	case r >= '1' && r <= '9' || (r == '0' && p.motionCount > 0):
+		p.motionCount = p.motionCount*10 + int(r-'0')
+		mh.statusBar.SetTemporaryMessage("%s (pending)", p.keys())
+		return true
+	case r == 'i' || r == 'a' || r == 'g':
+		p.prefix = r
+		mh.statusBar.SetTemporaryMessage("%s (pending)", p.keys())
+		return true
+	case r == p.op:
+		line := mh.editor.GetCursor().Line
+		rng, ok = mh.lineRange(line, line+count-1), true
+	default:
+		rng, ok = mh.motionRange(r, count, counted, p.op)
+	}
+
+	mh.operator = nil
+	mh.statusBar.ResetTemporaryMessage()
+	if !ok {
+		mh.statusBar.SetTemporaryMessage("Operator cancelled")
+		return true
+	}
+	if p.op != 'y' && p.op != 'c' {
+		// "." measures the motion again from the cursor of that time
+		typed := *p
+		mh.SetRepeat(typed.keys()+string(r), func() error {
+			op := typed
+			mh.operator = &op
+			mh.handleOperatorKey(input.ActionEvent{Action: input.ActionInsertRune, Rune: r}, nil)
+			return nil
+		})
+	}
+	return mh.applyOperator(p.op, rng, ev)
+}
+
+// lineRange covers lines from to to (in either order) in full, clamped to
+// the buffer.
+func (mh *ModeHandler) lineRange(from, to int) textobj.Range {
+	last := mh.editor.GetBuffer().LineCount() - 1
+	from, to = max(0, min(from, last)), max(0, min(to, last))
+	if from > to {
+		from, to = to, from
+	}
+	return textobj.Range{Start: types.Position{Line: from}, End: types.Position{Line: to}, Linewise: true}
+}
+
+// lineLength returns the rune length of a line, 0 when it does not exist.
+func (mh *ModeHandler) lineLength(line int) int {
+	text, err := mh.editor.GetBuffer().Line(line)
+	if err != nil {
+		return 0
+	}
+	return utf8.RuneCount(text)
+}
+
+// textObjectRange returns the range of a text object ("iw", "a(") at the
+// cursor.
+func (mh *ModeHandler) textObjectRange(r rune, around bool) (textobj.Range, bool) {
+	cursor := mh.editor.GetCursor()
+	return textobj.Object(mh.editor.GetBuffer().Lines(), cursor, r, around)
+}
+
+// motionRange returns the text from the cursor to where motion r, repeated
+// count times, goes. Charwise ranges end before their end column, so the
+// inclusive motions (e, $, %) end one past the character they reach. counted
+// says whether a count was typed, which G needs to tell "G" from "1G".
+func (mh *ModeHandler) motionRange(r rune, count int, counted bool, op rune) (textobj.Range, bool) {
+	cursor := mh.editor.GetCursor()
+	defer mh.editor.SetCursor(cursor) // Motions only measure
+
+	from := types.Position{Line: cursor.Line, Col: min(cursor.Col, mh.lineLength(cursor.Line))}
+	to := from
+	repeat := func(move func()) types.Position {
+		for i := 0; i < count; i++ {
+			move()
+		}
+		return mh.editor.GetCursor()
+	}
+
+	switch r {
+	case 'j':
+		return mh.lineRange(from.Line, from.Line+count), true
+	case 'k':
+		return mh.lineRange(from.Line-count, from.Line), true
+	case 'G':
+		target := mh.editor.GetBuffer().LineCount() - 1
+		if counted {
+			target = count - 1
+		}
+		return mh.lineRange(from.Line, target), true
+	case 'h':
+		to.Col = max(0, from.Col-count)
+	case 'l':
+		to.Col = min(mh.lineLength(from.Line), from.Col+count)
+	case '0':
+		to.Col = 0
+	case '^':
+		text, _ := mh.editor.GetBuffer().Line(from.Line)
+		blanks := len(text) - len(strings.TrimLeft(string(text), " \t"))
+		to.Col = blanks
+	case '$':
+		to.Line = min(from.Line+count-1, mh.editor.GetBuffer().LineCount()-1)
+		to.Col = mh.lineLength(to.Line)
+	case '%':
+		// Inclusive of both brackets, whichever way the match lies
+		_, match, ok := mh.editor.MatchingBracket(true)
+		if !ok {
+			return textobj.Range{}, false
+		}
+		to = match
+		if to.Line > from.Line || (to.Line == from.Line && to.Col > from.Col) {
+			to.Col++
+		} else {
+			from.Col++
+		}
+	case 'b':
+		to = repeat(mh.editor.WordBackward)
+	case 'e':
+		to = repeat(mh.editor.WordEnd)
+		to.Col = min(to.Col+1, mh.lineLength(to.Line))
+	case 'w':
+		if op == 'c' && from.Col < mh.lineLength(from.Line) && !mh.onBlank(from) {
+			// As in Vim, "cw" changes to the end of the word, keeping the
+			// blank after it
+			if count > 1 {
+				return mh.motionRange('e', count, counted, op)
+			}
+			text, _ := mh.editor.GetBuffer().Line(from.Line)
+			_, end, _ := textobj.Word([]rune(string(text)), from.Col, false)
+			return textobj.Range{Start: from, End: types.Position{Line: from.Line, Col: end}}, true
+		}
+		to = repeat(mh.editor.WordForward)
+		if to.Line > from.Line {
+			// Stop at the end of a line rather than at the first word of
+			// the next one: "dw" on the last word does not join lines.
+			text, _ := mh.editor.GetBuffer().Line(to.Line)
+			runes := []rune(string(text))
+			if strings.TrimSpace(string(runes[:min(to.Col, len(runes))])) == "" {
+				to = types.Position{Line: to.Line - 1, Col: mh.lineLength(to.Line - 1)}
+			}
+		}
+	default:
+		return textobj.Range{}, false
+	}
+
+	if to.Line < from.Line || (to.Line == from.Line && to.Col < from.Col) {
+		from, to = to, from
+	}
+	return textobj.Range{Start: from, End: to}, true
+}
+
+// onBlank reports whether the character at pos is a space or tab.
+func (mh *ModeHandler) onBlank(pos types.Position) bool {
+	text, _ := mh.editor.GetBuffer().Line(pos.Line)
+	runes := []rune(string(text))
+	return pos.Col < len(runes) && (runes[pos.Col] == ' ' || runes[pos.Col] == '\t')
+}
+
+// applyOperator runs op on rng.
+func (mh *ModeHandler) applyOperator(op rune, rng textobj.Range, ev *tcell.EventKey) bool {
+	if op != 'y' && mh.editor.GetBuffer().ReadOnly() {
+		mh.statusBar.SetTemporaryMessage(i18n.T("status.read_only"))
+		return true
+	}
+	empty := !rng.Linewise && rng.Start == rng.End
+
+	var err error
+	switch op {
+	case 'd':
+		if !empty {
+			err = mh.editor.CutRange(rng.Start, rng.End, rng.Linewise)
+		}
+	case 'y':
+		if !empty {
+			err = mh.editor.YankRange(rng.Start, rng.End, rng.Linewise)
+		}
+		cursor := mh.editor.GetCursor()
+		if rng.Linewise {
+			cursor.Line = rng.Start.Line
+		} else if rng.Start.Line < cursor.Line || (rng.Start.Line == cursor.Line && rng.Start.Col < cursor.Col) {
+			cursor = rng.Start
+		}
+		mh.editor.SetCursor(cursor)
+		if err == nil && rng.Linewise {
+			mh.statusBar.SetTemporaryMessage("%d line(s) yanked", rng.End.Line-rng.Start.Line+1)
+		}
+	case 'c':
+		err = mh.change(rng, empty)
+		if err == nil {
+			return mh.executeAction(input.ActionEnterInsertMode, input.ActionEvent{Action: input.ActionEnterInsertMode}, ev)
+		}
+	case '>', '<':
+		endLine := rng.End.Line
+		if !rng.Linewise && endLine > rng.Start.Line && rng.End.Col == 0 {
+			endLine-- // The range stops before this line
+		}
+		err = mh.editor.ShiftLines(rng.Start.Line, endLine, op == '>')
+	}
+	if err != nil {
+		mh.statusBar.SetTemporaryMessage("%c failed: %v", op, err)
+	}
+	return true
+}
+
+// change deletes rng for the c operator. Changed lines become one line
+// keeping the first one's indentation, ready to type over.
+func (mh *ModeHandler) change(rng textobj.Range, empty bool) error {
+	if !rng.Linewise {
+		if empty {
+			mh.editor.SetCursor(rng.Start)
+			return nil
+		}
+		return mh.editor.CutRange(rng.Start, rng.End, false)
+	}
+	if err := mh.editor.YankRange(rng.Start, rng.End, true); err != nil {
+		return err
+	}
+	text, _ := mh.editor.GetBuffer().Line(rng.Start.Line)
+	indent := text[:len(text)-len(strings.TrimLeft(string(text), " \t"))]
+	end := types.Position{Line: rng.End.Line, Col: mh.lineLength(rng.End.Line)}
+	after, err := mh.editor.ReplaceRange(types.Position{Line: rng.Start.Line}, end, append([]byte(nil), indent...))
+	if err != nil {
+		return err
+	}
+	mh.editor.SetCursor(after)
+	return nil
+}
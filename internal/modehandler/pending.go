@@ -0,0 +1,36 @@
+package modehandler
+
+import (
+	"strconv"
+
+	"github.com/bethropolis/tide/internal/input"
+)
+
+// PendingKeys returns what has been typed of a command that is not complete
+// yet, as it was typed: a count, an operator waiting for its motion, a g
+// or ] prefix, Ctrl+W, the leader key or keys that may start a mapping.
+// It returns "" when nothing is pending.
+func (mh *ModeHandler) PendingKeys() string {
+	var keys string
+	if mh.countAccumulator > 0 {
+		keys = strconv.Itoa(mh.countAccumulator)
+	}
+	switch {
+	case mh.operator != nil:
+		keys = mh.operator.keys()
+	case mh.pendingOperator != 0:
+		keys += string(mh.pendingOperator)
+	case mh.windowPending:
+		keys += "^W"
+	case mh.leaderWaiting:
+		keys += "<leader>"
+	}
+	if len(mh.pendingKeys) > 0 {
+		strokes := make([]input.KeyStroke, len(mh.pendingKeys))
+		for i, ev := range mh.pendingKeys {
+			strokes[i] = input.KeyStrokeOf(ev)
+		}
+		keys += input.FormatKeySequence(strokes)
+	}
+	return keys
+}
@@ -0,0 +1,34 @@
+package modehandler
+
+import "github.com/gdamore/tcell/v2"
+
+// SetRepeat makes "." in normal mode run fn, for changes made by other
+// means than typing, such as plugin operations. name describes the change
+// when it fails. Typing in insert mode replaces it as the change to repeat.
+func (mh *ModeHandler) SetRepeat(name string, fn func() error) {
+	mh.repeatName = name
+	mh.repeatFn = fn
+}
+
+// repeatLastChange runs the last change again for ".": the change set with
+// SetRepeat or by an operator, count times, or else the last insert.
+func (mh *ModeHandler) repeatLastChange(count int, ev *tcell.EventKey) bool {
+	if mh.repeatFn != nil {
+		fn := mh.repeatFn
+		for i := 0; i < count; i++ {
+			if err := fn(); err != nil {
+				mh.statusBar.SetErrorMessage("Repeating %s failed: %v", mh.repeatName, err)
+				break
+			}
+		}
+		return true
+	}
+	if len(mh.lastInsertActions) == 0 {
+		mh.statusBar.SetTemporaryMessage("Nothing to repeat")
+		return true
+	}
+	for _, ae := range mh.lastInsertActions {
+		mh.executeAction(ae.Action, ae, ev)
+	}
+	return true
+}
@@ -0,0 +1,179 @@
+package modehandler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bethropolis/tide/internal/input"
+	"github.com/bethropolis/tide/internal/script"
+	"github.com/gdamore/tcell/v2"
+)
+
+// StartRecording begins writing what is done in the editor to a script
+// at path (:record): commands, text typed in insert mode, named actions
+// and other keys. Mouse clicks, pastes and choices made in pickers and
+// dialogs are not recorded.
+func (mh *ModeHandler) StartRecording(path string) error {
+	if mh.recorder != nil {
+		return fmt.Errorf("already recording to %s", mh.recorder.Path())
+	}
+	r, err := script.Create(path)
+	if err != nil {
+		return err
+	}
+	mh.recorder = r
+	return nil
+}
+
+// StopRecording finishes the script being recorded and returns its path.
+func (mh *ModeHandler) StopRecording() (string, error) {
+	if mh.recorder == nil {
+		return "", fmt.Errorf("not recording")
+	}
+	path := mh.recorder.Path()
+	err := mh.recorder.Close()
+	mh.recorder = nil
+	return path, err
+}
+
+// Recording returns the path of the script being recorded, or "".
+func (mh *ModeHandler) Recording() string {
+	if mh.recorder == nil {
+		return ""
+	}
+	return mh.recorder.Path()
+}
+
+// recording reports whether what is done now goes into the script; steps
+// being replayed do not.
+func (mh *ModeHandler) recording() bool {
+	return mh.recorder != nil && mh.replaying == 0
+}
+
+// modalActions mean different things in different modes, so the keys
+// bound to them are recorded rather than their names.
+var modalActions = map[input.Action]bool{
+	input.ActionQuit:               true, // Esc and Ctrl+C leave modes
+	input.ActionInsertNewLine:      true,
+	input.ActionInsertTab:          true,
+	input.ActionInsertBacktab:      true,
+	input.ActionDeleteCharBackward: true,
+}
+
+// recordKey records a key handled in mode before. Keys typed on the
+// command line are not recorded, nor is the one that opened it: the
+// command is, when it runs.
+func (mh *ModeHandler) recordKey(actionEvent input.ActionEvent, ev *tcell.EventKey, before InputMode) {
+	if !mh.recording() || before == ModeCommand || mh.currentMode == ModeCommand {
+		return
+	}
+	if before == ModeInsert {
+		switch actionEvent.Action {
+		case input.ActionInsertRune:
+			mh.recorder.Add(script.Step{Kind: script.Insert, Text: string(actionEvent.Rune)})
+			return
+		case input.ActionInsertNewLine:
+			mh.recorder.Add(script.Step{Kind: script.Insert, Text: "\n"})
+			return
+		case input.ActionInsertTab:
+			mh.recorder.Add(script.Step{Kind: script.Insert, Text: "\t"})
+			return
+		case input.ActionDeleteCharBackward:
+			mh.recordBackspace()
+			return
+		}
+	}
+	action := actionEvent.Action
+	name := input.NameFromAction(action)
+	if name != "" && action != input.ActionInsertRune && !modalActions[action] && ev.Modifiers()&tcell.ModShift == 0 {
+		mh.recorder.Add(script.Step{Kind: script.Action, Text: name})
+		return
+	}
+	mh.recorder.Add(script.Step{Kind: script.Keys, Text: input.FormatKeySequence([]input.KeyStroke{input.KeyStrokeOf(ev)})})
+}
+
+// recordBackspace takes back the last character of the text being
+// recorded, or records a Backspace when there is none.
+func (mh *ModeHandler) recordBackspace() {
+	if !mh.recorder.Backspace() {
+		mh.recorder.Add(script.Step{Kind: script.Keys, Text: "<Backspace>"})
+	}
+}
+
+// RecordCompletion records an accepted completion, which replaced the
+// given number of characters before the cursor with text, as typed text.
+func (mh *ModeHandler) RecordCompletion(replaced int, text string) {
+	if !mh.recording() || mh.currentMode != ModeInsert {
+		return
+	}
+	for i := 0; i < replaced; i++ {
+		mh.recordBackspace()
+	}
+	mh.recorder.Add(script.Step{Kind: script.Insert, Text: text})
+}
+
+// recordCommand records a command line about to run. :record itself is
+// left out, so that stopping is not part of the script.
+func (mh *ModeHandler) recordCommand(cmdLine string) {
+	if !mh.recording() {
+		return
+	}
+	if fields := strings.Fields(cmdLine); len(fields) > 0 && fields[0] == "record" {
+		return
+	}
+	mh.recorder.Add(script.Step{Kind: script.Command, Text: cmdLine})
+}
+
+// ReplayStep does one step of a script as if it had been typed: a command
+// runs as from the command line, and text, actions and keys go to the
+// current mode. Key mappings (:map) are not applied again, as the keys
+// recorded are the ones they produced. An action is done by pressing a
+// key bound to it, so that it means what it did when recorded.
+func (mh *ModeHandler) ReplayStep(step script.Step) error {
+	mh.replaying++
+	defer func() { mh.replaying-- }()
+
+	switch step.Kind {
+	case script.Command:
+		mh.RunCommand(step.Text)
+	case script.Insert:
+		for _, r := range step.Text {
+			mh.handleKey(textKey(r))
+		}
+	case script.Keys:
+		keys, err := input.ParseKeySequence(step.Text, mh.leaderKey)
+		if err != nil {
+			return err
+		}
+		for _, ks := range keys {
+			mh.handleKey(ks.Event())
+		}
+	case script.Action:
+		action, ok := input.ActionFromName(step.Text)
+		if !ok {
+			return fmt.Errorf("unknown action %q", step.Text)
+		}
+		keys, bound := mh.inputProcessor.KeyStrokesForAction(action)
+		for i := 0; i < max(step.Count, 1); i++ {
+			if !bound {
+				mh.RunAction(action)
+				continue
+			}
+			for _, ks := range keys {
+				mh.handleKey(ks.Event())
+			}
+		}
+	}
+	return nil
+}
+
+// textKey returns the key that types r.
+func textKey(r rune) *tcell.EventKey {
+	switch r {
+	case '\n':
+		return tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone)
+	case '\t':
+		return tcell.NewEventKey(tcell.KeyTab, 0, tcell.ModNone)
+	}
+	return tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone)
+}
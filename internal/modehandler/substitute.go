@@ -0,0 +1,123 @@
+package modehandler
+
+import (
+	"fmt"
+
+	"github.com/bethropolis/tide/internal/core/find"
+	"github.com/bethropolis/tide/internal/input"
+	"github.com/bethropolis/tide/internal/types"
+)
+
+// substituteConfirm is a :s with the c flag waiting for an answer about
+// the match under the cursor.
+type substituteConfirm struct {
+	sub          *find.Substitution
+	pattern      string
+	found        bool // Any match was offered
+	cursorBefore types.Position
+	prompt       string
+}
+
+// startSubstituteConfirm offers each match of sub on lines [start, end]
+// in turn, replacing the ones answered with y. All the replacements undo
+// together.
+func (mh *ModeHandler) startSubstituteConfirm(sub find.Substitute, start, end int) error {
+	walk, err := mh.editor.GetFindManager().NewSubstitution(sub.Pattern, sub.Replacement, start, end, sub.Global, sub.CaseInsensitive)
+	if err != nil {
+		return err
+	}
+	if hist := mh.editor.GetHistoryManager(); hist != nil {
+		hist.BeginTransaction()
+	}
+	mh.substitute = &substituteConfirm{sub: walk, pattern: sub.Pattern, cursorBefore: mh.editor.GetCursor()}
+	mh.currentMode = ModeSubstitute
+	mh.nextSubstituteMatch()
+	return nil
+}
+
+// nextSubstituteMatch moves to the next match and asks about it, or
+// finishes when there are no more.
+func (mh *ModeHandler) nextSubstituteMatch() {
+	sc := mh.substitute
+	start, _, ok := sc.sub.Next()
+	if !ok {
+		mh.finishSubstitute()
+		return
+	}
+	sc.found = true
+	mh.editor.SetCursor(start)
+	mh.editor.ScrollToCursor()
+	sc.prompt = fmt.Sprintf("replace with %s (y/n/a/q/l)?", sc.sub.Replacement())
+	mh.statusBar.SetTemporaryMessage("%s", sc.prompt)
+}
+
+// handleActionSubstitute answers the question about the current match:
+// y replaces it, n skips it, a replaces it and all the rest, l replaces it
+// and stops, q or Esc stop.
+func (mh *ModeHandler) handleActionSubstitute(actionEvent input.ActionEvent) bool {
+	sc := mh.substitute
+	var err error
+	switch {
+	case actionEvent.Action == input.ActionQuit:
+		mh.finishSubstitute()
+		return true
+	case actionEvent.Action != input.ActionInsertRune:
+		return false
+	}
+
+	switch actionEvent.Rune {
+	case 'y':
+		if err = sc.sub.Replace(); err == nil {
+			mh.nextSubstituteMatch()
+		}
+	case 'n':
+		sc.sub.Skip()
+		mh.nextSubstituteMatch()
+	case 'a':
+		if err = sc.sub.ReplaceRest(); err == nil {
+			mh.finishSubstitute()
+		}
+	case 'l':
+		if err = sc.sub.Replace(); err == nil {
+			mh.finishSubstitute()
+		}
+	case 'q':
+		mh.finishSubstitute()
+	default:
+		return false
+	}
+	if err != nil {
+		mh.finishSubstitute()
+		mh.statusBar.SetErrorMessage("Replace failed: %v", err)
+	}
+	return true
+}
+
+// finishSubstitute ends the confirm mode and reports how many matches
+// were replaced.
+func (mh *ModeHandler) finishSubstitute() {
+	sc := mh.substitute
+	if sc == nil {
+		return
+	}
+	mh.substitute = nil
+	mh.currentMode = ModeNormal
+	if hist := mh.editor.GetHistoryManager(); hist != nil {
+		hist.EndTransaction(sc.cursorBefore)
+	}
+	mh.editor.ClearHighlights()
+	if sc.found {
+		mh.statusBar.SetTemporaryMessage("Replaced %d occurrence(s)", sc.sub.Count)
+	} else {
+		mh.statusBar.SetTemporaryMessage("Pattern not found: %s", sc.pattern)
+	}
+}
+
+// GetSubstitutePrompt returns the question shown while confirming a
+// substitute, or "" outside of it.
+func (mh *ModeHandler) GetSubstitutePrompt() string {
+	if mh.currentMode == ModeSubstitute && mh.substitute != nil {
+		return mh.substitute.prompt
+	}
+	return ""
+}
@@ -0,0 +1,33 @@
+package modehandler
+
+import (
+	"github.com/bethropolis/tide/internal/input"
+)
+
+// windowKeys are the window commands for keys with their own actions,
+// so Ctrl+W followed by an arrow or by Ctrl+W again works as in Vim.
+var windowKeys = map[input.Action]rune{
+	input.ActionMoveLeft:      'h',
+	input.ActionMoveDown:      'j',
+	input.ActionMoveUp:        'k',
+	input.ActionMoveRight:     'l',
+	input.ActionWindowCommand: 'w',
+}
+
+// handleWindowKey runs the window command named by the key typed after
+// Ctrl+W. Escape and keys that name no command cancel it.
+func (mh *ModeHandler) handleWindowKey(actionEvent input.ActionEvent) bool {
+	mh.windowPending = false
+	mh.statusBar.ResetTemporaryMessage()
+	r, ok := windowKeys[actionEvent.Action]
+	if actionEvent.Action == input.ActionInsertRune {
+		r, ok = actionEvent.Rune, true
+	}
+	if !ok || mh.api == nil {
+		return true
+	}
+	if err := mh.api.WindowCommand(r); err != nil {
+		mh.statusBar.SetTemporaryMessage("%v", err)
+	}
+	return true
+}
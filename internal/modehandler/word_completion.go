@@ -0,0 +1,124 @@
+package modehandler
+
+import (
+	"github.com/bethropolis/tide/internal/core/find"
+	"github.com/bethropolis/tide/internal/input"
+	"github.com/bethropolis/tide/internal/logger"
+	"github.com/bethropolis/tide/internal/types"
+)
+
+// wordCompletion is the state of a Ctrl+N/Ctrl+P completion: the candidates
+// for the prefix typed before the first key press, and which one is
+// currently inserted. Index -1 means the original prefix is shown.
+type wordCompletion struct {
+	start      types.Position // Where the prefix starts
+	prefix     string
+	candidates []string
+	index      int
+	inserted   string // Text currently in the buffer after start
+}
+
+// completeWord starts or continues buffer-word completion, replacing the
+// word before the cursor with the next (or previous) candidate. Cycling past
+// either end shows the original prefix again.
+func (mh *ModeHandler) completeWord(forward bool) bool {
+	wc := mh.wordCompletion
+	if wc == nil || !mh.completionInPlace(wc) {
+		wc = mh.startWordCompletion()
+		if wc == nil {
+			return false
+		}
+		mh.wordCompletion = wc
+		if forward {
+			wc.index = 0
+		} else {
+			wc.index = len(wc.candidates) - 1
+		}
+	} else {
+		n := len(wc.candidates) + 1 // Candidates plus the original prefix
+		step := 1
+		if !forward {
+			step = -1
+		}
+		wc.index = (wc.index+1+step+n)%n - 1
+	}
+
+	text := wc.prefix
+	if wc.index >= 0 {
+		text = wc.candidates[wc.index]
+		mh.statusBar.SetTemporaryMessage("-- Keyword completion -- match %d of %d", wc.index+1, len(wc.candidates))
+	} else {
+		mh.statusBar.SetTemporaryMessage("-- Keyword completion -- back at original")
+	}
+	mh.replaceCompletionText(wc, text)
+	return true
+}
+
+// startWordCompletion collects candidates for the word before the cursor.
+// Returns nil (with a status message) when there is nothing to complete.
+func (mh *ModeHandler) startWordCompletion() *wordCompletion {
+	if mh.completionWords == nil {
+		return nil
+	}
+	cursor := mh.editor.GetCursor()
+	line, err := mh.editor.GetBuffer().Line(cursor.Line)
+	if err != nil {
+		return nil
+	}
+	prefix, start := find.WordBefore(line, cursor.Col)
+	if prefix == "" {
+		mh.statusBar.SetTemporaryMessage("No word to complete")
+		return nil
+	}
+
+	candidates := mh.completionWords(prefix)
+	if len(candidates) == 0 {
+		mh.statusBar.SetTemporaryMessage("No completions for %q", prefix)
+		return nil
+	}
+	logger.Debugf("ModeHandler: %d completion candidates for %q", len(candidates), prefix)
+	return &wordCompletion{
+		start:      types.Position{Line: cursor.Line, Col: start},
+		prefix:     prefix,
+		candidates: candidates,
+		inserted:   prefix,
+	}
+}
+
+// completionInPlace reports whether the buffer still holds the text the
+// completion last inserted, with the cursor right after it.
+func (mh *ModeHandler) completionInPlace(wc *wordCompletion) bool {
+	cursor := mh.editor.GetCursor()
+	end := wc.start.Col + len([]rune(wc.inserted))
+	if cursor.Line != wc.start.Line || cursor.Col != end {
+		return false
+	}
+	line, err := mh.editor.GetBuffer().Line(cursor.Line)
+	if err != nil {
+		return false
+	}
+	runes := []rune(string(line))
+	return end <= len(runes) && string(runes[wc.start.Col:end]) == wc.inserted
+}
+
+// replaceCompletionText swaps the inserted text for text the way typing
+// would, so the change is undoable, highlighting stays in sync and dot-repeat
+// replays the same keystrokes.
+func (mh *ModeHandler) replaceCompletionText(wc *wordCompletion, text string) {
+	for range []rune(wc.inserted) {
+		if err := mh.editor.DeleteBackward(); err != nil {
+			logger.Warnf("ModeHandler: completion delete failed: %v", err)
+			return
+		}
+		mh.recordInsertAction(input.ActionEvent{Action: input.ActionDeleteCharBackward})
+	}
+	wc.inserted = ""
+	for _, r := range text {
+		if err := mh.editor.InsertRune(r); err != nil {
+			logger.Warnf("ModeHandler: completion insert failed: %v", err)
+			return
+		}
+		mh.recordInsertAction(input.ActionEvent{Action: input.ActionInsertRune, Rune: r})
+		wc.inserted += string(r)
+	}
+}
@@ -0,0 +1,116 @@
+// Package paths decides where tide keeps its files. Configuration
+// (config.toml, themes, templates, Lua plugins) goes in the config
+// directory, files tide writes for itself (logs, views, backups, the
+// project trust list) in the state directory, and longer-lived data in
+// the data directory.
+//
+// $TIDE_HOME, when set, holds all three. Otherwise the XDG base directory
+// variables are honored ($XDG_CONFIG_HOME, $XDG_DATA_HOME, $XDG_STATE_HOME),
+// with the usual ~/.config, ~/.local/share and ~/.local/state defaults on
+// Linux and the BSDs. On macOS and Windows everything defaults to the
+// config directory, as before.
+package paths
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// appName names tide's directory within each base directory.
+const appName = "tide"
+
+// HomeEnv overrides every directory tide uses.
+const HomeEnv = "TIDE_HOME"
+
+// ConfigDir returns the directory holding the user's configuration.
+func ConfigDir() (string, error) {
+	if home := os.Getenv(HomeEnv); home != "" {
+		return home, nil
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, appName), nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, appName), nil
+}
+
+// DataDir returns the directory for data tide keeps across sessions.
+func DataDir() (string, error) {
+	return baseDir("XDG_DATA_HOME", filepath.Join(".local", "share"))
+}
+
+// StateDir returns the directory for logs and other state tide writes.
+func StateDir() (string, error) {
+	return baseDir("XDG_STATE_HOME", filepath.Join(".local", "state"))
+}
+
+// baseDir resolves tide's directory within the XDG base directory named by
+// env, whose default under the home directory is def.
+func baseDir(env, def string) (string, error) {
+	if home := os.Getenv(HomeEnv); home != "" {
+		return home, nil
+	}
+	if dir := os.Getenv(env); dir != "" {
+		return filepath.Join(dir, appName), nil
+	}
+	switch runtime.GOOS {
+	case "darwin", "windows", "plan9", "ios", "android", "js":
+		return ConfigDir()
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, def, appName), nil
+}
+
+// Config joins elem to the config directory.
+func Config(elem ...string) (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(append([]string{dir}, elem...)...), nil
+}
+
+// Data joins elem to the data directory. See State for files written by
+// older versions.
+func Data(elem ...string) (string, error) {
+	dir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return withLegacy(dir, elem)
+}
+
+// State joins elem to the state directory. Older versions kept everything
+// in the config directory, so while the new path does not exist yet and
+// the old one does, the old one is returned and keeps being used.
+func State(elem ...string) (string, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	return withLegacy(dir, elem)
+}
+
+// withLegacy joins elem to dir, or to the config directory if only that
+// has it.
+func withLegacy(dir string, elem []string) (string, error) {
+	path := filepath.Join(append([]string{dir}, elem...)...)
+	if _, err := os.Stat(path); err == nil || len(elem) == 0 {
+		return path, nil
+	}
+	legacy, err := Config(elem...)
+	if err != nil || legacy == path {
+		return path, nil
+	}
+	if _, err := os.Stat(legacy); err == nil {
+		return legacy, nil
+	}
+	return path, nil
+}
@@ -0,0 +1,60 @@
+package paths
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestHomeOverride(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv(HomeEnv, home)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	for name, dir := range map[string]func() (string, error){"config": ConfigDir, "data": DataDir, "state": StateDir} {
+		if got, err := dir(); err != nil || got != home {
+			t.Errorf("%s dir = %q, %v; want %q", name, got, err, home)
+		}
+	}
+}
+
+func TestXDG(t *testing.T) {
+	t.Setenv(HomeEnv, "")
+	config, state := t.TempDir(), t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", config)
+	t.Setenv("XDG_STATE_HOME", state)
+	if got, _ := ConfigDir(); got != filepath.Join(config, "tide") {
+		t.Errorf("ConfigDir() = %q", got)
+	}
+	if got, _ := State("views"); got != filepath.Join(state, "tide", "views") {
+		t.Errorf("State(views) = %q", got)
+	}
+
+	// Views written by older versions under the config directory
+	legacy := filepath.Join(config, "tide", "views")
+	if err := os.MkdirAll(legacy, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := State("views"); got != legacy {
+		t.Errorf("State(views) = %q, want the old directory %q", got, legacy)
+	}
+	if err := os.MkdirAll(filepath.Join(state, "tide", "views"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := State("views"); got != filepath.Join(state, "tide", "views") {
+		t.Errorf("State(views) = %q once the new directory exists", got)
+	}
+}
+
+func TestStateDefault(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("XDG defaults only apply on Linux and the BSDs")
+	}
+	home := t.TempDir()
+	t.Setenv(HomeEnv, "")
+	t.Setenv("XDG_STATE_HOME", "")
+	t.Setenv("HOME", home)
+	if got, _ := StateDir(); got != filepath.Join(home, ".local", "state", "tide") {
+		t.Errorf("StateDir() = %q", got)
+	}
+}
@@ -28,11 +28,13 @@ func LoadLuaPlugins(pm *plugin.Manager, dir string) error {
 		p, err := NewLuaPlugin(path)
 		if err != nil {
 			logger.Errorf("Failed to load lua plugin '%s': %v", path, err)
+			pm.RecordError(path, err)
 			continue
 		}
 
 		if err := pm.Register(p); err != nil {
 			logger.Errorf("Failed to register lua plugin '%s': %v", path, err)
+			pm.RecordError(path, err)
 		} else {
 			logger.Infof("Loaded Lua plugin: %s", p.Name())
 		}
@@ -6,21 +6,27 @@ import (
 	"path/filepath"
 	"sync"
 
+	"github.com/bethropolis/tide/internal/config"
 	"github.com/bethropolis/tide/internal/event"
 	"github.com/bethropolis/tide/internal/logger"
 	"github.com/bethropolis/tide/internal/plugin"
 	"github.com/bethropolis/tide/internal/tui"
 	"github.com/bethropolis/tide/internal/types"
+	"github.com/bethropolis/tide/internal/version"
+	"github.com/gdamore/tcell/v2"
 	lua "github.com/yuin/gopher-lua"
 )
 
 type LuaPlugin struct {
 	name          string
 	script        string
+	path          string
 	api           plugin.EditorAPI
 	L             *lua.LState
 	mu            sync.Mutex
 	subscriptions map[event.Type][]event.SubscriptionID
+	drawHooks     []plugin.DrawHookID
+	drawScreen    tcell.Screen // Set while a draw hook runs, for tide.draw_text
 }
 
 // NewLuaPlugin creates a new Lua plugin from a script path.
@@ -37,6 +43,7 @@ func NewLuaPlugin(path string) (*LuaPlugin, error) {
 	return &LuaPlugin{
 		name:   name,
 		script: string(content),
+		path:   path,
 	}, nil
 }
 
@@ -68,6 +75,10 @@ func (p *LuaPlugin) Shutdown() error {
 		}
 	}
 	p.subscriptions = nil
+	for _, id := range p.drawHooks {
+		p.api.RemoveDrawHook(id)
+	}
+	p.drawHooks = nil
 
 	if p.L != nil {
 		p.L.Close()
@@ -94,6 +105,11 @@ func (p *LuaPlugin) convertEventToLua(e event.Event) lua.LValue {
 		p.L.SetField(tbl, "new_col", lua.LNumber(d.NewPosition.Col))
 		return tbl
 
+	case event.CursorHoldData:
+		p.L.SetField(tbl, "line", lua.LNumber(d.Position.Line))
+		p.L.SetField(tbl, "col", lua.LNumber(d.Position.Col))
+		return tbl
+
 	case event.BufferModifiedData:
 		editTbl := p.L.NewTable()
 		p.L.SetField(editTbl, "start_line", lua.LNumber(d.Edit.StartPosition.Row))
@@ -105,10 +121,25 @@ func (p *LuaPlugin) convertEventToLua(e event.Event) lua.LValue {
 		p.L.SetField(tbl, "edit", editTbl)
 		return tbl
 
+	case event.ContentChangedData:
+		p.L.SetField(tbl, "file_path", lua.LString(d.FilePath))
+		p.L.SetField(tbl, "text", lua.LString(d.Text))
+		return tbl
+
 	case event.BufferSavedData:
 		p.L.SetField(tbl, "file_path", lua.LString(d.FilePath))
 		return tbl
 
+	case event.BufferRenamedData:
+		p.L.SetField(tbl, "old_path", lua.LString(d.OldPath))
+		p.L.SetField(tbl, "new_path", lua.LString(d.NewPath))
+		return tbl
+
+	case event.FileDeletedData:
+		p.L.SetField(tbl, "file_path", lua.LString(d.FilePath))
+		p.L.SetField(tbl, "trash_path", lua.LString(d.TrashPath))
+		return tbl
+
 	case event.BufferLoadedData:
 		p.L.SetField(tbl, "file_path", lua.LString(d.FilePath))
 		return tbl
@@ -128,6 +159,24 @@ func (p *LuaPlugin) convertEventToLua(e event.Event) lua.LValue {
 	return lua.LNil
 }
 
+// geometryToLua converts the editor geometry into a Lua table.
+func (p *LuaPlugin) geometryToLua(geo plugin.Geometry) *lua.LTable {
+	tbl := p.L.NewTable()
+	p.L.SetField(tbl, "screen_width", lua.LNumber(geo.ScreenWidth))
+	p.L.SetField(tbl, "screen_height", lua.LNumber(geo.ScreenHeight))
+	p.L.SetField(tbl, "x", lua.LNumber(geo.Editor.X))
+	p.L.SetField(tbl, "y", lua.LNumber(geo.Editor.Y))
+	p.L.SetField(tbl, "width", lua.LNumber(geo.Editor.Width))
+	p.L.SetField(tbl, "height", lua.LNumber(geo.Editor.Height))
+	p.L.SetField(tbl, "gutter_width", lua.LNumber(geo.GutterWidth))
+	p.L.SetField(tbl, "top_line", lua.LNumber(geo.ViewportY))
+	p.L.SetField(tbl, "left_col", lua.LNumber(geo.ViewportX))
+	p.L.SetField(tbl, "line_count", lua.LNumber(geo.LineCount))
+	p.L.SetField(tbl, "cursor_line", lua.LNumber(geo.Cursor.Line))
+	p.L.SetField(tbl, "cursor_col", lua.LNumber(geo.Cursor.Col))
+	return tbl
+}
+
 func (p *LuaPlugin) registerAPI() {
 	// Create the `tide` table
 	tideTable := p.L.NewTable()
@@ -175,6 +224,33 @@ func (p *LuaPlugin) registerAPI() {
 		return 1
 	}))
 
+	// tide.map(modes, lhs, rhs [, {noremap = true}]) -> error or nil
+	// modes as for :map: "n", "v", "o", "i", "c", a combination, "" or "!"
+	p.L.SetField(tideTable, "map", p.L.NewFunction(func(L *lua.LState) int {
+		modes, lhs, rhs := L.CheckString(1), L.CheckString(2), L.CheckString(3)
+		noremap := false
+		if opts, ok := L.Get(4).(*lua.LTable); ok {
+			noremap = lua.LVAsBool(opts.RawGetString("noremap"))
+		}
+		source := fmt.Sprintf("Lua plugin %s (%s)", p.name, p.path)
+		if err := p.api.MapKeys(modes, lhs, rhs, noremap, source); err != nil {
+			L.Push(lua.LString(err.Error()))
+			return 1
+		}
+		L.Push(lua.LNil)
+		return 1
+	}))
+
+	// tide.unmap(modes, lhs) -> error or nil
+	p.L.SetField(tideTable, "unmap", p.L.NewFunction(func(L *lua.LState) int {
+		if err := p.api.UnmapKeys(L.CheckString(1), L.CheckString(2)); err != nil {
+			L.Push(lua.LString(err.Error()))
+			return 1
+		}
+		L.Push(lua.LNil)
+		return 1
+	}))
+
 	// tide.get_cursor() -> line, col
 	p.L.SetField(tideTable, "get_cursor", p.L.NewFunction(func(L *lua.LState) int {
 		pos := p.api.GetCursor()
@@ -191,6 +267,64 @@ func (p *LuaPlugin) registerAPI() {
 		return 0
 	}))
 
+	// tide.get_geometry() -> table
+	p.L.SetField(tideTable, "get_geometry", p.L.NewFunction(func(L *lua.LState) int {
+		L.Push(p.geometryToLua(p.api.GetGeometry()))
+		return 1
+	}))
+
+	// tide.add_draw_hook(callback) -> id
+	// The callback receives the geometry table after every frame and may
+	// paint with tide.draw_text while it runs.
+	p.L.SetField(tideTable, "add_draw_hook", p.L.NewFunction(func(L *lua.LState) int {
+		callback := L.CheckFunction(1)
+		id := p.api.AddDrawHook(func(screen tcell.Screen, geo plugin.Geometry) {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			p.drawScreen = screen
+			defer func() { p.drawScreen = nil }()
+
+			p.L.Push(callback)
+			p.L.Push(p.geometryToLua(geo))
+			if err := p.L.PCall(1, 0, nil); err != nil {
+				logger.Errorf("Lua draw hook error: %v", err)
+			}
+		})
+		p.drawHooks = append(p.drawHooks, id)
+		L.Push(lua.LNumber(id))
+		return 1
+	}))
+
+	// tide.remove_draw_hook(id) -> bool
+	p.L.SetField(tideTable, "remove_draw_hook", p.L.NewFunction(func(L *lua.LState) int {
+		id := plugin.DrawHookID(L.CheckNumber(1))
+		for i, hookID := range p.drawHooks {
+			if hookID == id {
+				p.api.RemoveDrawHook(id)
+				p.drawHooks = append(p.drawHooks[:i], p.drawHooks[i+1:]...)
+				L.Push(lua.LTrue)
+				return 1
+			}
+		}
+		L.Push(lua.LFalse)
+		return 1
+	}))
+
+	// tide.draw_text(x, y, text [, style_name]) — only inside a draw hook
+	p.L.SetField(tideTable, "draw_text", p.L.NewFunction(func(L *lua.LState) int {
+		x := L.CheckInt(1)
+		y := L.CheckInt(2)
+		text := L.CheckString(3)
+		styleName := L.OptString(4, "Default")
+		if p.drawScreen == nil {
+			L.RaiseError("draw_text called outside a draw hook")
+			return 0
+		}
+		w, _ := p.drawScreen.Size()
+		tui.DrawText(p.drawScreen, x, y, w-x, text, p.api.GetThemeStyle(styleName))
+		return 0
+	}))
+
 	// tide.get_buffer_lines(start_line, end_line)
 	p.L.SetField(tideTable, "get_buffer_lines", p.L.NewFunction(func(L *lua.LState) int {
 		startLine := L.CheckInt(1)
@@ -239,6 +373,60 @@ func (p *LuaPlugin) registerAPI() {
 		return 1
 	}))
 
+	// tide.repeatable(name, fn) -> error or nil
+	// Runs fn as one change: its edits undo together, and "." runs it again.
+	p.L.SetField(tideTable, "repeatable", p.L.NewFunction(func(L *lua.LState) int {
+		name := L.CheckString(1)
+		fn := L.CheckFunction(2)
+		first := true
+		err := p.api.RunRepeatable(name, func() error {
+			// The first run happens inside this call, which already holds
+			// the lock; repeats come from "." on the event loop.
+			if !first {
+				p.mu.Lock()
+				defer p.mu.Unlock()
+			}
+			first = false
+			p.L.Push(fn)
+			if err := p.L.PCall(0, 0, nil); err != nil {
+				logger.Errorf("Lua change '%s' error: %v", name, err)
+				return fmt.Errorf("lua change error: %w", err)
+			}
+			return nil
+		})
+		if err != nil {
+			L.Push(lua.LString(err.Error()))
+			return 1
+		}
+		L.Push(lua.LNil)
+		return 1
+	}))
+
+	// tide.version() -> {version, commit, build_date, go_version, platform}
+	p.L.SetField(tideTable, "version", p.L.NewFunction(func(L *lua.LState) int {
+		info := p.api.Version()
+		tbl := L.NewTable()
+		tbl.RawSetString("version", lua.LString(info.Version))
+		tbl.RawSetString("commit", lua.LString(info.Commit))
+		tbl.RawSetString("build_date", lua.LString(info.BuildDate))
+		tbl.RawSetString("go_version", lua.LString(info.GoVersion))
+		tbl.RawSetString("platform", lua.LString(info.Platform))
+		L.Push(tbl)
+		return 1
+	}))
+
+	// tide.version_at_least(v) -> bool
+	p.L.SetField(tideTable, "version_at_least", p.L.NewFunction(func(L *lua.LState) int {
+		L.Push(lua.LBool(version.Compare(p.api.Version().Version, L.CheckString(1)) >= 0))
+		return 1
+	}))
+
+	// tide.reduce_motion() -> bool: whether to skip animation
+	p.L.SetField(tideTable, "reduce_motion", p.L.NewFunction(func(L *lua.LState) int {
+		L.Push(lua.LBool(config.Get().UI.ReduceMotion))
+		return 1
+	}))
+
 	// tide.get_buffer_file_path()
 	p.L.SetField(tideTable, "get_buffer_file_path", p.L.NewFunction(func(L *lua.LState) int {
 		L.Push(lua.LString(p.api.GetBufferFilePath()))
@@ -275,6 +463,17 @@ func (p *LuaPlugin) registerAPI() {
 		return 1
 	}))
 
+	// tide.rename_file(new_path) -> err
+	p.L.SetField(tideTable, "rename_file", p.L.NewFunction(func(L *lua.LState) int {
+		newPath := L.CheckString(1)
+		if err := p.api.RenameFile(newPath); err != nil {
+			L.Push(lua.LString(err.Error()))
+			return 1
+		}
+		L.Push(lua.LNil)
+		return 1
+	}))
+
 	// tide.force_close_buffer()
 	p.L.SetField(tideTable, "force_close_buffer", p.L.NewFunction(func(L *lua.LState) int {
 		p.api.ForceCloseBuffer()
@@ -338,12 +537,20 @@ func (p *LuaPlugin) registerAPI() {
 		switch eventName {
 		case "buffer_modified":
 			eventType = event.TypeBufferModified
+		case "content_changed":
+			eventType = event.TypeContentChanged
 		case "buffer_loaded":
 			eventType = event.TypeBufferLoaded
 		case "buffer_saved":
 			eventType = event.TypeBufferSaved
+		case "buffer_renamed":
+			eventType = event.TypeBufferRenamed
+		case "file_deleted":
+			eventType = event.TypeFileDeleted
 		case "cursor_moved":
 			eventType = event.TypeCursorMoved
+		case "cursor_hold":
+			eventType = event.TypeCursorHold
 		case "theme_changed":
 			eventType = event.TypeThemeChanged
 		case "key_pressed":
@@ -358,7 +565,9 @@ func (p *LuaPlugin) registerAPI() {
 		}
 
 		id := p.api.SubscribeEvent(eventType, func(e event.Event) bool {
-			go func() {
+			// Run after the dispatching code returns, on the event loop, so
+			// the callback may edit the buffer without racing other input.
+			p.api.Schedule(func() {
 				p.mu.Lock()
 				defer p.mu.Unlock()
 
@@ -368,7 +577,7 @@ func (p *LuaPlugin) registerAPI() {
 				if err := p.L.PCall(1, 0, nil); err != nil {
 					logger.Errorf("Lua event '%s' callback error: %v", eventName, err)
 				}
-			}()
+			})
 			return false
 		})
 
@@ -378,6 +587,21 @@ func (p *LuaPlugin) registerAPI() {
 		return 1
 	}))
 
+	// tide.schedule(callback)
+	// Runs callback on the event loop once the current event is handled.
+	p.L.SetField(tideTable, "schedule", p.L.NewFunction(func(L *lua.LState) int {
+		callback := L.CheckFunction(1)
+		p.api.Schedule(func() {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			p.L.Push(callback)
+			if err := p.L.PCall(0, 0, nil); err != nil {
+				logger.Errorf("Lua scheduled callback error: %v", err)
+			}
+		})
+		return 0
+	}))
+
 	// tide.unsubscribe(id) -> bool
 	p.L.SetField(tideTable, "unsubscribe", p.L.NewFunction(func(L *lua.LState) int {
 		id := event.SubscriptionID(L.CheckNumber(1))
@@ -14,15 +14,46 @@ type Manager struct {
 	mu      sync.RWMutex
 	plugins map[string]Plugin // Store loaded plugins by name
 	api     EditorAPI         // The API instance passed to plugins during init
+	errs    map[string]error  // Plugins that failed to load, register or initialize
 }
 
 // NewManager creates a new plugin manager.
 func NewManager() *Manager {
 	return &Manager{
 		plugins: make(map[string]Plugin),
+		errs:    make(map[string]error),
 	}
 }
 
+// RecordError notes that the plugin called name failed to load, for :checkhealth.
+func (m *Manager) RecordError(name string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.errs[name] = err
+}
+
+// Errors returns the plugins that failed, by name.
+func (m *Manager) Errors() map[string]error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	errs := make(map[string]error, len(m.errs))
+	for name, err := range m.errs {
+		errs[name] = err
+	}
+	return errs
+}
+
+// Names returns the names of the registered plugins.
+func (m *Manager) Names() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	names := make([]string, 0, len(m.plugins))
+	for name := range m.plugins {
+		names = append(names, name)
+	}
+	return names
+}
+
 // Register adds a plugin instance to the manager.
 // This should be called before InitializePlugins.
 func (m *Manager) Register(plugin Plugin) error {
@@ -61,6 +92,7 @@ func (m *Manager) InitializePlugins(api EditorAPI) {
 			// Log error but continue initializing other plugins? Or halt?
 			// Let's log and continue for robustness.
 			logger.Debugf("Plugin Manager: ERROR initializing plugin '%s': %v", plugin.Name(), err)
+			m.RecordError(plugin.Name(), err)
 		} else {
 			logger.Debugf("Plugin Manager: Successfully initialized plugin '%s'", plugin.Name())
 		}
@@ -2,10 +2,12 @@
 package plugin
 
 import (
+	"github.com/bethropolis/tide/internal/core/conflict"
 	"github.com/bethropolis/tide/internal/event"
 	"github.com/bethropolis/tide/internal/theme"
 	"github.com/bethropolis/tide/internal/tui"
 	"github.com/bethropolis/tide/internal/types"
+	"github.com/bethropolis/tide/internal/version"
 	"github.com/gdamore/tcell/v2"
 )
 
@@ -13,6 +15,35 @@ import (
 // It takes arguments (e.g., from user input) and returns an error.
 type CommandFunc func(args []string) error
 
+// Geometry describes the terminal and the active editor's view, in screen
+// cells and buffer lines, as of the frame being drawn.
+type Geometry struct {
+	ScreenWidth  int
+	ScreenHeight int
+	Editor       tui.Rect // Area holding the gutter and text (excludes tab and status bars)
+	GutterWidth  int      // Columns taken by line numbers at the left of Editor
+	ViewportY    int      // First visible buffer line
+	ViewportX    int      // First visible visual column
+	LineCount    int      // Lines in the active buffer
+	Cursor       types.Position
+}
+
+// ScreenRow returns the screen row showing buffer line, and false when the
+// line is scrolled out of view.
+func (g Geometry) ScreenRow(line int) (int, bool) {
+	row := g.Editor.Y + line - g.ViewportY
+	return row, line >= g.ViewportY && row < g.Editor.Y+g.Editor.Height
+}
+
+// DrawHook paints plugin decorations (scroll markers, annotations, popups
+// built with tui.Window) after the editor has drawn a frame. screen is
+// clipped to geo.Editor, so hooks cannot draw over the bars or overlays.
+// Hooks run on the draw loop and should return quickly.
+type DrawHook func(screen tcell.Screen, geo Geometry)
+
+// DrawHookID identifies a registered draw hook for removal.
+type DrawHookID int
+
 // EditorAPI defines the methods plugins can use to interact with the editor core.
 // This acts as a controlled interface, preventing plugins from accessing everything.
 type EditorAPI interface {
@@ -28,31 +59,64 @@ type EditorAPI interface {
 	// --- Buffer Modification ---
 	// Use with caution! Ensure plugins don't corrupt state.
 	InsertText(pos types.Position, text []byte) error
+	InsertAtCursor(text string) error                                   // Undoable insert; the cursor ends up after the text
+	ReplaceSelection(transform func(text string) (string, error)) error // Undoable rewrite of the visual selection
+	// ReplaceSelectionOrBuffer rewrites the selection, or the whole buffer when
+	// nothing is selected, as one undoable change; a rewritten buffer keeps
+	// the cursor and viewport on the same code. It returns the start of the
+	// rewritten region so callers can map errors to buffer positions.
+	ReplaceSelectionOrBuffer(transform func(text string) (string, error)) (types.Position, error)
 	DeleteRange(start, end types.Position) error
-	SaveBuffer(filePath ...string) error                                                                  // Save buffer to file with optional path
-	Replace(pattern, replacement string, global, caseInsensitive bool) (int, error)                       // Replace on current line
-	ReplaceAll(pattern, replacement string, caseInsensitive bool) (int, error)                            // :%s – replace across entire buffer
-	ReplaceInRange(pattern, replacement string, startLine, endLine int, caseInsensitive bool) (int, error) // :'<,'>s – replace within line range
+	// RunRepeatable runs fn as one change: the edits it makes undo together
+	// with one "u", and "." in normal mode runs fn again, at the cursor of
+	// that time. name describes the change in error messages.
+	RunRepeatable(name string, fn func() error) error
+	SaveBuffer(filePath ...string) error                                                                         // Save buffer to file with optional path
+	Replace(pattern, replacement string, global, caseInsensitive bool) (int, error)                              // Replace on current line
+	ReplaceAll(pattern, replacement string, caseInsensitive bool) (int, error)                                   // :%s – replace across entire buffer
+	ReplaceInRange(pattern, replacement string, startLine, endLine int, caseInsensitive bool) (int, error)       // :'<,'>s – replace within line range
+	ReplaceLines(pattern, replacement string, startLine, endLine int, global, caseInsensitive bool) (int, error) // :N,Ms – replace the first (or every, if global) match on each line of a range
+	ProjectReplace(pattern, replacement string, caseInsensitive bool) error                                      // :S – preview and replace across the project
+	Retab() (int, error)                                                                                         // :retab – convert indentation to tabs, or spaces with expand_tab
+	RenameSymbol(newName string) error                                                                           // :lsprename – preview and rename via the language server
 
 	// --- Cursor & Viewport ---
 	GetCursor() types.Position
 	SetCursor(pos types.Position) // Will clamp and scroll
 	GetViewport() (y, x int)      // Get ViewportY, ViewportX
+	GetGeometry() Geometry        // Screen size and the active view's placement
 	// SetViewport(y, x int)? // Maybe less common for plugins to directly set viewport
 
+	// --- Drawing ---
+	AddDrawHook(hook DrawHook) DrawHookID // Paint on top of each frame until removed
+	RemoveDrawHook(id DrawHookID)
+
 	// --- Event Bus Interaction ---
 	DispatchEvent(eventType event.Type, data interface{})
 	SubscribeEvent(eventType event.Type, handler event.Handler) event.SubscriptionID
 	UnsubscribeEvent(eventType event.Type, id event.SubscriptionID)
 
 	// --- Command Registration ---
-	RegisterCommand(name string, cmdFunc CommandFunc) error // Allow plugins to expose commands
+	RegisterCommand(name string, cmdFunc CommandFunc) error       // Allow plugins to expose commands
+	SetCommandCompletion(name string, candidates func() []string) // Tab candidates for a command's first argument
+	AddHistory(kind, line string)                                 // Record a line in the "command", "search" or "expr" history
+	History(kind string) []string                                 // Lines of a history, oldest first
+
+	// --- Key Mappings ---
+	// Keys are written as in :map ("jk", "<leader>w", "<C-s>", ":w<CR>"), and
+	// modes as the letters n (normal), v (visual), o (operator-pending), i
+	// (insert) and c (command line); "" means normal, visual and
+	// operator-pending, "!" insert and command line.
+	MapKeys(modes, lhs, rhs string, noremap bool, source string) error // Make lhs type rhs; source is shown by :verbose map
+	UnmapKeys(modes, lhs string) error
+	ShowMappings(modes, lhs string, verbose bool) error // List the mappings starting with lhs (:map)
 
 	// --- Status Bar ---
 	SetStatusMessage(format string, args ...interface{}) // Show temporary messages
 
 	// --- Picker / Overlay ---
 	ShowPicker(title string, items []tui.PickerItem, onSelect func(val string), onCancel func()) // Launch a selection overlay
+	ShowConfirm(title string, message []string, choices []tui.ConfirmChoice)                     // Launch a modal confirmation dialog
 
 	// --- Theme Access ---
 	GetThemeStyle(styleName string) tcell.Style // Get a style from the active theme
@@ -60,6 +124,14 @@ type EditorAPI interface {
 	GetTheme() *theme.Theme
 	ListThemes() []string
 
+	// --- Concurrency ---
+	// Plugin callbacks run on the event loop and block input while they do.
+	// Slow work (I/O, external processes) belongs in Go; its results are
+	// applied to the editor through Schedule, since the other API methods
+	// are not safe to call from other goroutines.
+	Go(fn func())       // Run fn on a new goroutine; panics are logged, not fatal
+	Schedule(fn func()) // Run fn on the event loop soon; safe to call from any goroutine
+
 	// --- Application Control ---
 	RequestQuit(force bool) // Signal the application to quit
 
@@ -69,6 +141,66 @@ type EditorAPI interface {
 	PrevBuffer()
 	CloseBuffer() error
 	ForceCloseBuffer()
+	ListBuffers()                     // Pick an open buffer to switch to (:ls)
+	SwitchBuffer(n int) error         // Switch to buffer n, counting from 1 (:b N)
+	RenameFile(newPath string) error  // Rename the current buffer's file on disk
+	DeleteFile(toTrash bool) error    // Delete (or trash) the current buffer's file
+	RevertToSaved() error             // Discard unsaved changes as one undoable edit (:revert)
+	ReloadBuffer() error              // Read the file again as one undoable edit, discarding changes (:e!)
+	Jump(forward bool) error          // Go back (Ctrl+O) or forward (Ctrl+I) through the jump list
+	SetMark(name rune) error          // Set the named mark on the cursor and remember it for the file (ma)
+	JumpToMark(name rune) error       // Move to the named mark ('a)
+	ListMarks()                       // Pick a mark of the current buffer to go to (:marks)
+	DeleteMarks(names string) error   // Remove the marks named by the letters of names, or all with "!" (:delmarks)
+	MakeView() error                  // Save the current buffer's cursor and scroll position (:mkview)
+	LoadView() error                  // Restore the view saved by MakeView (:loadview)
+	ToggleTableView() (bool, error)   // Show a CSV/TSV buffer as aligned columns or plain text (:table)
+	ToggleTableHeader() bool          // Pin or unpin the table header row (:table header)
+	ToggleInlineBlame() bool          // Show or hide git blame for the cursor line (:blame)
+	ToggleTail() (bool, error)        // Follow the current file as it grows, like tail -f, or stop (:tail)
+	StageHunk() error                 // Stage the git change under the cursor (:hunk stage)
+	RevertHunk() error                // Restore the git change under the cursor from HEAD (:hunk revert)
+	PreviewHunk() error               // Show the git change under the cursor in a popup (:hunk preview)
+	DiffSaved() error                 // Mark unsaved changes in the gutter and list them (:diffsaved)
+	StopDiffSaved()                   // Remove the unsaved change marks (:diffsaved off)
+	RevertSavedHunk() error           // Restore the unsaved change under the cursor from disk (:diffsaved revert)
+	JumpSavedHunk(forward bool) error // Move to the next or previous unsaved change (:diffsaved next, prev)
+
+	// --- Shared Editing (experimental) ---
+	CollabHost(addr string) error // Share the current buffer, waiting on addr ("" for 127.0.0.1:7878) for a peer (:collab host)
+	CollabJoin(addr string) error // Replace the current buffer with a shared one and edit it together (:collab join)
+	StopCollab()                  // Leave the session, keeping the text (:collab stop)
+	CollabStatus() string         // Describes the session, "" outside of one
+
+	// --- Windows ---
+	Split(vertical bool, filePath string) error // Split the focused window, showing filePath or the current buffer (:split, :vsplit)
+	WindowCommand(c rune) error                 // Run the window command typed after Ctrl+W (:wincmd)
+	WindowCount() int                           // Number of split windows
+
+	ResolveConflict(choice conflict.Choice) error // Keep one or both sides of the merge conflict under the cursor (:conflict)
+
+	CheckHealth()                  // Report configuration, tool, grammar and plugin problems in a read-only buffer (:checkhealth)
+	ShowVersion()                  // Show build info, enabled features, plugins and grammars in a read-only buffer (:version)
+	ShowMan(topic string) error    // Show a manual page in a read-only buffer (:man)
+	ShowGoDoc(symbol string) error // Show go doc output for a package or symbol in a read-only buffer (:godoc)
+
+	// Version describes the running build, so plugins can gate behavior on
+	// it (see version.AtLeast).
+	Version() version.Info
+
+	// --- Quickfix List ---
+	SetQuickfix(items []types.QuickfixItem) // Replace the quickfix list; nil clears it
+	OpenQuickfix() error                    // Pick an entry from the quickfix list (:copen)
+	QuickfixStep(delta int) error           // Jump to the next/previous entry (:cnext/:cprev)
+
+	// --- Project Index ---
+	ShowSymbols() error           // Pick a definition from the project's symbols (:symbols)
+	GoToSymbol(name string) error // Jump to the definition of name, or of the word under the cursor if empty (:symbol)
+
+	// --- Scripts ---
+	StartRecording(path string) error // Record commands, typed text and actions to a script at path (:record)
+	StopRecording() (string, error)   // Finish the script being recorded and return its path
+	SourceScript(path string) error   // Replay the script at path (:source)
 
 	// --- Configuration ---
 	// GetPluginConfigValue retrieves a configuration value for a specific plugin.
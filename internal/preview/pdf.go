@@ -0,0 +1,451 @@
+package preview
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+var (
+	pageRe      = regexp.MustCompile(`/Type\s*/Page\b`)
+	streamRe    = regexp.MustCompile(`>>\s*stream\r?\n`)
+	blankLineRe = regexp.MustCompile(`\n{3,}`)
+)
+
+// renderPDF describes a PDF and gives the text of its content streams, in
+// the order they appear in the file. Only what the common text operators
+// show is found: text in fonts with their own encodings comes out garbled
+// or not at all, and scanned pages have none.
+func renderPDF(data []byte) []byte {
+	var out bytes.Buffer
+	budget := &inflater{left: maxInflated}
+	pages := pageCount(data, budget)
+	fmt.Fprintf(&out, "PDF document, %d page%s (read-only preview of its text)\n\n", pages, plural(pages))
+	if bytes.Contains(data, []byte("/Encrypt")) {
+		out.WriteString("The document is encrypted; its text cannot be shown.\n")
+		return out.Bytes()
+	}
+	text := pdfText(data, budget)
+	if text == "" {
+		out.WriteString("No text was found; the pages may be scanned images.\n")
+		return out.Bytes()
+	}
+	out.WriteString(text)
+	out.WriteByte('\n')
+	return out.Bytes()
+}
+
+// plural returns "s" unless n is 1.
+func plural(n int) string {
+	if n == 1 {
+		return ""
+	}
+	return "s"
+}
+
+// PDFText extracts the text shown by the content streams of a PDF, a line
+// for each line of text. Streams compressed with other than FlateDecode,
+// and those holding images, fonts or objects, are skipped, as is whatever
+// would inflate past maxInflated in all.
+func PDFText(data []byte) string {
+	return pdfText(data, &inflater{left: maxInflated})
+}
+
+// pdfText is PDFText inflating streams with f.
+func pdfText(data []byte, f *inflater) string {
+	var text strings.Builder
+	eachStream(data, func(dict string, stream []byte) {
+		for _, skip := range []string{"/Image", "/FontFile", "/Length1", "/XRef", "/ObjStm", "/Metadata"} {
+			if strings.Contains(dict, skip) {
+				return
+			}
+		}
+		if content, ok := f.inflate(dict, stream); ok {
+			text.WriteString(contentText(content))
+			text.WriteByte('\n')
+		}
+	})
+	s := strings.TrimSpace(text.String())
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		lines = append(lines, strings.TrimRight(line, " "))
+	}
+	return blankLineRe.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+}
+
+// pageCount counts the page objects of a PDF, including those packed in
+// object streams, inflating them with f.
+func pageCount(data []byte, f *inflater) int {
+	n := len(pageRe.FindAll(data, -1))
+	eachStream(data, func(dict string, stream []byte) {
+		if !strings.Contains(dict, "/ObjStm") {
+			return
+		}
+		if objects, ok := f.inflate(dict, stream); ok {
+			n += len(pageRe.FindAll(objects, -1))
+		}
+	})
+	return n
+}
+
+// eachStream calls fn with the dictionary and the data of each stream in a
+// PDF, in the order they appear.
+func eachStream(data []byte, fn func(dict string, stream []byte)) {
+	for _, loc := range streamRe.FindAllIndex(data, -1) {
+		dictStart := bytes.LastIndex(data[:loc[0]], []byte("obj"))
+		if dictStart < 0 {
+			continue
+		}
+		end := bytes.Index(data[loc[1]:], []byte("endstream"))
+		if end < 0 {
+			return
+		}
+		fn(string(data[dictStart:loc[0]]), data[loc[1]:loc[1]+end])
+	}
+}
+
+// maxInflated bounds what the streams of one PDF inflate to in all, so a
+// small file crafted to inflate enormously (a zip bomb) cannot use up the
+// memory.
+const maxInflated = maxFileSize
+
+// inflater inflates the streams of a PDF within a budget.
+type inflater struct {
+	left int64 // Bytes the streams may still inflate to
+}
+
+// inflate returns the data of a stream that is not compressed or only with
+// FlateDecode, cut short where the budget runs out.
+func (f *inflater) inflate(dict string, stream []byte) ([]byte, bool) {
+	if !strings.Contains(dict, "/Filter") {
+		return stream, true
+	}
+	if !strings.Contains(dict, "/FlateDecode") || hasOtherFilter(dict) {
+		return nil, false
+	}
+	r, err := zlib.NewReader(bytes.NewReader(stream))
+	if err != nil {
+		return nil, false
+	}
+	// A stream cut short still gives the text before the damage
+	content, _ := io.ReadAll(io.LimitReader(r, f.left))
+	f.left -= int64(len(content))
+	return content, len(content) > 0
+}
+
+// hasOtherFilter reports whether dict names a filter besides FlateDecode.
+func hasOtherFilter(dict string) bool {
+	for _, f := range []string{"/ASCII85Decode", "/ASCIIHexDecode", "/LZWDecode", "/RunLengthDecode", "/DCTDecode", "/JPXDecode", "/CCITTFaxDecode", "/JBIG2Decode"} {
+		if strings.Contains(dict, f) {
+			return true
+		}
+	}
+	return false
+}
+
+// contentText returns the text a content stream shows with the Tj, TJ, '
+// and " operators, starting a line for T*, a move down with Td or TD, or a
+// new line position with Tm.
+func contentText(content []byte) string {
+	var out strings.Builder
+	newline := func() {
+		if s := out.String(); s != "" && !strings.HasSuffix(s, "\n") {
+			out.WriteByte('\n')
+		}
+	}
+	space := func() {
+		if s := out.String(); s != "" && !strings.HasSuffix(s, " ") && !strings.HasSuffix(s, "\n") {
+			out.WriteByte(' ')
+		}
+	}
+
+	lex := &lexer{data: content}
+	var operands []token
+	lastY, haveY := 0.0, false
+	for {
+		tok, ok := lex.next()
+		if !ok {
+			break
+		}
+		if tok.kind != opToken {
+			operands = append(operands, tok)
+			continue
+		}
+		switch tok.text {
+		case "Tj":
+			if s, ok := lastString(operands); ok {
+				out.WriteString(s)
+			}
+		case "'", `"`:
+			newline()
+			if s, ok := lastString(operands); ok {
+				out.WriteString(s)
+			}
+		case "TJ":
+			if len(operands) > 0 && operands[len(operands)-1].kind == arrayToken {
+				for _, el := range operands[len(operands)-1].array {
+					switch el.kind {
+					case stringToken:
+						out.WriteString(decodeText(el.text))
+					case numberToken:
+						// A wide negative adjustment stands for a space
+						if el.number < -200 {
+							space()
+						}
+					}
+				}
+			}
+		case "T*":
+			newline()
+		case "Td", "TD":
+			if len(operands) >= 2 && operands[len(operands)-1].kind == numberToken {
+				if operands[len(operands)-1].number != 0 {
+					newline()
+				} else if operands[len(operands)-2].number > 0 {
+					space()
+				}
+			}
+		case "Tm":
+			if len(operands) >= 6 && operands[len(operands)-1].kind == numberToken {
+				y := operands[len(operands)-1].number
+				if haveY && y != lastY {
+					newline()
+				} else {
+					space()
+				}
+				lastY, haveY = y, true
+			}
+		case "ET":
+			space()
+		case "ID":
+			lex.skipInlineImage()
+		}
+		operands = operands[:0]
+	}
+	return out.String()
+}
+
+// lastString returns the string operand last given.
+func lastString(operands []token) (string, bool) {
+	if len(operands) == 0 || operands[len(operands)-1].kind != stringToken {
+		return "", false
+	}
+	return decodeText(operands[len(operands)-1].text), true
+}
+
+// decodeText turns the bytes of a PDF string into text: UTF-16 when it
+// starts with a byte order mark, else one character per byte. Control
+// characters, which fonts with their own encodings produce, are dropped.
+func decodeText(s string) string {
+	var runes []rune
+	if strings.HasPrefix(s, "\xfe\xff") {
+		var units []uint16
+		for i := 2; i+1 < len(s); i += 2 {
+			units = append(units, uint16(s[i])<<8|uint16(s[i+1]))
+		}
+		runes = utf16.Decode(units)
+	} else {
+		for i := 0; i < len(s); i++ {
+			runes = append(runes, rune(s[i]))
+		}
+	}
+	var out strings.Builder
+	for _, r := range runes {
+		if r >= ' ' && r != 0x7f {
+			out.WriteRune(r)
+		}
+	}
+	return out.String()
+}
+
+// tokenKind says what a token of a content stream is.
+type tokenKind int
+
+const (
+	opToken tokenKind = iota
+	numberToken
+	stringToken
+	arrayToken
+	otherToken // Names, dictionaries and anything else
+)
+
+// token is an operand or operator of a content stream.
+type token struct {
+	kind   tokenKind
+	text   string // Operator, or string bytes
+	number float64
+	array  []token
+}
+
+// lexer splits a content stream into tokens.
+type lexer struct {
+	data []byte
+	pos  int
+}
+
+// isDelimiter reports whether c ends a regular token.
+func isDelimiter(c byte) bool {
+	return isSpace(c) || strings.IndexByte("()<>[]{}/%", c) >= 0
+}
+
+// isSpace reports whether c is PDF white space.
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\f' || c == 0
+}
+
+// next returns the next token, and false at the end of the stream.
+func (l *lexer) next() (token, bool) {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		switch {
+		case isSpace(c):
+			l.pos++
+		case c == '%':
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+		case c == '(':
+			return token{kind: stringToken, text: l.literal()}, true
+		case c == '<' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '<', c == '>' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '>':
+			l.pos += 2
+			return token{kind: otherToken}, true
+		case c == '<':
+			return token{kind: stringToken, text: l.hex()}, true
+		case c == '[':
+			l.pos++
+			var array []token
+			for {
+				tok, ok := l.next()
+				if !ok || tok.kind == opToken && tok.text == "]" {
+					break
+				}
+				array = append(array, tok)
+			}
+			return token{kind: arrayToken, array: array}, true
+		case c == ']':
+			l.pos++
+			return token{kind: opToken, text: "]"}, true
+		case c == '/':
+			l.pos++
+			l.regular()
+			return token{kind: otherToken}, true
+		case strings.IndexByte("{}>)", c) >= 0:
+			l.pos++
+		default:
+			word := l.regular()
+			if n, err := strconv.ParseFloat(word, 64); err == nil {
+				return token{kind: numberToken, number: n}, true
+			}
+			return token{kind: opToken, text: word}, true
+		}
+	}
+	return token{}, false
+}
+
+// regular reads a regular token.
+func (l *lexer) regular() string {
+	start := l.pos
+	for l.pos < len(l.data) && !isDelimiter(l.data[l.pos]) {
+		l.pos++
+	}
+	return string(l.data[start:l.pos])
+}
+
+// literal reads a string in parentheses, which may nest, with its escapes.
+func (l *lexer) literal() string {
+	l.pos++ // (
+	var out []byte
+	depth := 1
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			if depth--; depth == 0 {
+				return string(out)
+			}
+		case '\\':
+			if l.pos >= len(l.data) {
+				return string(out)
+			}
+			e := l.data[l.pos]
+			l.pos++
+			switch e {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r', '\n':
+				// A line break after a backslash continues the string
+				if e == '\r' && l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+				continue
+			default:
+				if e >= '0' && e <= '7' {
+					n := int(e - '0')
+					for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+						n = n*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					c = byte(n)
+				} else {
+					c = e
+				}
+			}
+		}
+		out = append(out, c)
+	}
+	return string(out)
+}
+
+// hex reads a string of hex digits in angle brackets.
+func (l *lexer) hex() string {
+	l.pos++ // <
+	var digits []byte
+	for l.pos < len(l.data) && l.data[l.pos] != '>' {
+		if c := l.data[l.pos]; !isSpace(c) {
+			digits = append(digits, c)
+		}
+		l.pos++
+	}
+	l.pos++ // >
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	out := make([]byte, 0, len(digits)/2)
+	for i := 0; i+1 < len(digits); i += 2 {
+		n, err := strconv.ParseUint(string(digits[i:i+2]), 16, 8)
+		if err != nil {
+			return string(out)
+		}
+		out = append(out, byte(n))
+	}
+	return string(out)
+}
+
+// skipInlineImage skips the data of an inline image, after ID up to EI.
+func (l *lexer) skipInlineImage() {
+	for l.pos+1 < len(l.data) {
+		if l.data[l.pos] == 'E' && l.data[l.pos+1] == 'I' && l.pos > 0 && isSpace(l.data[l.pos-1]) &&
+			(l.pos+2 == len(l.data) || isDelimiter(l.data[l.pos+2])) {
+			l.pos += 2
+			return
+		}
+		l.pos++
+	}
+	l.pos = len(l.data)
+}
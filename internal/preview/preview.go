@@ -0,0 +1,151 @@
+// Package preview turns files that are not text into text that can be read
+// in a buffer: images become a thumbnail drawn with characters and PDFs the
+// text they contain, instead of their raw bytes.
+//
+// Terminal graphics (sixel, the kitty protocol) are not used: the editor
+// draws through a grid of character cells, which cannot carry them.
+package preview
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/gif" // Register the decoders image.Decode uses
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Kind is the kind of preview a file gets.
+type Kind int
+
+const (
+	None  Kind = iota // Text, or a format without a preview
+	Image             // PNG, JPEG or GIF
+	PDF
+)
+
+// maxFileSize is the largest file Render will read.
+const maxFileSize = 64 << 20
+
+// sniffLen is how much of a file Detect looks at.
+const sniffLen = 512
+
+// Detect returns the kind of preview the file at path gets, from its
+// content rather than its name. It returns None when the file cannot be
+// read.
+func Detect(path string) Kind {
+	f, err := os.Open(path)
+	if err != nil {
+		return None
+	}
+	defer f.Close()
+	head := make([]byte, sniffLen)
+	n, _ := io.ReadFull(f, head)
+	return detect(head[:n])
+}
+
+// detect returns the kind of preview for a file starting with head.
+func detect(head []byte) Kind {
+	switch http.DetectContentType(head) {
+	case "image/png", "image/jpeg", "image/gif":
+		return Image
+	case "application/pdf":
+		return PDF
+	}
+	return None
+}
+
+// Render returns the preview of the file at path: a line saying what the
+// file is, then the thumbnail or text, at most width columns wide for an
+// image.
+func Render(path string, width int) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() > maxFileSize {
+		return nil, fmt.Errorf("%s is larger than %d MiB", path, maxFileSize>>20)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	switch detect(data[:min(len(data), sniffLen)]) {
+	case Image:
+		return renderImage(data, width)
+	case PDF:
+		return renderPDF(data), nil
+	}
+	return nil, fmt.Errorf("%s: no preview for this kind of file", path)
+}
+
+// renderImage describes an image and draws its thumbnail.
+func renderImage(data []byte, width int) ([]byte, error) {
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode image: %w", err)
+	}
+	size := img.Bounds().Size()
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "%s image, %dx%d pixels (read-only preview)\n\n", strings.ToUpper(format), size.X, size.Y)
+	for _, line := range Thumbnail(img, width) {
+		out.WriteString(line)
+		out.WriteByte('\n')
+	}
+	return out.Bytes(), nil
+}
+
+// ramp holds the characters of a thumbnail from dark to light.
+const ramp = " .:-=+*#%@"
+
+// Thumbnail draws img at most cols characters wide, one character for each
+// box of pixels, denser for lighter boxes so that it reads on a dark
+// background. A cell is about twice as tall as it is wide, so each row
+// stands for twice as many pixels as each column. Transparent pixels count
+// as dark.
+func Thumbnail(img image.Image, cols int) []string {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w == 0 || h == 0 || cols < 1 {
+		return nil
+	}
+	cols = min(cols, w)
+	rows := min(max(cols*h/w/2, 1), h)
+
+	lines := make([]string, rows)
+	for row := 0; row < rows; row++ {
+		y0, y1 := b.Min.Y+row*h/rows, b.Min.Y+(row+1)*h/rows
+		var line strings.Builder
+		for col := 0; col < cols; col++ {
+			x0, x1 := b.Min.X+col*w/cols, b.Min.X+(col+1)*w/cols
+			lum := boxLuminance(img, x0, y0, x1, y1)
+			line.WriteByte(ramp[int(lum*float64(len(ramp)-1)+0.5)])
+		}
+		lines[row] = strings.TrimRight(line.String(), " ")
+	}
+	return lines
+}
+
+// boxLuminance returns the mean luminance, from 0 to 1, of the pixels in
+// the box, sampling at most 4x4 of them in a large one.
+func boxLuminance(img image.Image, x0, y0, x1, y1 int) float64 {
+	xStep, yStep := max((x1-x0)/4, 1), max((y1-y0)/4, 1)
+	var sum float64
+	n := 0
+	for y := y0; y < y1; y += yStep {
+		for x := x0; x < x1; x += xStep {
+			// Premultiplied by alpha, so transparency darkens
+			r, g, b, _ := img.At(x, y).RGBA()
+			sum += (0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)) / 0xffff
+			n++
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / float64(n)
+}
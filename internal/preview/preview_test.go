@@ -0,0 +1,99 @@
+package preview
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"reflect"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	var pngData bytes.Buffer
+	if err := png.Encode(&pngData, image.NewGray(image.Rect(0, 0, 2, 2))); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		head []byte
+		want Kind
+	}{
+		{pngData.Bytes(), Image},
+		{[]byte("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n"), PDF},
+		{[]byte("package main\n"), None},
+		{[]byte{0x7f, 'E', 'L', 'F', 2, 1, 1}, None},
+	}
+	for _, tt := range tests {
+		if got := detect(tt.head); got != tt.want {
+			t.Errorf("detect(%q) = %v, want %v", tt.head[:min(len(tt.head), 8)], got, tt.want)
+		}
+	}
+}
+
+func TestThumbnail(t *testing.T) {
+	// White on the left, black on the right, transparent at the bottom
+	img := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			c := color.NRGBA{A: 255}
+			if x < 4 {
+				c = color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+			}
+			if y >= 4 {
+				c.A = 0
+			}
+			img.Set(x, y, c)
+		}
+	}
+	got := Thumbnail(img, 4)
+	want := []string{"@@", ""}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Thumbnail() = %q, want %q", got, want)
+	}
+	if got := Thumbnail(img, 100); len(got) != 4 || len(got[0]) != 4 {
+		t.Errorf("Thumbnail(100) = %q, want 4 rows of 8 columns at most", got)
+	}
+}
+
+func TestPDFText(t *testing.T) {
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	zw.Write([]byte("BT /F1 12 Tf 72 700 Td [(Sec)-20(ond)-300(page)] TJ 0 -14 Td <FEFF00E9> Tj ET"))
+	zw.Close()
+
+	var pdf bytes.Buffer
+	pdf.WriteString("%PDF-1.4\n1 0 obj\n<< /Type /Pages /Count 2 >>\nendobj\n")
+	pdf.WriteString("2 0 obj\n<< /Type /Page /Parent 1 0 R >>\nendobj\n3 0 obj\n<< /Type /Page >>\nendobj\n")
+	first := "BT /F1 12 Tf 72 700 Td (Hello \\(world\\)) Tj T* (second line) Tj ET"
+	fmt.Fprintf(&pdf, "4 0 obj\n<< /Length %d >>\nstream\n%s\nendstream\nendobj\n", len(first), first)
+	fmt.Fprintf(&pdf, "5 0 obj\n<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream\nendobj\n", compressed.Len(), compressed.Bytes())
+	fmt.Fprintf(&pdf, "6 0 obj\n<< /Subtype /Image /Length 3 >>\nstream\n(x)\nendstream\nendobj\n")
+
+	want := "Hello (world)\nsecond line\nSecond page\né"
+	if got := PDFText(pdf.Bytes()); got != want {
+		t.Errorf("PDFText() = %q, want %q", got, want)
+	}
+	if got := renderPDF(pdf.Bytes()); !bytes.HasPrefix(got, []byte("PDF document, 2 pages")) {
+		t.Errorf("renderPDF() = %q, want it to start with the page count", got)
+	}
+}
+
+// TestInflateBudget stops inflating streams once they have inflated to the
+// budget in all.
+func TestInflateBudget(t *testing.T) {
+	var bomb bytes.Buffer
+	zw := zlib.NewWriter(&bomb)
+	zw.Write(make([]byte, 1<<20))
+	zw.Close()
+
+	f := &inflater{left: 1000}
+	dict := "<< /Filter /FlateDecode >>"
+	if got, ok := f.inflate(dict, bomb.Bytes()); !ok || len(got) != 1000 {
+		t.Errorf("first stream inflated to %d bytes, want 1000", len(got))
+	}
+	if got, ok := f.inflate(dict, bomb.Bytes()); ok || len(got) != 0 {
+		t.Errorf("stream past the budget inflated to %d bytes", len(got))
+	}
+}
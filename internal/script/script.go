@@ -0,0 +1,207 @@
+// Package script reads and writes editing scripts: an interactive session
+// recorded as high-level steps (:record), which can be replayed in the
+// editor (:source) or without a terminal (tide --script) to apply the same
+// transformation to other files.
+//
+// A script has one step per line:
+//
+//	# A comment; blank lines are ignored too
+//	:%s/foo/bar/g      An Ex command, as typed after ':'
+//	insert "text\n"    Text typed in insert mode, as a Go string literal
+//	action save        A named action (see [keybindings]), by its key
+//	action move_down 3 The same, done 3 times in a row
+//	keys dw<Esc>       Keys in :map notation, as typed in normal mode
+package script
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Kind says what a Step does.
+type Kind int
+
+const (
+	Command Kind = iota // Text is an Ex command line without the ':'
+	Insert              // Text is typed in insert mode
+	Action              // Text is the config name of an action
+	Keys                // Text is a key sequence in :map notation
+)
+
+// Step is one line of a script.
+type Step struct {
+	Kind  Kind
+	Text  string
+	Count int // Times an Action is done in a row; 0 means once
+	Line  int // 1-based line in the file it was read from, 0 if recorded
+}
+
+// String writes the step as a script line.
+func (s Step) String() string {
+	switch s.Kind {
+	case Command:
+		return ":" + s.Text
+	case Insert:
+		return "insert " + strconv.Quote(s.Text)
+	case Action:
+		if s.Count > 1 {
+			return fmt.Sprintf("action %s %d", s.Text, s.Count)
+		}
+		return "action " + s.Text
+	default:
+		return "keys " + s.Text
+	}
+}
+
+// Parse reads the steps of a script. Errors name the line at fault.
+func Parse(r io.Reader) ([]Step, error) {
+	var steps []Step
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		// Only a command keeps its trailing spaces, which may matter to it
+		line := strings.TrimLeft(strings.TrimSuffix(scanner.Text(), "\r"), " \t")
+		if strings.TrimSpace(line) == "" || line[0] == '#' {
+			continue
+		}
+		step, err := parseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		step.Line = n
+		steps = append(steps, step)
+	}
+	return steps, scanner.Err()
+}
+
+// parseLine parses one step.
+func parseLine(line string) (Step, error) {
+	if line[0] == ':' {
+		return Step{Kind: Command, Text: line[1:]}, nil
+	}
+	word, rest, _ := strings.Cut(strings.TrimRight(line, " \t"), " ")
+	rest = strings.TrimSpace(rest)
+	switch {
+	case word != "insert" && word != "action" && word != "keys":
+		return Step{}, fmt.Errorf("unknown step %q", word)
+	case rest == "":
+		return Step{}, fmt.Errorf("%s: missing argument", word)
+	}
+	switch word {
+	case "insert":
+		text, err := strconv.Unquote(rest)
+		if err != nil {
+			return Step{}, fmt.Errorf("insert: not a quoted string: %s", rest)
+		}
+		return Step{Kind: Insert, Text: text}, nil
+	case "action":
+		name, count, counted := strings.Cut(rest, " ")
+		if !counted {
+			return Step{Kind: Action, Text: name}, nil
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(count))
+		if err != nil || n < 1 {
+			return Step{}, fmt.Errorf("action: not a count: %s", count)
+		}
+		return Step{Kind: Action, Text: name, Count: n}, nil
+	}
+	return Step{Kind: Keys, Text: rest}, nil
+}
+
+// Load reads the script at path.
+func Load(path string) ([]Step, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	steps, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return steps, nil
+}
+
+// Recorder writes steps to a script file as they happen. Text typed in
+// insert mode and keys typed in a row are joined into one step, and so is
+// an action done again, so the step being built is only written when a
+// different one follows.
+type Recorder struct {
+	path    string
+	w       *bufio.Writer
+	f       *os.File
+	pending *Step
+}
+
+// Create starts a script at path, replacing any file there.
+func Create(path string) (*Recorder, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	r := &Recorder{path: path, f: f, w: bufio.NewWriter(f)}
+	fmt.Fprintln(r.w, "# Tide script; replay with :source or tide --script")
+	return r, nil
+}
+
+// Path returns the file being written.
+func (r *Recorder) Path() string {
+	return r.path
+}
+
+// Add records a step.
+func (r *Recorder) Add(s Step) {
+	if s.Kind == Action && s.Count == 0 {
+		s.Count = 1
+	}
+	if p := r.pending; p != nil && p.Kind == s.Kind {
+		switch {
+		case s.Kind == Insert || s.Kind == Keys:
+			p.Text += s.Text
+			return
+		case s.Kind == Action && s.Text == p.Text:
+			p.Count += s.Count
+			return
+		}
+	}
+	r.flush()
+	r.pending = &s
+}
+
+// Backspace takes the last character off text being inserted and reports
+// whether there was any; when there was not, the caller records the
+// deletion as an action.
+func (r *Recorder) Backspace() bool {
+	if r.pending == nil || r.pending.Kind != Insert || r.pending.Text == "" {
+		return false
+	}
+	_, size := utf8.DecodeLastRuneInString(r.pending.Text)
+	r.pending.Text = r.pending.Text[:len(r.pending.Text)-size]
+	if r.pending.Text == "" {
+		r.pending = nil
+	}
+	return true
+}
+
+// flush writes the step being built.
+func (r *Recorder) flush() {
+	if r.pending != nil {
+		fmt.Fprintln(r.w, r.pending.String())
+		r.pending = nil
+	}
+}
+
+// Close writes what is left and closes the file.
+func (r *Recorder) Close() error {
+	r.flush()
+	err := r.w.Flush()
+	if closeErr := r.f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,78 @@
+package script
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	src := "# header\n\n:%s/a/b/g \n  insert \"x\\ty\\n\"\naction save\r\naction move_down 3\nkeys dw<Esc>\n"
+	got, err := Parse(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Step{
+		{Kind: Command, Text: "%s/a/b/g ", Line: 3},
+		{Kind: Insert, Text: "x\ty\n", Line: 4},
+		{Kind: Action, Text: "save", Line: 5},
+		{Kind: Action, Text: "move_down", Count: 3, Line: 6},
+		{Kind: Keys, Text: "dw<Esc>", Line: 7},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() = %+v, want %+v", got, want)
+	}
+
+	for _, bad := range []string{"insert unquoted", "keys", "action save twice", "press x"} {
+		if _, err := Parse(strings.NewReader("\n" + bad)); err == nil || !strings.Contains(err.Error(), "line 2") {
+			t.Errorf("Parse(%q) error = %v, want one naming line 2", bad, err)
+		}
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "edit.tide")
+	r, err := Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Add(Step{Kind: Keys, Text: "d"})
+	r.Add(Step{Kind: Keys, Text: "w"})
+	r.Add(Step{Kind: Insert, Text: "héllo"})
+	r.Add(Step{Kind: Insert, Text: "!"})
+	for i := 0; i < 5; i++ {
+		if !r.Backspace() {
+			t.Fatalf("Backspace %d found nothing to take back", i)
+		}
+	}
+	r.Add(Step{Kind: Insert, Text: "i\n"})
+	r.Add(Step{Kind: Command, Text: "w"})
+	if r.Backspace() {
+		t.Error("Backspace after a command took something back")
+	}
+	r.Add(Step{Kind: Action, Text: "move_down"})
+	r.Add(Step{Kind: Action, Text: "move_down"})
+	r.Add(Step{Kind: Action, Text: "save"})
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	steps, err := Parse(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatalf("reading back %q: %v", data, err)
+	}
+	var lines []string
+	for _, s := range steps {
+		lines = append(lines, s.String())
+	}
+	want := []string{"keys dw", `insert "hi\n"`, ":w", "action move_down 2", "action save"}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("recorded %q, want %q", lines, want)
+	}
+}
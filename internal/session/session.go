@@ -0,0 +1,138 @@
+// Package session keeps snapshots of the editor's state (the open files,
+// where the cursor was in each, and the yank registers) so that after a
+// crash or a killed terminal the next start can offer to bring them back.
+// Each running instance writes its own file, named by its process id,
+// every few seconds and removes it when it quits normally; a file whose
+// process is gone was left by an instance that did not.
+//
+// Unsaved text is not part of a snapshot: forced quits back it up (see
+// config.BackupsDirName). Command and search histories are saved as they
+// grow, by cmdhistory.
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Version is bumped whenever State changes incompatibly.
+const Version = 1
+
+// State is one snapshot of an instance.
+type State struct {
+	Version   int        `json:"version"`
+	PID       int        `json:"pid"`
+	Saved     time.Time  `json:"saved"`
+	Dir       string     `json:"dir"`    // Working directory
+	Active    int        `json:"active"` // Index into Buffers of the active one, -1 if none
+	Buffers   []Buffer   `json:"buffers"`
+	Registers []Register `json:"registers"` // Newest first
+}
+
+// Buffer is an open file and the view of it.
+type Buffer struct {
+	Path     string `json:"path"`
+	Line     int    `json:"line"`
+	Col      int    `json:"col"`
+	ViewTop  int    `json:"view_top"`
+	ViewLeft int    `json:"view_left"`
+}
+
+// Register is one entry of the yank ring.
+type Register struct {
+	Text      string `json:"text"`
+	Linewise  bool   `json:"linewise,omitempty"`
+	Blockwise bool   `json:"blockwise,omitempty"`
+}
+
+// Snapshot is a saved State and the file it was read from.
+type Snapshot struct {
+	Path  string
+	State State
+}
+
+// alive reports whether a process is running; tests replace it.
+var alive = func(pid int) bool {
+	p, err := os.FindProcess(pid)
+	return err == nil && p.Signal(syscall.Signal(0)) == nil
+}
+
+// Path returns the snapshot file of process pid in dir.
+func Path(dir string, pid int) string {
+	return filepath.Join(dir, strconv.Itoa(pid)+".json")
+}
+
+// Save writes s to its process's file in dir, through a temporary file so
+// a crash while saving leaves the previous snapshot whole.
+func Save(dir string, s State) error {
+	s.Version = Version
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".session-*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), Path(dir, s.PID))
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// Remove deletes the snapshot of process pid, as on a normal quit.
+func Remove(dir string, pid int) error {
+	err := os.Remove(Path(dir, pid))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// Orphaned returns the snapshots in dir left by instances that are no
+// longer running, newest first. Files that cannot be read, or are of
+// another version, are left out.
+func Orphaned(dir string) []Snapshot {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var snapshots []Snapshot
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(strings.TrimSuffix(entry.Name(), ".json"))
+		if err != nil || !strings.HasSuffix(entry.Name(), ".json") || pid == os.Getpid() || alive(pid) {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var s State
+		if json.Unmarshal(data, &s) != nil || s.Version != Version {
+			continue
+		}
+		snapshots = append(snapshots, Snapshot{Path: path, State: s})
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].State.Saved.After(snapshots[j].State.Saved)
+	})
+	return snapshots
+}
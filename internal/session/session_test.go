@@ -0,0 +1,50 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestOrphaned(t *testing.T) {
+	dir := t.TempDir()
+	running := map[int]bool{200: true}
+	defer func(orig func(int) bool) { alive = orig }(alive)
+	alive = func(pid int) bool { return running[pid] }
+
+	now := time.Now().Truncate(time.Second)
+	for _, s := range []State{
+		{PID: 100, Saved: now.Add(-time.Hour), Buffers: []Buffer{{Path: "/old.go"}}},
+		{PID: 101, Saved: now, Active: 0, Buffers: []Buffer{{Path: "/new.go", Line: 3}}, Registers: []Register{{Text: "x\n", Linewise: true}}},
+		{PID: 200, Saved: now}, // Still running
+		{PID: os.Getpid(), Saved: now},
+	} {
+		if err := Save(dir, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	os.WriteFile(filepath.Join(dir, "300.json"), []byte("{"), 0o600)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("{}"), 0o600)
+
+	got := Orphaned(dir)
+	if len(got) != 2 || got[0].State.PID != 101 || got[1].State.PID != 100 {
+		t.Fatalf("Orphaned() = %+v, want the snapshots of 101 then 100", got)
+	}
+	if s := got[0].State; s.Buffers[0].Line != 3 || !s.Registers[0].Linewise || s.Version != Version {
+		t.Errorf("snapshot read back as %+v", s)
+	}
+	if got[0].Path != Path(dir, 101) {
+		t.Errorf("Path = %q, want %q", got[0].Path, Path(dir, 101))
+	}
+
+	if err := Remove(dir, 101); err != nil {
+		t.Fatal(err)
+	}
+	if err := Remove(dir, 101); err != nil {
+		t.Errorf("removing a missing snapshot: %v", err)
+	}
+	if got := Orphaned(dir); len(got) != 1 || got[0].State.PID != 100 {
+		t.Errorf("Orphaned() after Remove = %+v, want only 100", got)
+	}
+}
@@ -0,0 +1,114 @@
+// Package startuptime records how long each phase of startup takes, for
+// the --startuptime flag. Like Vim's, the report gives for every phase the
+// time since the process started and the time the phase itself took:
+//
+//	times in msec
+//	 clock   self: phase
+//
+//	000.011  000.011: --- TIDE STARTING ---
+//	001.840  001.829: load config
+//
+// Marks are cheap no-ops unless Start was called, so phases can be marked
+// unconditionally.
+package startuptime
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// processStart approximates when the process started: package variables
+// are initialized before main runs.
+var processStart = time.Now()
+
+var rec struct {
+	sync.Mutex
+	path  string
+	last  time.Time
+	lines []string
+	done  bool
+}
+
+// Start begins recording, to be written to path by Finish.
+func Start(path string) {
+	rec.Lock()
+	defer rec.Unlock()
+	rec.path = path
+	rec.last = processStart
+	rec.lines = nil
+	rec.done = false
+	mark(processStart, "--- TIDE STARTING ---")
+}
+
+// Mark records that phase has just finished. Phases running concurrently
+// with others should say so in their name, as their self time overlaps.
+func Mark(phase string) {
+	rec.Lock()
+	defer rec.Unlock()
+	if rec.path == "" || rec.done {
+		return
+	}
+	mark(time.Now(), phase)
+}
+
+// Since records phase as having taken the time since began, for phases
+// that overlap others, such as work done in the background.
+func Since(began time.Time, phase string) {
+	rec.Lock()
+	defer rec.Unlock()
+	if rec.path == "" || rec.done {
+		return
+	}
+	now := time.Now()
+	rec.lines = append(rec.lines, fmt.Sprintf("%s  %s: %s", msec(now.Sub(processStart)), msec(now.Sub(began)), phase))
+}
+
+// Finish appends the report to the file given to Start, once; later marks
+// are ignored.
+func Finish() error {
+	rec.Lock()
+	defer rec.Unlock()
+	if rec.path == "" || rec.done {
+		return nil
+	}
+	mark(time.Now(), "--- TIDE STARTED ---")
+	rec.done = true
+
+	f, err := os.OpenFile(rec.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	werr := write(f, rec.lines)
+	if err := f.Close(); werr == nil {
+		werr = err
+	}
+	return werr
+}
+
+// mark records phase as ending at now, taking the time since the last one.
+// rec must be locked.
+func mark(now time.Time, phase string) {
+	rec.lines = append(rec.lines, fmt.Sprintf("%s  %s: %s", msec(now.Sub(processStart)), msec(now.Sub(rec.last)), phase))
+	rec.last = now
+}
+
+// write prints a report with the given phase lines.
+func write(w io.Writer, lines []string) error {
+	if _, err := fmt.Fprint(w, "\n\ntimes in msec\n clock   self: phase\n\n"); err != nil {
+		return err
+	}
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// msec formats d as milliseconds with three decimals, "012.345".
+func msec(d time.Duration) string {
+	return fmt.Sprintf("%07.3f", float64(d.Microseconds())/1000)
+}
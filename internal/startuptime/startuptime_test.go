@@ -0,0 +1,50 @@
+package startuptime
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "startup.log")
+	Start(path)
+	Mark("load config")
+	Since(time.Now().Add(-2*time.Millisecond), "scan themes (background)")
+	if err := Finish(); err != nil {
+		t.Fatal(err)
+	}
+	Mark("after finish")
+	if err := Finish(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := string(data)
+	if strings.Count(text, "times in msec") != 1 || strings.Contains(text, "after finish") {
+		t.Fatalf("report written more than once or after Finish:\n%s", text)
+	}
+	line := regexp.MustCompile(`(?m)^\d{3,}\.\d{3}  \d{3,}\.\d{3}: (.*)$`)
+	var phases []string
+	for _, m := range line.FindAllStringSubmatch(text, -1) {
+		phases = append(phases, m[1])
+	}
+	want := []string{"--- TIDE STARTING ---", "load config", "scan themes (background)", "--- TIDE STARTED ---"}
+	if strings.Join(phases, "|") != strings.Join(want, "|") {
+		t.Errorf("phases = %q, want %q", phases, want)
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	rec.path = ""
+	Mark("nothing")
+	if err := Finish(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,135 @@
+// internal/statusbar/layout.go
+package statusbar
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/uniseg"
+)
+
+// Segment priorities: lower values are more important and are the last to
+// be shortened or dropped when the terminal is too narrow.
+const (
+	priorityFilename = iota
+	priorityMode
+	priorityRecording
+	priorityModified
+	priorityPending
+	priorityCursor
+)
+
+const (
+	leftGap     = " "    // Between left-aligned segments
+	rightGap    = " -- " // Between right-aligned segments
+	ellipsis    = "…"
+	minBlockGap = 1 // Minimum columns between the left and right blocks
+)
+
+// segment is one piece of the status line. forms lists the texts the
+// segment may be rendered as, from the most to the least verbose.
+type segment struct {
+	forms    []string
+	style    tcell.Style
+	priority int
+	right    bool
+
+	form    int  // Index into forms currently chosen
+	dropped bool // Omitted entirely
+}
+
+// text returns the currently chosen form.
+func (s *segment) text() string {
+	return s.forms[s.form]
+}
+
+// lineWidth returns the width taken by the visible segments, including gaps.
+func lineWidth(segs []segment) int {
+	left, right := 0, 0
+	nLeft, nRight := 0, 0
+	for i := range segs {
+		if segs[i].dropped {
+			continue
+		}
+		w := uniseg.StringWidth(segs[i].text())
+		if segs[i].right {
+			if nRight > 0 {
+				w += uniseg.StringWidth(rightGap)
+			}
+			right += w
+			nRight++
+		} else {
+			if nLeft > 0 {
+				w += uniseg.StringWidth(leftGap)
+			}
+			left += w
+			nLeft++
+		}
+	}
+	if nLeft > 0 && nRight > 0 {
+		return left + minBlockGap + right
+	}
+	return left + right
+}
+
+// fitSegments shrinks segs in place until they fit in width. The least
+// important segment is shortened first; once nothing can be shortened the
+// least important segment is dropped. A lone remaining segment that is still
+// too wide is truncated with an ellipsis.
+func fitSegments(segs []segment, width int) {
+	for lineWidth(segs) > width {
+		if victim := leastImportant(segs, true); victim >= 0 {
+			segs[victim].form++
+			continue
+		}
+		visible := 0
+		for i := range segs {
+			if !segs[i].dropped {
+				visible++
+			}
+		}
+		if visible <= 1 {
+			break
+		}
+		segs[leastImportant(segs, false)].dropped = true
+	}
+
+	for i := range segs {
+		if !segs[i].dropped && uniseg.StringWidth(segs[i].text()) > width {
+			segs[i].forms[segs[i].form] = truncate(segs[i].text(), width)
+		}
+	}
+}
+
+// leastImportant returns the index of the visible segment with the highest
+// priority value, or -1. With shrinkable set, only segments that still have
+// a shorter form are considered.
+func leastImportant(segs []segment, shrinkable bool) int {
+	best := -1
+	for i := range segs {
+		if segs[i].dropped || (shrinkable && segs[i].form >= len(segs[i].forms)-1) {
+			continue
+		}
+		if best < 0 || segs[i].priority > segs[best].priority {
+			best = i
+		}
+	}
+	return best
+}
+
+// truncate cuts s to at most width columns, ending it with an ellipsis.
+func truncate(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	limit := width - uniseg.StringWidth(ellipsis)
+	out := ""
+	w := 0
+	gr := uniseg.NewGraphemes(s)
+	for gr.Next() {
+		if w+gr.Width() > limit {
+			break
+		}
+		out += gr.Str()
+		w += gr.Width()
+	}
+	return out + ellipsis
+}
@@ -0,0 +1,56 @@
+package statusbar
+
+import "testing"
+
+func newSegs(modified bool) []segment {
+	segs := []segment{{forms: pathForms("/home/user/project/main.go"), priority: priorityFilename}}
+	if modified {
+		segs = append(segs, segment{forms: []string{"[Modified]", "[+]"}, priority: priorityModified})
+	}
+	return append(segs,
+		segment{forms: []string{"Line: 12, Col: 4", "12:4"}, priority: priorityCursor, right: true},
+		segment{forms: []string{" NORMAL "}, priority: priorityMode, right: true},
+	)
+}
+
+func visibleTexts(segs []segment) []string {
+	var out []string
+	for i := range segs {
+		if !segs[i].dropped {
+			out = append(out, segs[i].text())
+		}
+	}
+	return out
+}
+
+func TestFitSegments(t *testing.T) {
+	tests := []struct {
+		width int
+		want  []string
+	}{
+		{80, []string{"/home/user/project/main.go", "[Modified]", "Line: 12, Col: 4", " NORMAL "}},
+		{50, []string{"/home/user/project/main.go", "[+]", "12:4", " NORMAL "}},
+		{30, []string{"main.go", "[+]", "12:4", " NORMAL "}},
+		{17, []string{"main.go", " NORMAL "}},
+		{9, []string{"main.go"}},
+		{5, []string{"main…"}},
+	}
+	for _, tt := range tests {
+		segs := newSegs(true)
+		fitSegments(segs, tt.width)
+		got := visibleTexts(segs)
+		if len(got) != len(tt.want) {
+			t.Errorf("width %d: got %q, want %q", tt.width, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("width %d: got %q, want %q", tt.width, got, tt.want)
+				break
+			}
+		}
+		if w := lineWidth(segs); w > tt.width {
+			t.Errorf("width %d: line is %d columns wide", tt.width, w)
+		}
+	}
+}
@@ -3,10 +3,12 @@ package statusbar
 
 import (
 	"fmt" // Import strings for string operations
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/bethropolis/tide/internal/i18n"
 	"github.com/bethropolis/tide/internal/logger"
 	"github.com/bethropolis/tide/internal/theme" // Import theme package
 	"github.com/bethropolis/tide/internal/types" // For cursor position etc.
@@ -17,6 +19,7 @@ import (
 // Config defines the appearance and behavior of the status bar.
 type Config struct {
 	MessageTimeout time.Duration
+	ScreenReader   bool // Repeat messages on the announcement line (see DrawAnnouncement)
 }
 
 // DefaultConfig provides sensible defaults.
@@ -36,10 +39,15 @@ type StatusBar struct {
 	cursorPos  types.Position
 	isModified bool
 	editorMode string // Placeholder for future modes (NORMAL, INSERT, etc.)
+	pending    string // Keys typed of a command not complete yet
+	recording  string // Script being recorded, or ""
 
 	// Temporary message state
 	tempMessage     string
 	tempMessageTime time.Time
+	tempIsError     bool // Drawn in the theme's Error role
+
+	announcement string // Latest announcement, kept until the next one
 }
 
 // New creates a new StatusBar with the given configuration.
@@ -71,12 +79,77 @@ func (sb *StatusBar) SetEditorMode(mode string) {
 	sb.editorMode = mode
 }
 
+// SetInputState updates the keys typed of a command that is not complete
+// yet (a count, an operator waiting for its motion) and the script being
+// recorded; "" shows neither.
+func (sb *StatusBar) SetInputState(pending, recording string) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	sb.pending = pending
+	sb.recording = recording
+}
+
 // SetTemporaryMessage displays a message for a configured duration.
 func (sb *StatusBar) SetTemporaryMessage(format string, args ...interface{}) {
 	sb.mu.Lock()
 	defer sb.mu.Unlock()
 	sb.tempMessage = fmt.Sprintf(format, args...)
 	sb.tempMessageTime = time.Now()
+	sb.tempIsError = false
+	// Command and find input is echoed here on every frame; the terminal
+	// cursor already follows it, so only real messages are announced
+	if sb.config.ScreenReader && !isInputEcho(sb.tempMessage) {
+		sb.announcement = sb.tempMessage
+	}
+}
+
+// SetErrorMessage displays a temporary message reporting a failure.
+func (sb *StatusBar) SetErrorMessage(format string, args ...interface{}) {
+	sb.SetTemporaryMessage(format, args...)
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	sb.tempIsError = true
+}
+
+// Message returns the temporary message being shown, if any, and whether
+// it reports a failure.
+func (sb *StatusBar) Message() (string, bool) {
+	sb.mu.RLock()
+	defer sb.mu.RUnlock()
+	return sb.tempMessage, sb.tempIsError
+}
+
+// Announce puts a message on the announcement line without showing it in
+// the status bar. It does nothing unless the screen reader mode is on.
+func (sb *StatusBar) Announce(format string, args ...interface{}) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	if sb.config.ScreenReader {
+		sb.announcement = fmt.Sprintf(format, args...)
+	}
+}
+
+// DrawAnnouncement draws the latest announcement as plain text on row y.
+// Unlike status messages it does not expire, so a screen reader reviewing
+// the line finds what was said last.
+func (sb *StatusBar) DrawAnnouncement(screen tcell.Screen, y, width int, activeTheme *theme.Theme) {
+	if activeTheme == nil {
+		activeTheme = theme.GetCurrentTheme()
+	}
+	style := activeTheme.GetStyle("Default")
+	for x := 0; x < width; x++ {
+		screen.SetContent(x, y, ' ', nil, style)
+	}
+	sb.mu.RLock()
+	text := sb.announcement
+	sb.mu.RUnlock()
+	drawSegment(screen, 0, y, text, style, width)
+}
+
+// isInputEcho reports whether a temporary message is the command or find
+// line being typed rather than a message.
+func isInputEcho(msg string) bool {
+	return len(msg) > 0 && (msg[0] == ':' || msg[0] == '/')
 }
 
 // ResetTemporaryMessage clears any temporary message being displayed
@@ -92,11 +165,11 @@ func (sb *StatusBar) getDefaultDisplayText() string {
 	// Assumes read lock is held or not needed if called from Draw where write lock is held
 	fPath := sb.filePath
 	if fPath == "" {
-		fPath = "[No Name]"
+		fPath = i18n.T("status.no_name")
 	}
 	modifiedIndicator := ""
 	if sb.isModified {
-		modifiedIndicator = " [Modified]"
+		modifiedIndicator = " " + i18n.T("status.modified")
 	}
 
 	// --- Format mode indicator ---
@@ -110,25 +183,158 @@ func (sb *StatusBar) getDefaultDisplayText() string {
 		fPath, modifiedIndicator, cursor.Line+1, cursor.Col+1, modeIndicator)
 }
 
-// Draw renders the status bar onto the screen using segmented styles.
-func (sb *StatusBar) Draw(screen tcell.Screen, width, height int, activeTheme *theme.Theme) {
-	if height <= 0 || width <= 0 {
-		return
-	}
-	y := height - 1 // Status bar is always the last line
+// Window is what a window's status line shows.
+type Window struct {
+	Path      string
+	Modified  bool
+	Cursor    types.Position
+	Mode      string // Empty for windows without focus
+	Pending   string // Keys typed of a command not complete yet
+	Recording string // Script being recorded, or ""
+}
 
-	logger.DebugTagf("draw", "statusbar.Draw: Drawing on Y=%d (Screen Height=%d)", y, height)
+// Focused returns the status of the focused window, as last set with
+// SetFileInfo, SetCursorInfo, SetEditorMode and SetInputState.
+func (sb *StatusBar) Focused() Window {
+	sb.mu.RLock()
+	defer sb.mu.RUnlock()
+	return Window{Path: sb.filePath, Modified: sb.isModified, Cursor: sb.cursorPos, Mode: sb.editorMode,
+		Pending: sb.pending, Recording: sb.recording}
+}
 
-	// Ensure theme is valid
+// DrawWindow draws the status line of a window on row y, from column x
+// for width cells. The status lines of windows without focus are drawn
+// plain, in the base style.
+func DrawWindow(screen tcell.Screen, x, y, width int, win Window, focused bool, activeTheme *theme.Theme) {
+	if width <= 0 {
+		return
+	}
 	if activeTheme == nil {
 		activeTheme = theme.GetCurrentTheme() // Fallback to current theme
 	}
 
 	// Get base style for filling and separators
 	baseStyle := activeTheme.GetStyle("StatusBar")
+	styleOf := func(key string) tcell.Style {
+		if !focused {
+			return baseStyle
+		}
+		return activeTheme.GetStyle(key)
+	}
 
 	// --- Fill Background ---
-	// Fill the entire status bar row with the base style first.
+	// Fill the entire status line with the base style first.
+	end := x + width
+	for col := x; col < end; col++ {
+		screen.SetContent(col, y, ' ', nil, baseStyle)
+	}
+
+	// Segments shrink or drop by priority so the most important
+	// information survives in narrow windows.
+	displayPath := win.Path
+	if displayPath == "" {
+		displayPath = i18n.T("status.no_name")
+	}
+	segs := []segment{{
+		forms:    pathForms(displayPath),
+		style:    styleOf("StatusBar.Filename"),
+		priority: priorityFilename,
+	}}
+	if win.Modified {
+		segs = append(segs, segment{
+			forms:    []string{i18n.T("status.modified"), "[+]"},
+			style:    styleOf("StatusBar.Modified"),
+			priority: priorityModified,
+		})
+	}
+	if win.Recording != "" {
+		segs = append(segs, segment{
+			forms:    []string{i18n.T("status.recording", filepath.Base(win.Recording)), "rec"},
+			style:    styleOf("StatusBar.Recording"),
+			priority: priorityRecording,
+		})
+	}
+	if win.Pending != "" {
+		segs = append(segs, segment{
+			forms:    []string{win.Pending},
+			style:    styleOf("StatusBar.Pending"),
+			priority: priorityPending,
+			right:    true,
+		})
+	}
+	cursor := win.Cursor
+	segs = append(segs, segment{
+		forms: []string{
+			i18n.T("status.cursor", cursor.Line+1, cursor.Col+1),
+			fmt.Sprintf("%d:%d", cursor.Line+1, cursor.Col+1),
+		},
+		style:    styleOf("StatusBar.CursorInfo"),
+		priority: priorityCursor,
+		right:    true,
+	})
+	if win.Mode != "" {
+		modeStr := strings.ToUpper(win.Mode)
+		// Build style key: strip spaces so "VISUAL LINE" → "StatusBar.Mode.Visualline"
+		modeStyleKey := "StatusBar.Mode." + strings.Title(strings.ReplaceAll(strings.ToLower(modeStr), " ", ""))
+		label := i18n.T("mode." + strings.ReplaceAll(strings.ToLower(modeStr), " ", "_"))
+		segs = append(segs, segment{
+			forms:    []string{" " + label + " "}, // padded pill label
+			style:    styleOf(modeStyleKey),
+			priority: priorityMode,
+			right:    true,
+		})
+	}
+
+	fitSegments(segs, width)
+
+	// Left block
+	currentX := x
+	for i := range segs {
+		if segs[i].dropped || segs[i].right {
+			continue
+		}
+		if currentX > x {
+			currentX = drawSegment(screen, currentX, y, leftGap, baseStyle, end)
+		}
+		currentX = drawSegment(screen, currentX, y, segs[i].text(), segs[i].style, end)
+	}
+
+	// Right block, aligned to the edge
+	rightWidth := 0
+	for i := range segs {
+		if segs[i].dropped || !segs[i].right {
+			continue
+		}
+		if rightWidth > 0 {
+			rightWidth += uniseg.StringWidth(rightGap)
+		}
+		rightWidth += uniseg.StringWidth(segs[i].text())
+	}
+	rightX := end - rightWidth
+	first := true
+	for i := range segs {
+		if segs[i].dropped || !segs[i].right {
+			continue
+		}
+		if !first {
+			rightX = drawSegment(screen, rightX, y, rightGap, baseStyle, end)
+		}
+		rightX = drawSegment(screen, rightX, y, segs[i].text(), segs[i].style, end)
+		first = false
+	}
+}
+
+// DrawMessage draws the command line on row y: the command or search
+// being typed, or the latest message until it times out. It has a row of
+// its own so neither hides the file information on the status lines.
+func (sb *StatusBar) DrawMessage(screen tcell.Screen, y, width int, activeTheme *theme.Theme) {
+	if width <= 0 {
+		return
+	}
+	if activeTheme == nil {
+		activeTheme = theme.GetCurrentTheme()
+	}
+	baseStyle := activeTheme.GetStyle("Default")
 	for x := 0; x < width; x++ {
 		screen.SetContent(x, y, ' ', nil, baseStyle)
 	}
@@ -136,87 +342,39 @@ func (sb *StatusBar) Draw(screen tcell.Screen, width, height int, activeTheme *t
 	sb.mu.RLock() // Use RLock for reading state
 	tempMsg := sb.tempMessage
 	tempMsgTime := sb.tempMessageTime
-	fPath := sb.filePath
-	isMod := sb.isModified
-	cursor := sb.cursorPos
-	mode := sb.editorMode
-	sb.mu.RUnlock() // Unlock after reading
-
-	isTempMsgActive := !tempMsgTime.IsZero() && time.Since(tempMsgTime) <= sb.config.MessageTimeout
-	currentX := 0 // Tracks horizontal drawing position
-
-	if isTempMsgActive {
-		// --- Draw Temporary Message ---
-		var msgStyle tcell.Style
-		isCommandInput := len(tempMsg) > 0 && tempMsg[0] == ':'
-		isFindInput := len(tempMsg) > 0 && tempMsg[0] == '/'
-
-		if isCommandInput {
-			msgStyle = activeTheme.GetStyle("StatusBar.CommandInput")
-		} else if isFindInput {
-			msgStyle = activeTheme.GetStyle("StatusBar.FindInput")
-		} else {
-			msgStyle = activeTheme.GetStyle("StatusBar.Message")
-		}
-		// Draw the message using the helper, allowing it to fill available width
-		drawSegment(screen, currentX, y, tempMsg, msgStyle, width)
+	tempIsError := sb.tempIsError
+	sb.mu.RUnlock()
 
+	if tempMsgTime.IsZero() || time.Since(tempMsgTime) > sb.config.MessageTimeout {
+		return
+	}
+	var msgStyle tcell.Style
+	isCommandInput := len(tempMsg) > 0 && tempMsg[0] == ':'
+	isFindInput := len(tempMsg) > 0 && tempMsg[0] == '/'
+
+	if isCommandInput {
+		msgStyle = activeTheme.GetStyle("StatusBar.CommandInput")
+	} else if isFindInput {
+		msgStyle = activeTheme.GetStyle("StatusBar.FindInput")
+	} else if tempIsError {
+		fg, _, _ := activeTheme.Role(theme.RoleError).Decompose()
+		msgStyle = activeTheme.GetStyle("StatusBar.Message").Foreground(fg).Bold(true)
 	} else {
-		// --- Draw Default Segments ---
-		padding := " " // Space between segments
-
-		// 1. Filename
-		filenameStyle := activeTheme.GetStyle("StatusBar.Filename")
-		displayPath := fPath
-		if displayPath == "" {
-			displayPath = "[No Name]"
-		}
-		currentX = drawSegment(screen, currentX, y, displayPath, filenameStyle, width)
-
-		// 2. Modified Indicator
-		if isMod {
-			modifiedStyle := activeTheme.GetStyle("StatusBar.Modified")
-			currentX = drawSegment(screen, currentX, y, padding+"[Modified]", modifiedStyle, width)
-		}
+		msgStyle = activeTheme.GetStyle("StatusBar.Message")
+	}
+	// The command line takes the text area's background
+	_, bg, _ := baseStyle.Decompose()
+	drawSegment(screen, 0, y, tempMsg, msgStyle.Background(bg), width)
+}
 
-		// Prepare right-aligned segments (calculate their total width first)
-		cursorStr := fmt.Sprintf("Line: %d, Col: %d", cursor.Line+1, cursor.Col+1)
-		modeStr := strings.ToUpper(mode)
-		modePill := " " + modeStr + " " // padded pill label
-		separator := " -- "
-
-		cursorWidth := uniseg.StringWidth(cursorStr)
-		modeWidth := uniseg.StringWidth(modePill)
-		separatorWidth := uniseg.StringWidth(separator)
-
-		// Calculate start position for right-aligned block
-		rightBlockWidth := cursorWidth + separatorWidth + modeWidth
-		rightStartX := width - rightBlockWidth
-
-		// Only draw right block if it doesn't overlap with left block (filename + modified)
-		if rightStartX > currentX+uniseg.StringWidth(padding) { // Ensure space for padding
-			// 3. Cursor Info (Right Aligned)
-			cursorStyle := activeTheme.GetStyle("StatusBar.CursorInfo")
-			currentX = drawSegment(screen, rightStartX, y, cursorStr, cursorStyle, width)
-
-			// 4. Separator
-			currentX = drawSegment(screen, currentX, y, separator, baseStyle, width)
-
-			// 5. Mode — padded pill with per-mode colour
-			// Build style key: strip spaces so "VISUAL LINE" → "StatusBar.Mode.Visualline"
-			modeStyleKey := "StatusBar.Mode." + strings.Title(strings.ReplaceAll(strings.ToLower(modeStr), " ", ""))
-			modeStyle := activeTheme.GetStyle(modeStyleKey)
-			drawSegment(screen, currentX, y, modePill, modeStyle, width)
-		} else {
-			// Not enough space, maybe just draw cursor info if possible
-			rightStartX = width - cursorWidth
-			if rightStartX > currentX+uniseg.StringWidth(padding) {
-				cursorStyle := activeTheme.GetStyle("StatusBar.CursorInfo")
-				drawSegment(screen, rightStartX, y, cursorStr, cursorStyle, width)
-			}
-			logger.DebugTagf("draw", "Status bar segments overlap, omitting mode info.")
-		}
+// pathForms returns the display forms for a file path: the path as given
+// and, when different, just its base name.
+func pathForms(path string) []string {
+	base := filepath.Base(path)
+	if base == path || base == "." || base == string(filepath.Separator) {
+		return []string{path}
 	}
+	return []string{path, base}
 }
 
 // drawSegment draws text at a given position with a specific style,
@@ -0,0 +1,91 @@
+package statusbar
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestAnnouncement(t *testing.T) {
+	sb := New(Config{ScreenReader: true})
+	sb.SetTemporaryMessage("Saved %s", "a.txt")
+	sb.SetTemporaryMessage(":wri")
+	if sb.announcement != "Saved a.txt" {
+		t.Errorf("announcement = %q; want the message, not the command line echo", sb.announcement)
+	}
+	sb.Announce("INSERT mode")
+	if sb.announcement != "INSERT mode" {
+		t.Errorf("announcement = %q after Announce", sb.announcement)
+	}
+
+	off := New(DefaultConfig())
+	off.SetTemporaryMessage("Saved")
+	off.Announce("INSERT mode")
+	if off.announcement != "" {
+		t.Errorf("announcement = %q with the screen reader mode off", off.announcement)
+	}
+}
+
+func TestCommandLineKeepsStatus(t *testing.T) {
+	screen := tcell.NewSimulationScreen("")
+	if err := screen.Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer screen.Fini()
+	screen.SetSize(40, 2)
+
+	sb := New(DefaultConfig())
+	sb.SetFileInfo("main.go", false)
+	sb.SetEditorMode("Command")
+	sb.SetTemporaryMessage(":write")
+	DrawWindow(screen, 0, 0, 40, sb.Focused(), true, nil)
+	sb.DrawMessage(screen, 1, 40, nil)
+	screen.Show()
+
+	if got := rowText(screen, 0); !strings.HasPrefix(got, "main.go") {
+		t.Errorf("status line = %q; want the file name", got)
+	}
+	if got := strings.TrimSpace(rowText(screen, 1)); got != ":write" {
+		t.Errorf("command line = %q; want the command being typed", got)
+	}
+}
+
+func TestInputState(t *testing.T) {
+	screen := tcell.NewSimulationScreen("")
+	if err := screen.Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer screen.Fini()
+	screen.SetSize(60, 1)
+
+	sb := New(DefaultConfig())
+	sb.SetFileInfo("main.go", false)
+	sb.SetEditorMode("Normal")
+	sb.SetInputState("12d", "/tmp/fix.tide")
+	DrawWindow(screen, 0, 0, 60, sb.Focused(), true, nil)
+	screen.Show()
+
+	got := rowText(screen, 0)
+	for _, want := range []string{"recording fix.tide", "12d"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("status line = %q; want %q in it", got, want)
+		}
+	}
+
+	sb.SetInputState("", "")
+	DrawWindow(screen, 0, 0, 60, sb.Focused(), true, nil)
+	screen.Show()
+	if got := rowText(screen, 0); strings.Contains(got, "12d") || strings.Contains(got, "recording") {
+		t.Errorf("status line = %q; want no input state", got)
+	}
+}
+
+func rowText(screen tcell.SimulationScreen, y int) string {
+	cells, w, _ := screen.GetContents()
+	var b strings.Builder
+	for x := 0; x < w; x++ {
+		b.WriteString(string(cells[y*w+x].Runes))
+	}
+	return b.String()
+}
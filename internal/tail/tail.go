@@ -0,0 +1,80 @@
+// Package tail follows files that grow at the end, such as logs, the way
+// tail -f does: it notices what was appended by checking the file's size
+// and reads only that.
+package tail
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// Change is how a file changed between two polls.
+type Change struct {
+	From, To int64 // Bytes From up to To were appended
+	Reset    bool  // The file was truncated or replaced and must be read whole
+}
+
+// Grew reports whether bytes were appended.
+func (c Change) Grew() bool {
+	return !c.Reset && c.To > c.From
+}
+
+// File is a file being followed.
+type File struct {
+	path   string
+	offset int64       // Bytes already taken in
+	info   os.FileInfo // As of the last poll, to notice the file being replaced
+}
+
+// Follow starts following the file at path, of which the first offset bytes
+// are already known; the first Poll reports anything after them.
+func Follow(path string, offset int64) (*File, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("%s is not a regular file", path)
+	}
+	return &File{path: path, offset: offset, info: info}, nil
+}
+
+// Path returns the path being followed.
+func (f *File) Path() string {
+	return f.path
+}
+
+// Poll reports what happened to the file since the last poll, and takes it
+// as known: a file shorter than before, or another file moved in its place
+// (rotated logs), is a Reset. A file that is gone is an error.
+func (f *File) Poll() (Change, error) {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return Change{}, err
+	}
+	replaced := !os.SameFile(info, f.info)
+	f.info = info
+	if replaced || info.Size() < f.offset {
+		f.offset = info.Size()
+		return Change{To: info.Size(), Reset: true}, nil
+	}
+	c := Change{From: f.offset, To: info.Size()}
+	f.offset = info.Size()
+	return c, nil
+}
+
+// Read returns the bytes c says were appended.
+func (f *File) Read(c Change) ([]byte, error) {
+	file, err := os.Open(f.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	data := make([]byte, c.To-c.From)
+	n, err := file.ReadAt(data, c.From)
+	if err == io.EOF {
+		err = nil // Truncated since the poll; the next one resets
+	}
+	return data[:n], err
+}
@@ -0,0 +1,84 @@
+package tail
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func appendTo(t *testing.T, path, text string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(text); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestFollowAppends reads only what was appended since the last poll.
+func TestFollowAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	os.WriteFile(path, []byte("one\n"), 0644)
+	f, err := Follow(path, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c, err := f.Poll(); err != nil || c.Grew() || c.Reset {
+		t.Fatalf("unchanged file: %+v, %v", c, err)
+	}
+
+	appendTo(t, path, "two\nthr")
+	c, err := f.Poll()
+	if err != nil || !c.Grew() {
+		t.Fatalf("appended file: %+v, %v", c, err)
+	}
+	if got, _ := f.Read(c); string(got) != "two\nthr" {
+		t.Errorf("Read = %q, want %q", got, "two\nthr")
+	}
+
+	appendTo(t, path, "ee\n")
+	c, _ = f.Poll()
+	if got, _ := f.Read(c); string(got) != "ee\n" {
+		t.Errorf("Read = %q, want %q", got, "ee\n")
+	}
+}
+
+// TestFollowCatchesUp reports what was written after the known offset
+// before following started.
+func TestFollowCatchesUp(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	os.WriteFile(path, []byte("one\ntwo\n"), 0644)
+	f, _ := Follow(path, 4)
+	c, _ := f.Poll()
+	if got, _ := f.Read(c); string(got) != "two\n" {
+		t.Errorf("Read = %q, want %q", got, "two\n")
+	}
+}
+
+// TestFollowReset reports a truncated or replaced file as a reset.
+func TestFollowReset(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	os.WriteFile(path, []byte("one\ntwo\n"), 0644)
+	f, _ := Follow(path, 8)
+
+	os.WriteFile(path, []byte("x\n"), 0644)
+	if c, _ := f.Poll(); !c.Reset || c.Grew() {
+		t.Errorf("truncated file: %+v, want a reset", c)
+	}
+
+	rotated := filepath.Join(dir, "new.log")
+	os.WriteFile(rotated, []byte("a much longer first line\n"), 0644)
+	os.Rename(rotated, path)
+	if c, _ := f.Poll(); !c.Reset {
+		t.Errorf("replaced file: %+v, want a reset", c)
+	}
+
+	os.Remove(path)
+	if _, err := f.Poll(); err == nil {
+		t.Error("removed file: no error")
+	}
+}
@@ -8,9 +8,12 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/bethropolis/tide/internal/logger"
+	"github.com/bethropolis/tide/internal/paths"
+	"github.com/bethropolis/tide/internal/startuptime"
 	"github.com/gdamore/tcell/v2"
 )
 
@@ -21,23 +24,26 @@ type Manager struct {
 	themesDir    string
 	configDir    string // Store the base config directory
 	defaultTheme string // Path to the default theme file
+	defaultName  string // Lowercase name of the theme loaded from it, which the directory does not override
 	mutex        sync.RWMutex
-	loadError    error // Store error from initial load
+	loadError    error         // Store error from initial load
+	dirLoaded    chan struct{} // Closed once the themes directory has been scanned
 }
 
 // NewManager creates and initializes a theme manager.
 func NewManager() *Manager {
 	mgr := &Manager{
-		themes: make(map[string]*Theme),
+		themes:    make(map[string]*Theme),
+		dirLoaded: make(chan struct{}),
 	}
 
 	// Find config directory
-	configDir, err := os.UserConfigDir()
+	configDir, err := paths.ConfigDir()
 	if err != nil {
 		logger.Warnf("Could not find user config dir: %v. Themes cannot be loaded from default location.", err)
 		mgr.themesDir = "" // No directory to load from
 	} else {
-		mgr.configDir = filepath.Join(configDir, "tide")
+		mgr.configDir = configDir
 		mgr.themesDir = filepath.Join(mgr.configDir, "themes")
 		mgr.defaultTheme = filepath.Join(mgr.configDir, "theme.toml") // Default theme at config root
 	}
@@ -45,17 +51,7 @@ func NewManager() *Manager {
 	// 1. Load built-in themes first (provides fallbacks)
 	mgr.loadBuiltinThemes()
 
-	var loadDirErr error
-	// 2. Load themes from directory (if found)
-	if mgr.themesDir != "" {
-		loadDirErr = mgr.LoadThemesFromDir() // Load custom *.toml files
-		if loadDirErr != nil {
-			logger.Errorf("Error loading themes from directory '%s': %v", mgr.themesDir, loadDirErr)
-			// Continue, but custom themes might be missing
-		}
-	}
-
-	// 3. Attempt to load the specific default user theme file (now from config root)
+	// 2. Attempt to load the specific default user theme file (now from config root)
 	var userDefaultTheme *Theme // Store if loaded successfully
 	if mgr.configDir != "" {
 		if _, err := os.Stat(mgr.defaultTheme); err == nil {
@@ -80,6 +76,7 @@ func NewManager() *Manager {
 					logger.Infof("Loaded theme '%s' from default file '%s'", theme.Name, mgr.defaultTheme)
 				}
 				mgr.themes[themeNameLower] = theme
+				mgr.defaultName = themeNameLower
 			}
 		} else if !os.IsNotExist(err) {
 			// Error stating the file, other than not existing
@@ -91,12 +88,7 @@ func NewManager() *Manager {
 			logger.Debugf("Default user theme file not found: %s", mgr.defaultTheme)
 		}
 	}
-	// Assign final overall load error if one occurred
-	if loadDirErr != nil && mgr.loadError == nil {
-		mgr.loadError = loadDirErr
-	}
-
-	// 4. Set initial active theme with priority
+	// 3. Set initial active theme with priority
 	var initialThemeSet bool
 	// Priority 1: Use the theme loaded from theme.toml if successful
 	if userDefaultTheme != nil {
@@ -139,9 +131,38 @@ func NewManager() *Manager {
 	// Ensure global CurrentTheme reflects the manager's choice (for any code still using it)
 	SetCurrentTheme(mgr.activeTheme) // Updates the global variable
 
+	// 4. Load the other themes from the directory (if found) in the
+	// background; only choosing or listing themes waits for them
+	if mgr.themesDir != "" {
+		go mgr.scanThemesDir()
+	} else {
+		close(mgr.dirLoaded)
+	}
+
 	return mgr
 }
 
+// scanThemesDir loads the themes directory and marks it loaded.
+func (m *Manager) scanThemesDir() {
+	defer close(m.dirLoaded)
+	began := time.Now()
+	if err := m.LoadThemesFromDir(); err != nil { // Load custom *.toml files
+		logger.Errorf("Error loading themes from directory '%s': %v", m.themesDir, err)
+		// Continue, but custom themes might be missing
+		m.mutex.Lock()
+		if m.loadError == nil {
+			m.loadError = err
+		}
+		m.mutex.Unlock()
+	}
+	startuptime.Since(began, "scan themes directory (background)")
+}
+
+// waitForDir blocks until the themes directory has been scanned.
+func (m *Manager) waitForDir() {
+	<-m.dirLoaded
+}
+
 // loadBuiltinThemes adds themes compiled into the binary.
 func (m *Manager) loadBuiltinThemes() {
 	m.mutex.Lock()
@@ -151,6 +172,12 @@ func (m *Manager) loadBuiltinThemes() {
 	themeNameLower := stringsToLower(DevComfortDark.Name)
 	m.themes[themeNameLower] = &DevComfortDark // Use lowercase name as key
 	logger.Debugf("Loaded built-in theme: %s", DevComfortDark.Name)
+
+	// High-contrast and colorblind-safe variants
+	for _, t := range builtinVariants() {
+		m.themes[stringsToLower(t.Name)] = t
+		logger.Debugf("Loaded built-in theme: %s", t.Name)
+	}
 }
 
 // LoadThemesFromDir scans the themes directory and loads .toml files.
@@ -193,6 +220,10 @@ func (m *Manager) LoadThemesFromDir() error {
 			}
 
 			themeNameLower := stringsToLower(theme.Name)
+			if themeNameLower == m.defaultName {
+				logger.Debugf("Theme '%s' from '%s' is overridden by the default theme file", theme.Name, filePath)
+				continue
+			}
 			if existing, ok := m.themes[themeNameLower]; ok {
 				// Don't warn if overriding built-in, only if overriding another file
 				// This check is tricky. For now, let later loads win.
@@ -206,6 +237,39 @@ func (m *Manager) LoadThemesFromDir() error {
 	return nil
 }
 
+// CheckFiles loads every theme file again, the theme directory's and the
+// saved default, and returns the paths it read and the errors by path.
+func (m *Manager) CheckFiles() (checked []string, errs map[string]error) {
+	m.mutex.RLock()
+	themesDir, defaultTheme := m.themesDir, m.defaultTheme
+	m.mutex.RUnlock()
+
+	errs = make(map[string]error)
+	var files []string
+	if themesDir != "" {
+		entries, err := os.ReadDir(themesDir)
+		if err != nil && !os.IsNotExist(err) {
+			errs[themesDir] = err
+		}
+		for _, e := range entries {
+			if !e.IsDir() && strings.HasSuffix(stringsToLower(e.Name()), ".toml") {
+				files = append(files, filepath.Join(themesDir, e.Name()))
+			}
+		}
+	}
+	if defaultTheme != "" {
+		if _, err := os.Stat(defaultTheme); err == nil {
+			files = append(files, defaultTheme)
+		}
+	}
+	for _, path := range files {
+		if _, err := LoadThemeFromFile(path); err != nil {
+			errs[path] = err
+		}
+	}
+	return files, errs
+}
+
 // Current returns the currently active theme.
 func (m *Manager) Current() *Theme {
 	m.mutex.RLock()
@@ -219,6 +283,7 @@ func (m *Manager) Current() *Theme {
 
 // SetTheme sets the active theme by name (case-insensitive).
 func (m *Manager) SetTheme(name string) error {
+	m.waitForDir()
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
@@ -250,6 +315,7 @@ func (m *Manager) SetTheme(name string) error {
 
 // ListThemes returns the names of all loaded themes.
 func (m *Manager) ListThemes() []string {
+	m.waitForDir()
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
 
@@ -264,6 +330,7 @@ func (m *Manager) ListThemes() []string {
 
 // GetTheme returns a specific theme by name (case-insensitive).
 func (m *Manager) GetTheme(name string) (*Theme, bool) {
+	m.waitForDir()
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
 	theme, ok := m.themes[stringsToLower(name)]
@@ -336,6 +403,7 @@ func colorToHexString(color tcell.Color) string {
 
 // SaveThemeToFile saves a theme to a TOML file in the themes directory
 func (m *Manager) SaveThemeToFile(themeName, fileName string) error {
+	m.waitForDir()
 	m.mutex.RLock()
 	theme, ok := m.themes[stringsToLower(themeName)]
 	m.mutex.RUnlock()
@@ -0,0 +1,85 @@
+package theme
+
+import "github.com/gdamore/tcell/v2"
+
+// Semantic roles are the style names for what a color means rather than
+// what it decorates. Code that signals severity or change asks the theme
+// for a role instead of picking a color, so every theme (and every
+// colorblind-safe variant) decides once what "error" or "added" looks like.
+const (
+	RoleError   = "Error"
+	RoleWarning = "Warning"
+	RoleInfo    = "Info"
+	RoleHint    = "Hint"
+	RoleAdd     = "Add"    // Added lines, our side of a conflict
+	RoleRemove  = "Remove" // Removed lines
+)
+
+// Roles lists every semantic role.
+var Roles = []string{RoleError, RoleWarning, RoleInfo, RoleHint, RoleAdd, RoleRemove}
+
+// roleFallbacks are the terminal palette colors used for roles a theme
+// does not define.
+var roleFallbacks = map[string]tcell.Color{
+	RoleError:   tcell.ColorRed,
+	RoleWarning: tcell.ColorYellow,
+	RoleInfo:    tcell.ColorBlue,
+	RoleHint:    tcell.ColorGray,
+	RoleAdd:     tcell.ColorGreen,
+	RoleRemove:  tcell.ColorRed,
+}
+
+// Role returns the style for a semantic role: the theme's own when it
+// defines one, otherwise the Default style in the terminal's color for
+// the role.
+func (t *Theme) Role(role string) tcell.Style {
+	if style, ok := t.Styles[role]; ok {
+		return style
+	}
+	style := t.GetStyle("Default")
+	if color, ok := roleFallbacks[role]; ok {
+		style = style.Foreground(color)
+	}
+	if role == RoleError {
+		style = style.Bold(true)
+	}
+	return style
+}
+
+// Tint returns the Default style with a background shifted toward the
+// role's color, for marking whole lines (such as the sides of a merge
+// conflict) without hurting the contrast of the text on them.
+func (t *Theme) Tint(role string) tcell.Style {
+	base := t.GetStyle("Default")
+	fg, _, _ := t.Role(role).Decompose()
+	_, bg, _ := base.Decompose()
+	if !bg.Valid() || bg == tcell.ColorReset {
+		bg = tcell.ColorBlack // Assume a dark terminal
+		if !t.IsDark {
+			bg = tcell.ColorWhite
+		}
+	}
+	return base.Background(mix(bg, fg, 0.2))
+}
+
+// MissingRoles returns the roles the theme leaves to the fallbacks.
+func (t *Theme) MissingRoles() []string {
+	var missing []string
+	for _, role := range Roles {
+		if _, ok := t.Styles[role]; !ok {
+			missing = append(missing, role)
+		}
+	}
+	return missing
+}
+
+// mix blends amount (0 to 1) of color c into base.
+func mix(base, c tcell.Color, amount float64) tcell.Color {
+	br, bg, bb := base.RGB()
+	cr, cg, cb := c.RGB()
+	if br < 0 || cr < 0 {
+		return base
+	}
+	blend := func(x, y int32) int32 { return x + int32(float64(y-x)*amount) }
+	return tcell.NewRGBColor(blend(br, cr), blend(bg, cg), blend(bb, cb))
+}
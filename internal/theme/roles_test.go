@@ -0,0 +1,78 @@
+package theme
+
+import (
+	"math"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/bethropolis/tide/internal/logger"
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestMain(m *testing.M) {
+	logger.Init(logger.Config{LogLevel: "error", LogFilePath: os.DevNull})
+	os.Exit(m.Run())
+}
+
+// contrast returns the WCAG contrast ratio of two colors.
+func contrast(a, b tcell.Color) float64 {
+	luminance := func(c tcell.Color) float64 {
+		r, g, b := c.RGB()
+		channel := func(v int32) float64 {
+			s := float64(v) / 255
+			if s <= 0.03928 {
+				return s / 12.92
+			}
+			return math.Pow((s+0.055)/1.055, 2.4)
+		}
+		return 0.2126*channel(r) + 0.7152*channel(g) + 0.0722*channel(b)
+	}
+	la, lb := luminance(a), luminance(b)
+	return (math.Max(la, lb) + 0.05) / (math.Min(la, lb) + 0.05)
+}
+
+func TestVariants(t *testing.T) {
+	for _, v := range builtinVariants() {
+		if missing := v.MissingRoles(); len(missing) > 0 {
+			t.Errorf("%s does not define %v", v.Name, missing)
+		}
+		for key := range DevComfortDark.Styles {
+			if _, ok := v.Styles[key]; !ok {
+				t.Errorf("%s has no %s style", v.Name, key)
+			}
+		}
+		if !strings.HasPrefix(v.Name, "High Contrast") {
+			continue
+		}
+		for key, style := range v.Styles {
+			fg, bg, attrs := style.Decompose()
+			if attrs&tcell.AttrReverse != 0 {
+				fg, bg = bg, fg
+			}
+			if ratio := contrast(fg, bg); ratio < 7 {
+				t.Errorf("%s: %s has a contrast ratio of %.1f, want at least 7", v.Name, key, ratio)
+			}
+		}
+	}
+}
+
+func TestRoleFallback(t *testing.T) {
+	th := &Theme{Name: "t", IsDark: true, Styles: map[string]tcell.Style{
+		"Default": tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(tcell.NewHexColor(0x101010)),
+		RoleAdd:   tcell.StyleDefault.Foreground(tcell.NewHexColor(0x00ff00)),
+	}}
+	if fg, _, _ := th.Role(RoleAdd).Decompose(); fg != tcell.NewHexColor(0x00ff00) {
+		t.Errorf("Role(Add) ignores the theme's style")
+	}
+	if fg, _, _ := th.Role(RoleError).Decompose(); fg != tcell.ColorRed {
+		t.Errorf("Role(Error) fallback fg = %v, want red", fg)
+	}
+	if got := len(th.MissingRoles()); got != len(Roles)-1 {
+		t.Errorf("MissingRoles() has %d roles, want %d", got, len(Roles)-1)
+	}
+	_, bg, _ := th.Tint(RoleAdd).Decompose()
+	if r, g, b := bg.RGB(); g <= r || g <= b || g > 0x60 {
+		t.Errorf("Tint(Add) background = #%02x%02x%02x, want a dark green", r, g, b)
+	}
+}
@@ -50,20 +50,25 @@ func (t *Theme) GetStyle(name string) tcell.Style {
 
 // --- DevComfort Dark Theme Definition ---
 
+// --- Palette for DevComfort Dark ---
+var (
+	dcBackground = tcell.NewHexColor(0x2a2f38) // Slightly muted dark blue/grey (StatusBar BG)
+	dcForeground = tcell.NewHexColor(0xc5cdd9) // Soft off-white (Default Text)
+	dcComment    = tcell.NewHexColor(0x5c6370) // Muted Grey (Comments, Punctuation)
+	dcOrange     = tcell.NewHexColor(0xd19a66) // Muted Orange (Numbers, Constants)
+	dcYellow     = tcell.NewHexColor(0xe5c07b) // Soft Yellow (Functions, Attributes)
+	dcGreen      = tcell.NewHexColor(0x98c379) // Soft Green (Strings)
+	dcCyan       = tcell.NewHexColor(0x56b6c2) // Soft Cyan (Types, Namespaces, Builtins)
+	dcBlue       = tcell.NewHexColor(0x61afef) // Soft Blue (Keywords)
+	dcMagenta    = tcell.NewHexColor(0xc678dd) // Soft Magenta/Purple (Maybe escapes, specific keywords?)
+	dcLineNumber = tcell.NewHexColor(0x4b5263) // darker grey for line numbers
+	dcSurface    = tcell.NewHexColor(0x353b45) // Raised background for pinned lines
+	dcRed        = tcell.NewHexColor(0xe06c75) // Soft Red (Errors, removed lines)
+)
+
 var DevComfortDark Theme // Define variable for this theme
 
 func init() {
-	// --- Palette for DevComfort Dark ---
-	dcBackground := tcell.NewHexColor(0x2a2f38) // Slightly muted dark blue/grey (StatusBar BG)
-	dcForeground := tcell.NewHexColor(0xc5cdd9) // Soft off-white (Default Text)
-	dcComment := tcell.NewHexColor(0x5c6370)    // Muted Grey (Comments, Punctuation)
-	dcOrange := tcell.NewHexColor(0xd19a66)     // Muted Orange (Numbers, Constants)
-	dcYellow := tcell.NewHexColor(0xe5c07b)     // Soft Yellow (Functions, Attributes)
-	dcGreen := tcell.NewHexColor(0x98c379)      // Soft Green (Strings)
-	dcCyan := tcell.NewHexColor(0x56b6c2)       // Soft Cyan (Types, Namespaces, Builtins)
-	dcBlue := tcell.NewHexColor(0x61afef)       // Soft Blue (Keywords)
-	dcMagenta := tcell.NewHexColor(0xc678dd)    // Soft Magenta/Purple (Maybe escapes, specific keywords?)
-	dcLineNumber := tcell.NewHexColor(0x4b5263) // darker grey for line numbers
 	// --- Base Style ---
 	// Use terminal background, DevComfort foreground
 	baseStyle := tcell.StyleDefault.Background(dcBackground).Foreground(dcForeground) // <<< CHANGE HERE
@@ -77,12 +82,32 @@ func init() {
 			"Default":         baseStyle,
 			"Selection":       baseStyle.Reverse(true),                                                       // Invert default FG/BG
 			"SearchHighlight": tcell.StyleDefault.Background(tcell.ColorOrange).Foreground(tcell.ColorBlack), // Keep high contrast search
+			"WordHighlight":   baseStyle.Background(dcLineNumber),                                            // Other occurrences of the identifier under the cursor
+			"StickyContext":   baseStyle.Background(dcSurface).Italic(true),                                  // Enclosing function pinned at the top of the view
+			"VirtualText":     baseStyle.Foreground(dcComment),                                               // Hints after the end of a line (inline blame)
+			"MatchParen":      baseStyle.Background(dcSurface).Bold(true),                                    // The bracket under the cursor and the one it pairs with
+			"ExtraCursor":     baseStyle.Reverse(true),                                                       // Cursors besides the main one (Ctrl+D)
+			"ConflictMarker":  baseStyle.Background(dcSurface).Bold(true),                                    // <<<<<<< ======= >>>>>>> lines of a merge conflict
+			"ConflictOurs":    baseStyle.Background(tcell.NewHexColor(0x2f3d33)),                             // Our side of a merge conflict (green tint)
+			"ConflictTheirs":  baseStyle.Background(tcell.NewHexColor(0x2c3a4d)),                             // Their side of a merge conflict (blue tint)
+			"AppendedLine":    baseStyle.Background(tcell.NewHexColor(0x2f3d33)),                             // Lines just appended to a followed file (:tail)
+			"Mark":            baseStyle.Foreground(dcBlue).Bold(true),                                       // Letters of named marks in the gutter (ma)
+
+			// --- Semantic Roles (see roles.go) ---
+			RoleError:   baseStyle.Foreground(dcRed).Bold(true),
+			RoleWarning: baseStyle.Foreground(dcYellow),
+			RoleInfo:    baseStyle.Foreground(dcBlue),
+			RoleHint:    baseStyle.Foreground(dcComment),
+			RoleAdd:     baseStyle.Foreground(dcGreen),
+			RoleRemove:  baseStyle.Foreground(dcRed),
 
 			// --- Status Bar Styles ---
 			"StatusBar":            tcell.StyleDefault.Background(dcBackground).Foreground(dcForeground),         // Base: BG, default FG for separators
 			"StatusBar.Filename":   tcell.StyleDefault.Background(dcBackground).Foreground(dcForeground),         // Filename: Default FG
 			"StatusBar.Modified":   tcell.StyleDefault.Background(dcBackground).Foreground(dcOrange).Bold(true),  // Modified Indicator: Orange, Bold
 			"StatusBar.CursorInfo": tcell.StyleDefault.Background(dcBackground).Foreground(dcForeground),         // Line/Col: Default FG
+			"StatusBar.Pending":    tcell.StyleDefault.Background(dcBackground).Foreground(dcYellow).Bold(true),  // Count or operator being typed: Yellow, Bold
+			"StatusBar.Recording":  tcell.StyleDefault.Background(dcBackground).Foreground(dcRed).Bold(true),     // Script being recorded (:record): Red, Bold
 			"StatusBar.Mode":       tcell.StyleDefault.Background(dcBackground).Foreground(dcMagenta).Bold(true), // Mode (fallback): Magenta, Bold
 			// Per-mode colours
 			"StatusBar.Mode.Normal":     tcell.StyleDefault.Background(dcBlue).Foreground(tcell.ColorWhite).Bold(true),     // NORMAL: blue pill
@@ -0,0 +1,116 @@
+package theme
+
+import "github.com/gdamore/tcell/v2"
+
+// palette holds the colors of a built-in variant of DevComfort Dark. Each
+// variant keeps DevComfort's style names and attributes and swaps its
+// colors, so it covers every style DevComfort does.
+type palette struct {
+	background, foreground, comment, lineNumber, surface tcell.Color
+	keyword, str, number, function, typ, special         tcell.Color
+	search                                               tcell.Color // SearchHighlight background
+	errorColor, warning, info, hint, add, remove         tcell.Color
+}
+
+// Variants of DevComfort Dark for low vision and color blindness. The
+// high-contrast ones keep text at a contrast ratio of 7:1 or more against
+// the background. The colorblind-safe one draws from the Okabe-Ito
+// palette: nothing depends on telling red from green, so added and removed
+// lines are blue and orange.
+var (
+	highContrastDark = palette{
+		background: tcell.NewHexColor(0x000000), foreground: tcell.NewHexColor(0xffffff),
+		comment: tcell.NewHexColor(0xb3b3b3), lineNumber: tcell.NewHexColor(0xa6a6a6), surface: tcell.NewHexColor(0x262626),
+		keyword: tcell.NewHexColor(0x79c0ff), str: tcell.NewHexColor(0x7ee787), number: tcell.NewHexColor(0xffa657),
+		function: tcell.NewHexColor(0xffdf5d), typ: tcell.NewHexColor(0x56d4dd), special: tcell.NewHexColor(0xff9bf5),
+		search:     tcell.NewHexColor(0xffff00),
+		errorColor: tcell.NewHexColor(0xff7b72), warning: tcell.NewHexColor(0xffdf5d), info: tcell.NewHexColor(0x79c0ff),
+		hint: tcell.NewHexColor(0xb3b3b3), add: tcell.NewHexColor(0x7ee787), remove: tcell.NewHexColor(0xff7b72),
+	}
+	highContrastLight = palette{
+		background: tcell.NewHexColor(0xffffff), foreground: tcell.NewHexColor(0x000000),
+		comment: tcell.NewHexColor(0x4d4d4d), lineNumber: tcell.NewHexColor(0x555555), surface: tcell.NewHexColor(0xe6e6e6),
+		keyword: tcell.NewHexColor(0x0033b3), str: tcell.NewHexColor(0x0a5c00), number: tcell.NewHexColor(0x8a3b00),
+		function: tcell.NewHexColor(0x5c4400), typ: tcell.NewHexColor(0x005566), special: tcell.NewHexColor(0x7a0077),
+		search:     tcell.NewHexColor(0xffd700),
+		errorColor: tcell.NewHexColor(0xa30014), warning: tcell.NewHexColor(0x6b4500), info: tcell.NewHexColor(0x0033b3),
+		hint: tcell.NewHexColor(0x4d4d4d), add: tcell.NewHexColor(0x0a5c00), remove: tcell.NewHexColor(0xa30014),
+	}
+	colorblindDark = palette{
+		background: tcell.NewHexColor(0x1c1f24), foreground: tcell.NewHexColor(0xe8e8e8),
+		comment: tcell.NewHexColor(0x8c8c8c), lineNumber: tcell.NewHexColor(0x6e6e6e), surface: tcell.NewHexColor(0x2c3038),
+		keyword: tcell.NewHexColor(0x56b4e9), str: tcell.NewHexColor(0xe69f00), number: tcell.NewHexColor(0xcc79a7),
+		function: tcell.NewHexColor(0xf0e442), typ: tcell.NewHexColor(0x009e73), special: tcell.NewHexColor(0xcc79a7),
+		search:     tcell.NewHexColor(0xf0e442),
+		errorColor: tcell.NewHexColor(0xd55e00), warning: tcell.NewHexColor(0xf0e442), info: tcell.NewHexColor(0x56b4e9),
+		hint: tcell.NewHexColor(0x8c8c8c), add: tcell.NewHexColor(0x56b4e9), remove: tcell.NewHexColor(0xe69f00),
+	}
+)
+
+// builtinVariants returns fresh copies of the variant themes.
+func builtinVariants() []*Theme {
+	return []*Theme{
+		newVariant("High Contrast Dark", true, highContrastDark),
+		newVariant("High Contrast Light", false, highContrastLight),
+		newVariant("Colorblind Safe Dark", true, colorblindDark),
+	}
+}
+
+// newVariant recolors DevComfort Dark with p.
+func newVariant(name string, isDark bool, p palette) *Theme {
+	colors := map[tcell.Color]tcell.Color{
+		dcBackground: p.background,
+		dcForeground: p.foreground,
+		dcComment:    p.comment,
+		dcLineNumber: p.lineNumber,
+		dcSurface:    p.surface,
+		dcBlue:       p.keyword,
+		dcGreen:      p.str,
+		dcOrange:     p.number,
+		dcYellow:     p.function,
+		dcCyan:       p.typ,
+		dcMagenta:    p.special,
+		dcRed:        p.errorColor,
+	}
+	recolor := func(c tcell.Color) tcell.Color {
+		if to, ok := colors[c]; ok {
+			return to
+		}
+		return c
+	}
+
+	t := &Theme{Name: name, IsDark: isDark, Styles: make(map[string]tcell.Style, len(DevComfortDark.Styles))}
+	for key, style := range DevComfortDark.Styles {
+		fg, bg, _ := style.Decompose()
+		t.Styles[key] = style.Foreground(recolor(fg)).Background(recolor(bg))
+	}
+
+	base := t.Styles["Default"]
+	t.Styles["SearchHighlight"] = base.Background(p.search).Foreground(tcell.ColorBlack).Bold(true)
+	t.Styles["WordHighlight"] = base.Background(p.surface).Underline(true)
+	t.Styles[RoleError] = base.Foreground(p.errorColor).Bold(true)
+	t.Styles[RoleWarning] = base.Foreground(p.warning)
+	t.Styles[RoleInfo] = base.Foreground(p.info)
+	t.Styles[RoleHint] = base.Foreground(p.hint)
+	t.Styles[RoleAdd] = base.Foreground(p.add)
+	t.Styles[RoleRemove] = base.Foreground(p.remove)
+	t.Styles["ConflictOurs"] = t.Tint(RoleAdd)
+	t.Styles["ConflictTheirs"] = t.Tint(RoleWarning)
+	t.Styles["AppendedLine"] = t.Tint(RoleAdd)
+	t.Styles["Mark"] = base.Foreground(p.info).Bold(true)
+
+	// Mode pills: the background color as text on the mode's color reads
+	// well on both light and dark variants
+	pills := map[string]tcell.Color{
+		"StatusBar.Mode.Normal":     p.keyword,
+		"StatusBar.Mode.Insert":     p.str,
+		"StatusBar.Mode.Visual":     p.number,
+		"StatusBar.Mode.Visualline": p.number,
+		"StatusBar.Mode.Command":    p.typ,
+		"StatusBar.Mode.Find":       p.special,
+	}
+	for key, color := range pills {
+		t.Styles[key] = tcell.StyleDefault.Background(color).Foreground(p.background).Bold(true)
+	}
+	return t
+}
@@ -0,0 +1,30 @@
+package tui
+
+import "github.com/gdamore/tcell/v2"
+
+// clippedScreen drops every cell written outside its clip rectangle, so
+// code drawing on it cannot spill over the rest of the UI.
+type clippedScreen struct {
+	tcell.Screen
+	clip Rect
+}
+
+// Clip returns a view of screen on which SetContent only takes effect inside
+// r. Everything else (size, styles, events) passes through unchanged.
+func Clip(screen tcell.Screen, r Rect) tcell.Screen {
+	return &clippedScreen{Screen: screen, clip: r}
+}
+
+// SetContent writes the cell only when it lies inside the clip rectangle.
+func (s *clippedScreen) SetContent(x, y int, primary rune, combining []rune, style tcell.Style) {
+	if s.clip.Contains(x, y) {
+		s.Screen.SetContent(x, y, primary, combining, style)
+	}
+}
+
+// SetCell writes the cell only when it lies inside the clip rectangle.
+func (s *clippedScreen) SetCell(x, y int, style tcell.Style, ch ...rune) {
+	if s.clip.Contains(x, y) {
+		s.Screen.SetCell(x, y, style, ch...)
+	}
+}
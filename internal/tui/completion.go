@@ -134,45 +134,23 @@ func (c *CompletionOverlay) Draw(screen tcell.Screen, th *theme.Theme, screenW,
 	if boxW < 8 {
 		boxW = 8
 	}
-	if boxW > screenW {
-		boxW = screenW
-	}
-	boxH := maxRows + 2
-
-	x := c.AnchorCol + 1
-	y := c.AnchorLine + 1
-	if x+boxW >= screenW {
-		x = screenW - boxW - 1
-	}
-	if y+boxH >= screenH {
-		y = screenH - boxH - 1
-	}
-	if x < 0 {
-		x = 0
-	}
-	if y < 0 {
-		y = 0
-	}
 
-	style := th.GetStyle("Default")
-	DrawBox(screen, x, y, boxW, boxH, style)
-
-	for i := 0; i < maxRows; i++ {
-		it := c.Items[i]
-		label := it.Label
-		if label == "" {
-			label = it.InsertText
-		}
-		itemStyle := style
-		if i == c.SelectedIdx {
-			itemStyle = style.Reverse(true)
-		}
-		DrawText(screen, x+1, y+i+1, boxW-2, label, itemStyle)
-		drawn := len([]rune(label))
-		for col := x + 1 + drawn; col < x+boxW-1; col++ {
-			screen.SetContent(col, y+i+1, ' ', nil, itemStyle)
+	win := NewWindow(AnchoredRect(c.AnchorCol, c.AnchorLine, boxW, maxRows+2, screenW, screenH), func(screen tcell.Screen, area Rect, style tcell.Style) {
+		for i := 0; i < maxRows && i < area.Height; i++ {
+			it := c.Items[i]
+			label := it.Label
+			if label == "" {
+				label = it.InsertText
+			}
+			itemStyle := style
+			if i == c.SelectedIdx {
+				itemStyle = style.Reverse(true)
+			}
+			DrawText(screen, area.X, area.Y+i, area.Width, label, itemStyle)
+			fillRow(screen, area, area.X+len([]rune(label)), area.Y+i, itemStyle)
 		}
-	}
+	})
+	win.Draw(screen, th)
 }
 
 // FilterSymbols applies a case-insensitive prefix filter on candidates and
@@ -0,0 +1,165 @@
+// internal/tui/confirm.go
+package tui
+
+import (
+	"unicode"
+
+	"github.com/bethropolis/tide/internal/theme"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/uniseg"
+)
+
+// ConfirmChoice is one button in a ConfirmDialog. Key is the shortcut that
+// selects it directly (matched case-insensitively); Action runs after the
+// dialog has closed.
+type ConfirmChoice struct {
+	Key    rune
+	Label  string
+	Action func()
+}
+
+// ConfirmDialog is a small modal prompt with a message and a row of choices.
+// It implements the Overlay interface. Esc cancels, Left/Right/Tab move the
+// selection, Enter or a choice's Key confirms.
+type ConfirmDialog struct {
+	Active   bool
+	Title    string
+	Message  []string
+	Choices  []ConfirmChoice
+	Selected int
+	OnCancel func()
+}
+
+// NewConfirmDialog creates an inactive dialog.
+func NewConfirmDialog() *ConfirmDialog {
+	return &ConfirmDialog{}
+}
+
+// Show opens the dialog, replacing anything it was showing before.
+func (d *ConfirmDialog) Show(title string, message []string, choices []ConfirmChoice) {
+	d.Title = title
+	d.Message = message
+	d.Choices = choices
+	d.Selected = 0
+	d.OnCancel = nil
+	d.Active = true
+}
+
+// IsActive satisfies Overlay.
+func (d *ConfirmDialog) IsActive() bool { return d.Active }
+
+// Cancel closes the dialog without choosing.
+func (d *ConfirmDialog) Cancel() {
+	d.Active = false
+	if d.OnCancel != nil {
+		d.OnCancel()
+	}
+}
+
+// choose closes the dialog and runs the action at index i.
+func (d *ConfirmDialog) choose(i int) {
+	d.Active = false
+	if i >= 0 && i < len(d.Choices) && d.Choices[i].Action != nil {
+		d.Choices[i].Action()
+	}
+}
+
+// HandleKeyEvent satisfies Overlay. Every key is consumed while active.
+func (d *ConfirmDialog) HandleKeyEvent(ev *tcell.EventKey) bool {
+	if !d.Active {
+		return false
+	}
+
+	switch ev.Key() {
+	case tcell.KeyEscape, tcell.KeyCtrlC:
+		d.Cancel()
+	case tcell.KeyEnter:
+		d.choose(d.Selected)
+	case tcell.KeyLeft, tcell.KeyBacktab:
+		if d.Selected > 0 {
+			d.Selected--
+		}
+	case tcell.KeyRight, tcell.KeyTab:
+		if d.Selected < len(d.Choices)-1 {
+			d.Selected++
+		}
+	case tcell.KeyRune:
+		r := unicode.ToLower(ev.Rune())
+		for i, c := range d.Choices {
+			if c.Key != 0 && unicode.ToLower(c.Key) == r {
+				d.choose(i)
+				break
+			}
+		}
+	}
+	return true
+}
+
+// choiceLabel renders a choice as "[k]Label".
+func choiceLabel(c ConfirmChoice) string {
+	if c.Key == 0 {
+		return " " + c.Label + " "
+	}
+	return "[" + string(c.Key) + "]" + c.Label
+}
+
+// Draw renders the dialog centred on screen, sized to fit its content.
+func (d *ConfirmDialog) Draw(screen tcell.Screen, th *theme.Theme, screenW, screenH int) {
+	if !d.Active {
+		return
+	}
+
+	buttonsW := 0
+	for i, c := range d.Choices {
+		if i > 0 {
+			buttonsW += 2
+		}
+		buttonsW += uniseg.StringWidth(choiceLabel(c))
+	}
+	contentW := buttonsW
+	for _, line := range d.Message {
+		if w := uniseg.StringWidth(line); w > contentW {
+			contentW = w
+		}
+	}
+	if w := uniseg.StringWidth(d.Title) + 2; w > contentW {
+		contentW = w
+	}
+
+	boxW := contentW + 4
+	boxH := len(d.Message) + 4 // border + message + blank + buttons
+	if boxW > screenW {
+		boxW = screenW
+	}
+	if boxH > screenH {
+		boxH = screenH
+	}
+	r := Rect{X: (screenW - boxW) / 2, Y: (screenH - boxH) / 2, Width: boxW, Height: boxH}
+
+	win := NewWindow(r, func(screen tcell.Screen, area Rect, style tcell.Style) {
+		for i, line := range d.Message {
+			if i >= area.Height-2 {
+				break
+			}
+			DrawText(screen, area.X+1, area.Y+i, area.Width-2, line, style)
+		}
+
+		col := area.X + 1
+		row := area.Y + area.Height - 1
+		for i, c := range d.Choices {
+			if i > 0 {
+				col += 2
+			}
+			label := choiceLabel(c)
+			btnStyle := style
+			if i == d.Selected {
+				btnStyle = style.Reverse(true)
+			}
+			DrawText(screen, col, row, area.X+area.Width-col, label, btnStyle)
+			col += uniseg.StringWidth(label)
+		}
+	})
+	win.Title = d.Title
+	win.ZIndex = 100
+	win.Draw(screen, th)
+}
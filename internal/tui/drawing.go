@@ -3,10 +3,14 @@ package tui
 
 import (
 	"fmt" // Import fmt
+	"slices"
+	"unicode/utf8"
 
 	// Import config for DefaultTabWidth
 	"github.com/bethropolis/tide/internal/config" // Import config for DefaultTabWidth
 	"github.com/bethropolis/tide/internal/core"
+	"github.com/bethropolis/tide/internal/core/conflict"
+	"github.com/bethropolis/tide/internal/core/table"
 	"github.com/bethropolis/tide/internal/logger"
 	"github.com/bethropolis/tide/internal/theme" // Import theme package
 	"github.com/bethropolis/tide/internal/types" // Needed for Position type and HighlightRegion
@@ -51,6 +55,11 @@ func calculateVisualColumn(line []byte, runeIndex int, tabWidth int) int {
 		currentRuneIndex += len(runes) // Increment by the number of runes in the cluster
 	}
 
+	// A virtual cursor past the end of the line sits in the blank cells after it
+	if currentRuneIndex < runeIndex {
+		visualWidth += runeIndex - currentRuneIndex
+	}
+
 	return visualWidth
 }
 
@@ -72,10 +81,11 @@ func isPositionWithin(pos, start, end types.Position) bool {
 	return true
 }
 
-// DrawBuffer draws the *visible* portion using the provided theme.
+// DrawBuffer draws the *visible* portion using the provided theme into area
+// of the screen (a split pane, or the whole editor area).
 // It uses the editor's dirty-line tracking to skip rows that have not changed,
 // avoiding redundant screen.SetContent calls. After drawing, it calls ClearDirty.
-func DrawBuffer(tuiManager *TUI, editor *core.Editor, activeTheme *theme.Theme) {
+func DrawBuffer(tuiManager *TUI, editor *core.Editor, activeTheme *theme.Theme, area Rect) {
 	// Fallback theme handling (same as before)
 	if activeTheme == nil {
 		logger.Warnf("DrawBuffer called with nil theme, using package default.")
@@ -87,15 +97,56 @@ func DrawBuffer(tuiManager *TUI, editor *core.Editor, activeTheme *theme.Theme)
 	lineNumberStyle := activeTheme.GetStyle("LineNumber")
 	selectionStyle := activeTheme.GetStyle("Selection")
 	searchHighlightStyle := activeTheme.GetStyle("SearchHighlight")
+	wordHighlightStyle, ok := activeTheme.Styles["WordHighlight"]
+	if !ok {
+		wordHighlightStyle = defaultStyle.Underline(true) // Themes predating WordHighlight
+	}
+	stickyContextStyle, ok := activeTheme.Styles["StickyContext"]
+	if !ok {
+		stickyContextStyle = defaultStyle.Underline(true) // Themes predating StickyContext
+	}
+	virtualTextStyle, ok := activeTheme.Styles["VirtualText"]
+	if !ok {
+		virtualTextStyle = defaultStyle.Dim(true) // Themes predating VirtualText
+	}
+	matchParenStyle := styleOr(activeTheme, "MatchParen", defaultStyle.Bold(true).Underline(true))
+	extraCursorStyle := styleOr(activeTheme, "ExtraCursor", defaultStyle.Reverse(true))
+	appendedStyle := styleOr(activeTheme, "AppendedLine", activeTheme.Tint(theme.RoleAdd))
+	markStyle := styleOr(activeTheme, "Mark", activeTheme.Role(theme.RoleInfo).Bold(true))
+	// Screen readers get no decorations, and highlights that do not rely
+	// on color alone
+	screenReader := config.Get().UI.ScreenReader
+	if screenReader {
+		selectionStyle = selectionStyle.Reverse(true)
+		searchHighlightStyle = searchHighlightStyle.Underline(true)
+	}
+	conflictStyles := map[conflict.Side]tcell.Style{
+		conflict.SideMarker: styleOr(activeTheme, "ConflictMarker", defaultStyle.Bold(true)),
+		conflict.SideOurs:   styleOr(activeTheme, "ConflictOurs", activeTheme.Tint(theme.RoleAdd)),
+		conflict.SideTheirs: styleOr(activeTheme, "ConflictTheirs", activeTheme.Tint(theme.RoleInfo)),
+	}
 
-	// Get screen dimensions and viewport position
-	width, height := tuiManager.Size()
-	logger.DebugTagf("draw", "DrawBuffer Start: Screen Size (%d x %d)", width, height)
+	lineMarkStyles := map[core.LineMark]tcell.Style{
+		core.LineAdded:   activeTheme.Role(theme.RoleAdd),
+		core.LineChanged: activeTheme.Role(theme.RoleWarning),
+		core.LineRemoved: activeTheme.Role(theme.RoleRemove),
+	}
+
+	// Get area dimensions and viewport position; coordinates below are
+	// relative to the area
+	width, height := area.Width, area.Height
+	logger.DebugTagf("draw", "DrawBuffer Start: Area (%d x %d) at (%d, %d)", width, height, area.X, area.Y)
+	setContent := func(x, y int, r rune, combining []rune, style tcell.Style) {
+		tuiManager.screen.SetContent(area.X+x, area.Y+y, r, combining, style)
+	}
 
 	viewY, viewX := editor.GetViewport() // Get both viewY and viewX for horizontal scrolling
 
-	lines := editor.GetBuffer().Lines()
-	lineCount := len(lines)
+	// Lines are read one at a time for the rows drawn, so a memory-mapped
+	// file costs what is on screen rather than its size
+	buf := editor.GetBuffer()
+	bufferLines := buf.LineCount()
+	lineCount := bufferLines
 	if lineCount == 0 {
 		lineCount = 1
 	}
@@ -103,13 +154,26 @@ func DrawBuffer(tuiManager *TUI, editor *core.Editor, activeTheme *theme.Theme)
 	// Get selection info
 	selStart, selEnd, selectionActive := editor.GetSelection()
 	linewiseSelection := editor.IsLinewise()
+	blockSelection := editor.IsBlockwise()
+	blockStartLine, blockEndLine, blockStartCol, blockEndCol := editor.GetBlockRange()
+	if blockSelection && blockStartLine >= 0 {
+		selectionActive = true // A block one column wide starts and ends at the same place
+	}
+	// Multiple cursors: those besides the main one, and their selections
+	extraCursors := editor.ExtraCursors()
+	extraSelections := editor.ExtraSelections()
 
-	// Get search highlights
-	searchHighlights := editor.GetFindManager().GetHighlights()
+	wordHighlights := editor.GetFindManager().GetWordHighlights()
+	if screenReader {
+		wordHighlights = nil
+	}
 
 	// Calculate gutter width using shared helper
-	gutterWidth := config.GutterWidth(lineCount, width)
+	gutterWidth := editor.GutterWidth(width)
 	logger.DebugTagf("draw", "DrawBuffer Calc: lineCount=%d -> gutterWidth=%d", lineCount, gutterWidth)
+	if editor.SetDrawnGutter(gutterWidth) {
+		editor.MarkAllDirty() // The text of every row shifts (:set number!, more digits)
+	}
 
 	// Configure tab width
 	tabWidth := config.DefaultTabWidth
@@ -117,50 +181,113 @@ func DrawBuffer(tuiManager *TUI, editor *core.Editor, activeTheme *theme.Theme)
 		tabWidth = 8 // Fallback
 	}
 
+	// CSV/TSV table view: cells are placed by the layout instead of by width
+	tableLayout := editor.TableLayout()
+
+	// The pinned table header or the sticky context line covers the top row
+	pinnedLine, pinned := editor.PinnedLine()
+	drawnPin := -1
+	if pinned {
+		drawnPin = pinnedLine
+	}
+	pinChanged := editor.SetDrawnPin(drawnPin)
+	stickyContext := pinned && !editor.HeaderPinned()
+
+	// Merge conflict regions, whose sides get their own backgrounds
+	conflicts := editor.Conflicts()
+
+	// The bracket under the cursor and the one it pairs with
+	matchFrom, matchTo, hasMatch := editor.MatchingBracket(false)
+	if hasMatch {
+		editor.SetDrawnMatch(matchFrom.Line, matchTo.Line)
+	} else {
+		editor.SetDrawnMatch(-1, -1)
+	}
+
+	// Virtual text after the end of a line (inline git blame)
+	hintLine, hintText, hasHint := editor.LineHint()
+	hasHint = hasHint && !screenReader
+
 	// --- Draw Loop ---
 	for screenY := 0; screenY < height; screenY++ {
 		bufferLineIdx := screenY + viewY
+		if pinned && screenY == 0 {
+			bufferLineIdx = pinnedLine
+		}
 
 		// Skip unchanged lines unless a full redraw was requested.
-		if !editor.IsDirty(bufferLineIdx) {
+		if !editor.IsDirty(bufferLineIdx) && !(screenY == 0 && pinChanged) {
 			continue
 		}
 
+		// Conflict sides and the sticky context line are set apart by their
+		// background, laid over the syntax colors
+		rowStyle := func(style tcell.Style) tcell.Style { return style }
+		if region, ok := conflict.At(conflicts, bufferLineIdx); ok {
+			if over, ok := conflictStyles[region.SideOf(bufferLineIdx)]; ok {
+				rowStyle = func(style tcell.Style) tcell.Style { return overlayStyle(style, over) }
+			}
+		}
+		if editor.IsAppendedLine(bufferLineIdx) {
+			rowStyle = func(style tcell.Style) tcell.Style { return overlayStyle(style, appendedStyle) }
+		}
+		if stickyContext && screenY == 0 {
+			rowStyle = func(style tcell.Style) tcell.Style { return overlayStyle(style, stickyContextStyle) }
+		}
+
 		// Clear this screen row before (re)drawing it.
 		for x := 0; x < width; x++ {
-			tuiManager.screen.SetContent(x, screenY, ' ', nil, defaultStyle)
+			setContent(x, screenY, ' ', nil, rowStyle(defaultStyle))
 		}
 
 		// --- Draw Line Number Gutter ---
-		if bufferLineIdx >= 0 && bufferLineIdx < len(lines) {
-			lineNumStr := fmt.Sprintf("%d", bufferLineIdx+1)
-			for i, r := range lineNumStr {
-				tuiManager.screen.SetContent(i, screenY, r, nil, lineNumberStyle)
+		if bufferLineIdx >= 0 && bufferLineIdx < bufferLines {
+			// A gutter of one column holds only the change sign (line_numbers off)
+			if fit := gutterWidth - 1; fit > 0 {
+				lineNumStr := fmt.Sprintf("%d", bufferLineIdx+1)
+				if len(lineNumStr) > fit {
+					lineNumStr = lineNumStr[len(lineNumStr)-fit:] // A fixed gutter_width that is too narrow
+				}
+				for i, r := range lineNumStr {
+					setContent(i, screenY, r, nil, rowStyle(lineNumberStyle))
+				}
+			}
+			// A change sign goes in the space closing the gutter
+			if mark, ok := editor.LineMark(bufferLineIdx); ok && gutterWidth > 0 {
+				setContent(gutterWidth-1, screenY, rune(mark), nil, rowStyle(lineMarkStyles[mark]))
+			}
+			// A named mark (ma) shows its letter there instead
+			if name, ok := editor.MarkOnLine(bufferLineIdx); ok && gutterWidth > 0 {
+				setContent(gutterWidth-1, screenY, name, nil, rowStyle(markStyle))
 			}
 		}
 
 		// --- Draw Buffer Text (if line exists) ---
-		if bufferLineIdx < 0 || bufferLineIdx >= len(lines) {
+		if bufferLineIdx < 0 || bufferLineIdx >= bufferLines {
 			continue // Skip text drawing for lines outside buffer
 		}
+		line, err := buf.Line(bufferLineIdx)
+		if err != nil {
+			continue
+		}
 
 		// Get syntax highlights for this line
 		syntaxHighlights := editor.GetSyntaxHighlightsForLine(bufferLineIdx)
 
 		// Create a map for fast lookup of search highlights on this line
 		lineSearchHighlights := make(map[int]bool)
-		if searchHighlights != nil {
+		if searchHighlights := editor.SearchHighlightsForLine(bufferLineIdx); searchHighlights != nil {
 			for _, highlight := range searchHighlights {
 				if highlight.Start.Line <= bufferLineIdx && bufferLineIdx <= highlight.End.Line {
 					// If highlight spans multiple lines, we need special handling
 					if highlight.Start.Line < bufferLineIdx && bufferLineIdx < highlight.End.Line {
 						// Middle of multi-line highlight - entire line is highlighted
-						for i := 0; i < len(lines[bufferLineIdx]); i++ {
+						for i := 0; i < len(line); i++ {
 							lineSearchHighlights[i] = true
 						}
 					} else if highlight.Start.Line == bufferLineIdx && highlight.End.Line > bufferLineIdx {
 						// Start of multi-line highlight
-						for i := highlight.Start.Col; i < len(lines[bufferLineIdx]); i++ {
+						for i := highlight.Start.Col; i < len(line); i++ {
 							lineSearchHighlights[i] = true
 						}
 					} else if highlight.Start.Line < bufferLineIdx && highlight.End.Line == bufferLineIdx {
@@ -178,8 +305,23 @@ func DrawBuffer(tuiManager *TUI, editor *core.Editor, activeTheme *theme.Theme)
 			}
 		}
 
+		// Occurrences of the identifier under the cursor (always single-line)
+		lineWordHighlights := make(map[int]bool)
+		for _, highlight := range wordHighlights {
+			if highlight.Start.Line == bufferLineIdx {
+				for i := highlight.Start.Col; i < highlight.End.Col; i++ {
+					lineWordHighlights[i] = true
+				}
+			}
+		}
+
+		var tableRow table.Row
+		if tableLayout != nil {
+			tableRow = tableLayout.Row(line)
+		}
+
 		// Draw text with syntax highlighting, accounting for horizontal scrolling
-		lineStr := string(lines[bufferLineIdx])
+		lineStr := string(line)
 		gr := uniseg.NewGraphemes(lineStr)
 		screenX := gutterWidth // Start position after gutter
 		currentRuneIndex := 0
@@ -210,17 +352,30 @@ func DrawBuffer(tuiManager *TUI, editor *core.Editor, activeTheme *theme.Theme)
 				}
 			}
 
+			// Apply identifier highlight (below search so the two stay distinguishable)
+			if lineWordHighlights[currentRuneIndex] {
+				currentStyle = wordHighlightStyle
+			}
+
 			// Apply search highlight (takes precedence over syntax)
 			if _, isHighlighted := lineSearchHighlights[currentRuneIndex]; isHighlighted {
 				currentStyle = searchHighlightStyle
 			}
 
+			if hasMatch && (currentPos == matchFrom || currentPos == matchTo) {
+				currentStyle = matchParenStyle
+			}
+
 			// Apply selection style (takes precedence over both syntax and search)
 			if selectionActive {
 				inSelection := false
 				if linewiseSelection {
 					// Line-wise: entire line is selected if its line index is in range
 					inSelection = currentPos.Line >= selStart.Line && currentPos.Line <= selEnd.Line
+				} else if blockSelection {
+					// Block-wise: the same columns of every line in range
+					inSelection = currentPos.Line >= blockStartLine && currentPos.Line <= blockEndLine &&
+						currentPos.Col >= blockStartCol && currentPos.Col <= blockEndCol
 				} else {
 					inSelection = isPositionWithin(currentPos, selStart, selEnd)
 				}
@@ -228,10 +383,43 @@ func DrawBuffer(tuiManager *TUI, editor *core.Editor, activeTheme *theme.Theme)
 					currentStyle = selectionStyle
 				}
 			}
+			for _, r := range extraSelections {
+				if isPositionWithin(currentPos, r.Start, r.End) {
+					currentStyle = selectionStyle
+				}
+			}
+			if slices.Contains(extraCursors, currentPos) {
+				currentStyle = extraCursorStyle
+			}
+
+			currentStyle = rowStyle(currentStyle)
 
 			// Get the main rune
 			mainRune := runes[0]
 
+			if tableLayout != nil {
+				if bufferLineIdx == 0 {
+					currentStyle = currentStyle.Bold(true) // Header row
+				}
+				if tableRow.IsSeparator(currentRuneIndex) {
+					mainRune = table.Border
+					if currentStyle != selectionStyle {
+						currentStyle = lineNumberStyle
+					}
+				} else if mainRune == '\t' {
+					mainRune = ' '
+				}
+				cellX := tableRow.Cols[currentRuneIndex] - viewX + gutterWidth
+				if cellX >= gutterWidth && cellX < width {
+					setContent(cellX, screenY, mainRune, runes[1:], currentStyle)
+					for i := 1; i < clusterWidth && cellX+i < width; i++ {
+						setContent(cellX+i, screenY, ' ', nil, currentStyle)
+					}
+				}
+				currentRuneIndex += len(runes)
+				continue
+			}
+
 			// Handle tabs specially
 			if mainRune == '\t' {
 				// Calculate tab stops based on current visual position
@@ -252,7 +440,7 @@ func DrawBuffer(tuiManager *TUI, editor *core.Editor, activeTheme *theme.Theme)
 					}
 
 					for i := 0; i < visibleSpaces && tabScreenX+i < width; i++ {
-						tuiManager.screen.SetContent(tabScreenX+i, screenY, ' ', nil, currentStyle)
+						setContent(tabScreenX+i, screenY, ' ', nil, currentStyle)
 					}
 
 					// Adjust screen position if tab is visible
@@ -274,11 +462,11 @@ func DrawBuffer(tuiManager *TUI, editor *core.Editor, activeTheme *theme.Theme)
 					// Only draw if within screen width
 					if screenX >= gutterWidth && screenX < width {
 						// Draw character with style
-						tuiManager.screen.SetContent(screenX, screenY, mainRune, runes[1:], currentStyle)
+						setContent(screenX, screenY, mainRune, runes[1:], currentStyle)
 
 						// For wide characters (like CJK), fill the extra cells
 						for i := 1; i < clusterWidth && screenX+i < width; i++ {
-							tuiManager.screen.SetContent(screenX+i, screenY, ' ', nil, currentStyle)
+							setContent(screenX+i, screenY, ' ', nil, currentStyle)
 						}
 					}
 					screenX += clusterWidth
@@ -295,25 +483,61 @@ func DrawBuffer(tuiManager *TUI, editor *core.Editor, activeTheme *theme.Theme)
 				break
 			}
 		}
+
+		// Extra cursors at the end of the line sit in the blank cells after it
+		if lineEnd := utf8.RuneCountInString(lineStr); currentRuneIndex == lineEnd && tableLayout == nil {
+			for _, c := range extraCursors {
+				if x := gutterWidth + currentVisualX + c.Col - lineEnd - viewX; c.Line == bufferLineIdx && c.Col >= lineEnd && x >= gutterWidth && x < width {
+					setContent(x, screenY, ' ', nil, rowStyle(extraCursorStyle))
+				}
+			}
+		}
+
+		// Draw the line hint a few cells after the end of the text
+		if hasHint && hintLine == bufferLineIdx && tableLayout == nil && !(pinned && screenY == 0) {
+			hintX := gutterWidth + currentVisualX - viewX + lineHintGap
+			hint := uniseg.NewGraphemes(hintText)
+			for hint.Next() && hintX < width {
+				if runes := hint.Runes(); hintX >= gutterWidth && hintX+hint.Width() <= width {
+					setContent(hintX, screenY, runes[0], runes[1:], virtualTextStyle)
+				}
+				hintX += hint.Width()
+			}
+		}
 	}
 
 	// Reset dirty-line tracking now that this frame has been fully rendered.
 	editor.ClearDirty()
 }
 
-// DrawCursor positions the terminal cursor using visual width calculations.
-func DrawCursor(tuiManager *TUI, editor *core.Editor) {
+// lineHintGap is the number of blank cells between a line and its hint.
+const lineHintGap = 3
+
+// styleOr returns the theme's style called name, or fallback for themes
+// predating it.
+func styleOr(activeTheme *theme.Theme, name string, fallback tcell.Style) tcell.Style {
+	if style, ok := activeTheme.Styles[name]; ok {
+		return style
+	}
+	return fallback
+}
+
+// overlayStyle lays the background and attributes of over on style, keeping
+// style's foreground so syntax colors show through.
+func overlayStyle(style, over tcell.Style) tcell.Style {
+	_, _, attrs := style.Decompose()
+	_, bg, overAttrs := over.Decompose()
+	return style.Background(bg).Attributes(attrs | overAttrs)
+}
+
+// CursorCell returns the screen cell of the editor's cursor drawn in area,
+// whether or not it is inside the visible text.
+func CursorCell(editor *core.Editor, area Rect) (x, y int) {
 	cursor := editor.GetCursor()
 	viewY, viewX := editor.GetViewport()
 
-	// Calculate gutter width
-	lineCount := editor.GetBuffer().LineCount()
-	if lineCount == 0 {
-		lineCount = 1
-	}
 	// Calculate gutter width using shared helper
-	width, height := tuiManager.Size()
-	gutterWidth := config.GutterWidth(lineCount, width)
+	gutterWidth := editor.GutterWidth(area.Width)
 
 	// Configurable Tab Width
 	tabWidth := config.DefaultTabWidth
@@ -326,26 +550,35 @@ func DrawCursor(tuiManager *TUI, editor *core.Editor) {
 	cursorVisualCol := 0
 	if err == nil {
 		cursorVisualCol = calculateVisualColumn(lineBytes, cursor.Col, tabWidth)
+		if layout := editor.TableLayout(); layout != nil {
+			cursorVisualCol = layout.VisualCol(lineBytes, cursor.Col)
+		}
 	} else {
-		logger.DebugTagf("tui", "DrawCursor: Error getting line %d: %v", cursor.Line, err)
+		logger.DebugTagf("tui", "CursorCell: Error getting line %d: %v", cursor.Line, err)
 	}
 
 	// Calculate screen position based on viewport and visual column
-	screenX := (cursorVisualCol - viewX) + gutterWidth
-	screenY := cursor.Line - viewY
+	return area.X + (cursorVisualCol - viewX) + gutterWidth, area.Y + cursor.Line - viewY
+}
 
-	// Hide cursor if it's outside the drawable area
-	statusBarHeight := config.Get().Editor.StatusBarHeight // Use config value instead of hardcoding
-	viewHeight := height - statusBarHeight
-	textAreaWidth := width - gutterWidth
+// DrawCursor positions the terminal cursor using visual width calculations,
+// for the editor drawn in area.
+func DrawCursor(tuiManager *TUI, editor *core.Editor, area Rect) {
+	screenX, screenY := CursorCell(editor, area)
+	gutterWidth := editor.GutterWidth(area.Width)
+	textAreaWidth := area.Width - gutterWidth
 
 	// --- Add Debug Logging ---
-	logger.DebugTagf("draw", "DrawCursor: Screen Size (%d x %d), StatusBarHeight: %d, ViewHeight: %d, CursorScreen: (%d, %d)",
-		width, height, statusBarHeight, viewHeight, screenX, screenY)
+	logger.DebugTagf("draw", "DrawCursor: Area (%d x %d) at (%d, %d), CursorScreen: (%d, %d)",
+		area.Width, area.Height, area.X, area.Y, screenX, screenY)
 	// --- End Debug Logging ---
 
-	// Check against screen boundaries AND ensure it's not within the gutter itself
-	if screenX < gutterWidth || screenX >= width || screenY < 0 || screenY >= viewHeight || viewHeight <= 0 || textAreaWidth <= 0 {
+	// The pinned table header or sticky context line covers the top row
+	_, pinned := editor.PinnedLine()
+	topCovered := pinned && screenY == area.Y
+
+	// Check against area boundaries AND ensure it's not within the gutter itself
+	if topCovered || screenX < area.X+gutterWidth || screenX >= area.X+area.Width || screenY < area.Y || screenY >= area.Y+area.Height || area.Height <= 0 || textAreaWidth <= 0 {
 		tuiManager.screen.HideCursor()
 	} else {
 		tuiManager.screen.ShowCursor(screenX, screenY)
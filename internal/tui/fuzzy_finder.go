@@ -171,42 +171,6 @@ func (f *FuzzyFinder) Draw(screen tcell.Screen, th *theme.Theme, screenW, screen
 	f.mu.Lock()
 	defer f.mu.Unlock()
 
-	// Layout logic (centered box, 60% width, 50% height)
-	boxW := int(float64(screenW) * 0.6)
-	boxH := int(float64(screenH) * 0.5)
-	if boxW < 40 {
-		boxW = 40
-	}
-	if boxH < 10 {
-		boxH = 10
-	}
-	if boxW > screenW {
-		boxW = screenW
-	}
-	if boxH > screenH {
-		boxH = screenH
-	}
-
-	x := (screenW - boxW) / 2
-	y := (screenH - boxH) / 2
-
-	style := th.GetStyle("Default")
-	DrawBox(screen, x, y, boxW, boxH, style)
-
-	// Draw search prompt
-	prompt := "> " + f.searchTerm
-	DrawText(screen, x+2, y+1, boxW-4, prompt, style.Bold(true))
-	DrawText(screen, x+2+len(prompt), y+1, 1, "_", style.Reverse(true)) // cursor
-
-	// Draw separator
-	for col := x + 1; col < x+boxW-1; col++ {
-		screen.SetContent(col, y+2, '─', nil, style)
-	}
-
-	// Draw items with scrolling
-	maxItems := boxH - 4
-	listY := y + 3
-
 	// Build full item list
 	var allItems []string
 	if f.searchTerm == "" {
@@ -226,53 +190,66 @@ func (f *FuzzyFinder) Draw(screen tcell.Screen, th *theme.Theme, screenW, screen
 		f.selectedIndex = 0
 	}
 
-	// Adjust scroll offset to keep selectedIndex in view
-	if f.selectedIndex < f.scrollOffset {
-		f.scrollOffset = f.selectedIndex
-	} else if f.selectedIndex >= f.scrollOffset+maxItems {
-		f.scrollOffset = f.selectedIndex - maxItems + 1
-	}
-	if f.scrollOffset < 0 {
-		f.scrollOffset = 0
-	}
+	// Centered box, 60% width, 50% height
+	win := NewWindow(CenteredRect(screenW, screenH, 0.6, 0.5, 40, 10), func(screen tcell.Screen, area Rect, style tcell.Style) {
+		// Draw search prompt
+		prompt := "> " + f.searchTerm
+		DrawText(screen, area.X+1, area.Y, area.Width-2, prompt, style.Bold(true))
+		DrawText(screen, area.X+1+len(prompt), area.Y, 1, "_", style.Reverse(true)) // cursor
 
-	// Draw visible slice
-	for i := 0; i < maxItems; i++ {
-		itemIdx := f.scrollOffset + i
-		if itemIdx >= totalItems {
-			break
-		}
-		item := allItems[itemIdx]
-		itemStyle := style
-		if itemIdx == f.selectedIndex {
-			itemStyle = style.Reverse(true)
+		// Draw separator
+		for col := area.X; col < area.X+area.Width; col++ {
+			screen.SetContent(col, area.Y+1, '─', nil, style)
 		}
 
-		displayTxt := item
-		maxTxt := boxW - 4
-		if len([]rune(displayTxt)) > maxTxt {
-			runes := []rune(displayTxt)
-			displayTxt = string(runes[:maxTxt-3]) + "..."
+		// Draw items with scrolling
+		maxItems := area.Height - 2
+		listY := area.Y + 2
+
+		// Adjust scroll offset to keep selectedIndex in view
+		if f.selectedIndex < f.scrollOffset {
+			f.scrollOffset = f.selectedIndex
+		} else if f.selectedIndex >= f.scrollOffset+maxItems {
+			f.scrollOffset = f.selectedIndex - maxItems + 1
+		}
+		if f.scrollOffset < 0 {
+			f.scrollOffset = 0
 		}
 
-		DrawText(screen, x+2, listY+i, boxW-4, displayTxt, itemStyle)
+		// Draw visible slice
+		for i := 0; i < maxItems; i++ {
+			itemIdx := f.scrollOffset + i
+			if itemIdx >= totalItems {
+				break
+			}
+			item := allItems[itemIdx]
+			itemStyle := style
+			if itemIdx == f.selectedIndex {
+				itemStyle = style.Reverse(true)
+			}
+
+			displayTxt := item
+			maxTxt := area.Width - 2
+			if len([]rune(displayTxt)) > maxTxt {
+				runes := []rune(displayTxt)
+				displayTxt = string(runes[:maxTxt-3]) + "..."
+			}
 
-		// Fill rest of line so reverse style is clean
-		drawn := len([]rune(displayTxt))
-		for col := x + 2 + drawn; col < x+boxW-1; col++ {
-			screen.SetContent(col, listY+i, ' ', nil, itemStyle)
+			DrawText(screen, area.X+1, listY+i, maxTxt, displayTxt, itemStyle)
+
+			// Fill rest of line so reverse style is clean
+			fillRow(screen, area, area.X+1+len([]rune(displayTxt)), listY+i, itemStyle)
 		}
-	}
+	})
 
-	// Scroll indicator on the right border
-	if totalItems > maxItems {
-		// Show a small "N/M" count at the top-right corner of the box
-		countStr := fmt.Sprintf("%d/%d", f.selectedIndex+1, totalItems)
-		DrawText(screen, x+boxW-1-len(countStr)-1, y, len(countStr)+1, " "+countStr, style.Dim(true))
+	// Show a small "N/M" count at the top-right corner of the box
+	if totalItems > win.Height-4 {
+		win.TitleRight = fmt.Sprintf("%d/%d", f.selectedIndex+1, totalItems)
 	}
+	win.Draw(screen, th)
 
 	// Indexing indicator
 	if f.isIndexing {
-		DrawText(screen, x+boxW-12, y+boxH-1, 10, "Indexing...", style.Foreground(tcell.ColorYellow))
+		DrawText(screen, win.X+win.Width-12, win.Y+win.Height-1, 10, "Indexing...", th.Role(theme.RoleInfo))
 	}
 }
@@ -0,0 +1,293 @@
+// internal/tui/layout.go
+package tui
+
+import (
+	"github.com/bethropolis/tide/internal/core"
+	"github.com/gdamore/tcell/v2"
+)
+
+// Pane is a window of a split layout: an editor shown in part of the
+// editor area.
+type Pane struct {
+	Editor *core.Editor
+	View   core.View // Its cursor and scroll position while another pane has the focus
+	Rect   Rect      // Where the text goes, set by Arrange
+	Status Rect      // The pane's status line, the row below Rect
+}
+
+// Layout tiles the editor area with panes (:split, :vsplit). Panes split
+// side by side or stacked, and splits nest, so the layout is a tree whose
+// leaves are panes. Every pane ends in its own status line, which keeps
+// stacked panes apart; side-by-side panes are kept apart by a one-column
+// separator.
+type Layout struct {
+	root  *layoutNode
+	focus *layoutNode
+
+	// HideLoneStatus gives a pane no status line while it is the only one
+	// (status_line off)
+	HideLoneStatus bool
+}
+
+// layoutNode is a pane, or a split whose children share its area.
+type layoutNode struct {
+	parent   *layoutNode
+	vertical bool // Children side by side (a :vsplit) rather than stacked
+	children []*layoutNode
+	pane     *Pane // Leaves only
+	rect     Rect  // Area including the separators inside it
+}
+
+// NewLayout creates a layout of one pane showing ed.
+func NewLayout(ed *core.Editor) *Layout {
+	leaf := &layoutNode{pane: &Pane{Editor: ed}}
+	return &Layout{root: leaf, focus: leaf}
+}
+
+// Focused returns the pane keys go to.
+func (l *Layout) Focused() *Pane {
+	return l.focus.pane
+}
+
+// Panes returns every pane, left to right and top to bottom.
+func (l *Layout) Panes() []*Pane {
+	var panes []*Pane
+	var walk func(n *layoutNode)
+	walk = func(n *layoutNode) {
+		if n.pane != nil {
+			panes = append(panes, n.pane)
+		}
+		for _, c := range n.children {
+			walk(c)
+		}
+	}
+	walk(l.root)
+	return panes
+}
+
+// leaf returns the node of p, or nil when p is not in the layout.
+func (l *Layout) leaf(p *Pane) *layoutNode {
+	var found *layoutNode
+	var walk func(n *layoutNode)
+	walk = func(n *layoutNode) {
+		if n.pane == p {
+			found = n
+		}
+		for _, c := range n.children {
+			walk(c)
+		}
+	}
+	walk(l.root)
+	return found
+}
+
+// Focus makes p the focused pane. It reports false when p is not in the
+// layout.
+func (l *Layout) Focus(p *Pane) bool {
+	n := l.leaf(p)
+	if n == nil {
+		return false
+	}
+	l.focus = n
+	return true
+}
+
+// Split divides the focused pane in two, side by side when vertical or
+// else stacked, and focuses the new half, which shows ed. As in Vim the
+// new pane goes above or to the left.
+func (l *Layout) Split(ed *core.Editor, vertical bool) *Pane {
+	leaf := &layoutNode{pane: &Pane{Editor: ed}}
+	old := l.focus
+	parent := old.parent
+	if parent == nil || parent.vertical != vertical {
+		// Turn the pane into a split holding it and the new one
+		split := &layoutNode{parent: parent, vertical: vertical}
+		if parent == nil {
+			l.root = split
+		} else {
+			parent.children[indexOf(parent.children, old)] = split
+		}
+		old.parent = split
+		split.children = []*layoutNode{old}
+		parent = split
+	}
+	leaf.parent = parent
+	i := indexOf(parent.children, old)
+	parent.children = append(parent.children[:i], append([]*layoutNode{leaf}, parent.children[i:]...)...)
+	l.focus = leaf
+	return leaf.pane
+}
+
+// Close removes the focused pane and focuses the one that took its place.
+// The last pane cannot be closed; Close reports false for it.
+func (l *Layout) Close() bool {
+	old := l.focus
+	parent := old.parent
+	if parent == nil {
+		return false
+	}
+	i := indexOf(parent.children, old)
+	parent.children = append(parent.children[:i], parent.children[i+1:]...)
+	next := parent.children[max(0, i-1)]
+
+	if len(parent.children) == 1 {
+		// A split of one is just its child
+		only := parent.children[0]
+		only.parent = parent.parent
+		if parent.parent == nil {
+			l.root = only
+		} else {
+			parent.parent.children[indexOf(parent.parent.children, parent)] = only
+		}
+		if only.pane == nil && only.parent != nil && only.parent.vertical == only.vertical {
+			// Its children join the split above, which runs the same way
+			grand := only.parent
+			j := indexOf(grand.children, only)
+			for _, c := range only.children {
+				c.parent = grand
+			}
+			grand.children = append(grand.children[:j], append(only.children, grand.children[j+1:]...)...)
+		}
+	}
+	for next.pane == nil {
+		next = next.children[0]
+	}
+	l.focus = next
+	return true
+}
+
+// Only closes every pane but the focused one.
+func (l *Layout) Only() {
+	l.focus.parent = nil
+	l.root = l.focus
+}
+
+// Cycle moves the focus delta panes on in the order of Panes, wrapping
+// around.
+func (l *Layout) Cycle(delta int) {
+	panes := l.Panes()
+	for i, p := range panes {
+		if p == l.focus.pane {
+			l.Focus(panes[((i+delta)%len(panes)+len(panes))%len(panes)])
+			return
+		}
+	}
+}
+
+// Neighbour returns the pane next to the focused one in direction dir
+// ('h' left, 'j' down, 'k' up, 'l' right) as last arranged, preferring
+// the one sharing most of the focused pane's edge. It returns nil at the
+// edge of the layout.
+func (l *Layout) Neighbour(dir rune) *Pane {
+	from := l.focus.pane.Rect
+	var best *Pane
+	bestOverlap := 0
+	for _, p := range l.Panes() {
+		r := p.Rect
+		var adjacent bool
+		var overlap int
+		switch dir {
+		case 'h':
+			adjacent, overlap = r.X+r.Width+1 == from.X, spanOverlap(r.Y, r.Height, from.Y, from.Height)
+		case 'l':
+			adjacent, overlap = from.X+from.Width+1 == r.X, spanOverlap(r.Y, r.Height, from.Y, from.Height)
+		case 'k':
+			adjacent, overlap = r.Y+r.Height+1 == from.Y, spanOverlap(r.X, r.Width, from.X, from.Width)
+		case 'j':
+			adjacent, overlap = from.Y+from.Height+1 == r.Y, spanOverlap(r.X, r.Width, from.X, from.Width)
+		}
+		if adjacent && overlap > bestOverlap {
+			best, bestOverlap = p, overlap
+		}
+	}
+	return best
+}
+
+// spanOverlap returns how many cells [a, a+aLen) and [b, b+bLen) share.
+func spanOverlap(a, aLen, b, bLen int) int {
+	return max(0, min(a+aLen, b+bLen)-max(a, b))
+}
+
+// Arrange shares area out among the panes: the children of a split get
+// equal parts, less the separators between them, and each pane gives its
+// last rows to its status line (see StatusHeight).
+func (l *Layout) Arrange(area Rect) {
+	arrange(l.root, area, l.StatusHeight())
+}
+
+// StatusHeight returns the rows each pane gives to its status line: one,
+// or none for a lone pane with HideLoneStatus. Split panes keep theirs,
+// which keep stacked panes apart.
+func (l *Layout) StatusHeight() int {
+	if l.root.pane != nil && l.HideLoneStatus {
+		return 0
+	}
+	return 1
+}
+
+func arrange(n *layoutNode, area Rect, status int) {
+	n.rect = area
+	if n.pane != nil {
+		n.pane.Rect = area
+		n.pane.Rect.Height = max(0, area.Height-status)
+		n.pane.Status = Rect{X: area.X, Y: area.Y + n.pane.Rect.Height, Width: area.Width, Height: min(area.Height, status)}
+		return
+	}
+	total, gap := area.Height, 0 // Status lines separate stacked panes
+	if n.vertical {
+		total, gap = area.Width, 1 // One column between neighbours
+	}
+	count := len(n.children)
+	space := max(0, total-gap*(count-1))
+	offset := 0
+	for i, c := range n.children {
+		size := space / count
+		if i < space%count {
+			size++
+		}
+		part := Rect{X: area.X, Y: area.Y + offset, Width: area.Width, Height: size}
+		if n.vertical {
+			part = Rect{X: area.X + offset, Y: area.Y, Width: size, Height: area.Height}
+		}
+		arrange(c, part, status)
+		offset += size + gap
+	}
+}
+
+// DrawSeparators draws the columns between side-by-side panes as last
+// arranged. The status lines between stacked panes are drawn by the caller.
+func (l *Layout) DrawSeparators(screen tcell.Screen, style tcell.Style) {
+	var walk func(n *layoutNode)
+	walk = func(n *layoutNode) {
+		for i, c := range n.children {
+			walk(c)
+			if i == len(n.children)-1 || !n.vertical {
+				continue
+			}
+			x := c.rect.X + c.rect.Width
+			for y := n.rect.Y; y < n.rect.Y+n.rect.Height; y++ {
+				screen.SetContent(x, y, '│', nil, style)
+			}
+		}
+	}
+	walk(l.root)
+}
+
+// PaneAt returns the pane covering the screen cell (x, y), or nil.
+func (l *Layout) PaneAt(x, y int) *Pane {
+	for _, p := range l.Panes() {
+		if p.Rect.Contains(x, y) {
+			return p
+		}
+	}
+	return nil
+}
+
+func indexOf(nodes []*layoutNode, n *layoutNode) int {
+	for i, c := range nodes {
+		if c == n {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,116 @@
+package tui
+
+import "testing"
+
+func TestLayoutSplitAndArrange(t *testing.T) {
+	l := NewLayout(nil)
+	right := l.Focused()
+	left := l.Split(nil, true) // :vsplit
+	top := l.Split(nil, false) // :split of the left half
+	l.Arrange(Rect{Width: 81, Height: 21})
+
+	want := map[*Pane]Rect{
+		top:   {X: 0, Y: 0, Width: 40, Height: 10},
+		left:  {X: 0, Y: 11, Width: 40, Height: 9},
+		right: {X: 41, Y: 0, Width: 40, Height: 20},
+	}
+	for p, r := range want {
+		if p.Rect != r {
+			t.Errorf("pane rect = %+v, want %+v", p.Rect, r)
+		}
+		if status := (Rect{X: r.X, Y: r.Y + r.Height, Width: r.Width, Height: 1}); p.Status != status {
+			t.Errorf("status line = %+v, want %+v", p.Status, status)
+		}
+	}
+	if got := l.Panes(); len(got) != 3 || got[0] != top || got[1] != left || got[2] != right {
+		t.Errorf("Panes() not in screen order")
+	}
+	if l.Focused() != top {
+		t.Errorf("the new pane should be focused")
+	}
+	if l.PaneAt(50, 5) != right || l.PaneAt(40, 5) != nil || l.PaneAt(5, 10) != nil {
+		t.Errorf("PaneAt should find panes and miss separators and status lines")
+	}
+}
+
+func TestLayoutNeighbour(t *testing.T) {
+	l := NewLayout(nil)
+	right := l.Focused()
+	left := l.Split(nil, true)
+	top := l.Split(nil, false)
+	l.Arrange(Rect{Width: 81, Height: 21})
+
+	if got := l.Neighbour('l'); got != right {
+		t.Errorf("right of top-left should be the right pane")
+	}
+	if got := l.Neighbour('j'); got != left {
+		t.Errorf("below top-left should be bottom-left")
+	}
+	if got := l.Neighbour('k'); got != nil {
+		t.Errorf("nothing above the top-left pane")
+	}
+	l.Focus(right)
+	if got := l.Neighbour('h'); got != top {
+		t.Errorf("left of the right pane should be the top-left pane, sharing most of its edge")
+	}
+}
+
+func TestLayoutClose(t *testing.T) {
+	l := NewLayout(nil)
+	right := l.Focused()
+	l.Split(nil, true)
+	top := l.Split(nil, false)
+
+	l.Focus(top)
+	if !l.Close() {
+		t.Fatalf("Close failed")
+	}
+	l.Arrange(Rect{Width: 81, Height: 21})
+	if len(l.Panes()) != 2 || l.Focused().Rect != (Rect{Width: 40, Height: 20}) {
+		t.Errorf("after closing, the left pane should fill the left half; got %+v", l.Focused().Rect)
+	}
+
+	l.Focus(right)
+	l.Close()
+	if len(l.Panes()) != 1 || l.Close() {
+		t.Errorf("the last pane must not close")
+	}
+}
+
+func TestLayoutOnlyAndCycle(t *testing.T) {
+	l := NewLayout(nil)
+	a := l.Focused()
+	b := l.Split(nil, false)
+	c := l.Split(nil, true)
+
+	l.Cycle(1)
+	if l.Focused() != b {
+		t.Errorf("Cycle(1) from c should reach b")
+	}
+	l.Cycle(-2)
+	if l.Focused() != a {
+		t.Errorf("Cycle(-2) from b should wrap to a")
+	}
+	l.Focus(c)
+	l.Only()
+	if got := l.Panes(); len(got) != 1 || got[0] != c {
+		t.Errorf("Only should keep just the focused pane")
+	}
+}
+
+func TestLayoutHideLoneStatus(t *testing.T) {
+	l := NewLayout(nil)
+	l.HideLoneStatus = true
+	l.Arrange(Rect{Width: 80, Height: 20})
+	if p := l.Focused(); p.Rect.Height != 20 || p.Status.Height != 0 {
+		t.Errorf("lone pane = %+v, status %+v; want all 20 rows and no status line", p.Rect, p.Status)
+	}
+
+	l.Split(nil, false)
+	l.Arrange(Rect{Width: 80, Height: 20})
+	for _, p := range l.Panes() {
+		if p.Status.Height != 1 {
+			t.Errorf("split pane status = %+v, want a status line", p.Status)
+		}
+	}
+}
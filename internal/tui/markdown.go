@@ -0,0 +1,308 @@
+// internal/tui/markdown.go
+package tui
+
+import (
+	"strings"
+
+	"github.com/bethropolis/tide/internal/theme"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/uniseg"
+)
+
+// SpanKind is how a run of rendered markdown is emphasised.
+type SpanKind int
+
+const (
+	SpanPlain SpanKind = iota
+	SpanBold
+	SpanItalic
+	SpanCode    // Inline code and fenced code blocks
+	SpanHeading // Text of a # heading
+	SpanAdded   // + line of a diff code block
+	SpanRemoved // - line of a diff code block
+)
+
+// Span is a run of text with one emphasis.
+type Span struct {
+	Text string
+	Kind SpanKind
+}
+
+// MarkdownLine is one display line of rendered markdown.
+type MarkdownLine []Span
+
+// RenderMarkdown lays out the subset of markdown that documentation popups
+// need: headings, fenced code blocks, inline code, bold, italics, links
+// (shown as their text), bullets and rules. Text is wrapped to width; code
+// blocks are not wrapped and get cut off when drawn. Runs of blank lines
+// collapse into one.
+func RenderMarkdown(src string, width int) []MarkdownLine {
+	if width < 1 {
+		width = 1
+	}
+	var out []MarkdownLine
+	blank := true // Suppresses leading and repeated blank lines
+	addBlank := func() {
+		if !blank {
+			out = append(out, nil)
+			blank = true
+		}
+	}
+
+	inFence, inDiff := false, false
+	for _, line := range strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n") {
+		line = strings.ReplaceAll(line, "\t", "    ")
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			inDiff = inFence && strings.TrimSpace(trimmed[3:]) == "diff"
+			continue
+		}
+		if inFence {
+			kind := SpanCode
+			switch {
+			case inDiff && strings.HasPrefix(line, "+"):
+				kind = SpanAdded
+			case inDiff && strings.HasPrefix(line, "-"):
+				kind = SpanRemoved
+			}
+			out = append(out, MarkdownLine{{Text: line, Kind: kind}})
+			blank = false
+			continue
+		}
+
+		switch {
+		case trimmed == "":
+			addBlank()
+			continue
+		case isRule(trimmed):
+			out = append(out, MarkdownLine{{Text: strings.Repeat("─", width), Kind: SpanPlain}})
+		case strings.HasPrefix(trimmed, "#"):
+			text := strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+			out = append(out, wrapSpans(parseInline(text, SpanHeading), width, "")...)
+		case strings.HasPrefix(trimmed, "- ") || strings.HasPrefix(trimmed, "* ") || strings.HasPrefix(trimmed, "+ "):
+			indent := strings.Repeat(" ", len(line)-len(strings.TrimLeft(line, " ")))
+			spans := append([]Span{{Text: indent + "• "}}, parseInline(trimmed[2:], SpanPlain)...)
+			out = append(out, wrapSpans(spans, width, indent+"  ")...)
+		default:
+			out = append(out, wrapSpans(parseInline(trimmed, SpanPlain), width, "")...)
+		}
+		blank = false
+	}
+	for len(out) > 0 && out[len(out)-1] == nil {
+		out = out[:len(out)-1]
+	}
+	return out
+}
+
+// isRule reports whether a line is a thematic break (---, ***, ___).
+func isRule(s string) bool {
+	if len(s) < 3 {
+		return false
+	}
+	for _, r := range s {
+		if r != rune(s[0]) || (r != '-' && r != '*' && r != '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// parseInline splits a line into spans at code, bold and italic markers.
+// base is the emphasis of text outside any marker.
+func parseInline(s string, base SpanKind) []Span {
+	var spans []Span
+	var cur strings.Builder
+	kind := base
+	flush := func() {
+		if cur.Len() > 0 {
+			spans = append(spans, Span{Text: cur.String(), Kind: kind})
+			cur.Reset()
+		}
+	}
+	toggle := func(k SpanKind) {
+		flush()
+		if kind == k {
+			kind = base
+		} else {
+			kind = k
+		}
+	}
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && strings.IndexByte("\\`*_{}[]()#+-.!<>|", s[i+1]) >= 0:
+			i++
+			cur.WriteByte(s[i])
+		case c == '`':
+			end := strings.IndexByte(s[i+1:], '`')
+			if end < 0 {
+				cur.WriteByte(c)
+				continue
+			}
+			flush()
+			spans = append(spans, Span{Text: s[i+1 : i+1+end], Kind: SpanCode})
+			i += end + 1
+		case (c == '*' || c == '_') && i+1 < len(s) && s[i+1] == c:
+			toggle(SpanBold)
+			i++
+		case c == '*':
+			toggle(SpanItalic)
+		case c == '[':
+			// [text](url) shows only the text
+			mid := strings.Index(s[i:], "](")
+			end := -1
+			if mid > 0 {
+				end = strings.IndexByte(s[i+mid:], ')')
+			}
+			if end < 0 {
+				cur.WriteByte(c)
+				continue
+			}
+			cur.WriteString(s[i+1 : i+mid])
+			i += mid + end
+		default:
+			cur.WriteByte(c)
+		}
+	}
+	flush()
+	return spans
+}
+
+// wrapSpans breaks spans into lines of at most width cells at spaces.
+// Continuation lines start with indent. Words wider than a line are split.
+func wrapSpans(spans []Span, width int, indent string) []MarkdownLine {
+	var lines []MarkdownLine
+	var line MarkdownLine
+	lineW := 0
+	newLine := func() {
+		lines = append(lines, line)
+		line = MarkdownLine{{Text: indent}}
+		lineW = uniseg.StringWidth(indent)
+	}
+	add := func(text string, kind SpanKind) {
+		if n := len(line); n > 0 && line[n-1].Kind == kind {
+			line[n-1].Text += text
+		} else {
+			line = append(line, Span{Text: text, Kind: kind})
+		}
+		lineW += uniseg.StringWidth(text)
+	}
+
+	for _, sp := range spans {
+		for _, word := range splitKeepSpaces(sp.Text) {
+			w := uniseg.StringWidth(word)
+			if word == " " {
+				if lineW > 0 && lineW < width {
+					add(word, sp.Kind)
+				}
+				continue
+			}
+			if lineW+w > width && lineW > uniseg.StringWidth(indent) {
+				trimTrailingSpace(line)
+				newLine()
+			}
+			for lineW+w > width && w > 0 {
+				// Hard-split a word that cannot fit on any line
+				head, rest := splitWidth(word, width-lineW)
+				if head == "" {
+					break
+				}
+				add(head, sp.Kind)
+				newLine()
+				word, w = rest, uniseg.StringWidth(rest)
+			}
+			if word != "" {
+				add(word, sp.Kind)
+			}
+		}
+	}
+	trimTrailingSpace(line)
+	return append(lines, line)
+}
+
+// splitKeepSpaces splits s into words and single-space separators.
+func splitKeepSpaces(s string) []string {
+	var parts []string
+	for i, f := range strings.Split(s, " ") {
+		if i > 0 {
+			parts = append(parts, " ")
+		}
+		if f != "" {
+			parts = append(parts, f)
+		}
+	}
+	return parts
+}
+
+// splitWidth returns the longest prefix of s at most width cells wide and
+// the remainder.
+func splitWidth(s string, width int) (string, string) {
+	w := 0
+	gr := uniseg.NewGraphemes(s)
+	for gr.Next() {
+		if w+gr.Width() > width {
+			start, _ := gr.Positions()
+			return s[:start], s[start:]
+		}
+		w += gr.Width()
+	}
+	return s, ""
+}
+
+// trimTrailingSpace removes a space left at the end of a wrapped line.
+func trimTrailingSpace(line MarkdownLine) {
+	if n := len(line); n > 0 {
+		line[n-1].Text = strings.TrimRight(line[n-1].Text, " ")
+	}
+}
+
+// Width returns the number of cells the line takes.
+func (l MarkdownLine) Width() int {
+	w := 0
+	for _, sp := range l {
+		w += uniseg.StringWidth(sp.Text)
+	}
+	return w
+}
+
+// SpanStyle returns the style for a span kind on top of base. Code takes
+// its colour from the theme's string style, diff lines from the Add and
+// Remove roles.
+func SpanStyle(kind SpanKind, base tcell.Style, th *theme.Theme) tcell.Style {
+	switch kind {
+	case SpanBold:
+		return base.Bold(true)
+	case SpanItalic:
+		return base.Italic(true)
+	case SpanHeading:
+		return base.Bold(true).Underline(true)
+	case SpanCode:
+		fg, _, _ := th.GetStyle("string").Decompose()
+		return base.Foreground(fg)
+	case SpanAdded:
+		fg, _, _ := th.Role(theme.RoleAdd).Decompose()
+		return base.Foreground(fg)
+	case SpanRemoved:
+		fg, _, _ := th.Role(theme.RoleRemove).Decompose()
+		return base.Foreground(fg)
+	}
+	return base
+}
+
+// DrawMarkdown draws lines into area starting at line top.
+func DrawMarkdown(screen tcell.Screen, area Rect, lines []MarkdownLine, top int, base tcell.Style, th *theme.Theme) {
+	for row := 0; row < area.Height && top+row < len(lines); row++ {
+		x := area.X
+		for _, sp := range lines[top+row] {
+			remaining := area.X + area.Width - x
+			if remaining <= 0 {
+				break
+			}
+			DrawText(screen, x, area.Y+row, remaining, sp.Text, SpanStyle(sp.Kind, base, th))
+			x += uniseg.StringWidth(sp.Text)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+)
+
+// plain flattens rendered lines to their text.
+func plain(lines []MarkdownLine) []string {
+	out := make([]string, len(lines))
+	for i, l := range lines {
+		for _, sp := range l {
+			out[i] += sp.Text
+		}
+	}
+	return out
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	src := "# Title\n\n\n```go\nfunc f()\n```\nSee [docs](https://x) for **more** `info`\\_x.\n- item\n"
+	got := RenderMarkdown(src, 40)
+	want := []string{"Title", "", "func f()", "See docs for more info_x.", "• item"}
+	if !reflect.DeepEqual(plain(got), want) {
+		t.Fatalf("RenderMarkdown = %q, want %q", plain(got), want)
+	}
+	if got[0][0].Kind != SpanHeading || got[2][0].Kind != SpanCode {
+		t.Errorf("heading/code kinds = %v, %v", got[0][0].Kind, got[2][0].Kind)
+	}
+	kinds := map[string]SpanKind{}
+	for _, sp := range got[3] {
+		kinds[sp.Text] = sp.Kind
+	}
+	if kinds["more"] != SpanBold || kinds["info"] != SpanCode {
+		t.Errorf("inline spans = %+v", got[3])
+	}
+}
+
+func TestRenderMarkdownWraps(t *testing.T) {
+	got := plain(RenderMarkdown("one two three four\n- alpha beta gamma", 10))
+	want := []string{"one two", "three four", "• alpha", "  beta", "  gamma"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrapped = %q, want %q", got, want)
+	}
+	for _, l := range RenderMarkdown("abcdefghijklmnop", 5) {
+		if l.Width() > 5 {
+			t.Errorf("line %q wider than 5", plain([]MarkdownLine{l}))
+		}
+	}
+}
+
+func TestRenderMarkdownDiff(t *testing.T) {
+	got := RenderMarkdown("```diff\n@@ -1 +1 @@\n-old\n+new\n```\n```\n-not a diff\n```", 40)
+	want := []SpanKind{SpanCode, SpanRemoved, SpanAdded, SpanCode}
+	for i, kind := range want {
+		if got[i][0].Kind != kind {
+			t.Errorf("line %q kind = %v, want %v", got[i][0].Text, got[i][0].Kind, kind)
+		}
+	}
+}
@@ -2,10 +2,13 @@
 package tui
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/bethropolis/tide/internal/theme"
 	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/uniseg"
+	"github.com/sahilm/fuzzy"
 )
 
 // PickerItem represents a single row in the selection list.
@@ -31,8 +34,15 @@ type Picker struct {
 	SearchTerm    string
 	OnSelect      func(val string)
 	OnCancel      func()
+
+	anchored         bool // Drawn next to a cell instead of centred
+	anchorX, anchorY int
+	fuzzy            bool // Filtered fuzzily on label and description
 }
 
+// pickerMenuRows caps the rows an anchored picker shows before scrolling.
+const pickerMenuRows = 10
+
 // NewPicker creates a ready-to-use picker.
 func NewPicker(title string, items []PickerItem, onSelect func(val string)) *Picker {
 	p := &Picker{
@@ -54,6 +64,25 @@ func (p *Picker) Activate() {
 	p.ScrollOffset = 0
 	p.SearchTerm = ""
 	p.Filtered = p.Items
+	p.anchored = false
+	p.fuzzy = false
+}
+
+// ActivateFuzzy opens the picker filtering fuzzily: the search term is
+// matched against each item's label and description, best matches first,
+// for long lists of entries described in words such as the command
+// palette.
+func (p *Picker) ActivateFuzzy() {
+	p.Activate()
+	p.fuzzy = true
+}
+
+// ActivateAt opens the picker as a compact menu below the cell (x, y), such
+// as the cursor, instead of centred on the screen.
+func (p *Picker) ActivateAt(x, y int) {
+	p.Activate()
+	p.anchored = true
+	p.anchorX, p.anchorY = x, y
 }
 
 // Cancel closes the picker without making a selection.
@@ -115,11 +144,23 @@ func (p *Picker) HandleKeyEvent(ev *tcell.EventKey) bool {
 }
 
 // applyFilter narrows Items → Filtered by case-insensitive prefix match on
-// Label and resets SelectedIndex to 0.
+// Label, or fuzzily after ActivateFuzzy, and resets SelectedIndex to 0.
 func (p *Picker) applyFilter() {
-	if p.SearchTerm == "" {
+	switch {
+	case p.SearchTerm == "":
 		p.Filtered = p.Items
-	} else {
+	case p.fuzzy:
+		haystack := make([]string, len(p.Items))
+		for i, it := range p.Items {
+			haystack[i] = it.Label + " " + it.Description
+		}
+		matches := fuzzy.Find(p.SearchTerm, haystack)
+		kept := make([]PickerItem, 0, len(matches))
+		for _, m := range matches {
+			kept = append(kept, p.Items[m.Index])
+		}
+		p.Filtered = kept
+	default:
 		term := strings.ToLower(p.SearchTerm)
 		var kept []PickerItem
 		for _, it := range p.Items {
@@ -139,53 +180,49 @@ func (p *Picker) Draw(screen tcell.Screen, th *theme.Theme, screenW, screenH int
 		return
 	}
 
-	boxW := int(float64(screenW) * 0.6)
-	boxH := int(float64(screenH) * 0.4)
-	if boxW < 40 {
-		boxW = 40
-	}
-	if boxH < 6 {
-		boxH = 6
-	}
-	if boxW > screenW {
-		boxW = screenW
+	r := CenteredRect(screenW, screenH, 0.6, 0.4, 40, 6)
+	if p.anchored {
+		r = AnchoredRect(p.anchorX-1, p.anchorY, p.menuWidth(), min(len(p.Items), pickerMenuRows)+4, screenW, screenH)
 	}
-	if boxH > screenH {
-		boxH = screenH
-	}
-
-	x := (screenW - boxW) / 2
-	y := (screenH - boxH) / 2
-
-	style := th.GetStyle("Default")
-	DrawBox(screen, x, y, boxW, boxH, style)
-
-	// Title row: show title + count indicator
-	titleStr := p.Title
-	countStr := ""
+	win := NewWindow(r, p.drawContent)
+	win.Title = p.Title
 	if len(p.Filtered) > 0 {
-		countStr = string(rune(p.SelectedIndex+1)) + "/" + string(rune(len(p.Filtered)))
+		win.TitleRight = fmt.Sprintf("%d/%d", p.SelectedIndex+1, len(p.Filtered))
 	}
-	DrawText(screen, x+2, y, boxW-4, titleStr, style.Bold(true))
-	if countStr != "" {
-		DrawText(screen, x+boxW-1-len(countStr)-1, y, len(countStr)+1, " "+countStr, style.Dim(true))
+	win.Draw(screen, th)
+}
+
+// menuWidth returns the width of an anchored picker: enough for the widest
+// item, within bounds.
+func (p *Picker) menuWidth() int {
+	w := uniseg.StringWidth(p.Title) + 4
+	for _, it := range p.Items {
+		iw := uniseg.StringWidth(it.Label)
+		if it.Description != "" {
+			iw += 2 + uniseg.StringWidth(it.Description)
+		}
+		w = max(w, iw)
 	}
+	return min(max(w+4, 30), 80)
+}
 
+// drawContent renders the search prompt and the visible slice of items.
+func (p *Picker) drawContent(screen tcell.Screen, area Rect, style tcell.Style) {
 	// Search prompt
 	prompt := "> " + p.SearchTerm
-	DrawText(screen, x+2, y+1, boxW-4, prompt, style.Bold(true))
-	cursorX := x + 2 + len(prompt)
-	if cursorX < x+boxW-1 {
-		DrawText(screen, cursorX, y+1, 1, "_", style.Reverse(true))
+	DrawText(screen, area.X+1, area.Y, area.Width-2, prompt, style.Bold(true))
+	cursorX := area.X + 1 + len(prompt)
+	if cursorX < area.X+area.Width {
+		DrawText(screen, cursorX, area.Y, 1, "_", style.Reverse(true))
 	}
 
 	// Separator
-	for col := x + 1; col < x+boxW-1; col++ {
-		screen.SetContent(col, y+2, '─', nil, style)
+	for col := area.X; col < area.X+area.Width; col++ {
+		screen.SetContent(col, area.Y+1, '─', nil, style)
 	}
 
-	maxItems := boxH - 4
-	listY := y + 3
+	maxItems := area.Height - 2
+	listY := area.Y + 2
 
 	// Scroll adjustments
 	if p.SelectedIndex < p.ScrollOffset {
@@ -216,18 +253,15 @@ func (p *Picker) Draw(screen tcell.Screen, th *theme.Theme, screenW, screenH int
 		}
 
 		txt := display
-		maxTxt := boxW - 4
+		maxTxt := area.Width - 2
 		if len([]rune(txt)) > maxTxt {
 			runes := []rune(txt)
 			txt = string(runes[:maxTxt-3]) + "..."
 		}
 
-		DrawText(screen, x+2, listY+i, boxW-4, txt, itemStyle)
+		DrawText(screen, area.X+1, listY+i, maxTxt, txt, itemStyle)
 
 		// Fill remainder so reverse-video highlight is clean
-		drawn := len([]rune(txt))
-		for col := x + 2 + drawn; col < x+boxW-1; col++ {
-			screen.SetContent(col, listY+i, ' ', nil, itemStyle)
-		}
+		fillRow(screen, area, area.X+1+len([]rune(txt)), listY+i, itemStyle)
 	}
-}
\ No newline at end of file
+}
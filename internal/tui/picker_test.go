@@ -0,0 +1,45 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestPickerFilter(t *testing.T) {
+	items := []PickerItem{
+		{Label: "main.go", Value: "1"},
+		{Label: "Makefile", Value: "2"},
+		{Label: "write", Description: "Write buffer to file", Value: "3"},
+		{Label: "wq", Description: "Write and quit", Value: "4"},
+	}
+	typeTerm := func(p *Picker, term string) []string {
+		for _, r := range term {
+			p.HandleKeyEvent(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+		}
+		var got []string
+		for _, it := range p.Filtered {
+			got = append(got, it.Value)
+		}
+		return got
+	}
+
+	p := NewPicker("", items, nil)
+	p.Activate()
+	if got := typeTerm(p, "MA"); len(got) != 2 || got[0] != "1" || got[1] != "2" {
+		t.Errorf("substring filter = %v, want [1 2] in list order", got)
+	}
+	p.Activate()
+	if got := typeTerm(p, "quit"); len(got) != 0 {
+		t.Errorf("substring filter matched a description: %v", got)
+	}
+
+	p.ActivateFuzzy()
+	if got := typeTerm(p, "quit"); len(got) != 1 || got[0] != "4" {
+		t.Errorf("fuzzy filter = %v, want [4] from its description", got)
+	}
+	p.Activate()
+	if got := typeTerm(p, "wq"); len(got) != 1 || got[0] != "4" {
+		t.Errorf("Activate should go back to the substring filter, got %v", got)
+	}
+}
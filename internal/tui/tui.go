@@ -3,6 +3,7 @@ package tui
 
 import (
 	"fmt" // Keep fmt if needed for error formatting
+	"strings"
 
 	"github.com/bethropolis/tide/internal/theme" // Import theme package
 	"github.com/gdamore/tcell/v2"
@@ -10,7 +11,9 @@ import (
 
 // TUI manages the terminal screen using tcell.
 type TUI struct {
-	screen tcell.Screen
+	screen      tcell.Screen
+	cursorShape tcell.CursorStyle // Last shape sent to the terminal
+	title       string            // Last window title sent to the terminal
 }
 
 // New creates and initializes a new TUI instance.
@@ -28,10 +31,77 @@ func New() (*TUI, error) {
 	defStyle := currentTheme.GetStyle("Default")
 	s.SetStyle(defStyle)
 	s.EnableMouse()
+	s.EnablePaste() // Bracketed paste: pasted text arrives between EventPaste markers
 
 	return &TUI{screen: s}, nil
 }
 
+// NewHeadless creates a TUI on an in-memory screen of 80x24 cells, for
+// running without a terminal (tide --script).
+func NewHeadless() (*TUI, error) {
+	s := tcell.NewSimulationScreen("UTF-8")
+	if err := s.Init(); err != nil {
+		return nil, fmt.Errorf("failed to initialize headless screen: %w", err)
+	}
+	s.SetSize(80, 24)
+	return &TUI{screen: s}, nil
+}
+
+// SetCursorShape changes the terminal cursor shape (DECSCUSR). tcell puts
+// back the terminal's default cursor when the screen is finalized.
+func (t *TUI) SetCursorShape(shape tcell.CursorStyle) {
+	if shape == t.cursorShape {
+		return
+	}
+	t.cursorShape = shape
+	t.screen.SetCursorStyle(shape)
+}
+
+// SetTitle changes the title of the terminal's window; "" leaves it to
+// the terminal.
+func (t *TUI) SetTitle(title string) {
+	if title == t.title {
+		return
+	}
+	t.title = title
+	t.screen.SetTitle(title)
+}
+
+// ParseCursorShape converts a cursor_shape config value such as "bar" or
+// "blinking-block" into a tcell cursor style.
+func ParseCursorShape(name string) (tcell.CursorStyle, bool) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "default", "":
+		return tcell.CursorStyleDefault, true
+	case "block":
+		return tcell.CursorStyleSteadyBlock, true
+	case "blinking-block":
+		return tcell.CursorStyleBlinkingBlock, true
+	case "bar":
+		return tcell.CursorStyleSteadyBar, true
+	case "blinking-bar":
+		return tcell.CursorStyleBlinkingBar, true
+	case "underline":
+		return tcell.CursorStyleSteadyUnderline, true
+	case "blinking-underline":
+		return tcell.CursorStyleBlinkingUnderline, true
+	}
+	return tcell.CursorStyleDefault, false
+}
+
+// SteadyCursorShape returns the non-blinking form of shape.
+func SteadyCursorShape(shape tcell.CursorStyle) tcell.CursorStyle {
+	switch shape {
+	case tcell.CursorStyleBlinkingBlock:
+		return tcell.CursorStyleSteadyBlock
+	case tcell.CursorStyleBlinkingBar:
+		return tcell.CursorStyleSteadyBar
+	case tcell.CursorStyleBlinkingUnderline:
+		return tcell.CursorStyleSteadyUnderline
+	}
+	return shape
+}
+
 // Close finalizes the tcell screen.
 func (t *TUI) Close() {
 	if t.screen != nil {
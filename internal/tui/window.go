@@ -0,0 +1,238 @@
+// internal/tui/window.go
+package tui
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/bethropolis/tide/internal/theme"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/uniseg"
+)
+
+// Rect is a rectangle in screen cells.
+type Rect struct {
+	X, Y          int
+	Width, Height int
+}
+
+// Inset returns the rectangle shrunk by n cells on every side.
+func (r Rect) Inset(n int) Rect {
+	inner := Rect{X: r.X + n, Y: r.Y + n, Width: r.Width - 2*n, Height: r.Height - 2*n}
+	if inner.Width < 0 {
+		inner.Width = 0
+	}
+	if inner.Height < 0 {
+		inner.Height = 0
+	}
+	return inner
+}
+
+// Contains reports whether the cell (x, y) lies inside the rectangle.
+func (r Rect) Contains(x, y int) bool {
+	return x >= r.X && x < r.X+r.Width && y >= r.Y && y < r.Y+r.Height
+}
+
+// CenteredRect computes a box centred on the screen whose size is a fraction
+// of the screen, never smaller than minW x minH and never larger than the screen.
+func CenteredRect(screenW, screenH int, widthFrac, heightFrac float64, minW, minH int) Rect {
+	w := int(float64(screenW) * widthFrac)
+	h := int(float64(screenH) * heightFrac)
+	if w < minW {
+		w = minW
+	}
+	if h < minH {
+		h = minH
+	}
+	if w > screenW {
+		w = screenW
+	}
+	if h > screenH {
+		h = screenH
+	}
+	return Rect{X: (screenW - w) / 2, Y: (screenH - h) / 2, Width: w, Height: h}
+}
+
+// AnchoredRect places a w x h box just below and to the right of the anchor
+// cell, shifting it back on screen when it would overflow an edge.
+func AnchoredRect(anchorX, anchorY, w, h, screenW, screenH int) Rect {
+	if w > screenW {
+		w = screenW
+	}
+	if h > screenH {
+		h = screenH
+	}
+	x := anchorX + 1
+	y := anchorY + 1
+	if x+w >= screenW {
+		x = screenW - w - 1
+	}
+	if y+h >= screenH {
+		y = screenH - h - 1
+	}
+	if x < 0 {
+		x = 0
+	}
+	if y < 0 {
+		y = 0
+	}
+	return Rect{X: x, Y: y, Width: w, Height: h}
+}
+
+// AboveRect places a w x h box ending on the row above the anchor cell,
+// starting at its column. When there is no room above it goes below, like
+// AnchoredRect.
+func AboveRect(anchorX, anchorY, w, h, screenW, screenH int) Rect {
+	if anchorY-h < 0 {
+		return AnchoredRect(anchorX-1, anchorY, w, h, screenW, screenH)
+	}
+	r := AnchoredRect(anchorX-1, 0, w, h, screenW, screenH)
+	r.Y = anchorY - h
+	return r
+}
+
+// ContentRenderer paints a window's body into area, which excludes the border.
+type ContentRenderer func(screen tcell.Screen, area Rect, style tcell.Style)
+
+// Window is a floating, optionally bordered box drawn on top of the editor.
+// Overlays (pickers, completion menus, plugin popups) describe their frame
+// with a Window and supply a ContentRenderer instead of drawing borders and
+// backgrounds by hand.
+type Window struct {
+	Rect
+	Title      string          // drawn left-aligned on the top border
+	TitleRight string          // drawn right-aligned on the top border (e.g. "3/10")
+	Border     bool            // draw a box border around the content
+	StyleName  string          // theme style key; "Default" when empty
+	ZIndex     int             // higher values are drawn later (on top)
+	Visible    bool            // hidden windows are skipped by WindowStack
+	Render     ContentRenderer // optional body renderer
+}
+
+// NewWindow creates a visible, bordered window at the given rectangle.
+func NewWindow(r Rect, render ContentRenderer) *Window {
+	return &Window{
+		Rect:    r,
+		Border:  true,
+		Visible: true,
+		Render:  render,
+	}
+}
+
+// Content returns the area available to the renderer.
+func (w *Window) Content() Rect {
+	if w.Border {
+		return w.Rect.Inset(1)
+	}
+	return w.Rect
+}
+
+// Draw paints the window frame, titles and body. It returns the content area
+// so callers may draw additional content after the renderer has run.
+func (w *Window) Draw(screen tcell.Screen, th *theme.Theme) Rect {
+	if w.Width <= 0 || w.Height <= 0 {
+		return Rect{}
+	}
+
+	styleName := w.StyleName
+	if styleName == "" {
+		styleName = "Default"
+	}
+	style := th.GetStyle(styleName)
+
+	if w.Border && w.Width >= 2 && w.Height >= 2 {
+		DrawBox(screen, w.X, w.Y, w.Width, w.Height, style)
+	} else {
+		for row := w.Y; row < w.Y+w.Height; row++ {
+			for col := w.X; col < w.X+w.Width; col++ {
+				screen.SetContent(col, row, ' ', nil, style)
+			}
+		}
+	}
+
+	if w.Border {
+		if w.Title != "" {
+			DrawText(screen, w.X+2, w.Y, w.Width-4, w.Title, style.Bold(true))
+		}
+		if w.TitleRight != "" {
+			label := " " + w.TitleRight + " "
+			lw := uniseg.StringWidth(label)
+			DrawText(screen, w.X+w.Width-1-lw, w.Y, lw, label, style.Dim(true))
+		}
+	}
+
+	area := w.Content()
+	if w.Render != nil && area.Width > 0 && area.Height > 0 {
+		w.Render(screen, area, style)
+	}
+	return area
+}
+
+// WindowStack keeps a set of floating windows and draws them in z-order.
+// It is safe for concurrent use so plugins may add windows from goroutines.
+type WindowStack struct {
+	mu      sync.Mutex
+	windows []*Window
+}
+
+// NewWindowStack creates an empty stack.
+func NewWindowStack() *WindowStack {
+	return &WindowStack{}
+}
+
+// Add registers a window. Adding the same window twice is a no-op.
+func (s *WindowStack) Add(w *Window) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.windows {
+		if existing == w {
+			return
+		}
+	}
+	s.windows = append(s.windows, w)
+}
+
+// Remove unregisters a window.
+func (s *WindowStack) Remove(w *Window) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, existing := range s.windows {
+		if existing == w {
+			s.windows = append(s.windows[:i], s.windows[i+1:]...)
+			return
+		}
+	}
+}
+
+// Len returns the number of registered windows.
+func (s *WindowStack) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.windows)
+}
+
+// Draw paints every visible window, lowest ZIndex first. Windows with equal
+// ZIndex keep their insertion order.
+func (s *WindowStack) Draw(screen tcell.Screen, th *theme.Theme) {
+	s.mu.Lock()
+	ordered := make([]*Window, len(s.windows))
+	copy(ordered, s.windows)
+	s.mu.Unlock()
+
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].ZIndex < ordered[j].ZIndex
+	})
+	for _, w := range ordered {
+		if w.Visible {
+			w.Draw(screen, th)
+		}
+	}
+}
+
+// fillRow pads a list row from col to the end of area so that reverse-video
+// selection highlights span the full width.
+func fillRow(screen tcell.Screen, area Rect, col, row int, style tcell.Style) {
+	for ; col < area.X+area.Width; col++ {
+		screen.SetContent(col, row, ' ', nil, style)
+	}
+}
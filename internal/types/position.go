@@ -24,6 +24,7 @@ type HighlightType string
 
 const (
 	HighlightSearch HighlightType = "search"
+	HighlightWord   HighlightType = "word" // Occurrences of the identifier under the cursor
 	// Add HighlightSyntax, HighlightError later
 )
 
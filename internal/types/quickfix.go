@@ -0,0 +1,9 @@
+package types
+
+// QuickfixItem is one entry of the quickfix list: a location and a message,
+// e.g. a validation error reported by :json fmt.
+type QuickfixItem struct {
+	Path string   // File the entry refers to; empty means the active buffer
+	Pos  Position // 0-based location within the file
+	Text string   // Message shown in the list
+}
@@ -0,0 +1,162 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxPathCompletions caps the number of entries offered for one directory.
+const maxPathCompletions = 50
+
+// projectRootMarkers are files or directories that mark a project root.
+var projectRootMarkers = []string{".git", "go.mod", "package.json", "Cargo.toml", "pyproject.toml"}
+
+// PathMatch is a directory entry offered by CompletePath.
+type PathMatch struct {
+	Name  string
+	IsDir bool
+}
+
+// PathInString reports whether rune column col of line is inside a quoted
+// string whose text up to col looks like a path (contains a '/'), and
+// returns that text.
+func PathInString(line []rune, col int) (string, bool) {
+	if col > len(line) {
+		col = len(line)
+	}
+	var quote rune
+	start := 0
+	for i := 0; i < col; i++ {
+		r := line[i]
+		switch {
+		case quote == 0 && (r == '"' || r == '\'' || r == '`'):
+			quote, start = r, i+1
+		case quote != 0 && r == '\\' && quote != '`':
+			i++ // Skip the escaped character
+		case quote != 0 && r == quote:
+			quote = 0
+		}
+	}
+	if quote == 0 {
+		return "", false
+	}
+	token := string(line[start:col])
+	return token, strings.Contains(token, "/")
+}
+
+// CompletePath lists the entries matching the last element of the partial
+// path token. Relative tokens are resolved against each of bases in turn
+// (e.g. the buffer's directory, then the project root) and the results
+// merged; "~/" is expanded to the home directory. Hidden entries are only
+// offered when the typed name starts with a dot.
+func CompletePath(token string, bases []string) []PathMatch {
+	var c PathCompleter
+	return c.Complete(token, bases)
+}
+
+// PathCompleter is CompletePath for a token being typed: the directories
+// are read once and their entries kept until the directory part of the
+// token or the bases change. The zero value is ready to use.
+type PathCompleter struct {
+	key      string        // Directory part and bases the listings are for
+	listings [][]PathMatch // The entries of each directory searched
+}
+
+// Complete is CompletePath, reading the directories only when they differ
+// from the last call.
+func (c *PathCompleter) Complete(token string, bases []string) []PathMatch {
+	slash := strings.LastIndex(token, "/")
+	dirPart, name := token[:slash+1], token[slash+1:]
+
+	if key := dirPart + "\x00" + strings.Join(bases, "\x00"); key != c.key {
+		c.key, c.listings = key, nil
+		for _, dir := range completionDirs(dirPart, bases) {
+			if entries, err := listDir(dir); err == nil {
+				c.listings = append(c.listings, entries)
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	var matches []PathMatch
+	for _, entries := range c.listings {
+		for _, entry := range entries {
+			if !strings.HasPrefix(entry.Name, name) || seen[entry.Name] {
+				continue
+			}
+			if strings.HasPrefix(entry.Name, ".") && !strings.HasPrefix(name, ".") {
+				continue
+			}
+			seen[entry.Name] = true
+			matches = append(matches, entry)
+			if len(matches) >= maxPathCompletions {
+				return matches
+			}
+		}
+	}
+	return matches
+}
+
+// completionDirs returns the directories the directory part of a path
+// token stands for.
+func completionDirs(dirPart string, bases []string) []string {
+	switch {
+	case strings.HasPrefix(dirPart, "~/"):
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil
+		}
+		return []string{filepath.Join(home, dirPart[2:])}
+	case filepath.IsAbs(dirPart):
+		return []string{dirPart}
+	}
+	var dirs []string
+	for _, base := range bases {
+		if base != "" {
+			dirs = append(dirs, filepath.Join(base, dirPart))
+		}
+	}
+	return dirs
+}
+
+// listDir returns the entries of dir, following symbolic links to tell
+// directories apart.
+func listDir(dir string) ([]PathMatch, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]PathMatch, 0, len(entries))
+	for _, entry := range entries {
+		isDir := entry.IsDir()
+		if entry.Type()&os.ModeSymlink != 0 {
+			if info, err := os.Stat(filepath.Join(dir, entry.Name())); err == nil {
+				isDir = info.IsDir()
+			}
+		}
+		list = append(list, PathMatch{Name: entry.Name(), IsDir: isDir})
+	}
+	return list, nil
+}
+
+// FindProjectRoot walks up from dir looking for a project marker such as
+// .git or go.mod. Returns "" when none is found.
+func FindProjectRoot(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for {
+		for _, marker := range projectRootMarkers {
+			if _, err := os.Stat(filepath.Join(dir, marker)); err == nil {
+				return dir
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPathInString(t *testing.T) {
+	tests := []struct {
+		line   string
+		col    int
+		token  string
+		inPath bool
+	}{
+		{`open("./src/ma`, 14, "./src/ma", true},
+		{`x := "hello`, 11, "hello", false},
+		{`a := "x/y" + b`, 14, "", false},
+		{`s := "a\"/b`, 11, `a\"/b`, true},
+	}
+	for _, tt := range tests {
+		token, ok := PathInString([]rune(tt.line), tt.col)
+		if ok != tt.inPath || (ok && token != tt.token) {
+			t.Errorf("PathInString(%q, %d) = %q, %v; want %q, %v", tt.line, tt.col, token, ok, tt.token, tt.inPath)
+		}
+	}
+}
+
+func TestCompletePath(t *testing.T) {
+	bufDir, root := t.TempDir(), t.TempDir()
+	for _, p := range []string{"src/main.go", "src/map.go", "src/.hidden"} {
+		os.MkdirAll(filepath.Join(root, filepath.Dir(p)), 0755)
+		os.WriteFile(filepath.Join(root, p), nil, 0644)
+	}
+	os.MkdirAll(filepath.Join(bufDir, "src", "mapping"), 0755)
+
+	got := CompletePath("./src/ma", []string{bufDir, root})
+	want := []PathMatch{{"mapping", true}, {"main.go", false}, {"map.go", false}}
+	if len(got) != len(want) {
+		t.Fatalf("CompletePath = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("CompletePath[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+	if got := CompletePath("src/", []string{root}); len(got) != 2 {
+		t.Errorf("hidden entries should be skipped, got %v", got)
+	}
+}
+
+func TestPathCompleterCache(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "a"), 0755)
+	os.WriteFile(filepath.Join(root, "a", "one"), nil, 0644)
+
+	var c PathCompleter
+	if got := c.Complete("a/o", []string{root}); len(got) != 1 {
+		t.Fatalf("Complete = %v, want one entry", got)
+	}
+	os.WriteFile(filepath.Join(root, "a", "other"), nil, 0644)
+	if got := c.Complete("a/ot", []string{root}); len(got) != 0 {
+		t.Errorf("same directory should use the kept listing, got %v", got)
+	}
+	c.Complete("", []string{root})
+	if got := c.Complete("a/ot", []string{root}); len(got) != 1 {
+		t.Errorf("another directory in between should read it again, got %v", got)
+	}
+}
@@ -0,0 +1,183 @@
+package utils
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SplitDroppedPaths parses text that a terminal pastes when files are dragged
+// into it. Depending on the terminal and file manager that is one path per
+// line, space-separated paths with shell quoting or backslash escapes, or
+// file:// URIs. It returns nil unless every item is an absolute path, a ~/
+// path or a file:// URI, so ordinary text is not mistaken for paths.
+func SplitDroppedPaths(text string) []string {
+	var paths []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		var items []string
+		ok := true
+		for _, tok := range splitShellWords(line) {
+			p, isPath := droppedPath(tok)
+			if !isPath {
+				ok = false
+				break
+			}
+			items = append(items, p)
+		}
+		if !ok {
+			// A single unquoted path containing spaces
+			p, isPath := droppedPath(line)
+			if !isPath {
+				return nil
+			}
+			items = []string{p}
+		}
+		paths = append(paths, items...)
+	}
+	return paths
+}
+
+// droppedPath converts one pasted item to a file path. The second result is
+// false if the item does not look like an absolute path.
+func droppedPath(item string) (string, bool) {
+	if strings.HasPrefix(item, "file://") {
+		u, err := url.Parse(item)
+		if err != nil || u.Path == "" {
+			return "", false
+		}
+		item = u.Path
+	}
+	if strings.HasPrefix(item, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", false
+		}
+		item = filepath.Join(home, item[2:])
+	}
+	if !filepath.IsAbs(item) {
+		return "", false
+	}
+	return filepath.Clean(item), true
+}
+
+// splitShellWords splits s on unquoted whitespace, honouring single quotes,
+// double quotes and backslash escapes the way a POSIX shell does.
+func splitShellWords(s string) []string {
+	var words []string
+	var cur strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			cur.WriteRune(r)
+			escaped = false
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else if r == '\\' && quote == '"' {
+				escaped = true
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '\\':
+			escaped = true
+			inWord = true
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, cur.String())
+				cur.Reset()
+				inWord = false
+			}
+		default:
+			cur.WriteRune(r)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, cur.String())
+	}
+	return words
+}
+
+// compactKeepDirs is how many trailing directories DisplayPath leaves
+// uncompressed in the compact style.
+const compactKeepDirs = 2
+
+// DisplayPath formats path for the status bar and tab line. style is one of:
+//
+//	full     the path as given
+//	home     the home directory replaced by ~
+//	compact  like home, with all but the last two directories shortened to
+//	         their first letter (~/p/t/internal/app/app.go)
+//	name     just the file name
+//
+// Unknown styles fall back to full.
+func DisplayPath(path, style string) string {
+	home, _ := os.UserHomeDir()
+	return displayPath(path, style, home)
+}
+
+func displayPath(path, style, home string) string {
+	if path == "" {
+		return path
+	}
+	switch style {
+	case "name":
+		return filepath.Base(path)
+	case "home":
+		return tildePath(path, home)
+	case "compact":
+		return compactPath(tildePath(path, home))
+	default:
+		return path
+	}
+}
+
+// tildePath replaces a leading home directory in path with ~.
+func tildePath(path, home string) string {
+	if home == "" || !filepath.IsAbs(path) {
+		return path
+	}
+	rel, err := filepath.Rel(home, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+	if rel == "." {
+		return "~"
+	}
+	return "~" + string(filepath.Separator) + rel
+}
+
+// compactPath shortens every directory of path to its first letter, except
+// the last compactKeepDirs. A leading dot is kept so .config becomes .c.
+func compactPath(path string) string {
+	sep := string(filepath.Separator)
+	parts := strings.Split(path, sep)
+	// parts[len-1] is the file name; the kept directories precede it
+	for i := 0; i < len(parts)-1-compactKeepDirs; i++ {
+		part := parts[i]
+		if part == "" || part == "~" || part == "." || part == ".." {
+			continue
+		}
+		prefix := ""
+		if strings.HasPrefix(part, ".") {
+			prefix, part = ".", part[1:]
+		}
+		if r := []rune(part); len(r) > 0 {
+			parts[i] = prefix + string(r[0])
+		}
+	}
+	return strings.Join(parts, sep)
+}
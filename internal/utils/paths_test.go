@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitDroppedPaths(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"single", "/tmp/a.txt", []string{"/tmp/a.txt"}},
+		{"quoted", "'/tmp/my file.txt' '/tmp/b.go' ", []string{"/tmp/my file.txt", "/tmp/b.go"}},
+		{"escaped", `/tmp/my\ file.txt /tmp/c`, []string{"/tmp/my file.txt", "/tmp/c"}},
+		{"lines", "/tmp/a\n/tmp/b\n", []string{"/tmp/a", "/tmp/b"}},
+		{"uri", "file:///tmp/with%20space.txt", []string{"/tmp/with space.txt"}},
+		{"unquoted spaces", "/tmp/my file.txt", []string{"/tmp/my file.txt"}},
+		{"relative", "main.go", nil},
+		{"prose", "hello world", nil},
+		{"mixed", "/tmp/a\nnot a path", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SplitDroppedPaths(tt.text)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitDroppedPaths(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDisplayPath(t *testing.T) {
+	const home = "/home/ada"
+	tests := []struct {
+		path, style, want string
+	}{
+		{"/home/ada/projects/tide/internal/app/app.go", "full", "/home/ada/projects/tide/internal/app/app.go"},
+		{"/home/ada/projects/tide/internal/app/app.go", "home", "~/projects/tide/internal/app/app.go"},
+		{"/home/ada/projects/tide/internal/app/app.go", "compact", "~/p/t/internal/app/app.go"},
+		{"/home/ada/projects/tide/internal/app/app.go", "name", "app.go"},
+		{"/home/ada/.config/tide/themes/dark.toml", "compact", "~/.c/tide/themes/dark.toml"},
+		{"/etc/nginx/sites/default/site.conf", "compact", "/e/n/sites/default/site.conf"},
+		{"/home/adam/notes.txt", "home", "/home/adam/notes.txt"},
+		{"internal/app/app.go", "compact", "internal/app/app.go"},
+		{"a/b/c/d.go", "bogus", "a/b/c/d.go"},
+	}
+	for _, tt := range tests {
+		if got := displayPath(tt.path, tt.style, home); got != tt.want {
+			t.Errorf("displayPath(%q, %q) = %q, want %q", tt.path, tt.style, got, tt.want)
+		}
+	}
+}
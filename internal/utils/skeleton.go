@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// CursorPlaceholder marks where the cursor goes after a skeleton is inserted.
+const CursorPlaceholder = "{{cursor}}"
+
+// ExpandSkeleton replaces {{name}} placeholders in a skeleton template with
+// the matching vars. Unknown placeholders, including CursorPlaceholder, are
+// left untouched.
+func ExpandSkeleton(tmpl string, vars map[string]string) string {
+	pairs := make([]string, 0, len(vars)*2)
+	for name, value := range vars {
+		pairs = append(pairs, "{{"+name+"}}", value)
+	}
+	return strings.NewReplacer(pairs...).Replace(tmpl)
+}
+
+// GoPackageName derives a Go package name from the directory dir: its base
+// name lowercased with characters that are not valid in an identifier
+// dropped. It falls back to "main" when nothing usable is left.
+func GoPackageName(dir string) string {
+	base := strings.ToLower(filepath.Base(dir))
+	var b strings.Builder
+	for _, r := range base {
+		if r == '_' || unicode.IsLetter(r) || (unicode.IsDigit(r) && b.Len() > 0) {
+			b.WriteRune(r)
+		}
+	}
+	if b.Len() == 0 {
+		return "main"
+	}
+	return b.String()
+}
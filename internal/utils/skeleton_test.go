@@ -0,0 +1,25 @@
+package utils
+
+import "testing"
+
+func TestExpandSkeleton(t *testing.T) {
+	got := ExpandSkeleton("package {{package}}\n\n{{cursor}}{{unknown}}", map[string]string{"package": "tui"})
+	want := "package tui\n\n{{cursor}}{{unknown}}"
+	if got != want {
+		t.Errorf("ExpandSkeleton = %q, want %q", got, want)
+	}
+}
+
+func TestGoPackageName(t *testing.T) {
+	tests := map[string]string{
+		"/src/tide/internal/tui": "tui",
+		"/src/my-Tool":           "mytool",
+		"/src/2fa":               "fa",
+		"/":                      "main",
+	}
+	for dir, want := range tests {
+		if got := GoPackageName(dir); got != want {
+			t.Errorf("GoPackageName(%q) = %q, want %q", dir, got, want)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"time"
+)
+
+// MoveToTrash moves path into the user's trash can and returns its new
+// location. On Linux and the BSDs this follows the freedesktop.org Trash
+// specification ($XDG_DATA_HOME/Trash); on macOS the file is moved to
+// ~/.Trash. Other platforms return an error.
+func MoveToTrash(path string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot locate home directory: %w", err)
+	}
+
+	switch runtime.GOOS {
+	case "darwin":
+		trashDir := filepath.Join(home, ".Trash")
+		dst := uniqueTrashPath(trashDir, filepath.Base(absPath), "")
+		if err := MoveFile(absPath, dst); err != nil {
+			return "", err
+		}
+		return dst, nil
+
+	case "windows", "plan9", "js":
+		return "", fmt.Errorf("moving files to the trash is not supported on %s", runtime.GOOS)
+	}
+
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	filesDir := filepath.Join(dataHome, "Trash", "files")
+	infoDir := filepath.Join(dataHome, "Trash", "info")
+	if err := os.MkdirAll(filesDir, 0700); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(infoDir, 0700); err != nil {
+		return "", err
+	}
+
+	dst := uniqueTrashPath(filesDir, filepath.Base(absPath), infoDir)
+	name := filepath.Base(dst)
+
+	// The .trashinfo file is written first, as the spec requires, so the
+	// trashed file can always be restored to its original location.
+	info := fmt.Sprintf("[Trash Info]\nPath=%s\nDeletionDate=%s\n",
+		(&url.URL{Path: absPath}).EscapedPath(), time.Now().Format("2006-01-02T15:04:05"))
+	infoPath := filepath.Join(infoDir, name+".trashinfo")
+	if err := os.WriteFile(infoPath, []byte(info), 0600); err != nil {
+		return "", err
+	}
+	if err := MoveFile(absPath, dst); err != nil {
+		os.Remove(infoPath)
+		return "", err
+	}
+	return dst, nil
+}
+
+// uniqueTrashPath returns dir/base, or dir/base.N when that name (or its
+// .trashinfo entry in infoDir) is already taken.
+func uniqueTrashPath(dir, base, infoDir string) string {
+	taken := func(name string) bool {
+		if _, err := os.Lstat(filepath.Join(dir, name)); err == nil {
+			return true
+		}
+		if infoDir != "" {
+			if _, err := os.Lstat(filepath.Join(infoDir, name+".trashinfo")); err == nil {
+				return true
+			}
+		}
+		return false
+	}
+	name := base
+	for i := 2; taken(name); i++ {
+		name = base + "." + strconv.Itoa(i)
+	}
+	return filepath.Join(dir, name)
+}
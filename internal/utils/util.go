@@ -1,9 +1,13 @@
 package utils
 
 import (
+	"bytes"
 	"sync"
 	"time"
 	"unicode/utf8"
+
+	"github.com/bethropolis/tide/internal/types"
+	sitter "github.com/smacker/go-tree-sitter"
 )
 
 // RuneIndexToByteOffset converts a rune index to a byte offset in a byte slice.
@@ -66,6 +70,72 @@ func GetLeadingWhitespace(line []byte) []byte {
 	return ws
 }
 
+// ReindentLines re-indents a block of lines so its first non-blank line
+// starts with indent. Every line that begins with the first non-blank line's
+// original indentation gets that prefix replaced, which keeps nesting within
+// the block intact. Blank lines and less-indented lines are left unchanged.
+func ReindentLines(text, indent []byte) []byte {
+	lines := bytes.SplitAfter(text, []byte("\n"))
+	var base []byte
+	found := false
+	for _, line := range lines {
+		if len(bytes.TrimSpace(line)) > 0 {
+			base = GetLeadingWhitespace(line)
+			found = true
+			break
+		}
+	}
+	if !found || bytes.Equal(base, indent) {
+		return text
+	}
+
+	var out bytes.Buffer
+	out.Grow(len(text))
+	for _, line := range lines {
+		if len(bytes.TrimSpace(line)) > 0 && bytes.HasPrefix(line, base) {
+			out.Write(indent)
+			out.Write(line[len(base):])
+		} else {
+			out.Write(line)
+		}
+	}
+	return out.Bytes()
+}
+
+// EndPosition returns the position just after text when it is inserted at start.
+func EndPosition(start types.Position, text []byte) types.Position {
+	n := bytes.Count(text, []byte("\n"))
+	if n == 0 {
+		return types.Position{Line: start.Line, Col: start.Col + utf8.RuneCount(text)}
+	}
+	return types.Position{Line: start.Line + n, Col: utf8.RuneCount(text[bytes.LastIndexByte(text, '\n')+1:])}
+}
+
+// PositionToPoint converts pos to a tree-sitter point, whose column counts
+// bytes, reading the text of its line with line. A column past the end of
+// the line (virtual space) is taken as its end.
+func PositionToPoint(pos types.Position, line func(int) ([]byte, error)) sitter.Point {
+	text, err := line(pos.Line)
+	if err != nil {
+		return sitter.Point{Row: uint32(max(pos.Line, 0))}
+	}
+	offset := RuneIndexToByteOffset(text, pos.Col)
+	if offset < 0 {
+		offset = len(text) // Virtual space past the end of the line
+	}
+	return sitter.Point{Row: uint32(pos.Line), Column: uint32(offset)}
+}
+
+// PointToPosition converts a tree-sitter point to a position, reading the
+// text of its line with line.
+func PointToPosition(p sitter.Point, line func(int) ([]byte, error)) types.Position {
+	text, err := line(int(p.Row))
+	if err != nil {
+		return types.Position{Line: int(p.Row)}
+	}
+	return types.Position{Line: int(p.Row), Col: ByteOffsetToRuneIndex(text, int(p.Column))}
+}
+
 // Debouncer provides a way to debounce function calls
 type Debouncer struct {
 	mutex      sync.Mutex
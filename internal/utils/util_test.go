@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/bethropolis/tide/internal/types"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+func TestReindentLines(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		indent string
+		want   string
+	}{
+		{"deeper", "if x {\n\ty()\n}\n", "\t", "\tif x {\n\t\ty()\n\t}\n"},
+		{"shallower", "    a\n        b\n", "  ", "  a\n      b\n"},
+		{"leading blank line", "\n  a\n", "\t", "\n\ta\n"},
+		{"less indented line kept", "    a\n  b\n", "", "a\n  b\n"},
+		{"already matching", "\ta\n", "\t", "\ta\n"},
+		{"only blanks", "\n\n", "\t", "\n\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := string(ReindentLines([]byte(tt.text), []byte(tt.indent)))
+			if got != tt.want {
+				t.Errorf("ReindentLines(%q, %q) = %q, want %q", tt.text, tt.indent, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewUUID(t *testing.T) {
+	re := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	a, err := NewUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := NewUUID()
+	if !re.MatchString(a) || a == b {
+		t.Errorf("NewUUID() = %q, %q; want distinct v4 UUIDs", a, b)
+	}
+}
+
+func TestPositionToPoint(t *testing.T) {
+	lines := [][]byte{[]byte("héllo"), []byte("日本")}
+	line := func(i int) ([]byte, error) {
+		if i < 0 || i >= len(lines) {
+			return nil, errors.New("line index out of bounds")
+		}
+		return lines[i], nil
+	}
+	tests := []struct {
+		pos   types.Position
+		point sitter.Point
+	}{
+		{types.Position{Line: 0, Col: 2}, sitter.Point{Row: 0, Column: 3}},
+		{types.Position{Line: 1, Col: 1}, sitter.Point{Row: 1, Column: 3}},
+		{types.Position{Line: 1, Col: 2}, sitter.Point{Row: 1, Column: 6}},
+	}
+	for _, tt := range tests {
+		if got := PositionToPoint(tt.pos, line); got != tt.point {
+			t.Errorf("PositionToPoint(%v) = %v, want %v", tt.pos, got, tt.point)
+		}
+		if got := PointToPosition(tt.point, line); got != tt.pos {
+			t.Errorf("PointToPosition(%v) = %v, want %v", tt.point, got, tt.pos)
+		}
+	}
+	if got := PositionToPoint(types.Position{Line: 0, Col: 9}, line); got != (sitter.Point{Column: 6}) {
+		t.Errorf("past the end of the line: %v, want its end", got)
+	}
+}
@@ -0,0 +1,114 @@
+// Package version holds tide's build information. Release builds set the
+// variables with the linker:
+//
+//	go build -ldflags "-X github.com/bethropolis/tide/internal/version.Version=v0.2.0 \
+//		-X github.com/bethropolis/tide/internal/version.Commit=$(git rev-parse --short HEAD) \
+//		-X github.com/bethropolis/tide/internal/version.BuildDate=$(date -u +%Y-%m-%d)"
+//
+// Without them, the commit and date come from the VCS stamp Go embeds when
+// building inside a git checkout.
+package version
+
+import (
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+)
+
+// Set with -ldflags "-X ...".
+var (
+	Version   = "v0.1.1"
+	Commit    = "n/a"
+	BuildDate = "n/a"
+)
+
+// Info describes the running binary.
+type Info struct {
+	Version   string // e.g. "v0.1.1"
+	Commit    string // Short commit hash, "n/a" when unknown
+	BuildDate string
+	Modified  bool   // Built from a checkout with uncommitted changes
+	GoVersion string // Toolchain the binary was built with
+	Platform  string // GOOS/GOARCH
+}
+
+// Get returns the build information, filling gaps from the embedded VCS
+// stamp.
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			if info.Commit == "n/a" {
+				info.Commit = s.Value[:min(len(s.Value), 7)]
+			}
+		case "vcs.time":
+			if info.BuildDate == "n/a" {
+				info.BuildDate = s.Value
+			}
+		case "vcs.modified":
+			info.Modified = s.Value == "true"
+		}
+	}
+	return info
+}
+
+// String returns the one-line form, e.g. "v0.1.1 (3f2a9c1)".
+func (i Info) String() string {
+	commit := i.Commit
+	if i.Modified {
+		commit += "-dirty"
+	}
+	return i.Version + " (" + commit + ")"
+}
+
+// AtLeast reports whether the running version is v or newer. Versions are
+// compared numerically by their major.minor.patch parts; a leading "v" and
+// any pre-release or build suffix are ignored. An unparsable version
+// compares as 0.0.0.
+func AtLeast(v string) bool {
+	return Compare(Version, v) >= 0
+}
+
+// Compare returns -1, 0 or 1 as a is older than, the same as, or newer than
+// b, with the rules of AtLeast.
+func Compare(a, b string) int {
+	pa, pb := parse(a), parse(b)
+	for i := range pa {
+		switch {
+		case pa[i] < pb[i]:
+			return -1
+		case pa[i] > pb[i]:
+			return 1
+		}
+	}
+	return 0
+}
+
+// parse splits "v1.2.3-rc1" into [1 2 3]. Missing parts are 0.
+func parse(v string) [3]int {
+	var parts [3]int
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+	for i, s := range strings.SplitN(v, ".", 3) {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return [3]int{}
+		}
+		parts[i] = n
+	}
+	return parts
+}
@@ -0,0 +1,32 @@
+package version
+
+import "testing"
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"v0.1.1", "0.1.1", 0},
+		{"v0.1.1", "v0.1", 1},
+		{"v0.2", "v0.1.9", 1},
+		{"v0.10.0", "v0.9.0", 1},
+		{"v1.0.0-rc1", "v1.0.0", 0},
+		{"v0.1.1", "v1", -1},
+		{"dev", "v0.0.1", -1},
+	}
+	for _, tt := range tests {
+		if got := Compare(tt.a, tt.b); got != tt.want {
+			t.Errorf("Compare(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestAtLeast(t *testing.T) {
+	old := Version
+	defer func() { Version = old }()
+	Version = "v0.3.2"
+	if !AtLeast("v0.3") || !AtLeast("0.3.2") || AtLeast("v0.3.3") {
+		t.Errorf("AtLeast gives wrong answers for version %s", Version)
+	}
+}
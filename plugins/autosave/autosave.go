@@ -129,8 +129,9 @@ func (p *AutoSave) saverLoop(interval time.Duration) {
 	for {
 		select {
 		case <-ticker.C:
-			// Timer ticked, check if save is needed
-			p.saveIfModified()
+			// Timer ticked; check on the event loop so the save does not
+			// race edits being made to the buffer
+			p.api.Schedule(p.saveIfModified)
 		case <-p.stopChan:
 			// Shutdown signal received
 			logger.Debugf("%s: Received stop signal, exiting saver loop.", p.Name())